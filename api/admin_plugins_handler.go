@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// PluginStatusesHandler 获取所有已注册插件的启用、隔离和panic状态
+func PluginStatusesHandler(c *gin.Context) {
+	statuses := []service.PluginStatus{}
+	if searchService != nil {
+		statuses = searchService.PluginStatuses()
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"total":   len(statuses),
+		"plugins": statuses,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// EnablePluginHandler 运行时启用插件
+func EnablePluginHandler(c *gin.Context) {
+	setPluginEnabled(c, true)
+}
+
+// DisablePluginHandler 运行时停用插件
+func DisablePluginHandler(c *gin.Context) {
+	setPluginEnabled(c, false)
+}
+
+// setPluginEnabled 启用或停用插件，返回插件的最新状态
+func setPluginEnabled(c *gin.Context, enabled bool) {
+	name := c.Param("name")
+	if err := searchService.SetPluginEnabled(name, enabled); err != nil {
+		respondPluginAdminError(c, err)
+		return
+	}
+	respondPluginStatus(c, name)
+}
+
+// ResetPluginStateHandler 清除插件的内部缓存，返回插件的最新状态
+func ResetPluginStateHandler(c *gin.Context) {
+	name := c.Param("name")
+	if err := searchService.ResetPluginState(name); err != nil {
+		respondPluginAdminError(c, err)
+		return
+	}
+	respondPluginStatus(c, name)
+}
+
+// respondPluginAdminError 返回插件管理操作的错误，插件不存在时为404
+func respondPluginAdminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, service.ErrUnknownPlugin) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, model.NewErrorResponse(status, localizeError(c, err)).WithRequestID(GetRequestID(c)))
+}
+
+// respondPluginStatus 返回插件的最新状态
+func respondPluginStatus(c *gin.Context, name string) {
+	for _, status := range searchService.PluginStatuses() {
+		if status.Name == name {
+			jsonData, _ := jsonutil.Marshal(model.NewSuccessResponse(status))
+			c.Data(http.StatusOK, "application/json", jsonData)
+			return
+		}
+	}
+}
+
+// PluginStatsHandler 获取各插件的按日搜索统计，days控制统计窗口（默认7天）
+func PluginStatsHandler(c *gin.Context) {
+	days := 7
+	if n := util.StringToInt(c.Query("days")); n > 0 {
+		days = n
+	}
+	stats := service.GetPluginStats().Summaries(days)
+	response := model.NewSuccessResponse(gin.H{
+		"total":   len(stats),
+		"plugins": stats,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// PluginZeroHitsHandler 获取各插件按关键词分类的结果统计，以及当前被跳过的插件和分类
+func PluginZeroHitsHandler(c *gin.Context) {
+	stats := service.GetPluginZeroHits().Stats()
+	skipped := 0
+	for _, item := range stats {
+		if item.Skipped {
+			skipped++
+		}
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"enabled": config.AppConfig.ZeroHitSkip,
+		"skipped": skipped,
+		"plugins": stats,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// RecentSearchesHandler 获取最近的搜索请求，limit控制返回条数（默认50）
+func RecentSearchesHandler(c *gin.Context) {
+	limit := 50
+	if n := util.StringToInt(c.Query("limit")); n > 0 {
+		limit = n
+	}
+	searches := service.GetRecentSearches().List(limit)
+	response := model.NewSuccessResponse(gin.H{
+		"total":    len(searches),
+		"searches": searches,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// PluginShadowHandler 获取影子测试中各候选插件与线上插件的结果对比统计
+func PluginShadowHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(gin.H{
+		"sample_rate": config.AppConfig.ShadowSampleRate,
+		"pairs":       service.GetPluginShadowStats(),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
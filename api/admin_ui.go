@@ -0,0 +1,24 @@
+package api
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminUI 内置的管理后台页面，页面中的数据通过/api/admin接口获取，登录后使用管理员令牌访问
+//
+//go:embed admin_ui/index.html
+var adminUI embed.FS
+
+// AdminUIHandler 返回管理后台页面。页面本身不需要认证，管理接口仍需管理员权限
+func AdminUIHandler(c *gin.Context) {
+	data, err := adminUI.ReadFile("admin_ui/index.html")
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", data)
+}
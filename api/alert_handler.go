@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/util/alert"
+	jsonutil "pansou/util/json"
+)
+
+// ListAlertsHandler 获取告警列表，all=true时包含已恢复的告警
+func ListAlertsHandler(c *gin.Context) {
+	manager := alert.GetGlobalManager()
+	alerts := manager.List(c.Query("all") == "true")
+
+	response := model.NewSuccessResponse(gin.H{
+		"alerts": alerts,
+		"total":  len(alerts),
+		"sinks":  manager.SinkNames(),
+	})
+
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// AckAlertHandler 确认告警，确认后在级别升级前不再重复通知
+func AckAlertHandler(c *gin.Context) {
+	a, err := alert.GetGlobalManager().Ack(c.Param("id"), alertOperator(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	jsonData, _ := jsonutil.Marshal(model.NewSuccessResponse(a))
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// ResolveAlertHandler 手动恢复告警
+func ResolveAlertHandler(c *gin.Context) {
+	a, err := alert.GetGlobalManager().ResolveByID(c.Param("id"), alertOperator(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	jsonData, _ := jsonutil.Marshal(model.NewSuccessResponse(a))
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// alertOperator 获取操作告警的用户名
+func alertOperator(c *gin.Context) string {
+	if user := GetCurrentUser(c); user != nil {
+		return user.Username
+	}
+	return "unknown"
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"pansou/model"
 	"pansou/service"
+	"pansou/util/i18n"
 	jsonutil "pansou/util/json"
 )
 
@@ -25,7 +26,7 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req model.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "请求参数错误: "+err.Error()))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
 		return
 	}
 
@@ -39,7 +40,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// 返回用户信息（不包含密码）
 	response := model.NewSuccessResponse(gin.H{
 		"user": user,
-		"message": "注册成功",
+		"message": T(c, i18n.MsgAuthRegistered),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -50,7 +51,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "请求参数错误: "+err.Error()))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
 		return
 	}
 
@@ -75,7 +76,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	// 获取令牌
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "缺少认证令牌"))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgAuthMissingToken)))
 		return
 	}
 
@@ -83,13 +84,13 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	// 登出
 	if err := h.authService.Logout(token); err != nil {
-		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "登出失败: "+err.Error()))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, T(c, i18n.MsgAuthLogoutFailed, err.Error())))
 		return
 	}
 
 	// 返回成功信息
 	response := model.NewSuccessResponse(gin.H{
-		"message": "登出成功",
+		"message": T(c, i18n.MsgAuthLoggedOut),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -100,7 +101,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
@@ -117,13 +118,13 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
 	var req model.UserUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "请求参数错误: "+err.Error()))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
 		return
 	}
 
@@ -137,7 +138,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	// 返回更新后的用户信息
 	response := model.NewSuccessResponse(gin.H{
 		"user": updatedUser,
-		"message": "资料更新成功",
+		"message": T(c, i18n.MsgAuthProfileUpdated),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -148,13 +149,13 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
 	var req model.PasswordChangeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "请求参数错误: "+err.Error()))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
 		return
 	}
 
@@ -166,7 +167,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	// 返回成功信息
 	response := model.NewSuccessResponse(gin.H{
-		"message": "密码修改成功",
+		"message": T(c, i18n.MsgAuthPasswordChanged),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -177,13 +178,13 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 func (h *AuthHandler) UpgradeMembership(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
 	var req model.MembershipUpgradeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "请求参数错误: "+err.Error()))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
 		return
 	}
 
@@ -197,7 +198,7 @@ func (h *AuthHandler) UpgradeMembership(c *gin.Context) {
 	// 返回会员信息
 	response := model.NewSuccessResponse(gin.H{
 		"membership": membership,
-		"message": "会员升级成功",
+		"message": T(c, i18n.MsgAuthMembershipUpgraded),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -208,7 +209,7 @@ func (h *AuthHandler) UpgradeMembership(c *gin.Context) {
 func (h *AuthHandler) GetUserStats(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
@@ -235,14 +236,14 @@ func (h *AuthHandler) GetUserStats(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
 	// 生成新的JWT令牌
 	token, expiresAt, err := h.authService.(*service.AuthService).generateJWT(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "生成令牌失败: "+err.Error()))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, T(c, i18n.MsgAuthTokenFailed, err.Error())))
 		return
 	}
 
@@ -250,7 +251,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	response := model.NewSuccessResponse(gin.H{
 		"token": token,
 		"expires_at": expiresAt,
-		"message": "令牌刷新成功",
+		"message": T(c, i18n.MsgAuthTokenRefreshed),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"pansou/model"
 	"pansou/service"
+	"pansou/util/i18n"
 )
 
 var authService *service.AuthService
@@ -22,14 +23,14 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "缺少认证令牌"))
+			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthMissingToken)))
 			c.Abort()
 			return
 		}
 
 		// 检查Bearer格式
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "无效的认证格式"))
+			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthInvalidFormat)))
 			c.Abort()
 			return
 		}
@@ -40,7 +41,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 验证令牌
 		user, err := authService.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "无效的认证令牌: "+err.Error()))
+			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthInvalidToken, err.Error())))
 			c.Abort()
 			return
 		}
@@ -97,7 +98,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		// 获取用户权限
 		permissions, exists := c.Get("permissions")
 		if !exists {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "需要认证"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthRequired)))
 			c.Abort()
 			return
 		}
@@ -113,7 +114,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
 		}
 
 		if !hasPermission {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "权限不足"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthForbidden)))
 			c.Abort()
 			return
 		}
@@ -128,14 +129,14 @@ func RequireUserType(userType model.UserType) gin.HandlerFunc {
 		// 获取用户类型
 		userTypeValue, exists := c.Get("user_type")
 		if !exists {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "需要认证"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthRequired)))
 			c.Abort()
 			return
 		}
 
 		// 检查用户类型
 		if userTypeValue.(model.UserType) != userType {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "用户类型不匹配"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthUserTypeMismatch)))
 			c.Abort()
 			return
 		}
@@ -150,14 +151,14 @@ func RequireMember() gin.HandlerFunc {
 		// 获取用户
 		user, exists := c.Get("user")
 		if !exists {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "需要认证"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthRequired)))
 			c.Abort()
 			return
 		}
 
 		// 检查是否为会员
 		if !user.(*model.User).IsMember() {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "需要会员权限"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAuthMemberRequired)))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/cache"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// CacheWriteStatsHandler 获取缓存写入管理器统计信息和当前配置
+func CacheWriteStatsHandler(c *gin.Context) {
+	manager := service.GetGlobalCacheWriteManager()
+	if manager == nil {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, T(c, i18n.MsgCacheManagerNotReady)))
+		return
+	}
+
+	data := gin.H{
+		"stats":  manager.GetStats(),
+		"config": manager.GetConfig(),
+	}
+	if replicator := service.GetCacheReplicator(); replicator != nil {
+		data["replication"] = replicator.Stats()
+	}
+	response := model.NewSuccessResponse(data)
+
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// CacheStatsHandler 获取缓存容量、各级命中率、序列化错误和写入队列积压，用于评估CACHE_MAX_SIZE和CACHE_TTL是否合适
+func CacheStatsHandler(c *gin.Context) {
+	data := gin.H{
+		"enabled": config.AppConfig.CacheEnabled,
+		"config": gin.H{
+			"path":        config.AppConfig.CachePath,
+			"store":       config.AppConfig.CacheStore,
+			"max_size_mb": config.AppConfig.CacheMaxSizeMB,
+			"ttl_minutes": config.AppConfig.CacheTTLMinutes,
+		},
+		"search":     service.GetSearchCacheStats(),
+		"merge_memo": service.GetMergeMemoStats(),
+		"backfill":   service.GetSearchBackfillStats(),
+	}
+	if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
+		stats := mainCache.Stats()
+		data["memory"] = stats.Memory
+		data["disk"] = stats.Disk
+		data["store"] = stats.Store
+		if stats.Serializer != nil {
+			data["serializer"] = stats.Serializer
+		}
+	}
+	if manager := service.GetGlobalCacheWriteManager(); manager != nil {
+		writeStats := manager.GetWriteManagerStats()
+		data["write_manager"] = gin.H{
+			"queue_size":         writeStats.CurrentQueueSize,
+			"total_writes":       writeStats.TotalWrites,
+			"failed_writes":      writeStats.FailedWrites,
+			"last_flush_time":    writeStats.LastFlushTime,
+			"last_flush_trigger": writeStats.LastFlushTrigger,
+		}
+	}
+
+	jsonData, _ := jsonutil.Marshal(model.NewSuccessResponse(data))
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// UpdateCacheWriteConfigHandler 运行时调整缓存写入管理器参数
+func UpdateCacheWriteConfigHandler(c *gin.Context) {
+	manager := service.GetGlobalCacheWriteManager()
+	if manager == nil {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, T(c, i18n.MsgCacheManagerNotReady)))
+		return
+	}
+
+	var req model.CacheWriteConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+
+	var interval time.Duration
+	if req.MaxBatchInterval != "" {
+		d, err := time.ParseDuration(req.MaxBatchInterval)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgCacheInvalidInterval, err.Error())))
+			return
+		}
+		interval = d
+	}
+
+	if err := manager.UpdateConfig(interval, req.MaxBatchSize, cache.CacheWriteStrategy(req.Strategy)); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	response := model.NewSuccessResponse(gin.H{
+		"config":  manager.GetConfig(),
+		"message": T(c, i18n.MsgCacheConfigUpdated),
+	})
+
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// ListChannelCandidatesHandler 获取候选频道列表，top参数控制返回条数（默认50）
+func ListChannelCandidatesHandler(c *gin.Context) {
+	top := 50
+	if topStr := c.Query("top"); topStr != "" {
+		if n := util.StringToInt(topStr); n > 0 {
+			top = n
+		}
+	}
+
+	response := model.NewSuccessResponse(gin.H{
+		"channels":   config.GetDefaultChannels(),
+		"candidates": service.GetChannelDiscovery().TopCandidates(top),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// AddChannelCandidateHandler 将候选频道加入默认搜索频道列表
+func AddChannelCandidateHandler(c *gin.Context) {
+	channels, err := service.GetChannelDiscovery().Add(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	response := model.NewSuccessResponse(gin.H{"channels": channels})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// DismissChannelCandidateHandler 忽略候选频道
+func DismissChannelCandidateHandler(c *gin.Context) {
+	if err := service.GetChannelDiscovery().Dismiss(c.Param("name")); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	response := model.NewSuccessResponse(nil)
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
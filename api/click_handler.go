@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// LinkRedirectHandler 链接跳转：根据跳转ID重定向到网盘链接并记录点击
+func LinkRedirectHandler(c *gin.Context) {
+	target, ok := service.GetClickService().Resolve(c.Param("link_id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, T(c, i18n.MsgLinkExpired)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	c.Redirect(http.StatusFound, target)
+}
+
+// ClickStatsHandler 获取链接点击统计，top参数控制返回条数（默认50）
+func ClickStatsHandler(c *gin.Context) {
+	top := 50
+	if topStr := c.Query("top"); topStr != "" {
+		if n := util.StringToInt(topStr); n > 0 {
+			top = n
+		}
+	}
+
+	clickService := service.GetClickService()
+	response := model.NewSuccessResponse(gin.H{
+		"links":    clickService.TopLinks(top),
+		"keywords": clickService.TopKeywords(top),
+		"sources":  clickService.TopSources(top),
+	})
+
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
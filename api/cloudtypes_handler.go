@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// CloudTypesHandler 获取支持的链接类型，以及最近一段时间返回的各类型链接数，hours控制统计窗口（默认24小时）
+func CloudTypesHandler(c *gin.Context) {
+	hours := 24
+	if n := util.StringToInt(c.Query("hours")); n > 0 && n < hours {
+		hours = n
+	}
+	types := service.GetCloudTypeCounts(hours)
+	response := model.NewSuccessResponse(gin.H{
+		"total":        len(types),
+		"window_hours": hours,
+		"types":        types,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
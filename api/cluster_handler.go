@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// ClusterSearchHandler 工作节点接口：执行协调节点分配的插件搜索，返回未经合并排序的插件结果
+func ClusterSearchHandler(c *gin.Context) {
+	secret := config.AppConfig.ClusterSecret
+	if secret != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(service.ClusterSecretHeader)), []byte(secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgClusterInvalidSecret)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	var req model.ClusterSearchRequest
+	data, ok := readRequestBody(c)
+	if !ok {
+		return
+	}
+	if err := jsonutil.Unmarshal(data, &req); err != nil || req.Keyword == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidRequest)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	result, err := searchService.SearchPluginsForCluster(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, T(c, i18n.MsgClusterPluginFailed, err.Error())).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	response := model.NewSuccessResponse(result)
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// ClusterStatsHandler 获取协调节点的工作节点列表和请求统计
+func ClusterStatsHandler(c *gin.Context) {
+	workers := []service.ClusterWorkerStats{}
+	if coordinator := service.GetClusterCoordinator(); coordinator != nil {
+		workers = coordinator.Stats()
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"role":    config.AppConfig.ClusterRole,
+		"workers": workers,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	jsonutil "pansou/util/json"
+)
+
+// ConcurrencyStatusHandler 获取每次搜索的并发数及自适应调整的状态
+func ConcurrencyStatusHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(service.GetConcurrencyStatus())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// ConnStatsHandler 获取出站连接池配额、当前连接数及进程文件描述符使用情况
+func ConnStatsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(util.GetConnStats())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
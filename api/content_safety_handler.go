@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	jsonutil "pansou/util/json"
+)
+
+// ContentSafetyStatsHandler 获取内容安全过滤统计和域名信誉列表更新状态
+func ContentSafetyStatsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(service.GetContentSafetyFilter().Stats())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
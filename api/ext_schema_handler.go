@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/plugin"
+	jsonutil "pansou/util/json"
+)
+
+// ExtSchemaHandler 获取已启用插件支持的ext参数说明
+func ExtSchemaHandler(c *gin.Context) {
+	schemas := make(map[string][]plugin.ExtField)
+	if searchService != nil && searchService.GetPluginManager() != nil {
+		schemas = searchService.GetPluginManager().ExtSchemas()
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"plugins": schemas,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -6,12 +6,13 @@ import (
 	// "os"
 	
 	"github.com/gin-gonic/gin"
-	"pansou/config"
 	"pansou/model"
 	"pansou/service"
 	jsonutil "pansou/util/json"
 	"pansou/util"
+	"pansou/util/i18n"
 	"strings"
+	"time"
 )
 
 // 保存搜索服务的实例
@@ -92,6 +93,8 @@ func SearchHandler(c *gin.Context) {
 			plugins = nil
 		}
 		
+		profile := strings.TrimSpace(c.Query("profile"))
+		
 		// 处理cloud_types参数，支持逗号分隔
 		var cloudTypes []string
 		// 检查请求中是否存在cloud_types参数
@@ -112,6 +115,48 @@ func SearchHandler(c *gin.Context) {
 			cloudTypes = nil
 		}
 		
+		// 处理aliases参数，支持逗号分隔
+		var aliases []string
+		aliasesStr := c.Query("aliases")
+		if aliasesStr != "" && aliasesStr != " " {
+			parts := strings.Split(aliasesStr, ",")
+			for _, part := range parts {
+				trimmed := strings.TrimSpace(part)
+				if trimmed != "" {
+					aliases = append(aliases, trimmed)
+				}
+			}
+		}
+		
+		// 处理分页参数
+		pageSize := 0
+		pageSizeStr := c.Query("page_size")
+		if pageSizeStr != "" && pageSizeStr != " " {
+			pageSize = util.StringToInt(pageSizeStr)
+		}
+		pageToken := strings.TrimSpace(c.Query("page_token"))
+		
+		// 处理语言参数，lang支持逗号分隔
+		var languages []string
+		langStr := c.Query("lang")
+		if langStr != "" && langStr != " " {
+			parts := strings.Split(langStr, ",")
+			for _, part := range parts {
+				trimmed := strings.TrimSpace(part)
+				if trimmed != "" {
+					languages = append(languages, trimmed)
+				}
+			}
+		}
+		preferLang := strings.TrimSpace(c.Query("pref_lang"))
+		group := c.Query("group") == "true"
+		includeFiltered := c.Query("include_filtered") == "true"
+		highlight := c.Query("highlight") == "true"
+		rerank := strings.TrimSpace(c.Query("rerank"))
+		deepLinks := c.Query("deep_links") == "true"
+		minResolution := strings.TrimSpace(c.Query("min_resolution"))
+		debug := c.Query("debug") == "true"
+		
 		// 处理ext参数，JSON格式
 		var ext map[string]interface{}
 		extStr := c.Query("ext")
@@ -121,7 +166,7 @@ func SearchHandler(c *gin.Context) {
 				ext = make(map[string]interface{})
 			} else {
 				if err := jsonutil.Unmarshal([]byte(extStr), &ext); err != nil {
-					c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的ext参数格式: "+err.Error()))
+					c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidExtFormat, err.Error())).WithRequestID(GetRequestID(c)))
 					return
 				}
 			}
@@ -132,58 +177,94 @@ func SearchHandler(c *gin.Context) {
 		}
 
 		req = model.SearchRequest{
-			Keyword:      keyword,
-			Channels:     channels,
-			Concurrency:  concurrency,
-			ForceRefresh: forceRefresh,
-			ResultType:   resultType,
-			SourceType:   sourceType,
-			Plugins:      plugins,
-			CloudTypes:   cloudTypes, // 添加cloud_types到请求中
-			Ext:          ext,
+			Keyword:         keyword,
+			Channels:        channels,
+			Concurrency:     concurrency,
+			ForceRefresh:    forceRefresh,
+			ResultType:      resultType,
+			SourceType:      sourceType,
+			Plugins:         plugins,
+			Profile:         profile,
+			CloudTypes:      cloudTypes, // 添加cloud_types到请求中
+			Ext:             ext,
+			Aliases:         aliases,
+			PageSize:        pageSize,
+			PageToken:       pageToken,
+			Languages:       languages,
+			PreferLang:      preferLang,
+			Group:           group,
+			IncludeFiltered: includeFiltered,
+			Highlight:       highlight,
+			Rerank:          rerank,
+			DeepLinks:       deepLinks,
+			MinResolution:   minResolution,
+			Debug:           debug,
 		}
 	} else {
 		// POST方式：从请求体获取
-		data, err := c.GetRawData()
-		if err != nil {
-			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "读取请求数据失败: "+err.Error()))
+		data, ok := readRequestBody(c)
+		if !ok {
 			return
 		}
 
 		if err := jsonutil.Unmarshal(data, &req); err != nil {
-			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的请求参数: "+err.Error()))
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidRequestDetail, err.Error())).WithRequestID(GetRequestID(c)))
 			return
 		}
 	}
 	
-	// 检查并设置默认值
-	if len(req.Channels) == 0 {
-		req.Channels = config.AppConfig.DefaultChannels
+	// 校验结构化过滤条件，并合并到对应的搜索参数
+	filter, err := applySearchFilter(&req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidFilter, err.Error())).WithRequestID(GetRequestID(c)))
+		return
 	}
 	
-	// 如果未指定结果类型，默认返回merge并转换为merged_by_type
-	if req.ResultType == "" {
-		req.ResultType = "merged_by_type"
-	} else if req.ResultType == "merge" {
-		// 将merge转换为merged_by_type，以兼容内部处理
-		req.ResultType = "merged_by_type"
+	// 关键词及别名命中搜索黑名单时拒绝请求并记录审计日志
+	if match := service.GetSearchBlacklist().Check(append([]string{req.Keyword}, req.Aliases...)...); match != nil {
+		service.GetSearchBlacklist().Record(service.BlockedQuery{
+			Keyword:   req.Keyword,
+			Code:      match.Code,
+			ClientIP:  c.ClientIP(),
+			RequestID: GetRequestID(c),
+			TenantID:  c.GetString("tenant_id"),
+			UserID:    GetCurrentUserID(c),
+		})
+		message := match.Message
+		if message == "" {
+			message = T(c, i18n.MsgSearchBlocked)
+		}
+		response := model.NewErrorResponse(match.Status, message).WithRequestID(GetRequestID(c))
+		response.Data = gin.H{"reason": match.Code}
+		c.JSON(match.Status, response)
+		return
 	}
 	
-	// 如果未指定数据来源类型，默认为全部
-	if req.SourceType == "" {
-		req.SourceType = "all"
+	// 校验参数并设置默认值（结果类型、数据来源、默认频道，以及来源与插件/频道参数的互斥）
+	opts := service.SearchOptions{
+		Keyword:         req.Keyword,
+		Aliases:         req.Aliases,
+		Channels:        req.Channels,
+		Concurrency:     req.Concurrency,
+		ResultType:      req.ResultType,
+		SourceType:      req.SourceType,
+		Plugins:         req.Plugins,
+		Profile:         req.Profile,
+		CloudTypes:      req.CloudTypes,
+		Ext:             req.Ext,
+		Languages:       req.Languages,
+		PreferredLang:   req.PreferLang,
+		IncludeFiltered: req.IncludeFiltered,
+		Highlight:       req.Highlight,
+		Rerank:          req.Rerank,
+		DeepLinks:       req.DeepLinks,
+		MinResolution:   req.MinResolution,
+		Debug:           req.Debug,
 	}
-	
-	// 参数互斥逻辑：当src=tg时忽略plugins参数，当src=plugin时忽略channels参数
-	if req.SourceType == "tg" {
-		req.Plugins = nil // 忽略plugins参数
-	} else if req.SourceType == "plugin" {
-		req.Channels = nil // 忽略channels参数
-	} else if req.SourceType == "all" {
-		// 对于all类型，如果plugins为空或不存在，统一设为nil
-		if req.Plugins == nil || len(req.Plugins) == 0 {
-			req.Plugins = nil
-		}
+	if err := opts.Normalize(); err != nil && !(err == service.ErrKeywordRequired && req.PageToken != "") {
+		// 携带分页令牌时从快照取页，不需要关键词
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)).WithRequestID(GetRequestID(c)))
+		return
 	}
 	
 	// 检查用户权限和限制
@@ -191,52 +272,156 @@ func SearchHandler(c *gin.Context) {
 	if user != nil {
 		// 检查用户搜索权限
 		if !user.CanSearch() {
-			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "账户已被禁用"))
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(403, T(c, i18n.MsgAccountDisabled)).WithRequestID(GetRequestID(c)))
 			return
 		}
 		
 		// 根据用户类型调整并发数
 		maxConcurrency := user.GetMaxConcurrency()
-		if req.Concurrency <= 0 || req.Concurrency > maxConcurrency {
-			req.Concurrency = maxConcurrency
+		if opts.Concurrency <= 0 || opts.Concurrency > maxConcurrency {
+			opts.Concurrency = maxConcurrency
 		}
 		
 		// 根据用户偏好设置默认值
-		if len(req.Channels) == 0 && len(user.Profile.Preferences.DefaultChannels) > 0 {
-			req.Channels = user.Profile.Preferences.DefaultChannels
+		if len(opts.Channels) == 0 && len(user.Profile.Preferences.DefaultChannels) > 0 {
+			opts.Channels = user.Profile.Preferences.DefaultChannels
+		}
+		if len(opts.Plugins) == 0 && opts.Profile == "" && len(user.Profile.Preferences.DefaultPlugins) > 0 {
+			opts.Plugins = user.Profile.Preferences.DefaultPlugins
 		}
-		if len(req.Plugins) == 0 && len(user.Profile.Preferences.DefaultPlugins) > 0 {
-			req.Plugins = user.Profile.Preferences.DefaultPlugins
+		if len(opts.CloudTypes) == 0 && len(user.Profile.Preferences.DefaultCloudTypes) > 0 {
+			opts.CloudTypes = user.Profile.Preferences.DefaultCloudTypes
 		}
-		if len(req.CloudTypes) == 0 && len(user.Profile.Preferences.DefaultCloudTypes) > 0 {
-			req.CloudTypes = user.Profile.Preferences.DefaultCloudTypes
+		if opts.PreferredLang == "" {
+			opts.PreferredLang = user.Profile.Preferences.Language
 		}
 	} else {
 		// 未认证用户使用默认限制
-		if req.Concurrency <= 0 {
-			req.Concurrency = 3 // 未认证用户最大3个并发
+		if opts.Concurrency <= 0 {
+			opts.Concurrency = 3 // 未认证用户最大3个并发
 		}
-		if req.Concurrency > 3 {
-			req.Concurrency = 3
+		if opts.Concurrency > 3 {
+			opts.Concurrency = 3
 		}
 	}
 	
+	// TG频道和插件都不可用时明确返回503，而不是返回空结果
+	if reason := searchService.UnavailableSourceReason(opts.SourceType, opts.Channels, RequestLang(c)); reason != "" {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, T(c, i18n.MsgSearchNoSource, reason)).WithRequestID(GetRequestID(c)))
+		return
+	}
+	
+	// 校验语言参数
+	for _, lang := range opts.Languages {
+		if _, ok := util.NormalizeLanguage(lang); !ok {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidLanguage, lang)).WithRequestID(GetRequestID(c)))
+			return
+		}
+	}
+	
+	// 按插件注册的参数说明校验ext（只搜索TG时ext不会传给插件，无需校验）
+	if opts.SourceType != "tg" && searchService != nil && searchService.GetPluginManager() != nil {
+		ext, err := searchService.GetPluginManager().ValidateExt(opts.Ext)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidExt, err.Error())).WithRequestID(GetRequestID(c)))
+			return
+		}
+		opts.Ext = ext
+	}
+	
+	// 缓存刷新级别：X-Cache-Refresh请求头优先，未指定时refresh=true等同于full
+	if header := c.GetHeader("X-Cache-Refresh"); header != "" {
+		level, ok := model.ParseRefreshLevel(header)
+		if !ok {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidRefresh, header)).WithRequestID(GetRequestID(c)))
+			return
+		}
+		opts.Refresh = level
+	} else {
+		opts.Refresh = model.RefreshLevelFromBool(req.ForceRefresh)
+	}
+	
+	// 携带分页令牌时直接从快照中取页，不重新搜索
+	if req.PageToken != "" {
+		snapshotID, offset, pageSize, err := service.DecodePageToken(req.PageToken)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)).WithRequestID(GetRequestID(c)))
+			return
+		}
+		// 其他租户创建的快照按不存在处理
+		snapshot, ok := service.GetSearchSnapshotStore().Get(snapshotID, tenantID(c))
+		if !ok {
+			c.JSON(http.StatusGone, model.NewErrorResponse(410, T(c, i18n.MsgPageTokenExpired)).WithRequestID(GetRequestID(c)))
+			return
+		}
+		response := model.NewSuccessResponse(service.PaginateSearchResponse(snapshot, snapshotID, offset, pageSize))
+		jsonData, _ := jsonutil.Marshal(response)
+		c.Data(http.StatusOK, "application/json", jsonData)
+		return
+	}
+	if req.PageSize < 0 {
+		req.PageSize = 0
+	} else if req.PageSize > service.MaxPageSize {
+		req.PageSize = service.MaxPageSize
+	}
+	
 	// 可选：启用调试输出（生产环境建议注释掉）
-	// fmt.Printf("🔧 [调试] 搜索参数: keyword=%s, channels=%v, concurrency=%d, refresh=%v, resultType=%s, sourceType=%s, plugins=%v, cloudTypes=%v, ext=%v\n", 
-	//	req.Keyword, req.Channels, req.Concurrency, req.ForceRefresh, req.ResultType, req.SourceType, req.Plugins, req.CloudTypes, req.Ext)
+	// fmt.Printf("🔧 [调试] 搜索参数: %+v\n", opts)
 	
 	// 执行搜索
-	result, err := searchService.Search(req.Keyword, req.Channels, req.Concurrency, req.ForceRefresh, req.ResultType, req.SourceType, req.Plugins, req.CloudTypes, req.Ext)
+	start := time.Now()
+	result, err := searchService.Search(c.Request.Context(), opts)
+	
+	recordRecentSearch(c, &opts, start, result.Total, err)
 	
 	if err != nil {
-		response := model.NewErrorResponse(500, "搜索失败: "+err.Error())
+		response := model.NewErrorResponse(500, T(c, i18n.MsgSearchFailed, err.Error())).WithRequestID(GetRequestID(c))
 		jsonData, _ := jsonutil.Marshal(response)
 		c.Data(http.StatusInternalServerError, "application/json", jsonData)
 		return
 	}
 
+	// 维护模式未配置提示时使用默认提示
+	if result.Maintenance && result.Notice == "" {
+		result.Notice = T(c, i18n.MsgMaintenanceNotice)
+	}
+
+	// 按过滤条件中的时间范围和排序方式处理结果
+	result = service.ApplyTimeRangeAndRanking(result, filter.from, filter.to, filter.ranking)
+
+	// 按标题相似度聚类，放在分页之前，使分页按聚类计算
+	if req.Group {
+		result = service.ClusterSearchResponse(result)
+	}
+
+	// 启用分页时保存结果快照，后续页通过令牌从同一快照读取
+	if req.PageSize > 0 {
+		snapshotID := service.GetSearchSnapshotStore().Save(result, tenantID(c))
+		result = service.PaginateSearchResponse(result, snapshotID, 0, req.PageSize)
+	}
+
 	// 返回结果
 	response := model.NewSuccessResponse(result)
 	jsonData, _ := jsonutil.Marshal(response)
 	c.Data(http.StatusOK, "application/json", jsonData)
-} 
\ No newline at end of file
+} 
+
+// recordRecentSearch 记录搜索请求概要，供管理后台查看最近的搜索
+func recordRecentSearch(c *gin.Context, opts *service.SearchOptions, start time.Time, total int, err error) {
+	entry := service.RecentSearch{
+		Time:       start,
+		RequestID:  GetRequestID(c),
+		Keyword:    opts.Keyword,
+		SourceType: opts.SourceType,
+		ResultType: opts.ResultType,
+		Total:      total,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if tenant := service.TenantFromContext(c.Request.Context()); tenant != nil {
+		entry.Tenant = tenant.ID
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	service.GetRecentSearches().Record(entry)
+}
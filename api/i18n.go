@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"pansou/service"
+	"pansou/util"
+	"pansou/util/i18n"
+)
+
+// 服务层已知错误对应的消息ID，用于按请求语言翻译错误
+var serviceErrorMessages = map[error]string{
+	service.ErrInvalidPageToken:   i18n.MsgPageTokenInvalid,
+	service.ErrPageTokenExpired:   i18n.MsgPageTokenExpired,
+	service.ErrUnknownTenant:      i18n.MsgTenantUnknown,
+	service.ErrInvalidAPIKey:      i18n.MsgTenantInvalidAPIKey,
+	service.ErrAPIKeyRequired:     i18n.MsgTenantAPIKeyRequired,
+	service.ErrUnknownPlugin:      i18n.MsgPluginUnknown,
+	service.ErrKeywordRequired:    i18n.MsgSearchKeywordRequired,
+	service.ErrInvalidSourceType:  i18n.MsgSearchInvalidSource,
+	service.ErrInvalidResultType:  i18n.MsgSearchInvalidResult,
+	service.ErrInvalidRerank:      i18n.MsgSearchInvalidRerank,
+	service.ErrInvalidResolution:  i18n.MsgSearchInvalidMinRes,
+	service.ErrUnknownProfile:     i18n.MsgSearchUnknownProfile,
+	service.ErrProfileWithPlugins: i18n.MsgSearchProfileConflict,
+	service.ErrInvalidLookupQuery: i18n.MsgLookupInvalidQuery,
+	util.ErrInvalidGCPercent:      i18n.MsgMemoryInvalidGCPercent,
+	util.ErrInvalidMemoryLimit:    i18n.MsgMemoryInvalidLimit,
+	util.ErrInvalidLogSampleRate:  i18n.MsgLogInvalidSampleRate,
+	util.ErrInvalidDebugDuration:  i18n.MsgLogInvalidDuration,
+}
+
+// RequestLang 获取请求的接口消息语言（按Accept-Language选择）
+func RequestLang(c *gin.Context) string {
+	if lang := c.GetString("lang"); lang != "" {
+		return lang
+	}
+	lang := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+	c.Set("lang", lang)
+	return lang
+}
+
+// T 按请求语言格式化接口消息
+func T(c *gin.Context, id string, args ...interface{}) string {
+	return i18n.T(RequestLang(c), id, args...)
+}
+
+// localizeError 翻译服务层的已知错误，其余错误原样返回
+func localizeError(c *gin.Context, err error) string {
+	if id, ok := serviceErrorMessages[err]; ok {
+		return T(c, id)
+	}
+	return err.Error()
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/service"
+	"pansou/util"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// LogSettingsHandler 获取当前日志设置：详细日志开关、采样率和开启调试日志的插件
+func LogSettingsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(util.GetLogSettings())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// UpdateLogSettingsHandler 运行时开关异步插件和缓存的详细日志，调整高频日志的采样率
+func UpdateLogSettingsHandler(c *gin.Context) {
+	var req model.LogSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+
+	if err := util.UpdateLogSettings(req.Verbose, req.SampleRate); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)))
+		return
+	}
+
+	response := model.NewSuccessResponse(gin.H{
+		"logging": util.GetLogSettings(),
+		"message": T(c, i18n.MsgLogSettingsUpdated),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// EnablePluginDebugHandler 在一段时间内（默认5分钟）输出插件的调试日志：每次请求、插件缓存命中、
+// 搜索耗时和结果数，不受详细日志开关和采样率限制
+func EnablePluginDebugHandler(c *gin.Context) {
+	name := c.Param("name")
+	if _, ok := plugin.GetPluginByName(name); !ok {
+		respondPluginAdminError(c, service.ErrUnknownPlugin)
+		return
+	}
+
+	// 请求体可以省略
+	var req model.PluginDebugRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+	duration := util.DefaultPluginDebugDuration
+	if req.Duration != "" {
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgLogInvalidDuration)))
+			return
+		}
+		duration = parsed
+	}
+
+	if _, err := util.EnablePluginDebug(name, duration); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)))
+		return
+	}
+	response := model.NewSuccessResponse(util.GetLogSettings())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// DisablePluginDebugHandler 提前关闭插件的调试日志
+func DisablePluginDebugHandler(c *gin.Context) {
+	util.DisablePluginDebug(c.Param("name"))
+	response := model.NewSuccessResponse(util.GetLogSettings())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// LinkLookupHandler 链接反查：q为磁力链接、info-hash或网盘分享链接，返回该链接在历史搜索结果中的
+// 出现记录（标题、出现过的关键词、来源、首次出现时间），不发起关键词搜索
+func LinkLookupHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgLookupQueryRequired)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	result, err := service.LookupLink(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	response := model.NewSuccessResponse(result)
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// MaintenanceStatusHandler 获取维护模式状态
+func MaintenanceStatusHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(service.GetMaintenanceStatus())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// UpdateMaintenanceHandler 运行时进入或退出维护模式。维护模式下搜索只返回缓存结果，
+// 不再请求TG频道和插件，便于重启依赖服务或等待上游解除封禁
+func UpdateMaintenanceHandler(c *gin.Context) {
+	var req model.MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+
+	response := model.NewSuccessResponse(service.SetMaintenanceMode(req.Enabled, req.Message))
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
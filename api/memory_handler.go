@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/util"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// MemoryStatsHandler 获取进程堆内存统计和当前GC设置
+func MemoryStatsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(util.GetMemoryStats())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// UpdateMemorySettingsHandler 运行时调整GC触发阈值和软内存上限
+func UpdateMemorySettingsHandler(c *gin.Context) {
+	var req model.MemorySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+
+	if err := util.UpdateMemorySettings(req.GCPercent, req.MemoryLimitMB); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, localizeError(c, err)))
+		return
+	}
+
+	response := model.NewSuccessResponse(gin.H{
+		"memory":  util.GetMemoryStats(),
+		"message": T(c, i18n.MsgMemorySettingsUpdated),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// ManualGCHandler 立即执行GC并将空闲内存归还操作系统，返回前后的堆内存统计
+func ManualGCHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(util.RunManualGC())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
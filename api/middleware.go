@@ -7,14 +7,38 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"pansou/util"
 )
 
+// RequestIDMiddleware 请求ID中间件：沿用客户端传入的X-Request-ID或生成新ID，
+// 写入gin上下文、请求上下文和响应头，便于跨服务与插件日志关联同一次请求
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := util.NormalizeRequestID(c.GetHeader(util.RequestIDHeader))
+		if requestID == "" {
+			requestID = util.NewRequestID()
+		}
+		
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(util.WithRequestID(c.Request.Context(), requestID))
+		c.Writer.Header().Set(util.RequestIDHeader, requestID)
+		
+		c.Next()
+	}
+}
+
+// GetRequestID 获取当前请求的请求ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
 // CORSMiddleware 跨域中间件
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, X-Tenant-ID, X-API-Key")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -65,9 +89,15 @@ func LoggerMiddleware() gin.HandlerFunc {
 		// 请求IP
 		clientIP := c.ClientIP()
 		
+		// 请求ID
+		requestID := GetRequestID(c)
+		if requestID == "" {
+			requestID = "-"
+		}
+		
 		// 日志格式
 		gin.DefaultWriter.Write([]byte(
-			fmt.Sprintf("| %s | %s | %s | %d | %s\n", 
-				clientIP, reqMethod, displayURI, statusCode, latencyTime.String())))
+			fmt.Sprintf("| %s | %s | %s | %s | %d | %s\n", 
+				requestID, clientIP, reqMethod, displayURI, statusCode, latencyTime.String())))
 	}
 } 
\ No newline at end of file
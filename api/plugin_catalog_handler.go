@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/service"
+	jsonutil "pansou/util/json"
+)
+
+// PluginCatalogHandler 获取已启用插件的元数据（显示名称、描述、站点、支持的网盘类型），供前端渲染来源选择
+func PluginCatalogHandler(c *gin.Context) {
+	catalog := []plugin.PluginMetadata{}
+	if searchService != nil && searchService.GetPluginManager() != nil {
+		catalog = searchService.GetPluginManager().Catalog()
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"total":   len(catalog),
+		"plugins": catalog,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// PluginProfilesHandler 获取配置的插件组合及其展开后的插件，搜索请求通过profile参数使用
+func PluginProfilesHandler(c *gin.Context) {
+	profiles := []service.PluginProfile{}
+	if searchService != nil {
+		profiles = searchService.PluginProfiles()
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"total":    len(profiles),
+		"profiles": profiles,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
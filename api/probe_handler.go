@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// 单次批量检测的总时长上限，超时未完成的链接状态为unknown
+const linkProbeTimeout = 30 * time.Second
+
+// LinkProbeHandler 批量检测分享链接是否有效、已失效或需要提取码
+func LinkProbeHandler(c *gin.Context) {
+	var req model.LinkProbeRequest
+	data, ok := readRequestBody(c)
+	if !ok {
+		return
+	}
+	if err := jsonutil.Unmarshal(data, &req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidRequestDetail, err.Error())).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	targets := make([]service.LinkProbeTarget, 0, len(req.Links)+len(req.URLs))
+	for _, link := range req.Links {
+		if url := strings.TrimSpace(link.URL); url != "" {
+			targets = append(targets, service.LinkProbeTarget{URL: url, Password: strings.TrimSpace(link.Password)})
+		}
+	}
+	for _, url := range req.URLs {
+		if url = strings.TrimSpace(url); url != "" {
+			targets = append(targets, service.LinkProbeTarget{URL: url})
+		}
+	}
+	if len(targets) == 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgProbeNoLinks)).WithRequestID(GetRequestID(c)))
+		return
+	}
+	if len(targets) > config.AppConfig.LinkProbeMaxURLs {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgProbeTooManyLinks, config.AppConfig.LinkProbeMaxURLs)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), linkProbeTimeout)
+	defer cancel()
+	results := service.GetLinkValidator().CheckAll(ctx, targets)
+
+	summary := make(map[string]int)
+	for _, result := range results {
+		summary[result.Status]++
+	}
+	response := model.NewSuccessResponse(gin.H{
+		"total":   len(results),
+		"summary": summary,
+		"results": results,
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
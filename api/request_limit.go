@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/util/i18n"
+)
+
+// RequestLimitMiddleware 请求大小与读写期限中间件：
+// 查询字符串超过MAX_QUERY_LENGTH时返回414；请求体超过MAX_BODY_SIZE时返回413
+// （声明了Content-Length的请求直接拒绝，分块上传的请求在读取超限时由readRequestBody返回413）；
+// 请求体需在BODY_READ_TIMEOUT内读完，否则返回408；响应开始写入后需在RESPONSE_WRITE_TIMEOUT内写完，
+// 避免慢速客户端长期占用连接。需注册在压缩中间件之前
+func RequestLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit := config.AppConfig.MaxQueryLength; limit > 0 && len(c.Request.URL.RawQuery) > limit {
+			c.AbortWithStatusJSON(http.StatusRequestURITooLong, model.NewErrorResponse(414, T(c, i18n.MsgQueryTooLong, limit)).WithRequestID(GetRequestID(c)))
+			return
+		}
+
+		rc := http.NewResponseController(c.Writer)
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			if limit := config.AppConfig.MaxBodySize; limit > 0 {
+				if c.Request.ContentLength > limit {
+					c.Header("Connection", "close")
+					c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(413, T(c, i18n.MsgBodyTooLarge, limit)).WithRequestID(GetRequestID(c)))
+					return
+				}
+				c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+			}
+			if timeout := config.AppConfig.BodyReadTimeout; timeout > 0 {
+				// 不支持设置期限的连接（如测试用的ResponseRecorder）忽略错误
+				if rc.SetReadDeadline(time.Now().Add(timeout)) == nil {
+					c.Request.Body = &deadlineBody{ReadCloser: c.Request.Body, rc: rc}
+				}
+			}
+		}
+
+		if timeout := config.AppConfig.ResponseWriteTimeout; timeout > 0 {
+			c.Writer = &deadlineWriter{ResponseWriter: c.Writer, rc: rc, timeout: timeout}
+		}
+		c.Next()
+	}
+}
+
+// deadlineWriter 第一次写入响应体时设置写入期限，期限从开始写入算起，不包含搜索耗时
+type deadlineWriter struct {
+	gin.ResponseWriter
+	rc      *http.ResponseController
+	timeout time.Duration
+	once    sync.Once
+}
+
+func (w *deadlineWriter) setDeadline() {
+	w.once.Do(func() {
+		_ = w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	})
+}
+
+// Write 写入响应体
+func (w *deadlineWriter) Write(data []byte) (int, error) {
+	w.setDeadline()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应体
+func (w *deadlineWriter) WriteString(s string) (int, error) {
+	w.setDeadline()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// deadlineBody 请求体读完（或读取出错）后清除读取期限。期限不清除时，搜索等耗时较长的请求
+// 在处理过程中会因连接的后台读取超时而被取消
+type deadlineBody struct {
+	io.ReadCloser
+	rc   *http.ResponseController
+	once sync.Once
+}
+
+// Read 读取请求体
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(func() {
+			_ = b.rc.SetReadDeadline(time.Time{})
+		})
+	}
+	return n, err
+}
+
+// readRequestBody 读取请求体，失败时按原因返回413（超过MAX_BODY_SIZE）、408（超过BODY_READ_TIMEOUT）或400，
+// 并返回false
+func readRequestBody(c *gin.Context) ([]byte, bool) {
+	data, err := c.GetRawData()
+	if err == nil {
+		return data, true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &maxBytesErr):
+		c.Header("Connection", "close")
+		c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(413, T(c, i18n.MsgBodyTooLarge, maxBytesErr.Limit)).WithRequestID(GetRequestID(c)))
+	case errors.As(err, &netErr) && netErr.Timeout():
+		c.Header("Connection", "close")
+		c.JSON(http.StatusRequestTimeout, model.NewErrorResponse(408, T(c, i18n.MsgBodyReadTimeout)).WithRequestID(GetRequestID(c)))
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgReadBodyFailed, err.Error())).WithRequestID(GetRequestID(c)))
+	}
+	return nil, false
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// RetryStatsHandler 获取各插件的请求重试统计
+func RetryStatsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(gin.H{
+		"plugins": util.GetRetryStats(),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
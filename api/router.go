@@ -3,6 +3,8 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
 	"pansou/service"
 	"pansou/util"
 )
@@ -26,10 +28,22 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 	r := gin.Default()
 	
 	// 添加中间件
+	r.Use(RequestIDMiddleware()) // 请求ID需在日志中间件之前设置
 	r.Use(CORSMiddleware())
 	r.Use(LoggerMiddleware())
+	r.Use(RequestLimitMiddleware()) // 请求大小与读写期限，需在压缩中间件之前
 	r.Use(util.GzipMiddleware()) // 添加压缩中间件
 	
+	// 链接跳转（统计点击）
+	if config.AppConfig.ClickTrackingEnabled {
+		r.GET("/go/:link_id", LinkRedirectHandler)
+	}
+	
+	// 管理后台页面（启用时注册）
+	if config.AppConfig.AdminUIEnabled {
+		r.GET("/admin", AdminUIHandler)
+	}
+	
 	// 定义API路由组
 	api := r.Group("/api")
 	{
@@ -53,18 +67,95 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 			user.GET("/stats", authHandler.GetUserStats)                    // 获取用户统计
 		}
 		
-		// 搜索接口 - 支持POST和GET两种方式（可选认证）
-		api.POST("/search", OptionalAuthMiddleware(), SearchHandler)
-		api.GET("/search", OptionalAuthMiddleware(), SearchHandler)
+		// 搜索接口 - 支持POST和GET两种方式（可选认证，配置租户时按租户隔离和限流）
+		api.POST("/search", OptionalAuthMiddleware(), TenantMiddleware(), SearchHandler)
+		api.GET("/search", OptionalAuthMiddleware(), TenantMiddleware(), SearchHandler)
 		
 		// 高级搜索接口（需要会员权限）
-		api.POST("/search/advanced", AuthMiddleware(), RequireMember(), SearchHandler)
-		api.GET("/search/advanced", AuthMiddleware(), RequireMember(), SearchHandler)
+		api.POST("/search/advanced", AuthMiddleware(), RequireMember(), TenantMiddleware(), SearchHandler)
+		api.GET("/search/advanced", AuthMiddleware(), RequireMember(), TenantMiddleware(), SearchHandler)
+		
+		// 搜索建议（启用时注册）
+		if config.AppConfig.SuggestEnabled {
+			api.GET("/suggest", SuggestHandler)
+		}
+		
+		// 按磁力链接或分享链接反查历史出现记录（启用时注册，配置租户时按租户隔离）
+		if config.AppConfig.LinkIndexEnabled {
+			api.GET("/lookup", OptionalAuthMiddleware(), TenantMiddleware(), LinkLookupHandler)
+		}
+		
+		// 插件目录（显示名称、描述、站点、支持的网盘类型）
+		api.GET("/plugins", PluginCatalogHandler)
+		
+		// 插件ext参数说明
+		api.GET("/plugins/ext", ExtSchemaHandler)
+		
+		// 插件组合及其展开后的插件
+		api.GET("/plugins/profiles", PluginProfilesHandler)
+		
+		// 支持的链接类型及最近返回的各类型链接数
+		api.GET("/cloudtypes", CloudTypesHandler)
+		
+		// 批量检测分享链接状态（可选认证，配置租户时按租户限流）
+		api.POST("/probe", OptionalAuthMiddleware(), TenantMiddleware(), LinkProbeHandler)
+		
+		// 集群工作节点接口（工作节点启用）
+		if config.AppConfig.ClusterRole == service.ClusterRoleWorker {
+			api.POST("/cluster/search", ClusterSearchHandler)
+		}
+		
+		// 缓存容量与命中统计（需要管理员权限）
+		api.GET("/cache/stats", AuthMiddleware(), RequirePermission(model.PermissionAdmin), CacheStatsHandler)
 		
 		// 搜索历史接口（需要认证）
 		api.GET("/search/history", AuthMiddleware(), SearchHistoryHandler)
 		api.DELETE("/search/history", AuthMiddleware(), ClearSearchHistoryHandler)
 		
+		// 管理接口（需要管理员权限）
+		admin := api.Group("/admin")
+		admin.Use(AuthMiddleware(), RequirePermission(model.PermissionAdmin))
+		{
+			admin.GET("/cache/write", CacheWriteStatsHandler)          // 缓存写入统计与配置
+			admin.PATCH("/cache/write", UpdateCacheWriteConfigHandler) // 运行时调整缓存写入参数
+			admin.GET("/clicks", ClickStatsHandler)                     // 链接点击统计
+			admin.GET("/connections", ConnStatsHandler)                 // 出站连接池与文件描述符统计
+			admin.GET("/concurrency", ConcurrencyStatusHandler)         // 每次搜索的并发数及自适应调整状态
+			admin.GET("/retries", RetryStatsHandler)                    // 插件请求重试统计
+			admin.GET("/content-safety", ContentSafetyStatsHandler)     // 内容安全过滤统计
+			admin.GET("/search-blacklist", SearchBlacklistStatsHandler) // 搜索黑名单拒绝统计
+			admin.GET("/cluster", ClusterStatsHandler)                  // 集群工作节点统计
+			admin.GET("/alerts", ListAlertsHandler)                     // 告警列表
+			admin.POST("/alerts/:id/ack", AckAlertHandler)              // 确认告警
+			admin.POST("/alerts/:id/resolve", ResolveAlertHandler)      // 恢复告警
+			admin.GET("/plugins", PluginStatusesHandler)                // 插件状态
+			admin.POST("/plugins/:name/enable", EnablePluginHandler)    // 启用插件
+			admin.POST("/plugins/:name/disable", DisablePluginHandler)  // 停用插件
+			admin.POST("/plugins/:name/reset", ResetPluginStateHandler) // 清除插件内部缓存
+			admin.GET("/plugins/stats", PluginStatsHandler)             // 插件按日搜索统计
+			admin.GET("/plugins/zero-hits", PluginZeroHitsHandler)      // 插件按关键词分类的结果统计
+			admin.GET("/plugins/shadow", PluginShadowHandler)           // 候选插件与线上插件的结果对比
+			admin.GET("/searches/recent", RecentSearchesHandler)        // 最近搜索
+			admin.GET("/memory", MemoryStatsHandler)                    // 内存与GC状态
+			admin.PATCH("/memory", UpdateMemorySettingsHandler)         // 运行时调整GC阈值和软内存上限
+			admin.POST("/memory/gc", ManualGCHandler)                   // 手动GC并归还空闲内存
+			admin.GET("/maintenance", MaintenanceStatusHandler)         // 维护模式状态
+			admin.PUT("/maintenance", UpdateMaintenanceHandler)         // 进入或退出维护模式
+			admin.GET("/logging", LogSettingsHandler)                   // 日志设置
+			admin.PATCH("/logging", UpdateLogSettingsHandler)           // 运行时开关详细日志和调整采样率
+			admin.POST("/logging/plugins/:name", EnablePluginDebugHandler)    // 在一段时间内输出插件的调试日志
+			admin.DELETE("/logging/plugins/:name", DisablePluginDebugHandler) // 提前关闭插件的调试日志
+			admin.GET("/selftest", SelfTestReportHandler)               // 最近一次来源自检报告
+			admin.POST("/selftest", RunSelfTestHandler)                 // 用自检关键词检查所有插件和频道
+			
+			// 频道发现（启用时注册）
+			if config.AppConfig.ChannelDiscoveryEnabled {
+				admin.GET("/channels/candidates", ListChannelCandidatesHandler)               // 候选频道列表
+				admin.POST("/channels/candidates/:name/add", AddChannelCandidateHandler)      // 添加到默认频道
+				admin.POST("/channels/candidates/:name/dismiss", DismissChannelCandidateHandler) // 忽略候选频道
+			}
+		}
+		
 		// 健康检查接口
 		api.GET("/health", func(c *gin.Context) {
 			// 根据配置决定是否返回插件信息
@@ -81,7 +172,7 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 			}
 			
 			// 获取频道信息
-			channels := config.AppConfig.DefaultChannels
+			channels := config.GetDefaultChannels()
 			channelsCount := len(channels)
 			
 			response := gin.H{
@@ -91,10 +182,43 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 				"channels_count": channelsCount,
 			}
 			
+			// 出站连接与文件描述符使用概况
+			connStats := util.GetConnStats()
+			response["connections"] = gin.H{
+				"budget":   connStats.Budget,
+				"open":     connStats.Open,
+				"fd_open":  connStats.FDOpen,
+				"fd_limit": connStats.FDLimit,
+			}
+			
+			// 搜索结果缓存命中统计
+			response["search_cache"] = service.GetSearchCacheStats()
+			
+			// 维护模式状态
+			response["maintenance"] = service.GetMaintenanceStatus()
+			
+			// 启动自检结果
+			if report := service.GetStartupReport(); report != nil {
+				response["self_check"] = report
+			}
+			
 			// 只有当插件启用时才返回插件相关信息
 			if pluginsEnabled {
 				response["plugin_count"] = pluginCount
 				response["plugins"] = pluginNames
+				
+				// 发生过panic的插件及隔离状态
+				quarantined := plugin.GetQuarantinedPlugins()
+				if quarantined == nil {
+					quarantined = []string{}
+				}
+				response["quarantined_plugins"] = quarantined
+				response["plugin_panics"] = plugin.GetPluginHealth()
+				response["plugin_fallbacks"] = plugin.GetFallbackStats()
+				
+				// 失效站点及因此降级的插件
+				response["dead_domains"] = util.GetDeadDomains()
+				response["degraded_plugins"] = util.GetDegradedPlugins()
 			}
 			
 			c.JSON(200, response)
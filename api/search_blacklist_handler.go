@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	jsonutil "pansou/util/json"
+)
+
+// SearchBlacklistStatsHandler 获取搜索黑名单的规则数、拒绝统计和最近被拒绝的搜索
+func SearchBlacklistStatsHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(service.GetSearchBlacklist().Stats())
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+)
+
+// resolvedFilter 过滤条件中不对应顶层参数的部分，搜索完成后作用于响应
+type resolvedFilter struct {
+	from    time.Time
+	to      time.Time
+	ranking string
+}
+
+// applySearchFilter 校验请求体中的filter对象，并将来源、网盘类型、语言、最低分辨率和分页条件合并到请求参数中。
+// filter中的条件与同名顶层参数同时指定时返回错误，错误信息包含出错字段的路径
+func applySearchFilter(req *model.SearchRequest, now time.Time) (resolvedFilter, error) {
+	resolved := resolvedFilter{ranking: model.RankingDefault}
+	filter := req.Filter
+	if filter == nil {
+		return resolved, nil
+	}
+
+	if src := filter.Sources; src != nil {
+		switch src.Type {
+		case "", "all", "tg", "plugin":
+		default:
+			return resolved, fmt.Errorf("filter.sources.type 取值无效: %q，可选值为 all、tg、plugin", src.Type)
+		}
+		if err := mergeFilterField("filter.sources.type", "src", &req.SourceType, src.Type); err != nil {
+			return resolved, err
+		}
+		if err := mergeFilterList("filter.sources.channels", "channels", &req.Channels, src.Channels); err != nil {
+			return resolved, err
+		}
+		if err := mergeFilterList("filter.sources.plugins", "plugins", &req.Plugins, src.Plugins); err != nil {
+			return resolved, err
+		}
+		if err := mergeFilterField("filter.sources.profile", "profile", &req.Profile, src.Profile); err != nil {
+			return resolved, err
+		}
+	}
+
+	for i, cloudType := range filter.CloudTypes {
+		if !util.IsKnownCloudType(cloudType) {
+			return resolved, fmt.Errorf("filter.cloud_types[%d] 取值无效: %q，支持的类型: %s", i, cloudType, strings.Join(util.CloudTypeNames(), "、"))
+		}
+	}
+	if err := mergeFilterList("filter.cloud_types", "cloud_types", &req.CloudTypes, filter.CloudTypes); err != nil {
+		return resolved, err
+	}
+
+	for i, lang := range filter.Languages {
+		if _, ok := util.NormalizeLanguage(lang); !ok {
+			return resolved, fmt.Errorf("filter.lang[%d] 取值无效: %q，可选值为 zh、zh-CN、zh-TW、en、jp", i, lang)
+		}
+	}
+	if err := mergeFilterList("filter.lang", "lang", &req.Languages, filter.Languages); err != nil {
+		return resolved, err
+	}
+
+	if filter.MinResolution != "" && util.NormalizeResolution(filter.MinResolution) == "" {
+		return resolved, fmt.Errorf("filter.min_resolution 取值无效: %q，可选值为 480p、720p、1080p、2160p（4k）、4320p（8k）", filter.MinResolution)
+	}
+	if err := mergeFilterField("filter.min_resolution", "min_resolution", &req.MinResolution, filter.MinResolution); err != nil {
+		return resolved, err
+	}
+
+	if page := filter.Page; page != nil {
+		if page.Size < 0 || page.Size > service.MaxPageSize {
+			return resolved, fmt.Errorf("filter.page.size 超出范围: %d，取值范围为 0~%d", page.Size, service.MaxPageSize)
+		}
+		if page.Size > 0 {
+			if req.PageSize > 0 {
+				return resolved, fmt.Errorf("filter.page.size 与顶层参数 page_size 不能同时指定")
+			}
+			req.PageSize = page.Size
+		}
+		if err := mergeFilterField("filter.page.token", "page_token", &req.PageToken, page.Token); err != nil {
+			return resolved, err
+		}
+	}
+
+	if tr := filter.TimeRange; tr != nil {
+		from, to, err := parseTimeRange(tr, now)
+		if err != nil {
+			return resolved, err
+		}
+		resolved.from, resolved.to = from, to
+	}
+
+	switch filter.Ranking {
+	case "", model.RankingDefault:
+	case model.RankingTime, model.RankingSize:
+		resolved.ranking = filter.Ranking
+	default:
+		return resolved, fmt.Errorf("filter.ranking 取值无效: %q，可选值为 default、time、size", filter.Ranking)
+	}
+
+	return resolved, nil
+}
+
+// mergeFilterField 将过滤条件中的字符串字段写入对应的顶层参数
+func mergeFilterField(path, topLevel string, target *string, value string) error {
+	if value == "" {
+		return nil
+	}
+	if *target != "" && *target != value {
+		return fmt.Errorf("%s 与顶层参数 %s 不能同时指定", path, topLevel)
+	}
+	*target = value
+	return nil
+}
+
+// mergeFilterList 将过滤条件中的列表字段写入对应的顶层参数
+func mergeFilterList(path, topLevel string, target *[]string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if len(*target) > 0 {
+		return fmt.Errorf("%s 与顶层参数 %s 不能同时指定", path, topLevel)
+	}
+	*target = values
+	return nil
+}
+
+// parseTimeRange 解析发布时间范围
+func parseTimeRange(tr *model.TimeRangeFilter, now time.Time) (from, to time.Time, err error) {
+	if tr.Within != "" {
+		if tr.From != "" {
+			return from, to, fmt.Errorf("filter.time_range.within 与 filter.time_range.from 不能同时指定")
+		}
+		d, err := parseRelativeDuration(tr.Within)
+		if err != nil {
+			return from, to, fmt.Errorf("filter.time_range.within 格式无效: %q，应为正的时长，如 \"24h\"、\"7d\"", tr.Within)
+		}
+		from = now.Add(-d)
+	}
+	if tr.From != "" {
+		if from, err = parseFilterTime(tr.From, false); err != nil {
+			return from, to, fmt.Errorf("filter.time_range.from 格式无效: %q，应为RFC3339时间或YYYY-MM-DD日期", tr.From)
+		}
+	}
+	if tr.To != "" {
+		if to, err = parseFilterTime(tr.To, true); err != nil {
+			return from, to, fmt.Errorf("filter.time_range.to 格式无效: %q，应为RFC3339时间或YYYY-MM-DD日期", tr.To)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, fmt.Errorf("filter.time_range 无效: 开始时间 %s 晚于结束时间 %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	return from, to, nil
+}
+
+// parseFilterTime 解析RFC3339时间或YYYY-MM-DD日期，日期作为结束时间时取当天结束
+func parseFilterTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseRelativeDuration 解析相对时长，在time.ParseDuration的基础上支持天（d）
+func parseRelativeDuration(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration")
+	}
+	return d, nil
+}
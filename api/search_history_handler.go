@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"pansou/model"
+	"pansou/util/i18n"
 	jsonutil "pansou/util/json"
 )
 
@@ -24,7 +25,7 @@ type SearchHistoryEntry struct {
 func SearchHistoryHandler(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
@@ -56,7 +57,7 @@ func SearchHistoryHandler(c *gin.Context) {
 func ClearSearchHistoryHandler(c *gin.Context) {
 	user := GetCurrentUser(c)
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, "需要认证"))
+		c.JSON(http.StatusUnauthorized, model.NewErrorResponse(401, T(c, i18n.MsgAuthRequired)))
 		return
 	}
 
@@ -65,7 +66,7 @@ func ClearSearchHistoryHandler(c *gin.Context) {
 
 	// 返回成功信息
 	response := model.NewSuccessResponse(gin.H{
-		"message": "搜索历史已清空",
+		"message": T(c, i18n.MsgHistoryCleared),
 	})
 	
 	jsonData, _ := jsonutil.Marshal(response)
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// SelfTestReportHandler 获取最近一次来源自检报告，尚未执行过自检时为null
+func SelfTestReportHandler(c *gin.Context) {
+	response := model.NewSuccessResponse(gin.H{
+		"report": service.GetLastSelfTestReport(),
+	})
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
+
+// RunSelfTestHandler 用自检关键词搜索所有已启用的插件和默认频道，返回每个来源是否可访问、
+// 解析出的结果数和链接数。站点改版后可用于确认哪些插件需要修复
+func RunSelfTestHandler(c *gin.Context) {
+	// 请求体可以省略
+	var req model.SelfTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgInvalidParams, err.Error())))
+		return
+	}
+	switch req.SourceType {
+	case "", "all", "tg", "plugin":
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSearchInvalidSource)))
+		return
+	}
+
+	report := searchService.RunSelfTest(req.Keyword, req.SourceType, time.Duration(req.Timeout)*time.Second)
+	response := model.NewSuccessResponse(report)
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
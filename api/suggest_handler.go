@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util"
+	"pansou/util/i18n"
+	jsonutil "pansou/util/json"
+)
+
+// 搜索建议返回条数的默认值和上限
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// SuggestHandler 搜索建议：按输入q返回补全的关键词及次数，limit控制返回条数（默认10，最多50）
+func SuggestHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, T(c, i18n.MsgSuggestQueryRequired)).WithRequestID(GetRequestID(c)))
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if n := util.StringToInt(c.Query("limit")); n > 0 {
+		limit = n
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	response := model.NewSuccessResponse(gin.H{
+		"query":       query,
+		"suggestions": service.GetSuggestService().Suggest(query, limit),
+	})
+
+	jsonData, _ := jsonutil.Marshal(response)
+	c.Data(http.StatusOK, "application/json", jsonData)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"pansou/model"
+	"pansou/service"
+	"pansou/util/i18n"
+)
+
+// 租户识别请求头
+const (
+	TenantIDHeader = "X-Tenant-ID"
+	APIKeyHeader   = "X-API-Key"
+)
+
+// TenantMiddleware 租户中间件：根据X-API-Key或X-Tenant-ID识别租户并按租户限流，
+// 识别出的租户写入请求上下文，搜索服务据此隔离缓存和插件。未配置租户时不做任何处理
+func TenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		registry := service.GetTenantRegistry()
+		if !registry.Enabled() {
+			c.Next()
+			return
+		}
+
+		tenant, err := registry.Resolve(strings.TrimSpace(c.GetHeader(TenantIDHeader)), strings.TrimSpace(c.GetHeader(APIKeyHeader)))
+		if err != nil {
+			status := http.StatusUnauthorized
+			if err == service.ErrUnknownTenant {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, model.NewErrorResponse(status, T(c, i18n.MsgTenantResolveFailed, localizeError(c, err))).WithRequestID(GetRequestID(c)))
+			c.Abort()
+			return
+		}
+		if tenant == nil {
+			c.Next()
+			return
+		}
+
+		if allowed, wait := registry.Allow(tenant); !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, model.NewErrorResponse(http.StatusTooManyRequests, T(c, i18n.MsgRateLimited)).WithRequestID(GetRequestID(c)))
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_id", tenant.ID)
+		c.Request = c.Request.WithContext(service.WithTenant(c.Request.Context(), tenant))
+		c.Next()
+	}
+}
+
+// tenantID 请求识别出的租户ID，未识别租户时为空
+func tenantID(c *gin.Context) string {
+	if tenant := service.TenantFromContext(c.Request.Context()); tenant != nil {
+		return tenant.ID
+	}
+	return ""
+}
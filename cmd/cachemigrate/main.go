@@ -0,0 +1,65 @@
+// cachemigrate 磁盘缓存存储后端迁移工具：将一个后端中所有未过期的缓存项按剩余有效期复制到另一个后端。
+// 缓存项按存储的原始数据复制，启用了CACHE_ENCRYPTION_KEY时迁移后仍使用原密钥解密。
+// 迁移前需停止服务，bbolt和badger数据库同一时间只能由一个进程打开。
+//
+// 典型用法：
+//
+//	go run ./cmd/cachemigrate -path ./cache -from file -to bbolt
+//	go run ./cmd/cachemigrate -path ./cache -from bbolt -to badger -max-size 500
+//	go run ./cmd/cachemigrate -path ./cache -from file -to badger -clear-source
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"pansou/util/cache"
+)
+
+func main() {
+	path := flag.String("path", "./cache", "缓存目录，与服务的CACHE_PATH一致")
+	from := flag.String("from", cache.StoreFile, "源存储后端：file、bbolt、badger")
+	to := flag.String("to", cache.StoreBbolt, "目标存储后端：file、bbolt、badger")
+	maxSizeMB := flag.Int("max-size", 100, "目标存储的最大容量（MB），与服务的CACHE_MAX_SIZE一致")
+	clearSource := flag.Bool("clear-source", false, "迁移完成后清空源存储")
+	flag.Parse()
+
+	if *from == *to {
+		fmt.Fprintln(os.Stderr, "源存储和目标存储相同，无需迁移")
+		os.Exit(2)
+	}
+
+	// 源存储不限制容量，避免打开时按容量淘汰缓存项
+	src, err := cache.OpenStore(*from, *path, 1<<20)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开源存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer src.Close()
+
+	dst, err := cache.OpenStore(*to, *path, *maxSizeMB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开目标存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer dst.Close()
+
+	start := time.Now()
+	count, err := cache.CopyStore(dst, src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "迁移失败（已复制 %d 项）: %v\n", count, err)
+		os.Exit(1)
+	}
+	fmt.Printf("已将 %d 个缓存项从 %s 迁移到 %s，耗时 %v\n", count, *from, *to, time.Since(start).Round(time.Millisecond))
+
+	if *clearSource {
+		if err := src.Clear(); err != nil {
+			fmt.Fprintf(os.Stderr, "清空源存储失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("已清空源存储 %s\n", *from)
+	}
+	fmt.Printf("启动服务时设置 CACHE_STORE=%s 使用新的存储后端\n", *to)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 未指定关键词文件时使用的示例分布：少数热门关键词占大部分请求
+var defaultKeywords = []weightedKeyword{
+	{"速度与激情", 40}, {"庆余年", 30}, {"繁花", 20}, {"三体", 15},
+	{"流浪地球", 10}, {"黑神话", 8}, {"周杰伦", 5}, {"Python教程", 3},
+	{"Photoshop", 2}, {"纪录片", 1},
+}
+
+// weightedKeyword 关键词及其在记录中出现的次数
+type weightedKeyword struct {
+	keyword string
+	weight  int
+}
+
+// loadKeywords 读取关键词分布文件：每行一个关键词，可用制表符分隔出现次数（默认1），
+// 空行和#开头的行忽略。可直接使用搜索日志导出的"关键词\t次数"
+func loadKeywords(path string) ([]weightedKeyword, error) {
+	if path == "" {
+		return defaultKeywords, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keywords []weightedKeyword
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, weight := line, 1
+		if i := strings.LastIndex(line, "\t"); i >= 0 {
+			n, err := strconv.Atoi(strings.TrimSpace(line[i+1:]))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("第%d行的次数无效: %q", lineNo, line[i+1:])
+			}
+			keyword, weight = strings.TrimSpace(line[:i]), n
+		}
+		if keyword != "" {
+			keywords = append(keywords, weightedKeyword{keyword, weight})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("关键词文件为空: %s", path)
+	}
+	return keywords, nil
+}
+
+// keywordSampler 按出现次数加权随机抽取关键词，重放记录中的关键词分布
+type keywordSampler struct {
+	keywords   []string
+	cumulative []int
+	total      int
+}
+
+func newKeywordSampler(keywords []weightedKeyword) *keywordSampler {
+	s := &keywordSampler{}
+	for _, k := range keywords {
+		s.total += k.weight
+		s.keywords = append(s.keywords, k.keyword)
+		s.cumulative = append(s.cumulative, s.total)
+	}
+	return s
+}
+
+// Next 抽取一个关键词，rng不是并发安全的，由调用方为每个工作协程单独创建
+func (s *keywordSampler) Next(rng *rand.Rand) string {
+	n := rng.Intn(s.total)
+	return s.keywords[sort.SearchInts(s.cumulative, n+1)]
+}
@@ -0,0 +1,274 @@
+// loadtest 搜索接口压测工具：按记录的关键词分布重放搜索请求，统计延迟分位数和缓存命中率；
+// 可启动模拟TG频道服务作为搜索后端，也可在进程内统计结果合并和链接标题提取的内存分配。
+//
+// 典型用法：
+//
+//	go run ./cmd/loadtest -mock-tg :9099 -target ""                  # 只启动模拟频道服务
+//	TG_BASE_URL=http://127.0.0.1:9099/s/ CHANNELS=ch1,ch2 go run .   # 让服务使用模拟频道
+//	go run ./cmd/loadtest -target http://127.0.0.1:8888 -n 2000 -c 20
+//	go run ./cmd/loadtest -profile                                   # 统计管线内存分配
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	"pansou/testsupport"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+func main() {
+	target := flag.String("target", "http://127.0.0.1:8888", "被测服务地址，为空时只启动模拟频道服务")
+	keywordsFile := flag.String("keywords", "", "关键词分布文件，每行\"关键词\\t次数\"，为空时使用内置示例")
+	requests := flag.Int("n", 1000, "总请求数（指定-duration时忽略）")
+	concurrency := flag.Int("c", 10, "并发数")
+	duration := flag.Duration("duration", 0, "压测持续时间")
+	sourceType := flag.String("src", "tg", "搜索来源：all、tg、plugin")
+	resultType := flag.String("res", "merge", "结果类型：all、results、merge、flat")
+	seed := flag.Int64("seed", 1, "关键词抽样的随机种子")
+	mockAddr := flag.String("mock-tg", "", "启动模拟TG频道服务的监听地址，如:9099")
+	mockLatency := flag.Duration("mock-latency", 50*time.Millisecond, "模拟频道服务的响应延迟")
+	mockPages := flag.Int("mock-pages", 2, "模拟频道每个关键词的页数")
+	mockPerPage := flag.Int("mock-per-page", 20, "模拟频道每页的消息数")
+	profile := flag.Bool("profile", false, "在进程内统计结果合并和链接标题提取的耗时与内存分配后退出")
+	profileIterations := flag.Int("profile-iterations", 2000, "内存分配统计的迭代次数")
+	flag.Parse()
+
+	mockOptions := testsupport.TGServerOptions{Latency: *mockLatency, PerPage: *mockPerPage, Pages: *mockPages}
+
+	if *profile {
+		runProfile(*mockPerPage, *mockPages, *profileIterations)
+		return
+	}
+
+	if *mockAddr != "" {
+		server := &http.Server{Addr: *mockAddr, Handler: testsupport.NewTGHandler(mockOptions)}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "模拟频道服务启动失败: %v\n", err)
+				os.Exit(1)
+			}
+		}()
+		fmt.Printf("模拟频道服务已启动: http://%s/s/<频道>\n", *mockAddr)
+	}
+
+	if *target == "" {
+		if *mockAddr == "" {
+			fmt.Fprintln(os.Stderr, "未指定-target和-mock-tg，没有可执行的操作")
+			os.Exit(2)
+		}
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt)
+		<-quit
+		return
+	}
+
+	keywords, err := loadKeywords(*keywordsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取关键词失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	run := &loadRun{
+		target:     strings.TrimRight(*target, "/"),
+		sampler:    newKeywordSampler(keywords),
+		sourceType: *sourceType,
+		resultType: *resultType,
+		client:     &http.Client{Timeout: 60 * time.Second},
+	}
+	before, _ := run.fetchCacheStats()
+	report := run.execute(*requests, *concurrency, *duration, *seed)
+	after, err := run.fetchCacheStats()
+	report.print()
+	printCacheStats(before, after, err)
+}
+
+// loadRun 一次压测的参数
+type loadRun struct {
+	target     string
+	sampler    *keywordSampler
+	sourceType string
+	resultType string
+	client     *http.Client
+}
+
+// loadReport 压测结果
+type loadReport struct {
+	elapsed   time.Duration
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    int
+}
+
+// execute 用concurrency个协程发送请求，duration>0时按时间结束，否则发送total个请求
+func (r *loadRun) execute(total int, concurrency int, duration time.Duration, seed int64) *loadReport {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		remaining = int64(total)
+		deadline  time.Time
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		report    = &loadReport{statuses: make(map[int]int)}
+	)
+	if duration > 0 {
+		deadline = time.Now().Add(duration)
+	}
+
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(rng *rand.Rand) {
+			defer wg.Done()
+			for {
+				if duration > 0 {
+					if time.Now().After(deadline) {
+						return
+					}
+				} else if atomic.AddInt64(&remaining, -1) < 0 {
+					return
+				}
+
+				latency, status, err := r.search(r.sampler.Next(rng))
+				mu.Lock()
+				if err != nil {
+					report.errors++
+				} else {
+					report.latencies = append(report.latencies, latency)
+					report.statuses[status]++
+				}
+				mu.Unlock()
+			}
+		}(rand.New(rand.NewSource(seed + int64(w))))
+	}
+	wg.Wait()
+	report.elapsed = time.Since(start)
+	return report
+}
+
+// search 发送一次搜索请求并读完响应体
+func (r *loadRun) search(keyword string) (time.Duration, int, error) {
+	query := url.Values{}
+	query.Set("kw", keyword)
+	query.Set("src", r.sourceType)
+	query.Set("res", r.resultType)
+
+	start := time.Now()
+	resp, err := r.client.Get(r.target + "/api/search?" + query.Encode())
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, 0, err
+	}
+	return time.Since(start), resp.StatusCode, nil
+}
+
+// fetchCacheStats 从健康检查接口读取搜索缓存命中统计
+func (r *loadRun) fetchCacheStats() (map[string]service.SearchCacheStats, error) {
+	resp, err := r.client.Get(r.target + "/api/health")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var health struct {
+		SearchCache map[string]service.SearchCacheStats `json:"search_cache"`
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := jsonutil.Unmarshal(data, &health); err != nil {
+		return nil, err
+	}
+	return health.SearchCache, nil
+}
+
+func (report *loadReport) print() {
+	sort.Slice(report.latencies, func(i, j int) bool { return report.latencies[i] < report.latencies[j] })
+	completed := len(report.latencies)
+	fmt.Printf("请求数: %d  失败: %d  耗时: %v  QPS: %.1f\n", completed+report.errors, report.errors,
+		report.elapsed.Round(time.Millisecond), float64(completed)/report.elapsed.Seconds())
+
+	codes := make([]int, 0, len(report.statuses))
+	for code := range report.statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Printf("HTTP %d: %d\n", code, report.statuses[code])
+	}
+	if completed == 0 {
+		return
+	}
+	fmt.Printf("延迟 P50: %v  P95: %v  P99: %v  最大: %v\n",
+		percentile(report.latencies, 0.50), percentile(report.latencies, 0.95),
+		percentile(report.latencies, 0.99), report.latencies[completed-1])
+}
+
+// percentile 已排序延迟的分位数（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted))*p+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index].Round(time.Microsecond)
+}
+
+// printCacheStats 输出压测期间的缓存命中率（压测前后统计的差值）
+func printCacheStats(before, after map[string]service.SearchCacheStats, err error) {
+	if err != nil {
+		fmt.Printf("缓存命中率: 无法读取健康检查接口 (%v)\n", err)
+		return
+	}
+	for _, source := range []string{"tg", "plugin"} {
+		lookups := after[source].Lookups - before[source].Lookups
+		hits := after[source].Hits - before[source].Hits
+		if lookups <= 0 {
+			continue
+		}
+		fmt.Printf("缓存命中率 %s: %.1f%% (%d/%d)\n", source, float64(hits)*100/float64(lookups), hits, lookups)
+	}
+}
+
+// runProfile 用模拟频道页解析出的结果统计结果合并和链接标题提取的耗时与内存分配
+func runProfile(perPage int, pages int, iterations int) {
+	config.Init()
+	keyword := defaultKeywords[0].keyword
+	var results []model.SearchResult
+	for _, channel := range []string{"mock_a", "mock_b", "mock_c"} {
+		for page := 0; page < pages; page++ {
+			pageResults, _, err := util.ParseSearchResults(testsupport.RenderChannelPage(channel, keyword, page, perPage, pages, time.Now()), channel)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "解析模拟频道页失败: %v\n", err)
+				os.Exit(1)
+			}
+			results = append(results, pageResults...)
+		}
+	}
+
+	fmt.Printf("结果数: %d  迭代次数: %d\n", len(results), iterations)
+	for _, p := range service.ProfilePipeline(results, keyword, iterations) {
+		fmt.Printf("%-24s %10d ns/op %10.1f allocs/op %12.0f B/op\n", p.Name, p.NsPerOp, p.AllocsPerOp, p.BytesPerOp)
+	}
+}
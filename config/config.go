@@ -7,6 +7,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,12 +23,18 @@ type Config struct {
 	CachePath       string
 	CacheMaxSizeMB  int
 	CacheTTLMinutes int
+	CacheEncryptionKey     string   // 磁盘缓存加密密钥（为空则不加密）
+	CacheEncryptionOldKeys []string // 轮换前的旧密钥，仅用于解密
+	CacheTTLFinal          time.Duration // 等级1插件最终结果的缓存有效期
+	CacheTTLPartial        time.Duration // 部分或超时结果的缓存有效期
+	CacheStore             string        // 磁盘缓存存储后端：file、bbolt、badger
 	// 压缩相关配置
 	EnableCompression bool
 	MinSizeToCompress int // 最小压缩大小（字节）
 	// GC相关配置
 	GCPercent      int  // GC触发阈值百分比
 	OptimizeMemory bool // 是否启用内存优化
+	MemoryLimitMB  int  // 软内存上限（MB），0表示不限制
 	// 插件相关配置
 	PluginTimeoutSeconds int           // 插件超时时间（秒）
 	PluginTimeout        time.Duration // 插件超时时间（Duration）
@@ -45,12 +52,211 @@ type Config struct {
 	HTTPWriteTimeout time.Duration // 写入超时
 	HTTPIdleTimeout  time.Duration // 空闲超时
 	HTTPMaxConns     int           // 最大连接数
+	HTTPConnBudget   int           // 出站连接总预算（按插件分配连接池配额），0表示按文件描述符上限自动计算
+	PluginRetryBudget int          // 单次插件搜索可用的重试次数，0表示不限制
+	DetailFetchMax    int           // 单次插件搜索最多抓取的详情页数，0表示不限制
+	DetailFetchTimeout time.Duration // 单次插件搜索抓取详情页的总时长上限，0表示不限制
+	CookieJarPlugins  []string      // 启用持久化Cookie的插件，"*"表示全部插件
+	CookieJarKey      string        // Cookie文件加密密钥，为空时明文保存
+	PluginPanicThreshold     int           // 统计窗口内panic达到该次数时隔离插件，0表示不自动隔离
+	PluginPanicWindow        time.Duration // 插件panic统计窗口
+	PluginQuarantineDuration time.Duration // 插件隔离时长
+	PluginSchedules          map[string][]string // 插件的启用时段（cron表达式），未配置的插件始终启用
+	// 链接点击统计配置
+	ClickTrackingEnabled bool // 是否为合并链接生成跳转ID并统计点击
+	// 频道发现配置
+	ChannelDiscoveryEnabled  bool          // 是否从TG搜索结果中发现候选频道
+	ChannelDiscoveryInterval time.Duration // 候选频道探测间隔
+	// 内容安全过滤配置
+	ContentBlockPatterns     []string      // 标题屏蔽正则，匹配的结果在写入缓存前丢弃
+	ContentBlockDomains      []string      // 屏蔽的链接域名（含子域名）
+	DomainReputationURL      string        // 域名信誉列表地址，为空时只使用ContentBlockDomains
+	DomainReputationInterval time.Duration // 域名信誉列表更新间隔
+	// 多租户配置
+	TenantsFile string // 租户配置文件（JSON），为空时不启用租户
+	// 集群模式配置
+	ClusterRole    string   // 集群角色：coordinator（协调节点）、worker（工作节点），为空表示单机模式
+	ClusterWorkers []string // 协调节点使用的工作节点地址
+	ClusterSecret  string   // 节点间请求使用的共享密钥，为空时工作节点不校验
+	// 出站源地址配置
+	SourceAddresses []string // 出站连接的本地源地址池，按目标主机轮询绑定
+	// 搜索黑名单配置
+	SearchBlacklistFile string   // 黑名单规则文件（JSON）
+	SearchBlockKeywords []string // 禁止搜索的关键词
+	SearchAuditLog      string   // 被拒绝搜索的审计日志文件，为空时不写文件
+	// 启动自检配置
+	FailOnMiswire bool // 启动自检发现缓存链路未正确连接时是否退出
+	// TG频道分页配置
+	TGPageDepth         int            // 每个频道默认抓取的搜索结果页数
+	TGChannelPageDepths map[string]int // 按频道覆盖的页数
+	TGBaseURL           string         // 频道搜索页地址前缀，压测或集成测试时可指向模拟服务
+	// 频道专用解析器配置
+	ChannelParsers map[string]string // 频道（小写） -> 解析器名称
+	// 排序配置
+	RankingProfileFile string // 排序配置文件（JSON），可按内容分类覆盖时间衰减曲线
+	// 已见链接索引配置
+	SeenLinksEnabled bool          // 是否标记关键词上次搜索后新出现的链接
+	SeenLinksMax     int           // 每个关键词最多记录的链接数
+	SeenLinksTTL     time.Duration // 关键词索引的有效期
+	// 插件预设配置
+	PluginPreset string // 未设置ENABLED_PLUGINS时使用的插件预设：default、all
+	// 多语言配置
+	APIDefaultLang string // 请求未携带可识别的Accept-Language时接口消息使用的语言：zh、en
+	LogLang        string // 运维日志使用的语言：zh、en，与接口消息语言相互独立
+	// 来源可信度配置
+	SourceTrustLevels  map[string]string // 来源（tg:频道、plugin:插件，小写，支持tg:*、plugin:*） -> 可信度
+	SourceTrustDefault string            // 未配置来源的可信度：trusted、standard、community
+	// 插件缓存快照配置
+	PluginCacheSnapshotEnabled bool // 关闭时是否保存插件API响应缓存快照并在启动时恢复
+	PluginCacheSnapshotMax     int  // 快照最多保存的条目数（按最近使用排序）
+	// 响应大小配置
+	ResponseLinkCap int // 单次响应最多返回的链接数（results为结果条数），超出部分按排序截断，0表示不限制
+	// 搜索建议配置
+	SuggestEnabled    bool // 是否记录搜索关键词和结果标题并提供搜索建议接口
+	SuggestMaxEntries int  // 关键词和标题片段各最多保留的候选词数
+	// 平滑升级配置
+	UpgradeTimeout time.Duration // 等待新进程就绪以及旧进程处理完进行中请求的超时时间
+	PIDFile        string        // 当前服务进程的PID文件，升级后由新进程覆盖，为空时不写入
+	// 监听方式配置
+	UnixSocket       string      // 监听的Unix套接字路径，设置后代替TCP端口，供本机反向代理使用
+	UnixSocketMode   os.FileMode // Unix套接字文件权限
+	SocketActivation bool        // 是否使用systemd套接字激活（LISTEN_FDS）传入的监听套接字
+
+	// 管理后台配置
+	AdminUIEnabled bool // 是否提供/admin管理后台页面
+
+	// 结果后处理配置
+	PostProcessors []string // 结果合并排序后依次执行的后处理步骤
+
+	// 缓存复制配置（S3兼容对象存储）
+	CacheS3Endpoint     string        // 对象存储地址，与存储桶都设置时启用缓存复制
+	CacheS3Region       string        // 区域
+	CacheS3Bucket       string        // 存储桶
+	CacheS3AccessKey    string        // 访问密钥ID
+	CacheS3SecretKey    string        // 访问密钥
+	CacheS3Prefix       string        // 对象键前缀
+	CacheS3PathStyle    bool          // 是否使用路径风格访问存储桶
+	CacheS3SyncInterval time.Duration // 同步间隔
+
+	// 关键词分类配置
+	QueryCategoryRouting bool                // 是否按关键词分类只搜索可能有结果的插件
+	PluginCategories     map[string][]string // 插件名（小写） -> 擅长的内容分类，覆盖插件自身声明的分类
+
+	// 插件延迟预算配置
+	PluginLevelBudgets     map[int]float64          // 插件等级 -> 可使用的插件超时窗口比例
+	PluginTimeoutOverrides map[string]time.Duration // 插件名（小写） -> 单独的超时时间，优先于等级预算
+
+	// 插件分批搜索配置
+	PluginTwoWave         bool           // 是否分两批搜索插件：第一批的结果立即返回，第二批在后台执行只更新缓存
+	PluginFirstWaveLevels map[int]bool   // 属于第一批的插件等级
+	PluginWaveOverrides   map[string]int // 插件名（小写） -> 所属批次（1或2），优先于按等级划分
+
+	// 链接检测配置
+	LinkProbeMaxURLs  int           // /api/probe单次请求最多检测的链接数
+	LinkProbeRate     float64       // 每种网盘每秒最多发出的检测请求数，0表示不限制
+	LinkProbeCacheTTL time.Duration // 检测结果的缓存时间
+
+	// 缓存滑动过期配置
+	CacheSlidingTTL    bool          // 命中缓存时将有效期从当前时间重新计算
+	CacheSlidingMaxAge time.Duration // 缓存数据自写入起的最长保留时间，滑动过期不会超过该时间
+	CacheRefreshAfter  time.Duration // 命中的缓存数据写入超过该时间后在后台重新搜索
+
+	// 语义重排配置
+	RerankEmbeddingURL   string        // OpenAI兼容的向量接口地址，为空时使用内置的字符n-gram向量
+	RerankEmbeddingModel string        // 向量接口使用的模型名
+	RerankEmbeddingKey   string        // 向量接口的API Key
+	RerankTopK           int           // 参与重排的前K条结果（每种网盘类型的前K条链接）
+	RerankTimeout        time.Duration // 重排的延迟预算，超时时保持原排序
+
+	// 客户端跳转链接配置
+	DeepLinkTemplatesFile string // 跳转链接模板文件（JSON），按网盘类型配置deep_links=true时生成的链接
+
+	// 插件按主机限流配置
+	HostMaxConcurrency    int                      // 所有插件对同一主机（组）同时进行的请求数上限，0表示不限制
+	HostRequestInterval   time.Duration            // 所有插件对同一主机（组）相邻两次请求的最小间隔
+	HostGroups            map[string]string        // 域名（小写，含子域名） -> 主机组，同组的域名共享并发上限和请求间隔
+	HostConcurrencyLimits map[string]int           // 主机组或域名 -> 单独的并发上限
+	HostIntervals         map[string]time.Duration // 主机组或域名 -> 单独的请求间隔
+
+	// 失效站点配置
+	DeadDomainMinFailures   int           // 站点（主机组）连续失败达到该次数且持续DeadDomainAfter时判定为失效，0表示不检测
+	DeadDomainAfter         time.Duration // 站点持续失败多久后判定为失效
+	DeadDomainRetryInterval time.Duration // 依赖的站点全部失效的插件被降级，降级期间每隔该时间放行一次搜索以探测恢复
+
+	// 合并结果缓存配置
+	MergeMemoTTL        time.Duration // 相同结果和合并选项的合并链接缓存时间，0表示不缓存
+	MergeMemoMaxEntries int           // 最多缓存的合并结果数
+
+	// 请求大小与读写期限配置
+	MaxQueryLength       int           // 查询字符串的最大字节数，0表示不限制
+	MaxBodySize          int64         // 请求体的最大字节数，0表示不限制
+	BodyReadTimeout      time.Duration // 读取请求体的期限，0表示只受HTTP_READ_TIMEOUT限制
+	ResponseWriteTimeout time.Duration // 响应开始写入后写完的期限，0表示只受HTTP_WRITE_TIMEOUT限制
+
+	// 链接反查配置
+	LinkIndexEnabled bool          // 是否记录链接在搜索结果中的出现，用于按磁力链接或分享链接反查
+	LinkIndexTTL     time.Duration // 链接出现记录的保留时间
+
+	// 插件连接预热配置
+	PluginWarmupEnabled     bool          // 启动时是否预先建立到插件站点的连接
+	PluginWarmupConcurrency int           // 同时预热的插件数
+	PluginWarmupTimeout     time.Duration // 每个插件预热的超时时间
+
+	// 维护模式配置
+	MaintenanceMode    bool   // 启动时是否处于维护模式（只返回缓存结果，不请求TG频道和插件），可在管理接口中切换
+	MaintenanceMessage string // 维护模式下搜索响应中的提示，为空时使用默认提示
+
+	// 关键词翻译配置
+	KeywordTranslation bool              // 是否用片名对照表将中文关键词翻译为英文（或反之），用译名搜索英文站点插件
+	TitleAliasFile     string            // 中英文片名对照表文件（JSON）
+	PluginLanguages    map[string]string // 插件名（小写） -> 站点标题的语言（zh、en），覆盖插件自身声明的语言
+
+	// 结果持久化配置
+	NoPersistSources []string // 结果不写入磁盘缓存和持久化存储的来源（plugin:插件名、tg:频道名），只保存在内存中
+
+	// 发现类请求缓存配置
+	DiscoveryCacheTTL time.Duration // 插件获取buildId、扫描JS文件等发现类请求的响应缓存时间，为0时不缓存
+
+	// 来源自检配置
+	SelfTestKeyword string // 来源自检默认使用的关键词，应为各站点都能搜到的热门片名
+
+	// 自适应并发配置
+	AdaptiveConcurrency bool // 是否按近期错误率、超时率和CPU占用自动调整每次搜索的并发数，以DefaultConcurrency为初始值
+	ConcurrencyMin      int  // 自动调整的并发数下限
+	ConcurrencyMax      int  // 自动调整的并发数上限，为0时为初始值的2倍
+
+	// 零结果插件跳过配置
+	ZeroHitSkip          bool          // 是否跳过在某个关键词分类下长期没有结果的插件
+	ZeroHitMinSearches   int           // 插件在该分类下连续多少次搜索没有结果后跳过
+	ZeroHitProbeInterval time.Duration // 被跳过的插件每隔多久放行一次搜索，重新检查是否有结果
+
+	// 磁盘缓存写入安全配置
+	CacheFsync                string        // 文件存储写入后的fsync策略：always、interval、never
+	CacheFsyncInterval        time.Duration // interval策略下批量同步的间隔
+	CacheCorruptionQuarantine int           // 分片在1小时内发现多少个损坏的缓存项时隔离该分片，0表示不隔离
+
+	// 插件组合配置
+	PluginProfiles map[string][]string // 组合名（小写） -> 选择器，请求通过profile参数使用，展开为对应的插件
+
+	// 后台补全搜索配置
+	SearchBackfill            bool // 请求只搜索部分插件时，是否在后台按未指定插件的请求搜索同一关键词
+	SearchBackfillConcurrency int  // 后台补全搜索的插件并发数
+	SearchBackfillQueue       int  // 等待执行的补全搜索上限，队列已满时丢弃新的补全
+
+	// 插件影子测试配置
+	ShadowPlugins     map[string]string // 线上插件名（小写） -> 候选插件名，候选插件不参与线上搜索
+	ShadowSampleRate  float64           // 线上插件的搜索有多大比例（百分比）同时交给候选插件执行并对比结果
+	ShadowConcurrency int               // 同时执行的候选插件搜索上限，超出时跳过本次对比
+	ShadowLogFile     string            // 对比记录（JSON Lines）的追加文件，为空时只输出日志
 
 }
 
 // 全局配置实例
 var AppConfig *Config
 
+// 保护运行时修改默认频道列表
+var channelsMutex sync.RWMutex
+
 // 初始化配置
 func Init() {
 	proxyURL := getProxyURL()
@@ -68,12 +274,18 @@ func Init() {
 		CachePath:       getCachePath(),
 		CacheMaxSizeMB:  getCacheMaxSize(),
 		CacheTTLMinutes: getCacheTTL(),
+		CacheEncryptionKey:     os.Getenv("CACHE_ENCRYPTION_KEY"),
+		CacheEncryptionOldKeys: getCacheEncryptionOldKeys(),
+		CacheTTLFinal:          getMinutesEnv("CACHE_TTL_FINAL", 4*time.Duration(getCacheTTL())*time.Minute),
+		CacheTTLPartial:        getMinutesEnv("CACHE_TTL_PARTIAL", 5*time.Minute),
+		CacheStore:             getCacheStore(),
 		// 压缩相关配置
 		EnableCompression: getEnableCompression(),
 		MinSizeToCompress: getMinSizeToCompress(),
 		// GC相关配置
 		GCPercent:      getGCPercent(),
 		OptimizeMemory: getOptimizeMemory(),
+		MemoryLimitMB:  getMemoryLimitMB(),
 		// 插件相关配置
 		PluginTimeoutSeconds: pluginTimeoutSeconds,
 		PluginTimeout:        time.Duration(pluginTimeoutSeconds) * time.Second,
@@ -91,6 +303,202 @@ func Init() {
 		HTTPWriteTimeout: getHTTPWriteTimeout(),
 		HTTPIdleTimeout:  getHTTPIdleTimeout(),
 		HTTPMaxConns:     getHTTPMaxConns(),
+		HTTPConnBudget:   getHTTPConnBudget(),
+		PluginRetryBudget: getPluginRetryBudget(),
+		DetailFetchMax:    getDetailFetchMax(),
+		DetailFetchTimeout: getDetailFetchTimeout(),
+		CookieJarPlugins:  getCookieJarPlugins(),
+		CookieJarKey:      os.Getenv("COOKIE_JAR_KEY"),
+		PluginPanicThreshold:     getPluginPanicThreshold(),
+		PluginPanicWindow:        getMinutesEnv("PLUGIN_PANIC_WINDOW", 10*time.Minute),
+		PluginQuarantineDuration: getMinutesEnv("PLUGIN_QUARANTINE_DURATION", 30*time.Minute),
+		PluginSchedules:          getPluginSchedules(),
+		// 链接点击统计配置
+		ClickTrackingEnabled: getClickTrackingEnabled(),
+		// 频道发现配置
+		ChannelDiscoveryEnabled:  getChannelDiscoveryEnabled(),
+		ChannelDiscoveryInterval: getChannelDiscoveryInterval(),
+		// 内容安全过滤配置
+		ContentBlockPatterns:     getContentBlockPatterns(),
+		ContentBlockDomains:      getContentBlockDomains(),
+		DomainReputationURL:      os.Getenv("DOMAIN_REPUTATION_URL"),
+		DomainReputationInterval: getDomainReputationInterval(),
+		// 多租户配置
+		TenantsFile: os.Getenv("TENANTS_FILE"),
+		// 集群模式配置
+		ClusterRole:    getClusterRole(),
+		ClusterWorkers: getClusterWorkers(),
+		ClusterSecret:  os.Getenv("CLUSTER_SECRET"),
+		// 出站源地址配置
+		SourceAddresses: getSourceAddresses(),
+		// 搜索黑名单配置
+		SearchBlacklistFile: os.Getenv("SEARCH_BLACKLIST_FILE"),
+		SearchBlockKeywords: getSearchBlockKeywords(),
+		SearchAuditLog:      getSearchAuditLog(),
+		// 启动自检配置
+		FailOnMiswire: getFailOnMiswire(),
+		// TG频道分页配置
+		TGPageDepth:         getTGPageDepth(),
+		TGChannelPageDepths: getTGChannelPageDepths(),
+		TGBaseURL:           getTGBaseURL(),
+		// 频道专用解析器配置
+		ChannelParsers: getChannelParsers(),
+		// 排序配置
+		RankingProfileFile: os.Getenv("RANKING_PROFILE_FILE"),
+		// 已见链接索引配置
+		SeenLinksEnabled: getSeenLinksEnabled(),
+		SeenLinksMax:     getSeenLinksMax(),
+		SeenLinksTTL:     getMinutesEnv("SEEN_LINKS_TTL", 30*24*time.Hour),
+		// 插件预设配置
+		PluginPreset: strings.ToLower(strings.TrimSpace(os.Getenv("PLUGIN_PRESET"))),
+		// 多语言配置
+		APIDefaultLang: getLangEnv("API_DEFAULT_LANG"),
+		LogLang:        getLangEnv("LOG_LANG"),
+		// 来源可信度配置
+		SourceTrustLevels:  getSourceTrustLevels(),
+		SourceTrustDefault: getSourceTrustLevel(os.Getenv("SOURCE_TRUST_DEFAULT"), "standard"),
+		// 插件缓存快照配置
+		PluginCacheSnapshotEnabled: getPluginCacheSnapshotEnabled(),
+		PluginCacheSnapshotMax:     getPluginCacheSnapshotMax(),
+		// 响应大小配置
+		ResponseLinkCap: getResponseLinkCap(),
+		// 搜索建议配置
+		SuggestEnabled:    getSuggestEnabled(),
+		SuggestMaxEntries: getSuggestMaxEntries(),
+		// 平滑升级配置
+		UpgradeTimeout: getSecondsEnv("UPGRADE_TIMEOUT", 30*time.Second),
+		PIDFile:        os.Getenv("PID_FILE"),
+		// 监听方式配置
+		UnixSocket:       strings.TrimSpace(os.Getenv("UNIX_SOCKET")),
+		UnixSocketMode:   getUnixSocketMode(),
+		SocketActivation: getSocketActivation(),
+
+		// 管理后台配置
+		AdminUIEnabled: getAdminUIEnabled(),
+
+		// 结果后处理配置
+		PostProcessors: getPostProcessors(),
+
+		// 缓存复制配置
+		CacheS3Endpoint:     strings.TrimSpace(os.Getenv("CACHE_S3_ENDPOINT")),
+		CacheS3Region:       getCacheS3Region(),
+		CacheS3Bucket:       strings.TrimSpace(os.Getenv("CACHE_S3_BUCKET")),
+		CacheS3AccessKey:    os.Getenv("CACHE_S3_ACCESS_KEY"),
+		CacheS3SecretKey:    os.Getenv("CACHE_S3_SECRET_KEY"),
+		CacheS3Prefix:       getCacheS3Prefix(),
+		CacheS3PathStyle:    getCacheS3PathStyle(),
+		CacheS3SyncInterval: getMinutesEnv("CACHE_S3_SYNC_INTERVAL", 5*time.Minute),
+
+		// 关键词分类配置
+		QueryCategoryRouting: getQueryCategoryRouting(),
+		PluginCategories:     getPluginCategories(),
+
+		// 插件延迟预算配置
+		PluginLevelBudgets:     getPluginLevelBudgets(),
+		PluginTimeoutOverrides: getPluginTimeoutOverrides(),
+
+		// 插件分批搜索配置
+		PluginTwoWave:         getPluginTwoWave(),
+		PluginFirstWaveLevels: getPluginFirstWaveLevels(),
+		PluginWaveOverrides:   getPluginWaveOverrides(),
+
+		// 链接检测配置
+		LinkProbeMaxURLs:  getLinkProbeMaxURLs(),
+		LinkProbeRate:     getLinkProbeRate(),
+		LinkProbeCacheTTL: getMinutesEnv("LINK_PROBE_CACHE_TTL", 30*time.Minute),
+
+		// 缓存滑动过期配置
+		CacheSlidingTTL:    getCacheSlidingTTL(),
+		CacheSlidingMaxAge: getMinutesEnv("CACHE_SLIDING_MAX_AGE", 24*time.Hour),
+		CacheRefreshAfter:  getMinutesEnv("CACHE_REFRESH_AFTER", time.Duration(getCacheTTL())*time.Minute),
+
+		// 语义重排配置
+		RerankEmbeddingURL:   strings.TrimSpace(os.Getenv("RERANK_EMBEDDING_URL")),
+		RerankEmbeddingModel: strings.TrimSpace(os.Getenv("RERANK_EMBEDDING_MODEL")),
+		RerankEmbeddingKey:   strings.TrimSpace(os.Getenv("RERANK_EMBEDDING_KEY")),
+		RerankTopK:           getRerankTopK(),
+		RerankTimeout:        getMillisecondsEnv("RERANK_TIMEOUT", 300*time.Millisecond),
+
+		// 客户端跳转链接配置
+		DeepLinkTemplatesFile: os.Getenv("DEEP_LINK_TEMPLATES_FILE"),
+
+		// 插件按主机限流配置
+		HostMaxConcurrency:    getHostMaxConcurrency(),
+		HostRequestInterval:   getMillisecondsEnv("HOST_REQUEST_INTERVAL", 0),
+		HostGroups:            getHostGroups(),
+		HostConcurrencyLimits: getHostConcurrencyLimits(),
+		HostIntervals:         getHostIntervals(),
+
+		// 失效站点配置
+		DeadDomainMinFailures:   getDeadDomainMinFailures(),
+		DeadDomainAfter:         getMinutesEnv("DEAD_DOMAIN_AFTER", 10*time.Minute),
+		DeadDomainRetryInterval: getMinutesEnv("DEAD_DOMAIN_RETRY_INTERVAL", 5*time.Minute),
+
+		// 合并结果缓存配置
+		MergeMemoTTL:        getMergeMemoTTL(),
+		MergeMemoMaxEntries: getMergeMemoMaxEntries(),
+
+		// 请求大小与读写期限配置
+		MaxQueryLength:       getMaxQueryLength(),
+		MaxBodySize:          getMaxBodySize(),
+		BodyReadTimeout:      getSecondsEnv("BODY_READ_TIMEOUT", 10*time.Second),
+		ResponseWriteTimeout: getSecondsEnv("RESPONSE_WRITE_TIMEOUT", 30*time.Second),
+
+		// 链接反查配置
+		LinkIndexEnabled: getLinkIndexEnabled(),
+		LinkIndexTTL:     getMinutesEnv("LINK_INDEX_TTL", 30*24*time.Hour),
+
+		// 插件连接预热配置
+		PluginWarmupEnabled:     getPluginWarmupEnabled(),
+		PluginWarmupConcurrency: getPluginWarmupConcurrency(),
+		PluginWarmupTimeout:     getSecondsEnv("PLUGIN_WARMUP_TIMEOUT", 10*time.Second),
+
+		// 维护模式配置
+		MaintenanceMode:    getMaintenanceMode(),
+		MaintenanceMessage: strings.TrimSpace(os.Getenv("MAINTENANCE_MESSAGE")),
+
+		// 关键词翻译配置
+		KeywordTranslation: getKeywordTranslation(),
+		TitleAliasFile:     strings.TrimSpace(os.Getenv("TITLE_ALIAS_FILE")),
+		PluginLanguages:    getPluginLanguages(),
+
+		// 结果持久化配置
+		NoPersistSources: getNoPersistSources(),
+
+		// 发现类请求缓存配置
+		DiscoveryCacheTTL: getDiscoveryCacheTTL(),
+
+		// 来源自检配置
+		SelfTestKeyword: getSelfTestKeyword(),
+
+		// 自适应并发配置
+		AdaptiveConcurrency: getAdaptiveConcurrency(),
+		ConcurrencyMin:      getConcurrencyBound("CONCURRENCY_MIN", 4),
+		ConcurrencyMax:      getConcurrencyBound("CONCURRENCY_MAX", 0),
+
+		// 零结果插件跳过配置
+		ZeroHitSkip:          getZeroHitSkip(),
+		ZeroHitMinSearches:   getZeroHitMinSearches(),
+		ZeroHitProbeInterval: getMinutesEnv("ZERO_HIT_PROBE_INTERVAL", time.Hour),
+
+		// 磁盘缓存写入安全配置
+		CacheFsync:                getCacheFsync(),
+		CacheFsyncInterval:        getCacheFsyncInterval(),
+		CacheCorruptionQuarantine: getCacheCorruptionQuarantine(),
+
+		// 插件组合配置
+		PluginProfiles: getPluginProfiles(),
+
+		// 后台补全搜索配置
+		SearchBackfill:            getSearchBackfill(),
+		SearchBackfillConcurrency: getSearchBackfillConcurrency(),
+		SearchBackfillQueue:       getSearchBackfillQueue(),
+
+		// 插件影子测试配置
+		ShadowPlugins:     getShadowPlugins(),
+		ShadowSampleRate:  getShadowSampleRate(),
+		ShadowConcurrency: getShadowConcurrency(),
+		ShadowLogFile:     strings.TrimSpace(os.Getenv("SHADOW_LOG_FILE")),
 
 	}
 	
@@ -104,7 +512,14 @@ func getDefaultChannels() []string {
 	if channelsEnv == "" {
 		return []string{"tgsearchers3"}
 	}
-	return strings.Split(channelsEnv, ",")
+	// 忽略空项，CHANNELS只含空白或逗号时表示不搜索TG
+	var channels []string
+	for _, channel := range strings.Split(channelsEnv, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
 }
 
 // 从环境变量获取默认并发数，如果未设置则使用基于环境变量的简单计算
@@ -208,6 +623,17 @@ func getCachePath() string {
 	return path
 }
 
+// 从环境变量获取磁盘缓存存储后端，无法识别时使用文件存储
+func getCacheStore() string {
+	store := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_STORE")))
+	switch store {
+	case "bbolt", "badger":
+		return store
+	default:
+		return "file"
+	}
+}
+
 // 从环境变量获取缓存最大大小(MB)，如果未设置则使用默认值
 func getCacheMaxSize() int {
 	sizeEnv := os.Getenv("CACHE_MAX_SIZE")
@@ -278,6 +704,19 @@ func getOptimizeMemory() bool {
 	return enabled != "false" && enabled != "0"
 }
 
+// 从环境变量获取软内存上限（MB），如果未设置则不限制
+func getMemoryLimitMB() int {
+	limitEnv := os.Getenv("MEMORY_LIMIT_MB")
+	if limitEnv == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(limitEnv)
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
 // 从环境变量获取插件超时时间（秒），如果未设置则使用默认值
 func getPluginTimeout() int {
 	timeoutEnv := os.Getenv("PLUGIN_TIMEOUT")
@@ -488,6 +927,921 @@ func getHTTPMaxConns() int {
 	return maxConns
 }
 
+// 从环境变量获取出站连接总预算，未设置时返回0（由连接池按文件描述符上限自动计算）
+func getHTTPConnBudget() int {
+	budgetEnv := os.Getenv("HTTP_CONN_BUDGET")
+	if budgetEnv != "" {
+		budget, err := strconv.Atoi(budgetEnv)
+		if err == nil && budget > 0 {
+			return budget
+		}
+	}
+	return 0
+}
+
+// 从环境变量获取单次插件搜索的重试预算，如果未设置则默认4次
+func getPluginRetryBudget() int {
+	budgetEnv := os.Getenv("PLUGIN_RETRY_BUDGET")
+	if budgetEnv == "" {
+		return 4
+	}
+	budget, err := strconv.Atoi(budgetEnv)
+	if err != nil || budget < 0 {
+		return 4
+	}
+	return budget
+}
+
+// 从环境变量获取单次插件搜索的详情页抓取数上限，如果未设置则默认40
+func getDetailFetchMax() int {
+	maxEnv := os.Getenv("DETAIL_FETCH_MAX")
+	if maxEnv == "" {
+		return 40
+	}
+	max, err := strconv.Atoi(maxEnv)
+	if err != nil || max < 0 {
+		return 40
+	}
+	return max
+}
+
+// 从环境变量获取单次插件搜索的详情页抓取总时长（秒），如果未设置则默认20秒
+func getDetailFetchTimeout() time.Duration {
+	timeoutEnv := os.Getenv("DETAIL_FETCH_TIMEOUT")
+	if timeoutEnv == "" {
+		return 20 * time.Second
+	}
+	timeout, err := strconv.Atoi(timeoutEnv)
+	if err != nil || timeout < 0 {
+		return 20 * time.Second
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// 从环境变量获取启用持久化Cookie的插件列表，如果未设置则默认为javdb和panyq
+func getCookieJarPlugins() []string {
+	pluginsEnv, ok := os.LookupEnv("COOKIE_JAR_PLUGINS")
+	if !ok {
+		return []string{"javdb", "panyq"}
+	}
+	var plugins []string
+	for _, name := range strings.Split(pluginsEnv, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			plugins = append(plugins, name)
+		}
+	}
+	return plugins
+}
+
+// 从环境变量获取插件自动隔离的panic次数阈值，如果未设置则默认3次
+func getPluginPanicThreshold() int {
+	thresholdEnv := os.Getenv("PLUGIN_PANIC_THRESHOLD")
+	if thresholdEnv == "" {
+		return 3
+	}
+	threshold, err := strconv.Atoi(thresholdEnv)
+	if err != nil || threshold < 0 {
+		return 3
+	}
+	return threshold
+}
+
+// 从环境变量获取以分钟为单位的时长，未设置或无效时使用默认值
+func getMinutesEnv(name string, defaultValue time.Duration) time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || minutes <= 0 {
+		return defaultValue
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// 从环境变量获取以秒为单位的时长，未设置或无效时使用默认值
+func getSecondsEnv(name string, defaultValue time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || seconds <= 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// 从环境变量获取以毫秒为单位的时长，未设置或无效时使用默认值
+func getMillisecondsEnv(name string, defaultValue time.Duration) time.Duration {
+	ms, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || ms <= 0 {
+		return defaultValue
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// 从环境变量获取集群角色，只接受coordinator和worker，其他值视为单机模式
+func getClusterRole() string {
+	role := strings.ToLower(strings.TrimSpace(os.Getenv("CLUSTER_ROLE")))
+	if role == "coordinator" || role == "worker" {
+		return role
+	}
+	return ""
+}
+
+// 从环境变量获取工作节点地址，使用英文逗号分隔
+func getClusterWorkers() []string {
+	var workers []string
+	for _, worker := range strings.Split(os.Getenv("CLUSTER_WORKERS"), ",") {
+		if worker = strings.TrimRight(strings.TrimSpace(worker), "/"); worker != "" {
+			workers = append(workers, worker)
+		}
+	}
+	return workers
+}
+
+// 从环境变量获取出站源地址池，使用英文逗号分隔
+func getSourceAddresses() []string {
+	var addrs []string
+	for _, addr := range strings.Split(os.Getenv("SOURCE_ADDRESSES"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// 从环境变量获取禁止搜索的关键词，使用英文逗号分隔
+func getSearchBlockKeywords() []string {
+	var keywords []string
+	for _, keyword := range strings.Split(os.Getenv("SEARCH_BLOCK_KEYWORDS"), ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// 从环境变量获取搜索审计日志路径，未设置时使用默认路径，设置为"off"时不写文件
+func getSearchAuditLog() string {
+	path, ok := os.LookupEnv("SEARCH_AUDIT_LOG")
+	if !ok {
+		return "data/search_audit.log"
+	}
+	if path = strings.TrimSpace(path); strings.EqualFold(path, "off") {
+		return ""
+	}
+	return path
+}
+
+// 从环境变量获取启动自检失败时是否退出，如果未设置则默认false（只输出告警）
+func getFailOnMiswire() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("FAIL_ON_MISWIRE"))
+	return err == nil && enabled
+}
+
+// TG频道搜索结果页数上限
+const maxTGPageDepth = 10
+
+// 从环境变量获取每个频道默认抓取的页数，如果未设置则默认1页
+func getTGPageDepth() int {
+	depth, err := strconv.Atoi(os.Getenv("TG_PAGE_DEPTH"))
+	if err != nil || depth <= 0 {
+		return 1
+	}
+	if depth > maxTGPageDepth {
+		return maxTGPageDepth
+	}
+	return depth
+}
+
+// 从环境变量获取按频道覆盖的页数，格式为"频道:页数"，使用英文逗号分隔
+func getTGChannelPageDepths() map[string]int {
+	depths := make(map[string]int)
+	for _, item := range strings.Split(os.Getenv("TG_CHANNEL_PAGE_DEPTHS"), ",") {
+		channel, value, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			continue
+		}
+		depth, err := strconv.Atoi(strings.TrimSpace(value))
+		if channel = strings.ToLower(strings.TrimSpace(channel)); channel == "" || err != nil || depth <= 0 {
+			continue
+		}
+		if depth > maxTGPageDepth {
+			depth = maxTGPageDepth
+		}
+		depths[channel] = depth
+	}
+	return depths
+}
+
+// 从环境变量获取频道与专用解析器的绑定，格式为"频道:解析器"，使用英文逗号分隔
+func getChannelParsers() map[string]string {
+	parsers := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv("CHANNEL_PARSERS"), ",") {
+		channel, parser, ok := strings.Cut(strings.TrimSpace(item), ":")
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		parser = strings.ToLower(strings.TrimSpace(parser))
+		if ok && channel != "" && parser != "" {
+			parsers[channel] = parser
+		}
+	}
+	return parsers
+}
+
+// 从环境变量获取是否标记新出现的链接，如果未设置则默认启用
+func getSeenLinksEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SEEN_LINKS_ENABLED"))
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// 从环境变量获取每个关键词最多记录的链接数，如果未设置则默认2000
+func getSeenLinksMax() int {
+	max, err := strconv.Atoi(os.Getenv("SEEN_LINKS_MAX"))
+	if err != nil || max <= 0 {
+		return 2000
+	}
+	return max
+}
+
+// 从环境变量获取频道搜索页地址前缀，如果未设置则使用https://t.me/s/
+func getTGBaseURL() string {
+	baseURL := strings.TrimSpace(os.Getenv("TG_BASE_URL"))
+	if baseURL == "" {
+		return "https://t.me/s/"
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL
+}
+
+// 从环境变量获取语言设置，只支持zh和en，未设置或无效时默认zh
+func getLangEnv(name string) string {
+	lang := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	if lang == "en" {
+		return lang
+	}
+	return "zh"
+}
+
+// 从环境变量获取来源可信度，格式为"来源=可信度"，使用英文逗号分隔，如"tg:tgsearchers3=trusted,plugin:*=community"
+func getSourceTrustLevels() map[string]string {
+	levels := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv("SOURCE_TRUST_LEVELS"), ",") {
+		source, level, ok := strings.Cut(strings.TrimSpace(item), "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		if level = getSourceTrustLevel(level, ""); ok && source != "" && level != "" {
+			levels[source] = level
+		}
+	}
+	return levels
+}
+
+// 校验可信度取值，只支持trusted、standard、community，无效时返回fallback
+func getSourceTrustLevel(value string, fallback string) string {
+	switch level := strings.ToLower(strings.TrimSpace(value)); level {
+	case "trusted", "standard", "community":
+		return level
+	}
+	return fallback
+}
+
+// 从环境变量获取是否保存插件缓存快照，如果未设置则默认不启用
+func getPluginCacheSnapshotEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("PLUGIN_CACHE_SNAPSHOT_ENABLED"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取插件缓存快照的最大条目数，如果未设置则默认2000
+func getPluginCacheSnapshotMax() int {
+	max, err := strconv.Atoi(os.Getenv("PLUGIN_CACHE_SNAPSHOT_MAX"))
+	if err != nil || max <= 0 {
+		return 2000
+	}
+	return max
+}
+
+// 从环境变量获取单次响应的链接数上限，如果未设置则默认2000，设置为0表示不限制
+func getResponseLinkCap() int {
+	limit, err := strconv.Atoi(os.Getenv("RESPONSE_LINK_CAP"))
+	if err != nil || limit < 0 {
+		return 2000
+	}
+	return limit
+}
+
+// 从环境变量获取是否启用搜索建议，如果未设置则默认启用
+func getSuggestEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SUGGEST_ENABLED"))
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// 从环境变量获取搜索建议的候选词上限，如果未设置则默认5000
+func getSuggestMaxEntries() int {
+	max, err := strconv.Atoi(os.Getenv("SUGGEST_MAX_ENTRIES"))
+	if err != nil || max <= 0 {
+		return 5000
+	}
+	return max
+}
+
+// 从环境变量获取Unix套接字文件权限（八进制），如果未设置则默认0660
+func getUnixSocketMode() os.FileMode {
+	mode, err := strconv.ParseUint(strings.TrimSpace(os.Getenv("UNIX_SOCKET_MODE")), 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0660
+	}
+	return os.FileMode(mode)
+}
+
+// 从环境变量获取是否使用systemd套接字激活，如果未设置则默认不启用
+func getSocketActivation() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SOCKET_ACTIVATION"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取是否提供管理后台页面，默认提供
+func getAdminUIEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("ADMIN_UI_ENABLED"))
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// 从环境变量获取结果后处理步骤（逗号分隔，按顺序执行），如果未设置则只按语言过滤
+func getPostProcessors() []string {
+	env, ok := os.LookupEnv("POST_PROCESSORS")
+	if !ok {
+		return []string{"language"}
+	}
+	var names []string
+	for _, name := range strings.Split(env, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// 从环境变量获取对象存储区域，如果未设置则默认us-east-1
+func getCacheS3Region() string {
+	region := strings.TrimSpace(os.Getenv("CACHE_S3_REGION"))
+	if region == "" {
+		return "us-east-1"
+	}
+	return region
+}
+
+// 从环境变量获取缓存在对象存储中的键前缀，如果未设置则默认pansou-cache
+func getCacheS3Prefix() string {
+	prefix, ok := os.LookupEnv("CACHE_S3_PREFIX")
+	if !ok {
+		return "pansou-cache"
+	}
+	return strings.TrimSpace(prefix)
+}
+
+// 从环境变量获取是否使用路径风格访问存储桶，如果未设置则默认使用（兼容MinIO等自建服务）
+func getCacheS3PathStyle() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("CACHE_S3_PATH_STYLE"))
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// 从环境变量获取是否按关键词分类选择插件，如果未设置则默认不启用
+func getQueryCategoryRouting() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("QUERY_CATEGORY_ROUTING"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取插件擅长的内容分类，格式为"插件=分类|分类"，多个插件用逗号分隔
+func getPluginCategories() map[string][]string {
+	result := make(map[string][]string)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_CATEGORIES"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		var categories []string
+		for _, category := range strings.Split(value, "|") {
+			if category = strings.ToLower(strings.TrimSpace(category)); category != "" {
+				categories = append(categories, category)
+			}
+		}
+		result[name] = categories
+	}
+	return result
+}
+
+// 从环境变量获取各插件等级可使用的超时窗口比例，格式为"等级=比例"，未配置的等级使用默认值
+func getPluginLevelBudgets() map[int]float64 {
+	// 默认：1、2级插件使用完整的超时窗口，3级75%，4级50%
+	result := map[int]float64{1: 1, 2: 1, 3: 0.75, 4: 0.5}
+	for _, item := range strings.Split(os.Getenv("PLUGIN_LEVEL_BUDGETS"), ",") {
+		levelStr, ratioStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(levelStr))
+		if err != nil {
+			continue
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(ratioStr), 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			continue
+		}
+		result[level] = ratio
+	}
+	return result
+}
+
+// 从环境变量获取单个插件的超时时间，格式为"插件=秒数"，多个插件用逗号分隔
+func getPluginTimeoutOverrides() map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_TIMEOUT_OVERRIDES"), ",") {
+		name, secondsStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(secondsStr), 64)
+		if err != nil || seconds <= 0 {
+			continue
+		}
+		result[name] = time.Duration(seconds * float64(time.Second))
+	}
+	return result
+}
+
+// 从环境变量获取是否分两批搜索插件，如果未设置则默认关闭
+func getPluginTwoWave() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("PLUGIN_TWO_WAVE"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取属于第一批的插件等级，逗号分隔，如果未设置则默认1、2级
+func getPluginFirstWaveLevels() map[int]bool {
+	result := make(map[int]bool)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_FIRST_WAVE_LEVELS"), ",") {
+		if level, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+			result[level] = true
+		}
+	}
+	if len(result) == 0 {
+		return map[int]bool{1: true, 2: true}
+	}
+	return result
+}
+
+// 从环境变量获取单个插件所属的批次，格式为"插件=批次"，批次为1或2，多个插件用逗号分隔
+func getPluginWaveOverrides() map[string]int {
+	result := make(map[string]int)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_WAVE_OVERRIDES"), ",") {
+		name, waveStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		wave, err := strconv.Atoi(strings.TrimSpace(waveStr))
+		if err != nil || (wave != 1 && wave != 2) {
+			continue
+		}
+		result[name] = wave
+	}
+	return result
+}
+
+// 从环境变量获取单次链接检测的链接数上限，如果未设置则默认50
+func getLinkProbeMaxURLs() int {
+	max, err := strconv.Atoi(os.Getenv("LINK_PROBE_MAX_URLS"))
+	if err != nil || max <= 0 {
+		return 50
+	}
+	return max
+}
+
+// 从环境变量获取每种网盘每秒的检测请求数，如果未设置则默认2
+func getLinkProbeRate() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("LINK_PROBE_RATE"), 64)
+	if err != nil || rate < 0 {
+		return 2
+	}
+	return rate
+}
+
+// 从环境变量获取是否启用缓存滑动过期，如果未设置则默认关闭
+func getCacheSlidingTTL() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("CACHE_SLIDING_TTL"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取参与语义重排的结果数，如果未设置则默认50
+func getRerankTopK() int {
+	k, err := strconv.Atoi(os.Getenv("RERANK_TOP_K"))
+	if err != nil || k <= 0 {
+		return 50
+	}
+	return k
+}
+
+// 从环境变量获取插件对同一主机的并发请求上限，如果未设置则默认8
+func getHostMaxConcurrency() int {
+	limit, err := strconv.Atoi(os.Getenv("HOST_MAX_CONCURRENCY"))
+	if err != nil || limit < 0 {
+		return 8
+	}
+	return limit
+}
+
+// 从环境变量获取主机组，格式为"组名=域名|域名"，多个组用逗号分隔
+func getHostGroups() map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv("HOST_GROUPS"), ",") {
+		group, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		group = strings.ToLower(strings.TrimSpace(group))
+		if !ok || group == "" {
+			continue
+		}
+		for _, domain := range strings.Split(value, "|") {
+			if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+				result[domain] = group
+			}
+		}
+	}
+	return result
+}
+
+// 从环境变量获取单个主机组或域名的并发上限，格式为"主机组或域名=并发数"，多个用逗号分隔
+func getHostConcurrencyLimits() map[string]int {
+	result := make(map[string]int)
+	for _, item := range strings.Split(os.Getenv("HOST_CONCURRENCY_LIMITS"), ",") {
+		name, limitStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil || limit < 0 {
+			continue
+		}
+		result[name] = limit
+	}
+	return result
+}
+
+// 从环境变量获取单个主机组或域名的请求间隔，格式为"主机组或域名=毫秒数"，多个用逗号分隔
+func getHostIntervals() map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range strings.Split(os.Getenv("HOST_INTERVALS"), ",") {
+		name, msStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		ms, err := strconv.Atoi(strings.TrimSpace(msStr))
+		if err != nil || ms < 0 {
+			continue
+		}
+		result[name] = time.Duration(ms) * time.Millisecond
+	}
+	return result
+}
+
+// 从环境变量获取判定站点失效的连续失败次数，如果未设置则默认5
+func getDeadDomainMinFailures() int {
+	failures, err := strconv.Atoi(os.Getenv("DEAD_DOMAIN_MIN_FAILURES"))
+	if err != nil || failures < 0 {
+		return 5
+	}
+	return failures
+}
+
+// 从环境变量获取合并结果的缓存时间（秒），如果未设置则默认30秒，设置为0时不缓存
+func getMergeMemoTTL() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("MERGE_MEMO_TTL"))
+	if err != nil || seconds < 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// 从环境变量获取最多缓存的合并结果数，如果未设置则默认256
+func getMergeMemoMaxEntries() int {
+	max, err := strconv.Atoi(os.Getenv("MERGE_MEMO_MAX_ENTRIES"))
+	if err != nil || max <= 0 {
+		return 256
+	}
+	return max
+}
+
+// 从环境变量获取查询字符串的最大字节数，如果未设置则默认8192，设置为0时不限制
+func getMaxQueryLength() int {
+	length, err := strconv.Atoi(os.Getenv("MAX_QUERY_LENGTH"))
+	if err != nil || length < 0 {
+		return 8192
+	}
+	return length
+}
+
+// 从环境变量获取请求体的最大大小（KB），如果未设置则默认1024KB，设置为0时不限制
+func getMaxBodySize() int64 {
+	kb, err := strconv.ParseInt(os.Getenv("MAX_BODY_SIZE"), 10, 64)
+	if err != nil || kb < 0 {
+		return 1024 * 1024
+	}
+	return kb * 1024
+}
+
+// 从环境变量获取是否记录链接出现用于反查，如果未设置则默认启用
+func getLinkIndexEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("LINK_INDEX_ENABLED"))
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// 从环境变量获取是否在启动时预热插件连接，如果未设置则默认不启用
+func getPluginWarmupEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("PLUGIN_WARMUP_ENABLED"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取同时预热的插件数，如果未设置则默认8
+func getPluginWarmupConcurrency() int {
+	concurrency, err := strconv.Atoi(os.Getenv("PLUGIN_WARMUP_CONCURRENCY"))
+	if err != nil || concurrency <= 0 {
+		return 8
+	}
+	return concurrency
+}
+
+// 从环境变量获取插件的启用时段，格式为"插件名=cron表达式|cron表达式"，多个插件用分号分隔，
+// 如"panyq=* 0-7 * * *;hdmoli=* 22-23 * * 1-5|* * * * 0,6"
+func getPluginSchedules() map[string][]string {
+	result := make(map[string][]string)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_SCHEDULES"), ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		for _, expr := range strings.Split(value, "|") {
+			if expr = strings.TrimSpace(expr); expr != "" {
+				result[name] = append(result[name], expr)
+			}
+		}
+	}
+	return result
+}
+
+// 从环境变量获取启动时是否进入维护模式，如果未设置则默认false
+func getMaintenanceMode() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("MAINTENANCE_MODE"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取是否启用关键词翻译，如果未设置则默认false
+func getKeywordTranslation() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("KEYWORD_TRANSLATION"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取插件站点标题的语言，格式为"插件=语言"，多个插件用逗号分隔
+func getPluginLanguages() map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv("PLUGIN_LANGUAGES"), ",") {
+		name, lang, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if !ok || name == "" || lang == "" {
+			continue
+		}
+		result[name] = lang
+	}
+	return result
+}
+
+// 从环境变量获取结果不持久化的来源，格式为"plugin:插件名"或"tg:频道名"，多个来源用逗号分隔，
+// 没有前缀时视为插件名
+func getNoPersistSources() []string {
+	var sources []string
+	for _, source := range strings.Split(os.Getenv("NO_PERSIST_SOURCES"), ",") {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" {
+			continue
+		}
+		if !strings.HasPrefix(source, "plugin:") && !strings.HasPrefix(source, "tg:") {
+			source = "plugin:" + source
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// 从环境变量获取发现类请求的响应缓存时间（分钟），默认10分钟，设为0时不缓存
+func getDiscoveryCacheTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("DISCOVERY_CACHE_TTL"))
+	if err != nil || minutes < 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// 从环境变量获取来源自检的默认关键词
+func getSelfTestKeyword() string {
+	if keyword := strings.TrimSpace(os.Getenv("SELFTEST_KEYWORD")); keyword != "" {
+		return keyword
+	}
+	return "流浪地球"
+}
+
+// 从环境变量获取是否自动调整并发数，如果未设置则默认不启用
+func getAdaptiveConcurrency() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("ADAPTIVE_CONCURRENCY"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取自动调整并发数的上下限，未设置或无效时使用默认值
+func getConcurrencyBound(name string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// 从环境变量获取是否跳过长期没有结果的插件，如果未设置则默认不启用
+func getZeroHitSkip() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("ZERO_HIT_SKIP"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取跳过插件前需要连续没有结果的搜索次数，默认30次
+func getZeroHitMinSearches() int {
+	searches, err := strconv.Atoi(os.Getenv("ZERO_HIT_MIN_SEARCHES"))
+	if err != nil || searches <= 0 {
+		return 30
+	}
+	return searches
+}
+
+// 从环境变量获取文件存储的fsync策略，无法识别时使用interval
+func getCacheFsync() string {
+	policy := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_FSYNC")))
+	switch policy {
+	case "always", "never":
+		return policy
+	default:
+		return "interval"
+	}
+}
+
+// 从环境变量获取interval策略的同步间隔（秒），默认5秒
+func getCacheFsyncInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("CACHE_FSYNC_INTERVAL"))
+	if err != nil || seconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// 从环境变量获取隔离分片的损坏缓存项阈值，默认3个，设为0时不隔离
+func getCacheCorruptionQuarantine() int {
+	threshold, err := strconv.Atoi(os.Getenv("CACHE_CORRUPTION_QUARANTINE"))
+	if err != nil || threshold < 0 {
+		return 3
+	}
+	return threshold
+}
+
+// 从环境变量获取插件组合，格式为"组合名=选择器|选择器"，多个组合用逗号分隔。
+// 选择器可以是插件名、all、level:1-2（插件等级范围）、type:magnet（支持的网盘类型）或category:video（擅长的内容分类）。
+// 内置fast（等级1、2的插件）、deep（全部插件）和bt（磁力插件）三个组合，配置同名组合时覆盖
+func getPluginProfiles() map[string][]string {
+	result := map[string][]string{
+		"fast": {"level:1-2"},
+		"deep": {"all"},
+		"bt":   {"type:magnet"},
+	}
+	for _, item := range strings.Split(os.Getenv("PLUGIN_PROFILES"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		var selectors []string
+		for _, selector := range strings.Split(value, "|") {
+			if selector = strings.ToLower(strings.TrimSpace(selector)); selector != "" {
+				selectors = append(selectors, selector)
+			}
+		}
+		if len(selectors) == 0 {
+			delete(result, name)
+			continue
+		}
+		result[name] = selectors
+	}
+	return result
+}
+
+// 从环境变量获取是否启用后台补全搜索，默认不启用
+func getSearchBackfill() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SEARCH_BACKFILL"))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取后台补全搜索的插件并发数，默认2
+func getSearchBackfillConcurrency() int {
+	concurrency, err := strconv.Atoi(os.Getenv("SEARCH_BACKFILL_CONCURRENCY"))
+	if err != nil || concurrency <= 0 {
+		return 2
+	}
+	return concurrency
+}
+
+// 从环境变量获取等待执行的补全搜索上限，默认100
+func getSearchBackfillQueue() int {
+	size, err := strconv.Atoi(os.Getenv("SEARCH_BACKFILL_QUEUE"))
+	if err != nil || size <= 0 {
+		return 100
+	}
+	return size
+}
+
+// 从环境变量获取影子测试的插件对照，格式为"线上插件=候选插件"，多个插件用逗号分隔
+func getShadowPlugins() map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv("SHADOW_PLUGINS"), ",") {
+		name, candidate, ok := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		candidate = strings.TrimSpace(candidate)
+		if !ok || name == "" || candidate == "" {
+			continue
+		}
+		result[name] = candidate
+	}
+	return result
+}
+
+// 从环境变量获取影子测试的采样比例（百分比），默认10，取值范围0~100
+func getShadowSampleRate() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("SHADOW_SAMPLE_RATE"), 64)
+	if err != nil || rate < 0 {
+		return 10
+	}
+	if rate > 100 {
+		return 100
+	}
+	return rate
+}
+
+// 从环境变量获取同时执行的候选插件搜索上限，默认2
+func getShadowConcurrency() int {
+	concurrency, err := strconv.Atoi(os.Getenv("SHADOW_CONCURRENCY"))
+	if err != nil || concurrency <= 0 {
+		return 2
+	}
+	return concurrency
+}
+
 // 从环境变量获取异步插件日志开关，如果未设置则使用默认值
 func getAsyncLogEnabled() bool {
 	logEnv := os.Getenv("ASYNC_LOG_ENABLED")
@@ -501,10 +1855,147 @@ func getAsyncLogEnabled() bool {
 	return enabled
 }
 
+// 从环境变量获取用于轮换的旧缓存加密密钥，多个密钥用逗号分隔
+func getCacheEncryptionOldKeys() []string {
+	keysEnv := os.Getenv("CACHE_ENCRYPTION_OLD_KEYS")
+	if keysEnv == "" {
+		return nil
+	}
+	var keys []string
+	for _, key := range strings.Split(keysEnv, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// 从环境变量获取链接点击统计开关，如果未设置则使用默认值
+func getClickTrackingEnabled() bool {
+	enabledEnv := os.Getenv("CLICK_TRACKING_ENABLED")
+	if enabledEnv == "" {
+		return false // 默认关闭
+	}
+	enabled, err := strconv.ParseBool(enabledEnv)
+	if err != nil {
+		return false // 解析失败时关闭
+	}
+	return enabled
+}
+
+// 从环境变量获取频道发现开关，默认关闭
+func getChannelDiscoveryEnabled() bool {
+	enabledEnv := os.Getenv("CHANNEL_DISCOVERY_ENABLED")
+	if enabledEnv == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(enabledEnv)
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// 从环境变量获取候选频道探测间隔（分钟），默认30分钟
+func getChannelDiscoveryInterval() time.Duration {
+	intervalEnv := os.Getenv("CHANNEL_DISCOVERY_INTERVAL")
+	if intervalEnv != "" {
+		minutes, err := strconv.Atoi(intervalEnv)
+		if err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+	return 30 * time.Minute
+}
+
+// 从环境变量获取标题屏蔽正则，多个正则使用英文分号分隔
+func getContentBlockPatterns() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(os.Getenv("CONTENT_BLOCK_PATTERNS"), ";") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// 从环境变量获取屏蔽的链接域名，使用英文逗号分隔
+func getContentBlockDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(os.Getenv("CONTENT_BLOCK_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// 从环境变量获取域名信誉列表更新间隔（分钟），默认360分钟
+func getDomainReputationInterval() time.Duration {
+	intervalEnv := os.Getenv("DOMAIN_REPUTATION_INTERVAL")
+	if intervalEnv != "" {
+		minutes, err := strconv.Atoi(intervalEnv)
+		if err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+	return 6 * time.Hour
+}
+
+// GetDefaultChannels 获取默认搜索频道列表（包含运行时添加的频道）
+func GetDefaultChannels() []string {
+	channelsMutex.RLock()
+	defer channelsMutex.RUnlock()
+	return AppConfig.DefaultChannels
+}
+
+// GetChannelPageDepth 获取频道搜索时抓取的页数
+func GetChannelPageDepth(channel string) int {
+	if depth, ok := AppConfig.TGChannelPageDepths[strings.ToLower(channel)]; ok {
+		return depth
+	}
+	if AppConfig.TGPageDepth <= 0 {
+		return 1
+	}
+	return AppConfig.TGPageDepth
+}
+
+// AddDefaultChannels 运行时向默认频道列表添加频道，返回实际新增的频道
+func AddDefaultChannels(channels ...string) []string {
+	channelsMutex.Lock()
+	defer channelsMutex.Unlock()
+
+	existing := make(map[string]bool, len(AppConfig.DefaultChannels))
+	for _, ch := range AppConfig.DefaultChannels {
+		existing[strings.ToLower(ch)] = true
+	}
+
+	// 写时复制，避免影响正在使用旧列表的请求
+	updated := make([]string, len(AppConfig.DefaultChannels), len(AppConfig.DefaultChannels)+len(channels))
+	copy(updated, AppConfig.DefaultChannels)
+	var added []string
+	for _, ch := range channels {
+		ch = strings.TrimSpace(ch)
+		if ch == "" || existing[strings.ToLower(ch)] {
+			continue
+		}
+		existing[strings.ToLower(ch)] = true
+		updated = append(updated, ch)
+		added = append(added, ch)
+	}
+	AppConfig.DefaultChannels = updated
+	return added
+}
+
 // 应用GC设置
 func applyGCSettings() {
 	// 设置GC百分比
 	debug.SetGCPercent(AppConfig.GCPercent)
+
+	// 设置软内存上限，接近上限时GC更积极地回收
+	if AppConfig.MemoryLimitMB > 0 {
+		debug.SetMemoryLimit(int64(AppConfig.MemoryLimitMB) << 20)
+	}
 	
 	// 如果启用内存优化
 	if AppConfig.OptimizeMemory {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,7 +20,9 @@ import (
 	"pansou/plugin"
 	"pansou/service"
 	"pansou/util"
+	"pansou/util/alert"
 	"pansou/util/cache"
+	"pansou/util/graceful"
 
 	// 以下是插件的空导入，用于触发各插件的init函数，实现自动注册
 	// 添加新插件时，只需在此处添加对应的导入语句即可
@@ -91,6 +92,22 @@ func initApp() {
 	// 初始化HTTP客户端
 	util.InitHTTPClient()
 
+	// 初始化告警投递通道
+	alert.InitFromEnvironment()
+
+	// 冷启动时从对象存储恢复磁盘缓存，需在磁盘缓存初始化之前
+	if err := service.ValidateCacheReplication(); err != nil {
+		log.Fatalf("缓存复制配置无效: %v", err)
+	}
+	replicator := service.GetCacheReplicator()
+	if replicator != nil {
+		if restored, err := replicator.Restore(); err != nil {
+			log.Printf("从对象存储恢复缓存失败: %v", err)
+		} else if restored > 0 {
+			fmt.Printf("已从对象存储恢复 %d 个缓存文件\n", restored)
+		}
+	}
+
 	// 初始化缓存写入管理器
 	var err error
 	globalCacheWriteManager, err = cache.NewDelayedBatchWriteManager()
@@ -103,19 +120,19 @@ func initApp() {
 	// 将缓存写入管理器注入到service包
 	service.SetGlobalCacheWriteManager(globalCacheWriteManager)
 
-	// 延迟设置主缓存更新函数，确保service初始化完成
-	go func() {
-		// 等待一小段时间确保service包完全初始化
-		time.Sleep(100 * time.Millisecond)
-		if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
-			globalCacheWriteManager.SetMainCacheUpdater(func(key string, data []byte, ttl time.Duration) error {
-				return mainCache.SetBothLevels(key, data, ttl)
-			})
-		}
-	}()
+	// 缓存写入磁盘后在下个同步周期复制到对象存储
+	if replicator != nil {
+		globalCacheWriteManager.SetFlushListener(replicator.MarkDirty)
+		replicator.Start(config.AppConfig.CacheS3SyncInterval)
+	}
+
+	// 主缓存更新函数由service.NewSearchService设置，启动后由自检确认
 
 	// 确保异步插件系统初始化
 	plugin.InitAsyncPluginSystem()
+
+	// 加载插件的持久化Cookie
+	util.LoadCookieJars()
 }
 
 // startServer 启动Web服务器
@@ -123,6 +140,22 @@ func startServer() {
 	// 初始化插件管理器
 	pluginManager := plugin.NewPluginManager()
 
+	// 未设置ENABLED_PLUGINS时使用插件预设；没有TG频道时自动使用默认预设，避免没有任何搜索来源
+	if config.AppConfig.AsyncPluginEnabled && config.AppConfig.EnabledPlugins == nil {
+		preset := config.AppConfig.PluginPreset
+		if preset == "" && len(config.GetDefaultChannels()) == 0 {
+			preset = "default"
+			fmt.Println("未配置TG频道且未设置插件列表，自动启用默认插件预设 (PLUGIN_PRESET=default)")
+		}
+		if preset != "" {
+			if names := plugin.PresetPluginNames(preset); names != nil {
+				config.AppConfig.EnabledPlugins = names
+			} else {
+				fmt.Printf("未知的插件预设: %s\n", preset)
+			}
+		}
+	}
+
 	// 注册全局插件（根据配置过滤）
 	if config.AppConfig.AsyncPluginEnabled {
 		pluginManager.RegisterGlobalPluginsWithFilter(config.AppConfig.EnabledPlugins)
@@ -135,9 +168,43 @@ func startServer() {
 	}
 	config.UpdateDefaultConcurrency(pluginCount)
 
+	// 影子测试：候选插件从线上插件中移除，只用于对比结果
+	service.SetupPluginShadows(pluginManager)
+
 	// 初始化搜索服务
 	searchService := service.NewSearchService(pluginManager)
 
+	// 启动自检：确认缓存更新链路已正确连接
+	if report := service.RunStartupSelfCheck(pluginManager); !report.OK && config.AppConfig.FailOnMiswire {
+		log.Fatalf("启动自检失败: %s", strings.Join(report.Failed(), ", "))
+	}
+
+	// 恢复插件缓存快照，需在主缓存初始化之后、处理请求之前
+	service.RestorePluginCacheSnapshot()
+
+	// 回放缓存预写日志，恢复上次运行时尚未写入磁盘的缓存数据
+	if globalCacheWriteManager != nil {
+		if replayed, err := globalCacheWriteManager.ReplayJournal(); err != nil {
+			log.Printf("回放缓存预写日志失败: %v", err)
+		} else if replayed > 0 {
+			fmt.Printf("已从预写日志恢复 %d 条缓存数据\n", replayed)
+		}
+	}
+
+	// 检查TG频道和插件是否可用
+	service.CheckSearchSources(pluginManager)
+
+	// 后台预热插件站点的连接（启用时）
+	service.WarmPluginConnections(pluginManager)
+
+	// 启动频道发现任务
+	if config.AppConfig.ChannelDiscoveryEnabled {
+		searchService.StartChannelDiscovery(config.AppConfig.ChannelDiscoveryInterval)
+	}
+
+	// 启动域名信誉列表定期更新
+	service.GetContentSafetyFilter().Start(config.AppConfig.DomainReputationInterval)
+
 	// 设置路由
 	router := api.SetupRouter(searchService)
 
@@ -156,49 +223,177 @@ func startServer() {
 		IdleTimeout:  config.AppConfig.HTTPIdleTimeout,
 	}
 
+	// 创建监听器：平滑升级启动时使用继承的监听套接字，其次按配置使用systemd套接字激活、Unix套接字或TCP端口
+	listener, source, err := graceful.Listen(graceful.ListenOptions{
+		Addr:             srv.Addr,
+		UnixSocket:       config.AppConfig.UnixSocket,
+		UnixSocketMode:   config.AppConfig.UnixSocketMode,
+		SocketActivation: config.AppConfig.SocketActivation,
+	})
+	if err != nil {
+		log.Fatalf("创建监听器失败: %v", err)
+	}
+	switch source {
+	case graceful.SourceInherited:
+		fmt.Println("已接管旧进程的监听套接字")
+	case graceful.SourceSystemd:
+		fmt.Printf("使用systemd传入的监听套接字: %s\n", listener.Addr())
+	default:
+		if config.AppConfig.UnixSocket != "" {
+			fmt.Printf("监听Unix套接字: %s\n", config.AppConfig.UnixSocket)
+		}
+	}
+
 	// 创建通道来接收操作系统信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	upgrade := make(chan os.Signal, 1)
+	graceful.NotifyUpgrade(upgrade)
 
 	// 在单独的goroutine中启动服务器
 	go func() {
+		serveListener := listener
 		// 如果设置了最大连接数，使用限制监听器
 		if config.AppConfig.HTTPMaxConns > 0 {
-			// 创建监听器
-			listener, err := net.Listen("tcp", srv.Addr)
-			if err != nil {
-				log.Fatalf("创建监听器失败: %v", err)
+			serveListener = netutil.LimitListener(listener, config.AppConfig.HTTPMaxConns)
+		}
+		if err := srv.Serve(serveListener); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("启动服务器失败: %v", err)
+		}
+	}()
+
+	// 开始接受连接后通知旧进程退出，并记录当前服务进程
+	if err := graceful.Ready(); err != nil {
+		log.Printf("通知旧进程失败: %v", err)
+	}
+	if err := graceful.WritePIDFile(config.AppConfig.PIDFile); err != nil {
+		log.Printf("写入PID文件失败: %v", err)
+	}
+
+	// 等待中断信号或升级信号
+	for {
+		select {
+		case <-quit:
+			fmt.Println("正在关闭服务器...")
+
+			// 优先保存缓存数据到磁盘（数据安全第一）
+			saveState(true)
+
+			// 设置关闭超时时间
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			// 优雅关闭服务器
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Fatalf("服务器关闭异常: %v", err)
 			}
 
-			// 创建限制连接数的监听器
-			limitListener := netutil.LimitListener(listener, config.AppConfig.HTTPMaxConns)
+			fmt.Println("服务器已安全关闭")
+			return
+
+		case <-upgrade:
+			fmt.Println("收到升级信号，正在启动新进程...")
 
-			// 使用限制监听器启动服务器
-			if err := srv.Serve(limitListener); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("启动服务器失败: %v", err)
+			// 先保存数据，新进程启动时加载最新的缓存、插件缓存快照和统计数据
+			saveState(false)
+
+			// 预写日志交给新进程
+			if globalCacheWriteManager != nil {
+				if err := globalCacheWriteManager.CloseJournal(); err != nil {
+					log.Printf("关闭缓存预写日志失败: %v", err)
+				}
 			}
-		} else {
-			// 使用默认方式启动服务器（不限制连接数）
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("启动服务器失败: %v", err)
+
+			// 数据库存储持有文件锁，先关闭以便新进程打开，之后当前进程只使用内存缓存
+			releaseDisk := config.AppConfig.CacheStore != "file"
+			if releaseDisk {
+				releaseCacheDisk()
+			}
+
+			if err := graceful.Upgrade(listener, config.AppConfig.UpgradeTimeout); err != nil {
+				log.Printf("平滑升级失败，继续由当前进程提供服务: %v", err)
+				if releaseDisk {
+					reopenCacheDisk()
+				}
+				if globalCacheWriteManager != nil {
+					if err := globalCacheWriteManager.ReopenJournal(); err != nil {
+						log.Printf("重新打开缓存预写日志失败: %v", err)
+					}
+				}
+				continue
+			}
+			fmt.Println("新进程已接管监听，等待进行中的请求完成...")
+
+			// 停止接受新连接，等待进行中的请求完成
+			ctx, cancel := context.WithTimeout(context.Background(), config.AppConfig.UpgradeTimeout)
+			defer cancel()
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Printf("等待进行中的请求完成超时: %v", err)
 			}
+
+			// 保存处理剩余请求期间产生的数据
+			saveState(true)
+
+			fmt.Println("旧进程已退出")
+			return
 		}
-	}()
+	}
+}
 
-	// 等待中断信号
-	<-quit
-	fmt.Println("正在关闭服务器...")
+// 平滑升级时是否已将磁盘缓存交给新进程
+var cacheDiskReleased bool
 
-	// 优先保存缓存数据到磁盘（数据安全第一）
+// releaseCacheDisk 关闭磁盘缓存存储，释放数据库文件锁
+func releaseCacheDisk() {
+	if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
+		if err := mainCache.CloseDisk(); err != nil {
+			log.Printf("关闭磁盘缓存失败: %v", err)
+		}
+	}
+	cacheDiskReleased = true
+}
+
+// reopenCacheDisk 升级失败时重新打开磁盘缓存存储
+func reopenCacheDisk() {
+	if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
+		if err := mainCache.ReopenDisk(); err != nil {
+			log.Printf("重新打开磁盘缓存失败，继续只使用内存缓存: %v", err)
+			return
+		}
+	}
+	cacheDiskReleased = false
+}
+
+// saveState 保存缓存和各项统计数据到磁盘，关闭或升级时调用。
+// final为false时只刷新缓存写入管理器而不关闭，当前进程仍可继续处理请求
+func saveState(final bool) {
 	// 增加关闭超时时间，确保数据有足够时间保存
 	shutdownTimeout := 10 * time.Second
 	
+	// 磁盘缓存已交给新进程时不再写入缓存
+	if cacheDiskReleased {
+		if globalCacheWriteManager != nil && final {
+			globalCacheWriteManager.Shutdown(shutdownTimeout)
+		}
+		saveStats()
+		return
+	}
+	
 	if globalCacheWriteManager != nil {
-		if err := globalCacheWriteManager.Shutdown(shutdownTimeout); err != nil {
+		if final {
+			if err := globalCacheWriteManager.Shutdown(shutdownTimeout); err != nil {
+				log.Printf("缓存数据保存失败: %v", err)
+			}
+		} else if err := globalCacheWriteManager.Flush(); err != nil {
 			log.Printf("缓存数据保存失败: %v", err)
 		}
 	}
 	
+	// 保存插件缓存快照
+	if err := service.SavePluginCacheSnapshot(); err != nil {
+		log.Printf("插件缓存快照保存失败: %v", err)
+	}
+
 	// 额外确保内存缓存也被保存（双重保障）
 	if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
 		if err := mainCache.FlushMemoryToDisk(); err != nil {
@@ -206,16 +401,60 @@ func startServer() {
 		} 
 	}
 
-	// 设置关闭超时时间
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+	// 关闭磁盘缓存存储，确保数据库数据完整落盘
+	if final {
+		if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
+			if err := mainCache.CloseDisk(); err != nil {
+				log.Printf("关闭磁盘缓存失败: %v", err)
+			}
+		}
+	}
 
-	// 优雅关闭服务器
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("服务器关闭异常: %v", err)
+	// 将磁盘缓存同步到对象存储
+	if replicator := service.GetCacheReplicator(); replicator != nil {
+		if err := replicator.Sync(); err != nil {
+			log.Printf("缓存同步到对象存储失败: %v", err)
+		}
+	}
+	
+	saveStats()
+}
+
+// saveStats 保存各项统计数据和插件Cookie
+func saveStats() {
+	// 保存链接点击统计
+	if config.AppConfig.ClickTrackingEnabled {
+		if err := service.GetClickService().Flush(); err != nil {
+			log.Printf("点击统计保存失败: %v", err)
+		}
 	}
 
-	fmt.Println("服务器已安全关闭")
+	// 保存插件搜索统计
+	if err := service.GetPluginStats().Flush(); err != nil {
+		log.Printf("插件统计保存失败: %v", err)
+	}
+	if err := service.GetPluginZeroHits().Flush(); err != nil {
+		log.Printf("插件零结果统计保存失败: %v", err)
+	}
+
+	// 保存搜索建议数据
+	if config.AppConfig.SuggestEnabled {
+		if err := service.GetSuggestService().Flush(); err != nil {
+			log.Printf("搜索建议数据保存失败: %v", err)
+		}
+	}
+
+	// 保存频道发现数据
+	if config.AppConfig.ChannelDiscoveryEnabled {
+		if err := service.GetChannelDiscovery().Flush(); err != nil {
+			log.Printf("频道发现数据保存失败: %v", err)
+		}
+	}
+
+	// 保存插件的持久化Cookie
+	if err := util.SaveCookieJars(); err != nil {
+		log.Printf("插件Cookie保存失败: %v", err)
+	}
 }
 
 // printServiceInfo 打印服务信息
@@ -243,11 +482,16 @@ func printServiceInfo(port string, pluginManager *plugin.PluginManager) {
 		fmt.Printf("默认并发数: %d (= 频道数%d + 插件数%d + 10)\n",
 			config.AppConfig.DefaultConcurrency, channelCount, pluginCount)
 	}
+	if config.AppConfig.AdaptiveConcurrency {
+		status := service.GetConcurrencyStatus()
+		fmt.Printf("自适应并发已启用: 以默认并发数为初始值，按错误率、超时率和CPU占用在%d~%d之间调整\n", status.Min, status.Max)
+	}
 
 	// 输出缓存信息
 	if config.AppConfig.CacheEnabled {
-		fmt.Printf("缓存已启用: 路径=%s, 最大大小=%dMB, TTL=%d分钟\n",
+		fmt.Printf("缓存已启用: 路径=%s, 存储=%s, 最大大小=%dMB, TTL=%d分钟\n",
 			config.AppConfig.CachePath,
+			config.AppConfig.CacheStore,
 			config.AppConfig.CacheMaxSizeMB,
 			config.AppConfig.CacheTTLMinutes)
 	} else {
@@ -264,6 +508,9 @@ func printServiceInfo(port string, pluginManager *plugin.PluginManager) {
 	fmt.Printf("GC配置: 触发阈值=%d%%, 内存优化=%v\n",
 		config.AppConfig.GCPercent,
 		config.AppConfig.OptimizeMemory)
+	if config.AppConfig.MemoryLimitMB > 0 {
+		fmt.Printf("软内存上限: %dMB\n", config.AppConfig.MemoryLimitMB)
+	}
 
 	// 输出HTTP服务器配置信息
 	readTimeoutMsg := ""
@@ -0,0 +1,47 @@
+package model
+
+import "strings"
+
+// RefreshLevel 搜索缓存刷新级别，通过X-Cache-Refresh请求头指定
+type RefreshLevel string
+
+// 缓存刷新级别
+const (
+	RefreshNone    RefreshLevel = ""        // 正常使用缓存
+	RefreshMemory  RefreshLevel = "memory"  // 跳过内存缓存，从磁盘缓存重新读取
+	RefreshFull    RefreshLevel = "full"    // 跳过全部缓存，重新搜索（等同于refresh=true）
+	RefreshTG      RefreshLevel = "tg"      // 只重新搜索TG频道，插件结果仍使用缓存
+	RefreshPlugins RefreshLevel = "plugins" // 只重新搜索插件，TG结果仍使用缓存
+)
+
+// ParseRefreshLevel 解析刷新级别，不区分大小写，空字符串表示不刷新
+func ParseRefreshLevel(value string) (RefreshLevel, bool) {
+	switch level := RefreshLevel(strings.ToLower(strings.TrimSpace(value))); level {
+	case RefreshNone, RefreshMemory, RefreshFull, RefreshTG, RefreshPlugins:
+		return level, true
+	}
+	return RefreshNone, false
+}
+
+// RefreshLevelFromBool 将refresh布尔参数转换为刷新级别
+func RefreshLevelFromBool(forceRefresh bool) RefreshLevel {
+	if forceRefresh {
+		return RefreshFull
+	}
+	return RefreshNone
+}
+
+// RefreshesTG 是否需要重新搜索TG频道
+func (l RefreshLevel) RefreshesTG() bool {
+	return l == RefreshFull || l == RefreshTG
+}
+
+// RefreshesPlugins 是否需要重新搜索插件
+func (l RefreshLevel) RefreshesPlugins() bool {
+	return l == RefreshFull || l == RefreshPlugins
+}
+
+// SkipsMemory 读取缓存时是否跳过内存缓存
+func (l RefreshLevel) SkipsMemory() bool {
+	return l == RefreshMemory
+}
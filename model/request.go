@@ -2,13 +2,83 @@ package model
 
 // SearchRequest 搜索请求参数
 type SearchRequest struct {
-	Keyword      string                 `json:"kw" binding:"required"`       // 搜索关键词
-	Channels     []string               `json:"channels"`                    // 搜索的频道列表
-	Concurrency  int                    `json:"conc"`                        // 并发搜索数量
-	ForceRefresh bool                   `json:"refresh"`                     // 强制刷新，不使用缓存
-	ResultType   string                 `json:"res"`                         // 结果类型：all(返回所有结果)、results(仅返回results)、merge(仅返回merged_by_type)
-	SourceType   string                 `json:"src"`                         // 数据来源类型：all(默认，全部来源)、tg(仅Telegram)、plugin(仅插件)
-	Plugins      []string               `json:"plugins"`                     // 指定搜索的插件列表，不指定则搜索全部插件
-	Ext          map[string]interface{} `json:"ext"`                         // 扩展参数，用于传递给插件的自定义参数
-	CloudTypes   []string               `json:"cloud_types"`                 // 指定返回的网盘类型列表，不指定则返回所有类型
-} 
\ No newline at end of file
+	Keyword         string                 `json:"kw" binding:"required"` // 搜索关键词
+	Channels        []string               `json:"channels"`              // 搜索的频道列表
+	Concurrency     int                    `json:"conc"`                  // 并发搜索数量
+	ForceRefresh    bool                   `json:"refresh"`               // 强制刷新，不使用缓存
+	ResultType      string                 `json:"res"`                   // 结果类型：all(返回所有结果)、results(仅返回results)、merge(仅返回merged_by_type)
+	SourceType      string                 `json:"src"`                   // 数据来源类型：all(默认，全部来源)、tg(仅Telegram)、plugin(仅插件)
+	Plugins         []string               `json:"plugins"`               // 指定搜索的插件列表，不指定则搜索全部插件
+	Profile         string                 `json:"profile"`               // 插件组合（如fast、deep、bt），服务端展开为对应的插件，不能与plugins同时指定
+	Ext             map[string]interface{} `json:"ext"`                   // 扩展参数，用于传递给插件的自定义参数
+	CloudTypes      []string               `json:"cloud_types"`           // 指定返回的网盘类型列表，不指定则返回所有类型
+	Aliases         []string               `json:"aliases"`               // 关键词别名（如英文片名），与关键词一起搜索并交错合并结果
+	PageSize        int                    `json:"page_size"`             // 分页大小，大于0时启用分页并返回next_page_token
+	PageToken       string                 `json:"page_token"`            // 分页令牌，由上一页响应的next_page_token获得
+	Languages       []string               `json:"lang"`                  // 语言/地区过滤：zh-CN、zh-TW、en、jp，zh表示全部中文
+	PreferLang      string                 `json:"pref_lang"`             // 偏好语言，排序时提升该语言结果
+	Filter          *SearchFilter          `json:"filter"`                // 结构化过滤条件（仅POST请求体），与同名的顶层参数不能同时指定
+	Group           bool                   `json:"group"`                 // 将标题近似相同的结果聚类，Results只返回每类的代表结果
+	IncludeFiltered bool                   `json:"include_filtered"`      // 在filtered中返回未进入results的结果及原因
+	Highlight       bool                   `json:"highlight"`             // 返回关键词在标题、内容和链接note中的命中位置
+	Rerank          string                 `json:"rerank"`                // 重排方式：semantic按关键词与标题的语义相似度重排前K条
+	DeepLinks       bool                   `json:"deep_links"`            // 为合并链接生成客户端跳转链接（模板见DEEP_LINK_TEMPLATES_FILE）
+	MinResolution   string                 `json:"min_resolution"`        // 只保留标题标注的分辨率不低于该值的结果，如1080p、4k
+	Debug           bool                   `json:"debug"`                 // 返回各频道、插件的耗时、超时和缓存命中层级
+} 
+// CacheWriteConfigRequest 缓存写入管理器运行时调参请求，未设置的字段保持不变
+type CacheWriteConfigRequest struct {
+	MaxBatchInterval string `json:"max_batch_interval"` // 批量写入间隔，如"60s"
+	MaxBatchSize     int    `json:"max_batch_size"`     // 批量写入大小
+	Strategy         string `json:"strategy"`           // 写入策略：immediate、hybrid
+}
+
+// MemorySettingsRequest 运行时调整GC设置的请求，未设置的字段保持不变
+type MemorySettingsRequest struct {
+	GCPercent     *int   `json:"gc_percent"`      // GC触发阈值百分比
+	MemoryLimitMB *int64 `json:"memory_limit_mb"` // 软内存上限（MB），0表示取消上限
+}
+
+// MaintenanceRequest 运行时切换维护模式的请求
+type MaintenanceRequest struct {
+	Enabled bool    `json:"enabled"` // 是否进入维护模式
+	Message *string `json:"message"` // 搜索响应中的提示，未设置时保持不变，空字符串表示使用默认提示
+}
+
+// LogSettingsRequest 运行时调整日志的请求，未设置的字段保持不变
+type LogSettingsRequest struct {
+	Verbose    *bool `json:"verbose"`     // 是否输出异步插件和缓存的详细日志
+	SampleRate *int  `json:"sample_rate"` // 缓存更新等高频日志每N条输出1条
+}
+
+// PluginDebugRequest 开启插件调试日志的请求
+type PluginDebugRequest struct {
+	Duration string `json:"duration"` // 持续时间，如"5m"，为空时为5分钟
+}
+
+// SelfTestRequest 来源自检的请求
+type SelfTestRequest struct {
+	Keyword    string `json:"keyword"` // 自检关键词，为空时使用SELFTEST_KEYWORD
+	SourceType string `json:"src"`     // 检查的来源：all(默认)、tg、plugin
+	Timeout    int    `json:"timeout"` // 每个来源的超时时间（秒），为0时为15秒，最多60秒
+}
+
+// LinkProbeRequest 批量检测分享链接的请求
+type LinkProbeRequest struct {
+	Links []LinkProbeItem `json:"links"` // 待检测的链接
+	URLs  []string        `json:"urls"`  // 不带提取码的链接，与links合并检测
+}
+
+// LinkProbeItem 待检测的分享链接
+type LinkProbeItem struct {
+	URL      string `json:"url"`
+	Password string `json:"password"` // 提取码，为空时使用链接中携带的提取码
+}
+
+// ClusterSearchRequest 集群模式下协调节点发给工作节点的插件搜索请求
+type ClusterSearchRequest struct {
+	Keyword string                 `json:"kw"`      // 搜索关键词
+	Plugins []string               `json:"plugins"` // 由该工作节点执行的插件
+	Refresh bool                   `json:"refresh"` // 是否跳过工作节点的插件缓存
+	Ext     map[string]interface{} `json:"ext"`     // 扩展参数
+}
@@ -4,9 +4,14 @@ import "time"
 
 // Link 网盘链接
 type Link struct {
-	Type     string `json:"type" sonic:"type"`
-	URL      string `json:"url" sonic:"url"`
-	Password string `json:"password" sonic:"password"`
+	Type       string     `json:"type" sonic:"type"`
+	URL        string     `json:"url" sonic:"url"`
+	Password   string     `json:"password" sonic:"password"`
+	Size       int64      `json:"size,omitempty" sonic:"size,omitempty"`               // 文件总大小（字节），0表示未知
+	FileCount  int        `json:"file_count,omitempty" sonic:"file_count,omitempty"`   // 文件数量，0表示未知
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" sonic:"expires_at,omitempty"`   // 分享链接过期时间，nil表示未知
+	SourceNote string     `json:"source_note,omitempty" sonic:"source_note,omitempty"` // 来源附加说明，如清晰度版本
+	PasswordHidden bool `json:"password_hidden,omitempty" sonic:"password_hidden,omitempty"` // 提取码未直接返回，通过跳转地址访问时自动附带
 }
 
 // SearchResult 搜索结果
@@ -20,6 +25,13 @@ type SearchResult struct {
 	Links     []Link    `json:"links" sonic:"links"`
 	Tags      []string  `json:"tags,omitempty" sonic:"tags,omitempty"`
 	Images    []string  `json:"images,omitempty" sonic:"images,omitempty"` // TG消息中的图片链接
+	Language  string    `json:"lang,omitempty" sonic:"lang,omitempty"`     // 推断的语言/地区：zh-CN、zh-TW、en、jp
+	MentionedChannels []string `json:"-" sonic:"-"` // 消息转发来源及引用的其他TG频道，仅用于频道发现，不输出也不缓存
+	ClusterSize    int      `json:"cluster_size,omitempty" sonic:"cluster_size,omitempty"`       // group=true时同类结果数（含自身）
+	ClusterMembers []string `json:"cluster_members,omitempty" sonic:"cluster_members,omitempty"` // group=true时同类其他结果的唯一ID
+	CommunitySource bool `json:"community_source,omitempty" sonic:"community_source,omitempty"` // 来自社区来源，使用前请核实
+	Highlights      *Highlights `json:"highlights,omitempty" sonic:"highlights,omitempty"`         // highlight=true时关键词在标题和内容中的命中位置
+	Attributes      *ResultAttributes `json:"attributes,omitempty" sonic:"attributes,omitempty"` // 从标题解析的资源质量信息，无法识别时为nil
 }
 
 // MergedLink 合并后的网盘链接
@@ -30,16 +42,138 @@ type MergedLink struct {
 	Datetime time.Time `json:"datetime" sonic:"datetime"`
 	Source   string    `json:"source,omitempty" sonic:"source,omitempty"` // 数据来源：tg:频道名 或 plugin:插件名
 	Images   []string  `json:"images,omitempty" sonic:"images,omitempty"`   // TG消息中的图片链接
+	LinkID   string    `json:"link_id,omitempty" sonic:"link_id,omitempty"` // 跳转ID，通过 /go/{link_id} 访问并统计点击
+	Language string    `json:"lang,omitempty" sonic:"lang,omitempty"`       // 推断的语言/地区：zh-CN、zh-TW、en、jp
+	Size       int64      `json:"size,omitempty" sonic:"size,omitempty"`               // 文件总大小（字节），0表示未知
+	FileCount  int        `json:"file_count,omitempty" sonic:"file_count,omitempty"`   // 文件数量，0表示未知
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" sonic:"expires_at,omitempty"`   // 分享链接过期时间，nil表示未知
+	SourceNote string     `json:"source_note,omitempty" sonic:"source_note,omitempty"` // 来源附加说明，如清晰度版本
+	IsNew      bool       `json:"is_new,omitempty" sonic:"is_new,omitempty"`           // 关键词上次被搜索后新出现的链接
+	PasswordHidden  bool `json:"password_hidden,omitempty" sonic:"password_hidden,omitempty"`   // 提取码未直接返回，通过跳转地址访问时自动附带
+	CommunitySource bool `json:"community_source,omitempty" sonic:"community_source,omitempty"` // 来自社区来源，使用前请核实
+	Highlights      *Highlights `json:"highlights,omitempty" sonic:"highlights,omitempty"`         // highlight=true时关键词在note中的命中位置
+	DeepLinks       map[string]string `json:"deep_links,omitempty" sonic:"deep_links,omitempty"`   // deep_links=true时按模板生成的客户端跳转链接，名称 -> 链接
+	Attributes      *ResultAttributes `json:"attributes,omitempty" sonic:"attributes,omitempty"`   // 从note解析的资源质量信息，无法识别时为nil
 }
 
 // MergedLinks 按网盘类型分组的合并链接
 type MergedLinks map[string][]MergedLink
 
+// FlatLink 扁平列表中的链接（result_type=flat），各网盘类型的链接按统一排序排列在一个列表中
+type FlatLink struct {
+	Type       string     `json:"type" sonic:"type"`
+	URL        string     `json:"url" sonic:"url"`
+	Password   string     `json:"password" sonic:"password"`
+	Title      string     `json:"title" sonic:"title"`
+	Datetime   time.Time  `json:"datetime" sonic:"datetime"`
+	Source     string     `json:"source,omitempty" sonic:"source,omitempty"`
+	Images     []string   `json:"images,omitempty" sonic:"images,omitempty"`
+	LinkID     string     `json:"link_id,omitempty" sonic:"link_id,omitempty"`
+	Language   string     `json:"lang,omitempty" sonic:"lang,omitempty"`
+	Size       int64      `json:"size,omitempty" sonic:"size,omitempty"`
+	FileCount  int        `json:"file_count,omitempty" sonic:"file_count,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" sonic:"expires_at,omitempty"`
+	SourceNote string     `json:"source_note,omitempty" sonic:"source_note,omitempty"`
+	IsNew      bool       `json:"is_new,omitempty" sonic:"is_new,omitempty"`
+	PasswordHidden  bool `json:"password_hidden,omitempty" sonic:"password_hidden,omitempty"`
+	CommunitySource bool `json:"community_source,omitempty" sonic:"community_source,omitempty"`
+	Highlights      *Highlights `json:"highlights,omitempty" sonic:"highlights,omitempty"` // highlight=true时关键词在title中的命中位置
+	DeepLinks       map[string]string `json:"deep_links,omitempty" sonic:"deep_links,omitempty"` // deep_links=true时按模板生成的客户端跳转链接
+	Attributes      *ResultAttributes `json:"attributes,omitempty" sonic:"attributes,omitempty"` // 从title解析的资源质量信息
+}
+
+// HighlightSpan 关键词的一处命中，Start、End为按字符（Unicode码点）计算的偏移，End不包含
+type HighlightSpan struct {
+	Start int `json:"start" sonic:"start"`
+	End   int `json:"end" sonic:"end"`
+}
+
+// ResultAttributes 从标题中解析的资源质量信息，零值字段表示标题中没有标注
+type ResultAttributes struct {
+	Resolution string `json:"resolution,omitempty" sonic:"resolution,omitempty"` // 分辨率：4320p、2160p、1080p、720p、480p
+	Codec      string `json:"codec,omitempty" sonic:"codec,omitempty"`           // 视频编码：h265、h264、av1
+	HDR        bool   `json:"hdr,omitempty" sonic:"hdr,omitempty"`               // HDR或杜比视界
+	FPS        int    `json:"fps,omitempty" sonic:"fps,omitempty"`               // 帧率，如60
+	Subtitles  bool   `json:"subtitles,omitempty" sonic:"subtitles,omitempty"`   // 标注了中文字幕、双语字幕等
+	Complete   bool   `json:"complete,omitempty" sonic:"complete,omitempty"`     // 标注了完结、全集
+	Size       int64  `json:"size,omitempty" sonic:"size,omitempty"`             // 标题中标注的大小（字节）
+}
+
+// Highlights 关键词在各字段中的命中位置，按Start升序且互不重叠，没有命中的字段不返回
+type Highlights struct {
+	Title   []HighlightSpan `json:"title,omitempty" sonic:"title,omitempty"`
+	Content []HighlightSpan `json:"content,omitempty" sonic:"content,omitempty"`
+	Note    []HighlightSpan `json:"note,omitempty" sonic:"note,omitempty"`
+}
+
 // SearchResponse 搜索响应
 type SearchResponse struct {
 	Total        int           `json:"total" sonic:"total"`
 	Results      []SearchResult `json:"results,omitempty" sonic:"results,omitempty"`
 	MergedByType MergedLinks   `json:"merged_by_type,omitempty" sonic:"merged_by_type,omitempty"`
+	Links        []FlatLink    `json:"links,omitempty" sonic:"links,omitempty"` // result_type=flat时的扁平链接列表
+	NextPageToken string       `json:"next_page_token,omitempty" sonic:"next_page_token,omitempty"` // 下一页令牌，为空表示没有更多结果
+	Truncated      bool           `json:"truncated,omitempty" sonic:"truncated,omitempty"`             // 结果超过RESPONSE_LINK_CAP，已按排序截断
+	DroppedByType  map[string]int `json:"dropped_by_type,omitempty" sonic:"dropped_by_type,omitempty"` // 截断时各网盘类型被丢弃的链接数
+	DroppedResults int            `json:"dropped_results,omitempty" sonic:"dropped_results,omitempty"` // 截断时被丢弃的results条数
+	Category       string         `json:"category,omitempty" sonic:"category,omitempty"`               // 关键词的内容分类：video、anime、music、software、adult、ebook、general
+	Filtered       []FilteredResult `json:"filtered,omitempty" sonic:"filtered,omitempty"`             // include_filtered=true时返回的未进入results的结果
+	Maintenance    bool           `json:"maintenance,omitempty" sonic:"maintenance,omitempty"`         // 服务处于维护模式，结果只来自缓存
+	Notice         string         `json:"notice,omitempty" sonic:"notice,omitempty"`                   // 维护模式下的提示
+	Timing         *SearchTiming  `json:"timing,omitempty" sonic:"timing,omitempty"`                   // debug=true时返回的耗时明细
+}
+
+// 结果未进入results的原因
+const (
+	FilterReasonNoTime         = "no_time"          // 没有发布时间
+	FilterReasonLowLevelPlugin = "low_level_plugin" // 来自3、4级插件或TG频道，且标题不含优先关键词
+)
+
+// FilteredResult 找到但未进入results的结果及原因
+type FilteredResult struct {
+	SearchResult
+	FilterReasons []string `json:"filter_reasons" sonic:"filter_reasons"`
+}
+
+// SearchTiming 搜索的耗时明细，用于排查搜索慢的原因
+type SearchTiming struct {
+	TotalMs  int64          `json:"total_ms" sonic:"total_ms"`                   // 搜索总耗时
+	SearchMs int64          `json:"search_ms" sonic:"search_ms"`                 // 读取缓存和等待TG频道、插件返回的耗时
+	MergeMs  int64          `json:"merge_ms" sonic:"merge_ms"`                   // 结果排序、过滤和合并链接的耗时
+	Cache    []CacheTiming  `json:"cache,omitempty" sonic:"cache,omitempty"`     // 各次缓存读取
+	Sources  []SourceTiming `json:"sources,omitempty" sonic:"sources,omitempty"` // 本次实际请求的TG频道、插件和集群工作节点
+
+	Provenance map[string]string `json:"provenance,omitempty" sonic:"provenance,omitempty"` // 各来源（tg:频道名、plugin:插件名）结果的出处
+}
+
+// 结果出处（SearchTiming.Provenance的取值）
+const (
+	ProvenanceLive             = "live"                   // 本次请求实时搜索
+	ProvenancePartial          = "partial"                // 插件未在响应超时内完成，本次没有结果，后台继续搜索
+	ProvenancePluginCache      = "plugin-mem-cache"       // 插件内存缓存
+	ProvenancePluginCacheStale = "plugin-mem-cache-stale" // 插件内存缓存中已过期或不完整的结果，后台刷新中
+	ProvenanceMainCacheMemory  = "main-cache-memory"      // 主缓存的内存层
+	ProvenanceMainCacheDisk    = "main-cache-disk"        // 主缓存的磁盘层
+	ProvenanceWorker           = "worker"                 // 集群工作节点返回的结果
+)
+
+// CacheTiming 一次搜索缓存读取
+type CacheTiming struct {
+	Source    string `json:"source" sonic:"source"`         // tg或plugin
+	Keyword   string `json:"keyword" sonic:"keyword"`       // 搜索的关键词
+	Level     string `json:"level" sonic:"level"`           // 命中的层级：memory、disk，未命中为miss
+	ElapsedMs int64  `json:"elapsed_ms" sonic:"elapsed_ms"` // 读取耗时
+}
+
+// SourceTiming 一个搜索来源的耗时
+type SourceTiming struct {
+	Type      string `json:"type" sonic:"type"`                       // tg、plugin或worker（集群工作节点）
+	Name      string `json:"name" sonic:"name"`                       // 频道名、插件名或工作节点地址
+	Keyword   string `json:"keyword" sonic:"keyword"`                 // 搜索的关键词
+	ElapsedMs int64  `json:"elapsed_ms" sonic:"elapsed_ms"`           // 耗时，超时的来源为等待的时长
+	TimedOut  bool   `json:"timed_out" sonic:"timed_out"`             // 是否超时
+	Results   int    `json:"results" sonic:"results"`                 // 返回的结果数
+	Error     string `json:"error,omitempty" sonic:"error,omitempty"` // 出错时的错误信息
 }
 
 // Response API通用响应
@@ -47,6 +181,7 @@ type Response struct {
 	Code    int         `json:"code" sonic:"code"`
 	Message string      `json:"message" sonic:"message"`
 	Data    interface{} `json:"data,omitempty" sonic:"data,omitempty"`
+	RequestID string    `json:"request_id,omitempty" sonic:"request_id,omitempty"` // 请求ID，便于排查问题
 }
 
 // NewSuccessResponse 创建成功响应
@@ -64,4 +199,16 @@ func NewErrorResponse(code int, message string) Response {
 		Code:    code,
 		Message: message,
 	}
-}
\ No newline at end of file
+}
+
+// WithRequestID 为响应附加请求ID
+func (r Response) WithRequestID(requestID string) Response {
+	r.RequestID = requestID
+	return r
+}
+
+// ClusterSearchResponse 工作节点返回的插件搜索结果
+type ClusterSearchResponse struct {
+	Results []SearchResult `json:"results" sonic:"results"`
+	Partial []string       `json:"partial,omitempty" sonic:"partial,omitempty"` // 结果仍不完整、在工作节点后台继续搜索的插件
+}
@@ -0,0 +1,41 @@
+package model
+
+// 排序方式
+const (
+	RankingDefault = "default" // 综合得分：发布时间、优先关键词、插件等级
+	RankingTime    = "time"    // 按发布时间倒序
+	RankingSize    = "size"    // 按链接大小倒序，大小未知的排在最后
+)
+
+// SearchFilter POST搜索请求中的结构化过滤条件
+type SearchFilter struct {
+	Sources       *SourceFilter    `json:"sources"`        // 数据来源
+	CloudTypes    []string         `json:"cloud_types"`    // 网盘类型
+	Languages     []string         `json:"lang"`           // 语言/地区
+	MinResolution string           `json:"min_resolution"` // 最低分辨率：480p、720p、1080p、2160p（4k）、4320p（8k）
+	TimeRange     *TimeRangeFilter `json:"time_range"`     // 发布时间范围
+	Ranking       string           `json:"ranking"`        // 排序方式：default、time、size
+	Page          *PageFilter      `json:"page"`           // 分页
+}
+
+// SourceFilter 数据来源过滤
+type SourceFilter struct {
+	Type     string   `json:"type"`     // all、tg、plugin
+	Channels []string `json:"channels"` // TG频道列表
+	Plugins  []string `json:"plugins"`  // 插件列表
+	Profile  string   `json:"profile"`  // 插件组合
+}
+
+// TimeRangeFilter 发布时间范围，from/to为RFC3339时间或YYYY-MM-DD日期，
+// within为相对当前时间的范围（如"24h"、"7d"），不能与from同时使用
+type TimeRangeFilter struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Within string `json:"within"`
+}
+
+// PageFilter 分页参数
+type PageFilter struct {
+	Size  int    `json:"size"`  // 分页大小
+	Token string `json:"token"` // 分页令牌
+}
@@ -0,0 +1,25 @@
+package model
+
+import "strings"
+
+// Tenant 租户：同一实例服务多个前端时，每个租户使用独立的缓存命名空间、插件集合和限流配额
+type Tenant struct {
+	ID        string   `json:"id"`
+	APIKeys   []string `json:"api_keys,omitempty"`   // 租户的API Key，配置后必须通过X-API-Key识别该租户
+	Plugins   []string `json:"plugins,omitempty"`    // 允许使用的插件，为空表示全部插件
+	RateLimit int      `json:"rate_limit,omitempty"` // 每分钟允许的搜索请求数，0表示不限制
+	Burst     int      `json:"burst,omitempty"`      // 允许的突发请求数，默认等于RateLimit
+}
+
+// AllowsPlugin 租户是否允许使用指定插件（插件名不区分大小写）
+func (t *Tenant) AllowsPlugin(name string) bool {
+	if len(t.Plugins) == 0 {
+		return true
+	}
+	for _, p := range t.Plugins {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
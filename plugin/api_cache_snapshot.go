@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	jsonutil "pansou/util/json"
+)
+
+// apiCacheSnapshotEntry 快照中的插件API响应缓存条目
+type apiCacheSnapshotEntry struct {
+	Key      string         `json:"key"`
+	Response cachedResponse `json:"response"`
+}
+
+// apiCacheMaxAge 插件API响应缓存的最长保留时间，与定期清理的阈值一致
+func apiCacheMaxAge() time.Duration {
+	return defaultCacheTTL + 30*time.Minute
+}
+
+// SnapshotAPIResponseCache 导出插件API响应缓存的快照，只保留最近访问的maxEntries个未过期条目
+// （maxEntries<=0时不限制），返回快照数据和条目数。skipPlugin不为nil时跳过其返回true的插件的条目
+func SnapshotAPIResponseCache(maxEntries int, skipPlugin func(name string) bool) ([]byte, int, error) {
+	now := time.Now()
+	var entries []apiCacheSnapshotEntry
+	apiResponseCache.Range(func(key, value interface{}) bool {
+		keyStr, ok := key.(string)
+		cached, isCached := value.(cachedResponse)
+		if ok && skipPlugin != nil {
+			// 缓存键以插件名开头，见pluginCacheKey
+			name, _, _ := strings.Cut(keyStr, ":")
+			ok = !skipPlugin(name)
+		}
+		if ok && isCached && now.Sub(cached.Timestamp) <= apiCacheMaxAge() {
+			entries = append(entries, apiCacheSnapshotEntry{Key: keyStr, Response: cached})
+		}
+		return true
+	})
+
+	sort.Slice(entries, func(i, j int) bool {
+		return lastUsed(entries[i].Response).After(lastUsed(entries[j].Response))
+	})
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+
+	data, err := jsonutil.Marshal(entries)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, len(entries), nil
+}
+
+// RestoreAPIResponseCache 从快照恢复插件API响应缓存，跳过已过期的条目和内存中已存在的键，返回恢复的条目数
+func RestoreAPIResponseCache(data []byte) (int, error) {
+	var entries []apiCacheSnapshotEntry
+	if err := jsonutil.Unmarshal(data, &entries); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	restored := 0
+	for _, entry := range entries {
+		if entry.Key == "" || now.Sub(entry.Response.Timestamp) > apiCacheMaxAge() {
+			continue
+		}
+		if _, loaded := apiResponseCache.LoadOrStore(entry.Key, entry.Response); !loaded {
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// lastUsed 缓存条目最近一次被访问或写入的时间
+func lastUsed(cached cachedResponse) time.Time {
+	if cached.LastAccess.After(cached.Timestamp) {
+		return cached.LastAccess
+	}
+	return cached.Timestamp
+}
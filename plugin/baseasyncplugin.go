@@ -10,11 +10,12 @@ import (
 
 	"pansou/config"
 	"pansou/model"
+	"pansou/util"
 )
 
 // 工作池和统计相关变量
 var (
-	// API响应缓存，键为关键词，值为缓存的响应（仅内存，不持久化）
+	// API响应缓存，键为关键词，值为缓存的响应（内存缓存，可在关闭时保存快照、启动时恢复）
 	apiResponseCache = sync.Map{}
 	
 	// 工作池相关变量
@@ -45,7 +46,7 @@ var (
 	cleanupMutex    sync.Mutex
 )
 
-// 缓存响应结构（仅内存，不持久化到磁盘）
+// 缓存响应结构（内存缓存，快照见api_cache_snapshot.go）
 type cachedResponse struct {
 	Results   []model.SearchResult `json:"results"`
 	Timestamp time.Time           `json:"timestamp"`
@@ -209,6 +210,7 @@ type BaseAsyncPlugin struct {
 	finalUpdateTracker map[string]bool // 追踪已更新的最终结果缓存
 	finalUpdateMutex   sync.RWMutex  // 保护finalUpdateTracker的并发访问
 	skipServiceFilter  bool          // 是否跳过Service层的关键词过滤
+	fallbacks          []FallbackSource // 备用搜索入口，主入口出错或无结果时尝试
 }
 
 // NewBaseAsyncPlugin 创建基础异步插件
@@ -230,14 +232,23 @@ func NewBaseAsyncPlugin(name string, priority int) *BaseAsyncPlugin {
 		cacheTTL = time.Duration(config.AppConfig.AsyncCacheTTLHours) * time.Hour
 	}
 	
+	// 短超时和长超时客户端共享插件自己的连接池，配额受全局连接预算约束，临时失败按默认策略重试
+	transport := util.NewPluginTransport(name, nil)
+	// 两个客户端共享插件的持久化Cookie，是否启用由COOKIE_JAR_PLUGINS决定
+	jar := util.PluginCookieJar(name)
+	
 	return &BaseAsyncPlugin{
 		name:     name,
 		priority: priority,
 		client: &http.Client{
-			Timeout: responseTimeout,
+			Transport: transport,
+			Timeout:   responseTimeout,
+			Jar:       jar,
 		},
 		backgroundClient: &http.Client{
-			Timeout: processingTimeout,
+			Transport: transport,
+			Timeout:   processingTimeout,
+			Jar:       jar,
 		},
 		cacheTTL:           cacheTTL,
 		finalUpdateTracker: make(map[string]bool), // 初始化缓存更新追踪器
@@ -264,14 +275,23 @@ func NewBaseAsyncPluginWithFilter(name string, priority int, skipServiceFilter b
 		cacheTTL = time.Duration(config.AppConfig.AsyncCacheTTLHours) * time.Hour
 	}
 	
+	// 短超时和长超时客户端共享插件自己的连接池，配额受全局连接预算约束，临时失败按默认策略重试
+	transport := util.NewPluginTransport(name, nil)
+	// 两个客户端共享插件的持久化Cookie，是否启用由COOKIE_JAR_PLUGINS决定
+	jar := util.PluginCookieJar(name)
+	
 	return &BaseAsyncPlugin{
 		name:     name,
 		priority: priority,
 		client: &http.Client{
-			Timeout: responseTimeout,
+			Transport: transport,
+			Timeout:   responseTimeout,
+			Jar:       jar,
 		},
 		backgroundClient: &http.Client{
-			Timeout: processingTimeout,
+			Transport: transport,
+			Timeout:   processingTimeout,
+			Jar:       jar,
 		},
 		cacheTTL:           cacheTTL,
 		finalUpdateTracker: make(map[string]bool), // 初始化缓存更新追踪器
@@ -294,6 +314,11 @@ func (p *BaseAsyncPlugin) SetMainCacheUpdater(updater func(string, []model.Searc
 	p.mainCacheUpdater = updater
 }
 
+// HasMainCacheUpdater 是否已注入主缓存更新函数（启动自检使用）
+func (p *BaseAsyncPlugin) HasMainCacheUpdater() bool {
+	return p.mainCacheUpdater != nil
+}
+
 // Name 返回插件名称
 func (p *BaseAsyncPlugin) Name() string {
 	return p.name
@@ -309,6 +334,44 @@ func (p *BaseAsyncPlugin) SkipServiceFilter() bool {
 	return p.skipServiceFilter
 }
 
+// ResetState 清除插件在异步插件缓存中的API响应、发现类请求的响应缓存和最终结果更新记录
+func (p *BaseAsyncPlugin) ResetState() {
+	util.ClearResponseCache(p.name)
+	
+	prefix := p.name + ":"
+	apiResponseCache.Range(func(key, value interface{}) bool {
+		if k, ok := key.(string); ok && strings.HasPrefix(k, prefix) {
+			apiResponseCache.Delete(key)
+		}
+		return true
+	})
+	
+	p.finalUpdateMutex.Lock()
+	p.finalUpdateTracker = make(map[string]bool)
+	p.finalUpdateMutex.Unlock()
+}
+
+// ForgetCachedSearch 删除插件对关键词的API响应缓存（包括不同ext参数的缓存），下次搜索该关键词时重新请求站点
+func ForgetCachedSearch(name string, keyword string) {
+	key := name + ":" + keyword
+	apiResponseCache.Range(func(k, value interface{}) bool {
+		if s, ok := k.(string); ok && (s == key || strings.HasPrefix(s, key+":")) {
+			apiResponseCache.Delete(k)
+		}
+		return true
+	})
+}
+
+// pluginCacheKey 生成插件内存缓存键"插件名:关键词"，ext中有插件注册的参数时追加参数哈希，
+// 不同ext参数的搜索结果分别缓存
+func (p *BaseAsyncPlugin) pluginCacheKey(keyword string, ext map[string]interface{}) string {
+	key := fmt.Sprintf("%s:%s", p.name, keyword)
+	if extHash := ExtCacheHash(ext, p.name); extHash != "" {
+		key += ":" + extHash
+	}
+	return key
+}
+
 // AsyncSearch 异步搜索基础方法
 func (p *BaseAsyncPlugin) AsyncSearch(
 	keyword string,
@@ -323,8 +386,8 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 	
 	now := time.Now()
 	
-	// 修改缓存键，确保包含插件名称
-	pluginSpecificCacheKey := fmt.Sprintf("%s:%s", p.name, keyword)
+	// 修改缓存键，确保包含插件名称和影响结果的ext参数
+	pluginSpecificCacheKey := p.pluginCacheKey(keyword, ext)
 	
 	// 检查缓存
 	if cachedItems, ok := apiResponseCache.Load(pluginSpecificCacheKey); ok {
@@ -334,6 +397,8 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		if time.Since(cachedResult.Timestamp) < p.cacheTTL && cachedResult.Complete {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
+			util.PluginDebugf(p.name, "%s命中插件缓存: %s | 结果数: %d | 缓存时长: %v", requestLogTag(ext), keyword, len(cachedResult.Results), time.Since(cachedResult.Timestamp))
+			recordProvenance(ext, p.name, model.ProvenancePluginCache)
 			
 			// 如果缓存接近过期（已用时间超过TTL的80%），在后台刷新缓存
 			if time.Since(cachedResult.Timestamp) > (p.cacheTTL * 4 / 5) {
@@ -347,6 +412,8 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		if len(cachedResult.Results) > 0 {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
+			util.PluginDebugf(p.name, "%s命中不完整或过期的插件缓存: %s | 结果数: %d | 缓存时长: %v", requestLogTag(ext), keyword, len(cachedResult.Results), time.Since(cachedResult.Timestamp))
+			recordProvenance(ext, p.name, model.ProvenancePluginCacheStale)
 			
 			// 标记为部分过期
 			if time.Since(cachedResult.Timestamp) >= p.cacheTTL {
@@ -354,8 +421,8 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 				go p.refreshCacheInBackground(keyword, pluginSpecificCacheKey, searchFunc, cachedResult, mainCacheKey, ext)
 				
 				// 日志记录
-				fmt.Printf("[%s] 缓存已过期，后台刷新中: %s (已过期: %v)\n", 
-					p.name, pluginSpecificCacheKey, time.Since(cachedResult.Timestamp))
+				fmt.Printf("%s[%s] 缓存已过期，后台刷新中: %s (已过期: %v)\n", 
+					requestLogTag(ext), p.name, pluginSpecificCacheKey, time.Since(cachedResult.Timestamp))
 			}
 			
 			return cachedResult.Results, nil
@@ -371,10 +438,12 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 	
 	// 启动后台处理
 	go func() {
+		defer RecoverPluginPanic(p.name)
+		
 		// 尝试获取工作槽
 		if !acquireWorkerSlot() {
 			// 工作池已满，使用快速响应客户端直接处理
-			results, err := searchFunc(p.client, keyword, ext)
+			results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.client), keyword, ext)
 			if err != nil {
 				select {
 				case errorChan <- err:
@@ -405,7 +474,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		defer releaseWorkerSlot()
 		
 		// 执行搜索
-		results, err := searchFunc(p.backgroundClient, keyword, ext)
+		results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.backgroundClient), keyword, ext)
 		
 		// 检查是否已经响应
 		select {
@@ -525,6 +594,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 	select {
 	case results := <-resultChan:
 		close(doneChan)
+		recordProvenance(ext, p.name, model.ProvenanceLive)
 		return results, nil
 	case err := <-errorChan:
 		close(doneChan)
@@ -543,8 +613,11 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 			if len(cachedResult.Results) > 0 {
 				// 有部分缓存可用，记录访问并返回
 				recordCacheAccess(pluginSpecificCacheKey)
-				fmt.Printf("[%s] 响应超时，返回部分缓存: %s (项目数: %d)\n", 
-					p.name, pluginSpecificCacheKey, len(cachedResult.Results))
+				fmt.Printf("%s[%s] 响应超时，返回部分缓存: %s (项目数: %d)\n", 
+					requestLogTag(ext), p.name, pluginSpecificCacheKey, len(cachedResult.Results))
+				// 通知主缓存该插件结果不完整，后台完成后写入最终结果
+				p.updateMainCacheWithFinal(mainCacheKey, []model.SearchResult{}, false)
+				recordProvenance(ext, p.name, model.ProvenancePluginCacheStale)
 				return cachedResult.Results, nil
 			}
 		}
@@ -562,6 +635,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		p.updateMainCacheWithFinal(mainCacheKey, []model.SearchResult{}, false)
 		
 		// fmt.Printf("[%s] 响应超时，后台继续处理: %s\n", p.name, pluginSpecificCacheKey)
+		recordProvenance(ext, p.name, model.ProvenancePartial)
 		return []model.SearchResult{}, nil
 	}
 }
@@ -580,8 +654,8 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 	
 	now := time.Now()
 	
-	// 修改缓存键，确保包含插件名称
-	pluginSpecificCacheKey := fmt.Sprintf("%s:%s", p.name, keyword)
+	// 修改缓存键，确保包含插件名称和影响结果的ext参数
+	pluginSpecificCacheKey := p.pluginCacheKey(keyword, ext)
 	
 	// 检查缓存
 	if cachedItems, ok := apiResponseCache.Load(pluginSpecificCacheKey); ok {
@@ -591,6 +665,8 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		if time.Since(cachedResult.Timestamp) < p.cacheTTL && cachedResult.Complete {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
+			util.PluginDebugf(p.name, "%s命中插件缓存: %s | 结果数: %d | 缓存时长: %v", requestLogTag(ext), keyword, len(cachedResult.Results), time.Since(cachedResult.Timestamp))
+			recordProvenance(ext, p.name, model.ProvenancePluginCache)
 			
 			// 如果缓存接近过期（已用时间超过TTL的80%），在后台刷新缓存
 			if time.Since(cachedResult.Timestamp) > (p.cacheTTL * 4 / 5) {
@@ -610,6 +686,8 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		if len(cachedResult.Results) > 0 {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
+			util.PluginDebugf(p.name, "%s命中不完整或过期的插件缓存: %s | 结果数: %d | 缓存时长: %v", requestLogTag(ext), keyword, len(cachedResult.Results), time.Since(cachedResult.Timestamp))
+			recordProvenance(ext, p.name, model.ProvenancePluginCacheStale)
 			
 			// 标记为部分过期
 			if time.Since(cachedResult.Timestamp) >= p.cacheTTL {
@@ -636,6 +714,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 	
 	// 启动后台处理
 	go func() {
+		defer RecoverPluginPanic(p.name)
 		defer func() {
 			select {
 			case <-doneChan:
@@ -647,7 +726,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		// 尝试获取工作槽
 		if !acquireWorkerSlot() {
 			// 工作池已满，使用快速响应客户端直接处理
-			results, err := searchFunc(p.client, keyword, ext)
+			results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.client), keyword, ext)
 			if err != nil {
 				select {
 				case errorChan <- err:
@@ -665,7 +744,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		defer releaseWorkerSlot()
 		
 		// 使用长超时客户端进行搜索
-		results, err := searchFunc(p.backgroundClient, keyword, ext)
+		results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.backgroundClient), keyword, ext)
 		if err != nil {
 			select {
 			case errorChan <- err:
@@ -703,10 +782,11 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		if mainCacheKey != "" && p.mainCacheUpdater != nil {
 			err := p.mainCacheUpdater(mainCacheKey, results, p.cacheTTL, true, p.currentKeyword)
 			if err != nil {
-				fmt.Printf("❌ [%s] 及时完成缓存更新失败: %s | 错误: %v\n", p.name, mainCacheKey, err)
+				fmt.Printf("%s❌ [%s] 及时完成缓存更新失败: %s | 错误: %v\n", requestLogTag(ext), p.name, mainCacheKey, err)
 			}
 		}
 		
+		recordProvenance(ext, p.name, model.ProvenanceLive)
 		return model.PluginSearchResult{
 			Results:   results,
 			IsFinal:   true, // 🔥 及时完成，最终结果
@@ -723,6 +803,9 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		// 🔥 超时处理：返回空结果，后台继续处理
 		go p.completeSearchInBackground(keyword, searchFunc, pluginSpecificCacheKey, mainCacheKey, doneChan, ext)
 		
+		// 通知主缓存该插件结果不完整
+		p.updateMainCacheWithFinal(mainCacheKey, []model.SearchResult{}, false)
+		
 		// 存储临时缓存（标记为不完整）
 		apiResponseCache.Store(pluginSpecificCacheKey, cachedResponse{
 			Results:     []model.SearchResult{},
@@ -732,6 +815,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 			AccessCount: 1,
 		})
 		
+		recordProvenance(ext, p.name, model.ProvenancePartial)
 		return model.PluginSearchResult{
 			Results:   []model.SearchResult{},
 			IsFinal:   false, // 🔥 超时返回，非最终结果
@@ -760,7 +844,7 @@ func (p *BaseAsyncPlugin) completeSearchInBackground(
 	}()
 	
 	// 执行完整搜索
-	results, err := searchFunc(p.backgroundClient, keyword, ext)
+	results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.backgroundClient), keyword, ext)
 	if err != nil {
 		return
 	}
@@ -780,7 +864,7 @@ func (p *BaseAsyncPlugin) completeSearchInBackground(
 	if mainCacheKey != "" && p.mainCacheUpdater != nil {
 		err := p.mainCacheUpdater(mainCacheKey, results, p.cacheTTL, true, p.currentKeyword)
 		if err != nil {
-			fmt.Printf("❌ [%s] 后台完成缓存更新失败: %s | 错误: %v\n", p.name, mainCacheKey, err)
+			fmt.Printf("%s❌ [%s] 后台完成缓存更新失败: %s | 错误: %v\n", requestLogTag(ext), p.name, mainCacheKey, err)
 		}
 	}
 }
@@ -811,7 +895,7 @@ func (p *BaseAsyncPlugin) refreshCacheInBackground(
 	refreshStart := time.Now()
 	
 	// 执行搜索
-	results, err := searchFunc(p.backgroundClient, keyword, ext)
+	results, err := p.searchOnce(searchFunc, withSearchRetryBudget(p.backgroundClient), keyword, ext)
 	if err != nil || len(results) == 0 {
 		return
 	}
@@ -847,8 +931,8 @@ func (p *BaseAsyncPlugin) refreshCacheInBackground(
 	
 	// 记录刷新时间
 	refreshTime := time.Since(refreshStart)
-	fmt.Printf("[%s] 后台刷新完成: %s (耗时: %v, 新项目: %d, 合并项目: %d)\n", 
-		p.name, cacheKey, refreshTime, len(results), len(mergedResults))
+	fmt.Printf("%s[%s] 后台刷新完成: %s (耗时: %v, 新项目: %d, 合并项目: %d)\n", 
+		requestLogTag(ext), p.name, cacheKey, refreshTime, len(results), len(mergedResults))
 	
 	// 异步插件本地缓存系统已移除
 } 
@@ -866,7 +950,13 @@ func (p *BaseAsyncPlugin) updateMainCacheWithFinal(cacheKey string, results []mo
 	}
 	
 	// 🚀 优化：如果新结果为空，跳过缓存更新（避免无效操作）
+	// 非最终的空结果仍需通知主程序，以便按部分结果处理该缓存键
 	if len(results) == 0 {
+		if !isFinal {
+			if err := p.mainCacheUpdater(cacheKey, results, p.cacheTTL, false, p.currentKeyword); err != nil {
+				fmt.Printf("❌ [%s] 主缓存更新失败: %s | 错误: %v\n", p.name, cacheKey, err)
+			}
+		}
 		return
 	}
 	
@@ -934,6 +1024,17 @@ func (p *BaseAsyncPlugin) FilterResultsByKeyword(results []model.SearchResult, k
 	return filteredResults
 } 
 
+// withSearchRetryBudget 为单次搜索复制客户端：共享连接池和重试策略，但使用独立的重试预算
+func withSearchRetryBudget(client *http.Client) *http.Client {
+	transport, ok := client.Transport.(*util.RetryTransport)
+	if !ok {
+		return client
+	}
+	searchClient := *client
+	searchClient.Transport = transport.WithBudget(util.NewSearchRetryBudget())
+	return &searchClient
+}
+
 // GetClient 返回短超时客户端
 func (p *BaseAsyncPlugin) GetClient() *http.Client {
 	return p.client
@@ -967,6 +1068,11 @@ func SetGlobalCacheSerializer(serializer interface {
 	globalCacheSerializer = serializer
 }
 
+// HasGlobalCacheSerializer 是否已注入主缓存的序列化器（启动自检使用）
+func HasGlobalCacheSerializer() bool {
+	return globalCacheSerializer != nil
+}
+
 // getEnhancedCacheSerializer 获取增强缓存的序列化器
 func getEnhancedCacheSerializer() interface {
 	Serialize(interface{}) ([]byte, error)
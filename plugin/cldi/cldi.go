@@ -14,6 +14,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 type CldiPlugin struct {
@@ -44,6 +45,7 @@ func init() {
 		BaseAsyncPlugin: plugin.NewBaseAsyncPluginWithFilter("cldi", 3, true), // 磁力搜索插件，跳过Service层过滤
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterPluginMetadata(plugin.PluginMetadata{Name: "cldi", CloudTypes: []string{"magnet"}})
 }
 
 // Search 执行搜索并返回结果
@@ -177,31 +179,7 @@ func (p *CldiPlugin) setRequestHeaders(req *http.Request) {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *CldiPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
 
 // extractSearchResults 提取搜索结果
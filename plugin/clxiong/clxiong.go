@@ -45,6 +45,7 @@ func init() {
 		debugMode:       false, // 开启调试模式检查磁力链接提取问题
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterPluginMetadata(plugin.PluginMetadata{Name: "clxiong", DisplayName: "磁力熊", Homepage: BaseURL, CloudTypes: []string{"magnet"}})
 }
 
 // Search 搜索接口实现
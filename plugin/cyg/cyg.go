@@ -15,6 +15,7 @@ import (
 	"pansou/model"
 	"pansou/plugin"
 	"pansou/util/json"
+	"pansou/util"
 )
 
 // 预编译的正则表达式（性能优化）
@@ -82,6 +83,12 @@ func init() {
 		BaseAsyncPlugin: plugin.NewBaseAsyncPlugin("cyg", 3), // 优先级3，标准质量数据源
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterExtSchema("cyg",
+		plugin.ExtField{Key: "per_page", Type: plugin.ExtTypeInt, Default: 20, Description: "每页结果数"},
+		plugin.ExtField{Key: "page", Type: plugin.ExtTypeInt, Default: 1, Description: "页码"},
+		plugin.ExtField{Key: "order_by", Type: plugin.ExtTypeString, Default: "date", Description: "排序字段"},
+		plugin.ExtField{Key: "order", Type: plugin.ExtTypeString, Default: "desc", Description: "排序方向：asc、desc"},
+	)
 }
 
 // Search 执行搜索并返回结果（兼容性方法）
@@ -364,31 +371,7 @@ func (p *CygPlugin) setRequestHeaders(req *http.Request) {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *CygPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
 
 // parseExtOptions 从ext参数中解析搜索选项
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+)
+
+// 未加载配置时的详情页抓取预算
+const (
+	defaultDetailFetchMax     = 40
+	defaultDetailFetchTimeout = 20 * time.Second
+)
+
+// DetailFetchBudget 单次插件搜索的详情页抓取预算：限制抓取次数和总耗时，
+// 避免宽泛关键词命中大量结果时对每条结果都请求详情页
+type DetailFetchBudget struct {
+	maxFetches int64
+	deadline   time.Time
+	used       int64
+	skipped    int64
+}
+
+// NewDetailFetchBudget 创建详情页抓取预算，maxFetches或maxDuration小于等于0表示对应项不限制
+func NewDetailFetchBudget(maxFetches int, maxDuration time.Duration) *DetailFetchBudget {
+	b := &DetailFetchBudget{maxFetches: int64(maxFetches)}
+	if maxDuration > 0 {
+		b.deadline = time.Now().Add(maxDuration)
+	}
+	return b
+}
+
+// NewSearchDetailBudget 按DETAIL_FETCH_MAX和DETAIL_FETCH_TIMEOUT配置创建单次搜索的详情页抓取预算
+func NewSearchDetailBudget() *DetailFetchBudget {
+	if config.AppConfig == nil {
+		return NewDetailFetchBudget(defaultDetailFetchMax, defaultDetailFetchTimeout)
+	}
+	return NewDetailFetchBudget(config.AppConfig.DetailFetchMax, config.AppConfig.DetailFetchTimeout)
+}
+
+// TryAcquire 消耗一次抓取机会，次数用尽或超过总耗时时返回false。nil预算不限制
+func (b *DetailFetchBudget) TryAcquire() bool {
+	if b == nil {
+		return true
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		atomic.AddInt64(&b.skipped, 1)
+		return false
+	}
+	if b.maxFetches > 0 && atomic.AddInt64(&b.used, 1) > b.maxFetches {
+		atomic.AddInt64(&b.skipped, 1)
+		return false
+	}
+	return true
+}
+
+// Skipped 返回因预算用尽而跳过的抓取次数
+func (b *DetailFetchBudget) Skipped() int {
+	if b == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&b.skipped))
+}
+
+// FetchDetails 按排名顺序（索引从小到大）为count条结果抓取详情页，最多concurrency个并发。
+// 排名靠前的结果先占用预算，预算用尽后其余结果不再抓取；阻塞直到已开始的抓取全部完成
+func FetchDetails(budget *DetailFetchBudget, count, concurrency int, fetch func(index int)) {
+	if count <= 0 {
+		return
+	}
+	if concurrency <= 0 || concurrency > count {
+		concurrency = count
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fetch(index)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		if !budget.TryAcquire() {
+			// 剩余结果均计为跳过
+			atomic.AddInt64(&budget.skipped, int64(count-i-1))
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
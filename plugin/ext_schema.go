@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExtType ext参数值类型
+type ExtType string
+
+// ext参数支持的值类型
+const (
+	ExtTypeString ExtType = "string"
+	ExtTypeBool   ExtType = "bool"
+	ExtTypeInt    ExtType = "int"
+	ExtTypeNumber ExtType = "number"
+)
+
+// ExtField 插件支持的一个ext参数
+type ExtField struct {
+	Key         string      `json:"key"`
+	Type        ExtType     `json:"type"`
+	Default     interface{} `json:"default,omitempty"` // 未传入时插件使用的值，仅用于说明
+	Description string      `json:"description"`
+}
+
+// 插件ext参数说明注册表
+var (
+	extSchemas     = make(map[string][]ExtField)
+	extSchemasLock sync.RWMutex
+)
+
+// RegisterExtSchema 注册插件支持的ext参数，在插件init中与RegisterGlobalPlugin一起调用。
+// 以"_"开头的键保留给服务内部使用（如请求ID），不能注册
+func RegisterExtSchema(pluginName string, fields ...ExtField) {
+	extSchemasLock.Lock()
+	defer extSchemasLock.Unlock()
+	for _, field := range fields {
+		if strings.HasPrefix(field.Key, "_") {
+			panic(fmt.Sprintf("插件 %s 的ext参数 %s 使用了保留前缀\"_\"", pluginName, field.Key))
+		}
+	}
+	extSchemas[pluginName] = append(extSchemas[pluginName], fields...)
+}
+
+// GetExtSchema 获取插件注册的ext参数
+func GetExtSchema(pluginName string) []ExtField {
+	extSchemasLock.RLock()
+	defer extSchemasLock.RUnlock()
+	return extSchemas[pluginName]
+}
+
+// ExtCacheHash 计算ext中影响插件结果的参数的哈希，用于缓存键：只包含pluginNames注册过的参数
+// （pluginNames为空时包含所有插件注册的参数），以"_"开头的保留键和空值被忽略。
+// 没有相关参数时返回空字符串，此时缓存键与不带ext时相同
+func ExtCacheHash(ext map[string]interface{}, pluginNames ...string) string {
+	if len(ext) == 0 {
+		return ""
+	}
+
+	relevant := make(map[string]bool)
+	extSchemasLock.RLock()
+	if len(pluginNames) == 0 {
+		for _, fields := range extSchemas {
+			for _, field := range fields {
+				relevant[field.Key] = true
+			}
+		}
+	} else {
+		for _, name := range pluginNames {
+			for _, field := range extSchemas[name] {
+				relevant[field.Key] = true
+			}
+		}
+	}
+	extSchemasLock.RUnlock()
+
+	keys := make([]string, 0, len(ext))
+	for key, value := range ext {
+		if strings.HasPrefix(key, "_") || !relevant[key] || value == nil || value == "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		// 整数参数可能是int或JSON解析出的float64，%v对两者的整数值输出相同
+		fmt.Fprintf(&b, "%s=%v;", key, ext[key])
+	}
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ExtSchemas 获取已启用插件的ext参数说明，按插件名索引，未注册参数的插件不包含在内
+func (pm *PluginManager) ExtSchemas() map[string][]ExtField {
+	schemas := make(map[string][]ExtField)
+	for _, p := range pm.GetPlugins() {
+		if fields := GetExtSchema(p.Name()); len(fields) > 0 {
+			schemas[p.Name()] = fields
+		}
+	}
+	return schemas
+}
+
+// ValidateExt 按已启用插件注册的参数说明校验ext，返回规范化后的副本：
+// 整数参数的JSON数值（float64）转换为int。以"_"开头的保留键原样保留，
+// 未被任何已启用插件支持的键或类型不匹配的值返回错误
+func (pm *PluginManager) ValidateExt(ext map[string]interface{}) (map[string]interface{}, error) {
+	if len(ext) == 0 {
+		return ext, nil
+	}
+
+	fields := make(map[string]ExtField)
+	owners := make(map[string][]string)
+	for _, p := range pm.GetPlugins() {
+		for _, field := range GetExtSchema(p.Name()) {
+			if _, exists := fields[field.Key]; !exists {
+				fields[field.Key] = field
+			}
+			owners[field.Key] = append(owners[field.Key], p.Name())
+		}
+	}
+
+	keys := make([]string, 0, len(ext))
+	for key := range ext {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := make(map[string]interface{}, len(ext))
+	for _, key := range keys {
+		value := ext[key]
+		if strings.HasPrefix(key, "_") {
+			normalized[key] = value
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("ext.%s 不是已启用插件支持的参数，可通过 /api/plugins/ext 查看支持的参数", key)
+		}
+		converted, ok := convertExtValue(field.Type, value)
+		if !ok {
+			return nil, fmt.Errorf("ext.%s 类型错误: 应为%s（插件: %s），实际为 %#v", key, field.Type, strings.Join(owners[key], "、"), value)
+		}
+		normalized[key] = converted
+	}
+	return normalized, nil
+}
+
+// convertExtValue 校验并转换参数值
+func convertExtValue(t ExtType, value interface{}) (interface{}, bool) {
+	switch t {
+	case ExtTypeString:
+		s, ok := value.(string)
+		return s, ok
+	case ExtTypeBool:
+		b, ok := value.(bool)
+		return b, ok
+	case ExtTypeInt:
+		switch v := value.(type) {
+		case int:
+			return v, true
+		case int64:
+			return int(v), true
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+				return int(v), true
+			}
+		}
+		return nil, false
+	case ExtTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case int:
+			return float64(v), true
+		case int64:
+			return float64(v), true
+		}
+		return nil, false
+	default:
+		return value, true
+	}
+}
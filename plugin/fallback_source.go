@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/model"
+	"pansou/util"
+)
+
+// 剩余时间不足该值时不再尝试备用入口
+const minFallbackBudget = 500 * time.Millisecond
+
+// FallbackSource 插件的备用搜索入口（如站点的JSON API、RSS或HTML页面），
+// 主入口出错或没有结果时在剩余时间内依次尝试
+type FallbackSource struct {
+	Name   string
+	Search func(*http.Client, string, map[string]interface{}) ([]model.SearchResult, error)
+}
+
+// FallbackStats 插件备用入口的使用统计
+type FallbackStats struct {
+	Attempts  int64 `json:"attempts"`  // 尝试备用入口的次数
+	Recovered int64 `json:"recovered"` // 备用入口返回了结果的次数
+}
+
+var (
+	fallbackStats     = make(map[string]*FallbackStats)
+	fallbackStatsLock sync.Mutex
+)
+
+// AddFallbackSource 注册备用搜索入口，按注册顺序尝试
+func (p *BaseAsyncPlugin) AddFallbackSource(name string, searchFunc func(*http.Client, string, map[string]interface{}) ([]model.SearchResult, error)) {
+	if searchFunc == nil {
+		return
+	}
+	p.fallbacks = append(p.fallbacks, FallbackSource{Name: name, Search: searchFunc})
+}
+
+// searchWithFallbacks 执行主搜索函数，出错或没有结果时在客户端超时的剩余时间内尝试备用入口。
+// 备用入口也没有结果时返回主入口的结果和错误
+func (p *BaseAsyncPlugin) searchWithFallbacks(
+	searchFunc func(*http.Client, string, map[string]interface{}) ([]model.SearchResult, error),
+	client *http.Client,
+	keyword string,
+	ext map[string]interface{},
+) ([]model.SearchResult, error) {
+	start := time.Now()
+	results, err := searchFunc(client, keyword, ext)
+	if len(p.fallbacks) == 0 || (err == nil && len(results) > 0) {
+		return results, err
+	}
+
+	for _, fallback := range p.fallbacks {
+		fallbackClient := client
+		if client.Timeout > 0 {
+			remaining := client.Timeout - time.Since(start)
+			if remaining < minFallbackBudget {
+				break
+			}
+			c := *client
+			c.Timeout = remaining
+			fallbackClient = &c
+		}
+
+		stats := p.fallbackStats()
+		atomic.AddInt64(&stats.Attempts, 1)
+		fallbackResults, fallbackErr := fallback.Search(fallbackClient, keyword, ext)
+		if fallbackErr == nil && len(fallbackResults) > 0 {
+			atomic.AddInt64(&stats.Recovered, 1)
+			if util.PluginLogEnabled(p.name) {
+				fmt.Printf("%s[%s] 主入口无结果，备用入口 %s 返回 %d 条结果\n", requestLogTag(ext), p.name, fallback.Name, len(fallbackResults))
+			}
+			return fallbackResults, nil
+		}
+	}
+	return results, err
+}
+
+// fallbackStats 获取插件的备用入口统计
+func (p *BaseAsyncPlugin) fallbackStats() *FallbackStats {
+	fallbackStatsLock.Lock()
+	defer fallbackStatsLock.Unlock()
+	stats, ok := fallbackStats[p.name]
+	if !ok {
+		stats = &FallbackStats{}
+		fallbackStats[p.name] = stats
+	}
+	return stats
+}
+
+// GetFallbackStats 获取各插件备用入口的使用统计
+func GetFallbackStats() map[string]FallbackStats {
+	fallbackStatsLock.Lock()
+	defer fallbackStatsLock.Unlock()
+	result := make(map[string]FallbackStats, len(fallbackStats))
+	for name, stats := range fallbackStats {
+		result[name] = FallbackStats{
+			Attempts:  atomic.LoadInt64(&stats.Attempts),
+			Recovered: atomic.LoadInt64(&stats.Recovered),
+		}
+	}
+	return result
+}
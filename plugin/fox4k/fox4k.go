@@ -20,6 +20,7 @@ import (
 	"golang.org/x/net/proxy"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 // 常量定义
@@ -196,7 +197,7 @@ func createOptimizedHTTPClient() *http.Client {
 	}
 	
 	return &http.Client{
-		Transport: transport,
+		Transport: util.NewPluginTransport("fox4k", transport),
 		Timeout:   DefaultTimeout,
 	}
 }
@@ -606,58 +607,49 @@ func (p *Fox4kPlugin) enrichWithDetailInfo(results []model.SearchResult, client
 		return results
 	}
 	
-	// 使用信号量控制并发数
-	semaphore := make(chan struct{}, MaxConcurrency)
-	var wg sync.WaitGroup
 	var mutex sync.Mutex
 	
 	enrichedResults := make([]model.SearchResult, len(results))
 	copy(enrichedResults, results)
 	
-	for i := range enrichedResults {
-		wg.Add(1)
-		go func(index int) {
-			defer wg.Done()
-			
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-			
-			// 从UniqueID中提取ID
-			parts := strings.Split(enrichedResults[index].UniqueID, "-")
-			if len(parts) < 2 {
-				return
+	// 按结果顺序抓取详情页，受单次搜索的详情页抓取预算约束，排名靠前的结果优先
+	budget := plugin.NewSearchDetailBudget()
+	plugin.FetchDetails(budget, len(enrichedResults), MaxConcurrency, func(index int) {
+		// 从UniqueID中提取ID
+		parts := strings.Split(enrichedResults[index].UniqueID, "-")
+		if len(parts) < 2 {
+			return
+		}
+		id := parts[len(parts)-1]
+		
+		// 获取详情页信息
+		detailInfo := p.getDetailInfo(id, client)
+		if detailInfo != nil {
+			mutex.Lock()
+			enrichedResults[index].Links = detailInfo.Downloads
+			if detailInfo.Content != "" {
+				enrichedResults[index].Content = detailInfo.Content
 			}
-			id := parts[len(parts)-1]
-			
-			// 获取详情页信息
-			detailInfo := p.getDetailInfo(id, client)
-			if detailInfo != nil {
-				mutex.Lock()
-				enrichedResults[index].Links = detailInfo.Downloads
-				if detailInfo.Content != "" {
-					enrichedResults[index].Content = detailInfo.Content
-				}
-				// 补充标签
-				for _, tag := range detailInfo.Tags {
-					found := false
-					for _, existingTag := range enrichedResults[index].Tags {
-						if existingTag == tag {
-							found = true
-							break
-						}
-					}
-					if !found {
-						enrichedResults[index].Tags = append(enrichedResults[index].Tags, tag)
+			// 补充标签
+			for _, tag := range detailInfo.Tags {
+				found := false
+				for _, existingTag := range enrichedResults[index].Tags {
+					if existingTag == tag {
+						found = true
+						break
 					}
 				}
-				mutex.Unlock()
+				if !found {
+					enrichedResults[index].Tags = append(enrichedResults[index].Tags, tag)
+				}
 			}
-		}(i)
+			mutex.Unlock()
+		}
+	})
+	if skipped := budget.Skipped(); skipped > 0 {
+		debugPrintf("⏳ [Fox4k DEBUG] 详情页抓取预算用尽，跳过 %d 个结果\n", skipped)
 	}
 	
-	wg.Wait()
-	
 	// 过滤掉没有有效下载链接的结果
 	var validResults []model.SearchResult
 	for _, result := range enrichedResults {
@@ -811,7 +803,7 @@ func (p *Fox4kPlugin) extractDownloadLinks(doc *goquery.Document, detail *detail
 		for _, panLink := range matches {
 			// 提取密码（如果有）
 			password := p.extractPasswordFromText(pageText, panLink)
-			p.addDownloadLink(detail, panType, panLink, password)
+			p.addDownloadLink(detail, panType, panLink, password, "", "")
 		}
 	}
 	
@@ -832,13 +824,13 @@ func (p *Fox4kPlugin) extractDownloadLinks(doc *goquery.Document, detail *detail
 			
 			// 从 data-clipboard-text 属性提取链接
 			if clipboardText, exists := linkItem.Find(".down-copy").Attr("data-clipboard-text"); exists {
-				p.processFoundLink(detail, clipboardText, currentQuality)
+				p.processFoundLink(detail, clipboardText, currentQuality, itemText)
 			}
 			
 			// 从 href 属性提取链接
 			linkItem.Find("a").Each(func(l int, link *goquery.Selection) {
 				if href, exists := link.Attr("href"); exists {
-					p.processFoundLink(detail, href, currentQuality)
+					p.processFoundLink(detail, href, currentQuality, itemText)
 				}
 			})
 			
@@ -857,8 +849,8 @@ func (p *Fox4kPlugin) extractDownloadLinks(doc *goquery.Document, detail *detail
 	})
 }
 
-// processFoundLink 处理找到的链接
-func (p *Fox4kPlugin) processFoundLink(detail *detailPageResponse, link, quality string) {
+// processFoundLink 处理找到的链接，itemText为链接所在条目的文本，用于提取大小等元数据
+func (p *Fox4kPlugin) processFoundLink(detail *detailPageResponse, link, quality, itemText string) {
 	if link == "" {
 		return
 	}
@@ -874,7 +866,7 @@ func (p *Fox4kPlugin) processFoundLink(detail *detailPageResponse, link, quality
 	for panType, regex := range panLinkRegexes {
 		if regex.MatchString(link) {
 			password := p.extractPasswordFromLink(link)
-			p.addDownloadLink(detail, panType, link, password)
+			p.addDownloadLink(detail, panType, link, password, quality, itemText)
 			return
 		}
 	}
@@ -896,7 +888,7 @@ func (p *Fox4kPlugin) extractLinksFromText(detail *detailPageResponse, text, qua
 		matches := regex.FindAllString(text, -1)
 		for _, panLink := range matches {
 			password := p.extractPasswordFromText(text, panLink)
-			p.addDownloadLink(detail, panType, panLink, password)
+			p.addDownloadLink(detail, panType, panLink, password, quality, text)
 		}
 	}
 }
@@ -929,8 +921,9 @@ func (p *Fox4kPlugin) extractPasswordFromText(text, link string) string {
 	return ""
 }
 
-// addDownloadLink 添加下载链接
-func (p *Fox4kPlugin) addDownloadLink(detail *detailPageResponse, linkType, linkURL, password string) {
+// addDownloadLink 添加下载链接，quality为所在下载区域的清晰度版本，
+// itemText为链接所在条目的文本，从中提取大小、文件数和有效期
+func (p *Fox4kPlugin) addDownloadLink(detail *detailPageResponse, linkType, linkURL, password, quality, itemText string) {
 	if linkURL == "" {
 		return
 	}
@@ -940,82 +933,47 @@ func (p *Fox4kPlugin) addDownloadLink(detail *detailPageResponse, linkType, link
 		return
 	}
 	
-	// 检查是否已存在
-	for _, existingLink := range detail.Downloads {
+	meta := util.ExtractLinkMeta(itemText, time.Now())
+	
+	// 检查是否已存在（整页扫描先于下载区域，已存在时补充下载区域中的元数据）
+	for i := range detail.Downloads {
+		existingLink := &detail.Downloads[i]
 		if existingLink.URL == linkURL {
+			if existingLink.SourceNote == "" {
+				existingLink.SourceNote = quality
+			}
+			if existingLink.Size == 0 {
+				existingLink.Size = meta.Size
+			}
+			if existingLink.FileCount == 0 {
+				existingLink.FileCount = meta.FileCount
+			}
+			if existingLink.ExpiresAt == nil && !meta.ExpiresAt.IsZero() {
+				existingLink.ExpiresAt = &meta.ExpiresAt
+			}
 			return
 		}
 	}
 	
 	// 创建链接对象
 	link := model.Link{
-		Type:     linkType,
-		URL:      linkURL,
-		Password: password,
+		Type:       linkType,
+		URL:        linkURL,
+		Password:   password,
+		Size:       meta.Size,
+		FileCount:  meta.FileCount,
+		SourceNote: quality,
+	}
+	if !meta.ExpiresAt.IsZero() {
+		link.ExpiresAt = &meta.ExpiresAt
 	}
 	
 	detail.Downloads = append(detail.Downloads, link)
 }
 
-// doRequestWithRetry 带重试机制的HTTP请求
+// doRequestWithRetry 发送HTTP请求，只有200响应视为成功。网络错误和临时错误状态码由插件传输层重试，最多尝试3次
 func (p *Fox4kPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	debugPrintf("🔄 [Fox4k DEBUG] 开始重试机制 - 最大重试次数: %d\n", maxRetries)
-	
-	for i := 0; i < maxRetries; i++ {
-		debugPrintf("🔄 [Fox4k DEBUG] 第 %d/%d 次尝试\n", i+1, maxRetries)
-		
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			debugPrintf("⏳ [Fox4k DEBUG] 等待 %v 后重试\n", backoff)
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		attemptStart := time.Now()
-		resp, err := client.Do(reqClone)
-		attemptDuration := time.Since(attemptStart)
-		
-		debugPrintf("🔧 [Fox4k DEBUG] 第 %d 次尝试耗时: %v\n", i+1, attemptDuration)
-		
-		if err != nil {
-			debugPrintf("❌ [Fox4k DEBUG] 第 %d 次尝试失败: %v\n", i+1, err)
-			lastErr = err
-			continue
-		}
-		
-		debugPrintf("🔧 [Fox4k DEBUG] 第 %d 次尝试获得响应 - 状态码: %d\n", i+1, resp.StatusCode)
-		
-		if resp.StatusCode == 200 {
-			debugPrintf("✅ [Fox4k DEBUG] 第 %d 次尝试成功!\n", i+1)
-			return resp, nil
-		}
-		
-		debugPrintf("❌ [Fox4k DEBUG] 第 %d 次尝试状态码异常: %d\n", i+1, resp.StatusCode)
-		
-		// 读取响应体以便调试
-		if resp.Body != nil {
-			bodyBytes, readErr := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if readErr == nil && len(bodyBytes) > 0 {
-				bodyPreview := string(bodyBytes)
-				if len(bodyPreview) > 200 {
-					bodyPreview = bodyPreview[:200] + "..."
-				}
-				debugPrintf("🔧 [Fox4k DEBUG] 响应体预览: %s\n", bodyPreview)
-			}
-		}
-		
-		lastErr = fmt.Errorf("状态码 %d", resp.StatusCode)
-	}
-	
-	debugPrintf("❌ [Fox4k DEBUG] 所有重试都失败了!\n")
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
 
 // getRandomUA 获取随机User-Agent
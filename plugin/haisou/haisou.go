@@ -14,6 +14,7 @@ import (
 	"pansou/model"
 	"pansou/plugin"
 	"pansou/util/json"
+	"pansou/util"
 )
 
 const (
@@ -87,6 +88,7 @@ func init() {
 		BaseAsyncPlugin: plugin.NewBaseAsyncPlugin("haisou", 3), 
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterExtSchema("haisou", plugin.ExtField{Key: "pages_per_type", Type: plugin.ExtTypeInt, Default: DefaultPagesPerType, Description: "每种网盘类型搜索的页数，最大3"})
 }
 
 // Search 执行搜索并返回结果（兼容性方法）
@@ -447,31 +449,7 @@ func (p *HaisouPlugin) fetchShareLink(client *http.Client, hsid string, platform
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *HaisouPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
 
 // buildShareURL 根据平台类型和分享码构建完整的分享链接
@@ -15,6 +15,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 const (
@@ -69,6 +70,11 @@ func (p *HdmoliPlugin) Description() string {
 	return Description
 }
 
+// Homepage 返回插件站点地址
+func (p *HdmoliPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 搜索接口
 func (p *HdmoliPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	return p.searchImpl(&http.Client{Timeout: 30 * time.Second}, keyword, ext)
@@ -151,31 +157,11 @@ func (p *HdmoliPlugin) executeSearch(client *http.Client, keyword string) ([]mod
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *HdmoliPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
+	resp, err := util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %w", p.Name(), err)
 	}
-	
-	return nil, fmt.Errorf("[%s] 重试 %d 次后仍然失败: %w", p.Name(), maxRetries, lastErr)
+	return resp, nil
 }
 
 // parseSearchResults 解析搜索结果HTML
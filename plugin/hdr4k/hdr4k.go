@@ -3,7 +3,6 @@ package hdr4k
 import (
 	"fmt"
 	"math/rand"
-	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -14,6 +13,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 // 缓存相关变量
@@ -53,6 +53,7 @@ type cachedResponse struct {
 func init() {
 	// 注册插件
 	plugin.RegisterGlobalPlugin(NewHdr4kAsyncPlugin())
+	plugin.RegisterExtSchema("hdr4k", plugin.ExtField{Key: "title_en", Type: plugin.ExtTypeString, Description: "英文标题，与中文关键词一起搜索"})
 	
 	// 启动缓存清理
 	go startCacheCleaner()
@@ -570,53 +571,10 @@ func (p *Hdr4kAsyncPlugin) determineLinkType(url, name string) string {
 	return linkType
 }
 
-// doRequestWithRetry 发送HTTP请求并支持重试
+// doRequestWithRetry 发送HTTP请求，网络错误和临时错误状态码由插件传输层重试，最多重试maxRetries次
 func (p *Hdr4kAsyncPlugin) doRequestWithRetry(client *http.Client, req *http.Request, maxRetries int) (*http.Response, error) {
-	var resp *http.Response
-	var err error
-	
-	for i := 0; i <= maxRetries; i++ {
-		// 如果不是第一次尝试，等待一段时间
-		if i > 0 {
-			// 指数退避算法
-			backoff := time.Duration(1<<uint(i-1)) * 500 * time.Millisecond
-			if backoff > 5*time.Second {
-				backoff = 5 * time.Second
-			}
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求，避免重用同一个请求对象
-		reqClone := req.Clone(req.Context())
-		
-		// 发送请求
-		resp, err = client.Do(reqClone)
-		
-		// 如果请求成功或者是不可重试的错误，则退出循环
-		if err == nil || !p.isRetriableError(err) {
-			break
-		}
-	}
-	
-	return resp, err
-}
-
-// isRetriableError 判断错误是否可以重试
-func (p *Hdr4kAsyncPlugin) isRetriableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	
-	// 判断是否是网络错误或超时错误
-	if netErr, ok := err.(net.Error); ok {
-		return netErr.Timeout() || netErr.Temporary()
-	}
-	
-	// 其他可能需要重试的错误类型
-	errStr := err.Error()
-	return strings.Contains(errStr, "connection refused") ||
-		   strings.Contains(errStr, "connection reset") ||
-		   strings.Contains(errStr, "EOF")
+	policy := util.RetryPolicy{MaxAttempts: maxRetries + 1, BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second, RetryNonIdempotent: true}
+	return client.Do(req.WithContext(util.WithRetryPolicy(req.Context(), policy)))
 }
 
 // parseDateTime 解析日期时间字符串
@@ -13,6 +13,7 @@ import (
 
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 	"pansou/util/json"
 )
 
@@ -52,6 +53,7 @@ var (
 
 func init() {
 	plugin.RegisterGlobalPlugin(NewHubanPlugin())
+	plugin.RegisterExtSchema("huban", plugin.ExtField{Key: "referer", Type: plugin.ExtTypeString, Description: "请求来源，启用来源检查时必须匹配允许列表"})
 }
 
 // 预编译的正则表达式
@@ -95,7 +97,7 @@ func createOptimizedHTTPClient() *http.Client {
 	}
 
 	return &http.Client{
-		Transport: transport,
+		Transport: util.NewPluginTransport("huban", transport),
 		Timeout:   DefaultTimeout,
 	}
 }
@@ -511,28 +513,11 @@ func (p *HubanAsyncPlugin) deduplicateLinks(links []model.Link) []model.Link {
 
 // doRequestWithRetry 带重试的HTTP请求（优化JSON API的重试策略）
 func (p *HubanAsyncPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 2  // 对于JSON API减少重试次数
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		resp, err := client.Do(req)
-		if err == nil {
-			if resp.StatusCode == http.StatusOK {
-				return resp, nil
-			}
-			resp.Body.Close()
-			lastErr = fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
-		} else {
-			lastErr = err
-		}
-		
-		// JSON API快速重试：只等待很短时间
-		if i < maxRetries-1 {
-			time.Sleep(100 * time.Millisecond) // 从秒级改为100毫秒
-		}
+	resp, err := util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %w", p.Name(), err)
 	}
-	
-	return nil, fmt.Errorf("[%s] 请求失败，重试%d次后仍失败: %w", p.Name(), maxRetries, lastErr)
+	return resp, nil
 }
 
 // GetPerformanceStats 获取性能统计信息
@@ -18,6 +18,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 const (
@@ -82,6 +83,21 @@ func (p *JavdbPlugin) Description() string {
 	return Description
 }
 
+// Homepage 返回插件站点地址
+func (p *JavdbPlugin) Homepage() string {
+	return BaseURL
+}
+
+// SupportedCloudTypes 返回插件支持的链接类型
+func (p *JavdbPlugin) SupportedCloudTypes() []string {
+	return []string{"magnet"}
+}
+
+// SupportedCategories 返回插件擅长的内容分类
+func (p *JavdbPlugin) SupportedCategories() []string {
+	return []string{"adult"}
+}
+
 // SkipServiceFilter 磁力搜索插件，跳过Service层过滤
 func (p *JavdbPlugin) SkipServiceFilter() bool {
 	return true // 磁力搜索，跳过网盘服务过滤
@@ -244,31 +260,11 @@ func (p *JavdbPlugin) executeSearchWithRateLimit(client *http.Client, keyword st
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *JavdbPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
+	resp, err := util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %w", p.Name(), err)
 	}
-	
-	return nil, fmt.Errorf("[%s] 重试 %d 次后仍然失败: %w", p.Name(), maxRetries, lastErr)
+	return resp, nil
 }
 
 // doRequestWithRateLimitRetry 带429重试机制的HTTP请求
@@ -605,34 +601,23 @@ func (p *JavdbPlugin) fetchDetailMagnetLinks(client *http.Client, searchResults
 		log.Printf("[JAVDB] 开始获取 %d 个搜索结果的详情页磁力链接", len(searchResults))
 	}
 
-	// 使用通道控制并发数
-	semaphore := make(chan struct{}, MaxConcurrency)
-	var wg sync.WaitGroup
 	resultsChan := make(chan []model.SearchResult, len(searchResults))
 	
 	// 根据客户端超时调整策略
 	var finalResults []model.SearchResult
 	useTimeout := client.Timeout <= 5*time.Second // 短超时客户端使用超时机制
 
-	for i, result := range searchResults {
-		// 检查是否已经被限流，如果是则停止启动新的goroutine
-		if atomic.LoadInt32(&p.rateLimited) == 1 {
-			if p.debugMode {
-				log.Printf("[JAVDB] 检测到限流状态，停止启动新的详情页请求")
-			}
-			break
-		}
-
-		wg.Add(1)
-		go func(r model.SearchResult, index int) {
-			defer wg.Done()
-			semaphore <- struct{}{} // 获取信号量
-			defer func() { <-semaphore }() // 释放信号量
+	// 按结果顺序抓取详情页，受单次搜索的详情页抓取预算约束，排名靠前的结果优先
+	budget := plugin.NewSearchDetailBudget()
+	done := make(chan struct{})
+	go func() {
+		plugin.FetchDetails(budget, len(searchResults), MaxConcurrency, func(index int) {
+			r := searchResults[index]
 
-			// 在goroutine内部再次检查限流状态
+			// 检查是否已经被限流，如果是则跳过剩余的详情页请求
 			if atomic.LoadInt32(&p.rateLimited) == 1 {
 				if p.debugMode {
-					log.Printf("[JAVDB] goroutine内检测到限流状态，跳过详情页请求: %s", r.Title)
+					log.Printf("[JAVDB] 检测到限流状态，跳过详情页请求: %s", r.Title)
 				}
 				return
 			}
@@ -684,13 +669,10 @@ func (p *JavdbPlugin) fetchDetailMagnetLinks(client *http.Client, searchResults
 			} else if p.debugMode {
 				log.Printf("[JAVDB] 详情页无磁力链接: %s", r.Title)
 			}
-		}(result, i)
-	}
-
-	// 等待所有goroutine完成的信号
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
+		})
+		if p.debugMode && budget.Skipped() > 0 {
+			log.Printf("[JAVDB] 详情页抓取预算用尽，跳过 %d 个结果", budget.Skipped())
+		}
 		close(resultsChan)
 		close(done)
 	}()
@@ -16,6 +16,7 @@ import (
 func init() {
 	// 注册插件
 	plugin.RegisterGlobalPlugin(NewJikepanAsyncV2Plugin())
+	plugin.RegisterExtSchema("jikepan", plugin.ExtField{Key: "is_all", Type: plugin.ExtTypeBool, Default: false, Description: "是否全量搜索"})
 }
 
 const (
@@ -62,6 +62,11 @@ func (p *LeijingPlugin) Description() string {
 	return "雷鲸小站 - 天翼云盘资源分享站"
 }
 
+// Homepage 返回插件站点地址
+func (p *LeijingPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *LeijingPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
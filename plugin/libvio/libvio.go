@@ -64,6 +64,11 @@ func (p *LibvioPlugin) Description() string {
 	return "LIBVIO - 影视资源网盘下载"
 }
 
+// Homepage 返回插件站点地址
+func (p *LibvioPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *LibvioPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"sort"
+	"sync"
+
+	"pansou/model"
+)
+
+// PluginMetadata 插件的展示信息，供前端渲染来源选择
+type PluginMetadata struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	Priority    int    `json:"priority"`
+	// CloudTypes 支持的网盘类型（与结果中的链接类型一致，如quark、baidu、magnet）
+	CloudTypes []string `json:"cloud_types"`
+	// CloudTypesObserved 插件未声明支持的类型，CloudTypes为实际返回过的链接类型
+	CloudTypesObserved bool `json:"cloud_types_observed,omitempty"`
+	// Categories 擅长的内容分类（video、anime、music、software、adult、ebook），为空表示不限分类
+	Categories []string `json:"categories,omitempty"`
+	// Language 站点标题的语言（zh、en），为空表示中文站点
+	Language string `json:"language,omitempty"`
+}
+
+// 插件可选实现的元数据方法，未实现时使用注册的元数据或默认值
+type (
+	displayNamer       interface{ DisplayName() string }
+	describer          interface{ Description() string }
+	homepageProvider   interface{ Homepage() string }
+	cloudTypesProvider interface{ SupportedCloudTypes() []string }
+	categoriesProvider interface{ SupportedCategories() []string }
+	languageProvider   interface{ IndexLanguage() string }
+)
+
+var (
+	// 插件注册的元数据
+	pluginMetadata     = make(map[string]PluginMetadata)
+	pluginMetadataLock sync.RWMutex
+
+	// 插件实际返回过的链接类型
+	observedCloudTypes     = make(map[string]map[string]bool)
+	observedCloudTypesLock sync.RWMutex
+)
+
+// RegisterPluginMetadata 注册插件元数据，在插件init中与RegisterGlobalPlugin一起调用。
+// 只需填写已知的字段，Name必须与插件名称一致
+func RegisterPluginMetadata(meta PluginMetadata) {
+	if meta.Name == "" {
+		return
+	}
+	pluginMetadataLock.Lock()
+	defer pluginMetadataLock.Unlock()
+	pluginMetadata[meta.Name] = meta
+}
+
+// GetPluginMetadata 获取插件的元数据：注册的元数据优先，其次是插件自身的DisplayName、Description、
+// Homepage、SupportedCloudTypes、SupportedCategories、IndexLanguage方法；显示名称默认为插件名，未声明网盘类型时使用实际返回过的链接类型
+func GetPluginMetadata(p AsyncSearchPlugin) PluginMetadata {
+	pluginMetadataLock.RLock()
+	meta := pluginMetadata[p.Name()]
+	pluginMetadataLock.RUnlock()
+
+	meta.Name = p.Name()
+	meta.Priority = p.Priority()
+	if v, ok := p.(displayNamer); ok && meta.DisplayName == "" {
+		meta.DisplayName = v.DisplayName()
+	}
+	if v, ok := p.(describer); ok && meta.Description == "" {
+		meta.Description = v.Description()
+	}
+	if v, ok := p.(homepageProvider); ok && meta.Homepage == "" {
+		meta.Homepage = v.Homepage()
+	}
+	if v, ok := p.(cloudTypesProvider); ok && len(meta.CloudTypes) == 0 {
+		meta.CloudTypes = v.SupportedCloudTypes()
+	}
+	if v, ok := p.(categoriesProvider); ok && len(meta.Categories) == 0 {
+		meta.Categories = v.SupportedCategories()
+	}
+	if v, ok := p.(languageProvider); ok && meta.Language == "" {
+		meta.Language = v.IndexLanguage()
+	}
+	meta.Categories = append([]string(nil), meta.Categories...)
+
+	if meta.DisplayName == "" {
+		meta.DisplayName = meta.Name
+	}
+	if len(meta.CloudTypes) == 0 {
+		meta.CloudTypes = getObservedCloudTypes(meta.Name)
+		meta.CloudTypesObserved = true
+	} else {
+		meta.CloudTypes = append([]string(nil), meta.CloudTypes...)
+	}
+	return meta
+}
+
+// Catalog 获取已启用插件的元数据，按优先级和名称排序
+func (pm *PluginManager) Catalog() []PluginMetadata {
+	plugins := pm.GetPlugins()
+	catalog := make([]PluginMetadata, 0, len(plugins))
+	for _, p := range plugins {
+		catalog = append(catalog, GetPluginMetadata(p))
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Priority != catalog[j].Priority {
+			return catalog[i].Priority < catalog[j].Priority
+		}
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog
+}
+
+// recordObservedCloudTypes 记录插件返回的链接类型，用于未声明网盘类型的插件
+func recordObservedCloudTypes(name string, results []model.SearchResult) {
+	var types []string
+	observedCloudTypesLock.RLock()
+	known := observedCloudTypes[name]
+	for _, result := range results {
+		for _, link := range result.Links {
+			if link.Type != "" && !known[link.Type] {
+				types = append(types, link.Type)
+			}
+		}
+	}
+	observedCloudTypesLock.RUnlock()
+	if len(types) == 0 {
+		return
+	}
+
+	observedCloudTypesLock.Lock()
+	defer observedCloudTypesLock.Unlock()
+	if observedCloudTypes[name] == nil {
+		observedCloudTypes[name] = make(map[string]bool)
+	}
+	for _, t := range types {
+		observedCloudTypes[name][t] = true
+	}
+}
+
+// getObservedCloudTypes 获取插件返回过的链接类型
+func getObservedCloudTypes(name string) []string {
+	observedCloudTypesLock.RLock()
+	defer observedCloudTypesLock.RUnlock()
+	types := make([]string, 0, len(observedCloudTypes[name]))
+	for t := range observedCloudTypes[name] {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
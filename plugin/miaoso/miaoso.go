@@ -16,11 +16,13 @@ import (
 	"pansou/model"
 	"pansou/plugin"
 	"pansou/util/json"
+	"pansou/util"
 )
 
 // 在init函数中注册插件
 func init() {
 	plugin.RegisterGlobalPlugin(NewMiaosouPlugin())
+	plugin.RegisterExtSchema("miaoso", plugin.ExtField{Key: "title_en", Type: plugin.ExtTypeString, Description: "英文标题，与中文关键词一起搜索"})
 }
 
 const (
@@ -165,30 +167,7 @@ func (p *MiaosouPlugin) setRequestHeaders(req *http.Request, keyword string) {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *MiaosouPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	var lastErr error
-	
-	for i := 0; i < MaxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", MaxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: MaxRetries, BaseDelay: 200 * time.Millisecond})
 }
 
 // convertToSearchResult 将API响应项转换为SearchResult
@@ -13,6 +13,7 @@ import (
 
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 	"pansou/util/json"
 	"sync/atomic"
 )
@@ -255,6 +256,9 @@ func NewPanSearchPlugin() *PanSearchAsyncPlugin {
 	// 启动后台 buildId 更新器
 	go p.startBuildIdUpdater()
 
+	// 数据接口出错或无结果时改用搜索页面（不依赖buildId）
+	p.AddFallbackSource("html", p.searchPage)
+
 	return p
 }
 
@@ -276,7 +280,7 @@ func (p *PanSearchAsyncPlugin) updateBuildId() {
 	defer cancel()
 
 	// 发送请求获取页面
-	req, err := http.NewRequestWithContext(ctx, "GET", WebsiteURL, nil)
+	req, err := http.NewRequestWithContext(util.WithResponseCache(ctx), "GET", WebsiteURL, nil)
 	if err != nil {
 		// fmt.Printf("创建请求失败: %v\n", err)
 		return
@@ -388,7 +392,7 @@ func (p *PanSearchAsyncPlugin) getBuildId() (string, error) {
 	defer cancel()
 
 	// 发送请求获取页面
-	req, err := http.NewRequestWithContext(ctx, "GET", WebsiteURL, nil)
+	req, err := http.NewRequestWithContext(util.WithResponseCache(ctx), "GET", WebsiteURL, nil)
 	if err != nil {
 		// 如果创建请求失败但有旧的缓存，使用旧的缓存（优雅降级）
 		if buildIdCache != "" {
@@ -488,6 +492,18 @@ func (p *PanSearchAsyncPlugin) getBaseURL(client *http.Client) (string, error) {
 	return fmt.Sprintf(BaseURLTemplate, buildId), nil
 }
 
+// ResetState 清除buildId和搜索结果缓存，下次搜索时重新获取buildId
+func (p *PanSearchAsyncPlugin) ResetState() {
+	p.BaseAsyncPlugin.ResetState()
+	
+	buildIdMutex.Lock()
+	buildIdCache = ""
+	buildIdCacheTime = time.Time{}
+	buildIdMutex.Unlock()
+	
+	searchResultCache.Clear()
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *PanSearchAsyncPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -522,6 +538,7 @@ func (p *PanSearchAsyncPlugin) doSearch(client *http.Client, keyword string, ext
 			buildIdCache = ""              // 清空缓存
 			buildIdCacheTime = time.Time{} // 重置缓存时间
 			buildIdMutex.Unlock()
+			util.ClearResponseCache(p.Name()) // 缓存的首页中是旧的buildId
 
 			// 重新获取buildId
 			baseURL, err = p.getBaseURL(client)
@@ -615,6 +632,7 @@ func (p *PanSearchAsyncPlugin) doSearch(client *http.Client, keyword string, ext
 						buildIdCache = ""              // 清空缓存
 						buildIdCacheTime = time.Time{} // 重置缓存时间
 						buildIdMutex.Unlock()
+						util.ClearResponseCache(p.Name()) // 缓存的首页中是旧的buildId
 
 						// 重新获取buildId
 						newBuildId, err := p.getBuildId()
@@ -847,6 +865,45 @@ func (p *PanSearchAsyncPlugin) fetchFirstPage(keyword string, baseURL string, cl
 	return items, total, nil
 }
 
+// searchPage 备用入口：请求搜索页面，从__NEXT_DATA__中读取首页结果
+func (p *PanSearchAsyncPlugin) searchPage(client *http.Client, keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
+	reqURL := fmt.Sprintf("%s?keyword=%s", WebsiteURL, url.QueryEscape(keyword))
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("服务器返回非200状态码: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	matches := nextDataRegex.FindSubmatch(body)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("页面中没有__NEXT_DATA__")
+	}
+	var nextData struct {
+		Props PanSearchResponse `json:"props"`
+	}
+	if err := json.Unmarshal(matches[1], &nextData); err != nil {
+		return nil, fmt.Errorf("解析__NEXT_DATA__失败: %w", err)
+	}
+
+	items := p.deduplicateItems(nextData.Props.PageProps.Data.Data)
+	return p.convertResults(items, keyword), nil
+}
+
 // fetchPage 获取指定偏移量的页面
 func (p *PanSearchAsyncPlugin) fetchPage(keyword string, offset int, baseURL string) ([]PanSearchItem, error) {
 	// 构建请求URL
@@ -935,11 +992,17 @@ func (p *PanSearchAsyncPlugin) convertResults(items []PanSearchItem, keyword str
 			linkType = "aliyun"
 		}
 
-		// 创建链接
+		// 创建链接，资源描述中带有的大小、文件数和有效期一并保留
+		meta := util.ExtractLinkMeta(item.Content, time.Now())
 		link := model.Link{
-			URL:      linkInfo.URL,
-			Type:     linkType,
-			Password: linkInfo.Password,
+			URL:       linkInfo.URL,
+			Type:      linkType,
+			Password:  linkInfo.Password,
+			Size:      meta.Size,
+			FileCount: meta.FileCount,
+		}
+		if !meta.ExpiresAt.IsZero() {
+			link.ExpiresAt = &meta.ExpiresAt
 		}
 
 		// 创建唯一ID
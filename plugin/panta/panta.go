@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 	"regexp"
 	"strings"
 	"sync"
@@ -216,6 +217,18 @@ func (p *PantaAsyncPlugin) Priority() int {
 	return defaultPriority
 }
 
+// ResetState 清除链接识别、帖子ID、发帖时间和帖子详情等缓存
+func (p *PantaAsyncPlugin) ResetState() {
+	p.BaseAsyncPlugin.ResetState()
+	
+	for _, cache := range []*sync.Map{
+		&isNetDiskLinkCache, &determineLinkTypeCache, &extractPasswordCache,
+		&topicIDCache, &postTimeCache, &yearCache, &linkExtractCache, &threadLinksCache,
+	} {
+		cache.Clear()
+	}
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *PantaAsyncPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -399,19 +412,9 @@ func (p *PantaAsyncPlugin) parseSearchResults(doc *goquery.Document, client *htt
 			
 			// 如果没有找到链接，尝试获取帖子详情
 			if len(foundLinks) == 0 {
-				// 添加重试机制
-				for retry := 0; retry <= maxRetries; retry++ {
-					if retry > 0 {
-						// 重试前等待一段时间，使用指数退避
-						backoffTime := time.Duration(min(backoffBase*1<<uint(retry-1), maxBackoff)) * time.Millisecond
-						time.Sleep(backoffTime)
-					}
-					
-					threadLinks, err := p.fetchThreadLinks(topicID, client)
-					if err == nil && len(threadLinks) > 0 {
-						foundLinks = threadLinks
-						break
-					}
+				// 请求失败时的重试由doRequestWithRetry完成
+				if threadLinks, err := p.fetchThreadLinks(topicID, client); err == nil {
+					foundLinks = threadLinks
 				}
 			}
 			
@@ -1249,46 +1252,17 @@ func (p *PantaAsyncPlugin) recordResponseTime(d time.Duration) {
 	p.responseTimes = append(p.responseTimes, d)
 }
 
-// doRequestWithRetry 发送HTTP请求，带重试机制
+// doRequestWithRetry 发送HTTP请求，网络错误和临时错误状态码由插件传输层按指数退避重试，最多重试maxRetries次
 func (p *PantaAsyncPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	var resp *http.Response
-	var err error
-	var startTime time.Time
-	
-	// 重试循环
-	for retry := 0; retry <= maxRetries; retry++ {
-		// 如果不是第一次尝试，则等待一段时间
-		if retry > 0 {
-			// 使用指数退避算法计算等待时间
-			backoffTime := time.Duration(min(backoffBase*1<<uint(retry-1), maxBackoff)) * time.Millisecond
-			time.Sleep(backoffTime)
-			
-			// 创建新的请求，因为原请求可能已经被关闭
-			newReq := req.Clone(req.Context())
-			req = newReq
-		}
-		
-		// 记录开始时间
-		startTime = time.Now()
-		
-		// 发送请求
-		resp, err = client.Do(req)
-		
-		// 记录响应时间
-		responseTime := time.Since(startTime)
-		p.recordResponseTime(responseTime)
-		
-		// 如果请求成功，或者是不可重试的错误，则退出重试循环
-		if err == nil && resp.StatusCode < 500 {
-			break
-		}
-		
-		// 如果请求失败，但响应不为nil，则关闭响应体
-		if resp != nil {
-			resp.Body.Close()
-		}
+	policy := util.RetryPolicy{
+		MaxAttempts:        maxRetries + 1,
+		BaseDelay:          backoffBase * time.Millisecond,
+		MaxDelay:           maxBackoff * time.Millisecond,
+		RetryNonIdempotent: true,
 	}
-	
+	startTime := time.Now()
+	resp, err := client.Do(req.WithContext(util.WithRetryPolicy(req.Context(), policy)))
+	p.recordResponseTime(time.Since(startTime))
 	return resp, err
 }
 
@@ -132,7 +132,7 @@ func (p *PanwikiPlugin) searchImpl(client *http.Client, keyword string, ext map[
 		log.Printf("[Panwiki] 获取详情页链接后，结果数: %d", len(allResults))
 		for i, result := range allResults {
 			log.Printf("[Panwiki] 返回前检查 - 结果#%d: 标题=%s, 链接数=%d", i+1, result.Title, len(result.Links))
-			log.Printf("[Panwiki] 返回前检查 - 结果#%d: 链接=%s", i+1, result.Links)
+			log.Printf("[Panwiki] 返回前检查 - 结果#%d: 链接=%v", i+1, result.Links)
 		}
 	}
 
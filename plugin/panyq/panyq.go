@@ -1,11 +1,11 @@
 package panyq
 
 import (
+	"context"
 	"crypto/tls"
 	"pansou/util/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,10 +14,10 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"net/http/cookiejar"
 
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 // 常量定义
@@ -98,7 +98,6 @@ type PanyqPlugin struct {
 // NewPanyqPlugin 创建新的盘友圈搜索插件
 func NewPanyqPlugin() *PanyqPlugin {
 	// 创建一个可以忽略HTTPS证书验证并支持Cookie的HTTP客户端
-	jar, _ := cookiejar.New(nil)
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		// 启用HTTP/2
@@ -111,8 +110,8 @@ func NewPanyqPlugin() *PanyqPlugin {
 	
 	client := &http.Client{
 		Timeout:   DefaultTimeout,
-		Transport: transport,
-		Jar:       jar, // 使用Cookie管理
+		Transport: util.NewPluginTransport("panyq", transport),
+		Jar:       util.PluginCookieJar("panyq"), // 使用持久化Cookie管理
 		// 自动处理重定向
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 10 {
@@ -128,14 +127,38 @@ func NewPanyqPlugin() *PanyqPlugin {
 	}
 }
 
+// ResetState 清除Action ID、最终链接和搜索结果缓存，并删除Action ID缓存文件，下次搜索时重新发现Action ID
+func (p *PanyqPlugin) ResetState() {
+	p.BaseAsyncPlugin.ResetState()
+	
+	actionIDCacheLock.Lock()
+	actionIDCache = make(map[string]string)
+	actionIDCacheLock.Unlock()
+	
+	finalLinkCacheLock.Lock()
+	finalLinkCache = make(map[string]string)
+	finalLinkCacheLock.Unlock()
+	
+	searchResultCacheLock.Lock()
+	searchResultCache = make(map[string][]model.SearchResult)
+	searchResultCacheLock.Unlock()
+	
+	if err := os.Remove(filepath.Join(".", ConfigFileName)); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("panyq: 删除Action ID缓存文件失败: %v\n", err)
+	}
+}
+
 // Search 执行搜索并返回结果
 func (p *PanyqPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	if DebugLog {
 		fmt.Println("panyq: ext 参数内容:", ext)
 	}
 
-	// 检查搜索结果缓存
+	// 检查搜索结果缓存，不同referer的结果分别缓存，避免跳过来源检查
 	cacheKey := fmt.Sprintf("search:%s", keyword)
+	if extHash := plugin.ExtCacheHash(ext, p.Name()); extHash != "" {
+		cacheKey += ":" + extHash
+	}
 	searchResultCacheLock.RLock()
 	if cachedResults, ok := searchResultCache[cacheKey]; ok {
 		searchResultCacheLock.RUnlock()
@@ -645,8 +668,8 @@ func (p *PanyqPlugin) discoverActionIDs() (map[string]string, error) {
 
 // findPotentialActionIDs 从网站获取潜在的Action ID
 func (p *PanyqPlugin) findPotentialActionIDs(client *http.Client) ([]string, error) {
-	// 请求网站首页
-	req, err := http.NewRequest("GET", BaseURL, nil)
+	// 请求网站首页，首页和JS文件在DISCOVERY_CACHE_TTL内复用缓存的响应
+	req, err := http.NewRequestWithContext(util.WithResponseCache(context.Background()), "GET", BaseURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -695,7 +718,7 @@ func (p *PanyqPlugin) findPotentialActionIDs(client *http.Client) ([]string, err
 		jsURL := BaseURL + match[1]
 		
 		// 创建JS文件请求
-		jsReq, err := http.NewRequest("GET", jsURL, nil)
+		jsReq, err := http.NewRequestWithContext(util.WithResponseCache(context.Background()), "GET", jsURL, nil)
 		if err != nil {
 			continue
 		}
@@ -776,57 +799,10 @@ func (p *PanyqPlugin) validateFinalLinkID(actionID, testEID string) bool {
 	return false
 }
 
-// doRequestWithRetry 发送HTTP请求并支持重试
+// doRequestWithRetry 发送HTTP请求，网络错误和临时错误状态码由插件传输层重试，最多重试maxRetries次
 func (p *PanyqPlugin) doRequestWithRetry(client *http.Client, req *http.Request, maxRetries int) (*http.Response, error) {
-	var resp *http.Response
-	var err error
-	
-	for i := 0; i <= maxRetries; i++ {
-		// 如果不是第一次尝试，等待一段时间
-		if i > 0 {
-			// 指数退避算法
-			backoff := time.Duration(1<<uint(i-1)) * 500 * time.Millisecond
-			if backoff > 5*time.Second {
-				backoff = 5 * time.Second
-			}
-			time.Sleep(backoff)
-			
-			if DebugLog {
-				fmt.Printf("panyq: 重试请求 #%d，等待 %v\n", i, backoff)
-			}
-		}
-		
-		// 克隆请求，避免重用同一个请求对象
-		reqClone := req.Clone(req.Context())
-		
-		// 发送请求
-		resp, err = client.Do(reqClone)
-		
-		// 如果请求成功或者是不可重试的错误，则退出循环
-		if err == nil || !isRetriableError(err) {
-			break
-		}
-	}
-	
-	return resp, err
-}
-
-// isRetriableError 判断错误是否可以重试
-func isRetriableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	
-	// 判断是否是网络错误或超时错误
-	if netErr, ok := err.(net.Error); ok {
-		return netErr.Timeout() || netErr.Temporary()
-	}
-	
-	// 其他可能需要重试的错误类型
-	errStr := err.Error()
-	return strings.Contains(errStr, "connection refused") ||
-		   strings.Contains(errStr, "connection reset") ||
-		   strings.Contains(errStr, "EOF")
+	policy := util.RetryPolicy{MaxAttempts: maxRetries + 1, BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second, RetryNonIdempotent: true}
+	return client.Do(req.WithContext(util.WithRetryPolicy(req.Context(), policy)))
 }
 
 // getRawFinalLinkResponse 获取最终链接的原始响应文本
@@ -1330,6 +1306,7 @@ func (p *PanyqPlugin) extractTitle(desc string) string {
 // 在init函数中注册插件
 func init() {
 	plugin.RegisterGlobalPlugin(NewPanyqPlugin())
+	plugin.RegisterExtSchema("panyq", plugin.ExtField{Key: "referer", Type: plugin.ExtTypeString, Description: "请求来源，启用来源检查时必须匹配允许列表"})
 	
 	// 启动缓存清理
 	go startCacheCleaner()
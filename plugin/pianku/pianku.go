@@ -11,6 +11,7 @@ import (
 
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -18,6 +19,7 @@ import (
 // 在init函数中注册插件
 func init() {
 	plugin.RegisterGlobalPlugin(NewPiankuPlugin())
+	plugin.RegisterExtSchema("pianku", plugin.ExtField{Key: "title_en", Type: plugin.ExtTypeString, Description: "英文标题，与中文关键词一起搜索"})
 }
 
 const (
@@ -195,30 +197,7 @@ func (p *PiankuPlugin) setRequestHeaders(req *http.Request) {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *PiankuPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	var lastErr error
-	
-	for i := 0; i < MaxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", MaxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: MaxRetries, BaseDelay: 200 * time.Millisecond})
 }
 
 // extractSearchResults 提取搜索结果
@@ -1,7 +1,10 @@
 package plugin
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 
@@ -14,6 +17,28 @@ var (
 	globalRegistryLock sync.RWMutex
 )
 
+// ExtKeyRequestID ext参数中保留的请求ID键，由服务层写入，用于插件日志关联
+const ExtKeyRequestID = "_request_id"
+
+// RequestIDFromExt 从ext参数中获取请求ID，不存在时返回空字符串
+func RequestIDFromExt(ext map[string]interface{}) string {
+	if ext == nil {
+		return ""
+	}
+	if id, ok := ext[ExtKeyRequestID].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// requestLogTag 根据ext中的请求ID生成日志前缀
+func requestLogTag(ext map[string]interface{}) string {
+	if id := RequestIDFromExt(ext); id != "" {
+		return "[req:" + id + "] "
+	}
+	return ""
+}
+
 // AsyncSearchPlugin 异步搜索插件接口
 type AsyncSearchPlugin interface {
 	// Name 返回插件名称
@@ -37,6 +62,10 @@ type AsyncSearchPlugin interface {
 	// SkipServiceFilter 返回是否跳过Service层的关键词过滤
 	// 对于磁力搜索等需要宽泛结果的插件，应返回true
 	SkipServiceFilter() bool
+	
+	// ResetState 清除插件的内部缓存（API响应缓存，以及插件自行缓存的接口参数、详情页等），
+	// 下次搜索时重新获取。用于插件内部状态过期导致持续无结果时，无需重启服务
+	ResetState()
 }
 
 // RegisterGlobalPlugin 注册异步插件到全局注册表
@@ -45,15 +74,22 @@ func RegisterGlobalPlugin(plugin AsyncSearchPlugin) {
 		return
 	}
 	
-	globalRegistryLock.Lock()
-	defer globalRegistryLock.Unlock()
-	
 	name := plugin.Name()
 	if name == "" {
 		return
 	}
 	
+	globalRegistryLock.Lock()
+	previous, exists := globalRegistry[name]
 	globalRegistry[name] = plugin
+	globalRegistryLock.Unlock()
+	
+	// 通知观察者（在锁外执行，观察者可以查询注册表）
+	if exists {
+		notifyRegistryObservers(RegistryEvent{Type: PluginReplaced, Name: name, Plugin: plugin, Previous: previous})
+	} else {
+		notifyRegistryObservers(RegistryEvent{Type: PluginRegistered, Name: name, Plugin: plugin})
+	}
 }
 
 // GetRegisteredPlugins 获取所有已注册的异步插件
@@ -69,6 +105,28 @@ func GetRegisteredPlugins() []AsyncSearchPlugin {
 	return plugins
 }
 
+// PresetPluginNames 获取插件预设包含的插件名称：default为等级1和等级2的插件，all为所有已注册的插件，
+// 未知的预设返回nil
+func PresetPluginNames(preset string) []string {
+	names := make([]string, 0)
+	switch strings.ToLower(strings.TrimSpace(preset)) {
+	case "default":
+		for _, p := range GetRegisteredPlugins() {
+			if p.Priority() <= 2 {
+				names = append(names, p.Name())
+			}
+		}
+	case "all":
+		for _, p := range GetRegisteredPlugins() {
+			names = append(names, p.Name())
+		}
+	default:
+		return nil
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetPluginByName 根据名称获取已注册的插件
 func GetPluginByName(name string) (AsyncSearchPlugin, bool) {
 	globalRegistryLock.RLock()
@@ -80,7 +138,9 @@ func GetPluginByName(name string) (AsyncSearchPlugin, bool) {
 
 // PluginManager 异步插件管理器
 type PluginManager struct {
-	plugins []AsyncSearchPlugin
+	mu          sync.RWMutex
+	plugins     []AsyncSearchPlugin
+	fingerprint string // 插件集合指纹，插件列表变化时清空，下次使用时重新计算
 }
 
 // NewPluginManager 创建新的异步插件管理器
@@ -90,9 +150,47 @@ func NewPluginManager() *PluginManager {
 	}
 }
 
-// RegisterPlugin 注册异步插件
+// RegisterPlugin 注册异步插件，同名插件已存在时替换
 func (pm *PluginManager) RegisterPlugin(plugin AsyncSearchPlugin) {
-	pm.plugins = append(pm.plugins, plugin)
+	if !pm.ReplacePlugin(plugin) {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		pm.plugins = append(pm.plugins, plugin)
+		pm.fingerprint = ""
+	}
+}
+
+// RemovePlugin 移除指定名称的插件，返回插件是否存在
+func (pm *PluginManager) RemovePlugin(name string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for i, p := range pm.plugins {
+		if p.Name() == name {
+			// 写时复制，不影响正在遍历旧列表的搜索
+			updated := make([]AsyncSearchPlugin, 0, len(pm.plugins)-1)
+			updated = append(updated, pm.plugins[:i]...)
+			pm.plugins = append(updated, pm.plugins[i+1:]...)
+			pm.fingerprint = ""
+			return true
+		}
+	}
+	return false
+}
+
+// ReplacePlugin 用新实例替换同名插件，返回插件是否存在（不存在时不添加）
+func (pm *PluginManager) ReplacePlugin(plugin AsyncSearchPlugin) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for i, p := range pm.plugins {
+		if p.Name() == plugin.Name() {
+			updated := make([]AsyncSearchPlugin, len(pm.plugins))
+			copy(updated, pm.plugins)
+			updated[i] = plugin
+			pm.plugins = updated
+			return true
+		}
+	}
+	return false
 }
 
 // RegisterAllGlobalPlugins 注册所有全局异步插件
@@ -132,8 +230,34 @@ func (pm *PluginManager) RegisterGlobalPluginsWithFilter(enabledPlugins []string
 	}
 }
 
-// GetPlugins 获取所有注册的异步插件
+// Fingerprint 获取当前插件集合的指纹（按名称排序后计算），插件集合相同时指纹相同，与注册顺序无关。
+// 替换同名插件不改变指纹
+func (pm *PluginManager) Fingerprint() string {
+	pm.mu.RLock()
+	fingerprint := pm.fingerprint
+	pm.mu.RUnlock()
+	if fingerprint != "" {
+		return fingerprint
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.fingerprint == "" {
+		names := make([]string, len(pm.plugins))
+		for i, p := range pm.plugins {
+			names[i] = p.Name()
+		}
+		sort.Strings(names)
+		sum := md5.Sum([]byte(strings.Join(names, "\n")))
+		pm.fingerprint = hex.EncodeToString(sum[:8])
+	}
+	return pm.fingerprint
+}
+
+// GetPlugins 获取所有注册的异步插件（返回的切片不会被之后的注册、移除修改）
 func (pm *PluginManager) GetPlugins() []AsyncSearchPlugin {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
 	return pm.plugins
 }
 
@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// 未加载配置时的隔离参数
+const (
+	defaultPluginPanicThreshold     = 3
+	defaultPluginPanicWindow        = 10 * time.Minute
+	defaultPluginQuarantineDuration = 30 * time.Minute
+)
+
+// PluginHealth 插件的panic统计和隔离状态
+type PluginHealth struct {
+	Name             string     `json:"name"`
+	Panics           int64      `json:"panics"`                       // 累计panic次数
+	RecentPanics     int        `json:"recent_panics"`                // 统计窗口内的panic次数
+	LastPanic        *time.Time `json:"last_panic,omitempty"`         // 最近一次panic时间
+	LastPanicMessage string     `json:"last_panic_message,omitempty"` // 最近一次panic的内容
+	Quarantines      int64      `json:"quarantines"`                  // 累计被隔离次数
+	QuarantinedUntil *time.Time `json:"quarantined_until,omitempty"`  // 隔离结束时间，未隔离时为空
+}
+
+// pluginGuard 单个插件的panic记录
+type pluginGuard struct {
+	panics           int64
+	recent           []time.Time
+	lastPanic        time.Time
+	lastMessage      string
+	quarantines      int64
+	quarantinedUntil time.Time
+}
+
+var (
+	pluginGuards     = make(map[string]*pluginGuard)
+	pluginGuardsLock sync.Mutex
+)
+
+// pluginGuardSettings 返回隔离阈值、统计窗口和隔离时长，阈值为0表示不自动隔离
+func pluginGuardSettings() (int, time.Duration, time.Duration) {
+	if config.AppConfig == nil {
+		return defaultPluginPanicThreshold, defaultPluginPanicWindow, defaultPluginQuarantineDuration
+	}
+	return config.AppConfig.PluginPanicThreshold, config.AppConfig.PluginPanicWindow, config.AppConfig.PluginQuarantineDuration
+}
+
+// RecordPluginPanic 记录插件的一次panic，统计窗口内次数达到阈值时隔离该插件
+func RecordPluginPanic(name string, value interface{}) {
+	threshold, window, duration := pluginGuardSettings()
+	now := time.Now()
+	message := fmt.Sprint(value)
+
+	pluginGuardsLock.Lock()
+	guard, ok := pluginGuards[name]
+	if !ok {
+		guard = &pluginGuard{}
+		pluginGuards[name] = guard
+	}
+	guard.panics++
+	guard.lastPanic = now
+	guard.lastMessage = message
+	guard.recent = append(pruneBefore(guard.recent, now.Add(-window)), now)
+	quarantined := false
+	if threshold > 0 && len(guard.recent) >= threshold && !now.Before(guard.quarantinedUntil) {
+		guard.quarantinedUntil = now.Add(duration)
+		guard.quarantines++
+		guard.recent = nil
+		quarantined = true
+	}
+	pluginGuardsLock.Unlock()
+
+	fmt.Printf("[%s] 插件发生panic: %s\n%s", name, message, debug.Stack())
+	if quarantined {
+		fmt.Printf("[%s] %v内panic达到%d次，隔离至 %s\n", name, window, threshold, now.Add(duration).Format("2006-01-02 15:04:05"))
+	}
+}
+
+// IsPluginQuarantined 插件是否处于隔离期，隔离期内的插件不参与搜索
+func IsPluginQuarantined(name string) bool {
+	pluginGuardsLock.Lock()
+	defer pluginGuardsLock.Unlock()
+	guard, ok := pluginGuards[name]
+	return ok && time.Now().Before(guard.quarantinedUntil)
+}
+
+// GetPluginHealth 获取发生过panic的插件的统计和隔离状态，按插件名排序
+func GetPluginHealth() []PluginHealth {
+	_, window, _ := pluginGuardSettings()
+	now := time.Now()
+
+	pluginGuardsLock.Lock()
+	defer pluginGuardsLock.Unlock()
+	health := make([]PluginHealth, 0, len(pluginGuards))
+	for name, guard := range pluginGuards {
+		guard.recent = pruneBefore(guard.recent, now.Add(-window))
+		lastPanic := guard.lastPanic
+		h := PluginHealth{
+			Name:             name,
+			Panics:           guard.panics,
+			RecentPanics:     len(guard.recent),
+			LastPanic:        &lastPanic,
+			LastPanicMessage: guard.lastMessage,
+			Quarantines:      guard.quarantines,
+		}
+		if now.Before(guard.quarantinedUntil) {
+			until := guard.quarantinedUntil
+			h.QuarantinedUntil = &until
+		}
+		health = append(health, h)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
+
+// GetQuarantinedPlugins 获取处于隔离期的插件名
+func GetQuarantinedPlugins() []string {
+	var names []string
+	for _, h := range GetPluginHealth() {
+		if h.QuarantinedUntil != nil {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
+
+// RecoverPluginPanic 在插件相关的goroutine中defer调用，捕获panic并计入插件统计
+func RecoverPluginPanic(name string) {
+	if r := recover(); r != nil {
+		RecordPluginPanic(name, r)
+	}
+}
+
+// pruneBefore 去除早于cutoff的时间点（times按时间升序）
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,24 @@
+package plugin
+
+import "sync/atomic"
+
+// ProvenanceRecorder 记录插件本次返回的结果的出处（model.Provenance*），requestID为ext中的请求ID
+type ProvenanceRecorder func(requestID string, pluginName string, provenance string)
+
+var provenanceRecorder atomic.Value // ProvenanceRecorder
+
+// SetProvenanceRecorder 设置结果出处的记录函数，由服务层在启动时注入
+func SetProvenanceRecorder(recorder ProvenanceRecorder) {
+	provenanceRecorder.Store(recorder)
+}
+
+// recordProvenance 记录插件结果的出处，ext中没有请求ID或未设置记录函数时忽略
+func recordProvenance(ext map[string]interface{}, name string, provenance string) {
+	requestID := RequestIDFromExt(ext)
+	if requestID == "" {
+		return
+	}
+	if recorder, ok := provenanceRecorder.Load().(ProvenanceRecorder); ok && recorder != nil {
+		recorder(requestID, name, provenance)
+	}
+}
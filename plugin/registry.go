@@ -0,0 +1,79 @@
+package plugin
+
+import "sync"
+
+// RegistryEventType 全局插件注册表的变更类型
+type RegistryEventType string
+
+// 注册表变更类型
+const (
+	PluginRegistered   RegistryEventType = "registered"
+	PluginDeregistered RegistryEventType = "deregistered"
+	PluginReplaced     RegistryEventType = "replaced"
+)
+
+// RegistryEvent 全局插件注册表的变更
+type RegistryEvent struct {
+	Type     RegistryEventType
+	Name     string
+	Plugin   AsyncSearchPlugin // 注册或替换后的插件，注销时为nil
+	Previous AsyncSearchPlugin // 被替换或注销的插件，注册时为nil
+}
+
+var (
+	registryObservers     []func(RegistryEvent)
+	registryObserversLock sync.RWMutex
+)
+
+// OnRegistryChange 注册注册表变更的观察者，观察者按注册顺序同步调用
+func OnRegistryChange(observer func(RegistryEvent)) {
+	if observer == nil {
+		return
+	}
+	registryObserversLock.Lock()
+	defer registryObserversLock.Unlock()
+	registryObservers = append(registryObservers, observer)
+}
+
+// notifyRegistryObservers 通知所有观察者
+func notifyRegistryObservers(event RegistryEvent) {
+	registryObserversLock.RLock()
+	observers := registryObservers
+	registryObserversLock.RUnlock()
+	for _, observer := range observers {
+		observer(event)
+	}
+}
+
+// DeregisterGlobalPlugin 从全局注册表注销插件，返回插件是否存在
+func DeregisterGlobalPlugin(name string) bool {
+	globalRegistryLock.Lock()
+	previous, exists := globalRegistry[name]
+	delete(globalRegistry, name)
+	globalRegistryLock.Unlock()
+
+	if exists {
+		notifyRegistryObservers(RegistryEvent{Type: PluginDeregistered, Name: name, Previous: previous})
+	}
+	return exists
+}
+
+// ReplaceGlobalPlugin 用新实例替换全局注册表中的同名插件，返回被替换的插件；插件不存在时不注册并返回false
+func ReplaceGlobalPlugin(plugin AsyncSearchPlugin) (AsyncSearchPlugin, bool) {
+	if plugin == nil || plugin.Name() == "" {
+		return nil, false
+	}
+	name := plugin.Name()
+
+	globalRegistryLock.Lock()
+	previous, exists := globalRegistry[name]
+	if exists {
+		globalRegistry[name] = plugin
+	}
+	globalRegistryLock.Unlock()
+
+	if exists {
+		notifyRegistryObservers(RegistryEvent{Type: PluginReplaced, Name: name, Plugin: plugin, Previous: previous})
+	}
+	return previous, exists
+}
@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// scheduleField cron表达式的一个字段，bits的第i位表示允许值i
+type scheduleField uint64
+
+// scheduleWindow 一个启用时段：分、时、日、月、周五个字段
+type scheduleWindow [5]scheduleField
+
+// pluginSchedule 插件的启用时段，任一时段匹配即启用
+type pluginSchedule struct {
+	exprs   []string
+	windows []scheduleWindow
+}
+
+// cron字段的取值范围：分、时、日、月、周（0和7都表示周日）
+var scheduleFieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+var (
+	pluginSchedulesOnce sync.Once
+	pluginSchedules     map[string]*pluginSchedule
+)
+
+// loadPluginSchedules 解析PLUGIN_SCHEDULES中各插件的启用时段（首次使用时执行一次），无效的表达式记录日志后忽略
+func loadPluginSchedules() map[string]*pluginSchedule {
+	pluginSchedulesOnce.Do(func() {
+		pluginSchedules = make(map[string]*pluginSchedule)
+		if config.AppConfig == nil {
+			return
+		}
+		for name, exprs := range config.AppConfig.PluginSchedules {
+			schedule := &pluginSchedule{}
+			for _, expr := range exprs {
+				window, err := parseScheduleExpr(expr)
+				if err != nil {
+					fmt.Printf("[插件时段] %s 的启用时段 %q 无效，已忽略: %v\n", name, expr, err)
+					continue
+				}
+				schedule.exprs = append(schedule.exprs, expr)
+				schedule.windows = append(schedule.windows, window)
+			}
+			if len(schedule.windows) > 0 {
+				pluginSchedules[name] = schedule
+			}
+		}
+	})
+	return pluginSchedules
+}
+
+// parseScheduleExpr 解析5个字段（分 时 日 月 周）的cron表达式，每个字段支持*、数字、范围a-b、
+// 步长*/n或a-b/n，以及用逗号分隔的列表
+func parseScheduleExpr(expr string) (scheduleWindow, error) {
+	var window scheduleWindow
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return window, fmt.Errorf("需要5个字段（分 时 日 月 周），实际为%d个", len(fields))
+	}
+	for i, field := range fields {
+		bits, err := parseScheduleField(field, scheduleFieldRanges[i][0], scheduleFieldRanges[i][1])
+		if err != nil {
+			return window, fmt.Errorf("第%d个字段%q: %v", i+1, field, err)
+		}
+		window[i] = bits
+	}
+	// 周日可以写作0或7
+	if window[4]&(1<<7) != 0 {
+		window[4] |= 1
+	}
+	return window, nil
+}
+
+// parseScheduleField 解析cron表达式的一个字段
+func parseScheduleField(field string, min, max int) (scheduleField, error) {
+	var bits scheduleField
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("无效的步长%q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("无效的值%q", loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("无效的值%q", hiStr)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("取值需在%d~%d之间", min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches 时间t是否落在时段内。与cron一致，日和周都不是*时满足其一即可
+func (w scheduleWindow) matches(t time.Time) bool {
+	if w[0]&(1<<uint(t.Minute())) == 0 || w[1]&(1<<uint(t.Hour())) == 0 || w[3]&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dayAll := w[2] == fullScheduleField(2)
+	weekAll := w[4]&fullWeekField == fullWeekField
+	dayMatch := w[2]&(1<<uint(t.Day())) != 0
+	weekMatch := w[4]&(1<<uint(t.Weekday())) != 0
+	if !dayAll && !weekAll {
+		return dayMatch || weekMatch
+	}
+	return dayMatch && weekMatch
+}
+
+// 周字段中周日到周六（0~6）全部允许时的值
+const fullWeekField scheduleField = 1<<7 - 1
+
+// fullScheduleField 字段取值范围内全部允许时的值
+func fullScheduleField(i int) scheduleField {
+	var bits scheduleField
+	for v := scheduleFieldRanges[i][0]; v <= scheduleFieldRanges[i][1]; v++ {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+// IsPluginInSchedule 插件在t时是否处于启用时段，未配置时段（或时段均无效）的插件始终启用
+func IsPluginInSchedule(name string, t time.Time) bool {
+	schedule, ok := loadPluginSchedules()[name]
+	if !ok {
+		return true
+	}
+	for _, window := range schedule.windows {
+		if window.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPluginSchedule 获取插件配置的有效启用时段，未配置时返回nil
+func GetPluginSchedule(name string) []string {
+	if schedule, ok := loadPluginSchedules()[name]; ok {
+		return schedule.exprs
+	}
+	return nil
+}
+
+// ScheduledPlugins 从plugins中筛选出在t时处于启用时段的插件，搜索时调用
+func (pm *PluginManager) ScheduledPlugins(plugins []AsyncSearchPlugin, t time.Time) []AsyncSearchPlugin {
+	if len(loadPluginSchedules()) == 0 {
+		return plugins
+	}
+	scheduled := make([]AsyncSearchPlugin, 0, len(plugins))
+	for _, p := range plugins {
+		if IsPluginInSchedule(p.Name(), t) {
+			scheduled = append(scheduled, p)
+		}
+	}
+	return scheduled
+}
@@ -16,6 +16,7 @@ import (
 
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 const (
@@ -86,6 +87,10 @@ func init() {
 		BaseAsyncPlugin: plugin.NewBaseAsyncPlugin("sdso", 3), // 优先级3 = 普通质量数据源
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterExtSchema("sdso",
+		plugin.ExtField{Key: "pages_per_type", Type: plugin.ExtTypeInt, Default: DefaultPagesPerType, Description: "每种网盘类型搜索的页数，最大5"},
+		plugin.ExtField{Key: "pages", Type: plugin.ExtTypeInt, Description: "总页数，平均分配给各网盘类型（兼容旧参数）"},
+	)
 }
 
 // Search 执行搜索并返回结果（兼容性方法）
@@ -345,31 +350,7 @@ func (p *SDSOPlugin) fetchSinglePageWithType(client *http.Client, keyword string
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *SDSOPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
 
 // DecryptURL 解密SDSO网站返回的加密URL
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/model"
+	"pansou/util"
+)
+
+// errSearchPanicked 合并执行的搜索发生panic时，等待同一搜索的其他调用方收到的错误
+var errSearchPanicked = errors.New("合并执行的插件搜索异常退出")
+
+// 被合并（未实际请求上游）的插件搜索次数 (仅用于内部监控)
+var coalescedSearches int64
+
+// searchCall 一次正在执行的插件搜索
+type searchCall struct {
+	done    chan struct{}
+	results []model.SearchResult
+	err     error
+}
+
+// searchFlightGroup 合并相同键的并发搜索，同一时刻每个键只执行一次
+type searchFlightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*searchCall
+}
+
+// 全局插件搜索合并组，键与插件内存缓存键一致（插件名:关键词，以及ext参数哈希）
+var searchFlights = &searchFlightGroup{calls: make(map[string]*searchCall)}
+
+// do 执行搜索；已有相同键的搜索在执行时等待其结果，shared表示结果来自其他调用方的执行
+func (g *searchFlightGroup) do(key string, fn func() ([]model.SearchResult, error)) (results []model.SearchResult, err error, shared bool) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.results, call.err, true
+	}
+	call := &searchCall{done: make(chan struct{}), err: errSearchPanicked}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.results, call.err = fn()
+	return call.results, call.err, false
+}
+
+// extKeySearchFlight ext参数中标记正在执行的合并搜索的键，用于识别嵌套调用
+const extKeySearchFlight = "_search_flight"
+
+// withSearchFlight 复制ext并写入合并搜索的键。ext由多个插件并发共享，不能直接修改
+func withSearchFlight(ext map[string]interface{}, key string) map[string]interface{} {
+	flightExt := make(map[string]interface{}, len(ext)+1)
+	for k, v := range ext {
+		flightExt[k] = v
+	}
+	flightExt[extKeySearchFlight] = key
+	return flightExt
+}
+
+// searchOnce 执行插件搜索（主入口无结果时尝试备用入口），同一插件同一关键词的并发搜索共享一次上游请求。
+// 搜索中的panic被捕获并计入插件统计，处于隔离期的插件直接返回错误。
+// 缓存写入等后续处理仍由各调用方按自己的主缓存键完成
+func (p *BaseAsyncPlugin) searchOnce(
+	searchFunc func(*http.Client, string, map[string]interface{}) ([]model.SearchResult, error),
+	client *http.Client,
+	keyword string,
+	ext map[string]interface{},
+) ([]model.SearchResult, error) {
+	if IsPluginQuarantined(p.name) {
+		return nil, fmt.Errorf("[%s] 插件因频繁panic处于隔离期", p.name)
+	}
+	key := p.pluginCacheKey(keyword, ext)
+	run := func() (results []model.SearchResult, err error) {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				RecordPluginPanic(p.name, r)
+				results, err = nil, fmt.Errorf("[%s] 插件搜索发生panic: %v", p.name, r)
+			}
+		}()
+		results, err = p.searchWithFallbacks(searchFunc, client, keyword, withSearchFlight(ext, key))
+		recordObservedCloudTypes(p.name, results)
+		util.PluginDebugf(p.name, "%s搜索完成: %s | 结果数: %d | 耗时: %v | 错误: %v", requestLogTag(ext), keyword, len(results), time.Since(start), err)
+		return results, err
+	}
+
+	// 服务层的搜索函数会调用插件的Search方法再次进入searchOnce，嵌套调用不能等待外层的搜索，
+	// 使用单独的键合并，避免嵌套搜索超时后的后台补全与仍在执行的嵌套搜索重复请求上游
+	flightKey := key
+	if inFlight, _ := ext[extKeySearchFlight].(string); inFlight == key {
+		flightKey = key + extKeySearchFlight
+	}
+	results, err, shared := searchFlights.do(flightKey, run)
+	if shared {
+		atomic.AddInt64(&coalescedSearches, 1)
+		util.PluginDebugf(p.name, "%s合并到进行中的相同搜索: %s | 结果数: %d", requestLogTag(ext), keyword, len(results))
+	}
+	return results, err
+}
@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 单个条目HTML的最大长度，超过时丢弃该条目，避免标签未闭合时缓存整个页面
+const maxStreamItemBytes = 2 << 20
+
+// HTMLStreamOptions 流式解析HTML的条件
+type HTMLStreamOptions struct {
+	// Container 可选，只在第一个匹配该选择器的元素内查找条目，如 "ul#post_container"，该元素结束后停止读取。
+	// 与Item一样只能使用元素自身的条件
+	Container string
+	// Item 条目选择器，只能使用条目元素自身的条件（标签、类、ID、属性），不能包含后代、子元素等组合器，
+	// 如 "li.post"、".search-item.detail-width"。条目元素需要有结束标签
+	Item string
+	// MaxItems 解析到的条目数达到该值后停止读取，<=0表示读完整个页面
+	MaxItems int
+}
+
+// StreamHTMLItems 边读取边解析HTML：每读到一个完整的条目元素，就将其解析为goquery选择集交给fn处理，
+// fn返回false或条目数达到MaxItems时停止读取剩余内容，调用方关闭响应体即可放弃未读取的部分。
+// 适用于结果页很大、只需要前若干条结果的插件。返回交给fn处理的条目数
+func StreamHTMLItems(r io.Reader, opts HTMLStreamOptions, fn func(i int, s *goquery.Selection) bool) (int, error) {
+	itemMatcher, err := cascadia.Compile(opts.Item)
+	if err != nil {
+		return 0, fmt.Errorf("条目选择器无效: %w", err)
+	}
+	var containerMatcher cascadia.Selector
+	if opts.Container != "" {
+		if containerMatcher, err = cascadia.Compile(opts.Container); err != nil {
+			return 0, fmt.Errorf("容器选择器无效: %w", err)
+		}
+	}
+
+	z := html.NewTokenizer(r)
+	count := 0
+
+	// 容器内的元素层级，0表示不在容器内（未设置容器时视为始终在容器内）
+	containerTag, containerLevel := "", 0
+	// 正在读取的条目：标签名、同名标签的层级和已读取的原始HTML
+	itemTag, itemLevel := "", 0
+	var item bytes.Buffer
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return count, nil
+			}
+			return count, z.Err()
+		}
+
+		raw := z.Raw()
+		var name string
+		if tt == html.StartTagToken || tt == html.EndTagToken || tt == html.SelfClosingTagToken {
+			nameBytes, _ := z.TagName()
+			name = string(nameBytes)
+		}
+
+		// 正在读取条目：累积原始HTML，条目元素结束时解析
+		if itemTag != "" {
+			item.Write(raw)
+			switch {
+			case tt == html.StartTagToken && name == itemTag:
+				itemLevel++
+			case tt == html.EndTagToken && name == itemTag:
+				itemLevel--
+			}
+			if item.Len() > maxStreamItemBytes {
+				itemTag = ""
+				item.Reset()
+			} else if itemLevel == 0 {
+				itemTag = ""
+				if s := parseStreamItem(item.String(), name, itemMatcher); s != nil {
+					if !fn(count, s) {
+						return count + 1, nil
+					}
+					count++
+					if opts.MaxItems > 0 && count >= opts.MaxItems {
+						return count, nil
+					}
+				}
+				item.Reset()
+			}
+		}
+
+		// 条目内的标签同样需要更新容器层级，条目内不再匹配新的条目
+		switch tt {
+		case html.StartTagToken:
+			if containerTag != "" && name == containerTag {
+				containerLevel++
+			}
+			if itemTag != "" {
+				continue
+			}
+			node := startTagNode(z, name)
+			if containerMatcher != nil && containerTag == "" {
+				if containerMatcher.Match(node) {
+					containerTag, containerLevel = name, 1
+				}
+				continue
+			}
+			if itemMatcher.Match(node) && !isVoidElement(name) {
+				itemTag, itemLevel = name, 1
+				item.Write(raw)
+			}
+		case html.EndTagToken:
+			if containerTag != "" && name == containerTag {
+				if containerLevel--; containerLevel == 0 {
+					containerTag = ""
+					// 容器结束后不会再有需要的条目
+					return count, nil
+				}
+			}
+		}
+	}
+}
+
+// startTagNode 将当前开始标签转为游离的元素节点，用于匹配选择器
+func startTagNode(z *html.Tokenizer, name string) *html.Node {
+	node := &html.Node{Type: html.ElementNode, Data: name, DataAtom: atom.Lookup([]byte(name))}
+	for {
+		key, val, more := z.TagAttr()
+		if len(key) > 0 {
+			node.Attr = append(node.Attr, html.Attribute{Key: string(key), Val: string(val)})
+		}
+		if !more {
+			return node
+		}
+	}
+}
+
+// parseStreamItem 解析单个条目的HTML，表格相关的元素补上外层表格，否则会被HTML解析器丢弃
+func parseStreamItem(fragment string, tag string, matcher cascadia.Selector) *goquery.Selection {
+	switch tag {
+	case "tr":
+		fragment = "<table><tbody>" + fragment + "</tbody></table>"
+	case "td", "th":
+		fragment = "<table><tbody><tr>" + fragment + "</tr></tbody></table>"
+	case "tbody", "thead", "tfoot":
+		fragment = "<table>" + fragment + "</table>"
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return nil
+	}
+	s := doc.FindMatcher(matcher).First()
+	if s.Length() == 0 {
+		return nil
+	}
+	return s
+}
+
+// isVoidElement 是否为没有结束标签的空元素
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 // 常量定义
@@ -95,7 +96,7 @@ func createOptimizedHTTPClient() *http.Client {
 	}
 	
 	return &http.Client{
-		Transport: transport,
+		Transport: util.NewPluginTransport("thepiratebay", transport),
 		Timeout:   DefaultTimeout,
 	}
 }
@@ -111,6 +112,8 @@ func NewThePirateBayPlugin() *ThePirateBayPlugin {
 // 初始化插件
 func init() {
 	plugin.RegisterGlobalPlugin(NewThePirateBayPlugin())
+	plugin.RegisterExtSchema("thepiratebay", plugin.ExtField{Key: "title_en", Type: plugin.ExtTypeString, Description: "英文标题，与中文关键词一起搜索"})
+	plugin.RegisterPluginMetadata(plugin.PluginMetadata{Name: "thepiratebay", DisplayName: "The Pirate Bay", Homepage: "https://tpirbay.xyz", CloudTypes: []string{"magnet"}, Language: "en"})
 	
 	// 启动缓存清理
 	go startCacheCleaner()
@@ -472,29 +475,5 @@ func (p *ThePirateBayPlugin) parseUploadTime(timeStr string) time.Time {
 
 // doRequestWithRetry 带重试机制的HTTP请求 - 参考插件开发指南的最佳实践
 func (p *ThePirateBayPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
\ No newline at end of file
@@ -36,6 +36,7 @@ func init() {
 		debugMode:       false,
 	}
 	plugin.RegisterGlobalPlugin(p)
+	plugin.RegisterPluginMetadata(plugin.PluginMetadata{Name: "u3c3", DisplayName: "U3C3", Homepage: BaseURL, CloudTypes: []string{"magnet"}, Language: "en"})
 }
 
 // Search 搜索接口实现
@@ -14,6 +14,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 // 常量定义
@@ -79,6 +80,11 @@ func (p *WujiPlugin) Description() string {
 	return "ØMagnet 无极磁链 - 磁力链接搜索引擎"
 }
 
+// Homepage 返回插件站点地址
+func (p *WujiPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *WujiPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -377,23 +383,10 @@ func (p *WujiPlugin) setRequestHeaders(req *http.Request) {
 	req.Header.Set("Pragma", "no-cache")
 }
 
-// doRequestWithRetry 带重试的HTTP请求
+// doRequestWithRetry 发送HTTP请求，网络错误和临时错误状态码由插件传输层重试，最多尝试MaxRetries次
 func (p *WujiPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	var lastErr error
-	
-	for i := 0; i < MaxRetries; i++ {
-		resp, err := client.Do(req)
-		if err == nil {
-			return resp, nil
-		}
-		
-		lastErr = err
-		if i < MaxRetries-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
-		}
-	}
-	
-	return nil, fmt.Errorf("请求失败，已重试%d次: %w", MaxRetries, lastErr)
+	policy := util.RetryPolicy{MaxAttempts: MaxRetries, BaseDelay: time.Second, RetryNonIdempotent: true}
+	return client.Do(req.WithContext(util.WithRetryPolicy(req.Context(), policy)))
 }
 
 // enrichWithMagnetLinks 并发获取磁力链接并丰富搜索结果
@@ -461,4 +454,5 @@ func (p *WujiPlugin) enrichWithMagnetLinks(results []model.SearchResult, client
 // init 注册插件
 func init() {
 	plugin.RegisterGlobalPlugin(NewWujiPlugin())
+	plugin.RegisterExtSchema("wuji", plugin.ExtField{Key: "search", Type: plugin.ExtTypeString, Description: "结果过滤使用的关键词，默认为搜索关键词"})
 }
\ No newline at end of file
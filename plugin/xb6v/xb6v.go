@@ -75,6 +75,11 @@ func (p *Xb6vPlugin) Description() string {
 	return "6v电影 - 磁力链接资源站"
 }
 
+// Homepage 返回插件站点地址
+func (p *Xb6vPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *Xb6vPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -298,14 +303,12 @@ func (p *Xb6vPlugin) searchImpl(client *http.Client, keyword string, ext map[str
 		return nil, err
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(reader)
+	// 边读取边提取搜索结果（详情页链接和日期），达到最大结果数后不再读取剩余内容
+	detailPages, err := p.extractDetailURLs(reader)
 	if err != nil {
 		return nil, fmt.Errorf("解析搜索结果HTML失败: %w", err)
 	}
 	
-	// 提取搜索结果（详情页链接和日期）
-	detailPages := p.extractDetailURLs(doc)
-	
 	if p.debugMode {
 		log.Printf("[Xb6v] 找到 %d 个详情页链接", len(detailPages))
 	}
@@ -361,22 +364,23 @@ func (p *Xb6vPlugin) getResponseReader(resp *http.Response) (io.Reader, error) {
 	return reader, nil
 }
 
-// extractDetailURLs 从搜索结果页面提取详情页链接和日期
-func (p *Xb6vPlugin) extractDetailURLs(doc *goquery.Document) []DetailPageInfo {
+// extractDetailURLs 从搜索结果页面流式提取详情页链接和日期
+func (p *Xb6vPlugin) extractDetailURLs(reader io.Reader) ([]DetailPageInfo, error) {
 	var detailPages []DetailPageInfo
 	urlMap := make(map[string]bool) // 去重
 	
 	// 只从搜索结果区域提取链接，搜索结果在 ul#post_container 中
-	doc.Find("ul#post_container li.post").Each(func(i int, li *goquery.Selection) {
+	opts := plugin.HTMLStreamOptions{Container: "ul#post_container", Item: "li.post"}
+	_, err := plugin.StreamHTMLItems(reader, opts, func(i int, li *goquery.Selection) bool {
 		// 提取详情页链接
 		linkEl := li.Find("a[href*='.html']")
 		if linkEl.Length() == 0 {
-			return
+			return true
 		}
 		
 		href, exists := linkEl.Attr("href")
 		if !exists || href == "" {
-			return
+			return true
 		}
 		
 		if p.debugMode {
@@ -388,7 +392,7 @@ func (p *Xb6vPlugin) extractDetailURLs(doc *goquery.Document) []DetailPageInfo {
 			if p.debugMode {
 				log.Printf("[Xb6v] 链接格式无效，跳过: %s", href)
 			}
-			return
+			return true
 		}
 		
 		// 构建完整URL
@@ -401,7 +405,7 @@ func (p *Xb6vPlugin) extractDetailURLs(doc *goquery.Document) []DetailPageInfo {
 		
 		// 去重检查
 		if urlMap[fullURL] {
-			return
+			return true
 		}
 		
 		// 提取发布日期
@@ -434,13 +438,17 @@ func (p *Xb6vPlugin) extractDetailURLs(doc *goquery.Document) []DetailPageInfo {
 		if p.debugMode {
 			log.Printf("[Xb6v] 添加有效链接: %s, 日期: %s", fullURL, publishDate.Format("2006-01-02"))
 		}
+		return len(detailPages) < MaxResults
 	})
+	if err != nil && len(detailPages) == 0 {
+		return nil, err
+	}
 	
 	if p.debugMode {
 		log.Printf("[Xb6v] 提取到 %d 个有效详情页链接", len(detailPages))
 	}
 	
-	return detailPages
+	return detailPages, nil
 }
 
 // isInSidebar 检查元素是否在侧边栏或不相关区域
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 	"pansou/util/json"
 	"strings"
 	"sync"
@@ -76,7 +77,7 @@ func createOptimizedHTTPClient() *http.Client {
 		DisableKeepAlives:   false,
 		ForceAttemptHTTP2:   true,
 	}
-	return &http.Client{Transport: transport, Timeout: DefaultTimeout}
+	return &http.Client{Transport: util.NewPluginTransport(pluginName, transport), Timeout: DefaultTimeout}
 }
 
 // NewXdyhPlugin 创建新的XDYH异步插件
@@ -194,31 +195,7 @@ func (p *XdyhAsyncPlugin) setRequestHeaders(req *http.Request) {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *XdyhAsyncPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 500 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond})
 }
 
 // convertToSearchResults 将API响应转换为标准搜索结果
@@ -15,6 +15,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 const (
@@ -31,6 +32,7 @@ type XiaozhangPlugin struct {
 	debugMode    bool
 	detailCache  sync.Map // 缓存详情页结果
 	cacheTTL     time.Duration
+	transport    http.RoundTripper // 禁用自动解压的共享传输层，避免每次请求新建连接池
 }
 
 // NewXiaozhangPlugin 创建新的校长影视插件实例
@@ -42,6 +44,9 @@ func NewXiaozhangPlugin() *XiaozhangPlugin {
 		BaseAsyncPlugin: plugin.NewBaseAsyncPlugin("xiaozhang", 3),
 		debugMode:       debugMode,
 		cacheTTL:        30 * time.Minute,
+		transport: util.NewPluginTransport("xiaozhang", &http.Transport{
+			DisableCompression: true, // 禁用自动gzip解压，我们手动处理
+		}),
 	}
 	
 	return p
@@ -62,6 +67,11 @@ func (p *XiaozhangPlugin) Description() string {
 	return "校长影视 - 影视资源搜索"
 }
 
+// Homepage 返回插件站点地址
+func (p *XiaozhangPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 执行搜索并返回结果（兼容性方法）
 func (p *XiaozhangPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	result, err := p.SearchWithResult(keyword, ext)
@@ -93,10 +103,8 @@ func (p *XiaozhangPlugin) setRequestHeaders(req *http.Request, referer string) {
 func (p *XiaozhangPlugin) doRequest(client *http.Client, url string, referer string, followRedirect bool) (*http.Response, error) {
 	// 创建临时客户端，控制重定向行为
 	tempClient := &http.Client{
-		Timeout: client.Timeout,
-		Transport: &http.Transport{
-			DisableCompression: true, // 禁用自动gzip解压，我们手动处理
-		},
+		Timeout:   client.Timeout,
+		Transport: p.transport,
 	}
 	
 	if !followRedirect {
@@ -17,6 +17,7 @@ import (
 	"pansou/model"
 	"pansou/plugin"
 	"pansou/util/json"
+	"pansou/util"
 )
 
 const (
@@ -86,6 +87,11 @@ func (p *XysPlugin) Description() string {
 	return Description
 }
 
+// Homepage 返回插件站点地址
+func (p *XysPlugin) Homepage() string {
+	return BaseURL
+}
+
 // Search 搜索接口
 func (p *XysPlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
 	return p.searchImpl(&http.Client{Timeout: 30 * time.Second}, keyword, ext)
@@ -206,31 +212,11 @@ func (p *XysPlugin) getToken(client *http.Client, keyword string) (string, error
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *XysPlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
+	resp, err := util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %w", p.Name(), err)
 	}
-	
-	return nil, fmt.Errorf("[%s] 重试 %d 次后仍然失败: %w", p.Name(), maxRetries, lastErr)
+	return resp, nil
 }
 
 // executeSearch 执行搜索请求
@@ -16,6 +16,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
 	"pansou/plugin"
+	"pansou/util"
 )
 
 const (
@@ -24,6 +25,7 @@ const (
 	UserAgent         = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
 	MaxConcurrency    = 5  // 详情页最大并发数
 	MaxRetryCount     = 2  // 最大重试次数
+	MaxResults        = 50 // 最大搜索结果数，解析到该数量后不再读取页面剩余内容
 )
 
 // YuhuagePlugin 雨花阁插件
@@ -113,17 +115,11 @@ func (p *YuhuagePlugin) searchImpl(client *http.Client, keyword string, ext map[
 		return nil, fmt.Errorf("[%s] HTTP错误: %d", p.Name(), resp.StatusCode)
 	}
 	
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
+	// 边读取边解析搜索结果，达到最大结果数后不再读取剩余内容
+	results, err := p.parseSearchResults(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("[%s] 读取响应失败: %w", p.Name(), err)
 	}
-	
-	// 解析搜索结果
-	results, err := p.parseSearchResults(string(body))
-	if err != nil {
-		return nil, err
-	}
 
 	if p.debugMode {
 		log.Printf("[YUHUAGE] 搜索完成，获得 %d 个结果", len(results))
@@ -133,23 +129,18 @@ func (p *YuhuagePlugin) searchImpl(client *http.Client, keyword string, ext map[
 	return plugin.FilterResultsByKeyword(results, keyword), nil
 }
 
-// parseSearchResults 解析搜索结果
-func (p *YuhuagePlugin) parseSearchResults(html string) ([]model.SearchResult, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
-	if err != nil {
-		return nil, err
-	}
-
+// parseSearchResults 流式解析搜索结果页
+func (p *YuhuagePlugin) parseSearchResults(body io.Reader) ([]model.SearchResult, error) {
 	var results []model.SearchResult
 	var detailURLs []string
 
 	// 提取搜索结果
-	doc.Find(".search-item.detail-width").Each(func(i int, s *goquery.Selection) {
+	_, err := plugin.StreamHTMLItems(body, plugin.HTMLStreamOptions{Item: ".search-item.detail-width"}, func(i int, s *goquery.Selection) bool {
 		title := strings.TrimSpace(p.cleanTitle(s.Find(".item-title h3 a").Text()))
 		detailHref, exists := s.Find(".item-title h3 a").Attr("href")
 		
 		if !exists || title == "" {
-			return
+			return true
 		}
 
 		detailURL := BaseURL + detailHref
@@ -179,7 +170,11 @@ func (p *YuhuagePlugin) parseSearchResults(html string) ([]model.SearchResult, e
 		}
 
 		results = append(results, result)
+		return len(results) < MaxResults
 	})
+	if err != nil && len(results) == 0 {
+		return nil, err
+	}
 
 	if p.debugMode {
 		log.Printf("[YUHUAGE] 解析到 %d 个搜索结果，准备获取详情", len(results))
@@ -387,29 +382,5 @@ func (p *YuhuagePlugin) parseDateTime(timeStr string) time.Time {
 
 // doRequestWithRetry 带重试机制的HTTP请求
 func (p *YuhuagePlugin) doRequestWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
-	maxRetries := 3
-	var lastErr error
-	
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			// 指数退避重试
-			backoff := time.Duration(1<<uint(i-1)) * 200 * time.Millisecond
-			time.Sleep(backoff)
-		}
-		
-		// 克隆请求避免并发问题
-		reqClone := req.Clone(req.Context())
-		
-		resp, err := client.Do(reqClone)
-		if err == nil && resp.StatusCode == 200 {
-			return resp, nil
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-		lastErr = err
-	}
-	
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, lastErr)
+	return util.DoWithRetry(client, req, util.RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond})
 }
\ No newline at end of file
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/util"
+	"pansou/util/pool"
+)
+
+const (
+	// 并发数的调整周期
+	concurrencyAdjustInterval = 10 * time.Second
+	// 一个周期内至少有这么多搜索任务才按错误率和超时率调整
+	minConcurrencySamples = 20
+	// 失败率（超时和过载类错误的任务占比）超过此值时降低并发数，低于concurrencyHealthyFailureRate时才允许提高
+	concurrencyMaxFailureRate     = 0.3
+	concurrencyHealthyFailureRate = 0.1
+	// 进程CPU占用率（占GOMAXPROCS的比例）超过此值时降低并发数，低于concurrencyHealthyCPU时才允许提高
+	concurrencyMaxCPU     = 0.85
+	concurrencyHealthyCPU = 0.6
+)
+
+// ConcurrencyStatus 自适应并发的当前状态
+type ConcurrencyStatus struct {
+	Enabled     bool       `json:"enabled"`
+	Current     int        `json:"current"`               // 当前每次搜索的并发数
+	Min         int        `json:"min"`                   // 下限
+	Max         int        `json:"max"`                   // 上限
+	Initial     int        `json:"initial"`               // 初始值（CONCURRENCY或频道数+插件数+10）
+	FailureRate float64    `json:"failure_rate"`          // 上一周期搜索任务中超时和过载类错误的占比
+	CPUUsage    float64    `json:"cpu_usage"`             // 上一周期进程的CPU占用率，无法获取时为-1
+	Reason      string     `json:"reason,omitempty"`      // 最近一次调整的原因
+	AdjustedAt  *time.Time `json:"adjusted_at,omitempty"` // 最近一次调整的时间
+}
+
+// 错误信息中的HTTP状态码，插件通常以"状态码: 503"的形式返回
+var errorStatusCodePattern = regexp.MustCompile(`(?i)(?:状态码|status(?:\s*code)?)\s*[:：=]?\s*(\d{3})`)
+
+// concurrencyController 按近期搜索任务的失败率和进程CPU占用调整每次搜索的并发数：
+// 失败率或CPU占用过高时按比例降低，两者都正常时逐步提高（与panta插件内部的并发调整类似，但作用于所有搜索）
+type concurrencyController struct {
+	mu       sync.Mutex
+	current  int
+	min      int
+	max      int
+	initial  int
+	tasks    int
+	failures int
+
+	lastCPU     time.Duration
+	lastCPUTime time.Time
+
+	failureRate float64
+	cpuUsage    float64
+	reason      string
+	adjustedAt  time.Time
+}
+
+var (
+	concurrencyCtl     *concurrencyController
+	concurrencyCtlOnce sync.Once
+)
+
+// getConcurrencyController 获取自适应并发控制器，未启用ADAPTIVE_CONCURRENCY时返回nil。
+// 首次使用时以当时的默认并发数为初始值（启动时已按实际插件数更新），并开始定期调整
+func getConcurrencyController() *concurrencyController {
+	concurrencyCtlOnce.Do(func() {
+		if config.AppConfig == nil || !config.AppConfig.AdaptiveConcurrency {
+			return
+		}
+		concurrencyCtl = newConcurrencyController(config.AppConfig.DefaultConcurrency, config.AppConfig.ConcurrencyMin, config.AppConfig.ConcurrencyMax)
+		go func() {
+			ticker := time.NewTicker(concurrencyAdjustInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				concurrencyCtl.adjust()
+			}
+		}()
+	})
+	return concurrencyCtl
+}
+
+// newConcurrencyController 创建并发控制器，upper为0时为初始值的2倍
+func newConcurrencyController(initial, lower, upper int) *concurrencyController {
+	if lower < 1 {
+		lower = 1
+	}
+	if upper <= 0 {
+		upper = initial * 2
+	}
+	if upper < lower {
+		upper = lower
+	}
+	current := initial
+	if current < lower {
+		current = lower
+	}
+	if current > upper {
+		current = upper
+	}
+	return &concurrencyController{
+		current:     current,
+		min:         lower,
+		max:         upper,
+		initial:     initial,
+		lastCPU:     util.ProcessCPUTime(),
+		lastCPUTime: time.Now(),
+		cpuUsage:    -1,
+	}
+}
+
+// defaultSearchConcurrency 请求未指定并发数时使用的并发数：启用自适应并发时为当前调整后的值，否则为DefaultConcurrency
+func defaultSearchConcurrency() int {
+	if c := getConcurrencyController(); c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.current
+	}
+	return config.AppConfig.DefaultConcurrency
+}
+
+// recordConcurrencySample 记录一个TG频道或插件搜索任务的结果，只有超时和过载类错误计为失败。
+// 等待并发名额时超时的任务没有执行，不计入样本
+func recordConcurrencySample(err error) {
+	if c := getConcurrencyController(); c != nil {
+		c.record(err)
+	}
+}
+
+// record 记录一个任务的结果
+func (c *concurrencyController) record(err error) {
+	if errors.Is(err, pool.ErrNotStarted) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tasks++
+	if isOverloadError(err) {
+		c.failures++
+	}
+}
+
+// isOverloadError 判断任务错误是否说明上游或本机负载过高：执行超时、网络超时、限流（429）和服务端错误（5xx）。
+// 站点失效、解析失败等普通错误与并发数无关，不计为过载，避免几个长期失效的插件持续压低并发数
+func isOverloadError(err error) bool {
+	if err == nil || errors.Is(err, pool.ErrNotStarted) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if match := errorStatusCodePattern.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return code == 429 || code >= 500 && code < 600
+	}
+	return false
+}
+
+// adjust 按上一周期的失败率和CPU占用调整并发数并开始新的周期
+func (c *concurrencyController) adjust() {
+	now := time.Now()
+	cpuUsage := -1.0
+	if cpu := util.ProcessCPUTime(); cpu >= 0 && c.lastCPU >= 0 {
+		if wall := now.Sub(c.lastCPUTime); wall > 0 {
+			cpuUsage = float64(cpu-c.lastCPU) / float64(wall) / float64(runtime.GOMAXPROCS(0))
+		}
+		c.lastCPU, c.lastCPUTime = cpu, now
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failureRate := 0.0
+	enoughSamples := c.tasks >= minConcurrencySamples
+	if c.tasks > 0 {
+		failureRate = float64(c.failures) / float64(c.tasks)
+	}
+	c.failureRate, c.cpuUsage = failureRate, cpuUsage
+	c.tasks, c.failures = 0, 0
+
+	previous := c.current
+	var reason string
+	switch {
+	case cpuUsage > concurrencyMaxCPU:
+		c.current = c.current * 3 / 4
+		reason = fmt.Sprintf("CPU占用%.0f%%", cpuUsage*100)
+	case enoughSamples && failureRate > concurrencyMaxFailureRate:
+		c.current = c.current * 3 / 4
+		reason = fmt.Sprintf("失败率%.0f%%", failureRate*100)
+	case enoughSamples && failureRate < concurrencyHealthyFailureRate && cpuUsage < concurrencyHealthyCPU:
+		// 按初始值的1/10逐步提高
+		step := c.initial / 10
+		if step < 1 {
+			step = 1
+		}
+		c.current += step
+		reason = fmt.Sprintf("失败率%.0f%%", failureRate*100)
+	}
+	if c.current < c.min {
+		c.current = c.min
+	}
+	if c.current > c.max {
+		c.current = c.max
+	}
+
+	if c.current != previous {
+		c.reason = reason
+		c.adjustedAt = now
+		fmt.Printf("[自适应并发] %d -> %d | %s\n", previous, c.current, reason)
+	}
+}
+
+// GetConcurrencyStatus 获取自适应并发的当前状态
+func GetConcurrencyStatus() ConcurrencyStatus {
+	c := getConcurrencyController()
+	if c == nil {
+		concurrency := config.AppConfig.DefaultConcurrency
+		return ConcurrencyStatus{Current: concurrency, Min: concurrency, Max: concurrency, Initial: concurrency, CPUUsage: -1}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := ConcurrencyStatus{
+		Enabled:     true,
+		Current:     c.current,
+		Min:         c.min,
+		Max:         c.max,
+		Initial:     c.initial,
+		FailureRate: c.failureRate,
+		CPUUsage:    c.cpuUsage,
+		Reason:      c.reason,
+	}
+	if !c.adjustedAt.IsZero() {
+		adjustedAt := c.adjustedAt
+		status.AdjustedAt = &adjustedAt
+	}
+	return status
+}
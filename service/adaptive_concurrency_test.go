@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pansou/util/pool"
+)
+
+// newTestConcurrencyController 创建不测量CPU占用的并发控制器，调整只取决于记录的任务结果
+func newTestConcurrencyController(initial, lower, upper int) *concurrencyController {
+	c := newConcurrencyController(initial, lower, upper)
+	c.lastCPU = -1
+	return c
+}
+
+// notStartedErr 用工作池产生一个等待并发名额时超时的任务错误
+func notStartedErr(t *testing.T) error {
+	t.Helper()
+	release := make(chan struct{})
+	tasks := []func(context.Context) (int, error){
+		func(context.Context) (int, error) {
+			<-release
+			return 0, nil
+		},
+		func(context.Context) (int, error) {
+			return 0, nil
+		},
+	}
+	// 第一个任务占住唯一的名额，第二个任务在等待时超时
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+	}()
+	results := pool.RunWithTaskTimeouts(time.Second, 1, tasks, []time.Duration{0, 20 * time.Millisecond})
+	if !errors.Is(results[1].Err, pool.ErrNotStarted) || !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Fatalf("等待名额超时的任务错误应为ErrNotStarted和DeadlineExceeded，实际: %v", results[1].Err)
+	}
+	return results[1].Err
+}
+
+func TestIsOverloadError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("[panta] 请求失败: %w", context.DeadlineExceeded), true},
+		{errors.New("[hdr4k] 请求返回状态码: 503"), true},
+		{errors.New("请求失败（最多尝试3次）: HTTP状态码: 429"), true},
+		{errors.New("unexpected status code 502"), true},
+		{errors.New("[susu] 请求返回状态码: 404"), false},
+		{errors.New("dial tcp: lookup dead.example: no such host"), false},
+		{errors.New("解析搜索结果失败"), false},
+		{notStartedErr(t), false},
+	}
+	for _, tc := range cases {
+		if got := isOverloadError(tc.err); got != tc.want {
+			t.Errorf("isOverloadError(%v) = %v，期望 %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+// 一组插件每次搜索都返回普通错误（站点失效）或在等待名额时超时，不应使并发数下降
+func TestConcurrencyIgnoresPermanentlyFailingPlugins(t *testing.T) {
+	c := newTestConcurrencyController(40, 4, 40)
+	waitTimeout := notStartedErr(t)
+	broken := []error{
+		errors.New("dial tcp: lookup dead.example: no such host"),
+		errors.New("[susu] 请求返回状态码: 404"),
+		errors.New("解析搜索结果失败"),
+		errors.New("[panyq] 获取buildId失败"),
+	}
+
+	for cycle := 0; cycle < 10; cycle++ {
+		for search := 0; search < 10; search++ {
+			// 每次搜索：4个失效插件出错，3个插件等待名额超时，3个插件正常返回
+			for _, err := range broken {
+				c.record(err)
+			}
+			for i := 0; i < 3; i++ {
+				c.record(waitTimeout)
+			}
+			for i := 0; i < 3; i++ {
+				c.record(nil)
+			}
+		}
+		c.adjust()
+		if c.current < 40 {
+			t.Fatalf("第%d个周期并发数下降到 %d（%s）", cycle+1, c.current, c.reason)
+		}
+	}
+}
+
+// 超时和限流占比过高时仍然降低并发数，直到下限
+func TestConcurrencyBacksOffOnOverload(t *testing.T) {
+	c := newTestConcurrencyController(40, 4, 40)
+	overload := []error{
+		context.DeadlineExceeded,
+		errors.New("[hdr4k] 请求返回状态码: 429"),
+		errors.New("[fox4k] 请求返回状态码: 502"),
+	}
+
+	previous := c.current
+	for cycle := 0; cycle < 20; cycle++ {
+		for search := 0; search < 10; search++ {
+			for _, err := range overload {
+				c.record(err)
+			}
+			c.record(nil)
+		}
+		c.adjust()
+		if c.current > previous {
+			t.Fatalf("第%d个周期并发数从 %d 提高到 %d", cycle+1, previous, c.current)
+		}
+		previous = c.current
+	}
+	if c.current != 4 {
+		t.Fatalf("持续过载后并发数应降到下限4，实际 %d", c.current)
+	}
+}
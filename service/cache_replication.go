@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/util/cache"
+	"pansou/util/objstore"
+)
+
+// 单次同步或恢复的超时时间
+const cacheReplicationTimeout = 30 * time.Minute
+
+// replicatedFile 已上传文件的状态，大小和修改时间都未变化的文件不重复上传
+type replicatedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// CacheReplicationStats 缓存复制统计
+type CacheReplicationStats struct {
+	Enabled       bool      `json:"enabled"`
+	Bucket        string    `json:"bucket,omitempty"`
+	Prefix        string    `json:"prefix,omitempty"`
+	Files         int       `json:"files"`    // 已同步的文件数
+	Uploaded      int64     `json:"uploaded"` // 累计上传的文件数
+	Deleted       int64     `json:"deleted"`  // 累计删除的远端文件数
+	Restored      int       `json:"restored"` // 启动时恢复的文件数
+	LastSync      time.Time `json:"last_sync,omitempty"`
+	LastSyncError string    `json:"last_sync_error,omitempty"`
+}
+
+// CacheReplicator 将磁盘缓存目录同步到S3兼容的对象存储，冷启动时从对象存储恢复，
+// 使容器被重新调度后仍能保留积累的缓存
+type CacheReplicator struct {
+	client *objstore.S3Client
+	dir    string
+	prefix string
+
+	syncMu sync.Mutex                // 同一时间只执行一次同步
+	synced map[string]replicatedFile // 相对路径 -> 已上传的文件状态
+	listed bool                      // 是否已读取过远端的文件列表
+	dirty  int32                     // 上次同步后缓存写入管理器是否写入过磁盘
+
+	statsMu sync.Mutex
+	stats   CacheReplicationStats
+}
+
+var (
+	globalCacheReplicator *CacheReplicator
+	cacheReplicatorOnce   sync.Once
+)
+
+// GetCacheReplicator 获取全局缓存复制器，未配置对象存储时返回nil
+func GetCacheReplicator() *CacheReplicator {
+	cacheReplicatorOnce.Do(func() {
+		cfg := config.AppConfig
+		if cfg.CacheS3Endpoint == "" || cfg.CacheS3Bucket == "" {
+			return
+		}
+		if err := ValidateCacheReplication(); err != nil {
+			fmt.Printf("[缓存复制] %v\n", err)
+			return
+		}
+		client, err := objstore.NewS3Client(objstore.S3Config{
+			Endpoint:  cfg.CacheS3Endpoint,
+			Region:    cfg.CacheS3Region,
+			Bucket:    cfg.CacheS3Bucket,
+			AccessKey: cfg.CacheS3AccessKey,
+			SecretKey: cfg.CacheS3SecretKey,
+			PathStyle: cfg.CacheS3PathStyle,
+		})
+		if err != nil {
+			fmt.Printf("[缓存复制] 对象存储配置无效: %v\n", err)
+			return
+		}
+		globalCacheReplicator = NewCacheReplicator(client, cfg.CachePath, cfg.CacheS3Prefix)
+	})
+	return globalCacheReplicator
+}
+
+// ValidateCacheReplication 检查缓存复制配置。复制逐个上传磁盘缓存目录中的文件，只支持file存储：
+// bbolt和badger的数据文件在运行期间一直打开并持续写入，直接复制得到的可能是不一致的数据库
+func ValidateCacheReplication() error {
+	cfg := config.AppConfig
+	if cfg.CacheS3Endpoint == "" || cfg.CacheS3Bucket == "" {
+		return nil
+	}
+	if cfg.CacheStore != "file" {
+		return fmt.Errorf("缓存复制只支持CACHE_STORE=file，当前为%s", cfg.CacheStore)
+	}
+	return nil
+}
+
+// NewCacheReplicator 创建缓存复制器，将dir下的文件同步到对象存储的prefix下
+func NewCacheReplicator(client *objstore.S3Client, dir string, prefix string) *CacheReplicator {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &CacheReplicator{
+		client: client,
+		dir:    dir,
+		prefix: prefix,
+		synced: make(map[string]replicatedFile),
+		stats: CacheReplicationStats{
+			Enabled: true,
+			Bucket:  config.AppConfig.CacheS3Bucket,
+			Prefix:  prefix,
+		},
+	}
+}
+
+// MarkDirty 标记磁盘缓存有新的写入，下一个同步周期执行同步。注册为缓存写入管理器的刷新回调
+func (r *CacheReplicator) MarkDirty() {
+	atomic.StoreInt32(&r.dirty, 1)
+}
+
+// Restore 本地缓存目录为空时从对象存储下载全部文件，需在磁盘缓存初始化之前调用。返回恢复的文件数
+func (r *CacheReplicator) Restore() (int, error) {
+	if hasCacheFiles(r.dir) {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheReplicationTimeout)
+	defer cancel()
+
+	objects, err := r.client.ListObjects(ctx, r.prefix)
+	if err != nil {
+		return 0, fmt.Errorf("列出对象失败: %v", err)
+	}
+
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+	r.listed = true
+
+	restored := 0
+	for _, object := range objects {
+		rel := strings.TrimPrefix(object.Key, r.prefix)
+		localPath, ok := r.localPath(rel)
+		if !ok {
+			continue
+		}
+		data, err := r.client.GetObject(ctx, object.Key)
+		if err != nil {
+			return restored, fmt.Errorf("下载 %s 失败: %v", object.Key, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return restored, err
+		}
+		if err := os.WriteFile(localPath, data, 0644); err != nil {
+			return restored, err
+		}
+		if info, err := os.Stat(localPath); err == nil {
+			r.synced[rel] = replicatedFile{size: info.Size(), modTime: info.ModTime()}
+		}
+		restored++
+	}
+
+	r.statsMu.Lock()
+	r.stats.Restored = restored
+	r.stats.Files = len(r.synced)
+	r.statsMu.Unlock()
+	return restored, nil
+}
+
+// Sync 上传新增和修改过的文件，删除本地已不存在的远端文件
+func (r *CacheReplicator) Sync() error {
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+	atomic.StoreInt32(&r.dirty, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheReplicationTimeout)
+	defer cancel()
+
+	// 首次同步时读取远端文件列表，本地已不存在的远端文件随后被删除
+	if !r.listed {
+		objects, err := r.client.ListObjects(ctx, r.prefix)
+		if err != nil {
+			atomic.StoreInt32(&r.dirty, 1)
+			return fmt.Errorf("列出对象失败: %v", err)
+		}
+		for _, object := range objects {
+			if rel := strings.TrimPrefix(object.Key, r.prefix); rel != "" {
+				r.synced[rel] = replicatedFile{size: -1}
+			}
+		}
+		r.listed = true
+	}
+
+	var uploaded, deleted int64
+	current := make(map[string]bool)
+	err := filepath.WalkDir(r.dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && cache.IsQuarantinedDir(d.Name()) {
+			// 被隔离的损坏分片只保留在本地
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() || cache.IsAtomicTempFile(d.Name()) {
+			// 遍历期间被清理任务删除的文件和写入中的临时文件忽略
+			return nil
+		}
+		rel, err := filepath.Rel(r.dir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		current[rel] = true
+
+		state := replicatedFile{size: info.Size(), modTime: info.ModTime()}
+		if prev, ok := r.synced[rel]; ok && prev == state {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		if err := r.client.PutObject(ctx, r.prefix+rel, data); err != nil {
+			return fmt.Errorf("上传 %s 失败: %v", rel, err)
+		}
+		r.synced[rel] = state
+		uploaded++
+		return nil
+	})
+
+	if err == nil {
+		for rel := range r.synced {
+			if current[rel] {
+				continue
+			}
+			if err = r.client.DeleteObject(ctx, r.prefix+rel); err != nil {
+				err = fmt.Errorf("删除 %s 失败: %v", rel, err)
+				break
+			}
+			delete(r.synced, rel)
+			deleted++
+		}
+	}
+
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	r.stats.Uploaded += uploaded
+	r.stats.Deleted += deleted
+	r.stats.Files = len(r.synced)
+	if err != nil {
+		// 同步失败时保持脏标记，下个周期重试
+		atomic.StoreInt32(&r.dirty, 1)
+		r.stats.LastSyncError = err.Error()
+		return err
+	}
+	r.stats.LastSync = time.Now()
+	r.stats.LastSyncError = ""
+	return nil
+}
+
+// Start 启动定期同步：每个周期检查缓存写入管理器是否写入过磁盘，有写入时执行同步
+func (r *CacheReplicator) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	// 首次同步建立已上传文件的状态，恢复的文件不会重复上传
+	r.MarkDirty()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if atomic.LoadInt32(&r.dirty) == 0 {
+				continue
+			}
+			if err := r.Sync(); err != nil {
+				fmt.Printf("[缓存复制] 同步失败: %v\n", err)
+			}
+		}
+	}()
+}
+
+// Stats 获取复制统计
+func (r *CacheReplicator) Stats() CacheReplicationStats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	return r.stats
+}
+
+// localPath 将对象的相对路径转为缓存目录下的本地路径，拒绝跳出缓存目录的路径
+func (r *CacheReplicator) localPath(rel string) (string, bool) {
+	if rel == "" || strings.HasSuffix(rel, "/") {
+		return "", false
+	}
+	cleaned := path.Clean("/" + rel)
+	if cleaned == "/" {
+		return "", false
+	}
+	return filepath.Join(r.dir, filepath.FromSlash(cleaned[1:])), true
+}
+
+// hasCacheFiles 缓存目录中是否已有文件
+func hasCacheFiles(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
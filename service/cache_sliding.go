@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/util"
+)
+
+// 正在后台刷新的缓存键，同一缓存键同时只刷新一次
+var staleRefreshKeys sync.Map
+
+// slideCacheEntry 启用滑动过期（CACHE_SLIDING_TTL）时，命中缓存后将有效期延长为从现在起ttl，
+// 但不超过数据写入后的CACHE_SLIDING_MAX_AGE；数据写入已超过CACHE_REFRESH_AFTER时在后台调用refresh重新搜索并更新缓存，
+// 本次请求仍返回缓存数据。热门关键词因此不会在固定时间集中过期后重新搜索
+func slideCacheEntry(requestID string, cacheKey string, ttl time.Duration, refresh func()) {
+	if !config.AppConfig.CacheSlidingTTL || enhancedTwoLevelCache == nil {
+		return
+	}
+	lastModified, ok := enhancedTwoLevelCache.Touch(cacheKey, ttl, config.AppConfig.CacheSlidingMaxAge)
+	if !ok {
+		return
+	}
+	// 维护模式下只延长有效期，不在后台重新搜索
+	age := time.Since(lastModified)
+	if age < config.AppConfig.CacheRefreshAfter || IsMaintenanceMode() {
+		return
+	}
+	if _, running := staleRefreshKeys.LoadOrStore(cacheKey, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer staleRefreshKeys.Delete(cacheKey)
+		if util.SampledLogEnabled() {
+			fmt.Printf("%s[滑动过期] 缓存已写入 %v，后台刷新: %s\n", util.RequestLogTag(requestID), age.Round(time.Second), cacheKey)
+		}
+		refresh()
+	}()
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/plugin"
+)
+
+// CacheTier 插件结果的缓存分级，决定写入主缓存时的有效期
+type CacheTier string
+
+// 缓存分级
+const (
+	CacheTierFinal    CacheTier = "final"    // 等级1插件的最终结果，使用长有效期
+	CacheTierComplete CacheTier = "complete" // 其他插件的最终结果，使用CACHE_TTL
+	CacheTierPartial  CacheTier = "partial"  // 部分或超时结果，使用短有效期，下次访问时重新搜索
+)
+
+// cacheTierFor 根据是否为最终结果和插件等级确定缓存分级
+func cacheTierFor(isFinal bool, pluginName string) CacheTier {
+	if !isFinal {
+		return CacheTierPartial
+	}
+	if p, ok := plugin.GetPluginByName(pluginName); ok && p.Priority() == 1 {
+		return CacheTierFinal
+	}
+	return CacheTierComplete
+}
+
+// TTL 分级对应的缓存有效期
+func (t CacheTier) TTL() time.Duration {
+	switch t {
+	case CacheTierFinal:
+		return config.AppConfig.CacheTTLFinal
+	case CacheTierPartial:
+		return config.AppConfig.CacheTTLPartial
+	}
+	return time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
+}
+
+// partialCacheEntry 缓存键上仍在后台搜索的插件及其开始时间
+type partialCacheEntry map[string]time.Time
+
+var (
+	partialCacheKeys     = make(map[string]partialCacheEntry)
+	partialCacheKeysLock sync.Mutex
+)
+
+// markCacheTier 记录插件对缓存键的写入：部分结果将插件记为仍在后台搜索，最终结果清除该记录
+func markCacheTier(key string, pluginName string, tier CacheTier) {
+	partialCacheKeysLock.Lock()
+	defer partialCacheKeysLock.Unlock()
+	entry := partialCacheKeys[key]
+	if tier == CacheTierPartial {
+		if entry == nil {
+			entry = make(partialCacheEntry)
+			partialCacheKeys[key] = entry
+		}
+		entry[pluginName] = time.Now()
+		return
+	}
+	if entry != nil {
+		delete(entry, pluginName)
+		if len(entry) == 0 {
+			delete(partialCacheKeys, key)
+		}
+	}
+}
+
+// isPartialCacheKey 缓存键中是否有插件结果仍不完整。超过短有效期的记录视为已失效
+// （插件后台搜索失败时不会再写入最终结果）
+func isPartialCacheKey(key string) bool {
+	partialCacheKeysLock.Lock()
+	defer partialCacheKeysLock.Unlock()
+	entry := partialCacheKeys[key]
+	if entry == nil {
+		return false
+	}
+	cutoff := time.Now().Add(-config.AppConfig.CacheTTLPartial)
+	for name, since := range entry {
+		if since.Before(cutoff) {
+			delete(entry, name)
+		}
+	}
+	if len(entry) == 0 {
+		delete(partialCacheKeys, key)
+		return false
+	}
+	return true
+}
+
+// partialCachePlugins 缓存键中结果仍不完整的插件
+func partialCachePlugins(key string) []string {
+	if !isPartialCacheKey(key) {
+		return nil
+	}
+	partialCacheKeysLock.Lock()
+	defer partialCacheKeysLock.Unlock()
+	names := make([]string, 0, len(partialCacheKeys[key]))
+	for name := range partialCacheKeys[key] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cacheTTLForKey 写入缓存键时使用的有效期：仍有插件结果不完整时使用短有效期
+func cacheTTLForKey(key string, tier CacheTier) time.Duration {
+	if isPartialCacheKey(key) {
+		return CacheTierPartial.TTL()
+	}
+	return tier.TTL()
+}
@@ -0,0 +1,488 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// 频道发现相关限制
+const (
+	maxChannelCandidates       = 2000 // 最多保留的候选频道数
+	maxCandidateKeywords       = 20   // 每个候选频道最多记录的关键词数
+	maxCandidateSources        = 20   // 每个候选频道最多记录的引用来源频道数
+	maxRecentSearchKeywords    = 50   // 最近搜索关键词保留数
+	channelProbeBatchSize      = 10   // 每轮最多探测的候选频道数
+	channelProbeKeywords       = 5    // 每个候选频道探测使用的关键词数
+	channelProbeStaleAfter     = 6 * time.Hour
+	channelDiscoveryQueueSize  = 256
+	channelDiscoverySavePeriod = time.Minute
+)
+
+// ChannelCandidate 候选频道
+type ChannelCandidate struct {
+	Name           string           `json:"name"`
+	Mentions       int64            `json:"mentions"`        // 在搜索结果中被转发或引用的次数
+	SourceChannels map[string]int64 `json:"source_channels"` // 引用该频道的来源频道及次数
+	Keywords       map[string]int64 `json:"keywords"`        // 引用出现时对应的搜索关键词及次数
+	ProbeKeywords  int              `json:"probe_keywords"`  // 最近一次探测使用的关键词数
+	ProbeHits      int              `json:"probe_hits"`      // 最近一次探测中能搜到资源的关键词数
+	ProbeResults   int              `json:"probe_results"`   // 最近一次探测搜到的资源消息数
+	ProbeError     string           `json:"probe_error,omitempty"`
+	LastProbed     time.Time        `json:"last_probed,omitempty"`
+	FirstSeen      time.Time        `json:"first_seen"`
+	LastSeen       time.Time        `json:"last_seen"`
+	Score          float64          `json:"score"`
+}
+
+// channelDiscoveryFile 频道发现数据持久化格式
+type channelDiscoveryFile struct {
+	Candidates map[string]*ChannelCandidate `json:"candidates"`
+	Dismissed  []string                     `json:"dismissed"`
+	Added      []string                     `json:"added"`
+}
+
+// channelObservation 待处理的搜索结果
+type channelObservation struct {
+	keyword string
+	results []model.SearchResult
+}
+
+// ChannelProbeFunc 在指定频道中搜索关键词
+type ChannelProbeFunc func(keyword string, channel string) ([]model.SearchResult, error)
+
+// ChannelDiscovery 频道自动发现：从TG搜索结果的转发来源和频道引用中收集候选频道，
+// 并定期用近期搜索关键词探测候选频道，按能搜到资源的比例评分
+type ChannelDiscovery struct {
+	mu sync.Mutex
+
+	candidates     map[string]*ChannelCandidate
+	dismissed      map[string]bool
+	added          []string
+	recentKeywords []string
+
+	queue     chan channelObservation
+	startOnce sync.Once
+
+	dataFile string
+	dirty    bool
+}
+
+var (
+	globalChannelDiscovery *ChannelDiscovery
+	channelDiscoveryOnce   sync.Once
+)
+
+// GetChannelDiscovery 获取全局频道发现服务
+func GetChannelDiscovery() *ChannelDiscovery {
+	channelDiscoveryOnce.Do(func() {
+		globalChannelDiscovery = NewChannelDiscovery("data/channel_discovery.json")
+	})
+	return globalChannelDiscovery
+}
+
+// NewChannelDiscovery 创建频道发现服务，dataFile为空时不持久化
+func NewChannelDiscovery(dataFile string) *ChannelDiscovery {
+	d := &ChannelDiscovery{
+		candidates: make(map[string]*ChannelCandidate),
+		dismissed:  make(map[string]bool),
+		queue:      make(chan channelObservation, channelDiscoveryQueueSize),
+		dataFile:   dataFile,
+	}
+	if dataFile != "" {
+		d.load()
+	}
+	return d
+}
+
+// StartChannelDiscovery 启动频道发现任务，使用本服务的频道搜索进行探测
+func (s *SearchService) StartChannelDiscovery(interval time.Duration) {
+	GetChannelDiscovery().Start(s.searchChannel, interval)
+}
+
+// Start 恢复已添加的频道并启动结果处理、定期探测和落盘任务（仅首次调用生效）
+func (d *ChannelDiscovery) Start(probe ChannelProbeFunc, interval time.Duration) {
+	d.startOnce.Do(func() {
+		d.mu.Lock()
+		added := append([]string(nil), d.added...)
+		d.mu.Unlock()
+		if restored := config.AddDefaultChannels(added...); len(restored) > 0 {
+			fmt.Printf("[频道发现] 恢复已添加的频道: %s\n", strings.Join(restored, ","))
+		}
+
+		go d.processLoop()
+		go d.probeLoop(probe, interval)
+		if d.dataFile != "" {
+			go d.saveLoop()
+		}
+	})
+}
+
+// Observe 记录一次TG搜索的结果（非阻塞，队列已满时丢弃）
+func (d *ChannelDiscovery) Observe(keyword string, results []model.SearchResult) {
+	var withMentions []model.SearchResult
+	for _, r := range results {
+		if len(r.MentionedChannels) > 0 {
+			withMentions = append(withMentions, r)
+		}
+	}
+
+	select {
+	case d.queue <- channelObservation{keyword: keyword, results: withMentions}:
+	default:
+	}
+}
+
+// processLoop 处理搜索结果中的频道引用
+func (d *ChannelDiscovery) processLoop() {
+	for obs := range d.queue {
+		d.record(obs)
+	}
+}
+
+// record 累计候选频道的引用统计
+func (d *ChannelDiscovery) record(obs channelObservation) {
+	now := time.Now()
+	configured := make(map[string]bool)
+	for _, ch := range config.GetDefaultChannels() {
+		configured[strings.ToLower(ch)] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rememberKeyword(obs.keyword)
+
+	for _, r := range obs.results {
+		source := strings.ToLower(r.Channel)
+		for _, name := range r.MentionedChannels {
+			if configured[name] || d.dismissed[name] {
+				continue
+			}
+			c, ok := d.candidates[name]
+			if !ok {
+				c = &ChannelCandidate{
+					Name:           name,
+					SourceChannels: make(map[string]int64),
+					Keywords:       make(map[string]int64),
+					FirstSeen:      now,
+				}
+				d.candidates[name] = c
+			}
+			c.Mentions++
+			c.LastSeen = now
+			if _, ok := c.SourceChannels[source]; ok || len(c.SourceChannels) < maxCandidateSources {
+				c.SourceChannels[source]++
+			}
+			if _, ok := c.Keywords[obs.keyword]; ok || len(c.Keywords) < maxCandidateKeywords {
+				c.Keywords[obs.keyword]++
+			}
+			c.Score = candidateScore(c)
+			d.dirty = true
+		}
+	}
+
+	d.pruneCandidates()
+}
+
+// rememberKeyword 记录最近的搜索关键词（调用方需持有锁）
+func (d *ChannelDiscovery) rememberKeyword(keyword string) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return
+	}
+	for i, kw := range d.recentKeywords {
+		if kw == keyword {
+			d.recentKeywords = append(d.recentKeywords[:i], d.recentKeywords[i+1:]...)
+			break
+		}
+	}
+	d.recentKeywords = append(d.recentKeywords, keyword)
+	if len(d.recentKeywords) > maxRecentSearchKeywords {
+		d.recentKeywords = d.recentKeywords[len(d.recentKeywords)-maxRecentSearchKeywords:]
+	}
+}
+
+// pruneCandidates 候选频道过多时淘汰得分最低的（调用方需持有锁）
+func (d *ChannelDiscovery) pruneCandidates() {
+	if len(d.candidates) <= maxChannelCandidates {
+		return
+	}
+	list := d.sortedCandidatesLocked()
+	for _, c := range list[maxChannelCandidates:] {
+		delete(d.candidates, c.Name)
+	}
+}
+
+// candidateScore 计算候选频道得分：探测命中率权重最高，其次是引用来源和关键词的多样性
+func candidateScore(c *ChannelCandidate) float64 {
+	score := math.Log2(1+float64(c.Mentions))*2 +
+		float64(len(c.SourceChannels))*2 +
+		float64(len(c.Keywords))
+	if c.ProbeKeywords > 0 {
+		score += float64(c.ProbeHits) / float64(c.ProbeKeywords) * 20
+	}
+	return math.Round(score*100) / 100
+}
+
+// probeLoop 定期探测候选频道
+func (d *ChannelDiscovery) probeLoop(probe ChannelProbeFunc, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		d.probeCandidates(probe)
+	}
+}
+
+// probeCandidates 选取得分最高且未近期探测的候选频道，用近期搜索关键词在其中搜索
+func (d *ChannelDiscovery) probeCandidates(probe ChannelProbeFunc) {
+	type probeTask struct {
+		name     string
+		keywords []string
+	}
+
+	d.mu.Lock()
+	var tasks []probeTask
+	for _, c := range d.sortedCandidatesLocked() {
+		if len(tasks) >= channelProbeBatchSize {
+			break
+		}
+		if time.Since(c.LastProbed) < channelProbeStaleAfter {
+			continue
+		}
+		tasks = append(tasks, probeTask{name: c.Name, keywords: d.probeKeywordsLocked(c)})
+	}
+	d.mu.Unlock()
+
+	for _, task := range tasks {
+		hits, total := 0, 0
+		var probeErr error
+		for _, kw := range task.keywords {
+			results, err := probe(kw, task.name)
+			if err != nil {
+				probeErr = err
+				continue
+			}
+			if len(results) > 0 {
+				hits++
+				total += len(results)
+			}
+		}
+
+		d.mu.Lock()
+		if c, ok := d.candidates[task.name]; ok {
+			c.ProbeKeywords = len(task.keywords)
+			c.ProbeHits = hits
+			c.ProbeResults = total
+			c.ProbeError = ""
+			if probeErr != nil && hits == 0 {
+				c.ProbeError = probeErr.Error()
+			}
+			c.LastProbed = time.Now()
+			c.Score = candidateScore(c)
+			d.dirty = true
+		}
+		d.mu.Unlock()
+	}
+}
+
+// probeKeywordsLocked 选取探测关键词：优先使用引用出现时的关键词，不足时补充最近搜索的关键词（调用方需持有锁）
+func (d *ChannelDiscovery) probeKeywordsLocked(c *ChannelCandidate) []string {
+	type kwCount struct {
+		kw    string
+		count int64
+	}
+	counts := make([]kwCount, 0, len(c.Keywords))
+	for kw, n := range c.Keywords {
+		counts = append(counts, kwCount{kw, n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].count != counts[j].count {
+			return counts[i].count > counts[j].count
+		}
+		return counts[i].kw < counts[j].kw
+	})
+
+	seen := make(map[string]bool)
+	keywords := make([]string, 0, channelProbeKeywords)
+	for _, kc := range counts {
+		if len(keywords) >= channelProbeKeywords {
+			return keywords
+		}
+		seen[kc.kw] = true
+		keywords = append(keywords, kc.kw)
+	}
+	for i := len(d.recentKeywords) - 1; i >= 0 && len(keywords) < channelProbeKeywords; i-- {
+		if kw := d.recentKeywords[i]; !seen[kw] {
+			seen[kw] = true
+			keywords = append(keywords, kw)
+		}
+	}
+	return keywords
+}
+
+// sortedCandidatesLocked 按得分倒序排列候选频道（调用方需持有锁）
+func (d *ChannelDiscovery) sortedCandidatesLocked() []*ChannelCandidate {
+	list := make([]*ChannelCandidate, 0, len(d.candidates))
+	for _, c := range d.candidates {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// TopCandidates 获取得分最高的候选频道
+func (d *ChannelDiscovery) TopCandidates(limit int) []ChannelCandidate {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	list := d.sortedCandidatesLocked()
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	out := make([]ChannelCandidate, 0, len(list))
+	for _, c := range list {
+		cp := *c
+		cp.SourceChannels = copyCounts(c.SourceChannels)
+		cp.Keywords = copyCounts(c.Keywords)
+		out = append(out, cp)
+	}
+	return out
+}
+
+// copyCounts 复制计数映射
+func copyCounts(m map[string]int64) map[string]int64 {
+	cp := make(map[string]int64, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+// Add 将候选频道加入默认频道列表，返回更新后的频道列表
+func (d *ChannelDiscovery) Add(name string) ([]string, error) {
+	name = util.NormalizeChannelName(name)
+	if name == "" {
+		return nil, fmt.Errorf("无效的频道名")
+	}
+
+	config.AddDefaultChannels(name)
+
+	d.mu.Lock()
+	delete(d.candidates, name)
+	delete(d.dismissed, name)
+	exists := false
+	for _, ch := range d.added {
+		if ch == name {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		d.added = append(d.added, name)
+	}
+	d.dirty = true
+	d.mu.Unlock()
+
+	return config.GetDefaultChannels(), nil
+}
+
+// Dismiss 忽略候选频道，之后不再收集其引用
+func (d *ChannelDiscovery) Dismiss(name string) error {
+	name = util.NormalizeChannelName(name)
+	if name == "" {
+		return fmt.Errorf("无效的频道名")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.candidates, name)
+	d.dismissed[name] = true
+	d.dirty = true
+	return nil
+}
+
+// Flush 将频道发现数据写入磁盘
+func (d *ChannelDiscovery) Flush() error {
+	if d.dataFile == "" {
+		return nil
+	}
+
+	d.mu.Lock()
+	if !d.dirty {
+		d.mu.Unlock()
+		return nil
+	}
+	dismissed := make([]string, 0, len(d.dismissed))
+	for name := range d.dismissed {
+		dismissed = append(dismissed, name)
+	}
+	sort.Strings(dismissed)
+	data, err := jsonutil.MarshalIndent(channelDiscoveryFile{
+		Candidates: d.candidates,
+		Dismissed:  dismissed,
+		Added:      d.added,
+	}, "", "  ")
+	d.dirty = false
+	d.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("频道发现数据序列化失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(d.dataFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.dataFile, data, 0644)
+}
+
+// load 从磁盘加载频道发现数据
+func (d *ChannelDiscovery) load() {
+	data, err := os.ReadFile(d.dataFile)
+	if err != nil {
+		return
+	}
+	var stored channelDiscoveryFile
+	if err := jsonutil.Unmarshal(data, &stored); err != nil {
+		fmt.Printf("[频道发现] 加载失败: %v\n", err)
+		return
+	}
+	for name, c := range stored.Candidates {
+		if c.SourceChannels == nil {
+			c.SourceChannels = make(map[string]int64)
+		}
+		if c.Keywords == nil {
+			c.Keywords = make(map[string]int64)
+		}
+		d.candidates[name] = c
+	}
+	for _, name := range stored.Dismissed {
+		d.dismissed[name] = true
+	}
+	d.added = stored.Added
+}
+
+// saveLoop 定期落盘
+func (d *ChannelDiscovery) saveLoop() {
+	ticker := time.NewTicker(channelDiscoverySavePeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := d.Flush(); err != nil {
+			fmt.Printf("[频道发现] 保存失败: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+)
+
+// ChannelParser 频道专用的链接-标题解析器，用于以表格、自定义分隔符等非常规格式发布资源的频道
+type ChannelParser interface {
+	// Name 解析器名称，用于在CHANNEL_PARSERS中绑定频道
+	Name() string
+
+	// ParseLinkTitles 解析消息中链接与标题的对应关系，返回空映射时使用通用解析
+	ParseLinkTitles(result model.SearchResult) map[string]string
+}
+
+var (
+	channelParsers       = make(map[string]ChannelParser) // 解析器名称 -> 解析器
+	channelParserBinding = make(map[string]string)        // 频道（小写） -> 解析器名称
+	channelParsersLock   sync.RWMutex
+)
+
+func init() {
+	RegisterChannelParser(tableChannelParser{})
+	RegisterChannelParser(separatorChannelParser{})
+}
+
+// RegisterChannelParser 注册频道解析器，同名解析器会被覆盖
+func RegisterChannelParser(parser ChannelParser) {
+	if parser == nil || parser.Name() == "" {
+		return
+	}
+	channelParsersLock.Lock()
+	defer channelParsersLock.Unlock()
+	channelParsers[strings.ToLower(parser.Name())] = parser
+}
+
+// BindChannelParser 将频道绑定到指定名称的解析器，覆盖CHANNEL_PARSERS中的配置；parserName为空时解除绑定
+func BindChannelParser(channel string, parserName string) {
+	channelParsersLock.Lock()
+	defer channelParsersLock.Unlock()
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if parserName == "" {
+		delete(channelParserBinding, channel)
+		return
+	}
+	channelParserBinding[channel] = strings.ToLower(parserName)
+}
+
+// channelParserFor 获取频道绑定的解析器，未绑定或解析器不存在时返回nil
+func channelParserFor(channel string) ChannelParser {
+	if channel == "" {
+		return nil
+	}
+	channel = strings.ToLower(channel)
+
+	channelParsersLock.RLock()
+	defer channelParsersLock.RUnlock()
+	name, ok := channelParserBinding[channel]
+	if !ok && config.AppConfig != nil {
+		name, ok = config.AppConfig.ChannelParsers[channel]
+	}
+	if !ok {
+		return nil
+	}
+	return channelParsers[name]
+}
+
+// tableChannelParser 表格格式：每行一条资源，单元格以|、｜或制表符分隔，如"片名 | 夸克 | 链接"
+type tableChannelParser struct{}
+
+// Name 解析器名称
+func (tableChannelParser) Name() string { return "table" }
+
+// ParseLinkTitles 以同一行中第一个不含链接、不是网盘名称的单元格作为标题，表头和分隔行被忽略
+func (tableChannelParser) ParseLinkTitles(result model.SearchResult) map[string]string {
+	linkTitleMap := make(map[string]string)
+	for _, line := range strings.Split(result.Content, "\n") {
+		links := util.GenericLinkPattern.FindAllString(line, -1)
+		if len(links) == 0 {
+			continue
+		}
+		cells := strings.FieldsFunc(line, func(r rune) bool {
+			return r == '|' || r == '｜' || r == '\t'
+		})
+		if len(cells) < 2 {
+			continue
+		}
+		var title string
+		for _, cell := range cells {
+			if util.GenericLinkPattern.MatchString(cell) || isLinkPrefix(cell) || isCloudTypeLabel(cell) {
+				continue
+			}
+			if title = cleanTitle(cell); title != "" {
+				break
+			}
+		}
+		if title == "" {
+			continue
+		}
+		for _, link := range links {
+			linkTitleMap[link] = title
+		}
+	}
+	return linkTitleMap
+}
+
+// separatorChannelParser 自定义分隔符格式：资源之间以表情或符号分隔，链接前的文本即为标题，
+// 如"🎬 片名A 👉 链接 🎬 片名B 👉 链接"，有无换行均可
+type separatorChannelParser struct{}
+
+// Name 解析器名称
+func (separatorChannelParser) Name() string { return "separator" }
+
+// ParseLinkTitles 取每个链接与上一个链接之间的文本作为标题
+func (separatorChannelParser) ParseLinkTitles(result model.SearchResult) map[string]string {
+	linkTitleMap := make(map[string]string)
+	content := result.Content
+	prevEnd := 0
+	for _, loc := range util.GenericLinkPattern.FindAllStringIndex(content, -1) {
+		segment := content[prevEnd:loc[0]]
+		link := content[loc[0]:loc[1]]
+		prevEnd = loc[1]
+
+		// 标题是链接前以换行或表情分隔的最后一段非空文本，并去掉"链接："等标签
+		pieces := strings.FieldsFunc(segment, isSeparatorRune)
+		for i := len(pieces) - 1; i >= 0; i-- {
+			text := strings.TrimRight(strings.TrimSpace(pieces[i]), "：:")
+			for _, prefix := range []string{"资源地址", "网盘地址", "链接", "地址"} {
+				text = strings.TrimSpace(strings.TrimSuffix(text, prefix))
+			}
+			if title := cleanTitle(text); title != "" && !isCloudTypeLabel(title) {
+				linkTitleMap[link] = title
+				break
+			}
+		}
+	}
+	return linkTitleMap
+}
+
+// isSeparatorRune 是否为分隔资源的字符：换行、表情符号及其变体选择符和连接符
+func isSeparatorRune(r rune) bool {
+	return r == '\n' || r == '\uFE0F' || r == '\u200D' || unicode.In(r, unicode.So, unicode.Sk)
+}
+
+// isCloudTypeLabel 是否为网盘名称标签（如"夸克"、"百度网盘"），表格中这类单元格不作为标题
+func isCloudTypeLabel(text string) bool {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(text, "网盘")
+	text = strings.TrimSuffix(text, "云盘")
+	switch strings.ToLower(text) {
+	case "百度", "阿里", "夸克", "天翼", "uc", "移动", "115", "123", "迅雷", "pikpak", "磁力", "电驴":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	jsonutil "pansou/util/json"
+)
+
+// 每一代跳转映射的最大条目数，超过后轮换（旧一代仍可查询，再次命中时提升）
+const maxLinkTargetsPerGeneration = 50000
+
+// 点击统计落盘间隔
+const clickStatsSaveInterval = time.Minute
+
+// 点击统计保留的最大链接数和关键词数，超过后淘汰最久未点击的链接和点击最少的关键词
+const (
+	maxClickStatsLinks    = 50000
+	maxClickStatsKeywords = 10000
+)
+
+// 超过该时长未被点击的链接统计在落盘时清除
+const clickStatsRetention = 90 * 24 * time.Hour
+
+// linkTarget 跳转目标
+type linkTarget struct {
+	URL     string
+	Type    string
+	Keyword string
+	Source  string
+}
+
+// LinkClickStats 单个链接的点击统计
+type LinkClickStats struct {
+	LinkID    string    `json:"link_id"`
+	URL       string    `json:"url"`
+	Type      string    `json:"type"`
+	Keyword   string    `json:"keyword"`
+	Source    string    `json:"source"`
+	Clicks    int64     `json:"clicks"`
+	LastClick time.Time `json:"last_click"`
+}
+
+// ClickCount 按维度聚合的点击数
+type ClickCount struct {
+	Name   string `json:"name"`
+	Clicks int64  `json:"clicks"`
+}
+
+// clickStatsFile 点击统计持久化格式
+type clickStatsFile struct {
+	Links    map[string]*LinkClickStats `json:"links"`
+	Keywords map[string]int64           `json:"keywords"`
+	Sources  map[string]int64           `json:"sources"`
+}
+
+// ClickService 链接跳转与点击统计服务
+type ClickService struct {
+	mu sync.RWMutex
+
+	// 跳转映射（两代轮换，控制内存占用）
+	current  map[string]linkTarget
+	previous map[string]linkTarget
+
+	// 点击统计
+	links    map[string]*LinkClickStats
+	keywords map[string]int64
+	sources  map[string]int64
+
+	dataFile string
+	dirty    bool
+}
+
+var (
+	globalClickService *ClickService
+	clickServiceOnce   sync.Once
+)
+
+// GetClickService 获取全局点击统计服务
+func GetClickService() *ClickService {
+	clickServiceOnce.Do(func() {
+		globalClickService = NewClickService(filepath.Join(config.AppConfig.CachePath, "link_clicks.json"))
+	})
+	return globalClickService
+}
+
+// NewClickService 创建点击统计服务，dataFile为空时不持久化
+func NewClickService(dataFile string) *ClickService {
+	s := &ClickService{
+		current:  make(map[string]linkTarget),
+		previous: make(map[string]linkTarget),
+		links:    make(map[string]*LinkClickStats),
+		keywords: make(map[string]int64),
+		sources:  make(map[string]int64),
+		dataFile: dataFile,
+	}
+
+	if dataFile != "" {
+		s.load()
+		go s.saveLoop()
+	}
+
+	return s
+}
+
+// GenerateLinkID 根据链接URL生成稳定的跳转ID
+func GenerateLinkID(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:6])
+}
+
+// RegisterMergedLinks 为合并链接分配跳转ID并登记跳转目标
+func (s *ClickService) RegisterMergedLinks(mergedLinks model.MergedLinks, keyword string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for linkType, links := range mergedLinks {
+		for i := range links {
+			id := GenerateLinkID(links[i].URL)
+			links[i].LinkID = id
+			s.storeTarget(id, linkTarget{
+				URL:     links[i].URL,
+				Type:    linkType,
+				Keyword: keyword,
+				Source:  links[i].Source,
+			})
+		}
+	}
+}
+
+// RegisterRedirect 登记单个链接的跳转目标，跳转ID由链接URL生成，target为实际跳转地址（可附带提取码）
+func (s *ClickService) RegisterRedirect(url string, target string, linkType string, keyword string, source string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := GenerateLinkID(url)
+	s.storeTarget(id, linkTarget{
+		URL:     target,
+		Type:    linkType,
+		Keyword: keyword,
+		Source:  source,
+	})
+	return id
+}
+
+// storeTarget 登记跳转目标（调用方需持有锁）
+func (s *ClickService) storeTarget(id string, target linkTarget) {
+	if len(s.current) >= maxLinkTargetsPerGeneration {
+		s.previous = s.current
+		s.current = make(map[string]linkTarget)
+	}
+	s.current[id] = target
+}
+
+// Resolve 查询跳转目标并记录点击，不存在时返回false
+func (s *ClickService) Resolve(linkID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.current[linkID]
+	if !ok {
+		if target, ok = s.previous[linkID]; ok {
+			// 再次访问的旧链接提升到当前代
+			s.storeTarget(linkID, target)
+		} else if stats, exists := s.links[linkID]; exists {
+			// 已有点击记录的链接在重启后仍可跳转
+			target = linkTarget{URL: stats.URL, Type: stats.Type, Keyword: stats.Keyword, Source: stats.Source}
+			ok = true
+		}
+	}
+	if !ok {
+		return "", false
+	}
+
+	stats, exists := s.links[linkID]
+	if !exists {
+		stats = &LinkClickStats{LinkID: linkID}
+		s.links[linkID] = stats
+	}
+	stats.URL = target.URL
+	stats.Type = target.Type
+	stats.Keyword = target.Keyword
+	stats.Source = target.Source
+	stats.Clicks++
+	stats.LastClick = time.Now()
+
+	if target.Keyword != "" {
+		s.keywords[target.Keyword]++
+	}
+	if target.Source != "" {
+		s.sources[target.Source]++
+	}
+	s.dirty = true
+	if len(s.links) > maxClickStatsLinks || len(s.keywords) > maxClickStatsKeywords {
+		s.prune(time.Now())
+	}
+
+	return target.URL, true
+}
+
+// GetLinkClicks 获取指定URL的点击次数
+func (s *ClickService) GetLinkClicks(url string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if stats, ok := s.links[GenerateLinkID(url)]; ok {
+		return stats.Clicks
+	}
+	return 0
+}
+
+// TopLinks 获取点击最多的链接
+func (s *ClickService) TopLinks(limit int) []LinkClickStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]LinkClickStats, 0, len(s.links))
+	for _, stats := range s.links {
+		list = append(list, *stats)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Clicks != list[j].Clicks {
+			return list[i].Clicks > list[j].Clicks
+		}
+		return list[i].LastClick.After(list[j].LastClick)
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// TopKeywords 获取点击最多的关键词
+func (s *ClickService) TopKeywords(limit int) []ClickCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return topCounts(s.keywords, limit)
+}
+
+// TopSources 获取点击最多的来源
+func (s *ClickService) TopSources(limit int) []ClickCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return topCounts(s.sources, limit)
+}
+
+// topCounts 按点击数倒序排列
+func topCounts(counts map[string]int64, limit int) []ClickCount {
+	list := make([]ClickCount, 0, len(counts))
+	for name, clicks := range counts {
+		list = append(list, ClickCount{Name: name, Clicks: clicks})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Clicks != list[j].Clicks {
+			return list[i].Clicks > list[j].Clicks
+		}
+		return list[i].Name < list[j].Name
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// Flush 将点击统计写入磁盘
+func (s *ClickService) Flush() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	s.prune(time.Now())
+	data, err := jsonutil.MarshalIndent(clickStatsFile{
+		Links:    s.links,
+		Keywords: s.keywords,
+		Sources:  s.sources,
+	}, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("点击统计序列化失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.dataFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.dataFile, data, 0644)
+}
+
+// load 从磁盘加载点击统计
+func (s *ClickService) load() {
+	data, err := os.ReadFile(s.dataFile)
+	if err != nil {
+		return
+	}
+	var stored clickStatsFile
+	if err := jsonutil.Unmarshal(data, &stored); err != nil {
+		fmt.Printf("[点击统计] 加载失败: %v\n", err)
+		return
+	}
+	if stored.Links != nil {
+		s.links = stored.Links
+	}
+	if stored.Keywords != nil {
+		s.keywords = stored.Keywords
+	}
+	if stored.Sources != nil {
+		s.sources = stored.Sources
+	}
+	s.prune(time.Now())
+}
+
+// prune 清除过期的链接统计，链接数和关键词数超过上限时淘汰到上限的90%，留出余量避免每次点击都淘汰（调用方需持有锁）
+func (s *ClickService) prune(now time.Time) {
+	for id, stats := range s.links {
+		if now.Sub(stats.LastClick) > clickStatsRetention {
+			delete(s.links, id)
+		}
+	}
+	if len(s.links) > maxClickStatsLinks {
+		ids := make([]string, 0, len(s.links))
+		for id := range s.links {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return s.links[ids[i]].LastClick.Before(s.links[ids[j]].LastClick)
+		})
+		for _, id := range ids[:len(ids)-maxClickStatsLinks*9/10] {
+			delete(s.links, id)
+		}
+	}
+	if len(s.keywords) > maxClickStatsKeywords {
+		for _, c := range topCounts(s.keywords, 0)[maxClickStatsKeywords*9/10:] {
+			delete(s.keywords, c.Name)
+		}
+	}
+}
+
+// saveLoop 定期落盘
+func (s *ClickService) saveLoop() {
+	ticker := time.NewTicker(clickStatsSaveInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.Flush(); err != nil {
+			fmt.Printf("[点击统计] 保存失败: %v\n", err)
+		}
+	}
+}
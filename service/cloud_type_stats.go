@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"pansou/model"
+	"pansou/util"
+)
+
+// 链接类型统计按小时分桶，最多保留的小时数
+const cloudTypeStatsHours = 24
+
+// CloudTypeCount 链接类型及其在统计窗口内返回的链接数
+type CloudTypeCount struct {
+	util.CloudType
+	Count int64 `json:"count"`
+}
+
+// cloudTypeBucket 一小时内返回的各类型链接数
+type cloudTypeBucket struct {
+	hour   int64 // Unix时间的小时数
+	counts map[string]int64
+}
+
+var (
+	cloudTypeBuckets     [cloudTypeStatsHours]cloudTypeBucket
+	cloudTypeBucketsLock sync.Mutex
+)
+
+// recordResponseCloudTypes 统计搜索响应中返回的各类型链接数：按合并链接、扁平列表或Results中的链接统计
+func recordResponseCloudTypes(response model.SearchResponse) {
+	counts := make(map[string]int64)
+	switch {
+	case len(response.MergedByType) > 0:
+		for cloudType, links := range response.MergedByType {
+			counts[cloudType] += int64(len(links))
+		}
+	case len(response.Links) > 0:
+		for _, link := range response.Links {
+			counts[link.Type]++
+		}
+	default:
+		for _, result := range response.Results {
+			for _, link := range result.Links {
+				counts[link.Type]++
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	hour := time.Now().Unix() / 3600
+	cloudTypeBucketsLock.Lock()
+	defer cloudTypeBucketsLock.Unlock()
+	bucket := &cloudTypeBuckets[hour%cloudTypeStatsHours]
+	if bucket.hour != hour || bucket.counts == nil {
+		bucket.hour = hour
+		bucket.counts = make(map[string]int64)
+	}
+	for cloudType, count := range counts {
+		bucket.counts[cloudType] += count
+	}
+}
+
+// GetCloudTypeCounts 获取支持的链接类型，以及最近hours小时（含当前小时，最多24）内搜索响应中返回的各类型链接数。
+// 按支持的类型顺序排列，统计从服务启动开始，重启后清零
+func GetCloudTypeCounts(hours int) []CloudTypeCount {
+	if hours <= 0 || hours > cloudTypeStatsHours {
+		hours = cloudTypeStatsHours
+	}
+	since := time.Now().Unix()/3600 - int64(hours) + 1
+
+	totals := make(map[string]int64)
+	cloudTypeBucketsLock.Lock()
+	for _, bucket := range cloudTypeBuckets {
+		if bucket.hour < since {
+			continue
+		}
+		for cloudType, count := range bucket.counts {
+			totals[cloudType] += count
+		}
+	}
+	cloudTypeBucketsLock.Unlock()
+
+	types := util.CloudTypes()
+	result := make([]CloudTypeCount, 0, len(types))
+	for _, t := range types {
+		result = append(result, CloudTypeCount{CloudType: t, Count: totals[t.Type]})
+	}
+	return result
+}
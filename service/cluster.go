@@ -0,0 +1,285 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util"
+	"pansou/util/cache"
+	jsonutil "pansou/util/json"
+)
+
+// 集群角色
+const (
+	ClusterRoleCoordinator = "coordinator"
+	ClusterRoleWorker      = "worker"
+)
+
+// ClusterSecretHeader 节点间请求携带共享密钥的请求头
+const ClusterSecretHeader = "X-Cluster-Secret"
+
+// ClusterSearchPath 工作节点的插件搜索接口路径
+const ClusterSearchPath = "/api/cluster/search"
+
+// 工作节点请求在插件超时之外额外等待的时间（网络传输和结果序列化）
+const clusterRequestSlack = 5 * time.Second
+
+// ClusterWorkerStats 工作节点的请求统计
+type ClusterWorkerStats struct {
+	URL           string    `json:"url"`
+	Requests      int64     `json:"requests"`
+	Failures      int64     `json:"failures"`                  // 失败次数，失败时该节点分到的插件改由协调节点执行
+	LastLatencyMs int64     `json:"last_latency_ms"`           // 最近一次成功请求的耗时
+	LastError     string    `json:"last_error,omitempty"`      // 最近一次失败的原因
+	LastErrorTime time.Time `json:"last_error_time,omitempty"` // 最近一次失败的时间
+}
+
+// ClusterCoordinator 协调节点：将插件按名称哈希分配给工作节点，
+// 同一插件总是分给同一节点，使工作节点的插件缓存和出站IP保持稳定
+type ClusterCoordinator struct {
+	workers []string
+	secret  string
+	client  *http.Client
+
+	mu    sync.Mutex
+	stats map[string]*ClusterWorkerStats
+}
+
+var (
+	globalClusterCoordinator *ClusterCoordinator
+	clusterCoordinatorOnce   sync.Once
+)
+
+// GetClusterCoordinator 获取协调节点，不是协调节点或未配置工作节点时返回nil
+func GetClusterCoordinator() *ClusterCoordinator {
+	clusterCoordinatorOnce.Do(func() {
+		if config.AppConfig.ClusterRole != ClusterRoleCoordinator {
+			return
+		}
+		if len(config.AppConfig.ClusterWorkers) == 0 {
+			fmt.Println("[集群] 协调节点未配置工作节点（CLUSTER_WORKERS），插件在本节点执行")
+			return
+		}
+		globalClusterCoordinator = NewClusterCoordinator(config.AppConfig.ClusterWorkers, config.AppConfig.ClusterSecret,
+			config.AppConfig.PluginTimeout+clusterRequestSlack)
+		fmt.Printf("[集群] 协调节点已启用，工作节点: %s\n", strings.Join(config.AppConfig.ClusterWorkers, ", "))
+	})
+	return globalClusterCoordinator
+}
+
+// NewClusterCoordinator 创建协调节点
+func NewClusterCoordinator(workers []string, secret string, timeout time.Duration) *ClusterCoordinator {
+	c := &ClusterCoordinator{
+		workers: workers,
+		secret:  secret,
+		client:  &http.Client{Timeout: timeout},
+		stats:   make(map[string]*ClusterWorkerStats, len(workers)),
+	}
+	for _, worker := range workers {
+		c.stats[worker] = &ClusterWorkerStats{URL: worker}
+	}
+	return c
+}
+
+// Partition 将插件名分配给工作节点
+func (c *ClusterCoordinator) Partition(plugins []string) map[string][]string {
+	assignments := make(map[string][]string)
+	for _, name := range plugins {
+		h := fnv.New32a()
+		h.Write([]byte(strings.ToLower(name)))
+		worker := c.workers[int(h.Sum32()%uint32(len(c.workers)))]
+		assignments[worker] = append(assignments[worker], name)
+	}
+	return assignments
+}
+
+// Search 请求工作节点执行插件搜索，返回结果和工作节点上结果仍不完整的插件
+func (c *ClusterCoordinator) Search(ctx context.Context, worker string, req model.ClusterSearchRequest) (model.ClusterSearchResponse, error) {
+	start := time.Now()
+	response, err := c.search(ctx, worker, req)
+
+	c.mu.Lock()
+	stats := c.stats[worker]
+	stats.Requests++
+	if err != nil {
+		stats.Failures++
+		stats.LastError = err.Error()
+		stats.LastErrorTime = time.Now()
+	} else {
+		stats.LastLatencyMs = time.Since(start).Milliseconds()
+	}
+	c.mu.Unlock()
+	return response, err
+}
+
+// search 发送插件搜索请求并解析结果
+func (c *ClusterCoordinator) search(ctx context.Context, worker string, req model.ClusterSearchRequest) (model.ClusterSearchResponse, error) {
+	body, err := jsonutil.Marshal(req)
+	if err != nil {
+		return model.ClusterSearchResponse{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, worker+ClusterSearchPath, bytes.NewReader(body))
+	if err != nil {
+		return model.ClusterSearchResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		httpReq.Header.Set(ClusterSecretHeader, c.secret)
+	}
+	if requestID := util.RequestIDFromContext(ctx); requestID != "" {
+		httpReq.Header.Set(util.RequestIDHeader, requestID)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return model.ClusterSearchResponse{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return model.ClusterSearchResponse{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return model.ClusterSearchResponse{}, fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Code    int                         `json:"code"`
+		Message string                      `json:"message"`
+		Data    model.ClusterSearchResponse `json:"data"`
+	}
+	if err := jsonutil.Unmarshal(data, &response); err != nil {
+		return model.ClusterSearchResponse{}, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if response.Code != 0 {
+		return model.ClusterSearchResponse{}, fmt.Errorf("工作节点返回错误: %s", response.Message)
+	}
+	return response.Data, nil
+}
+
+// Stats 获取各工作节点的请求统计
+func (c *ClusterCoordinator) Stats() []ClusterWorkerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make([]ClusterWorkerStats, 0, len(c.stats))
+	for _, s := range c.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].URL < stats[j].URL })
+	return stats
+}
+
+// searchPluginsOnCluster 将插件分配给工作节点并行搜索，请求失败的工作节点分到的插件改在本节点执行。
+// 工作节点上结果仍不完整的插件在本节点的缓存键上同样记为不完整，缓存使用短有效期，下次访问时重新向工作节点获取
+func (s *SearchService) searchPluginsOnCluster(coordinator *ClusterCoordinator, requestID string, keyword string, plugins []plugin.AsyncSearchPlugin, refresh model.RefreshLevel, concurrency int, cacheKey string, ext map[string]interface{}) []model.SearchResult {
+	byName := make(map[string]plugin.AsyncSearchPlugin, len(plugins))
+	names := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		byName[p.Name()] = p
+		names = append(names, p.Name())
+	}
+
+	ctx := util.WithRequestID(context.Background(), requestID)
+	timing := searchTimingFor(requestID)
+	var (
+		mu       sync.Mutex
+		results  []model.SearchResult
+		fallback []plugin.AsyncSearchPlugin
+		wg       sync.WaitGroup
+	)
+	for worker, assigned := range coordinator.Partition(names) {
+		wg.Add(1)
+		go func(worker string, assigned []string) {
+			defer wg.Done()
+			start := time.Now()
+			response, err := coordinator.Search(ctx, worker, model.ClusterSearchRequest{
+				Keyword: keyword,
+				Plugins: assigned,
+				Refresh: refresh.RefreshesPlugins(),
+				Ext:     ext,
+			})
+			workerResults := response.Results
+			if timing != nil {
+				workerTiming := model.SourceTiming{
+					Type:      "worker",
+					Name:      worker,
+					Keyword:   keyword,
+					ElapsedMs: time.Since(start).Milliseconds(),
+					TimedOut:  isTimeoutError(err),
+					Results:   len(workerResults),
+				}
+				if err != nil && !workerTiming.TimedOut {
+					workerTiming.Error = err.Error()
+				}
+				timing.recordSource(workerTiming)
+				timing.recordResultProvenance(workerResults, model.ProvenanceWorker)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Printf("%s[集群] 工作节点搜索失败，%d个插件改在本节点执行: %s | 错误: %v\n", util.RequestLogTag(requestID), len(assigned), worker, err)
+				for _, name := range assigned {
+					fallback = append(fallback, byName[name])
+				}
+				return
+			}
+			markClusterCacheTiers(cacheKey, assigned, response.Partial)
+			results = append(results, workerResults...)
+		}(worker, assigned)
+	}
+	wg.Wait()
+
+	if len(fallback) > 0 {
+		results = append(results, s.runPluginTasks(keyword, fallback, concurrency, cacheKey, ext)...)
+	}
+	return results
+}
+
+// markClusterCacheTiers 按工作节点返回的不完整插件标记本节点的缓存键，其余分配的插件已返回最终结果
+func markClusterCacheTiers(cacheKey string, assigned []string, partial []string) {
+	isPartial := make(map[string]bool, len(partial))
+	for _, name := range partial {
+		isPartial[strings.ToLower(name)] = true
+	}
+	for _, name := range assigned {
+		final := !isPartial[strings.ToLower(name)]
+		markCacheTier(cacheKey, name, cacheTierFor(final, name))
+	}
+}
+
+// SearchPluginsForCluster 工作节点执行协调节点分配的插件搜索，同时返回结果仍不完整、在本节点后台继续搜索的插件
+func (s *SearchService) SearchPluginsForCluster(ctx context.Context, req model.ClusterSearchRequest) (model.ClusterSearchResponse, error) {
+	var plugins []string
+	for _, name := range req.Plugins {
+		if name = strings.TrimSpace(name); name != "" {
+			plugins = append(plugins, name)
+		}
+	}
+	// 未分配插件时不能按"全部插件"处理
+	if len(plugins) == 0 {
+		return model.ClusterSearchResponse{Results: []model.SearchResult{}}, nil
+	}
+	refresh := model.RefreshNone
+	if req.Refresh {
+		refresh = model.RefreshPlugins
+	}
+	results, err := s.searchPlugins(util.RequestIDFromContext(ctx), "", req.Keyword, plugins, refresh, 0, req.Ext)
+	if err != nil {
+		return model.ClusterSearchResponse{}, err
+	}
+	// 异步插件超时返回部分结果时同步记录在缓存键上，后台完成后清除
+	cacheKey := cache.GeneratePluginCacheKey(req.Keyword, plugins, req.Ext)
+	return model.ClusterSearchResponse{Results: results, Partial: partialCachePlugins(cacheKey)}, nil
+}
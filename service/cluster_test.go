@@ -0,0 +1,55 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/testsupport"
+	jsonutil "pansou/util/json"
+)
+
+// 工作节点返回的部分结果在协调节点的缓存键上记为不完整，工作节点返回最终结果后清除
+func TestSearchPluginsOnClusterMarksPartialResults(t *testing.T) {
+	t.Setenv("CACHE_PATH", t.TempDir())
+	config.Init()
+
+	partial := []string{"fakeslow"}
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := jsonutil.Marshal(model.NewSuccessResponse(model.ClusterSearchResponse{
+			Results: []model.SearchResult{{UniqueID: "fakefast-1", Title: "流浪地球"}},
+			Partial: partial,
+		}))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer worker.Close()
+
+	coordinator := NewClusterCoordinator([]string{worker.URL}, "", config.AppConfig.PluginTimeout)
+	plugins := []plugin.AsyncSearchPlugin{
+		testsupport.NewFakePlugin("fakefast", testsupport.FakePluginOptions{}),
+		testsupport.NewFakePlugin("fakeslow", testsupport.FakePluginOptions{}),
+	}
+	s := &SearchService{}
+	cacheKey := "cluster-partial-test"
+
+	results := s.searchPluginsOnCluster(coordinator, "", "流浪地球", plugins, model.RefreshNone, 0, cacheKey, nil)
+	if len(results) != 1 {
+		t.Fatalf("应返回工作节点的1条结果，实际 %d 条", len(results))
+	}
+	if !isPartialCacheKey(cacheKey) {
+		t.Fatalf("工作节点返回部分结果后缓存键应记为不完整")
+	}
+	if ttl := cacheTTLForKey(cacheKey, CacheTierComplete); ttl != CacheTierPartial.TTL() {
+		t.Fatalf("不完整的缓存键应使用短有效期 %v，实际 %v", CacheTierPartial.TTL(), ttl)
+	}
+
+	partial = nil
+	s.searchPluginsOnCluster(coordinator, "", "流浪地球", plugins, model.RefreshNone, 0, cacheKey, nil)
+	if isPartialCacheKey(cacheKey) {
+		t.Fatalf("工作节点返回最终结果后缓存键不应再记为不完整")
+	}
+}
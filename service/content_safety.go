@@ -0,0 +1,359 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+)
+
+// 域名信誉列表最大下载大小
+const maxDomainListSize = 16 << 20
+
+// DomainReputationSource 域名信誉数据源，返回应屏蔽的域名列表
+type DomainReputationSource interface {
+	Name() string
+	Domains() ([]string, error)
+}
+
+// URLDomainList 从URL下载的纯文本域名列表：每行一个域名，
+// 支持#注释和hosts文件格式（如"0.0.0.0 example.com"）
+type URLDomainList struct {
+	URL string
+}
+
+// Name 数据源名称
+func (l URLDomainList) Name() string {
+	return l.URL
+}
+
+// Domains 下载并解析域名列表
+func (l URLDomainList) Domains() ([]string, error) {
+	resp, err := util.GetHTTPClient().Get(l.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+	return parseDomainList(io.LimitReader(resp.Body, maxDomainListSize))
+}
+
+// parseDomainList 解析纯文本或hosts格式的域名列表
+func parseDomainList(r io.Reader) ([]string, error) {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// hosts格式取最后一列
+		domain := normalizeDomain(fields[len(fields)-1])
+		if domain != "" && domain != "localhost" && net.ParseIP(domain) == nil {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, scanner.Err()
+}
+
+// ReputationSourceStatus 域名信誉数据源的更新状态
+type ReputationSourceStatus struct {
+	Name        string    `json:"name"`
+	Domains     int       `json:"domains"`
+	LastUpdated time.Time `json:"last_updated,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// ContentSafetyStats 内容安全过滤统计
+type ContentSafetyStats struct {
+	Patterns       int                      `json:"patterns"`
+	StaticDomains  int                      `json:"static_domains"`
+	DroppedResults int64                    `json:"dropped_results"`
+	DroppedLinks   int64                    `json:"dropped_links"`
+	BySource       map[string]int64         `json:"by_source"` // 按结果来源（tg:频道、plugin:插件名）统计被丢弃的结果数
+	Sources        []ReputationSourceStatus `json:"sources"`
+}
+
+// ContentSafetyFilter 内容安全过滤：丢弃标题匹配屏蔽正则的结果，
+// 移除指向屏蔽域名的链接（链接全部被移除的结果一并丢弃）。在结果写入缓存前调用
+type ContentSafetyFilter struct {
+	mu            sync.RWMutex
+	patterns      []*regexp.Regexp
+	staticDomains map[string]bool
+	sourceDomains map[string]map[string]bool // 按数据源名称
+	sources       []DomainReputationSource
+	status        map[string]*ReputationSourceStatus
+
+	statsMu        sync.Mutex
+	droppedResults int64
+	droppedLinks   int64
+	bySource       map[string]int64
+
+	startOnce sync.Once
+}
+
+var (
+	globalContentSafetyFilter *ContentSafetyFilter
+	contentSafetyOnce         sync.Once
+)
+
+// GetContentSafetyFilter 获取按配置创建的全局内容安全过滤器
+func GetContentSafetyFilter() *ContentSafetyFilter {
+	contentSafetyOnce.Do(func() {
+		globalContentSafetyFilter = NewContentSafetyFilter(config.AppConfig.ContentBlockPatterns, config.AppConfig.ContentBlockDomains)
+		if config.AppConfig.DomainReputationURL != "" {
+			globalContentSafetyFilter.AddReputationSource(URLDomainList{URL: config.AppConfig.DomainReputationURL})
+		}
+	})
+	return globalContentSafetyFilter
+}
+
+// NewContentSafetyFilter 创建内容安全过滤器，无效的正则会被忽略并输出日志
+func NewContentSafetyFilter(patterns []string, domains []string) *ContentSafetyFilter {
+	f := &ContentSafetyFilter{
+		staticDomains: make(map[string]bool),
+		sourceDomains: make(map[string]map[string]bool),
+		status:        make(map[string]*ReputationSourceStatus),
+		bySource:      make(map[string]int64),
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			fmt.Printf("[内容安全] 忽略无效的屏蔽正则 %q: %v\n", pattern, err)
+			continue
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			f.staticDomains[domain] = true
+		}
+	}
+	return f
+}
+
+// AddReputationSource 添加域名信誉数据源，在Start之前调用
+func (f *ContentSafetyFilter) AddReputationSource(source DomainReputationSource) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sources = append(f.sources, source)
+	f.status[source.Name()] = &ReputationSourceStatus{Name: source.Name()}
+}
+
+// Start 立即更新一次域名信誉列表并按间隔定期更新（仅首次调用生效，没有数据源时不启动）
+func (f *ContentSafetyFilter) Start(interval time.Duration) {
+	f.mu.RLock()
+	hasSources := len(f.sources) > 0
+	f.mu.RUnlock()
+	if !hasSources || interval <= 0 {
+		return
+	}
+	f.startOnce.Do(func() {
+		go func() {
+			f.Refresh()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				f.Refresh()
+			}
+		}()
+	})
+}
+
+// Refresh 从所有数据源更新域名列表，更新失败的数据源保留上一次的列表
+func (f *ContentSafetyFilter) Refresh() {
+	f.mu.RLock()
+	sources := append([]DomainReputationSource(nil), f.sources...)
+	f.mu.RUnlock()
+
+	for _, source := range sources {
+		domains, err := source.Domains()
+
+		f.mu.Lock()
+		status := f.status[source.Name()]
+		if err != nil {
+			status.LastError = err.Error()
+			f.mu.Unlock()
+			fmt.Printf("[内容安全] 域名信誉列表更新失败: %s | 错误: %v\n", source.Name(), err)
+			continue
+		}
+		set := make(map[string]bool, len(domains))
+		for _, domain := range domains {
+			if domain = normalizeDomain(domain); domain != "" {
+				set[domain] = true
+			}
+		}
+		f.sourceDomains[source.Name()] = set
+		status.Domains = len(set)
+		status.LastUpdated = time.Now()
+		status.LastError = ""
+		f.mu.Unlock()
+	}
+}
+
+// Filter 过滤搜索结果，返回新的切片，不修改传入的结果
+func (f *ContentSafetyFilter) Filter(results []model.SearchResult) []model.SearchResult {
+	if len(results) == 0 || !f.active() {
+		return results
+	}
+
+	filtered := make([]model.SearchResult, 0, len(results))
+	droppedBySource := make(map[string]int64)
+	var droppedLinks int64
+	for _, result := range results {
+		if f.titleBlocked(result.Title) {
+			droppedBySource[getResultSource(result)]++
+			continue
+		}
+
+		var kept []model.Link
+		for i, link := range result.Links {
+			if !f.hostBlocked(linkHost(link.URL)) {
+				if kept != nil {
+					kept = append(kept, link)
+				}
+				continue
+			}
+			droppedLinks++
+			if kept == nil {
+				kept = append(make([]model.Link, 0, len(result.Links)-1), result.Links[:i]...)
+			}
+		}
+		if kept != nil {
+			if len(kept) == 0 {
+				droppedBySource[getResultSource(result)]++
+				continue
+			}
+			result.Links = kept
+		}
+		filtered = append(filtered, result)
+	}
+
+	if len(droppedBySource) > 0 || droppedLinks > 0 {
+		f.statsMu.Lock()
+		for source, n := range droppedBySource {
+			f.bySource[source] += n
+			f.droppedResults += n
+		}
+		f.droppedLinks += droppedLinks
+		f.statsMu.Unlock()
+	}
+	return filtered
+}
+
+// Stats 获取过滤统计
+func (f *ContentSafetyFilter) Stats() ContentSafetyStats {
+	f.mu.RLock()
+	stats := ContentSafetyStats{
+		Patterns:      len(f.patterns),
+		StaticDomains: len(f.staticDomains),
+		Sources:       make([]ReputationSourceStatus, 0, len(f.sources)),
+	}
+	for _, source := range f.sources {
+		stats.Sources = append(stats.Sources, *f.status[source.Name()])
+	}
+	f.mu.RUnlock()
+
+	f.statsMu.Lock()
+	stats.DroppedResults = f.droppedResults
+	stats.DroppedLinks = f.droppedLinks
+	stats.BySource = make(map[string]int64, len(f.bySource))
+	for source, n := range f.bySource {
+		stats.BySource[source] = n
+	}
+	f.statsMu.Unlock()
+
+	sort.Slice(stats.Sources, func(i, j int) bool { return stats.Sources[i].Name < stats.Sources[j].Name })
+	return stats
+}
+
+// active 是否配置了任何屏蔽规则
+func (f *ContentSafetyFilter) active() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.patterns) > 0 || len(f.staticDomains) > 0 {
+		return true
+	}
+	for _, set := range f.sourceDomains {
+		if len(set) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// titleBlocked 标题是否匹配屏蔽正则
+func (f *ContentSafetyFilter) titleBlocked(title string) bool {
+	for _, re := range f.patterns {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostBlocked 主机名或其任一上级域名是否被屏蔽
+func (f *ContentSafetyFilter) hostBlocked(host string) bool {
+	if host == "" {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for domain := host; ; {
+		if f.staticDomains[domain] {
+			return true
+		}
+		for _, set := range f.sourceDomains {
+			if set[domain] {
+				return true
+			}
+		}
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return false
+		}
+		domain = domain[i+1:]
+	}
+}
+
+// linkHost 提取链接的主机名，磁力和电驴链接没有主机名
+func linkHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	lower := strings.ToLower(rawURL)
+	if strings.HasPrefix(lower, "magnet:") || strings.HasPrefix(lower, "ed2k:") {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// normalizeDomain 统一域名格式：小写，去除通配符前缀和末尾的点
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "*.")
+	domain = strings.TrimPrefix(domain, ".")
+	return strings.TrimSuffix(domain, ".")
+}
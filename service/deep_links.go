@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// DeepLinkTemplates 客户端跳转链接模板：网盘类型 -> 名称 -> 模板，如
+// {"quark": {"quark_app": "..."}, "magnet": {"qbittorrent": "https://qb.example.com/add?urls={url_encoded}"}}
+type DeepLinkTemplates map[string]map[string]string
+
+// 模板中的占位符
+var deepLinkPlaceholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// 可以为空的占位符，其余占位符为空时不生成该跳转链接（如非分享链接没有{share_id}）
+var optionalDeepLinkPlaceholders = map[string]bool{
+	"{password}":         true,
+	"{password_encoded}": true,
+	"{title}":            true,
+	"{title_encoded}":    true,
+}
+
+var (
+	deepLinkTemplates     DeepLinkTemplates
+	deepLinkTemplatesOnce sync.Once
+)
+
+// GetDeepLinkTemplates 获取DEEP_LINK_TEMPLATES_FILE中配置的跳转链接模板，未配置或加载失败时为空
+func GetDeepLinkTemplates() DeepLinkTemplates {
+	deepLinkTemplatesOnce.Do(func() {
+		deepLinkTemplates = DeepLinkTemplates{}
+		if config.AppConfig == nil || config.AppConfig.DeepLinkTemplatesFile == "" {
+			return
+		}
+		loaded, err := loadDeepLinkTemplates(config.AppConfig.DeepLinkTemplatesFile)
+		if err != nil {
+			fmt.Printf("[跳转链接] 加载模板失败: %s | 错误: %v\n", config.AppConfig.DeepLinkTemplatesFile, err)
+			return
+		}
+		deepLinkTemplates = loaded
+	})
+	return deepLinkTemplates
+}
+
+// loadDeepLinkTemplates 读取JSON格式的跳转链接模板，网盘类型不区分大小写
+func loadDeepLinkTemplates(file string) (DeepLinkTemplates, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var loaded DeepLinkTemplates
+	if err := jsonutil.Unmarshal(data, &loaded); err != nil {
+		return nil, err
+	}
+	templates := make(DeepLinkTemplates, len(loaded))
+	for linkType, named := range loaded {
+		linkType = strings.ToLower(strings.TrimSpace(linkType))
+		for name, template := range named {
+			if name = strings.TrimSpace(name); name == "" || strings.TrimSpace(template) == "" {
+				continue
+			}
+			if templates[linkType] == nil {
+				templates[linkType] = make(map[string]string)
+			}
+			templates[linkType][name] = strings.TrimSpace(template)
+		}
+	}
+	return templates, nil
+}
+
+// Build 按链接类型的模板生成跳转链接，没有模板时返回nil。支持的占位符：
+// {url}、{url_encoded}：链接；{url_with_pwd}、{url_with_pwd_encoded}：附带pwd参数的链接；
+// {password}、{password_encoded}：提取码；{share_id}：分享ID（"/s/{id}"形式的路径）；
+// {hash}：磁力链接的info-hash；{title}、{title_encoded}：链接标题
+func (t DeepLinkTemplates) Build(linkType string, linkURL string, password string, title string) map[string]string {
+	named := t[strings.ToLower(linkType)]
+	if len(named) == 0 {
+		return nil
+	}
+
+	values := map[string]string{
+		"{url}":              linkURL,
+		"{url_encoded}":      url.QueryEscape(linkURL),
+		"{password}":         password,
+		"{password_encoded}": url.QueryEscape(password),
+		"{title}":            title,
+		"{title_encoded}":    url.QueryEscape(title),
+	}
+	withPassword := withPasswordParam(linkURL, password)
+	values["{url_with_pwd}"] = withPassword
+	values["{url_with_pwd_encoded}"] = url.QueryEscape(withPassword)
+	if u, err := url.Parse(linkURL); err == nil {
+		values["{share_id}"] = shareIDFromPath(u)
+	}
+	if _, hash, ok := util.NormalizeMagnet(linkURL); ok {
+		values["{hash}"] = hash
+	}
+
+	links := make(map[string]string, len(named))
+	for name, template := range named {
+		complete := true
+		expanded := deepLinkPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+			value, known := values[placeholder]
+			if !known {
+				return placeholder
+			}
+			if value == "" && !optionalDeepLinkPlaceholders[placeholder] {
+				complete = false
+			}
+			return value
+		})
+		if complete {
+			links[name] = expanded
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
+
+// applyDeepLinks 为合并链接和扁平列表中的链接生成客户端跳转链接。
+// 社区来源的链接返回的是跳转地址，原链接和提取码不公开，不生成跳转链接
+func applyDeepLinks(response *model.SearchResponse) {
+	templates := GetDeepLinkTemplates()
+	if len(templates) == 0 {
+		return
+	}
+	for linkType, links := range response.MergedByType {
+		for i := range links {
+			link := &links[i]
+			if !link.CommunitySource {
+				link.DeepLinks = templates.Build(linkType, link.URL, link.Password, link.Note)
+			}
+		}
+	}
+	for i := range response.Links {
+		link := &response.Links[i]
+		if !link.CommunitySource {
+			link.DeepLinks = templates.Build(link.Type, link.URL, link.Password, link.Title)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sort"
+
+	"pansou/model"
+)
+
+// flattenMergedLinks 将按网盘类型分组的链接展开为一个列表，按链接在排序后结果中首次出现的位置排列
+// （与results的排序一致），位置相同时依次按发布时间、网盘类型和URL排序
+func flattenMergedLinks(mergedLinks model.MergedLinks, rankedResults []model.SearchResult) []model.FlatLink {
+	// 链接在排序后结果中的位置
+	rank := make(map[string]int)
+	for i, result := range rankedResults {
+		for _, link := range result.Links {
+			key := linkDedupeKey(link.URL)
+			if _, exists := rank[key]; !exists {
+				rank[key] = i
+			}
+		}
+	}
+
+	type rankedLink struct {
+		rank int
+		link model.FlatLink
+	}
+	ranked := make([]rankedLink, 0, len(mergedLinks)*8)
+	for linkType, links := range mergedLinks {
+		for _, link := range links {
+			r, ok := rank[linkDedupeKey(link.URL)]
+			if !ok {
+				r = len(rankedResults)
+			}
+			ranked = append(ranked, rankedLink{rank: r, link: model.FlatLink{
+				Type:       linkType,
+				URL:        link.URL,
+				Password:   link.Password,
+				Title:      link.Note,
+				Datetime:   link.Datetime,
+				Source:     link.Source,
+				Images:     link.Images,
+				LinkID:     link.LinkID,
+				Language:   link.Language,
+				Size:       link.Size,
+				FileCount:  link.FileCount,
+				ExpiresAt:  link.ExpiresAt,
+				SourceNote: link.SourceNote,
+				IsNew:      link.IsNew,
+				Highlights: flatLinkHighlights(link.Highlights),
+				Attributes: link.Attributes,
+			}})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if !a.link.Datetime.Equal(b.link.Datetime) {
+			return a.link.Datetime.After(b.link.Datetime)
+		}
+		if a.link.Type != b.link.Type {
+			return a.link.Type < b.link.Type
+		}
+		return a.link.URL < b.link.URL
+	})
+
+	flat := make([]model.FlatLink, len(ranked))
+	for i := range ranked {
+		flat[i] = ranked[i].link
+	}
+	return flat
+}
+
+// flatLinkHighlights 扁平列表中链接的note作为title返回，命中位置随之移到title
+func flatLinkHighlights(highlights *model.Highlights) *model.Highlights {
+	if highlights == nil {
+		return nil
+	}
+	return &model.Highlights{Title: highlights.Note}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"pansou/model"
+)
+
+// highlightTerms 生成用于标注命中位置的小写词：关键词（含别名）本身，以及多词关键词中的各个词
+func highlightTerms(keywords []string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, keyword := range keywords {
+		lower := strings.ToLower(strings.TrimSpace(keyword))
+		add(lower)
+		if fields := strings.Fields(lower); len(fields) > 1 {
+			for _, field := range fields {
+				add(field)
+			}
+		}
+	}
+	return terms
+}
+
+// findHighlightSpans 查找各词在已转为小写的文本中的命中位置，重叠或相邻的命中合并为一处。
+// strings.ToLower逐字符转换，不改变字符数，因此返回的字符偏移同样适用于原文
+func findHighlightSpans(lowerText string, terms []string) []model.HighlightSpan {
+	if lowerText == "" {
+		return nil
+	}
+	type byteSpan struct{ start, end int }
+	var spans []byteSpan
+	for _, term := range terms {
+		for offset := 0; offset < len(lowerText); {
+			i := strings.Index(lowerText[offset:], term)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			spans = append(spans, byteSpan{start, start + len(term)})
+			offset = start + len(term)
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.start <= last.end {
+			if span.end > last.end {
+				last.end = span.end
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	// 字节偏移转换为字符偏移，按顺序累加避免重复计数
+	result := make([]model.HighlightSpan, len(merged))
+	pos, runes := 0, 0
+	for i, span := range merged {
+		runes += utf8.RuneCountInString(lowerText[pos:span.start])
+		start := runes
+		runes += utf8.RuneCountInString(lowerText[span.start:span.end])
+		pos = span.end
+		result[i] = model.HighlightSpan{Start: start, End: runes}
+	}
+	return result
+}
+
+// resultHighlights 关键词在结果标题和内容中的命中位置，lowerTitle为已转为小写的标题，都没有命中时返回nil
+func resultHighlights(result model.SearchResult, lowerTitle string, terms []string) *model.Highlights {
+	highlights := model.Highlights{
+		Title:   findHighlightSpans(lowerTitle, terms),
+		Content: findHighlightSpans(strings.ToLower(result.Content), terms),
+	}
+	if highlights.Title == nil && highlights.Content == nil {
+		return nil
+	}
+	return &highlights
+}
+
+// noteHighlights 关键词在合并链接note中的命中位置，lowerNote为已转为小写的note，没有命中时返回nil
+func noteHighlights(lowerNote string, terms []string) *model.Highlights {
+	spans := findHighlightSpans(lowerNote, terms)
+	if spans == nil {
+		return nil
+	}
+	return &model.Highlights{Note: spans}
+}
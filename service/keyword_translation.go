@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// 片名语言，也是插件站点标题的语言
+const (
+	TitleLangZh = "zh"
+	TitleLangEn = "en"
+)
+
+// TitleAlias 片名对照表中的一项，如{"zh": "盗梦空间", "en": "Inception"}
+type TitleAlias struct {
+	Zh string `json:"zh"`
+	En string `json:"en"`
+}
+
+// TitleAliasDictionary 中英文片名对照表，按规范化后的片名双向查找
+type TitleAliasDictionary struct {
+	zhToEn map[string]string
+	enToZh map[string]string
+}
+
+var (
+	titleAliasDictionary     *TitleAliasDictionary
+	titleAliasDictionaryOnce sync.Once
+)
+
+// GetTitleAliasDictionary 获取TITLE_ALIAS_FILE中配置的片名对照表，未配置或加载失败时为空表
+func GetTitleAliasDictionary() *TitleAliasDictionary {
+	titleAliasDictionaryOnce.Do(func() {
+		titleAliasDictionary = NewTitleAliasDictionary(nil)
+		if config.AppConfig == nil || config.AppConfig.TitleAliasFile == "" {
+			return
+		}
+		aliases, err := loadTitleAliases(config.AppConfig.TitleAliasFile)
+		if err != nil {
+			fmt.Printf("[关键词翻译] 加载片名对照表失败: %s | 错误: %v\n", config.AppConfig.TitleAliasFile, err)
+			return
+		}
+		titleAliasDictionary = NewTitleAliasDictionary(aliases)
+		fmt.Printf("[关键词翻译] 已加载片名对照表: %d条\n", len(aliases))
+	})
+	return titleAliasDictionary
+}
+
+// loadTitleAliases 读取JSON数组格式的片名对照表
+func loadTitleAliases(file string) ([]TitleAlias, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []TitleAlias
+	if err := jsonutil.Unmarshal(data, &aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// NewTitleAliasDictionary 创建片名对照表，中英文片名缺一的项被忽略，同一片名出现多次时使用第一项
+func NewTitleAliasDictionary(aliases []TitleAlias) *TitleAliasDictionary {
+	d := &TitleAliasDictionary{
+		zhToEn: make(map[string]string, len(aliases)),
+		enToZh: make(map[string]string, len(aliases)),
+	}
+	for _, alias := range aliases {
+		zh, en := strings.Join(strings.Fields(alias.Zh), " "), strings.Join(strings.Fields(alias.En), " ")
+		if zh == "" || en == "" {
+			continue
+		}
+		if key := normalizeTitleAliasKey(zh); d.zhToEn[key] == "" {
+			d.zhToEn[key] = en
+		}
+		if key := normalizeTitleAliasKey(en); d.enToZh[key] == "" {
+			d.enToZh[key] = zh
+		}
+	}
+	return d
+}
+
+// normalizeTitleAliasKey 规范化片名用于查找：不区分大小写，合并连续空白
+func normalizeTitleAliasKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// Translate 将中文片名翻译为英文或将英文片名翻译为中文，返回译名及其语言，
+// 关键词语言无法判断或对照表中没有时ok为false
+func (d *TitleAliasDictionary) Translate(keyword string) (translated, lang string, ok bool) {
+	key := normalizeTitleAliasKey(keyword)
+	switch keywordTitleLanguage(keyword) {
+	case TitleLangZh:
+		translated, lang = d.zhToEn[key], TitleLangEn
+	case TitleLangEn:
+		translated, lang = d.enToZh[key], TitleLangZh
+	}
+	if translated == "" {
+		return "", "", false
+	}
+	return translated, lang, true
+}
+
+// keywordTitleLanguage 判断关键词的语言：含汉字为中文，纯拉丁字母为英文，其余无法判断
+func keywordTitleLanguage(keyword string) string {
+	switch util.DetectLanguage(keyword) {
+	case util.LangZhCN, util.LangZhTW:
+		return TitleLangZh
+	case util.LangEn:
+		return TitleLangEn
+	}
+	return ""
+}
+
+// getPluginLanguage 获取插件站点标题的语言：PLUGIN_LANGUAGES配置优先，其次是插件元数据，默认为中文
+func getPluginLanguage(p plugin.AsyncSearchPlugin) string {
+	if lang, ok := config.AppConfig.PluginLanguages[strings.ToLower(p.Name())]; ok {
+		return lang
+	}
+	if lang := plugin.GetPluginMetadata(p).Language; lang != "" {
+		return strings.ToLower(lang)
+	}
+	return TitleLangZh
+}
+
+// partitionPluginsByLanguage 将要搜索的插件（plugins为nil时为全部插件）按站点语言分为两组：
+// matched为站点语言是lang的插件，others为其余插件
+func (s *SearchService) partitionPluginsByLanguage(plugins []string, lang string) (matched, others []string) {
+	if s.pluginManager == nil {
+		return nil, plugins
+	}
+	var requested map[string]bool
+	if plugins != nil {
+		requested = make(map[string]bool, len(plugins))
+		for _, name := range plugins {
+			requested[strings.ToLower(name)] = true
+		}
+	}
+	for _, p := range s.pluginManager.GetPlugins() {
+		if requested != nil && !requested[strings.ToLower(p.Name())] {
+			continue
+		}
+		if getPluginLanguage(p) == lang {
+			matched = append(matched, p.Name())
+		} else {
+			others = append(others, p.Name())
+		}
+	}
+	return matched, others
+}
+
+// searchPluginsTranslated 搜索插件。启用KEYWORD_TRANSLATION且片名对照表中有关键词的译名时，
+// 站点语言与译名相同的插件（如英文站点thepiratebay）使用译名搜索，其余插件仍使用原关键词，结果合并返回
+func (s *SearchService) searchPluginsTranslated(requestID string, namespace string, keyword string, plugins []string, refresh model.RefreshLevel, concurrency int, ext map[string]interface{}) ([]model.SearchResult, error) {
+	if !config.AppConfig.KeywordTranslation {
+		return s.searchPlugins(requestID, namespace, keyword, plugins, refresh, concurrency, ext)
+	}
+	translated, lang, ok := GetTitleAliasDictionary().Translate(keyword)
+	if !ok {
+		return s.searchPlugins(requestID, namespace, keyword, plugins, refresh, concurrency, ext)
+	}
+	translatedPlugins, originalPlugins := s.partitionPluginsByLanguage(plugins, lang)
+	if len(translatedPlugins) == 0 {
+		return s.searchPlugins(requestID, namespace, keyword, plugins, refresh, concurrency, ext)
+	}
+	fmt.Printf("%s[关键词翻译] %s -> %s | 插件: %s\n", util.RequestLogTag(requestID), keyword, translated, strings.Join(translatedPlugins, ","))
+
+	var (
+		wg                                 sync.WaitGroup
+		originalResults, translatedResults []model.SearchResult
+		originalErr, translatedErr         error
+	)
+	// 插件列表为空时不能传nil（表示全部插件），没有其余插件时跳过
+	if len(originalPlugins) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			originalResults, originalErr = s.searchPlugins(requestID, namespace, keyword, originalPlugins, refresh, concurrency, ext)
+		}()
+	}
+	translatedResults, translatedErr = s.searchPlugins(requestID, namespace, translated, translatedPlugins, refresh, concurrency, ext)
+	wg.Wait()
+
+	if originalErr != nil {
+		return nil, originalErr
+	}
+	if translatedErr != nil {
+		return nil, translatedErr
+	}
+	return mergeSearchResults(originalResults, translatedResults), nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"pansou/config"
+	"pansou/plugin"
+)
+
+// pluginLatencyBudget 插件在一次搜索中可使用的时间：PLUGIN_TIMEOUT_OVERRIDES中配置的单独超时优先，
+// 否则按插件等级取插件超时窗口的一定比例，使低等级的慢插件不能占满整个窗口。结果不超过插件超时时间
+func pluginLatencyBudget(p plugin.AsyncSearchPlugin) time.Duration {
+	window := config.AppConfig.PluginTimeout
+	if override, ok := config.AppConfig.PluginTimeoutOverrides[strings.ToLower(p.Name())]; ok {
+		if override < window {
+			return override
+		}
+		return window
+	}
+	ratio, ok := config.AppConfig.PluginLevelBudgets[p.Priority()]
+	if !ok || ratio >= 1 {
+		return window
+	}
+	return time.Duration(float64(window) * ratio)
+}
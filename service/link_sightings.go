@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+	"pansou/util/cache"
+)
+
+// 每个链接最多记录的标题、关键词和来源数
+const (
+	maxSightingTitles   = 10
+	maxSightingKeywords = 20
+	maxSightingSources  = 10
+)
+
+// 最近记录过的（关键词, 链接, 标题）组合每一代的最大条目数，超过后轮换
+const maxRecentSightingsPerGeneration = 100000
+
+// 链接出现记录的锁分段数
+const linkSightingLockStripes = 64
+
+// ErrInvalidLookupQuery 反查的输入不是磁力链接、info-hash或可识别的网盘分享链接
+var ErrInvalidLookupQuery = errors.New("q必须为磁力链接、info-hash或网盘分享链接")
+
+// LinkSighting 链接在历史搜索结果中的出现记录
+type LinkSighting struct {
+	URL       string    `json:"url"`
+	Type      string    `json:"type"`
+	Titles    []string  `json:"titles"`     // 链接出现时的标题，最近的在前
+	Keywords  []string  `json:"keywords"`   // 链接出现过的搜索关键词，最近的在前
+	Sources   []string  `json:"sources"`    // 返回过该链接的来源，最近的在前
+	PostedAt  time.Time `json:"posted_at"`  // 来源中最早的发布时间
+	FirstSeen time.Time `json:"first_seen"` // 第一次出现在搜索结果中的时间
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// LinkLookupResult 链接反查结果
+type LinkLookupResult struct {
+	Query    string        `json:"query"`
+	URL      string        `json:"url"` // 规范化后的链接，磁力链接为只含info-hash的链接
+	Type     string        `json:"type"`
+	Found    bool          `json:"found"`
+	Sighting *LinkSighting `json:"sighting,omitempty"`
+}
+
+// linkSightingEntry 待记录的一次出现
+type linkSightingEntry struct {
+	key      string
+	url      string
+	linkType string
+	title    string
+	source   string
+	posted   time.Time
+}
+
+var (
+	linkSightingLocks [linkSightingLockStripes]sync.Mutex
+
+	// 最近记录过的组合（两代轮换），相同关键词重复搜索时不再重复写入
+	recentSightingsMu       sync.Mutex
+	recentSightings         = make(map[uint64]struct{})
+	previousRecentSightings = make(map[uint64]struct{})
+)
+
+// linkSightingCacheKey 链接出现记录的缓存键，dedupeKey见linkDedupeKey
+func linkSightingCacheKey(namespace string, dedupeKey string) string {
+	hash := md5.Sum([]byte("link_sighting:" + dedupeKey))
+	return cache.NamespaceCacheKey(namespace, hex.EncodeToString(hash[:]))
+}
+
+// recordLinkSightings 记录合并链接在本次搜索中的出现（标题、关键词、来源），用于按链接反查。
+// 只记录最近未记录过的（关键词, 链接, 标题）组合，写入在后台进行
+func recordLinkSightings(namespace string, keyword string, mergedLinks model.MergedLinks) {
+	mainCache := enhancedTwoLevelCache
+	if !config.AppConfig.LinkIndexEnabled || mainCache == nil || len(mergedLinks) == 0 {
+		return
+	}
+
+	// 合并链接在之后会被修改（分配跳转ID、社区来源替换链接等），先复制需要的字段
+	var entries []linkSightingEntry
+	recentSightingsMu.Lock()
+	for linkType, links := range mergedLinks {
+		for _, link := range links {
+			// 不允许持久化的来源的链接不记录
+			if isNoPersistSource(link.Source) {
+				continue
+			}
+			key := linkDedupeKey(link.URL)
+			pair := sightingPairHash(namespace, keyword, key, link.Note)
+			if _, ok := recentSightings[pair]; ok {
+				continue
+			}
+			if _, ok := previousRecentSightings[pair]; !ok {
+				entries = append(entries, linkSightingEntry{
+					key: key, url: link.URL, linkType: linkType, title: link.Note, source: link.Source, posted: link.Datetime,
+				})
+			}
+			if len(recentSightings) >= maxRecentSightingsPerGeneration {
+				previousRecentSightings = recentSightings
+				recentSightings = make(map[uint64]struct{})
+			}
+			recentSightings[pair] = struct{}{}
+		}
+	}
+	recentSightingsMu.Unlock()
+	if len(entries) == 0 {
+		return
+	}
+
+	go func() {
+		now := time.Now()
+		for _, entry := range entries {
+			addLinkSighting(namespace, keyword, entry, now)
+		}
+	}()
+}
+
+// sightingPairHash 计算（关键词, 链接, 标题）组合的哈希
+func sightingPairHash(namespace, keyword, key, title string) uint64 {
+	h := fnv.New64a()
+	for _, part := range []string{namespace, keyword, key, title} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// addLinkSighting 把一次出现合并到链接的记录中
+func addLinkSighting(namespace string, keyword string, entry linkSightingEntry, now time.Time) {
+	mainCache := enhancedTwoLevelCache
+	key := linkSightingCacheKey(namespace, entry.key)
+	lock := &linkSightingLocks[fnv32(key)%linkSightingLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	sighting := LinkSighting{URL: entry.url, Type: entry.linkType, FirstSeen: now}
+	if data, hit, err := mainCache.Get(key); err == nil && hit {
+		var existing LinkSighting
+		if mainCache.GetSerializer().Deserialize(data, &existing) == nil {
+			sighting = existing
+		}
+	}
+	sighting.LastSeen = now
+	sighting.Titles = prependUnique(sighting.Titles, entry.title, maxSightingTitles)
+	sighting.Keywords = prependUnique(sighting.Keywords, keyword, maxSightingKeywords)
+	sighting.Sources = prependUnique(sighting.Sources, entry.source, maxSightingSources)
+	if !entry.posted.IsZero() && (sighting.PostedAt.IsZero() || entry.posted.Before(sighting.PostedAt)) {
+		sighting.PostedAt = entry.posted
+	}
+
+	data, err := mainCache.GetSerializer().Serialize(sighting)
+	if err != nil {
+		return
+	}
+	mainCache.Set(key, data, config.AppConfig.LinkIndexTTL)
+}
+
+// prependUnique 将value移到列表最前（已存在时去重），超出上限时丢弃最旧的
+func prependUnique(values []string, value string, max int) []string {
+	if value == "" {
+		return values
+	}
+	result := make([]string, 0, len(values)+1)
+	result = append(result, value)
+	for _, v := range values {
+		if v != value && len(result) < max {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// LookupLink 按磁力链接、info-hash或网盘分享链接反查它在历史搜索结果中的出现记录，不向插件和频道发起搜索
+func LookupLink(ctx context.Context, query string) (LinkLookupResult, error) {
+	query = strings.TrimSpace(query)
+	result := LinkLookupResult{Query: query}
+
+	switch {
+	case util.IsMagnetURL(query) || isInfoHash(query):
+		if !util.IsMagnetURL(query) {
+			query = "magnet:?xt=urn:btih:" + query
+		}
+		_, hash, ok := util.NormalizeMagnet(query)
+		if !ok {
+			return result, ErrInvalidLookupQuery
+		}
+		result.URL, result.Type = "magnet:?xt=urn:btih:"+hash, "magnet"
+	default:
+		linkType := util.GetLinkType(query)
+		if linkType == "" || linkType == "others" {
+			return result, ErrInvalidLookupQuery
+		}
+		result.URL, _ = util.CanonicalizeShareURL(linkType, query)
+		result.Type = linkType
+	}
+
+	mainCache := enhancedTwoLevelCache
+	if mainCache == nil {
+		return result, nil
+	}
+	var namespace string
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		namespace = tenant.ID
+	}
+	data, hit, err := mainCache.Get(linkSightingCacheKey(namespace, linkDedupeKey(result.URL)))
+	if err != nil || !hit {
+		return result, nil
+	}
+	var sighting LinkSighting
+	if err := mainCache.GetSerializer().Deserialize(data, &sighting); err != nil {
+		return result, nil
+	}
+	result.Found = true
+	result.Sighting = &sighting
+	return result, nil
+}
+
+// isInfoHash 是否为40位十六进制或32位base32的info-hash
+func isInfoHash(s string) bool {
+	if len(s) != 40 && len(s) != 32 {
+		return false
+	}
+	_, _, ok := util.NormalizeMagnet("magnet:?xt=urn:btih:" + s)
+	return ok
+}
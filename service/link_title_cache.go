@@ -0,0 +1,103 @@
+package service
+
+import (
+	"hash/fnv"
+	"runtime"
+	"sync"
+
+	"pansou/model"
+)
+
+// 每一代链接-标题映射缓存的最大条目数，超过后轮换
+const maxLinkTitleEntriesPerGeneration = 20000
+
+// 结果数超过该值时并行提取链接-标题映射
+const parallelLinkTitleThreshold = 32
+
+// linkTitleCache 按消息内容哈希缓存链接-标题映射。
+// 缓存命中的搜索结果在重新计算MergedByType时无需再次解析消息内容；
+// 缓存中的映射只读，调用方不得修改
+type linkTitleCache struct {
+	mu       sync.RWMutex
+	current  map[string]map[string]string
+	previous map[string]map[string]string
+}
+
+var globalLinkTitleCache = &linkTitleCache{
+	current:  make(map[string]map[string]string),
+	previous: make(map[string]map[string]string),
+}
+
+// get 查询缓存（当前代未命中时查询上一代）
+func (c *linkTitleCache) get(key string) (map[string]string, bool) {
+	c.mu.RLock()
+	m, ok := c.current[key]
+	if !ok {
+		m, ok = c.previous[key]
+	}
+	c.mu.RUnlock()
+	return m, ok
+}
+
+// put 写入缓存
+func (c *linkTitleCache) put(key string, m map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current) >= maxLinkTitleEntriesPerGeneration {
+		c.previous = c.current
+		c.current = make(map[string]map[string]string)
+	}
+	c.current[key] = m
+}
+
+// linkTitleCacheKey 由频道、消息内容和链接列表计算缓存键（频道决定使用的解析器）
+func linkTitleCacheKey(result model.SearchResult) string {
+	h := fnv.New128a()
+	h.Write([]byte(result.Channel))
+	h.Write([]byte{0})
+	h.Write([]byte(result.Content))
+	for _, link := range result.Links {
+		h.Write([]byte{0})
+		h.Write([]byte(link.URL))
+	}
+	return string(h.Sum(nil))
+}
+
+// getLinkTitleMap 获取单条结果的链接-标题映射（优先读缓存）
+func getLinkTitleMap(result model.SearchResult) map[string]string {
+	key := linkTitleCacheKey(result)
+	if m, ok := globalLinkTitleCache.get(key); ok {
+		return m
+	}
+	m := buildLinkTitleMap(result)
+	globalLinkTitleCache.put(key, m)
+	return m
+}
+
+// getLinkTitleMaps 批量获取链接-标题映射，结果较多时按CPU核数并行解析
+func getLinkTitleMaps(results []model.SearchResult) []map[string]string {
+	maps := make([]map[string]string, len(results))
+	if len(results) < parallelLinkTitleThreshold {
+		for i := range results {
+			maps[i] = getLinkTitleMap(results[i])
+		}
+		return maps
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(results) {
+		workers = len(results)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			for i := start; i < len(results); i += workers {
+				maps[i] = getLinkTitleMap(results[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	return maps
+}
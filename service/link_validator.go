@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+	"pansou/util/pool"
+)
+
+// 链接检测状态
+const (
+	LinkStatusAlive            = "alive"             // 分享有效
+	LinkStatusDead             = "dead"              // 分享已失效、被取消或不存在
+	LinkStatusPasswordRequired = "password_required" // 需要提取码，或提供的提取码错误
+	LinkStatusUnknown          = "unknown"           // 无法判断，如请求失败或被限流，不缓存
+	LinkStatusUnsupported      = "unsupported"       // 不是已知网盘的分享链接，如磁力链接
+)
+
+// 检测请求读取的最大响应长度，失效提示一般在页面开头
+const maxLinkProbeBodyBytes = 256 << 10
+
+// 最多缓存的检测结果数
+const maxLinkProbeCacheEntries = 20000
+
+// 检测请求的并发数
+const linkProbeConcurrency = 8
+
+// LinkCheckResult 单个分享链接的检测结果
+type LinkCheckResult struct {
+	URL          string    `json:"url"`                     // 请求中的链接
+	CanonicalURL string    `json:"canonical_url,omitempty"` // 规范化后的链接
+	Type         string    `json:"type,omitempty"`          // 网盘类型
+	Status       string    `json:"status"`                  // alive、dead、password_required、unknown、unsupported
+	Reason       string    `json:"reason,omitempty"`        // 判断依据，如命中的失效提示或HTTP状态码
+	Cached       bool      `json:"cached,omitempty"`        // 结果来自缓存
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// linkProbeRule 一种网盘的检测方式：request为空时直接请求分享页面，
+// 响应中命中deadMarkers视为失效，命中passwordMarkers视为需要提取码
+type linkProbeRule struct {
+	request         func(ctx context.Context, shareURL *url.URL, password string) (*http.Request, error)
+	deadMarkers     []string
+	passwordMarkers []string
+}
+
+// 各网盘分享页面通用的失效提示
+var commonDeadMarkers = []string{"分享已失效", "链接不存在", "分享不存在", "已取消分享", "取消了分享", "分享已过期", "链接已过期", "来晚了", "已被删除"}
+
+// 各网盘分享页面通用的提取码提示
+var commonPasswordMarkers = []string{"请输入提取码", "输入提取码", "提取码错误", "需要提取码"}
+
+// linkProbeRules 按网盘类型的检测方式，未列出的类型请求分享页面并使用通用提示
+var linkProbeRules = map[string]linkProbeRule{
+	// 百度网盘需要提取码时跳转到share/init页面
+	"baidu": {passwordMarkers: []string{"share/init"}},
+	// 阿里云盘分享页面由前端渲染，使用匿名分享信息接口
+	"aliyun": {
+		request:     aliyunProbeRequest,
+		deadMarkers: []string{"ShareLink.Cancelled", "ShareLink.Expired", "ShareLink.Forbidden", "NotFound.ShareLink"},
+	},
+	// 夸克网盘分享页面由前端渲染，使用获取分享令牌的接口，同时校验提取码
+	"quark": {request: quarkProbeRequest},
+}
+
+// aliyunProbeRequest 构造阿里云盘匿名获取分享信息的请求
+func aliyunProbeRequest(ctx context.Context, shareURL *url.URL, password string) (*http.Request, error) {
+	shareID := shareIDFromPath(shareURL)
+	if shareID == "" {
+		return nil, fmt.Errorf("无法识别分享ID")
+	}
+	body, _ := jsonutil.Marshal(map[string]string{"share_id": shareID})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://api.aliyundrive.com/adrive/v3/share_link/get_share_by_anonymous?share_id="+url.QueryEscape(shareID), strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// quarkProbeRequest 构造夸克网盘获取分享令牌的请求
+func quarkProbeRequest(ctx context.Context, shareURL *url.URL, password string) (*http.Request, error) {
+	shareID := shareIDFromPath(shareURL)
+	if shareID == "" {
+		return nil, fmt.Errorf("无法识别分享ID")
+	}
+	body, _ := jsonutil.Marshal(map[string]string{"pwd_id": shareID, "passcode": password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://drive-h.quark.cn/1/clouddrive/share/sharepage/token?pr=ucpro&fr=pc", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// shareIDFromPath 获取"/s/{id}"形式路径中的分享ID
+func shareIDFromPath(shareURL *url.URL) string {
+	parts := strings.Split(strings.Trim(shareURL.Path, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "s" {
+		return parts[1]
+	}
+	return ""
+}
+
+// linkProbeCacheEntry 缓存的检测结果
+type linkProbeCacheEntry struct {
+	result    LinkCheckResult
+	expiresAt time.Time
+}
+
+// linkProbeBucket 每种网盘的检测请求令牌桶
+type linkProbeBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// LinkValidator 检测网盘分享链接是否有效：按网盘类型限制发出检测请求的速率，检测结果缓存LINK_PROBE_CACHE_TTL
+type LinkValidator struct {
+	client *http.Client
+	rate   float64 // 每种网盘每秒的检测请求数
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	cache   map[string]linkProbeCacheEntry
+	buckets map[string]*linkProbeBucket
+}
+
+var (
+	globalLinkValidator *LinkValidator
+	linkValidatorOnce   sync.Once
+)
+
+// GetLinkValidator 获取全局链接检测器
+func GetLinkValidator() *LinkValidator {
+	linkValidatorOnce.Do(func() {
+		globalLinkValidator = NewLinkValidator(util.GetHTTPClient(), config.AppConfig.LinkProbeRate, config.AppConfig.LinkProbeCacheTTL)
+	})
+	return globalLinkValidator
+}
+
+// NewLinkValidator 创建链接检测器，rate为每种网盘每秒最多发出的检测请求数，<=0表示不限制；ttl<=0时不缓存
+func NewLinkValidator(client *http.Client, rate float64, ttl time.Duration) *LinkValidator {
+	return &LinkValidator{
+		client:  client,
+		rate:    rate,
+		ttl:     ttl,
+		cache:   make(map[string]linkProbeCacheEntry),
+		buckets: make(map[string]*linkProbeBucket),
+	}
+}
+
+// LinkProbeTarget 待检测的分享链接
+type LinkProbeTarget struct {
+	URL      string
+	Password string
+}
+
+// CheckAll 并发检测多个分享链接，结果顺序与targets一致。ctx结束时未完成的链接状态为unknown
+func (v *LinkValidator) CheckAll(ctx context.Context, targets []LinkProbeTarget) []LinkCheckResult {
+	tasks := make([]func(context.Context) (LinkCheckResult, error), len(targets))
+	for i, target := range targets {
+		target := target
+		tasks[i] = func(ctx context.Context) (LinkCheckResult, error) {
+			return v.Check(ctx, target.URL, target.Password), nil
+		}
+	}
+
+	results := make([]LinkCheckResult, len(targets))
+	for i, result := range pool.Run(ctx, linkProbeConcurrency, tasks) {
+		if result.Err != nil {
+			results[i] = LinkCheckResult{URL: targets[i].URL, Status: LinkStatusUnknown, Reason: result.Err.Error(), CheckedAt: time.Now()}
+			continue
+		}
+		results[i] = result.Value
+	}
+	return results
+}
+
+// Check 检测单个分享链接，password为链接的提取码（可为空）
+func (v *LinkValidator) Check(ctx context.Context, rawURL string, password string) LinkCheckResult {
+	rawURL = strings.TrimSpace(rawURL)
+	result := LinkCheckResult{URL: rawURL, CheckedAt: time.Now()}
+
+	result.Type = util.GetLinkType(rawURL)
+	canonical, urlPassword := util.CanonicalizeShareURL(result.Type, rawURL)
+	if password == "" {
+		password = urlPassword
+	}
+	// 只请求已知网盘的规范主机名，避免被用来访问任意地址
+	shareURL, err := url.Parse(canonical)
+	if err != nil || shareURL.Scheme != "https" || util.ShareHost(result.Type) == "" || shareURL.Host != util.ShareHost(result.Type) {
+		result.Status = LinkStatusUnsupported
+		return result
+	}
+	result.CanonicalURL = canonical
+
+	cacheKey := canonical + "|" + password
+	if cached, ok := v.cached(cacheKey); ok {
+		cached.URL = rawURL
+		cached.Cached = true
+		return cached
+	}
+
+	if err := v.wait(ctx, result.Type); err != nil {
+		result.Status = LinkStatusUnknown
+		result.Reason = "rate_limited"
+		return result
+	}
+
+	result.Status, result.Reason = v.probe(ctx, result.Type, shareURL, password)
+	result.CheckedAt = time.Now()
+	if result.Status != LinkStatusUnknown {
+		v.store(cacheKey, result)
+	}
+	return result
+}
+
+// probe 发出检测请求并根据响应判断状态
+func (v *LinkValidator) probe(ctx context.Context, linkType string, shareURL *url.URL, password string) (string, string) {
+	rule := linkProbeRules[linkType]
+
+	var req *http.Request
+	var err error
+	if rule.request != nil {
+		req, err = rule.request(ctx, shareURL, password)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, shareURL.String(), nil)
+	}
+	if err != nil {
+		return LinkStatusUnknown, err.Error()
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return LinkStatusUnknown, err.Error()
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLinkProbeBodyBytes))
+	body := string(data)
+	finalURL := resp.Request.URL.String()
+
+	if marker := findMarker(body, rule.deadMarkers, commonDeadMarkers); marker != "" {
+		return LinkStatusDead, marker
+	}
+	if marker := findMarker(finalURL+"\n"+body, rule.passwordMarkers, commonPasswordMarkers); marker != "" {
+		return LinkStatusPasswordRequired, marker
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return LinkStatusDead, fmt.Sprintf("http %d", resp.StatusCode)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return LinkStatusAlive, ""
+	default:
+		return LinkStatusUnknown, fmt.Sprintf("http %d", resp.StatusCode)
+	}
+}
+
+// findMarker 返回text中第一个命中的提示，没有命中时返回空字符串
+func findMarker(text string, markerLists ...[]string) string {
+	for _, markers := range markerLists {
+		for _, marker := range markers {
+			if strings.Contains(text, marker) {
+				return marker
+			}
+		}
+	}
+	return ""
+}
+
+// wait 按网盘类型的令牌桶等待发出检测请求的时机，ctx结束前仍无令牌时返回错误
+func (v *LinkValidator) wait(ctx context.Context, linkType string) error {
+	if v.rate <= 0 {
+		return nil
+	}
+	for {
+		v.mu.Lock()
+		now := time.Now()
+		bucket, ok := v.buckets[linkType]
+		if !ok {
+			bucket = &linkProbeBucket{tokens: 1, last: now}
+			v.buckets[linkType] = bucket
+		}
+		bucket.tokens += now.Sub(bucket.last).Seconds() * v.rate
+		if bucket.tokens > 1 {
+			bucket.tokens = 1
+		}
+		bucket.last = now
+		if bucket.tokens >= 1 {
+			bucket.tokens--
+			v.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - bucket.tokens) / v.rate * float64(time.Second))
+		v.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// cached 获取未过期的检测结果
+func (v *LinkValidator) cached(key string) (LinkCheckResult, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	entry, ok := v.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return LinkCheckResult{}, false
+	}
+	return entry.result, true
+}
+
+// store 缓存检测结果，超过上限时先清理过期条目，仍超过时清空
+func (v *LinkValidator) store(key string, result LinkCheckResult) {
+	if v.ttl <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.cache) >= maxLinkProbeCacheEntries {
+		now := time.Now()
+		for k, entry := range v.cache {
+			if now.After(entry.expiresAt) {
+				delete(v.cache, k)
+			}
+		}
+		if len(v.cache) >= maxLinkProbeCacheEntries {
+			v.cache = make(map[string]linkProbeCacheEntry)
+		}
+	}
+	v.cache[key] = linkProbeCacheEntry{result: result, expiresAt: time.Now().Add(v.ttl)}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"pansou/model"
+	"pansou/util"
+)
+
+// normalizeMagnetResults 校验并规范化结果中的磁力链接，返回新的切片，不修改传入的结果：
+// 无效的磁力链接被移除，链接全部被移除的结果一并丢弃；只含磁力链接且info-hash均已在之前的结果中出现的结果
+// 视为重复（如多个BT插件返回同一资源）丢弃
+func normalizeMagnetResults(results []model.SearchResult) []model.SearchResult {
+	normalized := make([]model.SearchResult, 0, len(results))
+	seenHashes := make(map[string]bool)
+	for _, result := range results {
+		var links []model.Link
+		var hashes []string
+		onlyMagnets := len(result.Links) > 0
+		for i, link := range result.Links {
+			if !util.IsMagnetURL(link.URL) {
+				onlyMagnets = false
+				if links != nil {
+					links = append(links, link)
+				}
+				continue
+			}
+
+			url, hash, ok := util.NormalizeMagnet(link.URL)
+			if ok && url == link.URL {
+				hashes = append(hashes, hash)
+				if links != nil {
+					links = append(links, link)
+				}
+				continue
+			}
+			if links == nil {
+				links = append(make([]model.Link, 0, len(result.Links)), result.Links[:i]...)
+			}
+			if ok {
+				hashes = append(hashes, hash)
+				link.URL = url
+				links = append(links, link)
+			}
+		}
+		if links != nil {
+			if len(links) == 0 {
+				continue
+			}
+			result.Links = links
+		}
+
+		if onlyMagnets && len(hashes) > 0 && allSeen(seenHashes, hashes) {
+			continue
+		}
+		for _, hash := range hashes {
+			seenHashes[hash] = true
+		}
+		normalized = append(normalized, result)
+	}
+	return normalized
+}
+
+// allSeen 是否所有info-hash都已出现过
+func allSeen(seen map[string]bool, hashes []string) bool {
+	for _, hash := range hashes {
+		if !seen[hash] {
+			return false
+		}
+	}
+	return true
+}
+
+// linkDedupeKey 合并链接时的去重键：磁力链接按info-hash，其他链接按URL
+func linkDedupeKey(url string) string {
+	if util.IsMagnetURL(url) {
+		if _, hash, ok := util.NormalizeMagnet(url); ok {
+			return "btih:" + hash
+		}
+	}
+	return url
+}
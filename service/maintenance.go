@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// MaintenanceStatus 维护模式状态
+type MaintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"` // 搜索响应中的提示，为空时使用默认提示
+	Since   *time.Time `json:"since,omitempty"`   // 进入维护模式的时间
+}
+
+var (
+	maintenanceOnce  sync.Once
+	maintenanceMutex sync.RWMutex
+	maintenanceState MaintenanceStatus
+)
+
+// loadMaintenanceState 首次使用时按MAINTENANCE_MODE和MAINTENANCE_MESSAGE初始化维护模式
+func loadMaintenanceState() {
+	maintenanceOnce.Do(func() {
+		if config.AppConfig == nil {
+			return
+		}
+		maintenanceState.Message = config.AppConfig.MaintenanceMessage
+		if config.AppConfig.MaintenanceMode {
+			now := time.Now()
+			maintenanceState.Enabled = true
+			maintenanceState.Since = &now
+		}
+	})
+}
+
+// IsMaintenanceMode 是否处于维护模式。维护模式下搜索只读取缓存，不请求TG频道和插件，
+// 缓存未命中时返回空结果，也不在后台刷新即将过期的缓存
+func IsMaintenanceMode() bool {
+	loadMaintenanceState()
+	maintenanceMutex.RLock()
+	defer maintenanceMutex.RUnlock()
+	return maintenanceState.Enabled
+}
+
+// GetMaintenanceStatus 获取维护模式状态
+func GetMaintenanceStatus() MaintenanceStatus {
+	loadMaintenanceState()
+	maintenanceMutex.RLock()
+	defer maintenanceMutex.RUnlock()
+	return maintenanceState
+}
+
+// SetMaintenanceMode 运行时进入或退出维护模式，message为nil时保持原提示。
+// 只影响当前进程，重启后恢复MAINTENANCE_MODE的配置
+func SetMaintenanceMode(enabled bool, message *string) MaintenanceStatus {
+	loadMaintenanceState()
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	if message != nil {
+		maintenanceState.Message = *message
+	}
+	switch {
+	case enabled && !maintenanceState.Enabled:
+		now := time.Now()
+		maintenanceState.Since = &now
+		fmt.Printf("[维护模式] 已进入维护模式，搜索只返回缓存结果\n")
+	case !enabled && maintenanceState.Enabled:
+		maintenanceState.Since = nil
+		fmt.Printf("[维护模式] 已退出维护模式\n")
+	}
+	maintenanceState.Enabled = enabled
+	return maintenanceState
+}
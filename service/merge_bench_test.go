@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pansou/model"
+)
+
+// benchSearchResults 生成n条TG和插件混合的搜索结果，每条带两个不同网盘的链接，部分链接在结果间重复
+func benchSearchResults(n int) []model.SearchResult {
+	now := time.Now()
+	results := make([]model.SearchResult, n)
+	for i := range results {
+		channel := ""
+		uniqueID := fmt.Sprintf("plugin-%d", i)
+		if i%2 == 0 {
+			channel = "tgchannel"
+			uniqueID = fmt.Sprintf("tgchannel-%d", i)
+		}
+		results[i] = model.SearchResult{
+			MessageID: fmt.Sprint(i),
+			UniqueID:  uniqueID,
+			Channel:   channel,
+			Datetime:  now.Add(-time.Duration(i) * time.Hour),
+			Title:     fmt.Sprintf("流浪地球2 4K HDR 第%d版", i),
+			Content:   benchMultilineMessage,
+			Links: []model.Link{
+				{Type: "quark", URL: fmt.Sprintf("https://pan.quark.cn/s/%012x", i)},
+				{Type: "baidu", URL: fmt.Sprintf("https://pan.baidu.com/s/1bench%d?pwd=abcd", i%(n/2+1)), Password: "abcd"},
+			},
+		}
+	}
+	return results
+}
+
+// BenchmarkMergeResultsByType 按网盘类型合并不同规模的搜索结果
+func BenchmarkMergeResultsByType(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		results := benchSearchResults(n)
+		b.Run(fmt.Sprintf("results=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				mergeResultsByType(results, "流浪地球", nil)
+			}
+		})
+	}
+}
+
+// BenchmarkExtractLinkTitlePairs 从TG消息中提取链接和标题
+func BenchmarkExtractLinkTitlePairs(b *testing.B) {
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{"multiline", benchMultilineMessage},
+		{"inline", benchInlineMessage},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				extractLinkTitlePairs(tc.content)
+			}
+		})
+	}
+}
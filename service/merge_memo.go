@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// mergeMemoEntry 一次合并排序的结果
+type mergeMemoEntry struct {
+	links   model.MergedLinks
+	expires time.Time
+}
+
+var (
+	mergeMemoMu      sync.Mutex
+	mergeMemoEntries = make(map[uint64]mergeMemoEntry)
+	mergeMemoSeed    = maphash.MakeSeed()
+
+	mergeMemoHits   int64
+	mergeMemoMisses int64
+)
+
+// mergeAndSortLinks 合并链接并按综合得分排序（mergeResultsByTypeWithKeywords + sortMergedLinks）。
+// 热门关键词的结果来自缓存，每次请求的合并输入相同，因此按输入内容和合并选项短期缓存合并结果，
+// 返回的是缓存的副本，调用方可以修改
+func mergeAndSortLinks(results []model.SearchResult, keywords []string, cloudTypes []string, highlightTerms []string, preferredLangs map[string]bool) model.MergedLinks {
+	ttl := config.AppConfig.MergeMemoTTL
+	if ttl <= 0 {
+		mergedLinks := mergeResultsByTypeWithKeywords(results, keywords, cloudTypes, highlightTerms)
+		sortMergedLinks(mergedLinks, preferredLangs)
+		return mergedLinks
+	}
+
+	key := mergeMemoKey(results, keywords, cloudTypes, highlightTerms, preferredLangs)
+	now := time.Now()
+	mergeMemoMu.Lock()
+	entry, ok := mergeMemoEntries[key]
+	mergeMemoMu.Unlock()
+	if ok && now.Before(entry.expires) {
+		atomic.AddInt64(&mergeMemoHits, 1)
+		return cloneMergedLinks(entry.links)
+	}
+	atomic.AddInt64(&mergeMemoMisses, 1)
+
+	mergedLinks := mergeResultsByTypeWithKeywords(results, keywords, cloudTypes, highlightTerms)
+	sortMergedLinks(mergedLinks, preferredLangs)
+
+	mergeMemoMu.Lock()
+	if len(mergeMemoEntries) >= config.AppConfig.MergeMemoMaxEntries {
+		for k, e := range mergeMemoEntries {
+			if !now.Before(e.expires) {
+				delete(mergeMemoEntries, k)
+			}
+		}
+		// 仍然已满时清空，下一轮请求重新填充热门关键词
+		if len(mergeMemoEntries) >= config.AppConfig.MergeMemoMaxEntries {
+			mergeMemoEntries = make(map[uint64]mergeMemoEntry)
+		}
+	}
+	mergeMemoEntries[key] = mergeMemoEntry{links: cloneMergedLinks(mergedLinks), expires: now.Add(ttl)}
+	mergeMemoMu.Unlock()
+	return mergedLinks
+}
+
+// mergeMemoKey 按合并用到的结果字段和合并选项计算缓存键。结果内容变化（如异步插件更新了缓存）时键随之变化
+func mergeMemoKey(results []model.SearchResult, keywords []string, cloudTypes []string, highlightTerms []string, preferredLangs map[string]bool) uint64 {
+	var h maphash.Hash
+	h.SetSeed(mergeMemoSeed)
+	var buf [8]byte
+	writeInt := func(n int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		h.WriteString(s)
+		h.WriteByte(0)
+	}
+	writeStrings := func(values []string) {
+		writeInt(int64(len(values)))
+		for _, s := range values {
+			writeString(s)
+		}
+	}
+
+	writeStrings(keywords)
+	writeStrings(cloudTypes)
+	writeStrings(highlightTerms)
+	langs := make([]string, 0, len(preferredLangs))
+	for lang, preferred := range preferredLangs {
+		if preferred {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	writeStrings(langs)
+
+	writeInt(int64(len(results)))
+	for _, result := range results {
+		writeString(result.UniqueID)
+		writeString(result.Channel)
+		writeString(result.Title)
+		writeString(result.Content)
+		writeString(result.Language)
+		writeInt(result.Datetime.UnixNano())
+		writeStrings(result.Images)
+		writeInt(int64(len(result.Links)))
+		for _, link := range result.Links {
+			writeString(link.Type)
+			writeString(link.URL)
+			writeString(link.Password)
+			writeString(link.SourceNote)
+			writeInt(link.Size)
+			writeInt(int64(link.FileCount))
+			if link.ExpiresAt != nil {
+				writeInt(link.ExpiresAt.UnixNano())
+			} else {
+				writeInt(0)
+			}
+		}
+	}
+	return h.Sum64()
+}
+
+// cloneMergedLinks 复制每种网盘类型的链接切片，后续的截断、分配跳转ID、可信度处理等只修改副本
+func cloneMergedLinks(mergedLinks model.MergedLinks) model.MergedLinks {
+	clone := make(model.MergedLinks, len(mergedLinks))
+	for linkType, links := range mergedLinks {
+		clone[linkType] = append([]model.MergedLink(nil), links...)
+	}
+	return clone
+}
+
+// MergeMemoStats 合并结果缓存的统计
+type MergeMemoStats struct {
+	Entries  int     `json:"entries"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// GetMergeMemoStats 获取合并结果缓存的条目数和命中统计
+func GetMergeMemoStats() MergeMemoStats {
+	mergeMemoMu.Lock()
+	stats := MergeMemoStats{Entries: len(mergeMemoEntries)}
+	mergeMemoMu.Unlock()
+	stats.Hits = atomic.LoadInt64(&mergeMemoHits)
+	stats.Misses = atomic.LoadInt64(&mergeMemoMisses)
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"sort"
+
+	"pansou/model"
+)
+
+// sortMergedLinks 按综合得分（见scoreResult）对每种网盘类型的链接排序：时间得分按链接标题推断的内容分类计算，
+// 优先关键词按链接标题匹配，插件等级按链接来源计算。得分相同时较新的链接在前，时间也相同时保持原有顺序
+func sortMergedLinks(mergedLinks model.MergedLinks, preferredLangs map[string]bool) {
+	profile := GetRankingProfile()
+	for _, links := range mergedLinks {
+		if len(links) < 2 {
+			continue
+		}
+		scores := make([]float64, len(links))
+		for i, link := range links {
+			result := model.SearchResult{Title: link.Note, Datetime: link.Datetime, Language: link.Language}
+			scores[i] = scoreResult(profile, result, link.Source, preferredLangs).TotalScore
+		}
+
+		order := make([]int, len(links))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := order[i], order[j]
+			if scores[a] != scores[b] {
+				return scores[a] > scores[b]
+			}
+			return links[a].Datetime.After(links[b].Datetime)
+		})
+
+		sorted := make([]model.MergedLink, len(links))
+		for i, index := range order {
+			sorted[i] = links[index]
+		}
+		copy(links, sorted)
+	}
+}
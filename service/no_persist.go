@@ -0,0 +1,85 @@
+package service
+
+import (
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util/cache"
+)
+
+var (
+	noPersistSources     map[string]bool
+	noPersistSourcesOnce sync.Once
+)
+
+// getNoPersistSources 获取NO_PERSIST_SOURCES配置的来源集合（小写，如plugin:xxx、tg:频道名）
+func getNoPersistSources() map[string]bool {
+	noPersistSourcesOnce.Do(func() {
+		noPersistSources = make(map[string]bool)
+		if config.AppConfig == nil {
+			return
+		}
+		for _, source := range config.AppConfig.NoPersistSources {
+			noPersistSources[source] = true
+		}
+	})
+	return noPersistSources
+}
+
+// isNoPersistSource 来源（getResultSource的返回值）的结果是否不允许写入磁盘缓存和持久化存储
+func isNoPersistSource(source string) bool {
+	sources := getNoPersistSources()
+	return len(sources) > 0 && sources[strings.ToLower(source)]
+}
+
+// isNoPersistPlugin 插件的结果是否不允许持久化
+func isNoPersistPlugin(name string) bool {
+	return isNoPersistSource("plugin:" + name)
+}
+
+// persistableResults 去除不允许持久化的来源的结果，没有需要去除的结果时返回原切片和false
+func persistableResults(results []model.SearchResult) ([]model.SearchResult, bool) {
+	if len(getNoPersistSources()) == 0 {
+		return results, false
+	}
+	kept := make([]model.SearchResult, 0, len(results))
+	for _, result := range results {
+		if !isNoPersistSource(getResultSource(result)) {
+			kept = append(kept, result)
+		}
+	}
+	if len(kept) == len(results) {
+		return results, false
+	}
+	return kept, true
+}
+
+// installNoPersistFilter 配置了NO_PERSIST_SOURCES时设置磁盘写入过滤：搜索结果写入磁盘缓存和预写日志前
+// 去除这些来源的结果，内存缓存仍保存全部结果；去除后没有结果时不写入磁盘，下次未命中内存缓存时重新搜索
+func installNoPersistFilter(mainCache *cache.EnhancedTwoLevelCache) {
+	if mainCache == nil || len(getNoPersistSources()) == 0 {
+		return
+	}
+	cache.SetDiskWriteFilter(func(key string, data []byte) []byte {
+		serializer := mainCache.GetSerializer()
+		var results []model.SearchResult
+		// 不是搜索结果的缓存（链接反查记录、快照等）原样写入
+		if err := serializer.Deserialize(data, &results); err != nil {
+			return data
+		}
+		kept, filtered := persistableResults(results)
+		if !filtered {
+			return data
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		filteredData, err := serializer.Serialize(kept)
+		if err != nil {
+			return nil
+		}
+		return filteredData
+	})
+}
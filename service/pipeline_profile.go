@@ -0,0 +1,57 @@
+package service
+
+import (
+	"runtime"
+	"time"
+
+	"pansou/model"
+)
+
+// PipelineProfile 搜索管线中单个步骤每次调用的耗时和内存分配
+type PipelineProfile struct {
+	Name        string  `json:"name"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     int64   `json:"ns_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+}
+
+// ProfilePipeline 用给定的搜索结果重复执行结果合并和链接标题提取，统计每次调用的耗时和内存分配。
+// mergeResultsByType每次处理全部结果（链接标题使用缓存，与线上稳定状态一致），
+// extractLinkTitlePairs每次处理一条消息。供压测工具评估性能改动，不应在服务运行时调用
+func ProfilePipeline(results []model.SearchResult, keyword string, iterations int) []PipelineProfile {
+	if len(results) == 0 || iterations <= 0 {
+		return nil
+	}
+	return []PipelineProfile{
+		profileStep("mergeResultsByType", iterations, func(int) {
+			mergeResultsByType(results, keyword, nil)
+		}),
+		profileStep("extractLinkTitlePairs", iterations, func(i int) {
+			extractLinkTitlePairs(results[i%len(results)].Content)
+		}),
+	}
+}
+
+// profileStep 预热后执行iterations次fn，按MemStats差值计算每次调用的分配
+func profileStep(name string, iterations int, fn func(int)) PipelineProfile {
+	fn(0)
+	runtime.GC()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		fn(i)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return PipelineProfile{
+		Name:        name,
+		Iterations:  iterations,
+		NsPerOp:     elapsed.Nanoseconds() / int64(iterations),
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(iterations),
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(iterations),
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"pansou/plugin"
+	"pansou/util"
+)
+
+// ErrUnknownPlugin 插件未注册
+var ErrUnknownPlugin = errors.New("插件不存在")
+
+// PluginStatus 插件在管理后台展示的状态
+type PluginStatus struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Priority    int    `json:"priority"`
+	Enabled     bool   `json:"enabled"`
+	Quarantined bool   `json:"quarantined"`
+	Degraded    bool   `json:"degraded"` // 依赖的站点均已失效，暂停搜索
+	Panics      int64  `json:"panics"`   // 累计panic次数
+	// Schedule 配置的启用时段，为空表示始终启用
+	Schedule []string `json:"schedule,omitempty"`
+	// OutOfSchedule 当前不在启用时段，暂不参与搜索
+	OutOfSchedule bool `json:"out_of_schedule"`
+}
+
+// PluginStatuses 获取所有已注册插件的启用、隔离、降级、启用时段和panic状态，按插件等级和名称排序
+func (s *SearchService) PluginStatuses() []PluginStatus {
+	enabled := make(map[string]bool)
+	if s.pluginManager != nil {
+		for _, p := range s.pluginManager.GetPlugins() {
+			enabled[p.Name()] = true
+		}
+	}
+	panics := make(map[string]int64)
+	for _, health := range plugin.GetPluginHealth() {
+		panics[health.Name] = health.Panics
+	}
+
+	degraded := make(map[string]bool)
+	for _, status := range util.GetDegradedPlugins() {
+		degraded[status.Name] = true
+	}
+
+	now := time.Now()
+	registered := plugin.GetRegisteredPlugins()
+	statuses := make([]PluginStatus, 0, len(registered))
+	for _, p := range registered {
+		meta := plugin.GetPluginMetadata(p)
+		statuses = append(statuses, PluginStatus{
+			Name:        p.Name(),
+			DisplayName: meta.DisplayName,
+			Priority:    p.Priority(),
+			Enabled:     enabled[p.Name()],
+			Quarantined: plugin.IsPluginQuarantined(p.Name()),
+			Degraded:    degraded[p.Name()],
+			Panics:      panics[p.Name()],
+
+			Schedule:      plugin.GetPluginSchedule(p.Name()),
+			OutOfSchedule: !plugin.IsPluginInSchedule(p.Name(), now),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Priority != statuses[j].Priority {
+			return statuses[i].Priority < statuses[j].Priority
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// SetPluginEnabled 运行时启用或停用已注册的插件，只影响当前进程，重启后恢复ENABLED_PLUGINS的配置
+func (s *SearchService) SetPluginEnabled(name string, enabled bool) error {
+	p, ok := plugin.GetPluginByName(name)
+	if !ok {
+		return ErrUnknownPlugin
+	}
+	if s.pluginManager == nil {
+		return fmt.Errorf("插件管理器未初始化")
+	}
+
+	if !enabled {
+		if s.pluginManager.RemovePlugin(name) {
+			fmt.Printf("[插件管理] 已停用插件: %s\n", name)
+		}
+		return nil
+	}
+
+	s.pluginManager.RegisterPlugin(p)
+	if mainCache := enhancedTwoLevelCache; mainCache != nil {
+		injectCacheUpdaterToPlugin(p, newPluginCacheUpdater(mainCache))
+	}
+	fmt.Printf("[插件管理] 已启用插件: %s\n", name)
+	return nil
+}
+
+// ResetPluginState 清除已注册插件的内部缓存（见plugin.AsyncSearchPlugin.ResetState）和零结果统计，插件停用时同样可以清除
+func (s *SearchService) ResetPluginState(name string) error {
+	p, ok := plugin.GetPluginByName(name)
+	if !ok {
+		return ErrUnknownPlugin
+	}
+	p.ResetState()
+	GetPluginZeroHits().Reset(p.Name())
+	fmt.Printf("[插件管理] 已清除插件内部缓存: %s\n", name)
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"pansou/config"
+	"pansou/plugin"
+)
+
+// 插件API响应缓存快照在主缓存中的键
+const pluginCacheSnapshotKey = "pansou:plugin_api_cache_snapshot"
+
+// 快照的有效期，超过后不再恢复
+const pluginCacheSnapshotTTL = 2 * time.Hour
+
+// SavePluginCacheSnapshot 将插件API响应缓存中最近使用的条目保存到主缓存（同步写入磁盘），在关闭服务时调用
+func SavePluginCacheSnapshot() error {
+	if !config.AppConfig.PluginCacheSnapshotEnabled {
+		return nil
+	}
+	mainCache := GetEnhancedTwoLevelCache()
+	if mainCache == nil {
+		return fmt.Errorf("主缓存未初始化")
+	}
+
+	data, count, err := plugin.SnapshotAPIResponseCache(config.AppConfig.PluginCacheSnapshotMax, isNoPersistPlugin)
+	if err != nil {
+		return err
+	}
+	if err := mainCache.SetBothLevels(pluginCacheSnapshotKey, data, pluginCacheSnapshotTTL); err != nil {
+		return err
+	}
+	fmt.Printf("[插件缓存] 已保存 %d 条插件缓存快照\n", count)
+	return nil
+}
+
+// RestorePluginCacheSnapshot 启动时从主缓存恢复插件API响应缓存，避免重启后所有插件同时在后台刷新
+func RestorePluginCacheSnapshot() {
+	if !config.AppConfig.PluginCacheSnapshotEnabled {
+		return
+	}
+	mainCache := GetEnhancedTwoLevelCache()
+	if mainCache == nil {
+		return
+	}
+
+	data, hit, err := mainCache.GetFromDisk(pluginCacheSnapshotKey)
+	if err != nil || !hit {
+		return
+	}
+	restored, err := plugin.RestoreAPIResponseCache(data)
+	if err != nil {
+		fmt.Printf("[插件缓存] 恢复插件缓存快照失败: %v\n", err)
+		return
+	}
+	fmt.Printf("[插件缓存] 已恢复 %d 条插件缓存\n", restored)
+}
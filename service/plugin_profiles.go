@@ -0,0 +1,89 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"pansou/config"
+	"pansou/plugin"
+)
+
+// PluginProfile 插件组合及其展开后的插件
+type PluginProfile struct {
+	Name      string   `json:"name"`
+	Selectors []string `json:"selectors"` // 配置的选择器，见PLUGIN_PROFILES
+	Plugins   []string `json:"plugins"`   // 已加载的插件中匹配的插件，按名称排序
+}
+
+// hasPluginProfile 是否配置了该插件组合
+func hasPluginProfile(name string) bool {
+	_, ok := config.AppConfig.PluginProfiles[name]
+	return ok
+}
+
+// PluginProfiles 获取配置的插件组合，按名称排序
+func (s *SearchService) PluginProfiles() []PluginProfile {
+	profiles := make([]PluginProfile, 0, len(config.AppConfig.PluginProfiles))
+	for name, selectors := range config.AppConfig.PluginProfiles {
+		profiles = append(profiles, PluginProfile{
+			Name:      name,
+			Selectors: selectors,
+			Plugins:   s.expandPluginProfile(name),
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// expandPluginProfile 将插件组合展开为已加载插件中匹配任一选择器的插件名，按名称排序
+func (s *SearchService) expandPluginProfile(name string) []string {
+	selectors := config.AppConfig.PluginProfiles[name]
+	names := []string{}
+	if s.pluginManager == nil {
+		return names
+	}
+	for _, p := range s.pluginManager.GetPlugins() {
+		for _, selector := range selectors {
+			if pluginMatchesSelector(p, selector) {
+				names = append(names, p.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginMatchesSelector 插件是否匹配组合的选择器：all、level:最低-最高、type:网盘类型、category:内容分类，其余视为插件名
+func pluginMatchesSelector(p plugin.AsyncSearchPlugin, selector string) bool {
+	kind, value, ok := strings.Cut(selector, ":")
+	if !ok {
+		return selector == "all" || selector == strings.ToLower(p.Name())
+	}
+	switch kind {
+	case "level":
+		minStr, maxStr, isRange := strings.Cut(value, "-")
+		if !isRange {
+			maxStr = minStr
+		}
+		min, err1 := strconv.Atoi(strings.TrimSpace(minStr))
+		max, err2 := strconv.Atoi(strings.TrimSpace(maxStr))
+		return err1 == nil && err2 == nil && p.Priority() >= min && p.Priority() <= max
+	case "type":
+		for _, t := range plugin.GetPluginMetadata(p).CloudTypes {
+			if t == value {
+				return true
+			}
+		}
+	case "category":
+		for _, c := range getPluginCategories(p) {
+			if c == value {
+				return true
+			}
+		}
+	}
+	return false
+}
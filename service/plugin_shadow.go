@@ -0,0 +1,280 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+)
+
+// 每对插件保留的最近对比记录数
+const shadowRecentSize = 20
+
+// ShadowComparison 一次影子对比：同一关键词下线上插件和候选插件的结果数、链接数和链接重合度
+type ShadowComparison struct {
+	Time             time.Time `json:"time"`
+	RequestID        string    `json:"request_id,omitempty"`
+	Plugin           string    `json:"plugin"`
+	Candidate        string    `json:"candidate"`
+	Keyword          string    `json:"keyword"`
+	LiveResults      int       `json:"live_results"` // 有链接的结果数
+	LiveLinks        int       `json:"live_links"`   // 按URL去重的链接数
+	LiveMs           int64     `json:"live_ms"`
+	LiveError        string    `json:"live_error,omitempty"`
+	CandidateResults int       `json:"candidate_results"`
+	CandidateLinks   int       `json:"candidate_links"`
+	CandidateMs      int64     `json:"candidate_ms"`
+	CandidateError   string    `json:"candidate_error,omitempty"`
+	SharedLinks      int       `json:"shared_links"` // 两者都返回的链接数
+	Overlap          float64   `json:"overlap"`      // 共同链接占两者链接并集的比例，两者都没有链接时为1
+}
+
+// ShadowPairStats 一对线上插件和候选插件的累计对比统计
+type ShadowPairStats struct {
+	Plugin          string             `json:"plugin"`
+	Candidate       string             `json:"candidate"`
+	Comparisons     int64              `json:"comparisons"`
+	Skipped         int64              `json:"skipped"` // 候选插件搜索已达SHADOW_CONCURRENCY而跳过的对比
+	LiveErrors      int64              `json:"live_errors"`
+	CandidateErrors int64              `json:"candidate_errors"`
+	LiveLinks       int64              `json:"live_links"`
+	CandidateLinks  int64              `json:"candidate_links"`
+	SharedLinks     int64              `json:"shared_links"`
+	AvgOverlap      float64            `json:"avg_overlap"` // 各次对比重合度的平均值
+	LiveAvgMs       int64              `json:"live_avg_ms"`
+	CandidateAvgMs  int64              `json:"candidate_avg_ms"`
+	Recent          []ShadowComparison `json:"recent"` // 最近的对比，新的在前
+}
+
+// shadowPair 线上插件对应的候选插件及累计统计
+type shadowPair struct {
+	candidate    plugin.AsyncSearchPlugin
+	stats        ShadowPairStats
+	overlapSum   float64
+	liveMsSum    int64
+	candidateSum int64
+}
+
+// PluginShadowService 插件影子测试：按SHADOW_SAMPLE_RATE抽取线上插件完成的搜索，在后台用相同的关键词和ext
+// 调用候选插件（如重写了解析逻辑的新版本），对比两者的结果数和链接重合度。候选插件的结果不返回给用户，也不写入缓存
+type PluginShadowService struct {
+	mu    sync.Mutex
+	pairs map[string]*shadowPair // 线上插件名（小写） -> 候选插件
+	sem   chan struct{}
+}
+
+var globalPluginShadows *PluginShadowService
+
+// SetupPluginShadows 按SHADOW_PLUGINS配置候选插件，需在创建搜索服务之前调用。
+// 候选插件须已在全局注册表中（编译进程序），若被ENABLED_PLUGINS或插件预设启用则从线上插件中移除
+func SetupPluginShadows(pluginManager *plugin.PluginManager) {
+	if len(config.AppConfig.ShadowPlugins) == 0 || pluginManager == nil {
+		return
+	}
+	s := &PluginShadowService{
+		pairs: make(map[string]*shadowPair),
+		sem:   make(chan struct{}, config.AppConfig.ShadowConcurrency),
+	}
+	live := make(map[string]string)
+	for _, p := range pluginManager.GetPlugins() {
+		live[strings.ToLower(p.Name())] = p.Name()
+	}
+	for name, candidateName := range config.AppConfig.ShadowPlugins {
+		candidate, ok := plugin.GetPluginByName(candidateName)
+		if !ok {
+			fmt.Printf("[影子测试] 候选插件 %s 未注册，跳过\n", candidateName)
+			continue
+		}
+		if pluginManager.RemovePlugin(candidate.Name()) {
+			fmt.Printf("[影子测试] 候选插件 %s 不参与线上搜索\n", candidate.Name())
+		}
+		liveName, enabled := live[name]
+		if !enabled {
+			fmt.Printf("[影子测试] 线上插件 %s 未启用，候选插件 %s 不会执行\n", name, candidate.Name())
+			liveName = name
+		}
+		s.pairs[name] = &shadowPair{
+			candidate: candidate,
+			stats:     ShadowPairStats{Plugin: liveName, Candidate: candidate.Name(), Recent: []ShadowComparison{}},
+		}
+		fmt.Printf("[影子测试] %s 的 %.4g%% 搜索将同时由 %s 执行并对比结果\n", liveName, config.AppConfig.ShadowSampleRate, candidate.Name())
+	}
+	if len(s.pairs) > 0 {
+		globalPluginShadows = s
+	}
+}
+
+// GetPluginShadowStats 获取各对插件的对比统计，按线上插件名排序；未配置影子测试时为空
+func GetPluginShadowStats() []ShadowPairStats {
+	stats := []ShadowPairStats{}
+	s := globalPluginShadows
+	if s == nil {
+		return stats
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pair := range s.pairs {
+		item := pair.stats
+		item.Recent = append([]ShadowComparison(nil), pair.stats.Recent...)
+		if item.Comparisons > 0 {
+			item.AvgOverlap = pair.overlapSum / float64(item.Comparisons)
+			item.LiveAvgMs = pair.liveMsSum / item.Comparisons
+			item.CandidateAvgMs = pair.candidateSum / item.Comparisons
+		}
+		stats = append(stats, item)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Plugin < stats[j].Plugin
+	})
+	return stats
+}
+
+// shadowPluginSearch 线上插件完成一次搜索后调用：按采样比例在后台让候选插件搜索同一关键词并对比结果。
+// 候选插件搜索数已达上限时跳过本次对比，不阻塞线上搜索
+func shadowPluginSearch(requestID string, live plugin.AsyncSearchPlugin, keyword string, ext map[string]interface{}, results []model.SearchResult, err error, elapsed time.Duration) {
+	s := globalPluginShadows
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	pair, ok := s.pairs[strings.ToLower(live.Name())]
+	s.mu.Unlock()
+	if !ok || rand.Float64()*100 >= config.AppConfig.ShadowSampleRate {
+		return
+	}
+	select {
+	case s.sem <- struct{}{}:
+	default:
+		s.mu.Lock()
+		pair.stats.Skipped++
+		s.mu.Unlock()
+		return
+	}
+
+	// 复制ext，避免与线上搜索共享
+	candidateExt := make(map[string]interface{}, len(ext))
+	for k, v := range ext {
+		candidateExt[k] = v
+	}
+	go func() {
+		defer func() { <-s.sem }()
+		start := time.Now()
+		candidateResults, candidateErr := searchShadowCandidate(pair.candidate, keyword, candidateExt)
+		comparison := compareShadowResults(results, candidateResults)
+		comparison.Time = time.Now()
+		comparison.RequestID = requestID
+		comparison.Plugin = live.Name()
+		comparison.Candidate = pair.candidate.Name()
+		comparison.Keyword = keyword
+		comparison.LiveMs = elapsed.Milliseconds()
+		comparison.CandidateMs = time.Since(start).Milliseconds()
+		if err != nil {
+			comparison.LiveError = err.Error()
+		}
+		if candidateErr != nil {
+			comparison.CandidateError = candidateErr.Error()
+		}
+		s.record(pair, comparison)
+	}()
+}
+
+// searchShadowCandidate 调用候选插件的Search，panic转为错误
+func searchShadowCandidate(candidate plugin.AsyncSearchPlugin, keyword string, ext map[string]interface{}) (results []model.SearchResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("候选插件 %s 搜索发生panic: %v", candidate.Name(), r)
+		}
+	}()
+	return candidate.Search(keyword, ext)
+}
+
+// compareShadowResults 统计两组结果中有链接的结果数、按URL去重的链接数和共同链接数
+func compareShadowResults(live, candidate []model.SearchResult) ShadowComparison {
+	liveLinks := make(map[string]bool)
+	comparison := ShadowComparison{}
+	for _, result := range live {
+		if len(result.Links) == 0 {
+			continue
+		}
+		comparison.LiveResults++
+		for _, link := range result.Links {
+			liveLinks[normalizeUrl(link.URL)] = true
+		}
+	}
+	candidateLinks := make(map[string]bool)
+	for _, result := range candidate {
+		if len(result.Links) == 0 {
+			continue
+		}
+		comparison.CandidateResults++
+		for _, link := range result.Links {
+			url := normalizeUrl(link.URL)
+			if !candidateLinks[url] && liveLinks[url] {
+				comparison.SharedLinks++
+			}
+			candidateLinks[url] = true
+		}
+	}
+	comparison.LiveLinks = len(liveLinks)
+	comparison.CandidateLinks = len(candidateLinks)
+
+	union := comparison.LiveLinks + comparison.CandidateLinks - comparison.SharedLinks
+	comparison.Overlap = 1
+	if union > 0 {
+		comparison.Overlap = float64(comparison.SharedLinks) / float64(union)
+	}
+	return comparison
+}
+
+// record 累计对比结果，输出日志并追加到SHADOW_LOG_FILE
+func (s *PluginShadowService) record(pair *shadowPair, c ShadowComparison) {
+	s.mu.Lock()
+	stats := &pair.stats
+	stats.Comparisons++
+	if c.LiveError != "" {
+		stats.LiveErrors++
+	}
+	if c.CandidateError != "" {
+		stats.CandidateErrors++
+	}
+	stats.LiveLinks += int64(c.LiveLinks)
+	stats.CandidateLinks += int64(c.CandidateLinks)
+	stats.SharedLinks += int64(c.SharedLinks)
+	pair.overlapSum += c.Overlap
+	pair.liveMsSum += c.LiveMs
+	pair.candidateSum += c.CandidateMs
+	stats.Recent = append([]ShadowComparison{c}, stats.Recent...)
+	if len(stats.Recent) > shadowRecentSize {
+		stats.Recent = stats.Recent[:shadowRecentSize]
+	}
+	s.mu.Unlock()
+
+	fmt.Printf("[影子测试] %s -> %s | 关键词: %s | 链接: %d -> %d，共同 %d（重合度 %.0f%%）| 耗时: %dms -> %dms%s\n",
+		c.Plugin, c.Candidate, c.Keyword, c.LiveLinks, c.CandidateLinks, c.SharedLinks, c.Overlap*100, c.LiveMs, c.CandidateMs, shadowErrorNote(c))
+	if file := config.AppConfig.ShadowLogFile; file != "" {
+		if err := appendJSONLine(file, c); err != nil {
+			fmt.Printf("[影子测试] 写入对比记录失败: %v\n", err)
+		}
+	}
+}
+
+// shadowErrorNote 对比日志中的错误说明
+func shadowErrorNote(c ShadowComparison) string {
+	var notes []string
+	if c.LiveError != "" {
+		notes = append(notes, "线上插件出错: "+c.LiveError)
+	}
+	if c.CandidateError != "" {
+		notes = append(notes, "候选插件出错: "+c.CandidateError)
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " | " + strings.Join(notes, "；")
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	jsonutil "pansou/util/json"
+)
+
+// 插件统计落盘间隔
+const pluginStatsSaveInterval = time.Minute
+
+// 插件统计保留的天数，更早的按日统计在落盘时清理
+const pluginStatsRetentionDays = 30
+
+// 按日统计使用的日期格式（本地时区）
+const pluginStatsDayLayout = "2006-01-02"
+
+// PluginDayStats 插件一天内的搜索统计
+type PluginDayStats struct {
+	Date           string `json:"date"`
+	Searches       int64  `json:"searches"`         // 完成的搜索次数（含插件缓存命中）
+	Errors         int64  `json:"errors"`           // 返回错误或发生panic的次数
+	Timeouts       int64  `json:"timeouts"`         // 超出延迟预算、结果未被采用的次数
+	Results        int64  `json:"results"`          // 返回的结果总数
+	TotalLatencyMs int64  `json:"total_latency_ms"` // 完成的搜索累计耗时
+}
+
+// PluginStatsSummary 插件在统计窗口内的汇总及按日统计
+type PluginStatsSummary struct {
+	Name         string           `json:"name"`
+	Searches     int64            `json:"searches"`
+	Errors       int64            `json:"errors"`
+	Timeouts     int64            `json:"timeouts"`
+	Results      int64            `json:"results"`
+	AvgLatencyMs float64          `json:"avg_latency_ms"`
+	ErrorRate    float64          `json:"error_rate"`             // 错误次数占完成搜索次数的比例
+	LastSuccess  *time.Time       `json:"last_success,omitempty"` // 最近一次成功搜索的时间，不受统计窗口限制
+	Daily        []PluginDayStats `json:"daily"`                  // 按日期升序
+}
+
+// pluginStatsEntry 单个插件的统计
+type pluginStatsEntry struct {
+	Days        map[string]*PluginDayStats `json:"days"`
+	LastSuccess time.Time                  `json:"last_success"`
+}
+
+// PluginStatsService 插件搜索统计：按插件、按日累计搜索次数、错误、超时和耗时，定期落盘，重启后继续累计
+type PluginStatsService struct {
+	mu      sync.Mutex
+	plugins map[string]*pluginStatsEntry
+
+	dataFile string
+	dirty    bool
+}
+
+var (
+	globalPluginStats *PluginStatsService
+	pluginStatsOnce   sync.Once
+)
+
+// GetPluginStats 获取全局插件统计服务
+func GetPluginStats() *PluginStatsService {
+	pluginStatsOnce.Do(func() {
+		globalPluginStats = NewPluginStatsService("data/plugin_stats.json")
+	})
+	return globalPluginStats
+}
+
+// NewPluginStatsService 创建插件统计服务，dataFile为空时不持久化
+func NewPluginStatsService(dataFile string) *PluginStatsService {
+	s := &PluginStatsService{
+		plugins:  make(map[string]*pluginStatsEntry),
+		dataFile: dataFile,
+	}
+
+	if dataFile != "" {
+		s.load()
+		go s.saveLoop()
+	}
+
+	return s
+}
+
+// day 获取插件当天的统计（调用方需持有锁）
+func (s *PluginStatsService) day(name string, now time.Time) (*pluginStatsEntry, *PluginDayStats) {
+	entry, ok := s.plugins[name]
+	if !ok {
+		entry = &pluginStatsEntry{Days: make(map[string]*PluginDayStats)}
+		s.plugins[name] = entry
+	}
+	date := now.Format(pluginStatsDayLayout)
+	stats, ok := entry.Days[date]
+	if !ok {
+		stats = &PluginDayStats{Date: date}
+		entry.Days[date] = stats
+	}
+	s.dirty = true
+	return entry, stats
+}
+
+// RecordSearch 记录插件完成的一次搜索，err不为nil时计为错误
+func (s *PluginStatsService) RecordSearch(name string, latency time.Duration, results int, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, stats := s.day(name, now)
+	stats.Searches++
+	stats.TotalLatencyMs += latency.Milliseconds()
+	if err != nil {
+		stats.Errors++
+		return
+	}
+	stats.Results += int64(results)
+	entry.LastSuccess = now
+}
+
+// RecordTimeout 记录插件超出延迟预算，本次搜索的结果未被采用
+func (s *PluginStatsService) RecordTimeout(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, stats := s.day(name, time.Now())
+	stats.Timeouts++
+}
+
+// Summaries 获取最近days天（含当天）各插件的汇总及按日统计，按搜索次数倒序
+func (s *PluginStatsService) Summaries(days int) []PluginStatsSummary {
+	if days <= 0 || days > pluginStatsRetentionDays {
+		days = pluginStatsRetentionDays
+	}
+	since := time.Now().AddDate(0, 0, -(days - 1)).Format(pluginStatsDayLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]PluginStatsSummary, 0, len(s.plugins))
+	for name, entry := range s.plugins {
+		summary := PluginStatsSummary{Name: name, Daily: []PluginDayStats{}}
+		var totalLatencyMs int64
+		for date, stats := range entry.Days {
+			if date < since {
+				continue
+			}
+			summary.Searches += stats.Searches
+			summary.Errors += stats.Errors
+			summary.Timeouts += stats.Timeouts
+			summary.Results += stats.Results
+			totalLatencyMs += stats.TotalLatencyMs
+			summary.Daily = append(summary.Daily, *stats)
+		}
+		sort.Slice(summary.Daily, func(i, j int) bool {
+			return summary.Daily[i].Date < summary.Daily[j].Date
+		})
+		if summary.Searches > 0 {
+			summary.AvgLatencyMs = float64(totalLatencyMs) / float64(summary.Searches)
+			summary.ErrorRate = float64(summary.Errors) / float64(summary.Searches)
+		}
+		if !entry.LastSuccess.IsZero() {
+			lastSuccess := entry.LastSuccess
+			summary.LastSuccess = &lastSuccess
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Searches != summaries[j].Searches {
+			return summaries[i].Searches > summaries[j].Searches
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// prune 清理超出保留天数的按日统计（调用方需持有锁）
+func (s *PluginStatsService) prune(now time.Time) {
+	oldest := now.AddDate(0, 0, -(pluginStatsRetentionDays - 1)).Format(pluginStatsDayLayout)
+	for name, entry := range s.plugins {
+		for date := range entry.Days {
+			if date < oldest {
+				delete(entry.Days, date)
+			}
+		}
+		if len(entry.Days) == 0 && entry.LastSuccess.IsZero() {
+			delete(s.plugins, name)
+		}
+	}
+}
+
+// Flush 将插件统计写入磁盘
+func (s *PluginStatsService) Flush() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	s.prune(time.Now())
+	data, err := jsonutil.MarshalIndent(s.plugins, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("插件统计序列化失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.dataFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.dataFile, data, 0644)
+}
+
+// load 从磁盘加载插件统计
+func (s *PluginStatsService) load() {
+	data, err := os.ReadFile(s.dataFile)
+	if err != nil {
+		return
+	}
+	var stored map[string]*pluginStatsEntry
+	if err := jsonutil.Unmarshal(data, &stored); err != nil {
+		fmt.Printf("[插件统计] 加载失败: %v\n", err)
+		return
+	}
+	for name, entry := range stored {
+		if entry == nil {
+			continue
+		}
+		if entry.Days == nil {
+			entry.Days = make(map[string]*PluginDayStats)
+		}
+		s.plugins[name] = entry
+	}
+}
+
+// saveLoop 定期落盘
+func (s *PluginStatsService) saveLoop() {
+	ticker := time.NewTicker(pluginStatsSaveInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.Flush(); err != nil {
+			fmt.Printf("[插件统计] 保存失败: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/plugin"
+	"pansou/util"
+)
+
+// WarmPluginConnections 启动时在后台为已启用插件的站点预先解析域名并建立连接，减少第一次搜索的
+// DNS和TLS握手耗时。并发数受PLUGIN_WARMUP_CONCURRENCY限制，每个插件最多等待PLUGIN_WARMUP_TIMEOUT，
+// 失败只记录日志。站点地址取自插件元数据的Homepage，未声明站点的插件跳过
+func WarmPluginConnections(pluginManager *plugin.PluginManager) {
+	if !config.AppConfig.PluginWarmupEnabled || !config.AppConfig.AsyncPluginEnabled || pluginManager == nil {
+		return
+	}
+
+	type target struct {
+		name     string
+		homepage string
+	}
+	var targets []target
+	for _, p := range pluginManager.GetPlugins() {
+		if meta := plugin.GetPluginMetadata(p); meta.Homepage != "" {
+			targets = append(targets, target{name: p.Name(), homepage: meta.Homepage})
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	go func() {
+		start := time.Now()
+		concurrency := config.AppConfig.PluginWarmupConcurrency
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		var warmed, failed int64
+		for _, t := range targets {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(t target) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				ctx, cancel := context.WithTimeout(context.Background(), config.AppConfig.PluginWarmupTimeout)
+				defer cancel()
+				if _, err := util.WarmConnections(ctx, t.name, t.homepage); err != nil {
+					atomic.AddInt64(&failed, 1)
+					fmt.Printf("[连接预热] %s 预热失败: %v\n", t.name, err)
+					return
+				}
+				atomic.AddInt64(&warmed, 1)
+			}(t)
+		}
+		wg.Wait()
+		fmt.Printf("[连接预热] 完成: %d个插件成功, %d个失败, 耗时%v\n", warmed, failed, time.Since(start).Round(time.Millisecond))
+	}()
+}
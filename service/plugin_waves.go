@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util"
+)
+
+// 正在执行第二批插件搜索的缓存键，同一缓存键同时只执行一次
+var secondWaveKeys sync.Map
+
+// pluginWave 插件所属的批次：PLUGIN_WAVE_OVERRIDES中单独配置的批次优先，否则按插件等级划分
+func pluginWave(p plugin.AsyncSearchPlugin) int {
+	if wave, ok := config.AppConfig.PluginWaveOverrides[strings.ToLower(p.Name())]; ok {
+		return wave
+	}
+	if config.AppConfig.PluginFirstWaveLevels[p.Priority()] {
+		return 1
+	}
+	return 2
+}
+
+// splitPluginWaves 将插件分为两批。未启用分批搜索或第一批为空时（如请求只指定了低等级插件），全部插件都在第一批
+func splitPluginWaves(plugins []plugin.AsyncSearchPlugin) (first, second []plugin.AsyncSearchPlugin) {
+	if !config.AppConfig.PluginTwoWave {
+		return plugins, nil
+	}
+	for _, p := range plugins {
+		if pluginWave(p) == 1 {
+			first = append(first, p)
+		} else {
+			second = append(second, p)
+		}
+	}
+	if len(first) == 0 {
+		return second, nil
+	}
+	return first, second
+}
+
+// executePlugins 执行一批插件搜索：集群模式下由工作节点分担，否则在本节点执行
+func (s *SearchService) executePlugins(requestID string, keyword string, plugins []plugin.AsyncSearchPlugin, refresh model.RefreshLevel, concurrency int, cacheKey string, ext map[string]interface{}) []model.SearchResult {
+	if len(plugins) == 0 {
+		return nil
+	}
+	if coordinator := GetClusterCoordinator(); coordinator != nil {
+		return s.searchPluginsOnCluster(coordinator, requestID, keyword, plugins, refresh, concurrency, cacheKey, ext)
+	}
+	return s.runPluginTasks(keyword, plugins, concurrency, cacheKey, ext)
+}
+
+// runSecondWave 在后台执行第二批插件搜索，完成后与第一批的原始结果合并写入缓存。
+// 同一缓存键已有第二批搜索在执行时不再重复执行
+func (s *SearchService) runSecondWave(requestID string, keyword string, plugins []plugin.AsyncSearchPlugin, refresh model.RefreshLevel, concurrency int, cacheKey string, ext map[string]interface{}, firstResults []model.SearchResult) {
+	if _, running := secondWaveKeys.LoadOrStore(cacheKey, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer secondWaveKeys.Delete(cacheKey)
+		secondResults := s.executePlugins(requestID, keyword, plugins, refresh, concurrency, cacheKey, ext)
+		combined := make([]model.SearchResult, 0, len(firstResults)+len(secondResults))
+		combined = append(append(combined, firstResults...), secondResults...)
+		if util.AsyncLogEnabled() {
+			fmt.Printf("%s[分批搜索] 第二批插件完成: %s | 插件数: %d | 新增结果数: %d\n",
+				util.RequestLogTag(requestID), keyword, len(plugins), len(secondResults))
+		}
+		storePluginResults(requestID, cacheKey, finalizePluginResults(combined))
+	}()
+}
+
+// finalizePluginResults 插件结果写入缓存和返回前的处理：规范化网盘分享链接，使不同插件返回的同一分享链接一致；
+// 规范化磁力链接，不同插件返回的同一磁力资源只保留一条；丢弃标题命中屏蔽规则或链接指向屏蔽域名的结果
+func finalizePluginResults(results []model.SearchResult) []model.SearchResult {
+	results = canonicalizeShareLinks(results)
+	results = normalizeMagnetResults(results)
+	return GetContentSafetyFilter().Filter(results)
+}
+
+// storePluginResults 将插件搜索的最终结果写入主缓存，覆盖可能有问题的异步插件缓存
+func storePluginResults(requestID string, cacheKey string, results []model.SearchResult) {
+	if !cacheInitialized || !config.AppConfig.CacheEnabled || enhancedTwoLevelCache == nil {
+		return
+	}
+	ttl := cacheTTLForKey(cacheKey, CacheTierComplete)
+
+	// 使用增强版缓存，确保与异步插件使用相同的序列化器
+	data, err := enhancedTwoLevelCache.GetSerializer().Serialize(results)
+	if err != nil {
+		fmt.Printf("%s[主程序] 缓存序列化失败: %s | 错误: %v\n", util.RequestLogTag(requestID), cacheKey, err)
+		return
+	}
+
+	// 使用同步方式确保数据写入磁盘
+	enhancedTwoLevelCache.SetBothLevels(cacheKey, data, ttl)
+	if util.SampledLogEnabled() {
+		fmt.Printf("%s[主程序] 缓存更新完成: %s | 结果数: %d\n", util.RequestLogTag(requestID), cacheKey, len(results))
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+	jsonutil "pansou/util/json"
+)
+
+// 零结果统计落盘间隔
+const zeroHitSaveInterval = time.Minute
+
+// 超时返回的标记保留时长，超过时长未被取走的标记在落盘时清理
+const zeroHitPartialTTL = 10 * time.Minute
+
+// PluginZeroHitStats 插件在一个关键词分类下的结果统计
+type PluginZeroHitStats struct {
+	Plugin     string     `json:"plugin"`
+	Category   string     `json:"category"`
+	Searches   int64      `json:"searches"`    // 完成的搜索次数，不含出错和超时
+	Hits       int64      `json:"hits"`        // 返回了带链接结果的次数
+	HitRate    float64    `json:"hit_rate"`    // 有结果的搜索占比
+	ZeroStreak int64      `json:"zero_streak"` // 最近一次有结果以来连续没有结果的次数
+	Skipped    bool       `json:"skipped"`     // 当前是否跳过该插件在此分类下的搜索
+	LastHit    *time.Time `json:"last_hit,omitempty"`
+	LastProbe  *time.Time `json:"last_probe,omitempty"` // 被跳过后最近一次放行搜索的时间
+}
+
+// zeroHitEntry 插件在一个分类下的统计
+type zeroHitEntry struct {
+	Searches   int64     `json:"searches"`
+	Hits       int64     `json:"hits"`
+	ZeroStreak int64     `json:"zero_streak"`
+	LastHit    time.Time `json:"last_hit"`
+	LastProbe  time.Time `json:"last_probe"`
+}
+
+// PluginZeroHitService 按插件和关键词分类统计搜索是否有结果。启用ZERO_HIT_SKIP时，
+// 插件在某个分类下连续ZERO_HIT_MIN_SEARCHES次没有结果后不再参与该分类的搜索，
+// 每隔ZERO_HIT_PROBE_INTERVAL放行一次搜索，有结果时恢复。统计定期落盘，重启后继续累计
+type PluginZeroHitService struct {
+	mu      sync.Mutex
+	entries map[string]map[string]*zeroHitEntry // 插件名 -> 分类 -> 统计
+
+	// 插件超时返回的搜索（请求ID|插件名 -> 标记时间），这些搜索没有结果不代表插件搜不到
+	partial sync.Map
+
+	dataFile string
+	dirty    bool
+}
+
+var (
+	globalPluginZeroHits *PluginZeroHitService
+	pluginZeroHitsOnce   sync.Once
+)
+
+// GetPluginZeroHits 获取全局零结果统计服务
+func GetPluginZeroHits() *PluginZeroHitService {
+	pluginZeroHitsOnce.Do(func() {
+		globalPluginZeroHits = NewPluginZeroHitService("data/plugin_zero_hits.json")
+	})
+	return globalPluginZeroHits
+}
+
+// NewPluginZeroHitService 创建零结果统计服务，dataFile为空时不持久化
+func NewPluginZeroHitService(dataFile string) *PluginZeroHitService {
+	s := &PluginZeroHitService{
+		entries:  make(map[string]map[string]*zeroHitEntry),
+		dataFile: dataFile,
+	}
+
+	if dataFile != "" {
+		s.load()
+		go s.saveLoop()
+	}
+
+	return s
+}
+
+// entry 获取插件在分类下的统计（调用方需持有锁）
+func (s *PluginZeroHitService) entry(name, category string) *zeroHitEntry {
+	categories, ok := s.entries[name]
+	if !ok {
+		categories = make(map[string]*zeroHitEntry)
+		s.entries[name] = categories
+	}
+	e, ok := categories[category]
+	if !ok {
+		e = &zeroHitEntry{}
+		categories[category] = e
+	}
+	return e
+}
+
+// skipped 插件在该分类下是否已连续足够多次没有结果
+func (e *zeroHitEntry) skipped() bool {
+	return e.ZeroStreak >= int64(config.AppConfig.ZeroHitMinSearches)
+}
+
+// markPartial 记录插件本次搜索超时返回，之后的Record不计入统计
+func (s *PluginZeroHitService) markPartial(requestID, name string) {
+	s.partial.Store(requestID+"|"+name, time.Now())
+}
+
+// Record 记录插件完成的一次搜索是否有带链接的结果，超时返回的搜索不计入
+func (s *PluginZeroHitService) Record(requestID, name, category string, hit bool) {
+	if requestID != "" {
+		if _, partial := s.partial.LoadAndDelete(requestID + "|" + name); partial {
+			return
+		}
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entry(name, category)
+	wasSkipped := e.skipped()
+	e.Searches++
+	s.dirty = true
+	if hit {
+		e.Hits++
+		e.ZeroStreak = 0
+		e.LastHit = now
+		if wasSkipped && config.AppConfig.ZeroHitSkip {
+			fmt.Printf("[零结果跳过] 插件 %s 在 %s 分类下重新有结果，恢复搜索\n", name, category)
+		}
+		return
+	}
+	e.ZeroStreak++
+	if !wasSkipped && e.skipped() {
+		// 从现在开始计算放行间隔
+		e.LastProbe = now
+		if config.AppConfig.ZeroHitSkip {
+			fmt.Printf("[零结果跳过] 插件 %s 在 %s 分类下连续 %d 次没有结果，暂停该分类的搜索\n", name, category, e.ZeroStreak)
+		}
+	}
+}
+
+// Allow 判断插件是否参与该分类的搜索：未被跳过时参与，被跳过时每隔ZERO_HIT_PROBE_INTERVAL放行一次
+func (s *PluginZeroHitService) Allow(name, category string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories, ok := s.entries[name]
+	if !ok {
+		return true
+	}
+	e, ok := categories[category]
+	if !ok || !e.skipped() {
+		return true
+	}
+	if now.Sub(e.LastProbe) < config.AppConfig.ZeroHitProbeInterval {
+		return false
+	}
+	e.LastProbe = now
+	s.dirty = true
+	return true
+}
+
+// Reset 清除插件的统计，插件修复后立即恢复所有分类的搜索
+func (s *PluginZeroHitService) Reset(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[name]; ok {
+		delete(s.entries, name)
+		s.dirty = true
+	}
+}
+
+// Stats 获取各插件按分类的统计，按插件名和分类排序
+func (s *PluginZeroHitService) Stats() []PluginZeroHitStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]PluginZeroHitStats, 0, len(s.entries))
+	for name, categories := range s.entries {
+		for category, e := range categories {
+			item := PluginZeroHitStats{
+				Plugin:     name,
+				Category:   category,
+				Searches:   e.Searches,
+				Hits:       e.Hits,
+				ZeroStreak: e.ZeroStreak,
+				Skipped:    config.AppConfig.ZeroHitSkip && e.skipped(),
+			}
+			if e.Searches > 0 {
+				item.HitRate = float64(e.Hits) / float64(e.Searches)
+			}
+			if !e.LastHit.IsZero() {
+				lastHit := e.LastHit
+				item.LastHit = &lastHit
+			}
+			if item.Skipped && !e.LastProbe.IsZero() {
+				lastProbe := e.LastProbe
+				item.LastProbe = &lastProbe
+			}
+			stats = append(stats, item)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Plugin != stats[j].Plugin {
+			return stats[i].Plugin < stats[j].Plugin
+		}
+		return stats[i].Category < stats[j].Category
+	})
+	return stats
+}
+
+// Flush 将统计写入磁盘
+func (s *PluginZeroHitService) Flush() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := jsonutil.MarshalIndent(s.entries, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("零结果统计序列化失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.dataFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.dataFile, data, 0644)
+}
+
+// load 从磁盘加载统计
+func (s *PluginZeroHitService) load() {
+	data, err := os.ReadFile(s.dataFile)
+	if err != nil {
+		return
+	}
+	var stored map[string]map[string]*zeroHitEntry
+	if err := jsonutil.Unmarshal(data, &stored); err != nil {
+		fmt.Printf("[零结果跳过] 加载统计失败: %v\n", err)
+		return
+	}
+	for name, categories := range stored {
+		for category, e := range categories {
+			if e != nil {
+				*s.entry(name, category) = *e
+			}
+		}
+	}
+}
+
+// saveLoop 定期落盘，并清理过期的超时标记
+func (s *PluginZeroHitService) saveLoop() {
+	ticker := time.NewTicker(zeroHitSaveInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.partial.Range(func(key, value interface{}) bool {
+			if time.Since(value.(time.Time)) > zeroHitPartialTTL {
+				s.partial.Delete(key)
+			}
+			return true
+		})
+		if err := s.Flush(); err != nil {
+			fmt.Printf("[零结果跳过] 保存统计失败: %v\n", err)
+		}
+	}
+}
+
+// skipZeroHitPlugins 去掉在该分类下长期没有结果的插件，plugins为nil时从全部插件中选择。
+// 没有插件被跳过或全部插件都被跳过时原样返回
+func (s *SearchService) skipZeroHitPlugins(category string, plugins []string) []string {
+	if s.pluginManager == nil {
+		return plugins
+	}
+	candidates := plugins
+	if candidates == nil {
+		for _, p := range s.pluginManager.GetPlugins() {
+			candidates = append(candidates, p.Name())
+		}
+	}
+
+	tracker := GetPluginZeroHits()
+	now := time.Now()
+	selected := make([]string, 0, len(candidates))
+	for _, name := range candidates {
+		if tracker.Allow(name, category, now) {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == len(candidates) || len(selected) == 0 {
+		return plugins
+	}
+	return selected
+}
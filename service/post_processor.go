@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// 内置的结果后处理步骤
+const (
+	PostProcessLanguage      = "language"       // 按请求的languages参数过滤结果
+	PostProcessDedup         = "dedup"          // 按链接去重，保留排在前面的结果
+	PostProcessTitleClean    = "title_clean"    // 清理标题中的前缀、表情符号和多余空白
+	PostProcessContentSafety = "content_safety" // 用当前的内容安全规则重新过滤（缓存中的结果可能早于规则更新）
+)
+
+// PostProcessContext 后处理步骤可用的请求信息
+type PostProcessContext struct {
+	Keyword   string          // 主关键词
+	Keywords  []string        // 主关键词和别名
+	Languages map[string]bool // 请求限定的语言，为空时不限制
+}
+
+// PostProcessor 结果后处理步骤：在结果合并、排序之后，生成Results和合并链接之前按顺序执行。
+// 传入的结果可能与缓存共享，步骤需要返回新的切片，不能修改传入的结果
+type PostProcessor interface {
+	// Name 返回步骤名称，用于POST_PROCESSORS配置
+	Name() string
+
+	// Process 处理结果，返回处理后的结果
+	Process(ctx *PostProcessContext, results []model.SearchResult) []model.SearchResult
+}
+
+// postProcessorFunc 由函数实现的后处理步骤
+type postProcessorFunc struct {
+	name string
+	fn   func(ctx *PostProcessContext, results []model.SearchResult) []model.SearchResult
+}
+
+func (p postProcessorFunc) Name() string { return p.name }
+
+func (p postProcessorFunc) Process(ctx *PostProcessContext, results []model.SearchResult) []model.SearchResult {
+	return p.fn(ctx, results)
+}
+
+// NewPostProcessor 用不依赖请求信息的函数创建后处理步骤
+func NewPostProcessor(name string, fn func([]model.SearchResult) []model.SearchResult) PostProcessor {
+	return postProcessorFunc{name: name, fn: func(_ *PostProcessContext, results []model.SearchResult) []model.SearchResult {
+		return fn(results)
+	}}
+}
+
+var (
+	// 已注册的后处理步骤
+	postProcessors     = make(map[string]PostProcessor)
+	postProcessorsLock sync.RWMutex
+
+	// 按POST_PROCESSORS配置解析的处理链
+	postProcessChain     []PostProcessor
+	postProcessChainOnce sync.Once
+)
+
+func init() {
+	RegisterPostProcessor(postProcessorFunc{name: PostProcessLanguage, fn: func(ctx *PostProcessContext, results []model.SearchResult) []model.SearchResult {
+		if len(ctx.Languages) == 0 {
+			return results
+		}
+		return filterResultsByLanguage(results, ctx.Languages)
+	}})
+	RegisterPostProcessor(NewPostProcessor(PostProcessDedup, dedupResults))
+	RegisterPostProcessor(NewPostProcessor(PostProcessTitleClean, cleanResultTitles))
+	RegisterPostProcessor(NewPostProcessor(PostProcessContentSafety, func(results []model.SearchResult) []model.SearchResult {
+		return GetContentSafetyFilter().Filter(results)
+	}))
+}
+
+// RegisterPostProcessor 注册后处理步骤，同名步骤会被替换。需要在第一次搜索前（如init中）注册，
+// 步骤只有出现在POST_PROCESSORS中才会执行
+func RegisterPostProcessor(p PostProcessor) {
+	if p == nil || p.Name() == "" {
+		return
+	}
+	postProcessorsLock.Lock()
+	defer postProcessorsLock.Unlock()
+	postProcessors[p.Name()] = p
+}
+
+// PostProcessorNames 获取已注册的后处理步骤名称
+func PostProcessorNames() []string {
+	postProcessorsLock.RLock()
+	defer postProcessorsLock.RUnlock()
+	names := make([]string, 0, len(postProcessors))
+	for name := range postProcessors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getPostProcessChain 按配置顺序解析处理链，未注册的步骤名称被忽略
+func getPostProcessChain() []PostProcessor {
+	postProcessChainOnce.Do(func() {
+		postProcessorsLock.RLock()
+		defer postProcessorsLock.RUnlock()
+		for _, name := range config.AppConfig.PostProcessors {
+			p, ok := postProcessors[name]
+			if !ok {
+				fmt.Printf("[后处理] 未知的后处理步骤: %s\n", name)
+				continue
+			}
+			postProcessChain = append(postProcessChain, p)
+		}
+	})
+	return postProcessChain
+}
+
+// runPostProcessors 按顺序执行处理链
+func runPostProcessors(ctx *PostProcessContext, results []model.SearchResult) []model.SearchResult {
+	for _, p := range getPostProcessChain() {
+		results = p.Process(ctx, results)
+	}
+	return results
+}
+
+// dedupResults 按链接去重：链接全部在前面的结果中出现过的结果被丢弃
+func dedupResults(results []model.SearchResult) []model.SearchResult {
+	seen := make(map[string]bool)
+	deduped := make([]model.SearchResult, 0, len(results))
+	for _, result := range results {
+		duplicate := len(result.Links) > 0
+		for _, link := range result.Links {
+			key := normalizeUrl(link.URL)
+			if !seen[key] {
+				seen[key] = true
+				duplicate = false
+			}
+		}
+		if !duplicate {
+			deduped = append(deduped, result)
+		}
+	}
+	return deduped
+}
+
+// cleanResultTitles 清理标题，标题清理后为空时保留原标题
+func cleanResultTitles(results []model.SearchResult) []model.SearchResult {
+	cleaned := make([]model.SearchResult, len(results))
+	copy(cleaned, results)
+	for i := range cleaned {
+		title := strings.Join(strings.Fields(cleanTitle(cleaned[i].Title)), " ")
+		if title != "" {
+			cleaned[i].Title = title
+		}
+	}
+	return cleaned
+}
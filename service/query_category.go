@@ -0,0 +1,85 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"pansou/config"
+	"pansou/plugin"
+)
+
+// 搜索关键词的分类
+const (
+	QueryCategoryVideo    = "video"    // 电影、电视剧、综艺、纪录片
+	QueryCategoryAnime    = "anime"    // 动画、番剧
+	QueryCategoryMusic    = "music"    // 音乐、专辑
+	QueryCategorySoftware = "software" // 软件、应用
+	QueryCategoryAdult    = "adult"    // 成人内容
+	QueryCategoryEbook    = "ebook"    // 电子书、小说、教材
+	QueryCategoryGeneral  = "general"  // 无法判断分类
+)
+
+// queryCategoryPatterns 关键词分类规则，按顺序匹配，先匹配的分类优先
+var queryCategoryPatterns = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{QueryCategoryAdult, regexp.MustCompile(`(?i)番号|无码|有码|女优|成人|18禁|里番|\b(jav|fc2|ppv|hentai|porn|xxx)\b|^[a-z]{2,6}-\d{3,5}$`)},
+	{QueryCategoryAnime, regexp.MustCompile(`(?i)动漫|动画|番剧|新番|国漫|剧场版|\b(anime|ova)\b`)},
+	{QueryCategoryEbook, regexp.MustCompile(`(?i)电子书|小说|教材|课本|书籍|有声书|\b(epub|mobi|azw3|pdf)\b`)},
+	{QueryCategorySoftware, regexp.MustCompile(`(?i)软件|破解版|绿色版|安装包|激活码|注册机|\b(apk|exe|dmg|app|macos|windows|android)\b|\bv\d+\.\d+`)},
+	{QueryCategoryMusic, regexp.MustCompile(`(?i)音乐|歌曲|专辑|单曲|歌单|无损|\b(flac|ape|mp3|wav|dsd)\b`)},
+	{QueryCategoryVideo, regexp.MustCompile(`(?i)电影|电视剧|剧集|美剧|韩剧|日剧|综艺|纪录片|蓝光|原盘|全\d+集|第[一二三四五六七八九十\d]+季|\bS\d{1,2}(E\d{1,3})?\b|\b(4k|1080p|2160p|remux|web-dl|bluray)\b`)},
+}
+
+// ClassifyQuery 按关键词中的特征词判断搜索的内容分类，无法判断时返回general
+func ClassifyQuery(keyword string) string {
+	keyword = strings.TrimSpace(keyword)
+	for _, rule := range queryCategoryPatterns {
+		if rule.pattern.MatchString(keyword) {
+			return rule.category
+		}
+	}
+	return QueryCategoryGeneral
+}
+
+// getPluginCategories 获取插件擅长的内容分类：PLUGIN_CATEGORIES配置优先，其次是插件元数据；为空表示不限分类
+func getPluginCategories(p plugin.AsyncSearchPlugin) []string {
+	if categories, ok := config.AppConfig.PluginCategories[strings.ToLower(p.Name())]; ok {
+		return categories
+	}
+	return plugin.GetPluginMetadata(p).Categories
+}
+
+// pluginMatchesCategory 插件是否可能有该分类的结果
+func pluginMatchesCategory(p plugin.AsyncSearchPlugin, category string) bool {
+	categories := getPluginCategories(p)
+	if len(categories) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// routePluginsByCategory 按关键词分类选择插件，返回只包含相关插件的名称列表；
+// 分类无法判断或所有插件都相关时返回nil，表示搜索全部插件
+func (s *SearchService) routePluginsByCategory(category string) []string {
+	if category == QueryCategoryGeneral || s.pluginManager == nil {
+		return nil
+	}
+	all := s.pluginManager.GetPlugins()
+	selected := make([]string, 0, len(all))
+	for _, p := range all {
+		if pluginMatchesCategory(p, category) {
+			selected = append(selected, p.Name())
+		}
+	}
+	if len(selected) == len(all) || len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
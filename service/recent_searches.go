@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// 最近搜索记录保留的条数
+const maxRecentSearches = 200
+
+// RecentSearch 一次搜索请求的概要
+type RecentSearch struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Keyword    string    `json:"keyword"`
+	SourceType string    `json:"source_type"`
+	ResultType string    `json:"result_type"`
+	Total      int       `json:"total"`
+	DurationMs int64     `json:"duration_ms"`
+	Tenant     string    `json:"tenant,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RecentSearches 最近搜索请求的环形缓冲区，供管理后台查看
+type RecentSearches struct {
+	mu      sync.Mutex
+	entries []RecentSearch
+	next    int
+	full    bool
+}
+
+var (
+	globalRecentSearches *RecentSearches
+	recentSearchesOnce   sync.Once
+)
+
+// GetRecentSearches 获取全局最近搜索记录
+func GetRecentSearches() *RecentSearches {
+	recentSearchesOnce.Do(func() {
+		globalRecentSearches = NewRecentSearches(maxRecentSearches)
+	})
+	return globalRecentSearches
+}
+
+// NewRecentSearches 创建最多保留size条记录的最近搜索记录
+func NewRecentSearches(size int) *RecentSearches {
+	if size <= 0 {
+		size = maxRecentSearches
+	}
+	return &RecentSearches{entries: make([]RecentSearch, size)}
+}
+
+// Record 记录一次搜索，超过容量时覆盖最早的记录
+func (r *RecentSearches) Record(entry RecentSearch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List 按时间倒序返回最近的limit条记录（limit<=0时返回全部）
+func (r *RecentSearches) List(limit int) []RecentSearch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+	list := make([]RecentSearch, 0, limit)
+	for i := 1; i <= limit; i++ {
+		list = append(list, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return list
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+
+	"pansou/util"
+)
+
+// 典型的TG频道消息：标题行后跟各网盘链接行
+const benchMultilineMessage = `🎬 流浪地球2 (2023) 4K HDR 更新30集
+链接：https://pan.quark.cn/s/1a2b3c4d5e6f
+地址：https://pan.baidu.com/s/1AbCdEfGhIjKlMnOp?pwd=abcd
+📺 三体 全30集 4K 臻彩
+https://www.aliyundrive.com/s/AbCdEfGhIjK
+阿里云盘：https://www.alipan.com/s/ZyXwVuTsRqP
+天翼云盘：https://cloud.189.cn/t/AbCdEf123456（访问码：ab12）`
+
+// 没有换行、多个资源挤在一行的消息
+const benchInlineMessage = `流浪地球2 4K 夸克：https://pan.quark.cn/s/1a2b3c4d5e6f 百度：https://pan.baidu.com/s/1AbCdEfGhIjKlMnOp?pwd=abcd ` +
+	`三体 全30集 阿里：https://www.alipan.com/s/ZyXwVuTsRqP 123：https://www.123pan.com/s/abcd-EfGh 115：https://115.com/s/sw1abcd?password=x1y2`
+
+// 改为注册表预编译前，每次提取都会重新编译的正则
+var benchPerCallPatterns = []string{"generic_link", "trailing_title", "emoji"}
+
+// BenchmarkExtractLinkTitlePairsPatterns 对比使用注册表预编译正则与每次调用重新编译正则的提取开销。
+// compile_per_call 在提取前按旧实现重新编译链接、标题和表情正则各一次，是旧实现开销的下限
+// （旧实现每提取一个标题还会再编译一次标题和表情正则）
+func BenchmarkExtractLinkTitlePairsPatterns(b *testing.B) {
+	exprs := make([]string, len(benchPerCallPatterns))
+	for i, name := range benchPerCallPatterns {
+		exprs[i] = util.GetPattern(name).String()
+	}
+	messages := map[string]string{"multiline": benchMultilineMessage, "inline": benchInlineMessage}
+
+	for _, name := range []string{"multiline", "inline"} {
+		content := messages[name]
+		b.Run(name+"/registry", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				extractLinkTitlePairs(content)
+			}
+		})
+		b.Run(name+"/compile_per_call", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, expr := range exprs {
+					regexp.MustCompile(expr)
+				}
+				extractLinkTitlePairs(content)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"pansou/model"
+)
+
+// capMergedLinks 合并链接总数超过limit时，按与扁平列表相同的排序保留前limit个链接，
+// 各网盘类型内保持原有顺序，返回截断后的链接和各类型被丢弃的数量（未截断时为nil）
+func capMergedLinks(mergedLinks model.MergedLinks, rankedResults []model.SearchResult, limit int) (model.MergedLinks, map[string]int) {
+	if limit <= 0 || countMergedLinks(mergedLinks) <= limit {
+		return mergedLinks, nil
+	}
+
+	type linkKey struct{ linkType, url string }
+	keep := make(map[linkKey]bool, limit)
+	for _, link := range flattenMergedLinks(mergedLinks, rankedResults)[:limit] {
+		keep[linkKey{link.Type, link.URL}] = true
+	}
+
+	capped := make(model.MergedLinks, len(mergedLinks))
+	dropped := make(map[string]int)
+	for linkType, links := range mergedLinks {
+		kept := make([]model.MergedLink, 0, len(links))
+		for _, link := range links {
+			if keep[linkKey{linkType, link.URL}] {
+				kept = append(kept, link)
+			} else {
+				dropped[linkType]++
+			}
+		}
+		if len(kept) > 0 {
+			capped[linkType] = kept
+		}
+	}
+	return capped, dropped
+}
+
+// capResults 结果列表超过limit时截断，返回截断后的结果和被丢弃的结果数
+func capResults(results []model.SearchResult, limit int) ([]model.SearchResult, int) {
+	if limit <= 0 || len(results) <= limit {
+		return results, 0
+	}
+	return results[:limit], len(results) - limit
+}
+
+// countMergedLinks 合并链接的总数
+func countMergedLinks(mergedLinks model.MergedLinks) int {
+	total := 0
+	for _, links := range mergedLinks {
+		total += len(links)
+	}
+	return total
+}
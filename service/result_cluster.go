@@ -0,0 +1,117 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"pansou/model"
+)
+
+// 标题相似度达到该值时视为同一资源
+const clusterSimilarityThreshold = 0.8
+
+// 标题中不参与比较的部分：方括号标签和TG转发前缀
+var clusterNoiseRegex = regexp.MustCompile(`【[^】]*】|\[[^\]]*\]|(?i)^\s*(?:forwarded from|转发自)\s*[^:：]*[:：]`)
+
+// 标题中的数字序列
+var clusterNumberRegex = regexp.MustCompile(`\d+`)
+
+// titleFingerprint 标题的规范化形式、其中的数字序列和字符二元组集合
+type titleFingerprint struct {
+	normalized string
+	numbers    string
+	bigrams    map[string]bool
+}
+
+// resultCluster 聚类中的一组结果，首个结果为代表
+type resultCluster struct {
+	representative int
+	fingerprint    titleFingerprint
+	members        []string
+}
+
+// ClusterResults 将标题近似相同的结果（如同一资源的多次TG转发）聚为一类，
+// 按原有顺序返回每类的代表结果（排名最靠前的一条），代表结果带有同类结果数和其他成员的唯一ID。
+// 返回新的切片，不修改传入的结果
+func ClusterResults(results []model.SearchResult) []model.SearchResult {
+	if len(results) < 2 {
+		return results
+	}
+
+	var clusters []*resultCluster
+	for i, result := range results {
+		fp := newTitleFingerprint(result.Title)
+		var matched *resultCluster
+		if fp.normalized != "" {
+			for _, cluster := range clusters {
+				if titleSimilarity(cluster.fingerprint, fp) >= clusterSimilarityThreshold {
+					matched = cluster
+					break
+				}
+			}
+		}
+		if matched != nil {
+			matched.members = append(matched.members, result.UniqueID)
+			continue
+		}
+		clusters = append(clusters, &resultCluster{representative: i, fingerprint: fp})
+	}
+
+	clustered := make([]model.SearchResult, 0, len(clusters))
+	for _, cluster := range clusters {
+		representative := results[cluster.representative]
+		representative.ClusterSize = len(cluster.members) + 1
+		representative.ClusterMembers = cluster.members
+		clustered = append(clustered, representative)
+	}
+	return clustered
+}
+
+// ClusterSearchResponse 对响应中的Results聚类，Results存在时总数改为聚类数
+func ClusterSearchResponse(response model.SearchResponse) model.SearchResponse {
+	if response.Results == nil {
+		return response
+	}
+	response.Results = ClusterResults(response.Results)
+	response.Total = len(response.Results)
+	return response
+}
+
+// newTitleFingerprint 规范化标题：去除标签和转发前缀，只保留字母、数字和汉字并转为小写
+func newTitleFingerprint(title string) titleFingerprint {
+	title = clusterNoiseRegex.ReplaceAllString(title, "")
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	normalized := b.String()
+	numbers := strings.Join(clusterNumberRegex.FindAllString(normalized, -1), ",")
+
+	runes := []rune(normalized)
+	bigrams := make(map[string]bool, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams[string(runes[i:i+2])] = true
+	}
+	return titleFingerprint{normalized: normalized, numbers: numbers, bigrams: bigrams}
+}
+
+// titleSimilarity 规范化标题相同时为1，否则为字符二元组的Jaccard相似度。
+// 标题中的数字（集数、季数、年份、分辨率等）不同时视为不同资源
+func titleSimilarity(a, b titleFingerprint) float64 {
+	if a.normalized == b.normalized {
+		return 1
+	}
+	if a.numbers != b.numbers || len(a.bigrams) == 0 || len(b.bigrams) == 0 {
+		return 0
+	}
+	intersection := 0
+	for bigram := range a.bigrams {
+		if b.bigrams[bigram] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a.bigrams)+len(b.bigrams)-intersection)
+}
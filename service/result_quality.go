@@ -0,0 +1,36 @@
+package service
+
+import (
+	"pansou/model"
+	"pansou/util"
+)
+
+// tagResultAttributes 为未解析质量信息的结果从标题解析分辨率、编码、字幕等标注
+func tagResultAttributes(results []model.SearchResult) {
+	for i := range results {
+		if results[i].Attributes == nil {
+			results[i].Attributes = util.ParseQualityAttributes(results[i].Title)
+		}
+	}
+}
+
+// filterResultsByResolution 只保留标题标注的分辨率不低于minResolution的结果，没有标注分辨率的结果不保留。
+// 返回新的切片，不修改传入的结果
+func filterResultsByResolution(results []model.SearchResult, minResolution string) []model.SearchResult {
+	minRank := util.ResolutionRank(minResolution)
+	filtered := make([]model.SearchResult, 0, len(results))
+	for _, result := range results {
+		if result.Attributes != nil && util.ResolutionRank(result.Attributes.Resolution) >= minRank {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// linkAttributes 合并链接的质量信息：链接标题与结果标题相同时沿用结果的解析结果，否则按链接标题解析
+func linkAttributes(result model.SearchResult, title string) *model.ResultAttributes {
+	if title == result.Title && result.Attributes != nil {
+		return result.Attributes
+	}
+	return util.ParseQualityAttributes(title)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+	"pansou/util/cache"
+)
+
+// SearchBackfillStats 后台补全搜索的统计
+type SearchBackfillStats struct {
+	Enabled   bool  `json:"enabled"`
+	Pending   int64 `json:"pending"`   // 等待执行的补全搜索
+	Queued    int64 `json:"queued"`    // 累计加入队列的补全搜索
+	Dropped   int64 `json:"dropped"`   // 队列已满时丢弃的补全搜索
+	Skipped   int64 `json:"skipped"`   // 执行时目标缓存已存在或与请求的插件相同而跳过的补全搜索
+	Completed int64 `json:"completed"` // 执行完成的补全搜索
+}
+
+// backfillJob 一次补全搜索：按未指定插件的请求搜索关键词，填充对应的插件缓存
+type backfillJob struct {
+	id         string // 去重标识：命名空间和未指定插件时的缓存键
+	keyword    string
+	tenant     *model.Tenant
+	ext        map[string]interface{}
+	requestKey string // 请求实际使用的插件缓存键，与补全目标相同时跳过
+}
+
+var (
+	backfillQueue     chan backfillJob
+	backfillQueueOnce sync.Once
+	// 已加入队列、尚未执行完成的补全搜索，同一关键词同时只补全一次
+	backfillPending sync.Map
+
+	backfillWaiting   int64
+	backfillQueued    int64
+	backfillDropped   int64
+	backfillSkipped   int64
+	backfillCompleted int64
+)
+
+// scheduleBackfill 请求只搜索了部分插件（指定了plugins或profile）时，将各关键词加入后台补全队列。
+// 补全搜索使用未指定插件的请求会搜索的插件，结果写入对应的缓存，之后搜索全部插件的请求可以直接命中
+func (s *SearchService) scheduleBackfill(ctx context.Context, state *SearchState) {
+	opts := state.Options
+	if !config.AppConfig.SearchBackfill || state.SourceType == "tg" || state.Plugins == nil {
+		return
+	}
+	if opts.Plugins == nil && opts.Profile == "" {
+		// 插件是按关键词分类或租户选择的，已经是未指定插件的请求使用的缓存
+		return
+	}
+	if !cacheInitialized || !config.AppConfig.CacheEnabled || IsMaintenanceMode() {
+		return
+	}
+
+	backfillQueueOnce.Do(func() {
+		backfillQueue = make(chan backfillJob, config.AppConfig.SearchBackfillQueue)
+		go s.backfillLoop()
+	})
+
+	tenant := TenantFromContext(ctx)
+	for _, keyword := range state.Keywords {
+		job := backfillJob{
+			id:         cache.NamespaceCacheKey(state.Namespace, cache.GeneratePluginCacheKey(keyword, nil, opts.Ext)),
+			keyword:    keyword,
+			tenant:     tenant,
+			ext:        opts.Ext,
+			requestKey: cache.NamespaceCacheKey(state.Namespace, cache.GeneratePluginCacheKey(keyword, state.Plugins, opts.Ext)),
+		}
+		if _, pending := backfillPending.LoadOrStore(job.id, struct{}{}); pending {
+			continue
+		}
+		atomic.AddInt64(&backfillWaiting, 1)
+		select {
+		case backfillQueue <- job:
+			atomic.AddInt64(&backfillQueued, 1)
+		default:
+			atomic.AddInt64(&backfillWaiting, -1)
+			backfillPending.Delete(job.id)
+			atomic.AddInt64(&backfillDropped, 1)
+		}
+	}
+}
+
+// backfillLoop 逐个执行补全搜索，不与用户请求争抢插件并发
+func (s *SearchService) backfillLoop() {
+	for job := range backfillQueue {
+		atomic.AddInt64(&backfillWaiting, -1)
+		s.runBackfill(job)
+		backfillPending.Delete(job.id)
+	}
+}
+
+// runBackfill 按未指定插件的请求确定插件（租户限制、关键词分类和零结果跳过），目标缓存不存在时搜索插件并写入缓存
+func (s *SearchService) runBackfill(job backfillJob) {
+	if IsMaintenanceMode() {
+		atomic.AddInt64(&backfillSkipped, 1)
+		return
+	}
+
+	ctx := context.Background()
+	if job.tenant != nil {
+		ctx = WithTenant(ctx, job.tenant)
+	}
+	state := &SearchState{Options: SearchOptions{Keyword: job.keyword, SourceType: "plugin", Ext: job.ext}}
+	if err := s.resolveSources(ctx, state); err != nil || state.done {
+		atomic.AddInt64(&backfillSkipped, 1)
+		return
+	}
+
+	cacheKey := cache.NamespaceCacheKey(state.Namespace, cache.GeneratePluginCacheKey(job.keyword, state.Plugins, job.ext))
+	if cacheKey == job.requestKey || isPartialCacheKey(cacheKey) {
+		atomic.AddInt64(&backfillSkipped, 1)
+		return
+	}
+	if enhancedTwoLevelCache != nil {
+		if _, hit, err := enhancedTwoLevelCache.Get(cacheKey); err == nil && hit {
+			atomic.AddInt64(&backfillSkipped, 1)
+			return
+		}
+	}
+
+	start := time.Now()
+	results, err := s.searchPlugins("", state.Namespace, job.keyword, state.Plugins, model.RefreshNone, config.AppConfig.SearchBackfillConcurrency, state.Ext)
+	atomic.AddInt64(&backfillCompleted, 1)
+	if err != nil {
+		fmt.Printf("[后台补全] %s 搜索失败: %v\n", job.keyword, err)
+		return
+	}
+	if util.SampledLogEnabled() {
+		fmt.Printf("[后台补全] %s 完成，结果数: %d，耗时: %v\n", job.keyword, len(results), time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// GetSearchBackfillStats 获取后台补全搜索的统计
+func GetSearchBackfillStats() SearchBackfillStats {
+	return SearchBackfillStats{
+		Enabled:   config.AppConfig.SearchBackfill,
+		Pending:   atomic.LoadInt64(&backfillWaiting),
+		Queued:    atomic.LoadInt64(&backfillQueued),
+		Dropped:   atomic.LoadInt64(&backfillDropped),
+		Skipped:   atomic.LoadInt64(&backfillSkipped),
+		Completed: atomic.LoadInt64(&backfillCompleted),
+	}
+}
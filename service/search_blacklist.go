@@ -0,0 +1,249 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"pansou/config"
+	jsonutil "pansou/util/json"
+)
+
+// 内存中保留的最近拒绝记录数
+const maxRecentBlockedQueries = 200
+
+// 未配置原因代码时使用的默认值
+const defaultBlacklistCode = "blocked"
+
+// BlacklistRule 搜索黑名单规则
+type BlacklistRule struct {
+	Code     string   `json:"code"`     // 原因代码，返回给客户端并写入审计日志
+	Keywords []string `json:"keywords"` // 关键词，不区分大小写，忽略空白，包含即命中
+	Patterns []string `json:"patterns"` // 正则，不区分大小写
+	Status   int      `json:"status"`   // 拒绝时的HTTP状态码：451（默认）或400
+	Message  string   `json:"message"`  // 返回给客户端的说明，为空时使用默认说明
+}
+
+// BlacklistMatch 命中的黑名单规则
+type BlacklistMatch struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+// BlockedQuery 被拒绝的搜索请求（审计记录）
+type BlockedQuery struct {
+	Time      time.Time `json:"time"`
+	Keyword   string    `json:"keyword"`
+	Code      string    `json:"code"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// SearchBlacklistStats 搜索黑名单统计
+type SearchBlacklistStats struct {
+	Rules   int              `json:"rules"`
+	Blocked int64            `json:"blocked"` // 累计拒绝的请求数
+	ByCode  map[string]int64 `json:"by_code"` // 按原因代码统计的拒绝数
+	Recent  []BlockedQuery   `json:"recent"`  // 最近拒绝的请求，最新的在前
+}
+
+// compiledBlacklistRule 预处理后的规则
+type compiledBlacklistRule struct {
+	BlacklistMatch
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// SearchBlacklist 搜索关键词黑名单：命中规则的搜索请求被拒绝并写入审计日志
+type SearchBlacklist struct {
+	rules     []compiledBlacklistRule
+	auditFile string
+
+	mu      sync.Mutex
+	blocked int64
+	byCode  map[string]int64
+	recent  []BlockedQuery
+}
+
+var (
+	globalSearchBlacklist *SearchBlacklist
+	searchBlacklistOnce   sync.Once
+)
+
+// GetSearchBlacklist 获取按配置创建的全局搜索黑名单
+func GetSearchBlacklist() *SearchBlacklist {
+	searchBlacklistOnce.Do(func() {
+		var rules []BlacklistRule
+		if file := config.AppConfig.SearchBlacklistFile; file != "" {
+			loaded, err := loadBlacklistRules(file)
+			if err != nil {
+				fmt.Printf("[搜索黑名单] 加载规则失败: %s | 错误: %v\n", file, err)
+			}
+			rules = append(rules, loaded...)
+		}
+		if keywords := config.AppConfig.SearchBlockKeywords; len(keywords) > 0 {
+			rules = append(rules, BlacklistRule{Code: defaultBlacklistCode, Keywords: keywords})
+		}
+		globalSearchBlacklist = NewSearchBlacklist(rules, config.AppConfig.SearchAuditLog)
+		if len(globalSearchBlacklist.rules) > 0 {
+			fmt.Printf("[搜索黑名单] 已加载 %d 条规则\n", len(globalSearchBlacklist.rules))
+		}
+	})
+	return globalSearchBlacklist
+}
+
+// loadBlacklistRules 读取JSON格式的规则列表
+func loadBlacklistRules(file string) ([]BlacklistRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []BlacklistRule
+	if err := jsonutil.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// NewSearchBlacklist 创建搜索黑名单，auditFile为空时只在内存中记录。无效的正则会被忽略并输出日志
+func NewSearchBlacklist(rules []BlacklistRule, auditFile string) *SearchBlacklist {
+	b := &SearchBlacklist{
+		auditFile: auditFile,
+		byCode:    make(map[string]int64),
+	}
+	for _, rule := range rules {
+		compiled := compiledBlacklistRule{
+			BlacklistMatch: BlacklistMatch{Code: rule.Code, Status: rule.Status, Message: rule.Message},
+		}
+		if compiled.Code == "" {
+			compiled.Code = defaultBlacklistCode
+		}
+		if compiled.Status != http.StatusBadRequest {
+			compiled.Status = http.StatusUnavailableForLegalReasons
+		}
+		for _, keyword := range rule.Keywords {
+			if keyword = normalizeBlacklistText(keyword); keyword != "" {
+				compiled.keywords = append(compiled.keywords, keyword)
+			}
+		}
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				fmt.Printf("[搜索黑名单] 忽略无效的正则 %q: %v\n", pattern, err)
+				continue
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		if len(compiled.keywords) > 0 || len(compiled.patterns) > 0 {
+			b.rules = append(b.rules, compiled)
+		}
+	}
+	return b
+}
+
+// Check 检查关键词（含别名），返回第一条命中的规则，未命中时返回nil
+func (b *SearchBlacklist) Check(keywords ...string) *BlacklistMatch {
+	for _, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		normalized := normalizeBlacklistText(keyword)
+		for i := range b.rules {
+			rule := &b.rules[i]
+			for _, k := range rule.keywords {
+				if strings.Contains(normalized, k) {
+					match := rule.BlacklistMatch
+					return &match
+				}
+			}
+			for _, re := range rule.patterns {
+				if re.MatchString(keyword) {
+					match := rule.BlacklistMatch
+					return &match
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Record 记录被拒绝的搜索请求，并追加写入审计日志（每行一条JSON）
+func (b *SearchBlacklist) Record(entry BlockedQuery) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blocked++
+	b.byCode[entry.Code]++
+	b.recent = append(b.recent, entry)
+	if len(b.recent) > maxRecentBlockedQueries {
+		b.recent = b.recent[len(b.recent)-maxRecentBlockedQueries:]
+	}
+
+	if b.auditFile == "" {
+		return
+	}
+	if err := appendJSONLine(b.auditFile, entry); err != nil {
+		fmt.Printf("[搜索黑名单] 写入审计日志失败: %s | 错误: %v\n", b.auditFile, err)
+	}
+}
+
+// Stats 获取拒绝统计
+func (b *SearchBlacklist) Stats() SearchBlacklistStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := SearchBlacklistStats{
+		Rules:   len(b.rules),
+		Blocked: b.blocked,
+		ByCode:  make(map[string]int64, len(b.byCode)),
+		Recent:  make([]BlockedQuery, 0, len(b.recent)),
+	}
+	for code, n := range b.byCode {
+		stats.ByCode[code] = n
+	}
+	for i := len(b.recent) - 1; i >= 0; i-- {
+		stats.Recent = append(stats.Recent, b.recent[i])
+	}
+	return stats
+}
+
+// appendJSONLine 向文件追加一行JSON，用于审计日志、影子测试对比记录等
+func appendJSONLine(file string, entry interface{}) error {
+	line, err := jsonutil.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// normalizeBlacklistText 转为小写并去除空白，避免用空格分隔关键词绕过规则
+func normalizeBlacklistText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
@@ -0,0 +1,42 @@
+package service
+
+import "sync/atomic"
+
+// SearchCacheStats 搜索结果缓存的查询统计（不含强制刷新跳过缓存的请求）
+type SearchCacheStats struct {
+	Lookups  int64   `json:"lookups"`
+	Hits     int64   `json:"hits"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// 按来源统计的缓存查询次数和命中次数
+var (
+	tgCacheLookups     int64
+	tgCacheHits        int64
+	pluginCacheLookups int64
+	pluginCacheHits    int64
+)
+
+// recordSearchCacheLookup 记录一次搜索缓存查询
+func recordSearchCacheLookup(lookups, hits *int64, hit bool) {
+	atomic.AddInt64(lookups, 1)
+	if hit {
+		atomic.AddInt64(hits, 1)
+	}
+}
+
+// GetSearchCacheStats 获取TG和插件搜索结果缓存的命中统计
+func GetSearchCacheStats() map[string]SearchCacheStats {
+	return map[string]SearchCacheStats{
+		"tg":     newSearchCacheStats(&tgCacheLookups, &tgCacheHits),
+		"plugin": newSearchCacheStats(&pluginCacheLookups, &pluginCacheHits),
+	}
+}
+
+func newSearchCacheStats(lookups, hits *int64) SearchCacheStats {
+	stats := SearchCacheStats{Lookups: atomic.LoadInt64(lookups), Hits: atomic.LoadInt64(hits)}
+	if stats.Lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(stats.Lookups)
+	}
+	return stats
+}
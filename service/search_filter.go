@@ -0,0 +1,126 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"pansou/model"
+)
+
+// ApplyTimeRangeAndRanking 按发布时间范围过滤搜索响应并按指定方式排序。
+// from/to为零值时表示不限制；指定时间范围时没有发布时间的结果和链接会被过滤。
+// 返回新的响应，不修改原响应中的切片
+func ApplyTimeRangeAndRanking(response model.SearchResponse, from, to time.Time, ranking string) model.SearchResponse {
+	reorder := ranking == model.RankingTime || ranking == model.RankingSize
+	if from.IsZero() && to.IsZero() && !reorder {
+		return response
+	}
+
+	inRange := func(t time.Time) bool {
+		if from.IsZero() && to.IsZero() {
+			return true
+		}
+		if t.IsZero() {
+			return false
+		}
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+
+	filtered := model.SearchResponse{
+		Truncated:      response.Truncated,
+		DroppedByType:  response.DroppedByType,
+		DroppedResults: response.DroppedResults,
+		Category:       response.Category,
+		Maintenance:    response.Maintenance,
+		Notice:         response.Notice,
+		Timing:         response.Timing,
+	}
+	// 未进入results的结果都没有发布时间，指定时间范围时不再返回
+	if from.IsZero() && to.IsZero() {
+		filtered.Filtered = response.Filtered
+	}
+	if response.Results != nil {
+		filtered.Results = make([]model.SearchResult, 0, len(response.Results))
+		for _, result := range response.Results {
+			if inRange(result.Datetime) {
+				filtered.Results = append(filtered.Results, result)
+			}
+		}
+		switch ranking {
+		case model.RankingTime:
+			sort.SliceStable(filtered.Results, func(i, j int) bool {
+				return filtered.Results[i].Datetime.After(filtered.Results[j].Datetime)
+			})
+		case model.RankingSize:
+			sort.SliceStable(filtered.Results, func(i, j int) bool {
+				return maxLinkSize(filtered.Results[i]) > maxLinkSize(filtered.Results[j])
+			})
+		}
+		filtered.Total = len(filtered.Results)
+	}
+
+	if response.MergedByType != nil {
+		filtered.MergedByType = make(model.MergedLinks, len(response.MergedByType))
+		mergedTotal := 0
+		for linkType, links := range response.MergedByType {
+			kept := make([]model.MergedLink, 0, len(links))
+			for _, link := range links {
+				if inRange(link.Datetime) {
+					kept = append(kept, link)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			switch ranking {
+			case model.RankingTime:
+				sort.SliceStable(kept, func(i, j int) bool {
+					return kept[i].Datetime.After(kept[j].Datetime)
+				})
+			case model.RankingSize:
+				sort.SliceStable(kept, func(i, j int) bool {
+					return kept[i].Size > kept[j].Size
+				})
+			}
+			filtered.MergedByType[linkType] = kept
+			mergedTotal += len(kept)
+		}
+		// 只返回merged_by_type时总数为链接数，与搜索服务的计算方式一致
+		if response.Results == nil {
+			filtered.Total = mergedTotal
+		}
+	}
+
+	if response.Links != nil {
+		filtered.Links = make([]model.FlatLink, 0, len(response.Links))
+		for _, link := range response.Links {
+			if inRange(link.Datetime) {
+				filtered.Links = append(filtered.Links, link)
+			}
+		}
+		switch ranking {
+		case model.RankingTime:
+			sort.SliceStable(filtered.Links, func(i, j int) bool {
+				return filtered.Links[i].Datetime.After(filtered.Links[j].Datetime)
+			})
+		case model.RankingSize:
+			sort.SliceStable(filtered.Links, func(i, j int) bool {
+				return filtered.Links[i].Size > filtered.Links[j].Size
+			})
+		}
+		filtered.Total = len(filtered.Links)
+	}
+
+	return filtered
+}
+
+// maxLinkSize 结果中最大的链接大小，均未知时为0
+func maxLinkSize(result model.SearchResult) int64 {
+	var size int64
+	for _, link := range result.Links {
+		if link.Size > size {
+			size = link.Size
+		}
+	}
+	return size
+}
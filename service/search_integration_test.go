@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/testsupport"
+	"pansou/util"
+)
+
+// 集成测试使用的TG频道
+var integrationChannels = []string{"mockch1", "mockch2"}
+
+// searchIntegration 集成测试环境：模拟频道服务和模拟插件接入真实的搜索服务
+type searchIntegration struct {
+	service *SearchService
+	tg      *testsupport.TGServer
+	fast    *testsupport.FakePlugin // 在异步响应超时之内返回
+	slow    *testsupport.FakePlugin // 超过异步响应超时，结果在后台完成后写入缓存
+}
+
+// newSearchIntegration 初始化配置、缓存和模拟后端，缓存目录为测试的临时目录
+func newSearchIntegration(t *testing.T) *searchIntegration {
+	t.Helper()
+	// 磁盘缓存在后台异步写入，测试结束时可能仍在写入，不使用t.TempDir以免清理目录时报错
+	cacheDir, err := os.MkdirTemp("", "pansou-search-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(cacheDir) })
+	t.Setenv("CACHE_PATH", cacheDir)
+	t.Setenv("CACHE_ENABLED", "true")
+	t.Setenv("CHANNELS", "mockch1,mockch2")
+	t.Setenv("ASYNC_PLUGIN_ENABLED", "true")
+	t.Setenv("ASYNC_RESPONSE_TIMEOUT", "2")
+	config.Init()
+	util.InitHTTPClient()
+	plugin.InitAsyncPluginSystem()
+
+	tg := testsupport.NewTGServer(testsupport.TGServerOptions{PerPage: 5})
+	t.Cleanup(tg.Close)
+	t.Cleanup(tg.Install())
+
+	env := &searchIntegration{
+		tg:   tg,
+		fast: testsupport.NewFakePlugin("fakefast", testsupport.FakePluginOptions{}),
+		slow: testsupport.NewFakePlugin("fakeslow", testsupport.FakePluginOptions{Latency: 3 * time.Second}),
+	}
+	pluginManager := plugin.NewPluginManager()
+	pluginManager.RegisterPlugin(env.fast)
+	pluginManager.RegisterPlugin(env.slow)
+	env.service = NewSearchService(pluginManager)
+	if !cacheInitialized {
+		t.Fatal("缓存未初始化")
+	}
+	return env
+}
+
+// search 搜索全部来源并返回全部结果
+func (e *searchIntegration) search(t *testing.T, keyword string) model.SearchResponse {
+	t.Helper()
+	resp, err := e.service.Search(context.Background(), SearchOptions{
+		Keyword:    keyword,
+		Channels:   integrationChannels,
+		SourceType: "all",
+		ResultType: "all",
+	})
+	if err != nil {
+		t.Fatalf("搜索 %s 失败: %v", keyword, err)
+	}
+	return resp
+}
+
+// countSources 统计结果中来自TG频道和各插件的结果数
+func countSources(results []model.SearchResult) (tg int, plugins map[string]int) {
+	plugins = make(map[string]int)
+	for _, result := range results {
+		if result.Channel != "" {
+			tg++
+			continue
+		}
+		for _, name := range []string{"fakefast", "fakeslow"} {
+			if strings.HasPrefix(result.UniqueID, name+"-") {
+				plugins[name]++
+			}
+		}
+	}
+	return tg, plugins
+}
+
+func TestSearchIntegration(t *testing.T) {
+	env := newSearchIntegration(t)
+	// 插件缓存是进程级的，关键词带上时间戳，重复执行测试时不会命中上次的缓存
+	keyword := fmt.Sprintf("流浪地球 %d", time.Now().UnixNano())
+
+	// 冷启动：请求所有频道和插件，慢插件超过异步响应超时，本次只返回快插件和TG的结果
+	resp := env.search(t, keyword)
+	tg, plugins := countSources(resp.Results)
+	if tg == 0 {
+		t.Fatalf("冷启动搜索没有TG结果")
+	}
+	if plugins["fakefast"] == 0 {
+		t.Fatalf("冷启动搜索没有快插件的结果")
+	}
+	if plugins["fakeslow"] != 0 {
+		t.Fatalf("慢插件在异步响应超时之前返回了结果: %d", plugins["fakeslow"])
+	}
+	for _, channel := range integrationChannels {
+		if env.tg.Handler.Requests(channel) == 0 {
+			t.Fatalf("频道 %s 未被请求", channel)
+		}
+	}
+	tgRequests := env.tg.Handler.TotalRequests()
+	fastCalls := env.fast.Calls()
+
+	// 缓存命中：再次搜索不请求频道和快插件
+	resp = env.search(t, keyword)
+	if got := env.tg.Handler.TotalRequests(); got != tgRequests {
+		t.Fatalf("缓存命中时仍请求了频道: %d -> %d", tgRequests, got)
+	}
+	if got := env.fast.Calls(); got != fastCalls {
+		t.Fatalf("缓存命中时仍调用了快插件: %d -> %d", fastCalls, got)
+	}
+	if tg, plugins = countSources(resp.Results); tg == 0 || plugins["fakefast"] == 0 {
+		t.Fatalf("缓存命中的结果不完整: TG %d，快插件 %d", tg, plugins["fakefast"])
+	}
+
+	// 异步更新：慢插件在后台完成后更新主缓存，之后的搜索包含其结果且不再调用插件
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp = env.search(t, keyword)
+		if _, plugins = countSources(resp.Results); plugins["fakeslow"] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("慢插件的结果未在异步更新后出现，调用次数: %d", env.slow.Calls())
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if got := env.slow.Calls(); got != 1 {
+		t.Fatalf("慢插件应只执行一次搜索，实际 %d 次", got)
+	}
+	if got := env.tg.Handler.TotalRequests(); got != tgRequests {
+		t.Fatalf("异步更新后仍请求了频道: %d -> %d", tgRequests, got)
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+)
+
+var (
+	// ErrKeywordRequired 搜索关键词为空
+	ErrKeywordRequired = errors.New("搜索关键词不能为空")
+	// ErrInvalidSourceType 数据来源类型无效
+	ErrInvalidSourceType = errors.New("src必须为all、tg或plugin")
+	// ErrInvalidResultType 结果类型无效
+	ErrInvalidResultType = errors.New("res必须为all、results、merge或flat")
+	// ErrInvalidResolution 最低分辨率无效
+	ErrInvalidResolution = errors.New("min_resolution必须为480p、720p、1080p、2160p（4k）或4320p（8k）")
+	// ErrUnknownProfile 插件组合不存在
+	ErrUnknownProfile = errors.New("插件组合不存在")
+	// ErrProfileWithPlugins 同时指定了插件组合和插件
+	ErrProfileWithPlugins = errors.New("profile与plugins不能同时指定")
+)
+
+// SearchOptions 搜索参数。零值字段使用默认值：全部来源、merged_by_type结果、默认频道和并发数
+type SearchOptions struct {
+	Keyword         string                 // 搜索关键词，必填
+	Aliases         []string               // 关键词别名（如英文片名），与关键词一起搜索并交错合并结果
+	Channels        []string               // 搜索的TG频道，为空时使用默认频道
+	Concurrency     int                    // 并发搜索数量，<=0时使用默认并发数
+	Refresh         model.RefreshLevel     // 缓存刷新级别
+	ResultType      string                 // 结果类型：all、results、merged_by_type（merge）、flat
+	SourceType      string                 // 数据来源类型：all、tg、plugin
+	Plugins         []string               // 指定搜索的插件，为空时搜索全部插件
+	Profile         string                 // 插件组合（见PLUGIN_PROFILES），搜索时展开为组合内的插件，不能与Plugins同时指定
+	CloudTypes      []string               // 只返回这些网盘类型的链接，为空时不限制
+	Ext             map[string]interface{} // 传给插件的扩展参数
+	Languages       []string               // 只保留这些语言的结果
+	PreferredLang   string                 // 排序时提升该语言的结果
+	IncludeFiltered bool                   // 在响应的Filtered中返回未进入Results的结果及原因
+	Highlight       bool                   // 标注关键词在结果标题、内容和链接note中的命中位置
+	Rerank          string                 // 重排方式：为空不重排，semantic按语义相似度重排前K条
+	DeepLinks       bool                   // 为合并链接和扁平列表中的链接生成客户端跳转链接
+	MinResolution   string                 // 只保留标题标注的分辨率不低于该值的结果，为空时不限制
+	Debug           bool                   // 在响应的Timing中返回各频道、插件和缓存的耗时明细
+}
+
+// SearchOption 设置搜索参数的函数式选项，供程序内调用方使用
+type SearchOption func(*SearchOptions)
+
+// NewSearchOptions 用函数式选项构造搜索参数
+func NewSearchOptions(keyword string, opts ...SearchOption) SearchOptions {
+	options := SearchOptions{Keyword: keyword}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithAliases 同时搜索关键词的别名
+func WithAliases(aliases ...string) SearchOption {
+	return func(o *SearchOptions) { o.Aliases = aliases }
+}
+
+// WithChannels 指定搜索的TG频道
+func WithChannels(channels ...string) SearchOption {
+	return func(o *SearchOptions) { o.Channels = channels }
+}
+
+// WithConcurrency 指定并发搜索数量
+func WithConcurrency(concurrency int) SearchOption {
+	return func(o *SearchOptions) { o.Concurrency = concurrency }
+}
+
+// WithRefresh 指定缓存刷新级别
+func WithRefresh(level model.RefreshLevel) SearchOption {
+	return func(o *SearchOptions) { o.Refresh = level }
+}
+
+// WithForceRefresh 跳过全部缓存重新搜索，等同于refresh=true
+func WithForceRefresh(force bool) SearchOption {
+	return func(o *SearchOptions) { o.Refresh = model.RefreshLevelFromBool(force) }
+}
+
+// WithResultType 指定结果类型
+func WithResultType(resultType string) SearchOption {
+	return func(o *SearchOptions) { o.ResultType = resultType }
+}
+
+// WithSourceType 指定数据来源类型
+func WithSourceType(sourceType string) SearchOption {
+	return func(o *SearchOptions) { o.SourceType = sourceType }
+}
+
+// WithPlugins 指定搜索的插件
+func WithPlugins(plugins ...string) SearchOption {
+	return func(o *SearchOptions) { o.Plugins = plugins }
+}
+
+// WithProfile 指定搜索的插件组合
+func WithProfile(profile string) SearchOption {
+	return func(o *SearchOptions) { o.Profile = profile }
+}
+
+// WithCloudTypes 只返回指定网盘类型的链接
+func WithCloudTypes(cloudTypes ...string) SearchOption {
+	return func(o *SearchOptions) { o.CloudTypes = cloudTypes }
+}
+
+// WithExt 指定传给插件的扩展参数
+func WithExt(ext map[string]interface{}) SearchOption {
+	return func(o *SearchOptions) { o.Ext = ext }
+}
+
+// WithLanguages 只保留指定语言的结果
+func WithLanguages(languages ...string) SearchOption {
+	return func(o *SearchOptions) { o.Languages = languages }
+}
+
+// WithPreferredLang 排序时提升指定语言的结果
+func WithPreferredLang(lang string) SearchOption {
+	return func(o *SearchOptions) { o.PreferredLang = lang }
+}
+
+// WithIncludeFiltered 在响应中返回未进入Results的结果及原因
+func WithIncludeFiltered(include bool) SearchOption {
+	return func(o *SearchOptions) { o.IncludeFiltered = include }
+}
+
+// WithHighlight 标注关键词在结果标题、内容和链接note中的命中位置
+func WithHighlight(highlight bool) SearchOption {
+	return func(o *SearchOptions) { o.Highlight = highlight }
+}
+
+// WithRerank 指定重排方式，如RerankSemantic
+func WithRerank(rerank string) SearchOption {
+	return func(o *SearchOptions) { o.Rerank = rerank }
+}
+
+// WithDeepLinks 为链接生成客户端跳转链接
+func WithDeepLinks(deepLinks bool) SearchOption {
+	return func(o *SearchOptions) { o.DeepLinks = deepLinks }
+}
+
+// WithMinResolution 只保留标题标注的分辨率不低于resolution的结果
+func WithMinResolution(resolution string) SearchOption {
+	return func(o *SearchOptions) { o.MinResolution = resolution }
+}
+
+// WithDebug 在响应中返回耗时明细
+func WithDebug(debug bool) SearchOption {
+	return func(o *SearchOptions) { o.Debug = debug }
+}
+
+// Normalize 校验参数并填充默认值，可重复调用。并发数不在这里填充，
+// 调用方可以先按用户权限调整，未设置时由搜索服务使用默认并发数
+func (o *SearchOptions) Normalize() error {
+	switch o.ResultType {
+	case "", "merge":
+		// merge转换为merged_by_type，以兼容内部处理
+		o.ResultType = "merged_by_type"
+	case "merged_by_type", "all", "results", "flat":
+	default:
+		return ErrInvalidResultType
+	}
+
+	switch o.Rerank {
+	case RerankNone, RerankSemantic:
+	default:
+		return ErrInvalidRerank
+	}
+
+	if o.MinResolution != "" {
+		resolution := util.NormalizeResolution(o.MinResolution)
+		if resolution == "" {
+			return ErrInvalidResolution
+		}
+		o.MinResolution = resolution
+	}
+
+	switch o.SourceType {
+	case "":
+		o.SourceType = "all"
+	case "all", "tg", "plugin":
+	default:
+		return ErrInvalidSourceType
+	}
+
+	if len(o.Channels) == 0 {
+		o.Channels = config.GetDefaultChannels()
+	}
+
+	// 参数互斥：只搜索TG时忽略插件和插件组合，只搜索插件时忽略频道
+	switch o.SourceType {
+	case "tg":
+		o.Plugins = nil
+		o.Profile = ""
+	case "plugin":
+		o.Channels = nil
+	}
+	if len(o.Plugins) == 0 {
+		o.Plugins = nil
+	}
+
+	o.Profile = strings.ToLower(strings.TrimSpace(o.Profile))
+	if o.Profile != "" {
+		if !hasPluginProfile(o.Profile) {
+			return ErrUnknownProfile
+		}
+		if len(o.Plugins) > 0 {
+			return ErrProfileWithPlugins
+		}
+	}
+
+	if o.Ext == nil {
+		o.Ext = make(map[string]interface{})
+	}
+
+	// 关键词最后校验，使关键词为空时其余参数也已规范化（如携带分页令牌的请求）
+	o.Keyword = strings.TrimSpace(o.Keyword)
+	if o.Keyword == "" {
+		return ErrKeywordRequired
+	}
+	return nil
+}
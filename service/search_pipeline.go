@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util"
+)
+
+// SearchStage 搜索管线的阶段
+type SearchStage string
+
+// 搜索管线按以下顺序执行各阶段
+const (
+	StageResolve SearchStage = "resolve" // 确定搜索的来源：插件组合展开、租户限制、插件参数规范化、按关键词分类选择和跳过插件、并发数和别名
+	StageFanOut  SearchStage = "fanout"  // 并行搜索TG频道和插件（含缓存读取），各来源的结果写入SourceResults
+	StageMerge   SearchStage = "merge"   // 合并各来源的结果并标注语言和属性，写入Results
+	StageRank    SearchStage = "rank"    // 按综合得分排序Results
+	StageFilter  SearchStage = "filter"  // 执行后处理步骤和分辨率过滤，之后对保留的结果做语义重排
+	StageShape   SearchStage = "shape"   // 生成Results视图、合并链接和扁平列表，写入Response
+)
+
+// searchStages 管线各阶段的执行顺序
+var searchStages = []SearchStage{StageResolve, StageFanOut, StageMerge, StageRank, StageFilter, StageShape}
+
+// SearchState 一次搜索在管线各阶段之间传递的状态。
+// 中间件可以在调用下一层之前修改阶段的输入，或在之后修改阶段的输出
+type SearchState struct {
+	Options   SearchOptions // 规范化后的搜索参数
+	RequestID string        // 请求ID，用于关联日志
+
+	// resolve阶段的输出
+	Namespace      string                 // 租户的缓存命名空间，非租户请求为空
+	SourceType     string                 // 实际搜索的来源类型，租户不允许任何请求的插件时变为tg
+	Plugins        []string               // 实际搜索的插件，为nil时搜索全部插件
+	Concurrency    int                    // 并发搜索数量
+	Keywords       []string               // 主关键词和别名，主关键词在首位
+	Category       string                 // 关键词的内容分类
+	Ext            map[string]interface{} // 传给插件的扩展参数（已附加请求ID）
+	PreferredLangs map[string]bool        // 排序时优先的语言
+
+	// fanout阶段的输出：单个关键词时依次为TG频道和插件的结果，有别名时为交错合并后的结果。
+	// 中间件可以追加其他来源的结果，在merge阶段一起合并
+	SourceResults [][]model.SearchResult
+
+	// merge阶段之后的结果列表，rank和filter阶段就地更新。
+	// 结果可能与缓存共享，中间件需要返回新的切片，不能修改其中的结果
+	Results []model.SearchResult
+
+	// shape阶段的输出
+	Response model.SearchResponse
+
+	timing       *searchTimingRecorder
+	searchDone   time.Time // 所有来源返回的时间，之后为排序、过滤和合并
+	rerankCtx    context.Context
+	rerankCancel context.CancelFunc
+	done         bool
+}
+
+// Finish 以response结束搜索，跳过之后的阶段
+func (st *SearchState) Finish(response model.SearchResponse) {
+	st.Response = response
+	st.done = true
+}
+
+// aliased 是否同时搜索了别名
+func (st *SearchState) aliased() bool {
+	return len(st.Keywords) > 1
+}
+
+// withoutPlugins 没有可搜索的插件：只搜索插件时以空结果结束搜索，否则只搜索TG
+func (st *SearchState) withoutPlugins() {
+	if st.SourceType == "plugin" {
+		st.Finish(filterResponseByType(model.SearchResponse{Results: []model.SearchResult{}, MergedByType: model.MergedLinks{}}, st.Options.ResultType))
+		return
+	}
+	st.SourceType = "tg"
+}
+
+// release 释放搜索过程中占用的资源
+func (st *SearchState) release() {
+	if st.rerankCancel != nil {
+		st.rerankCancel()
+	}
+}
+
+// SearchStageFunc 执行一个搜索阶段
+type SearchStageFunc func(ctx context.Context, state *SearchState) error
+
+// SearchMiddleware 搜索管线中间件：包装每个阶段的执行，可以在阶段前后读取和修改SearchState，
+// 返回错误时搜索失败，不调用next时跳过该阶段。用于在不修改核心代码的情况下增加结果补充、合规过滤、统计分析等功能
+type SearchMiddleware interface {
+	// Name 返回中间件名称
+	Name() string
+
+	// Wrap 包装stage阶段的执行，不需要处理的阶段直接返回next
+	Wrap(stage SearchStage, next SearchStageFunc) SearchStageFunc
+}
+
+// stageMiddleware 只包装一个阶段的中间件
+type stageMiddleware struct {
+	name  string
+	stage SearchStage
+	fn    func(ctx context.Context, state *SearchState, next SearchStageFunc) error
+}
+
+func (m stageMiddleware) Name() string { return m.name }
+
+func (m stageMiddleware) Wrap(stage SearchStage, next SearchStageFunc) SearchStageFunc {
+	if stage != m.stage {
+		return next
+	}
+	return func(ctx context.Context, state *SearchState) error {
+		return m.fn(ctx, state, next)
+	}
+}
+
+// NewSearchMiddleware 用函数创建只包装stage阶段的中间件，fn中调用next执行该阶段
+func NewSearchMiddleware(name string, stage SearchStage, fn func(ctx context.Context, state *SearchState, next SearchStageFunc) error) SearchMiddleware {
+	return stageMiddleware{name: name, stage: stage, fn: fn}
+}
+
+var (
+	// 已注册的中间件，按注册顺序排列，先注册的在外层
+	searchMiddlewares     []SearchMiddleware
+	searchMiddlewaresLock sync.RWMutex
+)
+
+// RegisterSearchMiddleware 注册搜索管线中间件，同名中间件会被替换并保持原来的位置。
+// 通常在init中注册，注册后的搜索立即生效
+func RegisterSearchMiddleware(m SearchMiddleware) {
+	if m == nil || m.Name() == "" {
+		return
+	}
+	searchMiddlewaresLock.Lock()
+	defer searchMiddlewaresLock.Unlock()
+	for i, existing := range searchMiddlewares {
+		if existing.Name() == m.Name() {
+			searchMiddlewares[i] = m
+			return
+		}
+	}
+	searchMiddlewares = append(searchMiddlewares, m)
+}
+
+// SearchMiddlewareNames 获取已注册的中间件名称，按执行顺序排列
+func SearchMiddlewareNames() []string {
+	searchMiddlewaresLock.RLock()
+	defer searchMiddlewaresLock.RUnlock()
+	names := make([]string, 0, len(searchMiddlewares))
+	for _, m := range searchMiddlewares {
+		names = append(names, m.Name())
+	}
+	return names
+}
+
+// runSearchPipeline 依次执行各阶段，每个阶段由已注册的中间件包装。阶段出错或调用了Finish时停止
+func (s *SearchService) runSearchPipeline(ctx context.Context, state *SearchState) error {
+	searchMiddlewaresLock.RLock()
+	middlewares := append([]SearchMiddleware(nil), searchMiddlewares...)
+	searchMiddlewaresLock.RUnlock()
+
+	for _, stage := range searchStages {
+		run := s.stageFunc(stage)
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			run = middlewares[i].Wrap(stage, run)
+		}
+		if err := run(ctx, state); err != nil {
+			return err
+		}
+		if state.done {
+			break
+		}
+	}
+
+	// 维护模式下结果只来自缓存，附加维护提示。在此处附加，提前结束搜索的响应同样带有提示
+	if status := GetMaintenanceStatus(); status.Enabled {
+		state.Response.Maintenance = true
+		state.Response.Notice = status.Message
+	}
+	return nil
+}
+
+// stageFunc 获取阶段的核心实现
+func (s *SearchService) stageFunc(stage SearchStage) SearchStageFunc {
+	switch stage {
+	case StageResolve:
+		return s.resolveSources
+	case StageFanOut:
+		return s.fanOut
+	case StageMerge:
+		return mergeStage
+	case StageRank:
+		return rankStage
+	case StageFilter:
+		return filterStage
+	default:
+		return shapeStage
+	}
+}
+
+// resolveSources 确定本次搜索的来源、并发数和关键词
+func (s *SearchService) resolveSources(ctx context.Context, state *SearchState) error {
+	opts := state.Options
+	state.SourceType, state.Plugins, state.Concurrency = opts.SourceType, opts.Plugins, opts.Concurrency
+
+	// 复制ext并附加请求ID，避免修改调用方的map
+	state.Ext = make(map[string]interface{}, len(opts.Ext)+1)
+	for k, v := range opts.Ext {
+		state.Ext[k] = v
+	}
+	if state.RequestID != "" {
+		state.Ext[plugin.ExtKeyRequestID] = state.RequestID
+	}
+
+	// 插件组合：展开为组合内已加载的插件，没有匹配的插件时只搜索TG
+	if opts.Profile != "" && state.SourceType != "tg" {
+		state.Plugins = s.expandPluginProfile(opts.Profile)
+		if len(state.Plugins) == 0 {
+			if state.withoutPlugins(); state.done {
+				return nil
+			}
+		}
+	}
+
+	// 租户：使用独立的缓存命名空间，插件限制在租户允许的范围内
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		state.Namespace = tenant.ID
+		if state.SourceType != "tg" {
+			plugins, ok := restrictTenantPlugins(tenant, state.Plugins)
+			if !ok {
+				// 请求的插件均不被租户允许，只搜索TG
+				if state.withoutPlugins(); state.done {
+					return nil
+				}
+			}
+			state.Plugins = plugins
+		}
+	}
+
+	state.Plugins = s.normalizeRequestedPlugins(state.SourceType, state.Plugins)
+
+	// 按关键词分类只搜索可能有结果的插件（请求指定插件时不调整）
+	state.Category = ClassifyQuery(opts.Keyword)
+	if state.Plugins == nil && state.SourceType != "tg" {
+		if config.AppConfig.QueryCategoryRouting {
+			state.Plugins = s.routePluginsByCategory(state.Category)
+		}
+		// 跳过在该分类下长期没有结果的插件
+		if config.AppConfig.ZeroHitSkip {
+			state.Plugins = s.skipZeroHitPlugins(state.Category, state.Plugins)
+		}
+	}
+
+	// 如果未指定并发数，使用配置中的默认值
+	if state.Concurrency <= 0 {
+		state.Concurrency = defaultSearchConcurrency()
+	}
+
+	// 关键词与别名去重（保持主关键词在前）
+	state.Keywords = buildKeywordSet(opts.Keyword, opts.Aliases)
+
+	// 偏好语言（"zh"同时偏好简繁两种）
+	state.PreferredLangs = util.ExpandLanguages([]string{opts.PreferredLang})
+	return nil
+}
+
+// normalizeRequestedPlugins 规范化插件参数：只搜索TG时忽略插件参数，
+// 未指定、全为空字符串或包含全部插件时返回nil（搜索全部插件）
+func (s *SearchService) normalizeRequestedPlugins(sourceType string, plugins []string) []string {
+	if sourceType == "tg" {
+		return nil
+	}
+	if sourceType != "all" && sourceType != "plugin" {
+		return plugins
+	}
+
+	// 创建请求的插件名称集合（忽略空字符串）
+	requested := make(map[string]bool, len(plugins))
+	for _, p := range plugins {
+		if p != "" {
+			requested[strings.ToLower(p)] = true
+		}
+	}
+	if len(requested) == 0 {
+		return nil
+	}
+
+	// 如果请求的插件数量与所有插件数量相同且包含所有插件，统一设为nil
+	nonEmpty := 0
+	for _, p := range plugins {
+		if p != "" {
+			nonEmpty++
+		}
+	}
+	allPlugins := s.pluginManager.GetPlugins()
+	if nonEmpty != len(allPlugins) {
+		return plugins
+	}
+	for _, p := range allPlugins {
+		if !requested[strings.ToLower(p.Name())] {
+			return plugins
+		}
+	}
+	return nil
+}
+
+// fanOut 并行搜索TG频道和插件
+func (s *SearchService) fanOut(ctx context.Context, state *SearchState) error {
+	opts := state.Options
+	if state.aliased() {
+		// 别名组合搜索：结果已按关键词排序并交错
+		results, err := s.searchAliases(state.RequestID, state.Namespace, state.Keywords, opts.Channels, opts.Refresh, state.SourceType, state.Plugins, state.Concurrency, state.Ext)
+		if err != nil {
+			return err
+		}
+		state.SourceResults = [][]model.SearchResult{results}
+	} else {
+		tgResults, pluginResults, err := s.searchKeywordSources(state.RequestID, state.Namespace, opts.Keyword, opts.Channels, opts.Refresh, state.SourceType, state.Plugins, state.Concurrency, state.Ext)
+		if err != nil {
+			return err
+		}
+		state.SourceResults = [][]model.SearchResult{tgResults, pluginResults}
+	}
+	state.searchDone = time.Now()
+
+	// 只搜索了部分插件时，在后台按未指定插件的请求补全缓存
+	s.scheduleBackfill(ctx, state)
+	return nil
+}
+
+// mergeStage 合并各来源的结果：单个关键词时按链接去重合并，有别名时交错合并
+func mergeStage(ctx context.Context, state *SearchState) error {
+	lists := state.SourceResults
+	if state.aliased() {
+		if len(lists) == 1 {
+			state.Results = lists[0]
+			return nil
+		}
+		state.Results = interleaveResults(lists)
+	} else {
+		switch len(lists) {
+		case 0:
+			state.Results = nil
+		case 1:
+			state.Results = mergeSearchResults(lists[0], nil)
+		default:
+			state.Results = mergeSearchResults(lists[0], lists[1])
+			for _, list := range lists[2:] {
+				state.Results = mergeSearchResults(state.Results, list)
+			}
+		}
+	}
+	tagResultLanguages(state.Results)
+	tagResultAttributes(state.Results)
+	return nil
+}
+
+// rankStage 按综合得分排序结果
+func rankStage(ctx context.Context, state *SearchState) error {
+	if !state.aliased() {
+		// 按照优化后的规则排序结果
+		sortResultsWithLanguagePreference(state.Results, state.PreferredLangs)
+		return nil
+	}
+
+	// 保持交错顺序，仅将偏好语言的结果整体提前（结果可能正被异步写入缓存，先复制）
+	if len(state.PreferredLangs) > 0 {
+		results := append([]model.SearchResult(nil), state.Results...)
+		sort.SliceStable(results, func(i, j int) bool {
+			return state.PreferredLangs[results[i].Language] && !state.PreferredLangs[results[j].Language]
+		})
+		state.Results = results
+	}
+	return nil
+}
+
+// filterStage 执行后处理步骤和分辨率过滤，之后按请求做语义重排
+func filterStage(ctx context.Context, state *SearchState) error {
+	opts := state.Options
+
+	// 按POST_PROCESSORS配置的顺序执行后处理步骤（默认只按语言过滤，无法识别语言的结果不保留）
+	state.Results = runPostProcessors(&PostProcessContext{
+		Keyword:   opts.Keyword,
+		Keywords:  state.Keywords,
+		Languages: util.ExpandLanguages(opts.Languages),
+	}, state.Results)
+
+	// 只保留标题标注的分辨率达到要求的结果
+	if opts.MinResolution != "" {
+		state.Results = filterResultsByResolution(state.Results, opts.MinResolution)
+	}
+
+	// 语义重排：results和合并链接共用一个延迟预算，超时或出错时保持原排序
+	if opts.Rerank == RerankSemantic {
+		state.rerankCtx, state.rerankCancel = context.WithTimeout(ctx, config.AppConfig.RerankTimeout)
+		if reranked, err := GetSemanticReranker().RerankResults(state.rerankCtx, opts.Keyword, state.Results); err != nil {
+			fmt.Printf("%s[语义重排] 保持原排序: %s | 错误: %v\n", util.RequestLogTag(state.RequestID), opts.Keyword, err)
+		} else {
+			state.Results = reranked
+		}
+	}
+	return nil
+}
+
+// shapeStage 按结果类型生成响应
+func shapeStage(ctx context.Context, state *SearchState) error {
+	opts := state.Options
+	keyword, resultType := opts.Keyword, opts.ResultType
+	allResults := state.Results
+
+	// 只计算响应中会返回的视图：results时不合并链接，merged_by_type和flat时不筛选Results
+	needResults := resultType != "merged_by_type" && resultType != "flat"
+	needMerged := resultType != "results"
+
+	// 关键词命中位置在下面的筛选和合并过程中顺带计算
+	var highlight []string
+	if opts.Highlight {
+		highlight = highlightTerms(state.Keywords)
+	}
+
+	// 过滤结果，只保留有时间的结果或包含优先关键词的结果或高等级插件结果到Results中
+	var filteredForResults []model.SearchResult
+	var filteredOut []model.FilteredResult
+	var droppedResults int
+	if needResults {
+		filteredForResults, filteredOut = filterResultsForResultsView(allResults, opts.IncludeFiltered, highlight)
+		filteredForResults, droppedResults = capResults(filteredForResults, config.AppConfig.ResponseLinkCap)
+	}
+
+	// 合并链接按网盘类型分组（使用所有过滤后的结果）
+	var mergedLinks model.MergedLinks
+	var droppedByType map[string]int
+	if needMerged {
+		// 每种网盘类型的链接按与Results相同的综合得分排序，而不是按链接出现的顺序。
+		// 相同的结果和合并选项在MERGE_MEMO_TTL内复用上次的合并结果
+		mergedLinks = mergeAndSortLinks(allResults, state.Keywords, opts.CloudTypes, highlight, state.PreferredLangs)
+		// 记录链接的出现（标题、关键词、来源），供/api/lookup反查
+		recordLinkSightings(state.Namespace, keyword, mergedLinks)
+		if state.rerankCtx != nil && state.rerankCtx.Err() == nil {
+			if err := GetSemanticReranker().RerankMergedLinks(state.rerankCtx, keyword, mergedLinks); err != nil {
+				fmt.Printf("%s[语义重排] 合并链接保持原排序: %s | 错误: %v\n", util.RequestLogTag(state.RequestID), keyword, err)
+			}
+		}
+
+		// 链接数超过上限时按排序截断，被丢弃的链接不分配跳转ID也不记为已见
+		mergedLinks, droppedByType = capMergedLinks(mergedLinks, allResults, config.AppConfig.ResponseLinkCap)
+
+		// 为合并链接分配跳转ID，用于点击统计
+		if config.AppConfig.ClickTrackingEnabled {
+			GetClickService().RegisterMergedLinks(mergedLinks, keyword)
+		}
+
+		// 标记关键词上次搜索以来新出现的链接
+		markNewLinks(state.Namespace, keyword, mergedLinks)
+	}
+
+	// 构建响应
+	var total int
+	if resultType == "merged_by_type" {
+		// 计算所有类型链接的总数
+		for _, links := range mergedLinks {
+			total += len(links)
+		}
+	} else {
+		// 只计算filteredForResults的数量
+		total = len(filteredForResults)
+	}
+
+	response := model.SearchResponse{
+		Total:          total,
+		Results:        filteredForResults, // 使用进一步过滤的结果
+		MergedByType:   mergedLinks,
+		Truncated:      droppedByType != nil || droppedResults > 0,
+		DroppedByType:  droppedByType,
+		DroppedResults: droppedResults,
+		Category:       state.Category,
+		Filtered:       filteredOut,
+	}
+
+	// 扁平列表按结果的排序展开合并链接
+	if resultType == "flat" {
+		response.Links = flattenMergedLinks(mergedLinks, allResults)
+	}
+
+	// 按来源可信度处理链接和提取码的展示
+	applySourceTrust(&response, keyword)
+
+	// 按模板生成客户端跳转链接（社区来源的链接除外）
+	if opts.DeepLinks {
+		applyDeepLinks(&response)
+	}
+
+	// 记录关键词和结果标题用于搜索建议（租户的搜索不计入，避免泄露到其他租户）
+	if config.AppConfig.SuggestEnabled && state.Namespace == "" {
+		GetSuggestService().Record(keyword, allResults)
+	}
+
+	// 根据resultType过滤返回结果
+	response = filterResponseByType(response, resultType)
+
+	// 统计返回的各类型链接数，见/api/cloudtypes
+	recordResponseCloudTypes(response)
+
+	// 附加耗时明细
+	response.Timing = state.timing.finish(state.searchDone)
+	state.Response = response
+	return nil
+}
@@ -2,11 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -61,8 +61,8 @@ func extractKeywordFromCacheKey(cacheKey string) string {
 
 // logAsyncCacheWithKeyword 异步缓存日志输出辅助函数（带关键词）
 func logAsyncCacheWithKeyword(keyword, cacheKey string, format string, args ...interface{}) {
-	// 检查配置开关
-	if config.AppConfig == nil || !config.AppConfig.AsyncLogEnabled {
+	// 检查日志开关和采样
+	if !util.SampledLogEnabled() {
 		return
 	}
 	
@@ -105,10 +105,15 @@ func init() {
 func mergeSearchResults(existing []model.SearchResult, newResults []model.SearchResult) []model.SearchResult {
 	// 使用map进行去重和合并，以UniqueID作为唯一标识
 	resultMap := make(map[string]model.SearchResult)
+	// 记录首次出现顺序，避免依赖map遍历顺序
+	keys := make([]string, 0, len(existing)+len(newResults))
 	
 	// 先添加现有结果
 	for _, result := range existing {
 		key := generateResultKey(result)
+		if _, exists := resultMap[key]; !exists {
+			keys = append(keys, key)
+		}
 		resultMap[key] = result
 	}
 	
@@ -121,18 +126,22 @@ func mergeSearchResults(existing []model.SearchResult, newResults []model.Search
 		} else {
 			// 新结果，直接添加
 			resultMap[key] = newResult
+			keys = append(keys, key)
 		}
 	}
 	
 	// 转换回切片
 	merged := make([]model.SearchResult, 0, len(resultMap))
-	for _, result := range resultMap {
-		merged = append(merged, result)
+	for _, key := range keys {
+		merged = append(merged, resultMap[key])
 	}
 	
-	// 按时间排序（最新的在前）
-	sort.Slice(merged, func(i, j int) bool {
-		return merged[i].Datetime.After(merged[j].Datetime)
+	// 按时间排序（最新的在前），时间相同时按唯一标识排序，保证结果顺序确定
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].Datetime.Equal(merged[j].Datetime) {
+			return merged[i].Datetime.After(merged[j].Datetime)
+		}
+		return generateResultKey(merged[i]) < generateResultKey(merged[j])
 	})
 	
 	return merged
@@ -217,6 +226,28 @@ func NewSearchService(pluginManager *plugin.PluginManager) *SearchService {
 	// 将主缓存注入到异步插件中
 	injectMainCacheToAsyncPlugins(pluginManager, enhancedTwoLevelCache)
 	
+	// NO_PERSIST_SOURCES中来源的结果只保存在内存缓存中
+	installNoPersistFilter(enhancedTwoLevelCache)
+	
+	// debug=true时记录插件结果来自实时搜索还是插件缓存
+	plugin.SetProvenanceRecorder(func(requestID string, pluginName string, provenance string) {
+		searchTimingFor(requestID).recordProvenance("plugin:"+pluginName, provenance)
+		// 超时返回的空结果不计入插件的零结果统计
+		if provenance == model.ProvenancePartial {
+			GetPluginZeroHits().markPartial(requestID, pluginName)
+		}
+	})
+	
+	// 运行时注册、替换或注销插件时同步插件管理器，并为新插件注入缓存更新函数
+	if pluginManager != nil {
+		plugin.OnRegistryChange(func(event plugin.RegistryEvent) {
+			syncPluginManager(pluginManager, event)
+		})
+		
+		// 未指定插件的缓存键包含当前插件集合的指纹，插件集合变化后不再命中按旧插件集合生成的缓存
+		cache.SetPluginSetFingerprint(pluginManager.Fingerprint)
+	}
+	
 	// 确保缓存写入管理器设置了主缓存更新函数
 	if globalCacheWriteManager != nil && enhancedTwoLevelCache != nil {
 		globalCacheWriteManager.SetMainCacheUpdater(func(key string, data []byte, ttl time.Duration) error {
@@ -242,8 +273,27 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 		plugin.SetGlobalCacheSerializer(serializer)
 	}
 	
-	// 创建缓存更新函数（支持IsFinal参数）- 接收原始数据并与现有缓存合并
-	cacheUpdater := func(key string, newResults []model.SearchResult, ttl time.Duration, isFinal bool, keyword string, pluginName string) error {
+	// 遍历所有插件，注入缓存更新函数
+	cacheUpdater := newPluginCacheUpdater(mainCache)
+	for _, p := range pluginManager.GetPlugins() {
+		injectCacheUpdaterToPlugin(p, cacheUpdater)
+	}
+}
+
+// pluginCacheUpdater 插件结果写入主缓存的函数，最后一个参数为插件名称
+type pluginCacheUpdater func(key string, newResults []model.SearchResult, ttl time.Duration, isFinal bool, keyword string, pluginName string) error
+
+// newPluginCacheUpdater 创建缓存更新函数（支持IsFinal参数）- 接收原始数据并与现有缓存合并
+func newPluginCacheUpdater(mainCache *cache.EnhancedTwoLevelCache) pluginCacheUpdater {
+	return func(key string, newResults []model.SearchResult, ttl time.Duration, isFinal bool, keyword string, pluginName string) error {
+		// 按结果完整性和插件等级确定缓存有效期，部分结果被记录下来，下次访问时重新搜索
+		tier := cacheTierFor(isFinal, pluginName)
+		markCacheTier(key, pluginName, tier)
+		ttl = cacheTTLForKey(key, tier)
+		
+		// 异步插件结果同样在写入缓存前经过内容安全过滤
+		newResults = GetContentSafetyFilter().Filter(newResults)
+		
 		// 优化：如果新结果为空，跳过缓存更新（避免无效操作）
 		if len(newResults) == 0 {
 			return nil
@@ -256,7 +306,7 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 			if err := mainCache.GetSerializer().Deserialize(existingData, &existingResults); err == nil {
 				// 合并新旧结果，去重保留最完整的数据
 				finalResults = mergeSearchResults(existingResults, newResults)
-				if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
+				if util.PluginLogEnabled(pluginName) {
 					if keyword != "" {
 						fmt.Printf("🔄 [%s:%s] 更新缓存| 原有: %d + 新增: %d = 合并后: %d\n", 
 						pluginName, keyword, len(existingResults), len(newResults), len(finalResults))
@@ -265,7 +315,7 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 			} else {
 				// 反序列化失败，使用新结果
 				finalResults = newResults
-							if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
+							if util.PluginLogEnabled(pluginName) {
 				displayKey := key[:8] + "..."
 				if keyword != "" {
 					fmt.Printf("[异步插件 %s] 缓存反序列化失败，使用新结果: %s(关键词:%s) | 结果数: %d\n", pluginName, displayKey, keyword, len(newResults))
@@ -277,7 +327,7 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 		} else {
 			// 无现有缓存，直接使用新结果
 			finalResults = newResults
-					if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
+					if util.PluginLogEnabled(pluginName) {
 			displayKey := key[:8] + "..."
 			if keyword != "" {
 				fmt.Printf("[异步插件 %s] 初始缓存创建: %s(关键词:%s) | 结果数: %d\n", pluginName, displayKey, keyword, len(newResults))
@@ -329,105 +379,95 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 		}
 	}
 	
-	// 获取所有插件
-	plugins := pluginManager.GetPlugins()
-	
-	// 遍历所有插件，找出异步插件
-	for _, p := range plugins {
-		// 检查插件是否实现了SetMainCacheUpdater方法（修复后的签名，增加关键词参数）
-		if asyncPlugin, ok := p.(interface{ SetMainCacheUpdater(func(string, []model.SearchResult, time.Duration, bool, string) error) }); ok {
-			// 为每个插件创建专门的缓存更新函数，绑定插件名称
-			pluginName := p.Name()
-			pluginCacheUpdater := func(key string, newResults []model.SearchResult, ttl time.Duration, isFinal bool, keyword string) error {
-				return cacheUpdater(key, newResults, ttl, isFinal, keyword, pluginName)
-			}
-			// 注入缓存更新函数
-			asyncPlugin.SetMainCacheUpdater(pluginCacheUpdater)
-		}
-	}
 }
 
-// Search 执行搜索
-func (s *SearchService) Search(keyword string, channels []string, concurrency int, forceRefresh bool, resultType string, sourceType string, plugins []string, cloudTypes []string, ext map[string]interface{}) (model.SearchResponse, error) {
-	// 确保ext不为nil
-	if ext == nil {
-		ext = make(map[string]interface{})
+// syncPluginManager 按注册表变更同步插件管理器：新注册的插件在启用插件功能时加入，替换的插件沿用原插件的启用状态，
+// 注销的插件被移除；加入或替换的插件注入主缓存更新函数
+func syncPluginManager(pluginManager *plugin.PluginManager, event plugin.RegistryEvent) {
+	switch event.Type {
+	case plugin.PluginDeregistered:
+		if pluginManager.RemovePlugin(event.Name) {
+			fmt.Printf("[插件注册] 已移除插件: %s\n", event.Name)
+		}
+		return
+	case plugin.PluginReplaced:
+		if !pluginManager.ReplacePlugin(event.Plugin) {
+			return
+		}
+		fmt.Printf("[插件注册] 已替换插件: %s\n", event.Name)
+	case plugin.PluginRegistered:
+		if config.AppConfig == nil || !config.AppConfig.AsyncPluginEnabled {
+			return
+		}
+		pluginManager.RegisterPlugin(event.Plugin)
+		fmt.Printf("[插件注册] 已加入插件: %s\n", event.Name)
 	}
 	
-	// 参数预处理
-	// 源类型标准化
-	if sourceType == "" {
-		sourceType = "all"
+	if mainCache := enhancedTwoLevelCache; mainCache != nil {
+		injectCacheUpdaterToPlugin(event.Plugin, newPluginCacheUpdater(mainCache))
 	}
+}
 
-	// 插件参数规范化处理
-	if sourceType == "tg" {
-		// 对于只搜索Telegram的请求，忽略插件参数
-		plugins = nil
-	} else if sourceType == "all" || sourceType == "plugin" {
-		// 检查是否为空列表或只包含空字符串
-		if plugins == nil || len(plugins) == 0 {
-			plugins = nil
-		} else {
-			// 检查是否有非空元素
-			hasNonEmpty := false
-			for _, p := range plugins {
-				if p != "" {
-					hasNonEmpty = true
-					break
-				}
-			}
-
-			// 如果全是空字符串，视为未指定
-			if !hasNonEmpty {
-				plugins = nil
-			} else {
-				// 检查是否包含所有插件
-				allPlugins := s.pluginManager.GetPlugins()
-				allPluginNames := make([]string, 0, len(allPlugins))
-				for _, p := range allPlugins {
-					allPluginNames = append(allPluginNames, strings.ToLower(p.Name()))
-				}
-
-				// 创建请求的插件名称集合（忽略空字符串）
-				requestedPlugins := make([]string, 0, len(plugins))
-				for _, p := range plugins {
-					if p != "" {
-						requestedPlugins = append(requestedPlugins, strings.ToLower(p))
-					}
-				}
-
-				// 如果请求的插件数量与所有插件数量相同，检查是否包含所有插件
-				if len(requestedPlugins) == len(allPluginNames) {
-					// 创建映射以便快速查找
-					pluginMap := make(map[string]bool)
-					for _, p := range requestedPlugins {
-						pluginMap[p] = true
-					}
+// injectCacheUpdaterToPlugin 向单个插件注入绑定了插件名称的缓存更新函数
+func injectCacheUpdaterToPlugin(p plugin.AsyncSearchPlugin, cacheUpdater pluginCacheUpdater) {
+	// 检查插件是否实现了SetMainCacheUpdater方法（修复后的签名，增加关键词参数）
+	if asyncPlugin, ok := p.(interface{ SetMainCacheUpdater(func(string, []model.SearchResult, time.Duration, bool, string) error) }); ok {
+		// 为每个插件创建专门的缓存更新函数，绑定插件名称
+		pluginName := p.Name()
+		asyncPlugin.SetMainCacheUpdater(func(key string, newResults []model.SearchResult, ttl time.Duration, isFinal bool, keyword string) error {
+			return cacheUpdater(key, newResults, ttl, isFinal, keyword, pluginName)
+		})
+	}
+}
 
-					// 检查是否包含所有插件
-					allIncluded := true
-					for _, name := range allPluginNames {
-						if !pluginMap[name] {
-							allIncluded = false
-							break
-						}
-					}
+// SearchKeyword 用函数式选项执行搜索，供程序内调用，如
+// SearchKeyword(ctx, "速度与激情", WithSourceType("plugin"), WithCloudTypes("baidu"))
+func (s *SearchService) SearchKeyword(ctx context.Context, keyword string, opts ...SearchOption) (model.SearchResponse, error) {
+	return s.Search(ctx, NewSearchOptions(keyword, opts...))
+}
 
-					// 如果包含所有插件，统一设为nil
-					if allIncluded {
-						plugins = nil
-					}
-				}
-			}
+// Search 执行搜索，同时搜索关键词的别名（如中英文片名），
+// 各关键词的结果按链接去重后交错合并，整组别名作为一个逻辑查询缓存。
+// opts.Refresh指定缓存刷新级别，可只刷新TG或插件部分的结果。
+// opts.Languages非空时只保留对应语言的结果，opts.PreferredLang非空时优先排列该语言的结果。
+// ctx中携带的请求ID会贯穿服务与插件日志。
+// 搜索按resolve、fanout、merge、rank、filter、shape阶段执行，每个阶段由RegisterSearchMiddleware注册的中间件包装
+func (s *SearchService) Search(ctx context.Context, opts SearchOptions) (model.SearchResponse, error) {
+	if err := opts.Normalize(); err != nil {
+		return model.SearchResponse{}, err
+	}
+	state := &SearchState{Options: opts, RequestID: util.RequestIDFromContext(ctx)}
+	defer state.release()
+	
+	// debug=true时记录各来源的耗时，按请求ID关联，没有请求ID时生成一个
+	if opts.Debug {
+		if state.RequestID == "" {
+			state.RequestID = util.NewRequestID()
 		}
+		var stop func()
+		state.timing, stop = startSearchTiming(state.RequestID)
+		defer stop()
 	}
 	
-	// 如果未指定并发数，使用配置中的默认值
-	if concurrency <= 0 {
-		concurrency = config.AppConfig.DefaultConcurrency
+	if err := s.runSearchPipeline(ctx, state); err != nil {
+		return model.SearchResponse{}, err
+	}
+	return state.Response, nil
+}
+
+// searchKeyword 并行搜索单个关键词的TG频道和插件，返回合并后的结果（未排序）
+func (s *SearchService) searchKeyword(requestID string, namespace string, keyword string, channels []string, refresh model.RefreshLevel, sourceType string, plugins []string, concurrency int, ext map[string]interface{}) ([]model.SearchResult, error) {
+	tgResults, pluginResults, err := s.searchKeywordSources(requestID, namespace, keyword, channels, refresh, sourceType, plugins, concurrency, ext)
+	if err != nil {
+		return nil, err
 	}
+	
+	// 合并结果
+	return mergeSearchResults(tgResults, pluginResults), nil
+}
 
+// searchKeywordSources 并行搜索单个关键词的TG频道和插件，分别返回两者的结果
+func (s *SearchService) searchKeywordSources(requestID string, namespace string, keyword string, channels []string, refresh model.RefreshLevel, sourceType string, plugins []string, concurrency int, ext map[string]interface{}) ([]model.SearchResult, []model.SearchResult, error) {
 	// 并行获取TG搜索和插件搜索结果
 	var tgResults []model.SearchResult
 	var pluginResults []model.SearchResult
@@ -440,7 +480,7 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			tgResults, tgErr = s.searchTG(keyword, channels, forceRefresh)
+			tgResults, tgErr = s.searchTG(requestID, namespace, keyword, channels, refresh)
 		}()
 	}
 	// 如果需要搜索插件（且插件功能已启用）
@@ -449,8 +489,9 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 		go func() {
 			defer wg.Done()
 			// 对于插件搜索，我们总是希望获取最新的缓存数据
-			// 因此，即使forceRefresh=false，我们也需要确保获取到最新的缓存
-			pluginResults, pluginErr = s.searchPlugins(keyword, plugins, forceRefresh, concurrency, ext)
+			// 因此，即使不刷新，我们也需要确保获取到最新的缓存。
+			// 启用关键词翻译时，英文站点插件使用片名对照表中的译名搜索
+			pluginResults, pluginErr = s.searchPluginsTranslated(requestID, namespace, keyword, plugins, refresh, concurrency, ext)
 		}()
 	}
 	
@@ -459,54 +500,128 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 	
 	// 检查错误
 	if tgErr != nil {
-		return model.SearchResponse{}, tgErr
+		fmt.Printf("%s❌ [%s] TG搜索失败: %v\n", util.RequestLogTag(requestID), keyword, tgErr)
+		return nil, nil, tgErr
 	}
 	if pluginErr != nil {
-		return model.SearchResponse{}, pluginErr
+		fmt.Printf("%s❌ [%s] 插件搜索失败: %v\n", util.RequestLogTag(requestID), keyword, pluginErr)
+		return nil, nil, pluginErr
 	}
-	
-	// 合并结果
-	allResults := mergeSearchResults(tgResults, pluginResults)
+	return tgResults, pluginResults, nil
+}
 
-	// 按照优化后的规则排序结果
-	sortResultsByTimeAndKeywords(allResults)
+// 单次搜索最多附带的别名数量
+const maxSearchAliases = 4
 
-	// 过滤结果，只保留有时间的结果或包含优先关键词的结果或高等级插件结果到Results中
-	filteredForResults := make([]model.SearchResult, 0, len(allResults))
-	for _, result := range allResults {
-		source := getResultSource(result)
-		pluginLevel := getPluginLevelBySource(source)
-		
-		// 有时间的结果或包含优先关键词的结果或高等级插件(1-2级)结果保留在Results中
-		if !result.Datetime.IsZero() || getKeywordPriority(result.Title) > 0 || pluginLevel <= 2 {
-			filteredForResults = append(filteredForResults, result)
-		}
+// buildKeywordSet 合并关键词与别名，去除空白和重复项（不区分大小写），主关键词始终在首位
+func buildKeywordSet(keyword string, aliases []string) []string {
+	if len(aliases) > maxSearchAliases {
+		aliases = aliases[:maxSearchAliases]
 	}
 
-	// 合并链接按网盘类型分组（使用所有过滤后的结果）
-	mergedLinks := mergeResultsByType(allResults, keyword, cloudTypes)
-
-	// 构建响应
-	var total int
-	if resultType == "merged_by_type" {
-		// 计算所有类型链接的总数
-		total = 0
-		for _, links := range mergedLinks {
-			total += len(links)
+	keywords := make([]string, 0, len(aliases)+1)
+	seen := make(map[string]bool, len(aliases)+1)
+	for _, kw := range append([]string{keyword}, aliases...) {
+		kw = strings.TrimSpace(kw)
+		lower := strings.ToLower(kw)
+		if kw == "" || seen[lower] {
+			continue
 		}
-	} else {
-		// 只计算filteredForResults的数量
-		total = len(filteredForResults)
+		seen[lower] = true
+		keywords = append(keywords, kw)
 	}
+	if len(keywords) == 0 {
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
 
-	response := model.SearchResponse{
-		Total:        total,
-		Results:      filteredForResults, // 使用进一步过滤的结果
-		MergedByType: mergedLinks,
+// searchAliases 并行搜索一组别名，各关键词结果分别排序后交错合并，按标准化链接去重。
+// 整组结果不单独缓存：各关键词的TG和插件缓存按结果是否完整使用不同的有效期，异步插件完成后也只更新这些缓存，
+// 每次由各关键词的缓存重新组合，不会在插件结果补全后仍返回组合时的部分结果
+func (s *SearchService) searchAliases(requestID string, namespace string, keywords []string, channels []string, refresh model.RefreshLevel, sourceType string, plugins []string, concurrency int, ext map[string]interface{}) ([]model.SearchResult, error) {
+	// 并行搜索每个关键词
+	perKeyword := make([][]model.SearchResult, len(keywords))
+	errs := make([]error, len(keywords))
+	var wg sync.WaitGroup
+	for i, kw := range keywords {
+		wg.Add(1)
+		go func(i int, kw string) {
+			defer wg.Done()
+			results, err := s.searchKeyword(requestID, namespace, kw, channels, refresh, sourceType, plugins, concurrency, ext)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			tagResultLanguages(results)
+			tagResultAttributes(results)
+			sortResultsByTimeAndKeywords(results)
+			perKeyword[i] = results
+		}(i, kw)
+	}
+	wg.Wait()
+	
+	// 只有全部关键词都失败时才返回错误
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
 	}
+	if failed == len(keywords) {
+		return nil, errs[0]
+	}
+	
+	return interleaveResults(perKeyword), nil
+}
 
-	// 根据resultType过滤返回结果
-	return filterResponseByType(response, resultType), nil
+// interleaveResults 轮流从各关键词的结果列表中取结果，
+// 一条结果的所有链接都已出现过时视为重复并跳过
+func interleaveResults(lists [][]model.SearchResult) []model.SearchResult {
+	total := 0
+	maxLen := 0
+	for _, list := range lists {
+		total += len(list)
+		if len(list) > maxLen {
+			maxLen = len(list)
+		}
+	}
+	
+	interleaved := make([]model.SearchResult, 0, total)
+	seenLinks := make(map[string]bool, total)
+	seenIDs := make(map[string]bool, total)
+	for i := 0; i < maxLen; i++ {
+		for _, list := range lists {
+			if i >= len(list) {
+				continue
+			}
+			result := list[i]
+			
+			if len(result.Links) == 0 {
+				// 无链接的结果按UniqueID去重
+				if seenIDs[result.UniqueID] {
+					continue
+				}
+				seenIDs[result.UniqueID] = true
+				interleaved = append(interleaved, result)
+				continue
+			}
+			
+			isNew := false
+			for _, link := range result.Links {
+				normalized := normalizeUrl(link.URL)
+				if !seenLinks[normalized] {
+					seenLinks[normalized] = true
+					isNew = true
+				}
+			}
+			if isNew {
+				interleaved = append(interleaved, result)
+			}
+		}
+	}
+	
+	return interleaved
 }
 
 // filterResponseByType 根据结果类型过滤响应
@@ -515,54 +630,78 @@ func filterResponseByType(response model.SearchResponse, resultType string) mode
 	case "merged_by_type":
 		// 只返回MergedByType，Results设为nil，结合omitempty标签，JSON序列化时会忽略此字段
 		return model.SearchResponse{
-			Total:        response.Total,
-			MergedByType: response.MergedByType,
-			Results:      nil,
+			Total:         response.Total,
+			MergedByType:  response.MergedByType,
+			Results:       nil,
+			Truncated:     response.DroppedByType != nil,
+			DroppedByType: response.DroppedByType,
+			Category:      response.Category,
+			Maintenance:   response.Maintenance,
+			Notice:        response.Notice,
+			Timing:        response.Timing,
+		}
+	case "flat":
+		// 只返回扁平链接列表，total为链接数
+		return model.SearchResponse{
+			Total:         len(response.Links),
+			Links:         response.Links,
+			Truncated:     response.DroppedByType != nil,
+			DroppedByType: response.DroppedByType,
+			Category:      response.Category,
+			Maintenance:   response.Maintenance,
+			Notice:        response.Notice,
+			Timing:        response.Timing,
 		}
 	case "all":
 		return response
 	case "results":
 		// 只返回Results
 		return model.SearchResponse{
-			Total:   response.Total,
-			Results: response.Results,
+			Total:          response.Total,
+			Results:        response.Results,
+			Truncated:      response.DroppedResults > 0,
+			DroppedResults: response.DroppedResults,
+			Category:       response.Category,
+			Filtered:       response.Filtered,
+			Maintenance:    response.Maintenance,
+			Notice:         response.Notice,
+			Timing:         response.Timing,
 		}
 	default:
 		// // 默认返回全部
 		// return response
 		return model.SearchResponse{
-			Total:        response.Total,
-			MergedByType: response.MergedByType,
-			Results:      nil,
+			Total:         response.Total,
+			MergedByType:  response.MergedByType,
+			Results:       nil,
+			Truncated:     response.DroppedByType != nil,
+			DroppedByType: response.DroppedByType,
+			Category:      response.Category,
+			Maintenance:   response.Maintenance,
+			Notice:        response.Notice,
+			Timing:        response.Timing,
 		}
 	}
 }
 
 // 根据时间和关键词排序结果
 func sortResultsByTimeAndKeywords(results []model.SearchResult) {
+	sortResultsWithLanguagePreference(results, nil)
+}
+
+// sortResultsWithLanguagePreference 按综合得分排序，preferredLangs中语言的结果额外加分
+func sortResultsWithLanguagePreference(results []model.SearchResult, preferredLangs map[string]bool) {
 	// 1. 计算每个结果的综合得分
 	scores := make([]ResultScore, len(results))
+	profile := GetRankingProfile()
 	
 	for i, result := range results {
-		source := getResultSource(result)
-		
-		scores[i] = ResultScore{
-			Result:       result,
-			TimeScore:    calculateTimeScore(result.Datetime),
-			KeywordScore: getKeywordPriority(result.Title),
-			PluginScore:  getPluginLevelScore(source),
-			TotalScore:   0, // 稍后计算
-		}
-		
-		// 计算综合得分
-		scores[i].TotalScore = scores[i].TimeScore + 
-							  float64(scores[i].KeywordScore) + 
-							  float64(scores[i].PluginScore)
+		scores[i] = scoreResult(profile, result, getResultSource(result), preferredLangs)
 	}
 	
-	// 2. 按综合得分排序
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].TotalScore > scores[j].TotalScore
+	// 2. 按综合得分排序，得分相同时依次按时间、唯一标识排序，保证分页时顺序稳定
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scoreLess(scores[i], scores[j])
 	})
 	
 	// 3. 更新原数组
@@ -571,7 +710,34 @@ func sortResultsByTimeAndKeywords(results []model.SearchResult) {
 	}
 }
 
+// scoreResult 计算结果的综合得分：时间得分 + 优先关键词得分 + 来源插件等级得分 + 偏好语言得分
+func scoreResult(profile *RankingProfile, result model.SearchResult, source string, preferredLangs map[string]bool) ResultScore {
+	score := ResultScore{
+		Result:       result,
+		TimeScore:    profile.TimeScore(result), // 按内容分类的衰减曲线计算
+		KeywordScore: getKeywordPriority(result.Title),
+		PluginScore:  getPluginLevelScore(source),
+	}
+	if preferredLangs[result.Language] {
+		score.LanguageScore = preferredLanguageScore
+	}
+	score.TotalScore = score.TimeScore +
+		float64(score.KeywordScore) +
+		float64(score.PluginScore) +
+		float64(score.LanguageScore)
+	return score
+}
 
+// scoreLess 判断结果a是否应排在结果b之前
+func scoreLess(a, b ResultScore) bool {
+	if a.TotalScore != b.TotalScore {
+		return a.TotalScore > b.TotalScore
+	}
+	if !a.Result.Datetime.Equal(b.Result.Datetime) {
+		return a.Result.Datetime.After(b.Result.Datetime)
+	}
+	return generateResultKey(a.Result) < generateResultKey(b.Result)
+}
 
 
 
@@ -587,44 +753,94 @@ func getKeywordPriority(title string) int {
 	return 0
 }
 
-// 搜索单个频道
+// searchChannel 搜索单个频道，按配置的页数向更早的消息翻页。所有页共享4秒预算：
+// 取得当前页后立即按分页游标开始抓取下一页，与当前页的解析并行；超时或没有更多结果时停止，已取得的结果照常返回
 func (s *SearchService) searchChannel(keyword string, channel string) ([]model.SearchResult, error) {
-	// 构建搜索URL
-	url := util.BuildSearchURL(channel, keyword, "")
-
 	// 使用全局HTTP客户端（已配置代理）
 	client := util.GetHTTPClient()
 
-	// 创建一个带超时的上下文
+	// 创建一个带超时的上下文，所有页共用
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
 
+	// 第一页失败时返回错误
+	body, err := fetchChannelPage(ctx, client, util.BuildSearchURL(channel, keyword, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	depth := config.GetChannelPageDepth(channel)
+	var results []model.SearchResult
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		// 在解析当前页的同时抓取下一页
+		var next chan channelPage
+		if page < depth {
+			if param := util.ExtractNextPageParam(body); param != "" {
+				next = make(chan channelPage, 1)
+				go func(url string) {
+					body, err := fetchChannelPage(ctx, client, url)
+					next <- channelPage{body: body, err: err}
+				}(util.BuildSearchURL(channel, keyword, param))
+			}
+		}
+
+		// 解析响应
+		pageResults, _, err := util.ParseSearchResults(body, channel)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			break
+		}
+		for _, result := range pageResults {
+			if !seen[result.UniqueID] {
+				seen[result.UniqueID] = true
+				results = append(results, result)
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		nextPage := <-next
+		if nextPage.err != nil {
+			// 超时或请求失败时保留已取得的页
+			break
+		}
+		body = nextPage.body
+	}
+
+	return results, nil
+}
+
+// channelPage 抓取到的频道搜索结果页
+type channelPage struct {
+	body string
+	err  error
+}
+
+// fetchChannelPage 抓取频道搜索结果页
+func fetchChannelPage(ctx context.Context, client *http.Client, url string) (string, error) {
 	// 创建请求
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	// 发送请求
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	// 解析响应
-	results, _, err := util.ParseSearchResults(string(body), channel)
-	if err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return string(body), nil
 }
 
 // 用于从消息内容中提取链接-标题对应关系的函数
@@ -646,8 +862,8 @@ func extractLinkTitlePairsWithNewlines(content string) map[string]string {
 	// 按行分割内容
 	lines := strings.Split(content, "\n")
 	
-	// 链接正则表达式
-	linkRegex := regexp.MustCompile(`https?://[^\s"']+`)
+	// 链接正则表达式（预编译）
+	linkRegex := util.GenericLinkPattern
 	
 	// 第一遍扫描：识别标题-链接对
 	var lastTitle string
@@ -752,18 +968,6 @@ func extractLinkTitlePairsWithoutNewlines(content string) map[string]string {
 	// 结果映射：链接URL -> 对应标题
 	linkTitleMap := make(map[string]string)
 	
-	// 使用精确的网盘链接正则表达式集合，避免贪婪匹配
-	linkPatterns := []*regexp.Regexp{
-		util.TianyiPanPattern,  // 天翼云盘
-		util.BaiduPanPattern,   // 百度网盘
-		util.QuarkPanPattern,   // 夸克网盘
-		util.AliyunPanPattern,  // 阿里云盘
-		util.UCPanPattern,      // UC网盘
-		util.Pan123Pattern,     // 123网盘
-		util.Pan115Pattern,     // 115网盘
-		util.XunleiPanPattern,  // 迅雷网盘
-	}
-	
 	// 收集所有链接及其位置
 	type linkInfo struct {
 		url string
@@ -771,25 +975,17 @@ func extractLinkTitlePairsWithoutNewlines(content string) map[string]string {
 	}
 	var allLinks []linkInfo
 	
-	// 使用各个精确正则表达式查找链接
-	for _, pattern := range linkPatterns {
-		matches := pattern.FindAllString(content, -1)
-		for _, match := range matches {
-			pos := strings.Index(content, match)
-			if pos >= 0 {
-				allLinks = append(allLinks, linkInfo{url: match, pos: pos})
-			}
+	// 使用精确的网盘链接正则表达式集合查找链接，避免贪婪匹配
+	for _, pattern := range util.PanLinkPatterns {
+		for _, loc := range pattern.FindAllStringIndex(content, -1) {
+			allLinks = append(allLinks, linkInfo{url: content[loc[0]:loc[1]], pos: loc[0]})
 		}
 	}
 	
 	// 按位置排序
-	for i := 0; i < len(allLinks)-1; i++ {
-		for j := i + 1; j < len(allLinks); j++ {
-			if allLinks[i].pos > allLinks[j].pos {
-				allLinks[i], allLinks[j] = allLinks[j], allLinks[i]
-			}
-		}
-	}
+	sort.SliceStable(allLinks, func(i, j int) bool {
+		return allLinks[i].pos < allLinks[j].pos
+	})
 	
 	// URL标准化和去重
 	uniqueLinks := make(map[string]string) // 标准化URL -> 原始URL
@@ -862,8 +1058,7 @@ func extractTitleBeforeLink(text string) string {
 	}
 	
 	// 尝试匹配常见的标题模式
-	titlePattern := regexp.MustCompile(`([^链地资网\s]+?(?:\([^)]+\))?(?:\s*\d+K)?(?:\s*臻彩)?(?:\s*MAX)?(?:\s*HDR)?(?:\s*更(?:新)?\d+集))$`)
-	matches := titlePattern.FindStringSubmatch(text)
+	matches := util.TrailingTitlePattern.FindStringSubmatch(text)
 	if len(matches) > 1 {
 		return cleanTitle(matches[1])
 	}
@@ -923,8 +1118,7 @@ func cleanTitle(title string) string {
 	title = strings.TrimPrefix(title, "片名:")
 	
 	// 移除表情符号和特殊字符
-	emojiRegex := regexp.MustCompile(`[\p{So}\p{Sk}]`)
-	title = emojiRegex.ReplaceAllString(title, "")
+	title = util.EmojiPattern.ReplaceAllString(title, "")
 	
 	return strings.TrimSpace(title)
 }
@@ -934,75 +1128,103 @@ func isEmpty(line string) bool {
 	return strings.TrimSpace(line) == ""
 }
 
+// buildLinkTitleMap 解析单条结果中链接与标题的对应关系
+func buildLinkTitleMap(result model.SearchResult) map[string]string {
+	// 绑定了专用解析器的频道优先使用专用解析器，解析不到时回退到通用解析
+	if parser := channelParserFor(result.Channel); parser != nil {
+		if linkTitleMap := parser.ParseLinkTitles(result); len(linkTitleMap) > 0 {
+			return linkTitleMap
+		}
+	}
+	
+	// 提取消息中的链接-标题对应关系
+	linkTitleMap := extractLinkTitlePairs(result.Content)
+	
+	// 如果没有从内容中提取到标题，尝试直接从内容中匹配
+	if len(linkTitleMap) == 0 && len(result.Links) > 0 && !strings.Contains(result.Content, "\n") {
+		// 这是没有换行符的情况，尝试直接匹配
+		content := result.Content
+		
+		// 支持多种网盘链接前缀
+		linkPrefixes := []string{"天翼链接：", "百度链接：", "夸克链接：", "阿里链接：", "UC链接：", "115链接：", "迅雷链接：", "123链接：", "链接："}
+		
+		var parts []string
+		
+		// 尝试找到匹配的前缀
+		for _, prefix := range linkPrefixes {
+			if strings.Contains(content, prefix) {
+				parts = strings.Split(content, prefix)
+				break
+			}
+		}
+		
+		// 如果找到了匹配的前缀并且分割成功
+		if len(parts) > 1 && len(result.Links) <= len(parts)-1 {
+			// 第一部分是第一个标题
+			titles := make([]string, 0, len(parts))
+			titles = append(titles, cleanTitle(parts[0]))
+			
+			// 处理每个包含链接的部分，提取标题
+			for i := 1; i < len(parts)-1; i++ {
+				part := parts[i]
+				// 找到链接的结束位置，使用更通用的分隔符
+				linkEnd := -1
+				for j, c := range part {
+					// 扩展分隔符列表，包含更多可能的字符
+					if c == ' ' || c == '窃' || c == '东' || c == '迎' || c == '千' || c == '我' || c == '恋' || c == '将' || c == '野' || 
+					   c == '合' || c == '集' || c == '天' || c == '翼' || c == '网' || c == '盘' || c == '(' || c == '（' {
+						linkEnd = j
+						break
+					}
+				}
+				
+				if linkEnd > 0 {
+					// 提取标题
+					title := cleanTitle(part[linkEnd:])
+					titles = append(titles, title)
+				}
+			}
+			
+			// 将标题与链接关联
+			for i, link := range result.Links {
+				if i < len(titles) {
+					linkTitleMap[link.URL] = titles[i]
+				}
+			}
+		}
+	}
+	
+	return linkTitleMap
+}
+
 // 将搜索结果按网盘类型分组
 func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes []string) model.MergedLinks {
+	return mergeResultsByTypeWithKeywords(results, []string{keyword}, cloudTypes, nil)
+}
+
+// mergeResultsByTypeWithKeywords 将搜索结果按网盘类型分组，链接标题包含任一关键词即保留。
+// highlightTerms不为空时同时标注关键词在链接note中的命中位置
+func mergeResultsByTypeWithKeywords(results []model.SearchResult, keywords []string, cloudTypes []string, highlightTerms []string) model.MergedLinks {
 	// 创建合并结果的映射
 	mergedLinks := make(model.MergedLinks, 12) // 预分配容量，假设有12种不同的网盘类型
 
-	// 用于去重的映射，键为URL
+	// 用于去重的映射，键为URL（磁力链接为info-hash）
 	uniqueLinks := make(map[string]model.MergedLink)
 
 	// 将关键词转为小写，用于不区分大小写的匹配
-	lowerKeyword := strings.ToLower(keyword)
+	lowerKeywords := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		if kw != "" {
+			lowerKeywords = append(lowerKeywords, strings.ToLower(kw))
+		}
+	}
 
+	// 预先（并行、带缓存）提取每条消息中的链接-标题对应关系
+	linkTitleMaps := getLinkTitleMaps(results)
+	
 	// 遍历所有搜索结果
-	for _, result := range results {
-		// 提取消息中的链接-标题对应关系
-		linkTitleMap := extractLinkTitlePairs(result.Content)
-		
-		// 如果没有从内容中提取到标题，尝试直接从内容中匹配
-		if len(linkTitleMap) == 0 && len(result.Links) > 0 && !strings.Contains(result.Content, "\n") {
-			// 这是没有换行符的情况，尝试直接匹配
-			content := result.Content
-			
-			// 支持多种网盘链接前缀
-			linkPrefixes := []string{"天翼链接：", "百度链接：", "夸克链接：", "阿里链接：", "UC链接：", "115链接：", "迅雷链接：", "123链接：", "链接："}
-			
-			var parts []string
-			
-			// 尝试找到匹配的前缀
-			for _, prefix := range linkPrefixes {
-				if strings.Contains(content, prefix) {
-					parts = strings.Split(content, prefix)
-					break
-				}
-			}
-			
-			// 如果找到了匹配的前缀并且分割成功
-			if len(parts) > 1 && len(result.Links) <= len(parts)-1 {
-				// 第一部分是第一个标题
-				titles := make([]string, 0, len(parts))
-				titles = append(titles, cleanTitle(parts[0]))
-				
-				// 处理每个包含链接的部分，提取标题
-				for i := 1; i < len(parts)-1; i++ {
-					part := parts[i]
-					// 找到链接的结束位置，使用更通用的分隔符
-					linkEnd := -1
-					for j, c := range part {
-						// 扩展分隔符列表，包含更多可能的字符
-						if c == ' ' || c == '窃' || c == '东' || c == '迎' || c == '千' || c == '我' || c == '恋' || c == '将' || c == '野' || 
-						   c == '合' || c == '集' || c == '天' || c == '翼' || c == '网' || c == '盘' || c == '(' || c == '（' {
-							linkEnd = j
-							break
-						}
-					}
-					
-					if linkEnd > 0 {
-						// 提取标题
-						title := cleanTitle(part[linkEnd:])
-						titles = append(titles, title)
-					}
-				}
-				
-				// 将标题与链接关联
-				for i, link := range result.Links {
-					if i < len(titles) {
-						linkTitleMap[link.URL] = titles[i]
-					}
-				}
-			}
-		}
+	for resultIndex, result := range results {
+		linkTitleMap := linkTitleMaps[resultIndex]
 		
 		for _, link := range result.Links {
 			// 尝试从映射中获取该链接对应的标题
@@ -1035,9 +1257,20 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 			}
 			
 			// 关键词过滤：现在我们有了准确的链接-标题对应关系，只需检查每个链接的具体标题
-			if !skipKeywordFilter && keyword != "" {
+			var lowerTitle string
+			if (!skipKeywordFilter && len(lowerKeywords) > 0) || len(highlightTerms) > 0 {
+				lowerTitle = strings.ToLower(title)
+			}
+			if !skipKeywordFilter && len(lowerKeywords) > 0 {
 				// 只检查链接的具体标题，无论是TG来源还是插件来源
-				if !strings.Contains(strings.ToLower(title), lowerKeyword) {
+				matched := false
+				for _, lowerKeyword := range lowerKeywords {
+					if strings.Contains(lowerTitle, lowerKeyword) {
+						matched = true
+						break
+					}
+				}
+				if !matched {
 					continue
 				}
 			}
@@ -1066,17 +1299,35 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 				Datetime: result.Datetime,
 				Source:   source, // 添加数据来源字段
 				Images:   result.Images, // 添加TG消息中的图片链接
+				Language: result.Language,
+				Size:       link.Size,
+				FileCount:  link.FileCount,
+				ExpiresAt:  link.ExpiresAt,
+				SourceNote: link.SourceNote,
+			}
+			// 链接有单独标题时按标题推断语言
+			if lang := util.DetectLanguage(title); lang != "" {
+				mergedLink.Language = lang
+			}
+			mergedLink.Attributes = linkAttributes(result, title)
+			if len(highlightTerms) > 0 {
+				mergedLink.Highlights = noteHighlights(lowerTitle, highlightTerms)
 			}
 
 			// 检查是否已存在相同URL的链接
-			if existingLink, exists := uniqueLinks[link.URL]; exists {
-				// 如果已存在，只有当当前链接的时间更新时才替换
+			key := linkDedupeKey(link.URL)
+			if existingLink, exists := uniqueLinks[key]; exists {
+				// 如果已存在，只有当当前链接的时间更新时才替换，缺少的元数据从另一条补充
 				if mergedLink.Datetime.After(existingLink.Datetime) {
-					uniqueLinks[link.URL] = mergedLink
+					fillMissingLinkMeta(&mergedLink, existingLink)
+					uniqueLinks[key] = mergedLink
+				} else {
+					fillMissingLinkMeta(&existingLink, mergedLink)
+					uniqueLinks[key] = existingLink
 				}
 			} else {
 				// 如果不存在，直接添加
-				uniqueLinks[link.URL] = mergedLink
+				uniqueLinks[key] = mergedLink
 			}
 		}
 	}
@@ -1087,21 +1338,15 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 	linkTypeMap := make(map[string]string) // URL -> Type的映射
 	
 	// 按原始results的顺序收集唯一链接
+	addedKeys := make(map[string]bool, len(uniqueLinks))
 	for _, result := range results {
 		for _, link := range result.Links {
-			if mergedLink, exists := uniqueLinks[link.URL]; exists {
-				// 检查是否已经添加过这个链接
-				found := false
-				for _, existing := range orderedLinks {
-					if existing.URL == link.URL {
-						found = true
-						break
-					}
-				}
-				if !found {
-					orderedLinks = append(orderedLinks, mergedLink)
-					linkTypeMap[link.URL] = link.Type
-				}
+			key := linkDedupeKey(link.URL)
+			// 检查是否已经添加过这个链接
+			if mergedLink, exists := uniqueLinks[key]; exists && !addedKeys[key] {
+				addedKeys[key] = true
+				orderedLinks = append(orderedLinks, mergedLink)
+				linkTypeMap[mergedLink.URL] = link.Type
 			}
 		}
 	}
@@ -1143,59 +1388,111 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 	return mergedLinks
 }
 
+// fillMissingLinkMeta 用src中的链接元数据补充dst中未知的部分
+func fillMissingLinkMeta(dst *model.MergedLink, src model.MergedLink) {
+	if dst.Size == 0 {
+		dst.Size = src.Size
+	}
+	if dst.FileCount == 0 {
+		dst.FileCount = src.FileCount
+	}
+	if dst.ExpiresAt == nil {
+		dst.ExpiresAt = src.ExpiresAt
+	}
+	if dst.SourceNote == "" {
+		dst.SourceNote = src.SourceNote
+	}
+}
+
+// getCachedData 按刷新级别读取搜索缓存：memory级别跳过内存缓存从磁盘重新读取，其余级别正常读取。
+// 命中时同时返回命中的缓存层级
+func getCachedData(cacheKey string, refresh model.RefreshLevel) ([]byte, string, bool, error) {
+	if refresh.SkipsMemory() {
+		data, hit, err := enhancedTwoLevelCache.GetFromDisk(cacheKey)
+		return data, cache.CacheLevelDisk, hit, err
+	}
+	return enhancedTwoLevelCache.GetWithLevel(cacheKey)
+}
+
 // searchTG 搜索TG频道
-func (s *SearchService) searchTG(keyword string, channels []string, forceRefresh bool) ([]model.SearchResult, error) {
+func (s *SearchService) searchTG(requestID string, namespace string, keyword string, channels []string, refresh model.RefreshLevel) ([]model.SearchResult, error) {
 	// 生成缓存键
-	cacheKey := cache.GenerateTGCacheKey(keyword, channels)
+	cacheKey := cache.NamespaceCacheKey(namespace, cache.GenerateTGCacheKey(keyword, channels))
 	
-	// 如果未启用强制刷新，尝试从缓存获取结果
-	if !forceRefresh && cacheInitialized && config.AppConfig.CacheEnabled {
+	// 如果刷新级别不要求重新搜索TG，尝试从缓存获取结果（维护模式下忽略刷新级别，只读取缓存）
+	maintenance := IsMaintenanceMode()
+	if (!refresh.RefreshesTG() || maintenance) && cacheInitialized && config.AppConfig.CacheEnabled {
 		var data []byte
 		var hit bool
 		var err error
 		
 		// 使用增强版缓存
 		if enhancedTwoLevelCache != nil {
-			data, hit, err = enhancedTwoLevelCache.Get(cacheKey)
+			var level string
+			lookupStart := time.Now()
+			data, level, hit, err = getCachedData(cacheKey, refresh)
 			
 			if err == nil && hit {
 				var results []model.SearchResult
 				if err := enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results); err == nil {
-					// 直接返回缓存数据，不检查新鲜度
+					// 直接返回缓存数据，启用滑动过期时延长有效期并在数据较旧时后台刷新
+					recordSearchCacheLookup(&tgCacheLookups, &tgCacheHits, true)
+					searchTimingFor(requestID).recordCache("tg", keyword, level, time.Since(lookupStart))
+					searchTimingFor(requestID).recordResultProvenance(results, mainCacheProvenance(level))
+					slideCacheEntry(requestID, cacheKey, time.Duration(config.AppConfig.CacheTTLMinutes)*time.Minute, func() {
+						s.searchTG(requestID, namespace, keyword, channels, model.RefreshTG)
+					})
 					return results, nil
 				}
 			}
+			recordSearchCacheLookup(&tgCacheLookups, &tgCacheHits, false)
+			searchTimingFor(requestID).recordCache("tg", keyword, "", time.Since(lookupStart))
 		}
 	}
 	
+	// 维护模式下不请求TG频道
+	if maintenance {
+		return []model.SearchResult{}, nil
+	}
+	
 	// 缓存未命中或强制刷新，执行实际搜索
 	var results []model.SearchResult
 	
-	// 使用工作池并行搜索多个频道
-	tasks := make([]pool.Task, 0, len(channels))
-	
-	for _, channel := range channels {
-		ch := channel // 创建副本，避免闭包问题
-		tasks = append(tasks, func() interface{} {
-			results, err := s.searchChannel(keyword, ch)
-			if err != nil {
-				return nil
-			}
-			return results
+	// 并行搜索多个频道，debug=true时记录每个频道的耗时
+	timing := searchTimingFor(requestID)
+	durations := newTaskDurations(timing, len(channels))
+	tasks := make([]func(context.Context) ([]model.SearchResult, error), 0, len(channels))
+	for i, channel := range channels {
+		i, ch := i, channel // 创建副本，避免闭包问题
+		tasks = append(tasks, func(context.Context) ([]model.SearchResult, error) {
+			defer durations.track(i)()
+			return s.searchChannel(keyword, ch)
 		})
 	}
 	
-	// 执行搜索任务并获取结果
-	taskResults := pool.ExecuteBatchWithTimeout(tasks, len(channels), config.AppConfig.PluginTimeout)
-	
-	// 合并所有频道的结果
-	for _, result := range taskResults {
-		if result != nil {
-			channelResults := result.([]model.SearchResult)
-			results = append(results, channelResults...)
+	// 合并所有频道的结果，出错或超时的频道没有结果
+	batchStart := time.Now()
+	channelResults := pool.RunWithTimeout(config.AppConfig.PluginTimeout, len(channels), tasks)
+	timing.recordTasks("tg", keyword, channels, channelResults, durations, nil, time.Since(batchStart))
+	for _, result := range channelResults {
+		recordConcurrencySample(result.Err)
+		if result.Err == nil {
+			results = append(results, result.Value...)
 		}
 	}
 	
+	// 规范化网盘分享链接（统一主机名、去除跟踪参数、提取码移入password），再规范化磁力链接，丢弃无效链接和重复的磁力资源
+	results = canonicalizeShareLinks(results)
+	results = normalizeMagnetResults(results)
+	
+	// 写入缓存前丢弃标题命中屏蔽规则或链接指向屏蔽域名的结果
+	results = GetContentSafetyFilter().Filter(results)
+	
+	// 频道发现：收集结果中转发和引用的其他频道
+	if config.AppConfig.ChannelDiscoveryEnabled {
+		GetChannelDiscovery().Observe(keyword, results)
+	}
+	
 	// 异步缓存结果
 	if cacheInitialized && config.AppConfig.CacheEnabled {
 		go func(res []model.SearchResult) {
@@ -1216,18 +1513,20 @@ func (s *SearchService) searchTG(keyword string, channels []string, forceRefresh
 }
 
 // searchPlugins 搜索插件
-func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRefresh bool, concurrency int, ext map[string]interface{}) ([]model.SearchResult, error) {
+func (s *SearchService) searchPlugins(requestID string, namespace string, keyword string, plugins []string, refresh model.RefreshLevel, concurrency int, ext map[string]interface{}) ([]model.SearchResult, error) {
 	// 确保ext不为nil
 	if ext == nil {
 		ext = make(map[string]interface{})
 	}
 	
 	// 生成缓存键
-	cacheKey := cache.GeneratePluginCacheKey(keyword, plugins)
+	cacheKey := cache.NamespaceCacheKey(namespace, cache.GeneratePluginCacheKey(keyword, plugins, ext))
 	
 	
-	// 如果未启用强制刷新，尝试从缓存获取结果
-	if !forceRefresh && cacheInitialized && config.AppConfig.CacheEnabled {
+	// 如果刷新级别不要求重新搜索插件，尝试从缓存获取结果（缓存中有不完整的插件结果时重新搜索）。
+	// 维护模式下忽略刷新级别，不完整的缓存也直接返回
+	maintenance := IsMaintenanceMode()
+	if (maintenance || (!refresh.RefreshesPlugins() && !isPartialCacheKey(cacheKey))) && cacheInitialized && config.AppConfig.CacheEnabled {
 		var data []byte
 		var hit bool
 		var err error
@@ -1237,22 +1536,37 @@ func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRef
 			
 			// 使用Get方法，它会检查磁盘缓存是否有更新
 			// 如果磁盘缓存比内存缓存更新，会自动更新内存缓存并返回最新数据
-			data, hit, err = enhancedTwoLevelCache.Get(cacheKey)
+			var level string
+			lookupStart := time.Now()
+			data, level, hit, err = getCachedData(cacheKey, refresh)
 			
 			if err == nil && hit {
 				var results []model.SearchResult
 				if err := enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results); err == nil {
 					// 返回缓存数据
-					fmt.Printf("✅ [%s] 命中缓存 结果数: %d\n", keyword,  len(results))
+					fmt.Printf("%s✅ [%s] 命中缓存 结果数: %d\n", util.RequestLogTag(requestID), keyword,  len(results))
+					recordSearchCacheLookup(&pluginCacheLookups, &pluginCacheHits, true)
+					searchTimingFor(requestID).recordCache("plugin", keyword, level, time.Since(lookupStart))
+					searchTimingFor(requestID).recordResultProvenance(results, mainCacheProvenance(level))
+					slideCacheEntry(requestID, cacheKey, cacheTTLForKey(cacheKey, CacheTierComplete), func() {
+						s.searchPlugins(requestID, namespace, keyword, plugins, model.RefreshPlugins, concurrency, ext)
+					})
 					return results, nil
 				} else {
 					displayKey := cacheKey[:8] + "..."
-					fmt.Printf("[主服务] 缓存反序列化失败: %s(关键词:%s) | 错误: %v\n", displayKey, keyword, err)
+					fmt.Printf("%s[主服务] 缓存反序列化失败: %s(关键词:%s) | 错误: %v\n", util.RequestLogTag(requestID), displayKey, keyword, err)
 				}
 			}
+			recordSearchCacheLookup(&pluginCacheLookups, &pluginCacheHits, false)
+			searchTimingFor(requestID).recordCache("plugin", keyword, "", time.Since(lookupStart))
 		}
 	}
 	
+	// 维护模式下不请求插件
+	if maintenance {
+		return []model.SearchResult{}, nil
+	}
+	
 	// 缓存未命中或强制刷新，执行实际搜索
 	
 	// 获取所有可用插件
@@ -1296,76 +1610,108 @@ func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRef
 	// 控制并发数
 	if concurrency <= 0 {
 		// 使用配置中的默认值
-		concurrency = config.AppConfig.DefaultConcurrency
+		concurrency = defaultSearchConcurrency()
 	}
 	
-	// 使用工作池执行并行搜索
-	tasks := make([]pool.Task, 0, len(availablePlugins))
+	// 不在启用时段、隔离期内和依赖的站点均已失效的插件不参与搜索
+	availablePlugins = s.pluginManager.ScheduledPlugins(availablePlugins, time.Now())
+	activePlugins := make([]plugin.AsyncSearchPlugin, 0, len(availablePlugins))
 	for _, p := range availablePlugins {
+		if !plugin.IsPluginQuarantined(p.Name()) && !util.IsPluginDegraded(p.Name()) {
+			activePlugins = append(activePlugins, p)
+		}
+	}
+	
+	// 分批搜索时第一批插件的结果立即返回，其余插件在后台执行，完成后与第一批的结果一起写入缓存
+	firstWave, secondWave := splitPluginWaves(activePlugins)
+	firstResults := s.executePlugins(requestID, keyword, firstWave, refresh, concurrency, cacheKey, ext)
+	allResults := finalizePluginResults(firstResults)
+	
+	if len(secondWave) > 0 {
+		s.runSecondWave(requestID, keyword, secondWave, refresh, concurrency, cacheKey, ext, firstResults)
+	} else {
+		// 恢复主程序缓存更新：确保最终合并结果被正确缓存
+		go storePluginResults(requestID, cacheKey, allResults)
+	}
+	
+	return allResults, nil
+}
+
+
+
+// runPluginTasks 在本节点并行执行插件搜索，返回有链接的结果
+func (s *SearchService) runPluginTasks(keyword string, plugins []plugin.AsyncSearchPlugin, concurrency int, cacheKey string, ext map[string]interface{}) []model.SearchResult {
+	// 并行执行插件搜索，每个插件按等级或单独配置的延迟预算超时，debug=true时记录每个插件的耗时
+	requestID := plugin.RequestIDFromExt(ext)
+	timing := searchTimingFor(requestID)
+	durations := newTaskDurations(timing, len(plugins))
+	names := make([]string, 0, len(plugins))
+	tasks := make([]func(context.Context) ([]model.SearchResult, error), 0, len(plugins))
+	budgets := make([]time.Duration, 0, len(plugins))
+	for i, p := range plugins {
+		budgets = append(budgets, pluginLatencyBudget(p))
+		names = append(names, p.Name())
+		pluginName := p.Name()
 		plugin := p // 创建副本，避免闭包问题
-		tasks = append(tasks, func() interface{} {
+		i := i
+		tasks = append(tasks, func(context.Context) (results []model.SearchResult, err error) {
+			defer durations.track(i)()
+			// 记录插件的搜索次数、错误和耗时（超出预算的插件在完成时同样记录）
+			start := time.Now()
+			defer func() {
+				GetPluginStats().RecordSearch(pluginName, time.Since(start), len(results), err)
+				// 配置了候选插件时按比例对比候选插件的结果
+				shadowPluginSearch(requestID, plugin, keyword, ext, results, err, time.Since(start))
+			}()
+			// 插件panic时计入插件统计，该插件无结果
+			defer recoverPluginTask(pluginName, &err)
+			
 			// 设置主缓存键和当前关键词
 			plugin.SetMainCacheKey(cacheKey)
 			plugin.SetCurrentKeyword(keyword)
 			
 			// 调用异步插件的AsyncSearch方法
-			results, err := plugin.AsyncSearch(keyword, func(client *http.Client, kw string, extParams map[string]interface{}) ([]model.SearchResult, error) {
+			return plugin.AsyncSearch(keyword, func(client *http.Client, kw string, extParams map[string]interface{}) ([]model.SearchResult, error) {
 				// 使用插件的Search方法作为搜索函数
 				return plugin.Search(kw, extParams)
 			}, cacheKey, ext)
-			
-			if err != nil {
-				return nil
-			}
-			return results
 		})
 	}
 	
-	// 执行搜索任务并获取结果
-	results := pool.ExecuteBatchWithTimeout(tasks, concurrency, config.AppConfig.PluginTimeout)
-	
-	// 合并所有插件的结果，过滤掉无链接的结果
+	// 合并所有插件的结果，过滤掉无链接的结果；出错或超出预算的插件没有结果
 	var allResults []model.SearchResult
-	for _, result := range results {
-		if result != nil {
-			pluginResults := result.([]model.SearchResult)
-			// 只添加有链接的结果到最终结果中
-			for _, pluginResult := range pluginResults {
-				if len(pluginResult.Links) > 0 {
-					allResults = append(allResults, pluginResult)
-				}
+	batchStart := time.Now()
+	pluginResults := pool.RunWithTaskTimeouts(config.AppConfig.PluginTimeout, concurrency, tasks, budgets)
+	timing.recordTasks("plugin", keyword, names, pluginResults, durations, budgets, time.Since(batchStart))
+	// 按关键词分类统计插件是否有结果，用于跳过长期没有结果的插件
+	category := ClassifyQuery(keyword)
+	for i, result := range pluginResults {
+		recordConcurrencySample(result.Err)
+		if result.Err != nil {
+			if errors.Is(result.Err, context.DeadlineExceeded) {
+				GetPluginStats().RecordTimeout(plugins[i].Name())
 			}
+			continue
 		}
-	}
-	
-	// 恢复主程序缓存更新：确保最终合并结果被正确缓存
-	if cacheInitialized && config.AppConfig.CacheEnabled {
-		go func(res []model.SearchResult, kw string, key string) {
-			ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
-			
-			// 使用增强版缓存，确保与异步插件使用相同的序列化器
-			if enhancedTwoLevelCache != nil {
-				data, err := enhancedTwoLevelCache.GetSerializer().Serialize(res)
-				if err != nil {
-					fmt.Printf("[主程序] 缓存序列化失败: %s | 错误: %v\n", key, err)
-					return
-				}
-				
-			// 主程序最后更新，覆盖可能有问题的异步插件缓存
-			// 使用同步方式确保数据写入磁盘
-			enhancedTwoLevelCache.SetBothLevels(key, data, ttl)
-				if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
-					fmt.Printf("[主程序] 缓存更新完成: %s | 结果数: %d", 
-						key, len(res))
-				}
+		hit := false
+		for _, pluginResult := range result.Value {
+			if len(pluginResult.Links) > 0 {
+				allResults = append(allResults, pluginResult)
+				hit = true
 			}
-		}(allResults, keyword, cacheKey)
+		}
+		GetPluginZeroHits().Record(requestID, plugins[i].Name(), category, hit)
 	}
-	
-	return allResults, nil
+	return allResults
 }
 
-
+// recoverPluginTask 在插件搜索任务中defer调用，捕获panic并计入插件统计，panic转为任务错误
+func recoverPluginTask(name string, err *error) {
+	if r := recover(); r != nil {
+		plugin.RecordPluginPanic(name, r)
+		*err = fmt.Errorf("插件 %s 搜索发生panic: %v", name, r)
+	}
+}
 
 // GetPluginManager 获取插件管理器
 func (s *SearchService) GetPluginManager() *plugin.PluginManager {
@@ -1382,9 +1728,60 @@ type ResultScore struct {
 	TimeScore    float64  // 时间得分
 	KeywordScore int      // 关键词得分  
 	PluginScore  int      // 插件等级得分
+	LanguageScore int     // 偏好语言得分
 	TotalScore   float64  // 综合得分
 }
 
+// 偏好语言结果的加分，与优先关键词同一量级
+const preferredLanguageScore = 300
+
+// tagResultLanguages 为未标注语言的结果推断语言
+func tagResultLanguages(results []model.SearchResult) {
+	for i := range results {
+		if results[i].Language == "" {
+			results[i].Language = util.DetectResultLanguage(results[i].Title, getResultSource(results[i]))
+		}
+	}
+}
+
+// filterResultsForResultsView 筛选Results视图中的结果：
+// 有时间的结果、包含优先关键词的结果或高等级插件(1-2级)结果。
+// withDropped为true时同时返回未进入Results的结果及原因；highlightTerms不为空时在筛选的同时标注关键词命中位置
+func filterResultsForResultsView(results []model.SearchResult, withDropped bool, highlightTerms []string) ([]model.SearchResult, []model.FilteredResult) {
+	filtered := make([]model.SearchResult, 0, len(results))
+	var dropped []model.FilteredResult
+	for _, result := range results {
+		source := getResultSource(result)
+		pluginLevel := getPluginLevelBySource(source)
+		
+		// result是副本，标注不会影响缓存中的结果
+		if len(highlightTerms) > 0 {
+			result.Highlights = resultHighlights(result, strings.ToLower(result.Title), highlightTerms)
+		}
+		
+		if !result.Datetime.IsZero() || getKeywordPriority(result.Title) > 0 || pluginLevel <= 2 {
+			filtered = append(filtered, result)
+		} else if withDropped {
+			dropped = append(dropped, model.FilteredResult{
+				SearchResult:  result,
+				FilterReasons: []string{model.FilterReasonNoTime, model.FilterReasonLowLevelPlugin},
+			})
+		}
+	}
+	return filtered, dropped
+}
+
+// filterResultsByLanguage 只保留语言在langSet中的结果
+func filterResultsByLanguage(results []model.SearchResult, langSet map[string]bool) []model.SearchResult {
+	filtered := make([]model.SearchResult, 0, len(results))
+	for _, result := range results {
+		if langSet[result.Language] {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
 // 插件等级缓存
 var (
 	pluginLevelCache = sync.Map{} // 插件等级缓存
@@ -1459,33 +1856,3 @@ func getPluginLevelScore(source string) int {
 		return 0     // 默认使用等级3得分
 	}
 }
-
-// calculateTimeScore 计算时间得分
-func calculateTimeScore(datetime time.Time) float64 {
-	if datetime.IsZero() {
-		return 0 // 无时间信息得0分
-	}
-	
-	now := time.Now()
-	daysDiff := now.Sub(datetime).Hours() / 24
-	
-	// 时间得分：越新得分越高，最大500分（增加时间权重）
-	switch {
-	case daysDiff <= 1:
-		return 500  // 1天内
-	case daysDiff <= 3:
-		return 400  // 3天内
-	case daysDiff <= 7:
-		return 300  // 1周内
-	case daysDiff <= 30:
-		return 200  // 1月内
-	case daysDiff <= 90:
-		return 100  // 3月内
-	case daysDiff <= 365:
-		return 50   // 1年内
-	default:
-		return 20   // 1年以上
-	}
-}
-
-
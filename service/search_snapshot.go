@@ -0,0 +1,270 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/model"
+)
+
+// 分页快照相关常量
+const (
+	// 快照保留时间，过期后分页令牌失效
+	searchSnapshotTTL = 10 * time.Minute
+	// 最多保留的快照数量，超过后淘汰最早的快照
+	maxSearchSnapshots = 1000
+	// 所有快照估算占用内存的上限，超过后淘汰最早的快照
+	maxSearchSnapshotBytes = 64 * 1024 * 1024
+	// 估算快照大小时每条结果或链接除字符串外的固定开销
+	snapshotItemOverhead = 256
+	// 单页最大条数
+	MaxPageSize = 200
+	// 分页令牌版本前缀
+	pageTokenVersion = "v1"
+)
+
+var (
+	// ErrInvalidPageToken 分页令牌格式错误
+	ErrInvalidPageToken = errors.New("无效的分页令牌")
+	// ErrPageTokenExpired 分页令牌对应的快照已过期
+	ErrPageTokenExpired = errors.New("分页令牌已过期，请重新搜索")
+)
+
+// searchSnapshot 一次搜索的完整结果快照
+type searchSnapshot struct {
+	response  model.SearchResponse
+	tenantID  string // 创建快照的租户，其他租户不能使用该快照的分页令牌
+	size      int    // 估算占用的内存
+	createdAt time.Time
+}
+
+// SearchSnapshotStore 搜索结果快照存储。
+// 首次分页请求时保存完整的有序结果，后续页从同一快照切片，
+// 避免缓存刷新或得分随时间变化导致翻页时结果错位。快照数量和估算占用的内存都有上限，超过时淘汰最早的快照
+type SearchSnapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*searchSnapshot
+	order     []string // 按创建顺序记录快照ID，用于淘汰
+	ttl       time.Duration
+	maxItems  int
+	maxBytes  int
+	bytes     int // 当前快照估算占用的内存
+}
+
+var (
+	globalSnapshotStore *SearchSnapshotStore
+	snapshotStoreOnce   sync.Once
+)
+
+// GetSearchSnapshotStore 获取全局快照存储
+func GetSearchSnapshotStore() *SearchSnapshotStore {
+	snapshotStoreOnce.Do(func() {
+		globalSnapshotStore = NewSearchSnapshotStore(searchSnapshotTTL, maxSearchSnapshots, maxSearchSnapshotBytes)
+	})
+	return globalSnapshotStore
+}
+
+// NewSearchSnapshotStore 创建快照存储，maxBytes为所有快照估算占用内存的上限
+func NewSearchSnapshotStore(ttl time.Duration, maxItems int, maxBytes int) *SearchSnapshotStore {
+	return &SearchSnapshotStore{
+		snapshots: make(map[string]*searchSnapshot),
+		ttl:       ttl,
+		maxItems:  maxItems,
+		maxBytes:  maxBytes,
+	}
+}
+
+// Save 保存租户的快照并返回快照ID，tenantID为空表示未识别租户的请求。
+// 单个快照超过内存上限时淘汰其他所有快照后保存
+func (s *SearchSnapshotStore) Save(response model.SearchResponse, tenantID string) string {
+	id := newSnapshotID()
+	now := time.Now()
+	size := estimateSnapshotSize(response)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked(now, size)
+	s.snapshots[id] = &searchSnapshot{response: response, tenantID: tenantID, size: size, createdAt: now}
+	s.order = append(s.order, id)
+	s.bytes += size
+	return id
+}
+
+// Get 获取租户的快照，不存在、已过期或由其他租户创建时返回false
+func (s *SearchSnapshotStore) Get(id string, tenantID string) (model.SearchResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, ok := s.snapshots[id]
+	if !ok || time.Since(snap.createdAt) > s.ttl || snap.tenantID != tenantID {
+		return model.SearchResponse{}, false
+	}
+	return snap.response, true
+}
+
+// evictLocked 清理过期的快照，并淘汰最早的快照直到能再放入一个大小为incoming的快照（调用方需持有锁）
+func (s *SearchSnapshotStore) evictLocked(now time.Time, incoming int) {
+	i := 0
+	for ; i < len(s.order); i++ {
+		id := s.order[i]
+		snap, ok := s.snapshots[id]
+		if ok && now.Sub(snap.createdAt) <= s.ttl && len(s.order)-i < s.maxItems && s.bytes+incoming <= s.maxBytes {
+			break
+		}
+		if ok {
+			s.bytes -= snap.size
+		}
+		delete(s.snapshots, id)
+	}
+	s.order = s.order[i:]
+}
+
+// estimateSnapshotSize 估算快照占用的内存：各结果和链接中字符串的长度加上固定开销
+func estimateSnapshotSize(response model.SearchResponse) int {
+	size := 0
+	for _, result := range response.Results {
+		size += snapshotItemOverhead + len(result.UniqueID) + len(result.MessageID) + len(result.Channel) + len(result.Title) + len(result.Content)
+		for _, link := range result.Links {
+			size += snapshotItemOverhead + len(link.URL) + len(link.Password) + len(link.SourceNote)
+		}
+		for _, tag := range result.Tags {
+			size += len(tag)
+		}
+		for _, image := range result.Images {
+			size += len(image)
+		}
+	}
+	for _, links := range response.MergedByType {
+		for _, link := range links {
+			size += snapshotItemOverhead + len(link.URL) + len(link.Password) + len(link.Note) + len(link.Source) + len(link.LinkID)
+			for _, image := range link.Images {
+				size += len(image)
+			}
+		}
+	}
+	for _, link := range response.Links {
+		size += snapshotItemOverhead + len(link.URL) + len(link.Password) + len(link.Title) + len(link.Source) + len(link.LinkID)
+		for _, image := range link.Images {
+			size += len(image)
+		}
+	}
+	return size
+}
+
+// newSnapshotID 生成随机快照ID
+func newSnapshotID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// EncodePageToken 生成分页令牌（对客户端不透明）
+func EncodePageToken(snapshotID string, offset, pageSize int) string {
+	raw := fmt.Sprintf("%s:%s:%d:%d", pageTokenVersion, snapshotID, offset, pageSize)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodePageToken 解析分页令牌
+func DecodePageToken(token string) (snapshotID string, offset, pageSize int, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", 0, 0, ErrInvalidPageToken
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 || parts[0] != pageTokenVersion || parts[1] == "" {
+		return "", 0, 0, ErrInvalidPageToken
+	}
+	offset, err1 := strconv.Atoi(parts[2])
+	pageSize, err2 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil || offset < 0 || pageSize <= 0 || pageSize > MaxPageSize {
+		return "", 0, 0, ErrInvalidPageToken
+	}
+	return parts[1], offset, pageSize, nil
+}
+
+// PaginateSearchResponse 从结果快照中截取一页。
+// results按顺序分页；merged_by_type的每种网盘类型各自按相同偏移分页。
+// 还有更多结果时返回下一页令牌
+func PaginateSearchResponse(response model.SearchResponse, snapshotID string, offset, pageSize int) model.SearchResponse {
+	page := model.SearchResponse{
+		Total:          response.Total,
+		Truncated:      response.Truncated,
+		DroppedByType:  response.DroppedByType,
+		DroppedResults: response.DroppedResults,
+		Category:       response.Category,
+		Maintenance:    response.Maintenance,
+		Notice:         response.Notice,
+	}
+	// 未进入results的结果和耗时明细只随第一页返回
+	if offset == 0 {
+		page.Filtered = response.Filtered
+		page.Timing = response.Timing
+	}
+	hasMore := false
+
+	if response.Results != nil {
+		page.Results = sliceResults(response.Results, offset, pageSize)
+		if offset+pageSize < len(response.Results) {
+			hasMore = true
+		}
+	}
+
+	if response.MergedByType != nil {
+		page.MergedByType = make(model.MergedLinks, len(response.MergedByType))
+		for linkType, links := range response.MergedByType {
+			if offset >= len(links) {
+				continue
+			}
+			end := offset + pageSize
+			if end > len(links) {
+				end = len(links)
+			}
+			page.MergedByType[linkType] = links[offset:end]
+			if end < len(links) {
+				hasMore = true
+			}
+		}
+	}
+
+	if response.Links != nil {
+		end := offset + pageSize
+		if end > len(response.Links) {
+			end = len(response.Links)
+		}
+		if offset < end {
+			page.Links = response.Links[offset:end]
+		} else {
+			page.Links = []model.FlatLink{}
+		}
+		if end < len(response.Links) {
+			hasMore = true
+		}
+	}
+
+	if hasMore {
+		page.NextPageToken = EncodePageToken(snapshotID, offset+pageSize, pageSize)
+	}
+	return page
+}
+
+// sliceResults 截取结果区间，越界时返回空切片
+func sliceResults(results []model.SearchResult, offset, pageSize int) []model.SearchResult {
+	if offset >= len(results) {
+		return []model.SearchResult{}
+	}
+	end := offset + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[offset:end]
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pansou/model"
+)
+
+// snapshotResponse 生成包含n条结果、每条内容长度为contentSize的响应
+func snapshotResponse(n, contentSize int) model.SearchResponse {
+	results := make([]model.SearchResult, n)
+	for i := range results {
+		results[i] = model.SearchResult{UniqueID: "r", Title: "流浪地球", Content: strings.Repeat("x", contentSize)}
+	}
+	return model.SearchResponse{Total: n, Results: results}
+}
+
+// 快照估算占用的内存超过上限时淘汰最早的快照
+func TestSearchSnapshotStoreByteBudget(t *testing.T) {
+	response := snapshotResponse(10, 1000)
+	size := estimateSnapshotSize(response)
+	store := NewSearchSnapshotStore(time.Minute, 100, size*3)
+
+	ids := make([]string, 5)
+	for i := range ids {
+		ids[i] = store.Save(response, "")
+	}
+	if store.bytes > size*3 {
+		t.Fatalf("快照占用 %d 超过上限 %d", store.bytes, size*3)
+	}
+	for i, id := range ids {
+		_, ok := store.Get(id, "")
+		if want := i >= 2; ok != want {
+			t.Fatalf("第%d个快照存在: %v，期望 %v", i+1, ok, want)
+		}
+	}
+}
+
+// 分页令牌只能由创建快照的租户使用
+func TestSearchSnapshotStoreTenant(t *testing.T) {
+	store := NewSearchSnapshotStore(time.Minute, 100, maxSearchSnapshotBytes)
+	id := store.Save(snapshotResponse(1, 10), "tenant-a")
+
+	if _, ok := store.Get(id, "tenant-a"); !ok {
+		t.Fatalf("创建快照的租户应能读取快照")
+	}
+	for _, tenant := range []string{"tenant-b", ""} {
+		if _, ok := store.Get(id, tenant); ok {
+			t.Fatalf("租户 %q 不应能读取 tenant-a 的快照", tenant)
+		}
+	}
+}
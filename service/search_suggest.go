@@ -0,0 +1,333 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"pansou/config"
+	"pansou/model"
+	jsonutil "pansou/util/json"
+)
+
+// 搜索建议数据落盘间隔
+const suggestSaveInterval = 5 * time.Minute
+
+// 每次搜索最多从结果中收集的标题数
+const suggestTitlesPerSearch = 50
+
+// 标题片段的长度范围（字符数），过短或过长的片段不作为建议
+const (
+	minSuggestTitleLen = 2
+	maxSuggestTitleLen = 40
+)
+
+// 标题分段使用的分隔符
+const suggestTitleSeparators = " \t|/\\【】[]()（）《》<>「」『』,，、:：;；!！?？·-_+"
+
+// 建议来源
+const (
+	SuggestSourceHistory = "history" // 搜索过的关键词
+	SuggestSourceTitle   = "title"   // 搜索结果标题中的片段
+)
+
+// Suggestion 搜索建议
+type Suggestion struct {
+	Keyword string `json:"keyword"`
+	Count   int64  `json:"count"`  // 关键词被搜索的次数，或标题片段在结果中出现的次数
+	Source  string `json:"source"` // history、title
+}
+
+// suggestEntry 建议候选词
+type suggestEntry struct {
+	Keyword  string    `json:"keyword"`
+	Count    int64     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// suggestFile 搜索建议持久化格式
+type suggestFile struct {
+	Keywords map[string]*suggestEntry `json:"keywords"`
+	Titles   map[string]*suggestEntry `json:"titles"`
+}
+
+// SuggestService 搜索建议服务：记录搜索过的关键词和结果标题片段，按前缀和模糊匹配返回补全的关键词
+type SuggestService struct {
+	mu         sync.RWMutex
+	keywords   map[string]*suggestEntry // 归一化关键词 -> 候选词
+	titles     map[string]*suggestEntry // 归一化标题片段 -> 候选词
+	maxEntries int
+
+	dataFile string
+	dirty    bool
+}
+
+var (
+	globalSuggestService *SuggestService
+	suggestServiceOnce   sync.Once
+)
+
+// GetSuggestService 获取全局搜索建议服务
+func GetSuggestService() *SuggestService {
+	suggestServiceOnce.Do(func() {
+		globalSuggestService = NewSuggestService("data/search_suggest.json", config.AppConfig.SuggestMaxEntries)
+	})
+	return globalSuggestService
+}
+
+// NewSuggestService 创建搜索建议服务，关键词和标题片段各最多保留maxEntries个，dataFile为空时不持久化
+func NewSuggestService(dataFile string, maxEntries int) *SuggestService {
+	if maxEntries <= 0 {
+		maxEntries = 5000
+	}
+	s := &SuggestService{
+		keywords:   make(map[string]*suggestEntry),
+		titles:     make(map[string]*suggestEntry),
+		maxEntries: maxEntries,
+		dataFile:   dataFile,
+	}
+
+	if dataFile != "" {
+		s.load()
+		go s.saveLoop()
+	}
+
+	return s
+}
+
+// Record 记录一次搜索：关键词计数加一，并收集排在前面的结果标题片段
+func (s *SuggestService) Record(keyword string, results []model.SearchResult) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addSuggestEntry(s.keywords, keyword, now)
+	for i, result := range results {
+		if i >= suggestTitlesPerSearch {
+			break
+		}
+		for _, segment := range titleSegments(result.Title) {
+			addSuggestEntry(s.titles, segment, now)
+		}
+	}
+	pruneSuggestEntries(s.keywords, s.maxEntries)
+	pruneSuggestEntries(s.titles, s.maxEntries)
+	s.dirty = true
+}
+
+// Suggest 返回与输入匹配的建议：前缀匹配优先，其次是包含匹配，最后是按顺序包含输入所有字符的模糊匹配；
+// 同一级别内按次数倒序，搜索过的关键词排在同名标题片段前面
+func (s *SuggestService) Suggest(query string, limit int) []Suggestion {
+	normalized := normalizeSuggestText(query)
+	if normalized == "" || limit <= 0 {
+		return []Suggestion{}
+	}
+
+	type candidate struct {
+		Suggestion
+		tier int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+
+	s.mu.RLock()
+	for _, group := range []struct {
+		entries map[string]*suggestEntry
+		source  string
+	}{
+		{s.keywords, SuggestSourceHistory},
+		{s.titles, SuggestSourceTitle},
+	} {
+		for key, entry := range group.entries {
+			if seen[key] || key == normalized {
+				continue
+			}
+			tier, ok := suggestMatchTier(key, normalized)
+			if !ok {
+				continue
+			}
+			seen[key] = true
+			candidates = append(candidates, candidate{
+				Suggestion: Suggestion{Keyword: entry.Keyword, Count: entry.Count, Source: group.source},
+				tier:       tier,
+			})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Keyword < b.Keyword
+	})
+
+	// 屏蔽的关键词不作为建议
+	blacklist := GetSearchBlacklist()
+	suggestions := make([]Suggestion, 0, limit)
+	for _, c := range candidates {
+		if len(suggestions) >= limit {
+			break
+		}
+		if blacklist.Check(c.Keyword) != nil {
+			continue
+		}
+		suggestions = append(suggestions, c.Suggestion)
+	}
+	return suggestions
+}
+
+// suggestMatchTier 匹配级别：0前缀匹配，1包含匹配，2模糊匹配
+func suggestMatchTier(candidate string, query string) (int, bool) {
+	if strings.HasPrefix(candidate, query) {
+		return 0, true
+	}
+	if strings.Contains(candidate, query) {
+		return 1, true
+	}
+	if utf8.RuneCountInString(query) >= 2 && isSubsequence(candidate, query) {
+		return 2, true
+	}
+	return 0, false
+}
+
+// isSubsequence query的字符是否按顺序出现在text中
+func isSubsequence(text string, query string) bool {
+	remaining := []rune(query)
+	for _, r := range text {
+		if r == remaining[0] {
+			remaining = remaining[1:]
+			if len(remaining) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addSuggestEntry 候选词计数加一，归一化后相同的候选词保留最近一次的原始写法
+func addSuggestEntry(entries map[string]*suggestEntry, text string, now time.Time) {
+	key := normalizeSuggestText(text)
+	if key == "" {
+		return
+	}
+	entry, ok := entries[key]
+	if !ok {
+		entry = &suggestEntry{}
+		entries[key] = entry
+	}
+	entry.Keyword = strings.TrimSpace(text)
+	entry.Count++
+	entry.LastSeen = now
+}
+
+// pruneSuggestEntries 候选词超过上限时淘汰次数最少、最久未出现的条目，淘汰到上限的90%，避免每次写入都排序
+func pruneSuggestEntries(entries map[string]*suggestEntry, maxEntries int) {
+	if len(entries) <= maxEntries {
+		return
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := entries[keys[i]], entries[keys[j]]
+		if a.Count != b.Count {
+			return a.Count < b.Count
+		}
+		return a.LastSeen.Before(b.LastSeen)
+	})
+	for _, key := range keys[:len(keys)-maxEntries*9/10] {
+		delete(entries, key)
+	}
+}
+
+// titleSegments 将标题按分隔符切分为可作为建议的片段
+func titleSegments(title string) []string {
+	var segments []string
+	for _, segment := range strings.FieldsFunc(title, func(r rune) bool {
+		return strings.ContainsRune(suggestTitleSeparators, r) || unicode.IsSpace(r)
+	}) {
+		if n := utf8.RuneCountInString(segment); n >= minSuggestTitleLen && n <= maxSuggestTitleLen {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// normalizeSuggestText 归一化候选词：小写并合并空白
+func normalizeSuggestText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// Flush 将搜索建议数据写入磁盘
+func (s *SuggestService) Flush() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := jsonutil.Marshal(suggestFile{
+		Keywords: s.keywords,
+		Titles:   s.titles,
+	})
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("搜索建议数据序列化失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.dataFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.dataFile, data, 0644)
+}
+
+// load 从磁盘加载搜索建议数据
+func (s *SuggestService) load() {
+	data, err := os.ReadFile(s.dataFile)
+	if err != nil {
+		return
+	}
+	var stored suggestFile
+	if err := jsonutil.Unmarshal(data, &stored); err != nil {
+		fmt.Printf("[搜索建议] 加载失败: %v\n", err)
+		return
+	}
+	if stored.Keywords != nil {
+		s.keywords = stored.Keywords
+	}
+	if stored.Titles != nil {
+		s.titles = stored.Titles
+	}
+}
+
+// saveLoop 定期落盘
+func (s *SuggestService) saveLoop() {
+	ticker := time.NewTicker(suggestSaveInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.Flush(); err != nil {
+			fmt.Printf("[搜索建议] 保存失败: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/model"
+	"pansou/util/cache"
+	"pansou/util/pool"
+)
+
+// 缓存未命中时CacheTiming.Level的取值
+const cacheLevelMiss = "miss"
+
+// 正在执行的debug=true搜索的耗时记录，按请求ID索引。搜索链路各环节都带有请求ID，
+// 通过请求ID找到记录，不需要修改各环节的参数
+var searchTimings sync.Map // requestID -> *searchTimingRecorder
+
+// searchTimingRecorder 一次搜索的耗时记录，并发安全。nil表示未开启记录，所有方法都可以在nil上调用
+type searchTimingRecorder struct {
+	mu      sync.Mutex
+	start   time.Time
+	cache   []model.CacheTiming
+	sources []model.SourceTiming
+	// 来源（tg:频道名、plugin:插件名） -> 结果出处，同一来源只保留第一次记录的出处
+	provenance map[string]string
+}
+
+// startSearchTiming 开始记录请求的耗时，返回的函数在搜索结束时调用以停止记录
+func startSearchTiming(requestID string) (*searchTimingRecorder, func()) {
+	recorder := &searchTimingRecorder{start: time.Now()}
+	if existing, loaded := searchTimings.LoadOrStore(requestID, recorder); loaded {
+		// 同一请求ID的搜索已在记录（如客户端重复使用请求ID），共用一份记录
+		return existing.(*searchTimingRecorder), func() {}
+	}
+	return recorder, func() { searchTimings.Delete(requestID) }
+}
+
+// searchTimingFor 获取请求的耗时记录，未开启debug时返回nil
+func searchTimingFor(requestID string) *searchTimingRecorder {
+	if requestID == "" {
+		return nil
+	}
+	if recorder, ok := searchTimings.Load(requestID); ok {
+		return recorder.(*searchTimingRecorder)
+	}
+	return nil
+}
+
+// recordCache 记录一次缓存读取，level为空表示未命中
+func (r *searchTimingRecorder) recordCache(source, keyword, level string, elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	if level == "" {
+		level = cacheLevelMiss
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = append(r.cache, model.CacheTiming{
+		Source:    source,
+		Keyword:   keyword,
+		Level:     level,
+		ElapsedMs: elapsed.Milliseconds(),
+	})
+}
+
+// recordSource 记录一个搜索来源的耗时
+func (r *searchTimingRecorder) recordSource(timing model.SourceTiming) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, timing)
+}
+
+// recordProvenance 记录来源的结果出处。插件搜索会嵌套经过插件缓存，最内层先记录，因此已有记录时不覆盖
+func (r *searchTimingRecorder) recordProvenance(source, provenance string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.provenance == nil {
+		r.provenance = make(map[string]string)
+	}
+	if _, ok := r.provenance[source]; !ok {
+		r.provenance[source] = provenance
+	}
+}
+
+// recordResultProvenance 按结果的来源记录出处，用于整体读取自主缓存或工作节点的结果
+func (r *searchTimingRecorder) recordResultProvenance(results []model.SearchResult, provenance string) {
+	if r == nil {
+		return
+	}
+	for _, result := range results {
+		r.recordProvenance(getResultSource(result), provenance)
+	}
+}
+
+// mainCacheProvenance 主缓存命中层级对应的结果出处
+func mainCacheProvenance(level string) string {
+	if level == cache.CacheLevelDisk {
+		return model.ProvenanceMainCacheDisk
+	}
+	return model.ProvenanceMainCacheMemory
+}
+
+// recordTasks 按工作池的结果记录一批并行任务的耗时。names[i]为tasks[i]的来源名称，
+// budgets[i]为任务的独立超时（可以为nil），elapsed为整批任务的耗时。
+// 任务的超时从提交时开始计算，超时任务的耗时为独立超时与整批耗时中较小的一个
+func (r *searchTimingRecorder) recordTasks(sourceType, keyword string, names []string, results []pool.Result[[]model.SearchResult], durations taskDurations, budgets []time.Duration, elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	for i, result := range results {
+		timing := model.SourceTiming{
+			Type:      sourceType,
+			Name:      names[i],
+			Keyword:   keyword,
+			ElapsedMs: durations.get(i).Milliseconds(),
+			Results:   len(result.Value),
+		}
+		if isTimeoutError(result.Err) {
+			waited := elapsed
+			if i < len(budgets) && budgets[i] > 0 && budgets[i] < waited {
+				waited = budgets[i]
+			}
+			timing.TimedOut = true
+			timing.ElapsedMs = waited.Milliseconds()
+		} else if result.Err != nil {
+			timing.Error = result.Err.Error()
+		} else {
+			// 插件结果来自插件缓存时插件已记录出处，这里不覆盖
+			r.recordProvenance(sourceType+":"+names[i], model.ProvenanceLive)
+		}
+		r.recordSource(timing)
+	}
+}
+
+// isTimeoutError 判断错误是否为超时：上下文超时或网络请求超时
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// finish 生成耗时明细，searchDone为所有来源返回的时间。来源按耗时从高到低排列
+func (r *searchTimingRecorder) finish(searchDone time.Time) *model.SearchTiming {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	timing := &model.SearchTiming{
+		TotalMs:  now.Sub(r.start).Milliseconds(),
+		SearchMs: searchDone.Sub(r.start).Milliseconds(),
+		MergeMs:  now.Sub(searchDone).Milliseconds(),
+		Cache:    append([]model.CacheTiming(nil), r.cache...),
+		Sources:  append([]model.SourceTiming(nil), r.sources...),
+	}
+	sort.SliceStable(timing.Sources, func(i, j int) bool {
+		return timing.Sources[i].ElapsedMs > timing.Sources[j].ElapsedMs
+	})
+	if len(r.provenance) > 0 {
+		timing.Provenance = make(map[string]string, len(r.provenance))
+		for source, provenance := range r.provenance {
+			timing.Provenance[source] = provenance
+		}
+	}
+	return timing
+}
+
+// taskDurations 一批任务各自的执行耗时（不含等待并发名额的时间），任务返回时写入。nil表示不记录
+type taskDurations []int64
+
+// newTaskDurations 开启记录时创建n个任务的耗时记录，否则返回nil
+func newTaskDurations(recorder *searchTimingRecorder, n int) taskDurations {
+	if recorder == nil {
+		return nil
+	}
+	return make(taskDurations, n)
+}
+
+// track 在任务开始时调用，返回的函数在任务返回时调用以记录耗时
+func (d taskDurations) track(i int) func() {
+	if d == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { atomic.StoreInt64(&d[i], int64(time.Since(start))) }
+}
+
+// get 获取任务的耗时，任务未返回时为0
+func (d taskDurations) get(i int) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&d[i]))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util/cache"
+)
+
+// 已见链接索引的锁分段数
+const seenLinkLockStripes = 64
+
+// 按关键词分段的锁，避免同一关键词的并发请求互相覆盖索引
+var seenLinkLocks [seenLinkLockStripes]sync.Mutex
+
+// seenLinksCacheKey 关键词已见链接索引的缓存键
+func seenLinksCacheKey(namespace string, keyword string) string {
+	hash := md5.Sum([]byte("seen_links:" + strings.ToLower(strings.TrimSpace(keyword))))
+	return cache.NamespaceCacheKey(namespace, hex.EncodeToString(hash[:]))
+}
+
+// seenLinkHash 链接的紧凑哈希，磁力链接按info-hash计算
+func seenLinkHash(url string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(linkDedupeKey(url)))
+	return h.Sum64()
+}
+
+// markNewLinks 与关键词上次搜索返回的链接比较，将新出现的链接标记为IsNew，并把本次的链接记入索引。
+// 索引保存在主缓存中（内存和磁盘），每个关键词最多保留SEEN_LINKS_MAX个最近的链接；
+// 关键词第一次被搜索时没有可比较的索引，不做标记
+func markNewLinks(namespace string, keyword string, mergedLinks model.MergedLinks) {
+	mainCache := enhancedTwoLevelCache
+	if !config.AppConfig.SeenLinksEnabled || mainCache == nil || len(mergedLinks) == 0 {
+		return
+	}
+	key := seenLinksCacheKey(namespace, keyword)
+	lock := &seenLinkLocks[fnv32(key)%seenLinkLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 读取索引，按写入顺序排列，最近的在后
+	var seen []uint64
+	hasIndex := false
+	if data, hit, err := mainCache.Get(key); err == nil && hit {
+		hasIndex = mainCache.GetSerializer().Deserialize(data, &seen) == nil
+	}
+	seenSet := make(map[uint64]bool, len(seen))
+	for _, h := range seen {
+		seenSet[h] = true
+	}
+
+	var added []uint64
+	for _, links := range mergedLinks {
+		for i := range links {
+			h := seenLinkHash(links[i].URL)
+			if seenSet[h] {
+				continue
+			}
+			seenSet[h] = true
+			added = append(added, h)
+			links[i].IsNew = hasIndex
+		}
+	}
+	if hasIndex && len(added) == 0 {
+		return
+	}
+
+	// 超出上限时丢弃最早记录的链接
+	seen = append(seen, added...)
+	if max := config.AppConfig.SeenLinksMax; max > 0 && len(seen) > max {
+		seen = append([]uint64(nil), seen[len(seen)-max:]...)
+	}
+	data, err := mainCache.GetSerializer().Serialize(seen)
+	if err != nil {
+		return
+	}
+	mainCache.Set(key, data, config.AppConfig.SeenLinksTTL)
+}
+
+// fnv32 计算字符串的32位FNV哈希
+func fnv32(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
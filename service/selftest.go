@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util/cache"
+	"pansou/util/pool"
+)
+
+// 自检结果状态
+const (
+	SelfTestPass    = "pass"    // 站点可访问，解析出带链接的结果
+	SelfTestEmpty   = "empty"   // 站点可访问，但没有解析出带链接的结果，通常是页面结构变化
+	SelfTestTimeout = "timeout" // 超时未完成
+	SelfTestFail    = "fail"    // 请求或解析出错
+)
+
+const (
+	// 默认每个来源的超时时间和同时检查的来源数
+	defaultSelfTestTimeout     = 15 * time.Second
+	maxSelfTestTimeout         = 60 * time.Second
+	defaultSelfTestConcurrency = 8
+	// 一次自检的总时长上限
+	maxSelfTestDuration = 5 * time.Minute
+)
+
+// SelfTestSource 单个来源的自检结果
+type SelfTestSource struct {
+	Source    string `json:"source"`            // plugin:插件名或tg:频道名
+	Keyword   string `json:"keyword,omitempty"` // 与自检关键词不同时为实际使用的关键词（英文站点插件使用译名）
+	Status    string `json:"status"`
+	Reachable bool   `json:"reachable"` // 是否成功请求站点
+	Results   int    `json:"results"`   // 解析出的结果数
+	Links     int    `json:"links"`     // 提取出的链接数
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SelfTestReport 自检报告
+type SelfTestReport struct {
+	Keyword   string           `json:"keyword"`
+	StartedAt time.Time        `json:"started_at"`
+	ElapsedMs int64            `json:"elapsed_ms"`
+	Summary   map[string]int   `json:"summary"` // 各状态的来源数
+	Sources   []SelfTestSource `json:"sources"`
+}
+
+var (
+	lastSelfTestReport *SelfTestReport
+	lastSelfTestMu     sync.RWMutex
+)
+
+// GetLastSelfTestReport 获取最近一次自检报告，尚未执行时返回nil
+func GetLastSelfTestReport() *SelfTestReport {
+	lastSelfTestMu.RLock()
+	defer lastSelfTestMu.RUnlock()
+	return lastSelfTestReport
+}
+
+// RunSelfTest 用自检关键词搜索所有已启用的插件和默认TG频道，逐个来源报告是否可访问、解析出的结果数和链接数，
+// 用于发现上游站点改版导致的插件失效。不读取缓存，每个来源最多等待timeout（为0时使用默认值），
+// sourceType为tg或plugin时只检查对应来源
+func (s *SearchService) RunSelfTest(keyword string, sourceType string, timeout time.Duration) SelfTestReport {
+	if keyword == "" {
+		keyword = config.AppConfig.SelfTestKeyword
+	}
+	if timeout <= 0 {
+		timeout = defaultSelfTestTimeout
+	}
+	if timeout > maxSelfTestTimeout {
+		timeout = maxSelfTestTimeout
+	}
+
+	var sources []string
+	var tasks []func(context.Context) (SelfTestSource, error)
+	if sourceType != "tg" && config.AppConfig.AsyncPluginEnabled && s.pluginManager != nil {
+		for _, p := range s.pluginManager.GetPlugins() {
+			p := p
+			sources = append(sources, "plugin:"+p.Name())
+			tasks = append(tasks, func(context.Context) (SelfTestSource, error) {
+				return s.selfTestPlugin(p, keyword), nil
+			})
+		}
+	}
+	if sourceType != "plugin" {
+		for _, channel := range config.GetDefaultChannels() {
+			channel := channel
+			sources = append(sources, "tg:"+channel)
+			tasks = append(tasks, func(context.Context) (SelfTestSource, error) {
+				return s.selfTestChannel(channel, keyword), nil
+			})
+		}
+	}
+
+	report := SelfTestReport{
+		Keyword:   keyword,
+		StartedAt: time.Now(),
+		Summary:   make(map[string]int),
+		Sources:   make([]SelfTestSource, 0, len(tasks)),
+	}
+	timeouts := make([]time.Duration, len(tasks))
+	for i := range timeouts {
+		timeouts[i] = timeout
+	}
+	for i, result := range pool.RunWithTaskTimeouts(maxSelfTestDuration, defaultSelfTestConcurrency, tasks, timeouts) {
+		source := result.Value
+		if result.Err != nil {
+			source = SelfTestSource{Source: sources[i], Status: SelfTestFail, ElapsedMs: timeout.Milliseconds(), Error: result.Err.Error()}
+			if errors.Is(result.Err, context.DeadlineExceeded) {
+				source.Status = SelfTestTimeout
+			}
+		}
+		report.Summary[source.Status]++
+		report.Sources = append(report.Sources, source)
+	}
+	report.ElapsedMs = time.Since(report.StartedAt).Milliseconds()
+
+	fmt.Printf("[自检] 关键词: %s | 通过: %d | 无结果: %d | 超时: %d | 失败: %d | 耗时: %dms\n",
+		keyword, report.Summary[SelfTestPass], report.Summary[SelfTestEmpty], report.Summary[SelfTestTimeout], report.Summary[SelfTestFail], report.ElapsedMs)
+	lastSelfTestMu.Lock()
+	lastSelfTestReport = &report
+	lastSelfTestMu.Unlock()
+	return report
+}
+
+// selfTestPlugin 绕过插件缓存搜索一次。站点语言与关键词不同且片名对照表中有译名时使用译名搜索
+func (s *SearchService) selfTestPlugin(p plugin.AsyncSearchPlugin, keyword string) SelfTestSource {
+	source := SelfTestSource{Source: "plugin:" + p.Name()}
+	if translated, lang, ok := GetTitleAliasDictionary().Translate(keyword); ok && getPluginLanguage(p) == lang {
+		keyword = translated
+		source.Keyword = translated
+	}
+
+	plugin.ForgetCachedSearch(p.Name(), keyword)
+	ext := make(map[string]interface{})
+	p.SetMainCacheKey(cache.GeneratePluginCacheKey(keyword, nil, ext))
+	p.SetCurrentKeyword(keyword)
+
+	start := time.Now()
+	var results []model.SearchResult
+	var err error
+	final := true
+	func() {
+		defer recoverPluginTask(p.Name(), &err)
+		// 支持SearchWithResult的插件可以区分超时（后台继续搜索）和确实没有结果
+		if searcher, ok := p.(interface {
+			SearchWithResult(string, map[string]interface{}) (model.PluginSearchResult, error)
+		}); ok {
+			var result model.PluginSearchResult
+			result, err = searcher.SearchWithResult(keyword, ext)
+			results, final = result.Results, result.IsFinal
+			return
+		}
+		results, err = p.Search(keyword, ext)
+	}()
+	source.ElapsedMs = time.Since(start).Milliseconds()
+
+	switch {
+	case err != nil:
+		source.Status = SelfTestFail
+		source.Error = err.Error()
+	case !final:
+		source.Status = SelfTestTimeout
+		source.Reachable = true
+	default:
+		source.Reachable = true
+		fillSelfTestCounts(&source, results)
+	}
+	return source
+}
+
+// selfTestChannel 直接请求TG频道的搜索页面
+func (s *SearchService) selfTestChannel(channel string, keyword string) SelfTestSource {
+	source := SelfTestSource{Source: "tg:" + channel}
+	start := time.Now()
+	results, err := s.searchChannel(keyword, channel)
+	source.ElapsedMs = time.Since(start).Milliseconds()
+	if err != nil {
+		source.Status = SelfTestFail
+		source.Error = err.Error()
+		return source
+	}
+	source.Reachable = true
+	fillSelfTestCounts(&source, results)
+	return source
+}
+
+// fillSelfTestCounts 统计结果数和链接数，有链接时通过
+func fillSelfTestCounts(source *SelfTestSource, results []model.SearchResult) {
+	source.Results = len(results)
+	for _, result := range results {
+		source.Links += len(result.Links)
+	}
+	if source.Links > 0 {
+		source.Status = SelfTestPass
+	} else {
+		source.Status = SelfTestEmpty
+	}
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+	jsonutil "pansou/util/json"
+)
+
+// 重排方式
+const (
+	RerankNone     = ""         // 不重排
+	RerankSemantic = "semantic" // 按关键词与标题的语义相似度重排前K条
+)
+
+// ErrInvalidRerank 重排方式无效
+var ErrInvalidRerank = errors.New("rerank必须为semantic")
+
+// 重排得分中语义相似度的权重，其余为原排序位置的权重
+const semanticRerankWeight = 0.8
+
+// 内置向量的维度
+const localEmbeddingDims = 512
+
+// 向量接口最多缓存的文本向量数
+const maxEmbeddingCacheEntries = 20000
+
+// Embedder 将文本转换为向量
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// SemanticReranker 按关键词与标题的语义相似度重排排名靠前的结果
+type SemanticReranker struct {
+	embedder Embedder
+	topK     int
+}
+
+var (
+	globalSemanticReranker *SemanticReranker
+	semanticRerankerOnce   sync.Once
+)
+
+// GetSemanticReranker 获取全局语义重排器：配置了RERANK_EMBEDDING_URL时使用向量接口，否则使用内置的字符n-gram向量
+func GetSemanticReranker() *SemanticReranker {
+	semanticRerankerOnce.Do(func() {
+		var embedder Embedder = localEmbedder{}
+		if config.AppConfig.RerankEmbeddingURL != "" {
+			embedder = newAPIEmbedder(util.GetHTTPClient(), config.AppConfig.RerankEmbeddingURL,
+				config.AppConfig.RerankEmbeddingModel, config.AppConfig.RerankEmbeddingKey)
+		}
+		globalSemanticReranker = NewSemanticReranker(embedder, config.AppConfig.RerankTopK)
+	})
+	return globalSemanticReranker
+}
+
+// NewSemanticReranker 创建语义重排器，topK为参与重排的条数
+func NewSemanticReranker(embedder Embedder, topK int) *SemanticReranker {
+	return &SemanticReranker{embedder: embedder, topK: topK}
+}
+
+// RerankResults 重排前K条结果，返回新的切片，不修改传入的结果。出错（包括ctx超时）时返回错误，调用方保持原排序
+func (r *SemanticReranker) RerankResults(ctx context.Context, query string, results []model.SearchResult) ([]model.SearchResult, error) {
+	k := min(r.topK, len(results))
+	if k < 2 {
+		return results, nil
+	}
+	titles := make([]string, k)
+	for i := 0; i < k; i++ {
+		titles[i] = results[i].Title
+	}
+	order, err := r.rank(ctx, query, titles)
+	if err != nil {
+		return results, err
+	}
+
+	reranked := make([]model.SearchResult, len(results))
+	for i, index := range order {
+		reranked[i] = results[index]
+	}
+	copy(reranked[k:], results[k:])
+	return reranked, nil
+}
+
+// RerankMergedLinks 按链接标题重排每种网盘类型的前K条链接（原地修改），出错时已重排的类型保持重排后的顺序
+func (r *SemanticReranker) RerankMergedLinks(ctx context.Context, query string, mergedLinks model.MergedLinks) error {
+	for _, links := range mergedLinks {
+		k := min(r.topK, len(links))
+		if k < 2 {
+			continue
+		}
+		notes := make([]string, k)
+		for i := 0; i < k; i++ {
+			notes[i] = links[i].Note
+		}
+		order, err := r.rank(ctx, query, notes)
+		if err != nil {
+			return err
+		}
+		sorted := make([]model.MergedLink, k)
+		for i, index := range order {
+			sorted[i] = links[index]
+		}
+		copy(links, sorted)
+	}
+	return nil
+}
+
+// rank 计算重排后的顺序：得分为语义相似度与原排序位置的加权和，得分相同时保持原顺序
+func (r *SemanticReranker) rank(ctx context.Context, query string, titles []string) ([]int, error) {
+	vectors, err := r.embedder.Embed(ctx, append([]string{query}, titles...))
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(titles)+1 {
+		return nil, fmt.Errorf("向量数量不匹配: %d != %d", len(vectors), len(titles)+1)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, len(titles))
+	for i := range titles {
+		position := 1 - float64(i)/float64(len(titles))
+		scores[i] = semanticRerankWeight*cosineSimilarity(vectors[0], vectors[i+1]) + (1-semanticRerankWeight)*position
+	}
+	order := make([]int, len(titles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	return order, nil
+}
+
+// cosineSimilarity 计算两个向量的余弦相似度，任一向量为零向量时为0
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// localEmbedder 内置向量：把英文单词、单词的字符三元组、多个英文单词的首字母缩写（如The Big Bang Theory得到tbbt）
+// 和中日文的字、相邻两字哈希到固定维度，不依赖外部模型，能改善英文标题、缩写和词序不同时的排序
+type localEmbedder struct{}
+
+// Embed 计算文本的内置向量
+func (localEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = localEmbedding(text)
+	}
+	return vectors, nil
+}
+
+// localEmbedding 计算单个文本的内置向量
+func localEmbedding(text string) []float64 {
+	vector := make([]float64, localEmbeddingDims)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		// 用哈希的最高位决定符号，减少哈希冲突带来的偏差
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[sum%localEmbeddingDims] += weight
+	}
+
+	var words []string
+	var cjk []rune
+	flushCJK := func() {
+		for i, r := range cjk {
+			add("c:"+string(r), 0.5)
+			if i > 0 {
+				add("b:"+string(cjk[i-1:i+1]), 1)
+			}
+		}
+		cjk = cjk[:0]
+	}
+	var word strings.Builder
+	flushWord := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word.WriteRune(r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	for _, w := range words {
+		add("w:"+w, 1)
+		padded := []rune("^" + w + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			add("t:"+string(padded[i:i+3]), 0.3)
+		}
+	}
+	// 首字母缩写与单词使用同一特征，使缩写查询能匹配完整标题
+	for _, acronym := range wordAcronyms(words) {
+		add("w:"+acronym, 1)
+	}
+	return vector
+}
+
+// wordAcronyms 连续的纯字母单词（至少两个，如S01、1080p等含数字的单词会打断）的首字母缩写，
+// 以the、a、an开头时同时生成去掉冠词的缩写
+func wordAcronyms(words []string) []string {
+	var acronyms []string
+	var run []string
+	flush := func() {
+		if len(run) >= 2 {
+			var initials strings.Builder
+			for _, w := range run {
+				initials.WriteRune([]rune(w)[0])
+			}
+			acronyms = append(acronyms, initials.String())
+			if len(run) >= 3 && (run[0] == "the" || run[0] == "a" || run[0] == "an") {
+				acronyms = append(acronyms, initials.String()[1:])
+			}
+		}
+		run = run[:0]
+	}
+	for _, w := range words {
+		alphabetic := true
+		for _, r := range w {
+			if !unicode.IsLetter(r) || r > unicode.MaxASCII {
+				alphabetic = false
+				break
+			}
+		}
+		if !alphabetic {
+			flush()
+			continue
+		}
+		run = append(run, w)
+	}
+	flush()
+	return acronyms
+}
+
+// apiEmbedder 调用OpenAI兼容的向量接口（POST {"model","input"}，返回data[].embedding），缓存已计算的文本向量
+type apiEmbedder struct {
+	client *http.Client
+	url    string
+	model  string
+	key    string
+
+	mu    sync.Mutex
+	cache map[string][]float64
+}
+
+// newAPIEmbedder 创建向量接口客户端
+func newAPIEmbedder(client *http.Client, url, model, key string) *apiEmbedder {
+	return &apiEmbedder{client: client, url: url, model: model, key: key, cache: make(map[string][]float64)}
+}
+
+// embeddingResponse 向量接口的响应
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed 只为未缓存的文本请求向量接口
+func (e *apiEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	var missing []string
+	missingIndex := make(map[string][]int)
+	e.mu.Lock()
+	for i, text := range texts {
+		if vector, ok := e.cache[text]; ok {
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := missingIndex[text]; !ok {
+			missing = append(missing, text)
+		}
+		missingIndex[text] = append(missingIndex[text], i)
+	}
+	e.mu.Unlock()
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	fetched, err := e.request(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.cache)+len(missing) > maxEmbeddingCacheEntries {
+		e.cache = make(map[string][]float64)
+	}
+	for i, text := range missing {
+		e.cache[text] = fetched[i]
+		for _, index := range missingIndex[text] {
+			vectors[index] = fetched[i]
+		}
+	}
+	return vectors, nil
+}
+
+// request 请求向量接口，返回与texts顺序一致的向量
+func (e *apiEmbedder) request(ctx context.Context, texts []string) ([][]float64, error) {
+	body, err := jsonutil.Marshal(map[string]interface{}{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.key != "" {
+		req.Header.Set("Authorization", "Bearer "+e.key)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("向量接口返回 %d", resp.StatusCode)
+	}
+
+	var parsed embeddingResponse
+	if err := jsonutil.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("解析向量接口响应失败: %v", err)
+	}
+	vectors := make([][]float64, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("向量接口返回了无效的index: %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("向量接口缺少第%d条文本的向量", i)
+		}
+	}
+	return vectors, nil
+}
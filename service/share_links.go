@@ -0,0 +1,52 @@
+package service
+
+import (
+	"pansou/model"
+	"pansou/util"
+)
+
+// canonicalizeShareLinks 规范化结果中的网盘分享链接（见util.CanonicalizeShareURL），返回新的切片，不修改传入的结果。
+// 链接已有的提取码同样规范化（见util.NormalizeSharePassword），URL中的提取码在链接没有有效提取码时填入Password；
+// 规范化后同一结果中重复的链接只保留第一个
+func canonicalizeShareLinks(results []model.SearchResult) []model.SearchResult {
+	canonicalized := make([]model.SearchResult, len(results))
+	for i, result := range results {
+		canonicalized[i] = result
+
+		var links []model.Link
+		seen := make(map[string]bool, len(result.Links))
+		for j, link := range result.Links {
+			linkType := link.Type
+			if linkType == "" {
+				linkType = util.GetLinkType(link.URL)
+			}
+			url, password := util.CanonicalizeShareURL(linkType, link.URL)
+			duplicate := seen[url]
+			seen[url] = true
+			if existing := util.NormalizeSharePassword(linkType, link.Password); existing != "" {
+				password = existing
+			}
+
+			changed := url != link.URL || password != link.Password
+			if !changed && !duplicate {
+				if links != nil {
+					links = append(links, link)
+				}
+				continue
+			}
+			if links == nil {
+				links = append(make([]model.Link, 0, len(result.Links)), result.Links[:j]...)
+			}
+			if duplicate {
+				continue
+			}
+			link.URL = url
+			link.Password = password
+			links = append(links, link)
+		}
+		if links != nil {
+			canonicalized[i].Links = links
+		}
+	}
+	return canonicalized
+}
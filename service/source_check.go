@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"pansou/config"
+	"pansou/plugin"
+	"pansou/util/i18n"
+)
+
+// SearchSourceStatus 搜索来源的可用性
+type SearchSourceStatus struct {
+	TGAvailable     bool     `json:"tg_available"`
+	PluginAvailable bool     `json:"plugin_available"`
+	Problems        []string `json:"problems,omitempty"` // 来源不可用的原因及对应配置
+}
+
+// CheckSearchSources 启动时检查TG频道和插件是否可用，并输出检查结果。两者都不可用时所有搜索都会返回503
+func CheckSearchSources(pluginManager *plugin.PluginManager) SearchSourceStatus {
+	var status SearchSourceStatus
+	status.TGAvailable = len(config.GetDefaultChannels()) > 0
+	if !status.TGAvailable {
+		status.Problems = append(status.Problems, i18n.L(i18n.LogSourceNoTGChannels))
+	}
+
+	switch {
+	case !config.AppConfig.AsyncPluginEnabled:
+		status.Problems = append(status.Problems, i18n.L(i18n.LogSourcePluginsDisabled))
+	case pluginManager == nil || len(pluginManager.GetPlugins()) == 0:
+		status.Problems = append(status.Problems, i18n.L(i18n.LogSourceNoPlugins))
+	default:
+		status.PluginAvailable = true
+	}
+
+	for _, problem := range status.Problems {
+		fmt.Printf("[配置检查] %s\n", problem)
+	}
+	if !status.TGAvailable && !status.PluginAvailable {
+		fmt.Printf("[配置检查] %s\n", i18n.L(i18n.LogSourceNoneAvailable))
+	}
+	return status
+}
+
+// UnavailableSourceReason 检查请求的搜索来源是否可用，不可用时按lang返回原因（用于503响应），可用时返回空字符串。
+// src=all时只要TG或插件之一可用即可
+func (s *SearchService) UnavailableSourceReason(sourceType string, channels []string, lang string) string {
+	var reasons []string
+	tgAvailable := len(channels) > 0
+	if !tgAvailable && sourceType != "plugin" {
+		reasons = append(reasons, i18n.T(lang, i18n.MsgSourceNoTGChannels))
+	}
+	pluginAvailable := config.AppConfig.AsyncPluginEnabled && s.pluginManager != nil && len(s.pluginManager.GetPlugins()) > 0
+	if !pluginAvailable && sourceType != "tg" {
+		if !config.AppConfig.AsyncPluginEnabled {
+			reasons = append(reasons, i18n.T(lang, i18n.MsgSourcePluginsDisabled))
+		} else {
+			reasons = append(reasons, i18n.T(lang, i18n.MsgSourceNoPlugins))
+		}
+	}
+
+	switch sourceType {
+	case "tg":
+		if tgAvailable {
+			return ""
+		}
+	case "plugin":
+		if pluginAvailable {
+			return ""
+		}
+	default:
+		if tgAvailable || pluginAvailable {
+			return ""
+		}
+	}
+	separator := "，"
+	if lang != i18n.LangZH {
+		separator = ", "
+	}
+	return strings.Join(reasons, separator)
+}
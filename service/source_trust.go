@@ -0,0 +1,121 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// 来源可信度
+const (
+	SourceTrustTrusted   = "trusted"   // 可信来源：直接返回链接和提取码
+	SourceTrustStandard  = "standard"  // 普通来源：直接返回链接和提取码
+	SourceTrustCommunity = "community" // 社区来源：链接经跳转地址访问，不直接返回提取码，并提示使用前核实
+)
+
+// GetSourceTrustLevel 获取来源（tg:频道名、plugin:插件名）的可信度：
+// 优先精确匹配，其次匹配tg:*、plugin:*，都未配置时使用默认可信度
+func GetSourceTrustLevel(source string) string {
+	source = strings.ToLower(source)
+	if level, ok := config.AppConfig.SourceTrustLevels[source]; ok {
+		return level
+	}
+	if kind, _, ok := strings.Cut(source, ":"); ok {
+		if level, ok := config.AppConfig.SourceTrustLevels[kind+":*"]; ok {
+			return level
+		}
+	}
+	if config.AppConfig.SourceTrustDefault != "" {
+		return config.AppConfig.SourceTrustDefault
+	}
+	return SourceTrustStandard
+}
+
+// sourceTrustEnabled 是否可能存在社区来源，未配置时跳过可信度处理
+func sourceTrustEnabled() bool {
+	if config.AppConfig.SourceTrustDefault == SourceTrustCommunity {
+		return true
+	}
+	for _, level := range config.AppConfig.SourceTrustLevels {
+		if level == SourceTrustCommunity {
+			return true
+		}
+	}
+	return false
+}
+
+// applySourceTrust 按来源可信度处理响应中的链接：社区来源的链接替换为跳转地址，
+// 提取码附带在跳转目标中而不直接返回，并标记为社区来源。需在合并链接标记和扁平列表排序之后调用
+func applySourceTrust(response *model.SearchResponse, keyword string) {
+	if !sourceTrustEnabled() {
+		return
+	}
+
+	for linkType, links := range response.MergedByType {
+		for i := range links {
+			link := &links[i]
+			if GetSourceTrustLevel(link.Source) != SourceTrustCommunity {
+				continue
+			}
+			link.URL, link.LinkID = registerTrustRedirect(link.URL, link.Password, linkType, keyword, link.Source)
+			link.PasswordHidden = link.Password != ""
+			link.Password = ""
+			link.CommunitySource = true
+		}
+	}
+
+	for i := range response.Links {
+		link := &response.Links[i]
+		if GetSourceTrustLevel(link.Source) != SourceTrustCommunity {
+			continue
+		}
+		link.URL, link.LinkID = registerTrustRedirect(link.URL, link.Password, link.Type, keyword, link.Source)
+		link.PasswordHidden = link.Password != ""
+		link.Password = ""
+		link.CommunitySource = true
+	}
+
+	for i := range response.Results {
+		result := &response.Results[i]
+		source := getResultSource(*result)
+		if GetSourceTrustLevel(source) != SourceTrustCommunity {
+			continue
+		}
+		// 结果可能来自缓存，复制链接后再修改
+		links := make([]model.Link, len(result.Links))
+		for j, link := range result.Links {
+			link.URL, _ = registerTrustRedirect(link.URL, link.Password, link.Type, keyword, source)
+			link.PasswordHidden = link.Password != ""
+			link.Password = ""
+			links[j] = link
+		}
+		result.Links = links
+		result.CommunitySource = true
+	}
+}
+
+// registerTrustRedirect 登记链接的跳转目标（附带提取码），返回跳转地址和跳转ID
+func registerTrustRedirect(linkURL string, password string, linkType string, keyword string, source string) (string, string) {
+	id := GetClickService().RegisterRedirect(linkURL, withPasswordParam(linkURL, password), linkType, keyword, source)
+	return "/go/" + id, id
+}
+
+// withPasswordParam 将提取码以pwd参数附加到链接，链接已带pwd参数或无法解析时原样返回
+func withPasswordParam(linkURL string, password string) string {
+	if password == "" {
+		return linkURL
+	}
+	u, err := url.Parse(linkURL)
+	if err != nil || u.Scheme == "magnet" {
+		return linkURL
+	}
+	query := u.Query()
+	if query.Get("pwd") != "" {
+		return linkURL
+	}
+	query.Set("pwd", password)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
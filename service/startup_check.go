@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/plugin"
+	"pansou/util/i18n"
+)
+
+// StartupCheck 单项启动自检结果
+type StartupCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// StartupReport 启动自检报告
+type StartupReport struct {
+	OK     bool           `json:"ok"`
+	Time   time.Time      `json:"time"`
+	Checks []StartupCheck `json:"checks"`
+}
+
+// Failed 未通过的检查项名称
+func (r StartupReport) Failed() []string {
+	var failed []string
+	for _, check := range r.Checks {
+		if !check.OK {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+var (
+	startupReport     *StartupReport
+	startupReportLock sync.RWMutex
+)
+
+// GetStartupReport 获取最近一次启动自检报告，尚未执行时返回nil
+func GetStartupReport() *StartupReport {
+	startupReportLock.RLock()
+	defer startupReportLock.RUnlock()
+	return startupReport
+}
+
+// RunStartupSelfCheck 检查缓存链路是否已正确连接：主缓存已初始化、序列化器已注入插件、
+// 缓存写入管理器已初始化并设置了主缓存更新函数、异步插件均已注入主缓存更新函数。
+// 应在NewSearchService之后调用，结果输出到日志并保存供健康检查接口查询
+func RunStartupSelfCheck(pluginManager *plugin.PluginManager) StartupReport {
+	report := StartupReport{OK: true, Time: time.Now()}
+	// add 记录检查结果，failDetail只在未通过时保留
+	add := func(name string, ok bool, failDetail string) {
+		check := StartupCheck{Name: name, OK: ok}
+		if !ok {
+			check.Detail = failDetail
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	// 未启用缓存时缓存链路不需要连接
+	if config.AppConfig == nil || !config.AppConfig.CacheEnabled {
+		report.Checks = append(report.Checks, StartupCheck{Name: "main_cache", OK: true, Detail: "缓存未启用，跳过缓存链路检查"})
+	} else {
+		add("main_cache", enhancedTwoLevelCache != nil, "主缓存未初始化")
+		add("cache_serializer", plugin.HasGlobalCacheSerializer(), "序列化器未注入插件")
+
+		manager := globalCacheWriteManager
+		add("write_manager", manager != nil && manager.IsInitialized(), "缓存写入管理器未初始化")
+		add("write_manager_updater", manager != nil && manager.HasMainCacheUpdater(), "缓存写入管理器未设置主缓存更新函数")
+
+		if pluginManager != nil {
+			var missing []string
+			for _, p := range pluginManager.GetPlugins() {
+				if wired, ok := p.(interface{ HasMainCacheUpdater() bool }); ok && !wired.HasMainCacheUpdater() {
+					missing = append(missing, p.Name())
+				}
+			}
+			add("plugin_cache_updaters", len(missing) == 0, "未注入主缓存更新函数的插件: "+strings.Join(missing, ", "))
+		}
+	}
+
+	startupReportLock.Lock()
+	startupReport = &report
+	startupReportLock.Unlock()
+
+	for _, check := range report.Checks {
+		status := i18n.L(i18n.LogSelfCheckPassed)
+		if !check.OK {
+			status = i18n.L(i18n.LogSelfCheckFailed)
+		}
+		if check.Detail != "" {
+			fmt.Printf("[启动自检] %s: %s | %s\n", check.Name, status, check.Detail)
+		} else {
+			fmt.Printf("[启动自检] %s: %s\n", check.Name, status)
+		}
+	}
+	if !report.OK {
+		fmt.Printf("[启动自检] %s\n", i18n.L(i18n.LogSelfCheckMiswired, strings.Join(report.Failed(), ", ")))
+	}
+	return report
+}
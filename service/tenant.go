@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	jsonutil "pansou/util/json"
+)
+
+// 租户识别错误
+var (
+	ErrUnknownTenant  = errors.New("未知的租户")
+	ErrInvalidAPIKey  = errors.New("无效的API Key")
+	ErrAPIKeyRequired = errors.New("该租户需要通过API Key访问")
+)
+
+// tenantKey 上下文中保存租户的键类型
+type tenantKey struct{}
+
+// WithTenant 将租户写入上下文
+func WithTenant(ctx context.Context, tenant *model.Tenant) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext 从上下文中获取租户，未识别租户时返回nil
+func TenantFromContext(ctx context.Context) *model.Tenant {
+	if ctx == nil {
+		return nil
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(*model.Tenant)
+	return tenant
+}
+
+// tenantBucket 租户的令牌桶
+type tenantBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TenantRegistry 租户配置与限流状态
+type TenantRegistry struct {
+	tenants map[string]*model.Tenant
+	byKey   map[string]*model.Tenant
+
+	mu      sync.Mutex
+	buckets map[string]*tenantBucket
+}
+
+var (
+	globalTenantRegistry *TenantRegistry
+	tenantRegistryOnce   sync.Once
+)
+
+// GetTenantRegistry 获取按TENANTS_FILE加载的全局租户配置，未配置或加载失败时不启用租户
+func GetTenantRegistry() *TenantRegistry {
+	tenantRegistryOnce.Do(func() {
+		var tenants []model.Tenant
+		if config.AppConfig.TenantsFile != "" {
+			var err error
+			tenants, err = loadTenants(config.AppConfig.TenantsFile)
+			if err != nil {
+				fmt.Printf("[租户] 加载租户配置失败: %s | 错误: %v\n", config.AppConfig.TenantsFile, err)
+			}
+		}
+		registry, err := NewTenantRegistry(tenants)
+		if err != nil {
+			fmt.Printf("[租户] 租户配置无效: %v\n", err)
+			registry, _ = NewTenantRegistry(nil)
+		}
+		if registry.Enabled() {
+			fmt.Printf("[租户] 已加载 %d 个租户\n", len(registry.tenants))
+		}
+		globalTenantRegistry = registry
+	})
+	return globalTenantRegistry
+}
+
+// loadTenants 读取JSON格式的租户列表
+func loadTenants(file string) ([]model.Tenant, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var tenants []model.Tenant
+	if err := jsonutil.Unmarshal(data, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// NewTenantRegistry 创建租户配置，租户ID和API Key不能重复
+func NewTenantRegistry(tenants []model.Tenant) (*TenantRegistry, error) {
+	r := &TenantRegistry{
+		tenants: make(map[string]*model.Tenant, len(tenants)),
+		byKey:   make(map[string]*model.Tenant),
+		buckets: make(map[string]*tenantBucket),
+	}
+	for i := range tenants {
+		tenant := tenants[i]
+		tenant.ID = strings.TrimSpace(tenant.ID)
+		if tenant.ID == "" {
+			return nil, errors.New("租户ID不能为空")
+		}
+		if _, exists := r.tenants[tenant.ID]; exists {
+			return nil, fmt.Errorf("租户ID重复: %s", tenant.ID)
+		}
+		if tenant.RateLimit > 0 && tenant.Burst <= 0 {
+			tenant.Burst = tenant.RateLimit
+		}
+		r.tenants[tenant.ID] = &tenant
+		for _, key := range tenant.APIKeys {
+			if key == "" {
+				continue
+			}
+			if _, exists := r.byKey[key]; exists {
+				return nil, fmt.Errorf("API Key重复（租户%s）", tenant.ID)
+			}
+			r.byKey[key] = &tenant
+		}
+	}
+	return r, nil
+}
+
+// Enabled 是否配置了租户
+func (r *TenantRegistry) Enabled() bool {
+	return len(r.tenants) > 0
+}
+
+// Resolve 根据API Key或租户ID识别租户。优先使用API Key；配置了API Key的租户不能只凭租户ID访问。
+// 两者都为空时返回nil（不属于任何租户）
+func (r *TenantRegistry) Resolve(tenantID, apiKey string) (*model.Tenant, error) {
+	if apiKey != "" {
+		tenant, ok := r.byKey[apiKey]
+		if !ok {
+			return nil, ErrInvalidAPIKey
+		}
+		if tenantID != "" && tenantID != tenant.ID {
+			return nil, ErrInvalidAPIKey
+		}
+		return tenant, nil
+	}
+	if tenantID == "" {
+		return nil, nil
+	}
+	tenant, ok := r.tenants[tenantID]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	if len(tenant.APIKeys) > 0 {
+		return nil, ErrAPIKeyRequired
+	}
+	return tenant, nil
+}
+
+// Allow 按租户的令牌桶判断是否允许本次请求，不允许时返回需要等待的时间
+func (r *TenantRegistry) Allow(tenant *model.Tenant) (bool, time.Duration) {
+	if tenant == nil || tenant.RateLimit <= 0 {
+		return true, 0
+	}
+	rate := float64(tenant.RateLimit) / float64(time.Minute)
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bucket, ok := r.buckets[tenant.ID]
+	if !ok {
+		bucket = &tenantBucket{tokens: float64(tenant.Burst), last: now}
+		r.buckets[tenant.ID] = bucket
+	}
+	bucket.tokens += float64(now.Sub(bucket.last)) * rate
+	if bucket.tokens > float64(tenant.Burst) {
+		bucket.tokens = float64(tenant.Burst)
+	}
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / rate)
+}
+
+// restrictTenantPlugins 将请求的插件限制在租户允许的范围内：未指定插件时使用租户的全部插件。
+// 返回false表示请求的插件均不被允许
+func restrictTenantPlugins(tenant *model.Tenant, plugins []string) ([]string, bool) {
+	if tenant == nil || len(tenant.Plugins) == 0 {
+		return plugins, true
+	}
+	var allowed []string
+	for _, p := range plugins {
+		if p != "" && tenant.AllowsPlugin(p) {
+			allowed = append(allowed, p)
+		}
+	}
+	if len(allowed) > 0 {
+		return allowed, true
+	}
+	for _, p := range plugins {
+		if p != "" {
+			return nil, false
+		}
+	}
+	return append([]string(nil), tenant.Plugins...), true
+}
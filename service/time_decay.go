@@ -0,0 +1,151 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	jsonutil "pansou/util/json"
+)
+
+// 内容分类
+const (
+	CategorySeries   = "series"   // 连载剧集、动漫、综艺，时效性最强
+	CategoryMovie    = "movie"    // 电影，经典影片不应因发布时间久而排到后面
+	CategorySoftware = "software" // 软件，新版本更有价值
+	CategoryEbook    = "ebook"    // 电子书、文档，基本不受时间影响
+	CategoryDefault  = "default"  // 未识别的分类
+)
+
+// DecayStep 时间衰减曲线的一档：发布不超过MaxDays天的结果得Score分，MaxDays<=0表示其余所有结果
+type DecayStep struct {
+	MaxDays float64 `json:"max_days"`
+	Score   float64 `json:"score"`
+}
+
+// RankingProfile 排序配置，TimeDecay按内容分类配置时间衰减曲线，未配置的分类使用default
+type RankingProfile struct {
+	TimeDecay map[string][]DecayStep `json:"time_decay"`
+}
+
+// defaultRankingProfile 内置排序配置，default与原有的固定分档一致
+var defaultRankingProfile = RankingProfile{
+	TimeDecay: map[string][]DecayStep{
+		CategoryDefault: {
+			{1, 500}, {3, 400}, {7, 300}, {30, 200}, {90, 100}, {365, 50}, {0, 20},
+		},
+		CategorySeries: {
+			{1, 500}, {3, 400}, {7, 280}, {30, 120}, {90, 40}, {0, 0},
+		},
+		CategoryMovie: {
+			{7, 300}, {30, 250}, {365, 200}, {0, 150},
+		},
+		CategorySoftware: {
+			{7, 400}, {30, 300}, {90, 200}, {365, 80}, {0, 20},
+		},
+		CategoryEbook: {
+			{30, 200}, {0, 150},
+		},
+	},
+}
+
+// 分类识别规则，按顺序匹配标题和标签
+var categoryPatterns = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{CategorySeries, regexp.MustCompile(`(?i)更新至|更至|全\d+集|第\d+集|连载|剧集|电视剧|美剧|韩剧|日剧|国产剧|番剧|动漫|综艺|\bS\d{1,2}E\d{1,3}\b|\bEP?\d{2,3}\b`)},
+	{CategoryEbook, regexp.MustCompile(`(?i)电子书|小说|教材|\b(epub|mobi|azw3|pdf)\b`)},
+	{CategorySoftware, regexp.MustCompile(`(?i)软件|破解|绿色版|安装包|激活|\b(apk|exe|dmg|windows|macos|android)\b|v\d+\.\d+`)},
+	{CategoryMovie, regexp.MustCompile(`(?i)电影|影片|蓝光|原盘|\b(bluray|blu-ray|remux|web-dl|2160p|1080p|4k)\b`)},
+}
+
+var (
+	rankingProfile     *RankingProfile
+	rankingProfileOnce sync.Once
+)
+
+// GetRankingProfile 获取排序配置：RANKING_PROFILE_FILE中配置的分类覆盖内置曲线
+func GetRankingProfile() *RankingProfile {
+	rankingProfileOnce.Do(func() {
+		profile := RankingProfile{TimeDecay: make(map[string][]DecayStep)}
+		for category, curve := range defaultRankingProfile.TimeDecay {
+			profile.TimeDecay[category] = curve
+		}
+		if config.AppConfig != nil && config.AppConfig.RankingProfileFile != "" {
+			loaded, err := loadRankingProfile(config.AppConfig.RankingProfileFile)
+			if err != nil {
+				fmt.Printf("[排序配置] 加载失败，使用内置配置: %s | 错误: %v\n", config.AppConfig.RankingProfileFile, err)
+			} else {
+				for category, curve := range loaded.TimeDecay {
+					profile.TimeDecay[strings.ToLower(category)] = normalizeDecayCurve(curve)
+				}
+			}
+		}
+		rankingProfile = &profile
+	})
+	return rankingProfile
+}
+
+// loadRankingProfile 读取JSON格式的排序配置
+func loadRankingProfile(file string) (*RankingProfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var profile RankingProfile
+	if err := jsonutil.Unmarshal(data, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// normalizeDecayCurve 按天数升序排列曲线，兜底档（MaxDays<=0）放在最后
+func normalizeDecayCurve(curve []DecayStep) []DecayStep {
+	sorted := append([]DecayStep(nil), curve...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MaxDays <= 0 || sorted[j].MaxDays <= 0 {
+			return sorted[j].MaxDays <= 0 && sorted[i].MaxDays > 0
+		}
+		return sorted[i].MaxDays < sorted[j].MaxDays
+	})
+	return sorted
+}
+
+// detectContentCategory 根据标题和标签识别结果的内容分类
+func detectContentCategory(result model.SearchResult) string {
+	text := result.Title
+	if len(result.Tags) > 0 {
+		text += " " + strings.Join(result.Tags, " ")
+	}
+	for _, rule := range categoryPatterns {
+		if rule.pattern.MatchString(text) {
+			return rule.category
+		}
+	}
+	return CategoryDefault
+}
+
+// TimeScore 按结果分类的衰减曲线计算时间得分，无时间信息得0分
+func (p *RankingProfile) TimeScore(result model.SearchResult) float64 {
+	if result.Datetime.IsZero() {
+		return 0
+	}
+	curve, ok := p.TimeDecay[detectContentCategory(result)]
+	if !ok {
+		curve = p.TimeDecay[CategoryDefault]
+	}
+	daysDiff := time.Since(result.Datetime).Hours() / 24
+	for _, step := range curve {
+		if step.MaxDays <= 0 || daysDiff <= step.MaxDays {
+			return step.Score
+		}
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package testsupport
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"pansou/model"
+	"pansou/plugin"
+)
+
+// 模拟插件结果的时间基准，结果时间固定，便于断言排序
+var fakeResultBaseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FakePluginOptions 模拟插件的行为
+type FakePluginOptions struct {
+	Priority         int           // 插件等级，默认3
+	Latency          time.Duration // 每次搜索的耗时，超过插件超时时间可覆盖异步更新流程
+	Err              error         // 非nil时每次搜索都返回该错误
+	FailEvery        int           // 大于0时每N次搜索失败一次
+	ResultsPerSearch int           // 每次搜索返回的结果数，默认5
+	// Results 自定义结果，call为从1开始的搜索次数，设置后忽略ResultsPerSearch
+	Results func(keyword string, call int) []model.SearchResult
+}
+
+// FakePlugin 返回确定结果的模拟插件，经过BaseAsyncPlugin的缓存和异步更新逻辑，与真实插件的调用路径一致
+type FakePlugin struct {
+	*plugin.BaseAsyncPlugin
+	opts  FakePluginOptions
+	calls int64
+}
+
+// NewFakePlugin 创建模拟插件，需要时由调用方注册到插件管理器或全局注册表
+func NewFakePlugin(name string, opts FakePluginOptions) *FakePlugin {
+	if opts.Priority <= 0 {
+		opts.Priority = 3
+	}
+	if opts.ResultsPerSearch <= 0 {
+		opts.ResultsPerSearch = 5
+	}
+	return &FakePlugin{
+		BaseAsyncPlugin: plugin.NewBaseAsyncPlugin(name, opts.Priority),
+		opts:            opts,
+	}
+}
+
+// Search 执行搜索并返回结果（兼容性方法）
+func (p *FakePlugin) Search(keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
+	result, err := p.SearchWithResult(keyword, ext)
+	if err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+// SearchWithResult 执行搜索并返回包含IsFinal标记的结果
+func (p *FakePlugin) SearchWithResult(keyword string, ext map[string]interface{}) (model.PluginSearchResult, error) {
+	return p.AsyncSearchWithResult(keyword, p.doSearch, p.MainCacheKey, ext)
+}
+
+// Calls 实际执行搜索的次数（命中插件缓存的请求不计入）
+func (p *FakePlugin) Calls() int {
+	return int(atomic.LoadInt64(&p.calls))
+}
+
+// doSearch 按配置的延迟和错误返回确定的结果
+func (p *FakePlugin) doSearch(client *http.Client, keyword string, ext map[string]interface{}) ([]model.SearchResult, error) {
+	call := int(atomic.AddInt64(&p.calls, 1))
+	if p.opts.Latency > 0 {
+		time.Sleep(p.opts.Latency)
+	}
+	if p.opts.Err != nil {
+		return nil, p.opts.Err
+	}
+	if p.opts.FailEvery > 0 && call%p.opts.FailEvery == 0 {
+		return nil, fmt.Errorf("模拟插件 %s 第%d次搜索失败", p.Name(), call)
+	}
+	if p.opts.Results != nil {
+		return p.opts.Results(keyword, call), nil
+	}
+	return FakeResults(p.Name(), keyword, p.opts.ResultsPerSearch), nil
+}
+
+// FakeResults 生成确定的插件结果：标题包含关键词，每条结果一个夸克链接，时间依次早一天
+func FakeResults(pluginName string, keyword string, n int) []model.SearchResult {
+	results := make([]model.SearchResult, 0, n)
+	for i := 0; i < n; i++ {
+		hash := mockHash(pluginName, keyword, fmt.Sprint(i))
+		results = append(results, model.SearchResult{
+			UniqueID: fmt.Sprintf("%s-%s", pluginName, hash[:12]),
+			Datetime: fakeResultBaseTime.AddDate(0, 0, -i),
+			Title:    fmt.Sprintf("%s %d", keyword, i+1),
+			Content:  fmt.Sprintf("%s 模拟结果 %d", keyword, i+1),
+			Links: []model.Link{
+				{Type: "quark", URL: "https://pan.quark.cn/s/" + hash[:12]},
+			},
+		})
+	}
+	return results
+}
@@ -0,0 +1,196 @@
+// Package testsupport 提供集成测试和压测使用的模拟后端：返回确定结果的模拟插件，
+// 以及输出t.me频道搜索页HTML的模拟频道服务，无需访问真实站点即可覆盖搜索、缓存和异步更新流程
+package testsupport
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// 模拟频道的第一条消息ID，分页时消息ID依次递减
+const mockFirstMessageID = 100000
+
+// TGServerOptions 模拟频道服务的参数
+type TGServerOptions struct {
+	Latency time.Duration    // 每次响应前的延迟，模拟网络耗时
+	PerPage int              // 每页消息数，默认20
+	Pages   int              // 每个关键词的总页数，默认1
+	Now     func() time.Time // 消息时间的基准，默认time.Now
+}
+
+// TGHandler 模拟t.me频道搜索页（/s/<频道>?q=<关键词>&before=<消息ID>），
+// 按频道和关键词生成确定的消息，并记录每个频道的请求次数
+type TGHandler struct {
+	opts     TGServerOptions
+	mu       sync.Mutex
+	requests map[string]int
+	statuses map[string]int
+}
+
+// NewTGHandler 创建模拟频道处理器
+func NewTGHandler(opts TGServerOptions) *TGHandler {
+	if opts.PerPage <= 0 {
+		opts.PerPage = 20
+	}
+	if opts.Pages <= 0 {
+		opts.Pages = 1
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &TGHandler{
+		opts:     opts,
+		requests: make(map[string]int),
+		statuses: make(map[string]int),
+	}
+}
+
+// ServeHTTP 输出频道搜索结果页
+func (h *TGHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	channel := strings.Trim(strings.TrimPrefix(r.URL.Path, "/s/"), "/")
+	if channel == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.mu.Lock()
+	h.requests[channel]++
+	status := h.statuses[channel]
+	h.mu.Unlock()
+
+	if h.opts.Latency > 0 {
+		time.Sleep(h.opts.Latency)
+	}
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	page := 0
+	if before, err := strconv.Atoi(r.URL.Query().Get("before")); err == nil {
+		page = (mockFirstMessageID - before) / h.opts.PerPage
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(RenderChannelPage(channel, r.URL.Query().Get("q"), page, h.opts.PerPage, h.opts.Pages, h.opts.Now())))
+}
+
+// SetStatus 让频道的请求返回指定状态码（如500），status为0时恢复正常
+func (h *TGHandler) SetStatus(channel string, status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.statuses[channel] = status
+}
+
+// Requests 频道收到的请求次数（含分页请求）
+func (h *TGHandler) Requests(channel string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests[channel]
+}
+
+// TotalRequests 所有频道收到的请求次数
+func (h *TGHandler) TotalRequests() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	total := 0
+	for _, n := range h.requests {
+		total += n
+	}
+	return total
+}
+
+// TGServer 基于httptest的模拟频道服务
+type TGServer struct {
+	*httptest.Server
+	Handler *TGHandler
+}
+
+// NewTGServer 启动模拟频道服务，使用完毕后调用Close
+func NewTGServer(opts TGServerOptions) *TGServer {
+	handler := NewTGHandler(opts)
+	return &TGServer{Server: httptest.NewServer(handler), Handler: handler}
+}
+
+// BaseURL 频道搜索页地址前缀，可作为TG_BASE_URL
+func (s *TGServer) BaseURL() string {
+	return s.URL + "/s/"
+}
+
+// Install 将config.AppConfig.TGBaseURL指向模拟服务，返回恢复原配置的函数。需先调用config.Init
+func (s *TGServer) Install() func() {
+	previous := config.AppConfig.TGBaseURL
+	config.AppConfig.TGBaseURL = s.BaseURL()
+	return func() {
+		config.AppConfig.TGBaseURL = previous
+	}
+}
+
+// RenderChannelPage 生成频道搜索结果页的HTML，结构与t.me一致。每条消息包含一个夸克链接和一个带提取码的百度链接，
+// 夸克链接只由关键词和消息序号决定，不同频道会返回相同链接，用于覆盖去重逻辑
+func RenderChannelPage(channel string, keyword string, page int, perPage int, pages int, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><section class="tgme_channel_history js-message_history">`)
+	if page < 0 || page >= pages {
+		sb.WriteString(`</section></body></html>`)
+		return sb.String()
+	}
+
+	escapedKeyword := html.EscapeString(keyword)
+	lastID := 0
+	for i := 0; i < perPage; i++ {
+		seq := page*perPage + i
+		id := mockFirstMessageID - seq
+		lastID = id
+		shared := mockHash(keyword, strconv.Itoa(seq))
+		own := mockHash(channel, keyword, strconv.Itoa(seq))
+		datetime := now.Add(-time.Duration(seq) * 6 * time.Hour).UTC().Format(time.RFC3339)
+
+		fmt.Fprintf(&sb, `<div class="tgme_widget_message_wrap js-widget_message_wrap">`+
+			`<div class="tgme_widget_message js-widget_message" data-post="%s/%d">`+
+			`<div class="tgme_widget_message_bubble">`+
+			`<div class="tgme_widget_message_text js-message_text" dir="auto">`+
+			`名称：%s 第%d季 4K<br/>`+
+			`夸克：<a href="https://pan.quark.cn/s/%s">https://pan.quark.cn/s/%s</a><br/>`+
+			`名称：%s 合集 %d<br/>`+
+			`百度：<a href="https://pan.baidu.com/s/1%s?pwd=%s">https://pan.baidu.com/s/1%s?pwd=%s</a><br/>`+
+			`<a href="?q=%%23%s">#%s</a>`+
+			`</div>`+
+			`<div class="tgme_widget_message_footer"><div class="tgme_widget_message_info">`+
+			`<a class="tgme_widget_message_date" href="https://t.me/%s/%d"><time datetime="%s" class="time"></time></a>`+
+			`</div></div></div></div></div>`,
+			channel, id,
+			escapedKeyword, seq%9+1,
+			shared[:12], shared[:12],
+			escapedKeyword, seq,
+			own[:16], own[:4], own[:16], own[:4],
+			escapedKeyword, escapedKeyword,
+			channel, id, datetime)
+	}
+	sb.WriteString(`</section>`)
+	if page+1 < pages {
+		fmt.Fprintf(&sb, `<a class="tme_messages_more js-messages_more" data-before="%d" href="/s/%s?q=%s&before=%d"></a>`,
+			lastID, channel, escapedKeyword, lastID)
+	}
+	sb.WriteString(`</body></html>`)
+	return sb.String()
+}
+
+// mockHash 生成确定的十六进制字符串
+func mockHash(parts ...string) string {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	sum := h.Sum64()
+	return fmt.Sprintf("%016x%016x", sum, sum*0x9e3779b97f4a7c15)
+}
@@ -0,0 +1,338 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Level 告警级别
+type Level int
+
+const (
+	// LevelInfo 提示
+	LevelInfo Level = iota
+	// LevelWarning 警告
+	LevelWarning
+	// LevelCritical 严重
+	LevelCritical
+)
+
+// String 返回告警级别名称
+func (l Level) String() string {
+	switch l {
+	case LevelCritical:
+		return "critical"
+	case LevelWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// ParseLevel 解析告警级别名称，无法识别时返回默认值
+func ParseLevel(s string, defaultLevel Level) Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical":
+		return LevelCritical
+	case "warning", "warn":
+		return LevelWarning
+	case "info":
+		return LevelInfo
+	default:
+		return defaultLevel
+	}
+}
+
+// Status 告警状态
+type Status string
+
+const (
+	// StatusFiring 告警中
+	StatusFiring Status = "firing"
+	// StatusAcknowledged 已确认（不再重复通知，直到恢复或升级）
+	StatusAcknowledged Status = "acknowledged"
+	// StatusResolved 已恢复
+	StatusResolved Status = "resolved"
+)
+
+// Alert 告警记录
+type Alert struct {
+	ID         string    `json:"id"`
+	Key        string    `json:"key"` // 去重键，同一键的告警在恢复前只保留一条
+	Level      string    `json:"level"`
+	Source     string    `json:"source"` // 告警来源，如 cache.buffer
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	Status     Status    `json:"status"`
+	Count      int       `json:"count"` // 触发次数
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	AckedBy    string    `json:"acked_by,omitempty"`
+	AckedAt    time.Time `json:"acked_at,omitempty"`
+	ResolvedBy string    `json:"resolved_by,omitempty"`
+	ResolvedAt time.Time `json:"resolved_at,omitempty"`
+
+	level Level
+}
+
+// Sink 告警投递通道
+type Sink interface {
+	// Name 返回通道名称
+	Name() string
+
+	// Send 投递告警，text为按模板渲染后的文本
+	Send(a Alert, text string) error
+}
+
+// route 告警路由：达到最低级别的告警才会投递到对应通道
+type route struct {
+	sink     Sink
+	minLevel Level
+}
+
+// DefaultTemplate 默认告警文本模板
+const DefaultTemplate = `[PanSou告警][{{.Level}}] {{.Title}}
+{{.Message}}
+来源: {{.Source}} | 状态: {{.Status}} | 次数: {{.Count}} | 时间: {{.LastSeen.Format "2006-01-02 15:04:05"}}`
+
+// 已恢复告警最多保留条数
+const maxResolvedAlerts = 200
+
+// Manager 告警管理器
+type Manager struct {
+	mu       sync.RWMutex
+	alerts   map[string]*Alert // ID -> 告警
+	active   map[string]string // Key -> 未恢复告警的ID
+	routes   []route
+	tmpl     *template.Template
+	sequence int64
+}
+
+// NewManager 创建告警管理器
+func NewManager() *Manager {
+	return &Manager{
+		alerts: make(map[string]*Alert),
+		active: make(map[string]string),
+		tmpl:   template.Must(template.New("alert").Parse(DefaultTemplate)),
+	}
+}
+
+// 全局告警管理器
+var globalManager = NewManager()
+
+// GetGlobalManager 获取全局告警管理器
+func GetGlobalManager() *Manager {
+	return globalManager
+}
+
+// AddSink 添加投递通道，只有级别不低于minLevel的告警会投递到该通道
+func (m *Manager) AddSink(sink Sink, minLevel Level) {
+	if sink == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, route{sink: sink, minLevel: minLevel})
+}
+
+// SinkNames 返回已配置的投递通道及其最低级别
+func (m *Manager) SinkNames() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make(map[string]string, len(m.routes))
+	for _, r := range m.routes {
+		names[r.sink.Name()] = r.minLevel.String()
+	}
+	return names
+}
+
+// SetTemplate 设置告警文本模板（Go text/template语法，字段同Alert）
+func (m *Manager) SetTemplate(text string) error {
+	tmpl, err := template.New("alert").Parse(text)
+	if err != nil {
+		return fmt.Errorf("告警模板解析失败: %v", err)
+	}
+	m.mu.Lock()
+	m.tmpl = tmpl
+	m.mu.Unlock()
+	return nil
+}
+
+// Fire 触发告警。同一key的告警在恢复前合并计数，
+// 仅在首次触发或级别升级时投递通知；已确认的告警在级别升级前不再通知
+func (m *Manager) Fire(key string, level Level, source, title, message string) {
+	now := time.Now()
+
+	m.mu.Lock()
+	var notify bool
+	var a *Alert
+	if id, ok := m.active[key]; ok {
+		a = m.alerts[id]
+		a.Count++
+		a.LastSeen = now
+		a.Message = message
+		if level > a.level {
+			// 级别升级，重新通知
+			a.level = level
+			a.Level = level.String()
+			a.Title = title
+			a.Status = StatusFiring
+			notify = true
+		}
+	} else {
+		m.sequence++
+		a = &Alert{
+			ID:        fmt.Sprintf("%d-%d", now.Unix(), m.sequence),
+			Key:       key,
+			Level:     level.String(),
+			Source:    source,
+			Title:     title,
+			Message:   message,
+			Status:    StatusFiring,
+			Count:     1,
+			FirstSeen: now,
+			LastSeen:  now,
+			level:     level,
+		}
+		m.alerts[a.ID] = a
+		m.active[key] = a.ID
+		notify = true
+	}
+	snapshot := *a
+	m.mu.Unlock()
+
+	if notify {
+		m.dispatch(snapshot)
+	}
+}
+
+// Resolve 按key自动恢复告警（由监控方在指标恢复正常时调用）
+func (m *Manager) Resolve(key string) {
+	m.mu.Lock()
+	id, ok := m.active[key]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	a := m.alerts[id]
+	m.markResolved(a, "system")
+	snapshot := *a
+	m.mu.Unlock()
+
+	m.dispatch(snapshot)
+}
+
+// Ack 确认告警
+func (m *Manager) Ack(id, by string) (Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.alerts[id]
+	if !ok {
+		return Alert{}, fmt.Errorf("告警不存在: %s", id)
+	}
+	if a.Status == StatusResolved {
+		return *a, fmt.Errorf("告警已恢复: %s", id)
+	}
+	a.Status = StatusAcknowledged
+	a.AckedBy = by
+	a.AckedAt = time.Now()
+	return *a, nil
+}
+
+// ResolveByID 手动恢复告警
+func (m *Manager) ResolveByID(id, by string) (Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.alerts[id]
+	if !ok {
+		return Alert{}, fmt.Errorf("告警不存在: %s", id)
+	}
+	if a.Status != StatusResolved {
+		m.markResolved(a, by)
+	}
+	return *a, nil
+}
+
+// markResolved 标记告警为已恢复（调用方需持有锁）
+func (m *Manager) markResolved(a *Alert, by string) {
+	a.Status = StatusResolved
+	a.ResolvedBy = by
+	a.ResolvedAt = time.Now()
+	if m.active[a.Key] == a.ID {
+		delete(m.active, a.Key)
+	}
+	m.pruneResolved()
+}
+
+// pruneResolved 清理过多的已恢复告警（调用方需持有锁）
+func (m *Manager) pruneResolved() {
+	resolved := make([]*Alert, 0)
+	for _, a := range m.alerts {
+		if a.Status == StatusResolved {
+			resolved = append(resolved, a)
+		}
+	}
+	if len(resolved) <= maxResolvedAlerts {
+		return
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].ResolvedAt.Before(resolved[j].ResolvedAt)
+	})
+	for _, a := range resolved[:len(resolved)-maxResolvedAlerts] {
+		delete(m.alerts, a.ID)
+	}
+}
+
+// List 列出告警，includeResolved为false时只返回未恢复的告警，按最近触发时间倒序
+func (m *Manager) List(includeResolved bool) []Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]Alert, 0, len(m.alerts))
+	for _, a := range m.alerts {
+		if !includeResolved && a.Status == StatusResolved {
+			continue
+		}
+		list = append(list, *a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list
+}
+
+// dispatch 渲染模板并异步投递到匹配级别的通道，同时保留标准输出日志
+func (m *Manager) dispatch(a Alert) {
+	m.mu.RLock()
+	tmpl := m.tmpl
+	routes := make([]route, len(m.routes))
+	copy(routes, m.routes)
+	m.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, a); err != nil {
+		buf.Reset()
+		fmt.Fprintf(&buf, "[PanSou告警][%s] %s\n%s", a.Level, a.Title, a.Message)
+	}
+	text := buf.String()
+
+	fmt.Printf("[告警][%s][%s] %s: %s\n", a.Level, a.Status, a.Title, a.Message)
+
+	for _, r := range routes {
+		if a.level < r.minLevel {
+			continue
+		}
+		go func(sink Sink) {
+			if err := sink.Send(a, text); err != nil {
+				fmt.Printf("[告警] 通道 %s 投递失败: %v\n", sink.Name(), err)
+			}
+		}(r.sink)
+	}
+}
@@ -0,0 +1,168 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	jsonutil "pansou/util/json"
+)
+
+// 投递通道HTTP超时
+const sinkTimeout = 10 * time.Second
+
+// WebhookSink 通用Webhook通道，以JSON格式POST告警
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookSink 创建Webhook通道
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+// Name 返回通道名称
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Send 投递告警
+func (s *WebhookSink) Send(a Alert, text string) error {
+	body, err := jsonutil.Marshal(map[string]interface{}{
+		"alert": a,
+		"text":  text,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook返回状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// TelegramSink Telegram机器人通道
+type TelegramSink struct {
+	Token  string
+	ChatID string
+	client *http.Client
+}
+
+// NewTelegramSink 创建Telegram机器人通道
+func NewTelegramSink(token, chatID string) *TelegramSink {
+	return &TelegramSink{Token: token, ChatID: chatID, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+// Name 返回通道名称
+func (s *TelegramSink) Name() string {
+	return "telegram"
+}
+
+// Send 投递告警
+func (s *TelegramSink) Send(a Alert, text string) error {
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.Token)
+	resp, err := s.client.PostForm(apiURL, url.Values{
+		"chat_id": {s.ChatID},
+		"text":    {text},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram返回状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPSink 邮件通道
+type SMTPSink struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name 返回通道名称
+func (s *SMTPSink) Name() string {
+	return "smtp"
+}
+
+// Send 投递告警
+func (s *SMTPSink) Send(a Alert, text string) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host := s.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	subject := fmt.Sprintf("[PanSou告警][%s] %s", a.Level, a.Title)
+	msg := "From: " + s.From + "\r\n" +
+		"To: " + strings.Join(s.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		text + "\r\n"
+	return smtp.SendMail(s.Addr, auth, s.From, s.To, []byte(msg))
+}
+
+// InitFromEnvironment 根据环境变量为全局告警管理器配置投递通道和模板
+//
+//	ALERT_WEBHOOK_URL / ALERT_WEBHOOK_LEVEL
+//	ALERT_TELEGRAM_TOKEN / ALERT_TELEGRAM_CHAT_ID / ALERT_TELEGRAM_LEVEL
+//	ALERT_SMTP_ADDR / ALERT_SMTP_USER / ALERT_SMTP_PASSWORD / ALERT_SMTP_FROM / ALERT_SMTP_TO / ALERT_SMTP_LEVEL
+//	ALERT_TEMPLATE
+func InitFromEnvironment() {
+	m := GetGlobalManager()
+
+	if u := os.Getenv("ALERT_WEBHOOK_URL"); u != "" {
+		m.AddSink(NewWebhookSink(u), ParseLevel(os.Getenv("ALERT_WEBHOOK_LEVEL"), LevelWarning))
+	}
+
+	token, chatID := os.Getenv("ALERT_TELEGRAM_TOKEN"), os.Getenv("ALERT_TELEGRAM_CHAT_ID")
+	if token != "" && chatID != "" {
+		m.AddSink(NewTelegramSink(token, chatID), ParseLevel(os.Getenv("ALERT_TELEGRAM_LEVEL"), LevelCritical))
+	}
+
+	if addr := os.Getenv("ALERT_SMTP_ADDR"); addr != "" {
+		var to []string
+		for _, r := range strings.Split(os.Getenv("ALERT_SMTP_TO"), ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				to = append(to, r)
+			}
+		}
+		if len(to) > 0 {
+			m.AddSink(&SMTPSink{
+				Addr:     addr,
+				Username: os.Getenv("ALERT_SMTP_USER"),
+				Password: os.Getenv("ALERT_SMTP_PASSWORD"),
+				From:     os.Getenv("ALERT_SMTP_FROM"),
+				To:       to,
+			}, ParseLevel(os.Getenv("ALERT_SMTP_LEVEL"), LevelCritical))
+		}
+	}
+
+	if tmpl := os.Getenv("ALERT_TEMPLATE"); tmpl != "" {
+		if err := m.SetTemplate(tmpl); err != nil {
+			fmt.Printf("[告警] %v，使用默认模板\n", err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// 文件存储写入缓存项后的fsync策略
+const (
+	FsyncAlways   = "always"   // 每次写入后立即同步数据文件和目录，最安全但写入最慢
+	FsyncInterval = "interval" // 按CACHE_FSYNC_INTERVAL批量同步最近写入的文件
+	FsyncNever    = "never"    // 不主动同步，由操作系统决定何时落盘
+)
+
+// 写入过程中的临时文件前缀，加载时清理进程崩溃遗留的临时文件
+const atomicTempPrefix = ".tmp-"
+
+// 缓存项校验和使用的CRC32表
+var entryChecksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// entryChecksum 计算缓存数据的校验和
+func entryChecksum(data []byte) uint32 {
+	return crc32.Checksum(data, entryChecksumTable)
+}
+
+// 被隔离的分片目录名中的标记
+const quarantineMarker = ".corrupt-"
+
+// IsAtomicTempFile 是否为写入过程中的临时文件
+func IsAtomicTempFile(name string) bool {
+	return strings.HasPrefix(name, atomicTempPrefix)
+}
+
+// IsQuarantinedDir 是否为因损坏被隔离的分片目录
+func IsQuarantinedDir(name string) bool {
+	return strings.Contains(name, quarantineMarker)
+}
+
+// fsyncPolicy 获取当前的fsync策略和批量同步间隔，未初始化配置时不同步
+func fsyncPolicy() (string, time.Duration) {
+	if config.AppConfig == nil {
+		return FsyncNever, 0
+	}
+	return config.AppConfig.CacheFsync, config.AppConfig.CacheFsyncInterval
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名为path，进程崩溃时path要么是旧内容要么是完整的新内容。
+// durable为true时按fsync策略同步到磁盘，用于缓存数据和过期时间等丢失后会导致错误的内容
+func writeFileAtomic(path string, data []byte, durable bool) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, atomicTempPrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	policy, _ := fsyncPolicy()
+	syncNow := durable && policy == FsyncAlways
+	_, err = tmp.Write(data)
+	if err == nil && syncNow {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	switch {
+	case syncNow:
+		// 同步目录，使重命名本身落盘
+		syncDir(dir)
+	case durable && policy == FsyncInterval:
+		scheduleFsync(path)
+	}
+	return nil
+}
+
+// syncDir 同步目录项
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+var (
+	// 等待批量同步的文件
+	pendingFsync     = make(map[string]struct{})
+	pendingFsyncLock sync.Mutex
+	fsyncLoopOnce    sync.Once
+)
+
+// scheduleFsync 将文件加入下一次批量同步
+func scheduleFsync(path string) {
+	pendingFsyncLock.Lock()
+	pendingFsync[path] = struct{}{}
+	pendingFsyncLock.Unlock()
+	fsyncLoopOnce.Do(func() {
+		go fsyncLoop()
+	})
+}
+
+// fsyncLoop 定期同步最近写入的文件及其所在目录
+func fsyncLoop() {
+	for {
+		_, interval := fsyncPolicy()
+		if interval <= 0 {
+			interval = time.Second
+		}
+		time.Sleep(interval)
+		flushPendingFsync()
+	}
+}
+
+// flushPendingFsync 同步等待中的文件，已被删除或替换的文件忽略
+func flushPendingFsync() {
+	pendingFsyncLock.Lock()
+	paths := pendingFsync
+	pendingFsync = make(map[string]struct{})
+	pendingFsyncLock.Unlock()
+	if len(paths) == 0 {
+		return
+	}
+
+	dirs := make(map[string]struct{})
+	for path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		if err := f.Sync(); err != nil {
+			fmt.Printf("[磁盘缓存] 同步文件失败: %s | 错误: %v\n", path, err)
+		}
+		f.Close()
+		dirs[filepath.Dir(path)] = struct{}{}
+	}
+	for dir := range dirs {
+		syncDir(dir)
+	}
+}
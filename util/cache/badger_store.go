@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"os"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+// 单次清理最多回收的值日志文件数
+const badgerMaxGCRounds = 8
+
+// badgerBackend badger数据库后端
+type badgerBackend struct {
+	db  *badger.DB
+	dir string
+}
+
+// NewBadgerStore 创建基于badger的存储，适合写入频繁、缓存量较大的场景
+func NewBadgerStore(dir string, maxSizeMB int) (Store, error) {
+	return newKVStore(StoreBadger, func() (kvBackend, error) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		// 缓存数据可以丢失，使用较小的内存表并关闭badger自身的日志
+		opts := badger.DefaultOptions(dir).
+			WithLogger(nil).
+			WithMemTableSize(16 << 20).
+			WithValueLogFileSize(64 << 20).
+			WithNumVersionsToKeep(1)
+		db, err := badger.Open(opts)
+		if err != nil {
+			return nil, err
+		}
+		return &badgerBackend{db: db, dir: dir}, nil
+	}, maxSizeMB)
+}
+
+func (b *badgerBackend) put(key string, value []byte, ttl time.Duration) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		// 同时设置badger的TTL，过期数据在压缩时被回收
+		return txn.SetEntry(badger.NewEntry([]byte(key), value).WithTTL(ttl))
+	})
+}
+
+func (b *badgerBackend) get(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return value, err
+}
+
+func (b *badgerBackend) delete(keys []string) error {
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		if err := batch.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+func (b *badgerBackend) iterate(fn func(key string, value []byte)) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			err := item.Value(func(v []byte) error {
+				fn(string(item.Key()), v)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *badgerBackend) clear() error {
+	return b.db.DropAll()
+}
+
+// gc 回收值日志中已删除或过期数据占用的空间
+func (b *badgerBackend) gc() {
+	for i := 0; i < badgerMaxGCRounds; i++ {
+		if b.db.RunValueLogGC(0.5) != nil {
+			return
+		}
+	}
+}
+
+func (b *badgerBackend) size() int64 {
+	return treeSize(b.dir)
+}
+
+func (b *badgerBackend) close() error {
+	return b.db.Close()
+}
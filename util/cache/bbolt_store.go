@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// 缓存项所在的bucket
+var bboltBucket = []byte("cache")
+
+// 等待其他进程释放数据库文件锁的时间
+const bboltOpenTimeout = 10 * time.Second
+
+// bboltBackend bbolt数据库后端
+type bboltBackend struct {
+	db *bolt.DB
+}
+
+// NewBboltStore 创建基于bbolt的存储，所有缓存项保存在单个数据库文件中
+func NewBboltStore(path string, maxSizeMB int) (Store, error) {
+	return newKVStore(StoreBbolt, func() (kvBackend, error) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: bboltOpenTimeout, NoFreelistSync: true})
+		if err != nil {
+			return nil, err
+		}
+		err = db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(bboltBucket)
+			return err
+		})
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &bboltBackend{db: db}, nil
+	}, maxSizeMB)
+}
+
+func (b *bboltBackend) put(key string, value []byte, ttl time.Duration) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bboltBucket).Put([]byte(key), value)
+	})
+}
+
+func (b *bboltBackend) get(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// bbolt返回的数据只在事务内有效，需要复制
+		if v := tx.Bucket(bboltBucket).Get([]byte(key)); v != nil {
+			value = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return value, err
+}
+
+func (b *bboltBackend) delete(keys []string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bboltBucket)
+		for _, key := range keys {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *bboltBackend) iterate(fn func(key string, value []byte)) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bboltBucket).ForEach(func(k, v []byte) error {
+			fn(string(k), v)
+			return nil
+		})
+	})
+}
+
+func (b *bboltBackend) clear() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bboltBucket); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err := tx.CreateBucket(bboltBucket)
+		return err
+	})
+}
+
+// gc bbolt删除的页面由空闲列表复用，无需单独回收
+func (b *bboltBackend) gc() {}
+
+func (b *bboltBackend) size() int64 {
+	info, err := os.Stat(b.db.Path())
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (b *bboltBackend) close() error {
+	return b.db.Close()
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	
 	"pansou/plugin"
 )
@@ -23,8 +24,29 @@ var (
 	allPluginsHash string
 	// 所有频道名称的哈希值
 	allChannelsHash string
+	
+	// 未指定插件时实际使用的插件集合指纹，见SetPluginSetFingerprint
+	pluginSetFingerprint atomic.Value // func() string
 )
 
+// SetPluginSetFingerprint 设置未指定插件时实际使用的插件集合的指纹函数（如插件管理器的Fingerprint）。
+// 指纹参与未指定插件的缓存键，修改ENABLED_PLUGINS后重启或运行时启用/停用插件后，按其他插件集合生成的缓存不再命中
+func SetPluginSetFingerprint(fingerprint func() string) {
+	pluginSetFingerprint.Store(fingerprint)
+}
+
+// defaultPluginsHash 未指定插件时的插件哈希：设置了插件集合指纹时附加指纹
+func defaultPluginsHash() string {
+	hash := allPluginsHash
+	if precomputed, ok := precomputedHashes.Load("all_plugins"); ok {
+		hash = precomputed.(string)
+	}
+	if fingerprint, ok := pluginSetFingerprint.Load().(func() string); ok && fingerprint != nil {
+		hash += "@" + fingerprint()
+	}
+	return hash
+}
+
 // 初始化预计算的哈希值
 func init() {
 	// 预计算空列表的哈希值
@@ -60,16 +82,19 @@ func GenerateTGCacheKey(keyword string, channels []string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// GeneratePluginCacheKey 为插件搜索生成缓存键
-func GeneratePluginCacheKey(keyword string, plugins []string) string {
+// GeneratePluginCacheKey 为插件搜索生成缓存键，ext中被所选插件注册的参数参与缓存键
+func GeneratePluginCacheKey(keyword string, plugins []string, ext map[string]interface{}) string {
 	// 关键词标准化
 	normalizedKeyword := strings.ToLower(strings.TrimSpace(keyword))
 	
 	// 获取插件列表哈希
 	pluginsHash := getPluginsHash(plugins)
 	
-	// 生成插件搜索特定的缓存键
+	// 生成插件搜索特定的缓存键，没有相关ext参数时与不带ext的键相同
 	keyStr := fmt.Sprintf("plugin:%s:%s", normalizedKeyword, pluginsHash)
+	if extHash := plugin.ExtCacheHash(ext, nonEmptyNames(plugins)...); extHash != "" {
+		keyStr += ":ext=" + extHash
+	}
 	hash := md5.Sum([]byte(keyStr))
 	return hex.EncodeToString(hash[:])
 }
@@ -147,10 +172,7 @@ func getPluginsHash(plugins []string) string {
 	// 检查是否为空列表
 	if plugins == nil || len(plugins) == 0 {
 		// 使用预计算的所有插件哈希
-		if hash, ok := precomputedHashes.Load("all_plugins"); ok {
-			return hash.(string)
-		}
-		return allPluginsHash
+		return defaultPluginsHash()
 	}
 	
 	// 检查是否有空字符串元素
@@ -164,10 +186,7 @@ func getPluginsHash(plugins []string) string {
 	
 	// 如果全是空字符串，也视为空列表
 	if !hasNonEmptyPlugin {
-		if hash, ok := precomputedHashes.Load("all_plugins"); ok {
-			return hash.(string)
-		}
-		return allPluginsHash
+		return defaultPluginsHash()
 	}
 	
 	// 对于小型列表，直接使用字符串连接
@@ -207,11 +226,23 @@ func getPluginsHash(plugins []string) string {
 	return hash
 }
 
-// 计算列表的哈希值
+// nonEmptyNames 去掉列表中的空字符串
+func nonEmptyNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// 计算列表的哈希值，各项之间加分隔符，避免["ab","c"]和["a","bc"]得到相同的哈希
 func calculateListHash(items []string) string {
 	h := md5.New()
 	for _, item := range items {
 		h.Write([]byte(item))
+		h.Write([]byte{0})
 	}
 	return hex.EncodeToString(h.Sum(nil))
 }
@@ -283,4 +314,13 @@ func GenerateCacheKeyLegacy(query string, filters map[string]string) string {
 	// 计算MD5哈希
 	hash := md5.Sum([]byte(keyStr))
 	return hex.EncodeToString(hash[:])
-} 
\ No newline at end of file
+} 
+
+// NamespaceCacheKey 为缓存键加上命名空间（如租户ID），不同命名空间的缓存互不可见，命名空间为空时返回原键
+func NamespaceCacheKey(namespace string, key string) string {
+	if namespace == "" {
+		return key
+	}
+	hash := md5.Sum([]byte("ns:" + namespace + ":" + key))
+	return hex.EncodeToString(hash[:])
+}
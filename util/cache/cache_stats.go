@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// CacheLevelStats 一级缓存（内存或磁盘）的容量与命中统计
+type CacheLevelStats struct {
+	Entries    int     `json:"entries"`
+	Bytes      int64   `json:"bytes"` // 缓存数据的大小
+	MaxEntries int     `json:"max_entries,omitempty"`
+	MaxBytes   int64   `json:"max_bytes"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"`
+}
+
+// StoreStats 磁盘缓存存储后端的容量统计
+type StoreStats struct {
+	Backend   string            `json:"backend"`
+	Entries   int               `json:"entries"`
+	Bytes     int64             `json:"bytes"` // 缓存数据的大小
+	MaxBytes  int64             `json:"max_bytes"`
+	DiskBytes int64             `json:"disk_bytes"` // 实际占用的磁盘空间，含元数据文件或数据库的额外开销
+	Shards    []StoreShardStats `json:"shards,omitempty"`
+
+	Corrupted   int64 `json:"corrupted,omitempty"`   // 文件存储累计发现并删除的损坏缓存项数
+	Quarantined int   `json:"quarantined,omitempty"` // 文件存储因损坏过多隔离分片的次数
+}
+
+// StoreShardStats 文件存储单个分片的容量统计
+type StoreShardStats struct {
+	Shard     int   `json:"shard"`
+	Entries   int   `json:"entries"`
+	Bytes     int64 `json:"bytes"`
+	MaxBytes  int64 `json:"max_bytes"`
+	DiskBytes int64 `json:"disk_bytes"`
+
+	Corrupted   int64 `json:"corrupted,omitempty"`
+	Quarantined int   `json:"quarantined,omitempty"`
+}
+
+// SerializerStats 序列化器的错误统计
+type SerializerStats struct {
+	SerializeErrors   int64 `json:"serialize_errors"`
+	DeserializeErrors int64 `json:"deserialize_errors"` // 含结构版本不兼容、数据损坏等
+}
+
+// CacheStats 两级缓存的统计
+type CacheStats struct {
+	Memory     CacheLevelStats  `json:"memory"`
+	Disk       CacheLevelStats  `json:"disk"`
+	Store      StoreStats       `json:"store"`
+	Serializer *SerializerStats `json:"serializer,omitempty"` // 使用GobSerializer时提供
+}
+
+// newLevelStats 根据命中和未命中次数计算命中率
+func newLevelStats(hits, misses int64) CacheLevelStats {
+	stats := CacheLevelStats{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		stats.HitRatio = float64(hits) / float64(total)
+	}
+	return stats
+}
+
+// Stats 获取内存缓存、磁盘缓存的容量与命中统计。内存未命中的读取继续查询磁盘，
+// 因此磁盘的查询次数等于内存的未命中次数（跳过内存缓存直接读取磁盘的请求同样计入内存未命中）
+func (c *EnhancedTwoLevelCache) Stats() CacheStats {
+	memHits := atomic.LoadInt64(&c.memoryHits)
+	diskHits := atomic.LoadInt64(&c.diskHits)
+	diskMisses := atomic.LoadInt64(&c.diskMisses)
+
+	stats := CacheStats{
+		Memory: newLevelStats(memHits, diskHits+diskMisses),
+		Disk:   newLevelStats(diskHits, diskMisses),
+		Store:  c.disk.Stats(),
+	}
+	stats.Memory.Entries, stats.Memory.Bytes = c.memory.Size()
+	stats.Memory.MaxEntries = c.memory.maxItems
+	stats.Memory.MaxBytes = c.memory.maxSize
+	stats.Disk.Entries = stats.Store.Entries
+	stats.Disk.Bytes = stats.Store.Bytes
+	stats.Disk.MaxBytes = stats.Store.MaxBytes
+
+	if gob, ok := c.GetSerializer().(*GobSerializer); ok {
+		serializerStats := gob.Stats()
+		stats.Serializer = &serializerStats
+	}
+	return stats
+}
+
+// dirSize 统计目录下文件占用的空间（不递归子目录）
+func dirSize(dir string) int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// treeSize 递归统计目录下所有文件占用的空间
+func treeSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
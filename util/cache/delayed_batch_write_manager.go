@@ -12,7 +12,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"pansou/config"
 	"pansou/model"
+	"pansou/util/alert"
 )
 
 // CacheWriteStrategy 缓存写入策略
@@ -37,6 +39,7 @@ type CacheOperation struct {
 	Priority         int                // 优先级 (1=highest, 4=lowest)
 	DataSize         int                // 数据大小（字节）
 	IsFinal          bool               // 是否为最终结果
+	journalSeq       uint64             // 预写日志中的记录序号，0表示未记录
 }
 
 // CacheWriteConfig 缓存写入配置
@@ -52,6 +55,7 @@ type CacheWriteConfig struct {
 	// 行为参数
 	HighPriorityRatio       float64            `env:"HIGH_PRIORITY_RATIO" default:"0.3"`
 	EnableCompression       bool               // 默认启用操作合并
+	EnableJournal           bool               `env:"CACHE_WRITE_JOURNAL" default:"true"`
 	
 	// 内部计算参数（运行时动态调整）
 	idleThresholdCPU        float64            // CPU空闲阈值
@@ -130,6 +134,13 @@ func (c *CacheWriteConfig) loadFromEnvironment() {
 			c.HighPriorityRatio = r
 		}
 	}
+	
+	// 预写日志
+	if journal := os.Getenv("CACHE_WRITE_JOURNAL"); journal != "" {
+		if enabled, err := strconv.ParseBool(journal); err == nil {
+			c.EnableJournal = enabled
+		}
+	}
 }
 
 // calculateOptimalBatchInterval 计算最优批量间隔
@@ -273,12 +284,25 @@ type DelayedBatchWriteManager struct {
 	// 主缓存更新函数
 	mainCacheUpdater  func(string, []byte, time.Duration) error
 	
+	// 数据写入磁盘后的回调（如触发缓存目录同步到对象存储）
+	flushListener     func()
+	
 	// 序列化器
 	serializer        *GobSerializer
 	
+	// 预写日志（进入缓冲区的操作先记录，崩溃后启动时回放）
+	journal           *writeJournal
+	journalMutex      sync.RWMutex
+	
 	// 初始化标志
 	initialized       int32
 	initMutex         sync.Mutex
+	
+	// 运行时配置锁（保护strategy与config的并发读写）
+	configMutex       sync.RWMutex
+	
+	// 上次告警检查时的失败写入数
+	lastAlertFailedWrites int64
 }
 
 // WriteManagerStats 写入管理器统计信息
@@ -313,6 +337,7 @@ func NewDelayedBatchWriteManager() (*DelayedBatchWriteManager, error) {
 	config := &CacheWriteConfig{
 		Strategy:          CacheStrategyHybrid,
 		EnableCompression: true,
+		EnableJournal:     true,
 	}
 	
 	// 初始化配置
@@ -354,6 +379,14 @@ func (m *DelayedBatchWriteManager) Initialize() error {
 		return fmt.Errorf("全局缓冲区管理器初始化失败: %v", err)
 	}
 	
+	// 打开预写日志，失败时不记录日志继续运行
+	if m.config.EnableJournal {
+		if err := m.openJournal(); err != nil {
+			fmt.Printf("[预写日志] 打开失败，缓冲区数据将不记录日志: %v\n", err)
+		}
+		go m.journalSyncProcessor()
+	}
+	
 	// 启动后台处理goroutine
 	go m.backgroundProcessor()
 	
@@ -376,6 +409,151 @@ func (m *DelayedBatchWriteManager) SetMainCacheUpdater(updater func(string, []by
 	m.mainCacheUpdater = updater
 }
 
+// SetFlushListener 设置数据写入磁盘后的回调，需在处理请求前设置
+func (m *DelayedBatchWriteManager) SetFlushListener(listener func()) {
+	m.flushListener = listener
+}
+
+// notifyFlush 通知数据已写入磁盘
+func (m *DelayedBatchWriteManager) notifyFlush() {
+	if m.flushListener != nil {
+		m.flushListener()
+	}
+}
+
+// openJournal 打开缓存目录下的预写日志，配置了缓存加密时日志记录使用同一密钥加密
+func (m *DelayedBatchWriteManager) openJournal() error {
+	var cipher *CacheCipher
+	if config.AppConfig.CacheEncryptionKey != "" {
+		var err error
+		if cipher, err = NewCacheCipher(config.AppConfig.CacheEncryptionKey, config.AppConfig.CacheEncryptionOldKeys); err != nil {
+			return fmt.Errorf("缓存加密初始化失败: %v", err)
+		}
+	}
+	journal, err := openWriteJournal(config.AppConfig.CachePath, cipher)
+	if err != nil {
+		return err
+	}
+	m.journalMutex.Lock()
+	m.journal = journal
+	m.journalMutex.Unlock()
+	return nil
+}
+
+// currentJournal 获取当前的预写日志，未启用或已关闭时返回nil
+func (m *DelayedBatchWriteManager) currentJournal() *writeJournal {
+	m.journalMutex.RLock()
+	defer m.journalMutex.RUnlock()
+	return m.journal
+}
+
+// appendToJournal 将进入缓冲区的操作追加到预写日志，失败只记录日志
+func (m *DelayedBatchWriteManager) appendToJournal(op *CacheOperation) {
+	journal := m.currentJournal()
+	if journal == nil {
+		return
+	}
+	data, err := m.serializer.Serialize(op.Data)
+	if err != nil {
+		fmt.Printf("[预写日志] 数据序列化失败 %s: %v\n", op.Key, err)
+		return
+	}
+	// 日志同样是持久化存储，过滤后没有需要持久化的数据时不记录
+	if data = filterDiskWrite(op.Key, data); data == nil {
+		return
+	}
+	seq, err := journal.append(op.Key, data, time.Now().Add(op.TTL))
+	if err != nil {
+		fmt.Printf("[预写日志] 记录失败 %s: %v\n", op.Key, err)
+		return
+	}
+	op.journalSeq = seq
+}
+
+// commitJournal 标记操作已写入磁盘
+func (m *DelayedBatchWriteManager) commitJournal(operations []*CacheOperation) {
+	journal := m.currentJournal()
+	if journal == nil || len(operations) == 0 {
+		return
+	}
+	if err := journal.commit(operations); err != nil {
+		fmt.Printf("[预写日志] 清理失败: %v\n", err)
+	}
+}
+
+// journalSyncProcessor 定期将预写日志同步到磁盘
+func (m *DelayedBatchWriteManager) journalSyncProcessor() {
+	ticker := time.NewTicker(writeJournalSyncInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C:
+			if journal := m.currentJournal(); journal != nil {
+				if err := journal.sync(); err != nil {
+					fmt.Printf("[预写日志] 同步失败: %v\n", err)
+				}
+			}
+			
+		case <-m.shutdownChan:
+			return
+		}
+	}
+}
+
+// ReplayJournal 将上次运行时进入缓冲区但未写入磁盘的数据（进程崩溃时丢失的部分）写回主缓存，
+// 每个缓存键只回放最新且未过期的一条，返回回放的条数。需在设置主缓存更新函数之后、处理请求之前调用
+func (m *DelayedBatchWriteManager) ReplayJournal() (int, error) {
+	journal := m.currentJournal()
+	if journal == nil {
+		return 0, nil
+	}
+	if m.mainCacheUpdater == nil {
+		return 0, fmt.Errorf("主缓存更新函数未设置")
+	}
+	replayed, err := journal.replay(m.mainCacheUpdater)
+	if replayed > 0 {
+		m.notifyFlush()
+	}
+	return replayed, err
+}
+
+// CloseJournal 关闭预写日志，之后进入缓冲区的操作不再记录。
+// 平滑升级时在新进程启动前调用，避免两个进程同时写同一个日志
+func (m *DelayedBatchWriteManager) CloseJournal() error {
+	m.journalMutex.Lock()
+	journal := m.journal
+	m.journal = nil
+	m.journalMutex.Unlock()
+	
+	if journal == nil {
+		return nil
+	}
+	return journal.close()
+}
+
+// ReopenJournal 平滑升级失败后重新打开预写日志，并回放新进程遗留的记录
+func (m *DelayedBatchWriteManager) ReopenJournal() error {
+	if !m.config.EnableJournal || atomic.LoadInt32(&m.initialized) == 0 || m.currentJournal() != nil {
+		return nil
+	}
+	if err := m.openJournal(); err != nil {
+		return err
+	}
+	_, err := m.ReplayJournal()
+	return err
+}
+
+// HasMainCacheUpdater 是否已设置主缓存更新函数（启动自检使用）
+func (m *DelayedBatchWriteManager) HasMainCacheUpdater() bool {
+	return m.mainCacheUpdater != nil
+}
+
+// IsInitialized 是否已完成初始化（启动自检使用）
+func (m *DelayedBatchWriteManager) IsInitialized() bool {
+	return atomic.LoadInt32(&m.initialized) == 1
+}
+
 // HandleCacheOperation 处理缓存操作
 func (m *DelayedBatchWriteManager) HandleCacheOperation(op *CacheOperation) error {
 	// 确保管理器已初始化
@@ -389,10 +567,13 @@ func (m *DelayedBatchWriteManager) HandleCacheOperation(op *CacheOperation) erro
 	}
 	
 	// 根据策略处理磁盘写入
-	if m.strategy == CacheStrategyImmediate {
+	if m.currentStrategy() == CacheStrategyImmediate {
 		return m.immediateWriteToDisk(op)
 	}
 	
+	// 进入缓冲区前先记录预写日志，进程崩溃时可在启动时恢复
+	m.appendToJournal(op)
+	
 	// 使用全局缓冲区管理器进行智能缓冲
 	return m.handleWithGlobalBuffer(op)
 }
@@ -465,6 +646,9 @@ func (m *DelayedBatchWriteManager) globalBufferMonitor() {
 			// 检查是否有过期的缓冲区需要刷新
 			m.checkAndFlushExpiredBuffers()
 			
+			// 检查缓冲区与写入状态，必要时触发告警
+			m.checkBufferAlerts()
+			
 		case <-m.shutdownChan:
 			return
 		}
@@ -496,6 +680,53 @@ func (m *DelayedBatchWriteManager) checkAndFlushExpiredBuffers() {
 	}
 }
 
+// checkBufferAlerts 检查写入队列、全局缓冲区和写入失败情况，超过阈值时触发告警，恢复后自动解除
+func (m *DelayedBatchWriteManager) checkBufferAlerts() {
+	alerts := alert.GetGlobalManager()
+	
+	// 写入队列使用率
+	queueSize := int(atomic.LoadInt32(&m.stats.CurrentQueueSize))
+	queueRatio := float64(queueSize) / float64(cap(m.writeQueue))
+	switch {
+	case queueRatio >= 0.95:
+		alerts.Fire("cache.write_queue", alert.LevelCritical, "cache.write",
+			"缓存写入队列即将溢出", fmt.Sprintf("队列使用 %d/%d (%.0f%%)", queueSize, cap(m.writeQueue), queueRatio*100))
+	case queueRatio >= 0.8:
+		alerts.Fire("cache.write_queue", alert.LevelWarning, "cache.write",
+			"缓存写入队列积压", fmt.Sprintf("队列使用 %d/%d (%.0f%%)", queueSize, cap(m.writeQueue), queueRatio*100))
+	default:
+		alerts.Resolve("cache.write_queue")
+	}
+	
+	// 全局缓冲区数量与内存占用
+	bufferStats := m.globalBufferManager.GetStats()
+	maxBuffers := m.globalBufferManager.maxBuffers
+	bufferRatio := float64(bufferStats.ActiveBuffers) / float64(maxBuffers)
+	memoryLimit := int64(m.config.MaxBatchDataSize) * int64(maxBuffers)
+	memoryRatio := float64(bufferStats.MemoryUsage) / float64(memoryLimit)
+	message := fmt.Sprintf("活跃缓冲区 %d/%d，缓冲数据 %.1fMB/%.1fMB",
+		bufferStats.ActiveBuffers, maxBuffers,
+		float64(bufferStats.MemoryUsage)/1024/1024, float64(memoryLimit)/1024/1024)
+	switch {
+	case bufferRatio >= 1 || memoryRatio >= 0.9:
+		alerts.Fire("cache.global_buffer", alert.LevelCritical, "cache.buffer", "全局缓冲区资源耗尽", message)
+	case bufferRatio >= 0.8 || memoryRatio >= 0.7:
+		alerts.Fire("cache.global_buffer", alert.LevelWarning, "cache.buffer", "全局缓冲区压力较高", message)
+	default:
+		alerts.Resolve("cache.global_buffer")
+	}
+	
+	// 写入失败：两次检查之间出现新的失败即告警
+	failed := atomic.LoadInt64(&m.stats.FailedWrites)
+	if delta := failed - m.lastAlertFailedWrites; delta > 0 {
+		alerts.Fire("cache.write_failed", alert.LevelCritical, "cache.write",
+			"缓存批量写入失败", fmt.Sprintf("最近检查周期内失败 %d 次，累计 %d 次", delta, failed))
+	} else {
+		alerts.Resolve("cache.write_failed")
+	}
+	m.lastAlertFailedWrites = failed
+}
+
 // isBufferNotExistError 检查是否为缓冲区不存在错误
 func isBufferNotExistError(err error) bool {
 	return err != nil && (
@@ -536,7 +767,11 @@ func (m *DelayedBatchWriteManager) immediateWriteToDisk(op *CacheOperation) erro
 	atomic.AddInt64(&m.stats.TotalOperations, 1)
 	atomic.AddInt64(&m.stats.ImmediateWrites, 1)
 	
-	return m.mainCacheUpdater(op.Key, data, op.TTL)
+	if err := m.mainCacheUpdater(op.Key, data, op.TTL); err != nil {
+		return err
+	}
+	m.notifyFlush()
+	return nil
 }
 
 // enqueueForBatchWrite 加入批量写入队列
@@ -660,6 +895,12 @@ func (m *DelayedBatchWriteManager) Shutdown(timeout time.Duration) error {
 			lastErr = err
 		} 
 		
+		// 第四步：关闭预写日志，未能写入磁盘的数据保留在日志中，下次启动时回放
+		if err := m.CloseJournal(); err != nil {
+			fmt.Printf("[数据保护] 预写日志关闭失败: %v\n", err)
+			lastErr = err
+		}
+		
 		done <- lastErr
 	}()
 	
@@ -675,6 +916,17 @@ func (m *DelayedBatchWriteManager) Shutdown(timeout time.Duration) error {
 	}
 }
 
+// Flush 立即将全局缓冲区和本地队列中的数据写入磁盘，不关闭管理器，平滑升级启动新进程前调用
+func (m *DelayedBatchWriteManager) Flush() error {
+	if atomic.LoadInt32(&m.initialized) == 0 {
+		return nil
+	}
+	if err := m.flushAllGlobalBuffers(); err != nil {
+		return err
+	}
+	return m.flushAllPendingData()
+}
+
 // flushAllGlobalBuffers 刷新所有全局缓冲区
 func (m *DelayedBatchWriteManager) flushAllGlobalBuffers() error {
 	allBuffers := m.globalBufferManager.FlushAllBuffers()
@@ -867,19 +1119,25 @@ func (m *DelayedBatchWriteManager) batchWriteToDisk(operations []*CacheOperation
 	}
 	
 	// 批量处理所有操作
-	for _, op := range operations {
+	for i, op := range operations {
 		// 序列化数据
 		data, err := m.serializer.Serialize(op.Data)
 		if err != nil {
+			m.commitJournal(operations[:i])
 			return fmt.Errorf("数据序列化失败: %v", err)
 		}
 		
 		// 写入磁盘
 		if err := m.mainCacheUpdater(op.Key, data, op.TTL); err != nil {
+			m.commitJournal(operations[:i])
 			return fmt.Errorf("磁盘写入失败: %v", err)
 		}
 	}
 	
+	if len(operations) > 0 {
+		m.commitJournal(operations)
+		m.notifyFlush()
+	}
 	return nil
 }
 
@@ -896,6 +1154,12 @@ func (m *DelayedBatchWriteManager) autoTuneParameters() {
 	// 完全自动调优，无需配置开关
 	stats := m.collectRecentStats()
 	
+	// 与运行时调参互斥
+	m.queueMutex.Lock()
+	defer m.queueMutex.Unlock()
+	m.configMutex.Lock()
+	defer m.configMutex.Unlock()
+	
 	// 调优批量间隔：基于系统负载动态调整
 	avgSystemLoad := stats.SystemLoadAverage
 	switch {
@@ -977,4 +1241,72 @@ func (m *DelayedBatchWriteManager) GetWriteManagerStats() *WriteManagerStats {
 	}
 	
 	return &stats
-}
\ No newline at end of file
+}
+
+// currentStrategy 获取当前写入策略
+func (m *DelayedBatchWriteManager) currentStrategy() CacheWriteStrategy {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.strategy
+}
+
+// GetConfig 获取当前写入配置及其约束边界
+func (m *DelayedBatchWriteManager) GetConfig() map[string]interface{} {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	
+	return map[string]interface{}{
+		"strategy":            string(m.strategy),
+		"max_batch_interval":  m.config.MaxBatchInterval.String(),
+		"max_batch_size":      m.config.MaxBatchSize,
+		"max_batch_data_size": m.config.MaxBatchDataSize,
+		"high_priority_ratio": m.config.HighPriorityRatio,
+		"enable_compression":  m.config.EnableCompression,
+		"constraints": map[string]interface{}{
+			"min_batch_interval": m.config.minBatchInterval.String(),
+			"max_batch_interval": m.config.maxBatchInterval.String(),
+			"min_batch_size":     m.config.minBatchSize,
+			"max_batch_size":     m.config.maxBatchSize,
+		},
+	}
+}
+
+// UpdateConfig 运行时调整批量写入参数，零值表示保持不变
+func (m *DelayedBatchWriteManager) UpdateConfig(interval time.Duration, size int, strategy CacheWriteStrategy) error {
+	// 参数校验：必须落在硬编码约束边界内
+	if interval != 0 && (interval < m.config.minBatchInterval || interval > m.config.maxBatchInterval) {
+		return fmt.Errorf("批量间隔超出范围: %v 应在 [%v, %v] 之间",
+			interval, m.config.minBatchInterval, m.config.maxBatchInterval)
+	}
+	if size != 0 && (size < m.config.minBatchSize || size > m.config.maxBatchSize) {
+		return fmt.Errorf("批量大小超出范围: %d 应在 [%d, %d] 之间",
+			size, m.config.minBatchSize, m.config.maxBatchSize)
+	}
+	if strategy != "" && strategy != CacheStrategyImmediate && strategy != CacheStrategyHybrid {
+		return fmt.Errorf("未知的写入策略: %s", strategy)
+	}
+	
+	// 与批量写入和自动调优互斥
+	m.queueMutex.Lock()
+	defer m.queueMutex.Unlock()
+	m.configMutex.Lock()
+	defer m.configMutex.Unlock()
+	
+	if interval != 0 {
+		m.config.MaxBatchInterval = interval
+		m.config.forceFlushInterval = interval * 5
+		if m.flushTicker != nil {
+			m.flushTicker.Reset(interval)
+		}
+	}
+	if size != 0 {
+		m.config.MaxBatchSize = size
+	}
+	if strategy != "" && strategy != m.strategy {
+		m.strategy = strategy
+		m.config.Strategy = strategy
+		fmt.Printf("缓存写入策略已切换: %s\n", strategy)
+	}
+	
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 	
+	"pansou/config"
 	"pansou/util/json"
 )
 
@@ -20,6 +21,7 @@ type diskCacheMetadata struct {
 	LastUsed    time.Time `json:"last_used"`
 	Size        int       `json:"size"`
 	LastModified time.Time `json:"last_modified"` // 添加最后修改时间字段
+	Checksum    uint32    `json:"checksum,omitempty"` // 数据的CRC32校验和，旧版本写入的缓存项为0（不校验）
 }
 
 // DiskCache 磁盘缓存
@@ -29,8 +31,16 @@ type DiskCache struct {
 	metadata  map[string]*diskCacheMetadata
 	mutex     sync.RWMutex
 	currSize  int64
+
+	corrupted     int64     // 累计发现的损坏缓存项数
+	quarantined   int       // 因损坏过多被隔离的次数
+	recentCorrupt int       // 当前统计窗口内发现的损坏缓存项数
+	corruptSince  time.Time // 当前统计窗口的开始时间
 }
 
+// 一个统计窗口内损坏的缓存项达到CACHE_CORRUPTION_QUARANTINE时隔离整个分片
+const corruptionWindow = time.Hour
+
 // NewDiskCache 创建新的磁盘缓存
 func NewDiskCache(path string, maxSizeMB int) (*DiskCache, error) {
 	// 确保缓存目录存在
@@ -69,8 +79,14 @@ func (c *DiskCache) loadMetadata() {
 			continue
 		}
 
+		// 清理进程崩溃时遗留的临时文件
+		if IsAtomicTempFile(file.Name()) {
+			os.Remove(filepath.Join(c.path, file.Name()))
+			continue
+		}
+
 		// 跳过元数据文件
-		if file.Name() == "metadata.json" {
+		if file.Name() == "metadata.json" || filepath.Ext(file.Name()) == ".meta" {
 			continue
 		}
 
@@ -81,8 +97,12 @@ func (c *DiskCache) loadMetadata() {
 			continue
 		}
 
+		// 元数据无法解析或数据文件大小不符（写入中途崩溃）时视为损坏
 		var meta diskCacheMetadata
-		if err := json.Unmarshal(data, &meta); err != nil {
+		if err := json.Unmarshal(data, &meta); err != nil || file.Size() != int64(meta.Size) {
+			os.Remove(filepath.Join(c.path, file.Name()))
+			os.Remove(metadataFile)
+			c.noteCorruption()
 			continue
 		}
 
@@ -92,16 +112,20 @@ func (c *DiskCache) loadMetadata() {
 		// 存储元数据
 		c.metadata[meta.Key] = &meta
 	}
+	if c.corrupted > 0 {
+		fmt.Printf("[磁盘缓存] 加载 %s 时清理了 %d 个损坏的缓存项\n", c.path, c.corrupted)
+	}
+	c.quarantineIfNeeded()
 }
 
-// 保存元数据
-func (c *DiskCache) saveMetadata(key string, meta *diskCacheMetadata) error {
+// 保存元数据，durable为true时按fsync策略同步到磁盘
+func (c *DiskCache) saveMetadata(key string, meta *diskCacheMetadata, durable bool) error {
 	metadataFile := filepath.Join(c.path, c.getFilename(key)+".meta")
 	data, err := json.Marshal(meta)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(metadataFile, data, 0644)
+	return writeFileAtomic(metadataFile, data, durable)
 }
 
 // 获取文件名
@@ -115,13 +139,10 @@ func (c *DiskCache) Set(key string, data []byte, ttl time.Duration) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// 如果已存在，先减去旧项的大小
+	// 如果已存在，先减去旧项的大小（旧文件在写入新文件时被原子替换）
 	if meta, exists := c.metadata[key]; exists {
 		c.currSize -= int64(meta.Size)
-		// 删除旧文件
-		filename := c.getFilename(key)
-		os.Remove(filepath.Join(c.path, filename))
-		os.Remove(filepath.Join(c.path, filename+".meta"))
+		delete(c.metadata, key)
 	}
 
 	// 检查空间
@@ -140,8 +161,8 @@ func (c *DiskCache) Set(key string, data []byte, ttl time.Duration) error {
 		return fmt.Errorf("创建缓存目录失败: %v", err)
 	}
 
-	// 写入文件
-	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+	// 先写入临时文件再重命名，进程崩溃时不会留下写了一半的数据
+	if err := writeFileAtomic(filePath, data, true); err != nil {
 		return err
 	}
 
@@ -153,10 +174,11 @@ func (c *DiskCache) Set(key string, data []byte, ttl time.Duration) error {
 		LastUsed:    now,
 		LastModified: now, // 设置最后修改时间
 		Size:        len(data),
+		Checksum:    entryChecksum(data),
 	}
 
 	// 保存元数据
-	if err := c.saveMetadata(key, meta); err != nil {
+	if err := c.saveMetadata(key, meta, true); err != nil {
 		// 如果元数据保存失败，删除数据文件
 		os.Remove(filePath)
 		return err
@@ -198,10 +220,16 @@ func (c *DiskCache) Get(key string) ([]byte, bool, error) {
 		return nil, false, err
 	}
 
-	// 更新最后使用时间
+	// 数据与元数据记录的大小或校验和不符时视为损坏，删除后按未命中处理
+	if len(data) != meta.Size || (meta.Checksum != 0 && entryChecksum(data) != meta.Checksum) {
+		c.removeCorrupted(key, meta)
+		return nil, false, nil
+	}
+
+	// 更新最后使用时间（丢失不影响正确性，不需要同步到磁盘）
 	c.mutex.Lock()
 	meta.LastUsed = time.Now()
-	c.saveMetadata(key, meta)
+	c.saveMetadata(key, meta, false)
 	c.mutex.Unlock()
 
 	return data, true, nil
@@ -360,4 +388,112 @@ func (c *DiskCache) GetLastModified(key string) (time.Time, bool) {
 	}
 
 	return meta.LastModified, true
-} 
\ No newline at end of file
+} 
+// getExpiry 获取缓存项的过期时间
+func (c *DiskCache) getExpiry(key string) (time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	return meta.Expiry, true
+}
+
+// touch 修改缓存项的过期时间并保存元数据
+func (c *DiskCache) touch(key string, expiry time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return nil
+	}
+	meta.Expiry = expiry
+	return c.saveMetadata(key, meta, true)
+}
+
+// stats 获取缓存项数量、数据大小和目录占用的磁盘空间（含元数据文件）
+func (c *DiskCache) stats() StoreShardStats {
+	c.mutex.RLock()
+	stats := StoreShardStats{
+		Entries:  len(c.metadata),
+		Bytes:    c.currSize,
+		MaxBytes: int64(c.maxSizeMB) * 1024 * 1024,
+		Corrupted:   c.corrupted,
+		Quarantined: c.quarantined,
+	}
+	c.mutex.RUnlock()
+
+	stats.DiskBytes = dirSize(c.path)
+	return stats
+}
+
+// keys 获取当前分片中的所有缓存键
+func (c *DiskCache) keys() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	keys := make([]string, 0, len(c.metadata))
+	for key := range c.metadata {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// removeCorrupted 删除损坏的缓存项，损坏过多时隔离整个分片
+func (c *DiskCache) removeCorrupted(key string, meta *diskCacheMetadata) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// 读取期间已被替换或删除时不处理
+	if current, exists := c.metadata[key]; !exists || current != meta {
+		return
+	}
+	filename := c.getFilename(key)
+	os.Remove(filepath.Join(c.path, filename))
+	os.Remove(filepath.Join(c.path, filename+".meta"))
+	c.currSize -= int64(meta.Size)
+	delete(c.metadata, key)
+
+	fmt.Printf("[磁盘缓存] 缓存项校验失败，已删除: %s/%s\n", c.path, filename)
+	c.noteCorruption()
+	c.quarantineIfNeeded()
+}
+
+// noteCorruption 记录一个损坏的缓存项（调用方需持有锁）
+func (c *DiskCache) noteCorruption() {
+	now := time.Now()
+	if now.Sub(c.corruptSince) > corruptionWindow {
+		c.corruptSince = now
+		c.recentCorrupt = 0
+	}
+	c.corrupted++
+	c.recentCorrupt++
+}
+
+// quarantineIfNeeded 统计窗口内损坏的缓存项达到阈值时，将分片目录移到旁边（目录名加.corrupt-时间后缀）
+// 保留现场并以空分片继续服务，避免同一次崩溃损坏的其他缓存项继续被读取（调用方需持有锁）
+func (c *DiskCache) quarantineIfNeeded() {
+	if config.AppConfig == nil || config.AppConfig.CacheCorruptionQuarantine <= 0 || c.recentCorrupt < config.AppConfig.CacheCorruptionQuarantine {
+		return
+	}
+
+	target := c.path + quarantineMarker + time.Now().Format("20060102-150405")
+	if err := os.Rename(c.path, target); err != nil {
+		fmt.Printf("[磁盘缓存] 隔离分片失败: %s | 错误: %v\n", c.path, err)
+		return
+	}
+	if err := os.MkdirAll(c.path, 0755); err != nil {
+		fmt.Printf("[磁盘缓存] 重建分片目录失败: %s | 错误: %v\n", c.path, err)
+	}
+	fmt.Printf("[磁盘缓存] 分片 %s 在1小时内发现 %d 个损坏的缓存项，已隔离到 %s，该分片的 %d 个缓存项被丢弃\n",
+		c.path, c.recentCorrupt, target, len(c.metadata))
+
+	c.metadata = make(map[string]*diskCacheMetadata)
+	c.currSize = 0
+	c.recentCorrupt = 0
+	c.quarantined++
+}
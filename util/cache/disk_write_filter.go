@@ -0,0 +1,22 @@
+package cache
+
+import "sync/atomic"
+
+// DiskWriteFilter 在缓存数据写入磁盘（包括预写日志）前处理数据，返回实际写入磁盘的数据，
+// 返回nil表示不写入磁盘。内存缓存始终保存原数据
+type DiskWriteFilter func(key string, data []byte) []byte
+
+var diskWriteFilter atomic.Value // DiskWriteFilter
+
+// SetDiskWriteFilter 设置写入磁盘前的数据过滤函数，如去除不允许持久化的来源的结果，传入nil取消过滤
+func SetDiskWriteFilter(filter DiskWriteFilter) {
+	diskWriteFilter.Store(filter)
+}
+
+// filterDiskWrite 按过滤函数处理要写入磁盘的数据，未设置过滤函数时原样返回
+func filterDiskWrite(key string, data []byte) []byte {
+	if filter, ok := diskWriteFilter.Load().(DiskWriteFilter); ok && filter != nil {
+		return filter(key, data)
+	}
+	return data
+}
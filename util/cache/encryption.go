@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 加密数据格式：magic(4) + 密钥指纹(4) + nonce(12) + 密文
+var encryptedMagic = []byte("PSE1")
+
+const keyFingerprintSize = 4
+
+// ErrNoDecryptionKey 找不到可解密数据的密钥
+var ErrNoDecryptionKey = errors.New("缓存数据已加密，但没有匹配的解密密钥")
+
+// cacheKey 单个加密密钥
+type cacheKey struct {
+	fingerprint []byte
+	aead        cipher.AEAD
+}
+
+// CacheCipher 磁盘缓存加密器（AES-256-GCM），支持使用旧密钥解密以便轮换
+type CacheCipher struct {
+	current *cacheKey
+	keys    []*cacheKey // 包含current，按优先级排列
+}
+
+// NewCacheCipher 根据当前密钥和旧密钥（仅用于解密）创建加密器，
+// 密钥字符串经SHA-256派生为AES-256密钥
+func NewCacheCipher(secret string, oldSecrets []string) (*CacheCipher, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("缓存加密密钥不能为空")
+	}
+
+	current, err := newCacheKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CacheCipher{current: current, keys: []*cacheKey{current}}
+	for _, old := range oldSecrets {
+		if old == "" || old == secret {
+			continue
+		}
+		k, err := newCacheKey(old)
+		if err != nil {
+			return nil, err
+		}
+		c.keys = append(c.keys, k)
+	}
+
+	return c, nil
+}
+
+// newCacheKey 由密钥字符串派生AES-GCM实例
+func newCacheKey(secret string) (*cacheKey, error) {
+	derived := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, fmt.Errorf("创建AES加密器失败: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建GCM失败: %v", err)
+	}
+	fingerprint := sha256.Sum256(derived[:])
+	return &cacheKey{fingerprint: fingerprint[:keyFingerprintSize], aead: aead}, nil
+}
+
+// HasOldKeys 是否配置了用于轮换的旧密钥
+func (c *CacheCipher) HasOldKeys() bool {
+	return len(c.keys) > 1
+}
+
+// IsEncrypted 判断数据是否为加密格式
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// Encrypt 使用当前密钥加密数据
+func (c *CacheCipher) Encrypt(plain []byte) ([]byte, error) {
+	aead := c.current.aead
+	headerSize := len(encryptedMagic) + keyFingerprintSize + aead.NonceSize()
+
+	out := make([]byte, headerSize, headerSize+len(plain)+aead.Overhead())
+	copy(out, encryptedMagic)
+	copy(out[len(encryptedMagic):], c.current.fingerprint)
+	nonce := out[len(encryptedMagic)+keyFingerprintSize : headerSize]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+
+	return aead.Seal(out, nonce, plain, nil), nil
+}
+
+// Decrypt 解密数据。未加密的数据原样返回；
+// needsRewrite为true表示数据未加密或由旧密钥加密，应使用当前密钥重新写入
+func (c *CacheCipher) Decrypt(data []byte) (plain []byte, needsRewrite bool, err error) {
+	if !IsEncrypted(data) {
+		return data, true, nil
+	}
+
+	offset := len(encryptedMagic)
+	if len(data) < offset+keyFingerprintSize {
+		return nil, false, fmt.Errorf("加密缓存数据已损坏")
+	}
+	fingerprint := data[offset : offset+keyFingerprintSize]
+	offset += keyFingerprintSize
+
+	for _, k := range c.keys {
+		if !bytes.Equal(k.fingerprint, fingerprint) {
+			continue
+		}
+		nonceSize := k.aead.NonceSize()
+		if len(data) < offset+nonceSize {
+			return nil, false, fmt.Errorf("加密缓存数据已损坏")
+		}
+		plain, err := k.aead.Open(nil, data[offset:offset+nonceSize], data[offset+nonceSize:], nil)
+		if err != nil {
+			return nil, false, fmt.Errorf("缓存数据解密失败: %v", err)
+		}
+		return plain, k != c.current, nil
+	}
+
+	return nil, false, ErrNoDecryptionKey
+}
@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"pansou/config"
@@ -10,6 +12,9 @@ import (
 
 // EnhancedTwoLevelCache 改进的两级缓存
 type EnhancedTwoLevelCache struct {
+	memoryHits int64 // 内存缓存命中次数
+	diskHits   int64 // 内存未命中、磁盘缓存命中的次数
+	diskMisses int64 // 两级缓存均未命中的次数
 	memory     *ShardedMemoryCache
 	disk       *ShardedDiskCache
 	mutex      sync.RWMutex
@@ -25,15 +30,45 @@ func NewEnhancedTwoLevelCache() (*EnhancedTwoLevelCache, error) {
 	memCache := NewShardedMemoryCache(memCacheMaxItems, memCacheSizeMB)
 	memCache.StartCleanupTask()
 
-	// 创建优化的分片磁盘缓存，使用动态分片数量
-	diskCache, err := NewOptimizedShardedDiskCache(config.AppConfig.CachePath, config.AppConfig.CacheMaxSizeMB)
+	// 按配置打开磁盘缓存存储后端，文件存储使用动态分片数量
+	store, err := OpenStore(config.AppConfig.CacheStore, config.AppConfig.CachePath, config.AppConfig.CacheMaxSizeMB)
 	if err != nil {
 		return nil, err
 	}
+	diskCache := NewShardedDiskCacheWithStore(store)
+	
+	// 文件存储的各分片自带清理任务，数据库存储由全局清理任务定期清理过期项
+	if _, isFileStore := store.(*FileStore); !isFileStore {
+		diskCache.StartCleanupTask()
+	}
 
+	// 配置磁盘缓存静态加密
+	if config.AppConfig.CacheEncryptionKey != "" {
+		cipher, err := NewCacheCipher(config.AppConfig.CacheEncryptionKey, config.AppConfig.CacheEncryptionOldKeys)
+		if err != nil {
+			return nil, fmt.Errorf("缓存加密初始化失败: %v", err)
+		}
+		diskCache.SetCipher(cipher)
+		
+		// 配置了旧密钥时，后台将旧数据重新加密为当前密钥
+		if cipher.HasOldKeys() {
+			go func() {
+				count := diskCache.RotateEncryption()
+				fmt.Printf("[缓存加密] 密钥轮换完成，已检查 %d 个缓存项\n", count)
+			}()
+		}
+	}
+	
 	// 创建序列化器
 	serializer := NewGobSerializer()
 
+	// 检查磁盘缓存的结构版本，迁移或丢弃旧版本数据。迁移会重新写入或删除缓存项，
+	// 须在开始处理请求之前完成，不能与搜索结果的写入并发执行
+	if report, checked := diskCache.MigrateSchemaIfNeeded(config.AppConfig.CachePath, serializer); checked {
+		fmt.Printf("[缓存迁移] 结构版本 v%d，检查 %d 项，迁移 %d 项，丢弃 %d 项\n",
+			CacheSchemaVersion, report.Checked, report.Migrated, report.Dropped)
+	}
+
 	// 设置内存缓存的磁盘缓存引用，用于LRU淘汰时的备份
 	memCache.SetDiskCacheReference(diskCache)
 
@@ -91,26 +126,79 @@ func (c *EnhancedTwoLevelCache) SetWithFinalFlag(key string, data []byte, ttl ti
 	}
 }
 
+// 缓存命中的层级
+const (
+	CacheLevelMemory = "memory"
+	CacheLevelDisk   = "disk"
+)
+
 // Get 获取缓存
 func (c *EnhancedTwoLevelCache) Get(key string) ([]byte, bool, error) {
+	data, _, hit, err := c.GetWithLevel(key)
+	return data, hit, err
+}
+
+// GetWithLevel 获取缓存，命中时同时返回命中的层级（CacheLevelMemory或CacheLevelDisk）
+func (c *EnhancedTwoLevelCache) GetWithLevel(key string) ([]byte, string, bool, error) {
 	
 	// 检查内存缓存
 	data, _, memHit := c.memory.GetWithTimestamp(key)
 	if memHit {
-		return data, true, nil
+		atomic.AddInt64(&c.memoryHits, 1)
+		return data, CacheLevelMemory, true, nil
 	}
 
     // 尝试从磁盘读取数据
 	diskData, diskHit, diskErr := c.disk.Get(key)
 	if diskErr == nil && diskHit {
+		atomic.AddInt64(&c.diskHits, 1)
 		// 磁盘缓存命中，更新内存缓存
 		diskLastModified, _ := c.disk.GetLastModified(key)
 		ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
 		c.memory.SetWithTimestamp(key, diskData, ttl, diskLastModified)
-		return diskData, true, nil
+		return diskData, CacheLevelDisk, true, nil
 	}
 	
-	return nil, false, nil
+	atomic.AddInt64(&c.diskMisses, 1)
+	return nil, "", false, nil
+}
+
+// GetFromDisk 跳过内存缓存直接读取磁盘缓存，命中时用磁盘数据覆盖内存缓存
+func (c *EnhancedTwoLevelCache) GetFromDisk(key string) ([]byte, bool, error) {
+	diskData, diskHit, diskErr := c.disk.Get(key)
+	if diskErr != nil || !diskHit {
+		atomic.AddInt64(&c.diskMisses, 1)
+		return nil, false, diskErr
+	}
+	atomic.AddInt64(&c.diskHits, 1)
+	diskLastModified, _ := c.disk.GetLastModified(key)
+	ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
+	c.memory.SetWithTimestamp(key, diskData, ttl, diskLastModified)
+	return diskData, true, nil
+}
+
+// Touch 滑动过期：将缓存项的有效期延长为从现在起ttl，但不超过最后修改时间之后的maxAge，返回缓存项的最后修改时间。
+// 内存缓存每次都延长；磁盘缓存只在延长量超过ttl的一半时异步写入，避免热门缓存每次读取都写盘
+func (c *EnhancedTwoLevelCache) Touch(key string, ttl, maxAge time.Duration) (time.Time, bool) {
+	lastModified, ok := c.memory.GetLastModified(key)
+	if !ok {
+		if lastModified, ok = c.disk.GetLastModified(key); !ok {
+			return time.Time{}, false
+		}
+	}
+	
+	expiry := time.Now().Add(ttl)
+	if maxAge > 0 {
+		if limit := lastModified.Add(maxAge); limit.Before(expiry) {
+			expiry = limit
+		}
+	}
+	c.memory.Extend(key, expiry)
+	
+	if diskExpiry, ok := c.disk.Expiry(key); ok && expiry.Sub(diskExpiry) > ttl/2 {
+		go c.disk.Touch(key, expiry)
+	}
+	return lastModified, true
 }
 
 // Delete 删除缓存
@@ -145,6 +233,17 @@ func (c *EnhancedTwoLevelCache) GetSerializer() Serializer {
 	return c.serializer
 }
 
+// CloseDisk 关闭磁盘缓存存储，释放数据库文件锁，之后只使用内存缓存。
+// 平滑升级时在启动新进程前调用，使新进程可以打开同一个数据库
+func (c *EnhancedTwoLevelCache) CloseDisk() error {
+	return c.disk.Close()
+}
+
+// ReopenDisk 重新打开已关闭的磁盘缓存存储
+func (c *EnhancedTwoLevelCache) ReopenDisk() error {
+	return c.disk.Reopen()
+}
+
 // FlushMemoryToDisk 将内存缓存中的所有数据刷新到磁盘
 func (c *EnhancedTwoLevelCache) FlushMemoryToDisk() error {
 	// 获取内存缓存中的所有键值对
@@ -155,6 +254,9 @@ func (c *EnhancedTwoLevelCache) FlushMemoryToDisk() error {
 	for key, item := range allItems {
 		// 同步写入到磁盘缓存
 		if err := c.disk.Set(key, item.Data, item.TTL); err != nil {
+			if errors.Is(err, ErrStoreClosed) {
+				return err
+			}
 			fmt.Printf("[内存同步] 同步失败: %s -> %v\n", key, err)
 			lastErr = err
 			continue
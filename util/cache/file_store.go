@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// FileStore 分片文件存储：按键哈希分到多个目录，每个缓存项一个数据文件和一个元数据文件。
+// 文件先写入临时文件再重命名，读取时校验数据的校验和，损坏过多的分片被隔离
+type FileStore struct {
+	baseDir    string
+	shardCount int
+	shardMask  uint32 // 用于快速取模的掩码
+	shards     []*DiskCache
+	maxSizeMB  int
+	mutex      sync.RWMutex
+}
+
+// NewFileStore 创建指定分片数的文件存储
+func NewFileStore(baseDir string, shardCount, maxSizeMB int) (*FileStore, error) {
+	// 确保每个分片的大小合理
+	shardSize := maxSizeMB / shardCount
+	if shardSize < 1 {
+		shardSize = 1
+	}
+
+	store := &FileStore{
+		baseDir:    baseDir,
+		shardCount: shardCount,
+		shardMask:  uint32(shardCount - 1), // 用于快速取模
+		shards:     make([]*DiskCache, shardCount),
+		maxSizeMB:  maxSizeMB,
+	}
+
+	// 初始化每个分片
+	for i := 0; i < shardCount; i++ {
+		shardPath := filepath.Join(baseDir, fmt.Sprintf("shard_%d", i))
+		diskCache, err := NewDiskCache(shardPath, shardSize)
+		if err != nil {
+			return nil, err
+		}
+		store.shards[i] = diskCache
+	}
+
+	return store, nil
+}
+
+// NewOptimizedFileStore 创建文件存储（动态分片数）
+func NewOptimizedFileStore(baseDir string, maxSizeMB int) (*FileStore, error) {
+	// 动态确定分片数量：与内存缓存保持一致的策略
+	shardCount := runtime.NumCPU() * 2
+	if shardCount < 4 {
+		shardCount = 4
+	}
+	if shardCount > 32 { // 磁盘缓存分片数适当限制，避免过多文件夹
+		shardCount = 32
+	}
+
+	// 确保分片数是2的幂，便于使用掩码进行快速取模
+	shardCount = nextPowerOfTwoDisk(shardCount)
+
+	return NewFileStore(baseDir, shardCount, maxSizeMB)
+}
+
+// 获取下一个2的幂（磁盘缓存版本）
+func nextPowerOfTwoDisk(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	n--
+	n |= n >> 1
+	n |= n >> 2
+	n |= n >> 4
+	n |= n >> 8
+	n |= n >> 16
+	return n + 1
+}
+
+// 获取键对应的分片
+func (s *FileStore) getShard(key string) *DiskCache {
+	return s.shards[s.GetShardIndex(key)]
+}
+
+// Set 设置缓存
+func (s *FileStore) Set(key string, data []byte, ttl time.Duration) error {
+	return s.getShard(key).Set(key, data, ttl)
+}
+
+// Get 获取缓存
+func (s *FileStore) Get(key string) ([]byte, bool, error) {
+	return s.getShard(key).Get(key)
+}
+
+// Delete 删除缓存
+func (s *FileStore) Delete(key string) error {
+	return s.getShard(key).Delete(key)
+}
+
+// Has 检查缓存是否存在
+func (s *FileStore) Has(key string) bool {
+	return s.getShard(key).Has(key)
+}
+
+// Clear 清空所有缓存
+func (s *FileStore) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var lastErr error
+	for _, shard := range s.shards {
+		if err := shard.Clear(); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// GetLastModified 获取缓存项的最后修改时间
+func (s *FileStore) GetLastModified(key string) (time.Time, bool) {
+	return s.getShard(key).GetLastModified(key)
+}
+
+// Expiry 获取缓存项的过期时间
+func (s *FileStore) Expiry(key string) (time.Time, bool) {
+	return s.getShard(key).getExpiry(key)
+}
+
+// Touch 修改缓存项的过期时间
+func (s *FileStore) Touch(key string, expiry time.Time) error {
+	return s.getShard(key).touch(key, expiry)
+}
+
+// Keys 获取所有分片中的缓存键
+func (s *FileStore) Keys() []string {
+	var keys []string
+	for _, shard := range s.shards {
+		keys = append(keys, shard.keys()...)
+	}
+	return keys
+}
+
+// CleanExpired 并行清理所有分片中的过期项
+func (s *FileStore) CleanExpired() {
+	for _, shard := range s.shards {
+		go func(d *DiskCache) {
+			d.cleanExpired()
+		}(shard)
+	}
+}
+
+// Stats 获取各分片的缓存项数量、数据大小和占用的磁盘空间
+func (s *FileStore) Stats() StoreStats {
+	stats := StoreStats{
+		Backend:  StoreFile,
+		MaxBytes: int64(s.maxSizeMB) * 1024 * 1024,
+		Shards:   make([]StoreShardStats, len(s.shards)),
+	}
+	for i, shard := range s.shards {
+		shardStats := shard.stats()
+		shardStats.Shard = i
+		stats.Shards[i] = shardStats
+		stats.Entries += shardStats.Entries
+		stats.Bytes += shardStats.Bytes
+		stats.DiskBytes += shardStats.DiskBytes
+		stats.Corrupted += shardStats.Corrupted
+		stats.Quarantined += shardStats.Quarantined
+	}
+	return stats
+}
+
+// Close 同步尚未落盘的写入（fsync策略为interval时），文件存储没有其他需要释放的资源
+func (s *FileStore) Close() error {
+	flushPendingFsync()
+	return nil
+}
+
+// Reopen 文件存储无需重新打开
+func (s *FileStore) Reopen() error {
+	return nil
+}
+
+// GetShards 获取所有分片（用于测试和调试）
+func (s *FileStore) GetShards() []*DiskCache {
+	return s.shards
+}
+
+// GetShardIndex 获取指定键对应的分片索引（用于测试和调试）
+func (s *FileStore) GetShardIndex(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if s.shardMask > 0 {
+		return int(h.Sum32() & s.shardMask)
+	} else {
+		// 兼容老版本的模运算
+		return int(h.Sum32()) % s.shardCount
+	}
+}
@@ -0,0 +1,371 @@
+package cache
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 键值存储中每个值的头部：过期时间和最后修改时间（UnixNano，大端序）
+const kvHeaderLen = 16
+
+// kvBackend 嵌入式键值数据库，由kvStore负责过期、容量和元数据
+type kvBackend interface {
+	put(key string, value []byte, ttl time.Duration) error
+	// get 键不存在时返回nil, nil
+	get(key string) ([]byte, error)
+	delete(keys []string) error
+	// iterate 遍历所有键值，value只在回调期间有效
+	iterate(fn func(key string, value []byte)) error
+	clear() error
+	// gc 回收已删除数据占用的空间
+	gc()
+	// size 数据库占用的磁盘空间
+	size() int64
+	close() error
+}
+
+// kvMeta 缓存项元数据，打开存储时从数据库加载到内存
+type kvMeta struct {
+	expiry       time.Time
+	lastModified time.Time
+	size         int
+}
+
+// kvStore 基于嵌入式键值数据库的存储，所有缓存项保存在单个数据库中，避免大量小文件
+type kvStore struct {
+	name      string
+	open      func() (kvBackend, error)
+	backend   kvBackend // 为nil时存储已关闭
+	maxSizeMB int
+
+	mutex    sync.RWMutex
+	metadata map[string]*kvMeta
+	currSize int64
+}
+
+// newKVStore 打开数据库并加载所有缓存项的元数据
+func newKVStore(name string, open func() (kvBackend, error), maxSizeMB int) (*kvStore, error) {
+	s := &kvStore{name: name, open: open, maxSizeMB: maxSizeMB}
+	if err := s.Reopen(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// encodeKVValue 为数据添加过期时间和最后修改时间头部
+func encodeKVValue(data []byte, expiry, modified time.Time) []byte {
+	value := make([]byte, kvHeaderLen+len(data))
+	binary.BigEndian.PutUint64(value[0:8], uint64(expiry.UnixNano()))
+	binary.BigEndian.PutUint64(value[8:16], uint64(modified.UnixNano()))
+	copy(value[kvHeaderLen:], data)
+	return value
+}
+
+// decodeKVHeader 解析值头部，数据过短时返回false
+func decodeKVHeader(value []byte) (expiry, modified time.Time, ok bool) {
+	if len(value) < kvHeaderLen {
+		return time.Time{}, time.Time{}, false
+	}
+	expiry = time.Unix(0, int64(binary.BigEndian.Uint64(value[0:8])))
+	modified = time.Unix(0, int64(binary.BigEndian.Uint64(value[8:16])))
+	return expiry, modified, true
+}
+
+// Reopen 打开数据库，重新加载元数据并删除已过期或无法解析的缓存项
+func (s *kvStore) Reopen() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend != nil {
+		return nil
+	}
+
+	backend, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	metadata := make(map[string]*kvMeta)
+	var currSize int64
+	var stale []string
+	err = backend.iterate(func(key string, value []byte) {
+		expiry, modified, ok := decodeKVHeader(value)
+		if !ok || now.After(expiry) {
+			stale = append(stale, key)
+			return
+		}
+		size := len(value) - kvHeaderLen
+		metadata[key] = &kvMeta{expiry: expiry, lastModified: modified, size: size}
+		currSize += int64(size)
+	})
+	if err == nil && len(stale) > 0 {
+		err = backend.delete(stale)
+	}
+	if err != nil {
+		backend.close()
+		return err
+	}
+
+	s.backend = backend
+	s.metadata = metadata
+	s.currSize = currSize
+	return nil
+}
+
+// Set 设置缓存，超出容量时优先淘汰最早过期的缓存项
+func (s *kvStore) Set(key string, data []byte, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return ErrStoreClosed
+	}
+
+	var oldSize int64
+	if meta, exists := s.metadata[key]; exists {
+		oldSize = int64(meta.size)
+	}
+	maxSize := int64(s.maxSizeMB) * 1024 * 1024
+	if s.currSize-oldSize+int64(len(data)) > maxSize {
+		s.evict(key, int64(len(data))-oldSize)
+	}
+
+	now := time.Now()
+	expiry := now.Add(ttl)
+	if err := s.backend.put(key, encodeKVValue(data, expiry, now), ttl); err != nil {
+		return err
+	}
+
+	s.currSize += int64(len(data)) - oldSize
+	s.metadata[key] = &kvMeta{expiry: expiry, lastModified: now, size: len(data)}
+	return nil
+}
+
+// evict 按过期时间从早到晚淘汰缓存项（不含正在写入的键），直到有足够空间（调用方需持有锁）
+func (s *kvStore) evict(skipKey string, requiredSpace int64) {
+	keys := make([]string, 0, len(s.metadata))
+	for key := range s.metadata {
+		if key != skipKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.metadata[keys[i]].expiry.Before(s.metadata[keys[j]].expiry)
+	})
+
+	maxSize := int64(s.maxSizeMB) * 1024 * 1024
+	var evicted []string
+	for _, key := range keys {
+		if s.currSize+requiredSpace <= maxSize {
+			break
+		}
+		s.currSize -= int64(s.metadata[key].size)
+		delete(s.metadata, key)
+		evicted = append(evicted, key)
+	}
+	if len(evicted) > 0 {
+		s.backend.delete(evicted)
+	}
+}
+
+// Get 获取缓存
+func (s *kvStore) Get(key string) ([]byte, bool, error) {
+	s.mutex.RLock()
+	meta, exists := s.metadata[key]
+	if !exists || s.backend == nil {
+		s.mutex.RUnlock()
+		return nil, false, nil
+	}
+	var value []byte
+	var err error
+	expired := time.Now().After(meta.expiry)
+	if !expired {
+		value, err = s.backend.get(key)
+	}
+	s.mutex.RUnlock()
+
+	if err != nil {
+		return nil, false, err
+	}
+	if expired || len(value) < kvHeaderLen {
+		s.Delete(key)
+		return nil, false, nil
+	}
+	return value[kvHeaderLen:], true, nil
+}
+
+// Delete 删除缓存
+func (s *kvStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return ErrStoreClosed
+	}
+
+	meta, exists := s.metadata[key]
+	if !exists {
+		return nil
+	}
+	if err := s.backend.delete([]string{key}); err != nil {
+		return err
+	}
+	s.currSize -= int64(meta.size)
+	delete(s.metadata, key)
+	return nil
+}
+
+// Has 检查缓存是否存在
+func (s *kvStore) Has(key string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	meta, exists := s.metadata[key]
+	return exists && s.backend != nil && !time.Now().After(meta.expiry)
+}
+
+// Clear 清空所有缓存
+func (s *kvStore) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return ErrStoreClosed
+	}
+
+	if err := s.backend.clear(); err != nil {
+		return err
+	}
+	s.metadata = make(map[string]*kvMeta)
+	s.currSize = 0
+	return nil
+}
+
+// GetLastModified 获取缓存项的最后修改时间
+func (s *kvStore) GetLastModified(key string) (time.Time, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	meta, exists := s.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+	return meta.lastModified, true
+}
+
+// Expiry 获取缓存项的过期时间
+func (s *kvStore) Expiry(key string) (time.Time, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	meta, exists := s.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+	return meta.expiry, true
+}
+
+// Touch 修改缓存项的过期时间，重新写入值头部
+func (s *kvStore) Touch(key string, expiry time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return ErrStoreClosed
+	}
+
+	meta, exists := s.metadata[key]
+	if !exists {
+		return nil
+	}
+	value, err := s.backend.get(key)
+	if err != nil || len(value) < kvHeaderLen {
+		return err
+	}
+	binary.BigEndian.PutUint64(value[0:8], uint64(expiry.UnixNano()))
+	if err := s.backend.put(key, value, time.Until(expiry)); err != nil {
+		return err
+	}
+	meta.expiry = expiry
+	return nil
+}
+
+// Keys 获取所有缓存键
+func (s *kvStore) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(s.metadata))
+	for key := range s.metadata {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Stats 获取缓存项数量、数据大小和数据库占用的磁盘空间
+func (s *kvStore) Stats() StoreStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := StoreStats{
+		Backend:  s.name,
+		Entries:  len(s.metadata),
+		Bytes:    s.currSize,
+		MaxBytes: int64(s.maxSizeMB) * 1024 * 1024,
+	}
+	if s.backend != nil {
+		stats.DiskBytes = s.backend.size()
+	}
+	return stats
+}
+
+// CleanExpired 清理过期项并回收数据库空间
+func (s *kvStore) CleanExpired() {
+	s.removeExpired()
+
+	// 回收空间耗时较长，只持有读锁，不阻塞读取
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.backend != nil {
+		s.backend.gc()
+	}
+}
+
+// removeExpired 删除过期项
+func (s *kvStore) removeExpired() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return
+	}
+
+	now := time.Now()
+	var expired []string
+	for key, meta := range s.metadata {
+		if now.After(meta.expiry) {
+			expired = append(expired, key)
+		}
+	}
+	if len(expired) > 0 {
+		if err := s.backend.delete(expired); err != nil {
+			return
+		}
+		for _, key := range expired {
+			s.currSize -= int64(s.metadata[key].size)
+			delete(s.metadata, key)
+		}
+	}
+}
+
+// Close 关闭数据库，释放文件锁
+func (s *kvStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.backend == nil {
+		return nil
+	}
+
+	err := s.backend.close()
+	s.backend = nil
+	s.metadata = make(map[string]*kvMeta)
+	s.currSize = 0
+	return err
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/model"
+)
+
+// CacheSchemaVersion 缓存数据结构版本。修改缓存中保存的model类型（SearchResult、Link等）的字段时递增，
+// 字段类型或含义不兼容时通过RegisterCacheMigration注册旧版本数据的转换函数
+const CacheSchemaVersion byte = 2
+
+// 未带版本头的旧数据视为版本1
+const legacySchemaVersion byte = 1
+
+// 版本头：0x00（gob数据首字节为消息长度，不会为0）+ "PS" + 版本号
+var schemaMagic = []byte{0x00, 'P', 'S'}
+
+const schemaHeaderLen = 4
+
+// 缓存目录中记录磁盘缓存已迁移到的结构版本的文件，与当前版本一致时启动不再扫描
+const schemaVersionFileName = "schema.version"
+
+// ErrCacheSchemaMismatch 缓存数据的结构版本与当前版本不兼容
+var ErrCacheSchemaMismatch = errors.New("缓存数据结构版本不兼容")
+
+// CacheMigration 将旧版本的gob数据转换为当前版本的搜索结果
+type CacheMigration func(payload []byte) ([]model.SearchResult, error)
+
+var (
+	cacheMigrations     = make(map[byte]CacheMigration)
+	cacheMigrationsLock sync.RWMutex
+)
+
+// RegisterCacheMigration 注册从指定版本迁移到当前版本的转换函数。
+// 未注册转换函数的旧版本数据按当前类型直接解码（gob可容忍字段增减），解码失败的数据被丢弃
+func RegisterCacheMigration(fromVersion byte, migration CacheMigration) {
+	cacheMigrationsLock.Lock()
+	defer cacheMigrationsLock.Unlock()
+	cacheMigrations[fromVersion] = migration
+}
+
+// addSchemaHeader 为序列化数据添加当前版本头
+func addSchemaHeader(payload []byte) []byte {
+	data := make([]byte, schemaHeaderLen+len(payload))
+	copy(data, schemaMagic)
+	data[len(schemaMagic)] = CacheSchemaVersion
+	copy(data[schemaHeaderLen:], payload)
+	return data
+}
+
+// splitSchemaHeader 分离版本头，没有版本头的数据视为版本1
+func splitSchemaHeader(data []byte) (byte, []byte) {
+	if len(data) >= schemaHeaderLen && bytes.HasPrefix(data, schemaMagic) {
+		return data[len(schemaMagic)], data[schemaHeaderLen:]
+	}
+	return legacySchemaVersion, data
+}
+
+// SchemaMigrationReport 启动时缓存结构版本检查的结果
+type SchemaMigrationReport struct {
+	Checked  int // 检查的缓存项数
+	Current  int // 已是当前版本的缓存项数
+	Migrated int // 迁移到当前版本并重新写入的缓存项数
+	Dropped  int // 无法迁移而删除的缓存项数
+}
+
+// MigrateSchemaIfNeeded 缓存目录中记录的结构版本不是当前版本时执行MigrateSchema并记录当前版本，
+// 返回是否执行了检查。须在提供服务之前同步调用
+func (c *ShardedDiskCache) MigrateSchemaIfNeeded(dir string, serializer *GobSerializer) (SchemaMigrationReport, bool) {
+	path := filepath.Join(dir, schemaVersionFileName)
+	if data, err := os.ReadFile(path); err == nil {
+		if version, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && version == int(CacheSchemaVersion) {
+			return SchemaMigrationReport{}, false
+		}
+	}
+	report := c.MigrateSchema(serializer)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(int(CacheSchemaVersion))+"\n"), 0644); err != nil {
+		fmt.Printf("[缓存迁移] 记录结构版本失败: %v\n", err)
+	}
+	return report, true
+}
+
+// MigrateSchema 检查磁盘缓存中所有缓存项的结构版本，将旧版本数据迁移到当前版本（按剩余有效期重新写入），
+// 无法迁移的数据直接删除，避免之后每次读取都反序列化失败。
+// 迁移先读取再重新写入或删除缓存项，与其他写入并发时可能覆盖或删除刚写入的新数据，只能在提供服务之前执行
+func (c *ShardedDiskCache) MigrateSchema(serializer *GobSerializer) SchemaMigrationReport {
+	var report SchemaMigrationReport
+	for _, key := range c.store.Keys() {
+		data, hit, err := c.Get(key)
+		if err != nil || !hit {
+			continue
+		}
+		report.Checked++
+
+		version, payload := splitSchemaHeader(data)
+		if version == CacheSchemaVersion {
+			report.Current++
+			continue
+		}
+
+		results, err := migratePayload(serializer, version, payload)
+		if err == nil {
+			var migrated []byte
+			if migrated, err = serializer.Serialize(results); err == nil {
+				if expiry, ok := c.store.Expiry(key); ok {
+					if ttl := time.Until(expiry); ttl > 0 {
+						err = c.Set(key, migrated, ttl)
+					}
+				}
+			}
+		}
+		if err != nil {
+			c.Delete(key)
+			report.Dropped++
+			continue
+		}
+		report.Migrated++
+	}
+	return report
+}
+
+// migratePayload 使用注册的转换函数或按当前类型解码旧版本数据
+func migratePayload(serializer *GobSerializer, version byte, payload []byte) ([]model.SearchResult, error) {
+	cacheMigrationsLock.RLock()
+	migration, ok := cacheMigrations[version]
+	cacheMigrationsLock.RUnlock()
+	if ok {
+		return migration(payload)
+	}
+
+	var results []model.SearchResult
+	if err := serializer.decodePayload(payload, &results); err != nil {
+		return nil, fmt.Errorf("%w（版本%d）: %v", ErrCacheSchemaMismatch, version, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"pansou/model"
+)
+
+// 启动时迁移旧版本数据并记录结构版本，之后的启动不再扫描
+func TestMigrateSchemaIfNeeded(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(StoreFile, dir, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	disk := NewShardedDiskCacheWithStore(store)
+	serializer := NewGobSerializer()
+
+	// 不带版本头的旧版本数据
+	var legacy bytes.Buffer
+	if err := gob.NewEncoder(&legacy).Encode([]model.SearchResult{{UniqueID: "legacy-1", Title: "流浪地球"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := disk.Set("legacy", legacy.Bytes(), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	report, checked := disk.MigrateSchemaIfNeeded(dir, serializer)
+	if !checked || report.Migrated != 1 {
+		t.Fatalf("首次启动应迁移1项，实际 checked=%v %+v", checked, report)
+	}
+	data, hit, err := disk.Get("legacy")
+	if err != nil || !hit {
+		t.Fatalf("迁移后的缓存项丢失: %v", err)
+	}
+	if version, _ := splitSchemaHeader(data); version != CacheSchemaVersion {
+		t.Fatalf("迁移后的版本为 %d，期望 %d", version, CacheSchemaVersion)
+	}
+
+	if _, checked := disk.MigrateSchemaIfNeeded(dir, serializer); checked {
+		t.Fatalf("已记录当前结构版本时不应再次扫描")
+	}
+}
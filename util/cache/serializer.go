@@ -3,7 +3,9 @@ package cache
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 	
 	"pansou/model"
@@ -38,7 +40,9 @@ type Serializer interface {
 
 // GobSerializer 使用gob进行序列化/反序列化
 type GobSerializer struct {
-	bufferPool sync.Pool
+	serializeErrors   int64 // 序列化失败次数
+	deserializeErrors int64 // 反序列化失败次数
+	bufferPool        sync.Pool
 }
 
 // NewGobSerializer 创建新的gob序列化器
@@ -60,16 +64,55 @@ func (s *GobSerializer) Serialize(v interface{}) ([]byte, error) {
 	
 	enc := gob.NewEncoder(buf)
 	if err := enc.Encode(v); err != nil {
+		atomic.AddInt64(&s.serializeErrors, 1)
 		return nil, err
 	}
 	
-	result := make([]byte, buf.Len())
-	copy(result, buf.Bytes())
-	return result, nil
+	// 添加缓存结构版本头
+	return addSchemaHeader(buf.Bytes()), nil
 }
 
-// Deserialize 反序列化数据
+// Deserialize 反序列化数据。旧版本数据使用注册的转换函数或按当前类型解码，
+// 无法解码时返回ErrCacheSchemaMismatch
 func (s *GobSerializer) Deserialize(data []byte, v interface{}) error {
+	err := s.deserialize(data, v)
+	if err != nil {
+		atomic.AddInt64(&s.deserializeErrors, 1)
+	}
+	return err
+}
+
+// Stats 获取序列化和反序列化失败的次数
+func (s *GobSerializer) Stats() SerializerStats {
+	return SerializerStats{
+		SerializeErrors:   atomic.LoadInt64(&s.serializeErrors),
+		DeserializeErrors: atomic.LoadInt64(&s.deserializeErrors),
+	}
+}
+
+// deserialize 按版本头解码数据
+func (s *GobSerializer) deserialize(data []byte, v interface{}) error {
+	version, payload := splitSchemaHeader(data)
+	if version == CacheSchemaVersion {
+		return s.decodePayload(payload, v)
+	}
+
+	if results, ok := v.(*[]model.SearchResult); ok {
+		migrated, err := migratePayload(s, version, payload)
+		if err != nil {
+			return err
+		}
+		*results = migrated
+		return nil
+	}
+	if err := s.decodePayload(payload, v); err != nil {
+		return fmt.Errorf("%w（版本%d）: %v", ErrCacheSchemaMismatch, version, err)
+	}
+	return nil
+}
+
+// decodePayload 解码不含版本头的gob数据
+func (s *GobSerializer) decodePayload(data []byte, v interface{}) error {
 	buf := s.bufferPool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer s.bufferPool.Put(buf)
@@ -1,156 +1,174 @@
 package cache
 
 import (
-	"fmt"
-	"hash/fnv"
-	"path/filepath"
-	"runtime"
 	"sync"
 	"time"
 )
 
-// ShardedDiskCache 分片磁盘缓存
+// ShardedDiskCache 磁盘缓存：在存储后端（见Store）之上提供静态加密和结构版本迁移
 type ShardedDiskCache struct {
-	baseDir     string
-	shardCount  int
-	shardMask   uint32 // 用于快速取模的掩码
-	shards      []*DiskCache
-	maxSizeMB   int
+	store       Store
 	mutex       sync.RWMutex
+	cipher      *CacheCipher // 可选的静态加密器，为nil时明文存储
 }
 
 // NewShardedDiskCache 创建新的分片磁盘缓存（兼容现有接口）
 func NewShardedDiskCache(baseDir string, shardCount, maxSizeMB int) (*ShardedDiskCache, error) {
-	return newShardedDiskCacheWithCount(baseDir, shardCount, maxSizeMB)
+	store, err := NewFileStore(baseDir, shardCount, maxSizeMB)
+	if err != nil {
+		return nil, err
+	}
+	return NewShardedDiskCacheWithStore(store), nil
 }
 
 // NewOptimizedShardedDiskCache 创建优化的分片磁盘缓存（动态分片数）
 func NewOptimizedShardedDiskCache(baseDir string, maxSizeMB int) (*ShardedDiskCache, error) {
-	// 动态确定分片数量：与内存缓存保持一致的策略
-	shardCount := runtime.NumCPU() * 2
-	if shardCount < 4 {
-		shardCount = 4
+	store, err := NewOptimizedFileStore(baseDir, maxSizeMB)
+	if err != nil {
+		return nil, err
 	}
-	if shardCount > 32 { // 磁盘缓存分片数适当限制，避免过多文件夹
-		shardCount = 32
+	return NewShardedDiskCacheWithStore(store), nil
+}
+
+// NewShardedDiskCacheWithStore 使用指定的存储后端创建磁盘缓存
+func NewShardedDiskCacheWithStore(store Store) *ShardedDiskCache {
+	return &ShardedDiskCache{store: store}
+}
+
+// GetStore 获取存储后端
+func (c *ShardedDiskCache) GetStore() Store {
+	return c.store
+}
+
+// SetCipher 设置磁盘加密器，设置后写入的数据均加密存储
+func (c *ShardedDiskCache) SetCipher(cipher *CacheCipher) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cipher = cipher
+}
+
+// getCipher 获取磁盘加密器
+func (c *ShardedDiskCache) getCipher() *CacheCipher {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.cipher
+}
+
+// Set 设置缓存。数据先经过SetDiskWriteFilter设置的过滤函数，过滤后没有需要持久化的数据时不写入
+func (c *ShardedDiskCache) Set(key string, data []byte, ttl time.Duration) error {
+	if data = filterDiskWrite(key, data); data == nil {
+		return nil
+	}
+	if cipher := c.getCipher(); cipher != nil {
+		encrypted, err := cipher.Encrypt(data)
+		if err != nil {
+			return err
+		}
+		data = encrypted
 	}
 	
-	// 确保分片数是2的幂，便于使用掩码进行快速取模
-	shardCount = nextPowerOfTwoDisk(shardCount)
-	
-	return newShardedDiskCacheWithCount(baseDir, shardCount, maxSizeMB)
+	return c.store.Set(key, data, ttl)
 }
 
-// 获取下一个2的幂（磁盘缓存版本）
-func nextPowerOfTwoDisk(n int) int {
-	if n <= 1 {
-		return 1
+// Get 获取缓存
+func (c *ShardedDiskCache) Get(key string) ([]byte, bool, error) {
+	data, hit, err := c.store.Get(key)
+	if err != nil || !hit {
+		return data, hit, err
 	}
-	n--
-	n |= n >> 1
-	n |= n >> 2
-	n |= n >> 4
-	n |= n >> 8
-	n |= n >> 16
-	return n + 1
-}
-
-// 内部构造函数
-func newShardedDiskCacheWithCount(baseDir string, shardCount, maxSizeMB int) (*ShardedDiskCache, error) {
-	// 确保每个分片的大小合理
-	shardSize := maxSizeMB / shardCount
-	if shardSize < 1 {
-		shardSize = 1
+	
+	cipher := c.getCipher()
+	if cipher == nil {
+		// 未配置密钥时无法读取加密数据，视为未命中
+		if IsEncrypted(data) {
+			return nil, false, nil
+		}
+		return data, true, nil
 	}
 	
-	cache := &ShardedDiskCache{
-		baseDir:    baseDir,
-		shardCount: shardCount,
-		shardMask:  uint32(shardCount - 1), // 用于快速取模
-		shards:     make([]*DiskCache, shardCount),
-		maxSizeMB:  maxSizeMB,
+	plain, needsRewrite, err := cipher.Decrypt(data)
+	if err != nil {
+		// 密钥已更换且旧密钥未保留，丢弃无法解密的数据
+		c.store.Delete(key)
+		return nil, false, nil
 	}
 	
-	// 初始化每个分片
-	for i := 0; i < shardCount; i++ {
-		shardPath := filepath.Join(baseDir, fmt.Sprintf("shard_%d", i))
-		diskCache, err := NewDiskCache(shardPath, shardSize)
-		if err != nil {
-			return nil, err
+	// 明文或旧密钥加密的数据，使用当前密钥重新写入（按剩余有效期）
+	if needsRewrite {
+		if expiry, ok := c.store.Expiry(key); ok {
+			if ttl := time.Until(expiry); ttl > 0 {
+				c.Set(key, plain, ttl)
+			}
 		}
-		cache.shards[i] = diskCache
 	}
 	
-	return cache, nil
-}
-
-// 获取键对应的分片
-func (c *ShardedDiskCache) getShard(key string) *DiskCache {
-	// 计算哈希值决定分片
-	h := fnv.New32a()
-	h.Write([]byte(key))
-	shardIndex := h.Sum32() & c.shardMask // 使用掩码进行快速取模
-	return c.shards[shardIndex]
+	return plain, true, nil
 }
 
-// Set 设置缓存
-func (c *ShardedDiskCache) Set(key string, data []byte, ttl time.Duration) error {
-	shard := c.getShard(key)
-	return shard.Set(key, data, ttl)
-}
-
-// Get 获取缓存
-func (c *ShardedDiskCache) Get(key string) ([]byte, bool, error) {
-	shard := c.getShard(key)
-	return shard.Get(key)
+// RotateEncryption 使用当前密钥重新加密所有明文或旧密钥加密的缓存项，返回处理的缓存项数量
+func (c *ShardedDiskCache) RotateEncryption() int {
+	if c.getCipher() == nil {
+		return 0
+	}
+	
+	count := 0
+	for _, key := range c.store.Keys() {
+		// Get会在需要时自动重新加密
+		if _, hit, _ := c.Get(key); hit {
+			count++
+		}
+	}
+	return count
 }
 
 // Delete 删除缓存
 func (c *ShardedDiskCache) Delete(key string) error {
-	shard := c.getShard(key)
-	return shard.Delete(key)
+	return c.store.Delete(key)
 }
 
 // Has 检查缓存是否存在
 func (c *ShardedDiskCache) Has(key string) bool {
-	shard := c.getShard(key)
-	return shard.Has(key)
+	return c.store.Has(key)
 }
 
 // Clear 清空所有缓存
 func (c *ShardedDiskCache) Clear() error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	
-	var lastErr error
-	for _, shard := range c.shards {
-		if err := shard.Clear(); err != nil {
-			lastErr = err
-		}
-	}
-	
-	return lastErr
+	return c.store.Clear()
 } 
 
 // GetLastModified 获取缓存项的最后修改时间
 func (c *ShardedDiskCache) GetLastModified(key string) (time.Time, bool) {
-	shard := c.getShard(key)
-	return shard.GetLastModified(key)
+	return c.store.GetLastModified(key)
 }
 
-// cleanExpired 清理所有分片中的过期项
-func (c *ShardedDiskCache) cleanExpired() {
-	// 并行清理所有分片中的过期项
-	for _, shard := range c.shards {
-		go func(s *DiskCache) {
-			s.cleanExpired()
-		}(shard)
-	}
+// Expiry 获取缓存项的过期时间
+func (c *ShardedDiskCache) Expiry(key string) (time.Time, bool) {
+	return c.store.Expiry(key)
+}
+
+// Touch 修改缓存项的过期时间
+func (c *ShardedDiskCache) Touch(key string, expiry time.Time) error {
+	return c.store.Touch(key, expiry)
 }
 
-// CleanExpired 公开的清理方法，符合cleanupTarget接口
+// Stats 获取存储后端的容量统计
+func (c *ShardedDiskCache) Stats() StoreStats {
+	return c.store.Stats()
+}
+
+// CleanExpired 清理过期项，符合cleanupTarget接口
 func (c *ShardedDiskCache) CleanExpired() {
-	c.cleanExpired()
+	c.store.CleanExpired()
+}
+
+// Close 关闭存储后端，释放数据库文件锁
+func (c *ShardedDiskCache) Close() error {
+	return c.store.Close()
+}
+
+// Reopen 重新打开已关闭的存储后端
+func (c *ShardedDiskCache) Reopen() error {
+	return c.store.Reopen()
 }
 
 // StartCleanupTask 启动定期清理任务（修改为使用单例模式）
@@ -159,20 +177,3 @@ func (c *ShardedDiskCache) StartCleanupTask() {
 	registerForCleanup(c)
 	startGlobalCleanupTask()
 }
-
-// GetShards 获取所有分片（用于测试和调试）
-func (c *ShardedDiskCache) GetShards() []*DiskCache {
-	return c.shards
-}
-
-// GetShardIndex 获取指定键对应的分片索引（用于测试和调试）
-func (c *ShardedDiskCache) GetShardIndex(key string) int {
-	h := fnv.New32a()
-	h.Write([]byte(key))
-	if c.shardMask > 0 {
-		return int(h.Sum32() & c.shardMask)
-	} else {
-		// 兼容老版本的模运算
-		return int(h.Sum32()) % c.shardCount
-	}
-} 
\ No newline at end of file
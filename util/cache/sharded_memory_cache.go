@@ -213,6 +213,35 @@ func (c *ShardedMemoryCache) GetLastModified(key string) (time.Time, bool) {
 	return item.lastModified, true
 }
 
+// Size 获取缓存项数量和数据总大小
+func (c *ShardedMemoryCache) Size() (int, int64) {
+	var entries int
+	var size int64
+	for _, shard := range c.shards {
+		shard.mutex.RLock()
+		entries += len(shard.items)
+		shard.mutex.RUnlock()
+		size += atomic.LoadInt64(&shard.currSize)
+	}
+	return entries, size
+}
+
+// Extend 将未过期缓存项的过期时间延长到expiry，expiry早于当前过期时间时不修改
+func (c *ShardedMemoryCache) Extend(key string, expiry time.Time) bool {
+	shard := c.getShard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	
+	item, exists := shard.items[key]
+	if !exists || time.Now().After(item.expiry) {
+		return false
+	}
+	if expiry.After(item.expiry) {
+		item.expiry = expiry
+	}
+	return true
+}
+
 // 从指定分片中驱逐最久未使用的项（带磁盘备份）
 func (c *ShardedMemoryCache) evictFromShard(shard *memoryCacheShard) {
 	var oldestKey string
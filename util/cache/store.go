@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// 磁盘缓存存储后端
+const (
+	StoreFile   = "file"   // 每个缓存项一个数据文件和一个元数据文件
+	StoreBbolt  = "bbolt"  // 单个bbolt数据库文件
+	StoreBadger = "badger" // badger LSM数据库目录
+)
+
+// ErrStoreClosed 存储已关闭
+var ErrStoreClosed = errors.New("缓存存储已关闭")
+
+// Store 磁盘缓存的存储后端。存储的是加密和序列化之后的数据，
+// 加密、结构版本迁移等逻辑由上层的ShardedDiskCache负责
+type Store interface {
+	Set(key string, data []byte, ttl time.Duration) error
+	// Get 获取未过期的缓存项，不存在或已过期时返回未命中
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) error
+	Has(key string) bool
+	Clear() error
+	GetLastModified(key string) (time.Time, bool)
+	// Expiry 获取缓存项的过期时间
+	Expiry(key string) (time.Time, bool)
+	// Touch 修改缓存项的过期时间，不改变数据和最后修改时间
+	Touch(key string, expiry time.Time) error
+	// Keys 获取所有缓存键（可能包含尚未清理的过期项）
+	Keys() []string
+	CleanExpired()
+	// Stats 获取容量统计
+	Stats() StoreStats
+	// Close 关闭存储并释放文件锁，关闭后读取均未命中、写入返回ErrStoreClosed
+	Close() error
+	// Reopen 重新打开已关闭的存储
+	Reopen() error
+}
+
+// OpenStore 按后端类型打开磁盘缓存存储，path为缓存目录。
+// bbolt保存在目录下的cache.bolt文件，badger保存在目录下的badger子目录，与文件存储的分片目录互不影响
+func OpenStore(backend, path string, maxSizeMB int) (Store, error) {
+	switch backend {
+	case "", StoreFile:
+		return NewOptimizedFileStore(path, maxSizeMB)
+	case StoreBbolt:
+		return NewBboltStore(filepath.Join(path, "cache.bolt"), maxSizeMB)
+	case StoreBadger:
+		return NewBadgerStore(filepath.Join(path, "badger"), maxSizeMB)
+	default:
+		return nil, fmt.Errorf("不支持的缓存存储后端: %s", backend)
+	}
+}
+
+// CopyStore 将src中所有未过期的缓存项按剩余有效期写入dst，返回复制的缓存项数量
+func CopyStore(dst, src Store) (int, error) {
+	count := 0
+	for _, key := range src.Keys() {
+		expiry, ok := src.Expiry(key)
+		if !ok {
+			continue
+		}
+		ttl := time.Until(expiry)
+		if ttl <= 0 {
+			continue
+		}
+		data, hit, err := src.Get(key)
+		if err != nil || !hit {
+			continue
+		}
+		if err := dst.Set(key, data, ttl); err != nil {
+			return count, fmt.Errorf("写入 %s 失败: %v", key, err)
+		}
+		count++
+	}
+	return count, nil
+}
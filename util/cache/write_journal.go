@@ -0,0 +1,342 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// 预写日志文件名，位于缓存目录下
+	writeJournalFileName = "write.journal"
+
+	// 日志超过此大小且仍有未写入磁盘的记录时重写日志，只保留未写入的记录
+	writeJournalCompactSize = 64 * 1024 * 1024
+
+	// 单条记录的上限，读取时超过此长度视为日志损坏
+	writeJournalMaxRecordSize = 256 * 1024 * 1024
+
+	// 后台将日志同步到磁盘的间隔
+	writeJournalSyncInterval = time.Second
+)
+
+// journalRecord 预写日志中的一条记录，Data为序列化后的搜索结果
+type journalRecord struct {
+	Seq       uint64
+	Key       string
+	Data      []byte
+	ExpiresAt time.Time
+}
+
+// writeJournal 延迟批量写入的预写日志。进入缓冲区的操作先追加到日志，批量写入磁盘后标记完成，
+// 全部完成时清空日志。进程崩溃后启动时回放日志，恢复尚未写入磁盘的数据。
+//
+// 每条记录格式为：4字节长度 + 4字节CRC32 + gob编码的journalRecord，配置了缓存加密时gob编码的部分整体加密；
+// 读取到不完整或校验失败的记录时停止（崩溃时最后一条记录可能只写了一半）
+type writeJournal struct {
+	mu      sync.Mutex
+	path    string
+	cipher  *CacheCipher // 与磁盘缓存相同的静态加密器，为nil时明文记录
+	file    *os.File
+	size    int64
+	seq     uint64
+	pending map[string]uint64 // key -> 最新一条未写入磁盘的记录序号
+	dirty   bool              // 是否有未同步到磁盘的追加
+
+	// 上次运行遗留的记录是否已回放，回放前不清空日志
+	replayed bool
+}
+
+// openWriteJournal 打开（不存在时创建）缓存目录下的预写日志，cipher不为nil时加密记录
+func openWriteJournal(dir string, cipher *CacheCipher) (*writeJournal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, writeJournalFileName)
+
+	// 新记录的序号接在遗留记录之后，避免与遗留记录混淆
+	var maxSeq uint64
+	validSize, corrupt, err := readJournalFile(path, cipher, func(record *journalRecord) error {
+		if record.Seq > maxSeq {
+			maxSeq = record.Seq
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// 截掉崩溃时写了一半的记录，否则之后追加的记录无法读取
+	if corrupt {
+		fmt.Printf("[预写日志] 日志末尾存在不完整的记录，已忽略\n")
+		if err := file.Truncate(validSize); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &writeJournal{
+		path:     path,
+		cipher:   cipher,
+		file:     file,
+		size:     validSize,
+		seq:      maxSeq,
+		pending:  make(map[string]uint64),
+		replayed: validSize == 0,
+	}, nil
+}
+
+// encodeJournalRecord 将记录编码为带长度和校验和的字节，cipher不为nil时加密编码后的记录
+func encodeJournalRecord(record *journalRecord, cipher *CacheCipher) ([]byte, error) {
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(record); err != nil {
+		return nil, err
+	}
+	payload := encoded.Bytes()
+	if cipher != nil {
+		encrypted, err := cipher.Encrypt(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = encrypted
+	}
+	buf := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	return append(buf, payload...), nil
+}
+
+// append 追加一条记录并返回记录序号。整条记录一次写入，进程崩溃时不会丢失已返回的记录
+func (j *writeJournal) append(key string, data []byte, expiresAt time.Time) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return 0, errors.New("预写日志已关闭")
+	}
+	j.seq++
+	buf, err := encodeJournalRecord(&journalRecord{Seq: j.seq, Key: key, Data: data, ExpiresAt: expiresAt}, j.cipher)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := j.file.Write(buf); err != nil {
+		return 0, err
+	}
+	j.size += int64(len(buf))
+	j.dirty = true
+	j.pending[key] = j.seq
+	return j.seq, nil
+}
+
+// commit 标记操作已写入磁盘。同一个键只有最新的记录写入后才算完成，
+// 全部完成时清空日志，否则日志过大时重写为只包含未完成的记录
+func (j *writeJournal) commit(operations []*CacheOperation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	for _, op := range operations {
+		if op.journalSeq != 0 && j.pending[op.Key] == op.journalSeq {
+			delete(j.pending, op.Key)
+		}
+	}
+	if !j.replayed {
+		return nil
+	}
+	if len(j.pending) == 0 {
+		return j.truncateLocked()
+	}
+	if j.size > writeJournalCompactSize {
+		return j.compactLocked()
+	}
+	return nil
+}
+
+// truncateLocked 清空日志，调用方需持有j.mu
+func (j *writeJournal) truncateLocked() error {
+	if j.size == 0 {
+		return nil
+	}
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	j.size = 0
+	j.dirty = true
+	return nil
+}
+
+// compactLocked 将仍未完成的记录写入临时文件后替换日志，调用方需持有j.mu
+func (j *writeJournal) compactLocked() error {
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var size int64
+	_, _, err = readJournalFile(j.path, j.cipher, func(record *journalRecord) error {
+		if j.pending[record.Key] != record.Seq {
+			return nil
+		}
+		buf, err := encodeJournalRecord(record, j.cipher)
+		if err != nil {
+			return err
+		}
+		n, err := tmp.Write(buf)
+		size += int64(n)
+		return err
+	})
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.file.Close()
+	j.file = file
+	j.size = size
+	j.dirty = false
+	return nil
+}
+
+// sync 将已追加的记录同步到磁盘，防止断电丢失
+func (j *writeJournal) sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil || !j.dirty {
+		return nil
+	}
+	j.dirty = false
+	return j.file.Sync()
+}
+
+// close 同步并关闭日志，之后的追加返回错误
+func (j *writeJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Sync()
+	if closeErr := j.file.Close(); err == nil {
+		err = closeErr
+	}
+	j.file = nil
+	return err
+}
+
+// replay 读取日志中的记录，每个键只回放最新一条未过期的记录，全部回放成功后清空日志。
+// 回放期间已追加的新记录（仍在缓冲区中）会被保留
+func (j *writeJournal) replay(apply func(key string, data []byte, ttl time.Duration) error) (int, error) {
+	latest := make(map[string]*journalRecord)
+	var order []string
+	_, _, err := readJournalFile(j.path, j.cipher, func(record *journalRecord) error {
+		if _, ok := latest[record.Key]; !ok {
+			order = append(order, record.Key)
+		}
+		latest[record.Key] = record
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	replayed := 0
+	for _, key := range order {
+		record := latest[key]
+		ttl := record.ExpiresAt.Sub(now)
+		if ttl <= 0 {
+			continue
+		}
+		if err := apply(record.Key, record.Data, ttl); err != nil {
+			return replayed, fmt.Errorf("回放 %s 失败: %v", record.Key, err)
+		}
+		replayed++
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.replayed = true
+	if j.file == nil {
+		return replayed, nil
+	}
+	if len(j.pending) == 0 {
+		return replayed, j.truncateLocked()
+	}
+	return replayed, j.compactLocked()
+}
+
+// readJournalFile 依次读取日志文件中的记录，返回完整记录的总长度。cipher不为nil时解密记录，
+// 启用加密前留下的明文记录原样读取。遇到不完整、校验失败或无法解密的记录时停止并返回corrupt为true
+func readJournalFile(path string, cipher *CacheCipher, fn func(record *journalRecord) error) (validSize int64, corrupt bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return validSize, err != io.EOF, nil
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		if length == 0 || length > writeJournalMaxRecordSize {
+			return validSize, true, nil
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return validSize, true, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+			return validSize, true, nil
+		}
+		encoded := payload
+		if cipher != nil {
+			if encoded, _, err = cipher.Decrypt(payload); err != nil {
+				return validSize, true, nil
+			}
+		}
+		var record journalRecord
+		if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&record); err != nil {
+			return validSize, true, nil
+		}
+		if err := fn(&record); err != nil {
+			return validSize, false, err
+		}
+		validSize += int64(8 + length)
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// replayJournal 重新打开日志并回放，返回各键回放的数据
+func replayJournal(t *testing.T, dir string, cipher *CacheCipher) map[string][]byte {
+	t.Helper()
+	journal, err := openWriteJournal(dir, cipher)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	defer journal.close()
+	replayed := make(map[string][]byte)
+	if _, err := journal.replay(func(key string, data []byte, ttl time.Duration) error {
+		replayed[key] = data
+		return nil
+	}); err != nil {
+		t.Fatalf("回放预写日志失败: %v", err)
+	}
+	return replayed
+}
+
+// 配置了缓存加密时日志文件中不出现明文的键和数据，使用同一密钥可以回放
+func TestWriteJournalEncryptsRecords(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := NewCacheCipher("journal-test-secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, data := "plugin:流浪地球", []byte("https://pan.quark.cn/s/secretlink")
+
+	journal, err := openWriteJournal(dir, cipher)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	if _, err := journal.append(key, data, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("追加记录失败: %v", err)
+	}
+	journal.close()
+
+	raw, err := os.ReadFile(filepath.Join(dir, writeJournalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, data) || bytes.Contains(raw, []byte(key)) {
+		t.Fatalf("加密后的预写日志中仍有明文")
+	}
+
+	if got := replayJournal(t, dir, cipher)[key]; !bytes.Equal(got, data) {
+		t.Fatalf("回放的数据不一致: %q", got)
+	}
+}
+
+// 启用加密前留下的明文日志在启用加密后仍能回放
+func TestWriteJournalReplaysPlaintextAfterEnablingEncryption(t *testing.T) {
+	dir := t.TempDir()
+	key, data := "plugin:三体", []byte("https://pan.baidu.com/s/1plain")
+
+	journal, err := openWriteJournal(dir, nil)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	if _, err := journal.append(key, data, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("追加记录失败: %v", err)
+	}
+	journal.close()
+
+	cipher, err := NewCacheCipher("journal-test-secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := replayJournal(t, dir, cipher)[key]; !bytes.Equal(got, data) {
+		t.Fatalf("回放的数据不一致: %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TG频道引用匹配正则
+var (
+	// ChannelLinkPattern 匹配 t.me/频道名 形式的频道链接
+	ChannelLinkPattern = MustRegisterPattern("tg_channel_link", `(?i)(?:https?://)?(?:t|telegram)\.me/(?:s/)?([a-zA-Z][a-zA-Z0-9_]{4,31})`)
+	// ChannelAtPattern 匹配 @频道名 形式的频道提及
+	ChannelAtPattern = MustRegisterPattern("tg_channel_at", `(?:^|[^a-zA-Z0-9_@.])@([a-zA-Z][a-zA-Z0-9_]{4,31})`)
+)
+
+// t.me下不是频道名的保留路径
+var reservedChannelPaths = map[string]bool{
+	"joinchat":    true,
+	"addstickers": true,
+	"addemoji":    true,
+	"addlist":     true,
+	"share":       true,
+	"proxy":       true,
+	"socks":       true,
+	"setlanguage": true,
+	"iv":          true,
+}
+
+// NormalizeChannelName 规范化频道名（小写），非法或保留名称返回空字符串
+func NormalizeChannelName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 5 || len(name) > 32 || reservedChannelPaths[name] || strings.HasSuffix(name, "bot") {
+		return ""
+	}
+	return name
+}
+
+// extractChannelMentions 提取消息的转发来源频道及正文中引用的其他频道（不含消息所在频道）
+func extractChannelMentions(messageDiv, messageTextElem *goquery.Selection, messageText, channel string) []string {
+	self := strings.ToLower(channel)
+	seen := make(map[string]bool)
+	var mentions []string
+	add := func(name string) {
+		name = NormalizeChannelName(name)
+		if name == "" || name == self || seen[name] {
+			return
+		}
+		seen[name] = true
+		mentions = append(mentions, name)
+	}
+
+	// 转发来源
+	if href, ok := messageDiv.Find(".tgme_widget_message_forwarded_from_name").Attr("href"); ok {
+		if m := ChannelLinkPattern.FindStringSubmatch(href); len(m) > 1 {
+			add(m[1])
+		}
+	}
+
+	// 正文中的频道链接（包括a标签中隐藏的链接）
+	messageTextElem.Find("a").Each(func(i int, a *goquery.Selection) {
+		if href, ok := a.Attr("href"); ok {
+			if m := ChannelLinkPattern.FindStringSubmatch(href); len(m) > 1 {
+				add(m[1])
+			}
+		}
+	})
+	for _, m := range ChannelLinkPattern.FindAllStringSubmatch(messageText, -1) {
+		add(m[1])
+	}
+
+	// @提及
+	for _, m := range ChannelAtPattern.FindAllStringSubmatch(messageText, -1) {
+		add(m[1])
+	}
+
+	return mentions
+}
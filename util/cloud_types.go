@@ -0,0 +1,51 @@
+package util
+
+import "strings"
+
+// CloudType 支持的链接类型
+type CloudType struct {
+	Type string `json:"type"` // 类型标识，与结果中链接的type、请求的cloud_types参数一致
+	Name string `json:"name"` // 显示名称
+}
+
+// cloudTypes 支持的链接类型，按展示顺序排列。GetLinkType、磁力和电驴链接的识别结果均在此列表中
+var cloudTypes = []CloudType{
+	{Type: "baidu", Name: "百度网盘"},
+	{Type: "aliyun", Name: "阿里云盘"},
+	{Type: "quark", Name: "夸克网盘"},
+	{Type: "tianyi", Name: "天翼云盘"},
+	{Type: "uc", Name: "UC网盘"},
+	{Type: "mobile", Name: "移动云盘"},
+	{Type: "115", Name: "115网盘"},
+	{Type: "pikpak", Name: "PikPak"},
+	{Type: "xunlei", Name: "迅雷网盘"},
+	{Type: "123", Name: "123网盘"},
+	{Type: "magnet", Name: "磁力链接"},
+	{Type: "ed2k", Name: "电驴链接"},
+	{Type: "others", Name: "其他"},
+}
+
+// CloudTypes 获取支持的链接类型列表
+func CloudTypes() []CloudType {
+	return append([]CloudType(nil), cloudTypes...)
+}
+
+// CloudTypeNames 获取支持的链接类型标识，按展示顺序排列
+func CloudTypeNames() []string {
+	names := make([]string, len(cloudTypes))
+	for i, t := range cloudTypes {
+		names[i] = t.Type
+	}
+	return names
+}
+
+// IsKnownCloudType 判断是否为支持的链接类型（不区分大小写）
+func IsKnownCloudType(cloudType string) bool {
+	cloudType = strings.ToLower(cloudType)
+	for _, t := range cloudTypes {
+		if t.Type == cloudType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+)
+
+// 连接预算相关默认值
+const (
+	// 未配置预算且无法获取文件描述符上限时使用的默认预算
+	defaultConnBudget = 1024
+	// 自动计算时预算的下限
+	minAutoConnBudget = 256
+	// 每个连接池至少分配的连接数
+	minPoolQuota = 4
+	// 未指定连接数的传输层按此值申请配额（与http.DefaultTransport一致）
+	defaultPoolRequest = 100
+)
+
+// connPool 按名称（通常为插件名）划分的连接池配额
+type connPool struct {
+	name       string
+	requested  int
+	transports []*PooledTransport // 使用该配额的传输层，用于预热连接
+
+	once  sync.Once
+	quota int64 // 首次使用时确定，之前为0
+	sem   chan struct{}
+
+	open  int64
+	dials int64
+	waits int64
+}
+
+// PooledTransport 受全局连接预算约束的HTTP传输层。
+// 同名的传输层共享同一份连接配额，底层http.Transport在首次请求时按配额调整连接池参数
+type PooledTransport struct {
+	pool *connPool
+	base *http.Transport
+	once sync.Once
+}
+
+var (
+	connPoolsMu    sync.Mutex
+	connPools      = make(map[string]*connPool)
+	totalRequested int
+)
+
+// NewPooledTransport 创建受连接预算约束的传输层。
+// base为期望的传输层配置（可为nil，使用默认配置），其连接数设置作为配额申请值；
+// 实际配额在首次请求时按各连接池的申请值从全局预算中按比例分配
+func NewPooledTransport(name string, base *http.Transport) *PooledTransport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	requested := base.MaxIdleConns
+	if base.MaxConnsPerHost > requested {
+		requested = base.MaxConnsPerHost
+	}
+	if requested <= 0 {
+		requested = defaultPoolRequest
+	}
+
+	connPoolsMu.Lock()
+	pool, ok := connPools[name]
+	if !ok {
+		pool = &connPool{name: name}
+		connPools[name] = pool
+	}
+	if requested > pool.requested {
+		totalRequested += requested - pool.requested
+		pool.requested = requested
+	}
+	t := &PooledTransport{pool: pool, base: base}
+	pool.transports = append(pool.transports, t)
+	connPoolsMu.Unlock()
+
+	return t
+}
+
+// NewPooledClient 创建使用受约束传输层的HTTP客户端
+func NewPooledClient(name string, base *http.Transport, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewPooledTransport(name, base),
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *PooledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(t.setup)
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections 关闭空闲连接
+func (t *PooledTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
+
+// setup 按配额调整底层传输层的连接池参数，并接管拨号以统计和限制连接数
+func (t *PooledTransport) setup() {
+	pool := t.pool
+	pool.init()
+
+	quota := int(atomic.LoadInt64(&pool.quota))
+	if t.base.MaxIdleConns <= 0 || t.base.MaxIdleConns > quota {
+		t.base.MaxIdleConns = quota
+	}
+	if t.base.MaxIdleConnsPerHost > quota {
+		t.base.MaxIdleConnsPerHost = quota
+	}
+	if t.base.MaxConnsPerHost <= 0 || t.base.MaxConnsPerHost > quota {
+		t.base.MaxConnsPerHost = quota
+	}
+
+	dial := t.base.DialContext
+	if dial == nil && t.base.Dial != nil {
+		legacyDial := t.base.Dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return legacyDial(network, addr)
+		}
+	}
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	// 配置了源地址池时按目标主机绑定出站源地址
+	if sources := getSourceAddrPool(); sources != nil {
+		dial = sources.dialContext
+	}
+	t.base.Dial = nil
+	t.base.DialContext = pool.wrapDial(dial)
+}
+
+// init 从全局预算中计算本连接池的配额（首次使用时执行一次）
+func (p *connPool) init() {
+	p.once.Do(func() {
+		connPoolsMu.Lock()
+		requested, total := p.requested, totalRequested
+		connPoolsMu.Unlock()
+
+		quota := requested
+		if budget := ConnBudget(); total > budget {
+			quota = budget * requested / total
+		}
+		if quota < minPoolQuota {
+			quota = minPoolQuota
+		}
+		if quota > requested {
+			quota = requested
+		}
+
+		p.sem = make(chan struct{}, quota)
+		atomic.StoreInt64(&p.quota, int64(quota))
+	})
+}
+
+// wrapDial 包装拨号函数：连接数达到配额时先关闭空闲连接，仍没有配额时等待使用中的连接关闭
+func (p *connPool) wrapDial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		select {
+		case p.sem <- struct{}{}:
+		default:
+			atomic.AddInt64(&p.waits, 1)
+			// 配额可能被其他主机的空闲keep-alive连接占用，不关闭时要等到IdleConnTimeout才会释放
+			p.closeIdleConnections()
+			select {
+			case p.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			<-p.sem
+			return nil, err
+		}
+		atomic.AddInt64(&p.dials, 1)
+		atomic.AddInt64(&p.open, 1)
+		return &pooledConn{Conn: conn, pool: p}, nil
+	}
+}
+
+// closeIdleConnections 关闭使用本配额的所有传输层的空闲连接，归还其占用的配额
+func (p *connPool) closeIdleConnections() {
+	connPoolsMu.Lock()
+	transports := append([]*PooledTransport(nil), p.transports...)
+	connPoolsMu.Unlock()
+	for _, t := range transports {
+		t.CloseIdleConnections()
+	}
+}
+
+// pooledConn 关闭时归还配额的连接
+type pooledConn struct {
+	net.Conn
+	pool   *connPool
+	closed int32
+}
+
+// Close 关闭连接并归还配额
+func (c *pooledConn) Close() error {
+	err := c.Conn.Close()
+	if atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		atomic.AddInt64(&c.pool.open, -1)
+		<-c.pool.sem
+	}
+	return err
+}
+
+// ConnBudget 返回全局出站连接预算：优先使用HTTP_CONN_BUDGET配置，
+// 否则取进程文件描述符上限的一半
+func ConnBudget() int {
+	if config.AppConfig != nil && config.AppConfig.HTTPConnBudget > 0 {
+		return config.AppConfig.HTTPConnBudget
+	}
+	if limit := FDLimit(); limit > 0 {
+		budget := limit / 2
+		if budget < minAutoConnBudget {
+			budget = minAutoConnBudget
+		}
+		return budget
+	}
+	return defaultConnBudget
+}
+
+// ConnPoolStats 单个连接池的统计信息
+type ConnPoolStats struct {
+	Name      string `json:"name"`
+	Requested int    `json:"requested"`       // 申请的连接数
+	Quota     int    `json:"quota,omitempty"` // 实际分配的配额，未使用过的连接池为0
+	Open      int64  `json:"open"`            // 当前打开的连接数
+	Dials     int64  `json:"dials"`           // 累计建立的连接数
+	Waits     int64  `json:"waits"`           // 因配额用尽而关闭空闲连接或等待的次数
+}
+
+// ConnStats 全局连接统计
+type ConnStats struct {
+	Budget  int               `json:"budget"`   // 全局出站连接预算
+	Open    int64             `json:"open"`     // 所有连接池当前打开的连接数
+	FDOpen  int               `json:"fd_open"`  // 进程当前打开的文件描述符数，无法获取时为-1
+	FDLimit int               `json:"fd_limit"` // 进程文件描述符上限，无法获取时为-1
+	Pools   []ConnPoolStats   `json:"pools"`
+	Sources []SourceAddrStats `json:"sources,omitempty"`     // 出站源地址的使用情况，未配置源地址池时为空
+	Hosts   []HostLimitStats  `json:"host_limits,omitempty"` // 插件请求按主机组的限流情况
+
+	ResponseCache ResponseCacheStats `json:"response_cache"` // 插件发现类请求的响应缓存
+}
+
+// GetConnStats 获取连接池与文件描述符使用情况
+func GetConnStats() ConnStats {
+	stats := ConnStats{
+		Budget:  ConnBudget(),
+		FDOpen:  OpenFDCount(),
+		FDLimit: FDLimit(),
+	}
+
+	connPoolsMu.Lock()
+	stats.Pools = make([]ConnPoolStats, 0, len(connPools))
+	for _, p := range connPools {
+		ps := ConnPoolStats{
+			Name:      p.name,
+			Requested: p.requested,
+			Quota:     int(atomic.LoadInt64(&p.quota)),
+			Open:      atomic.LoadInt64(&p.open),
+			Dials:     atomic.LoadInt64(&p.dials),
+			Waits:     atomic.LoadInt64(&p.waits),
+		}
+		stats.Open += ps.Open
+		stats.Pools = append(stats.Pools, ps)
+	}
+	connPoolsMu.Unlock()
+	if sources := getSourceAddrPool(); sources != nil {
+		stats.Sources = sources.stats()
+	}
+	stats.Hosts = GetHostLimitStats()
+	stats.ResponseCache = GetResponseCacheStats()
+	sort.Slice(stats.Pools, func(i, j int) bool {
+		if stats.Pools[i].Open != stats.Pools[j].Open {
+			return stats.Pools[i].Open > stats.Pools[j].Open
+		}
+		return stats.Pools[i].Name < stats.Pools[j].Name
+	})
+	return stats
+}
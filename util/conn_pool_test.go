@@ -0,0 +1,48 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 依次请求超过配额个数的不同主机：之前主机留下的空闲连接不应阻塞到新主机的拨号
+func TestPooledTransportDialsMoreHostsThanQuota(t *testing.T) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConns = minPoolQuota
+	base.IdleConnTimeout = time.Minute
+	client := NewPooledClient(fmt.Sprintf("conn-pool-test-%d", time.Now().UnixNano()), base, 0)
+
+	const hosts = minPoolQuota * 2
+	for i := 0; i < hosts; i++ {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			cancel()
+			t.Fatalf("请求第%d个主机失败（配额 %d）: %v", i+1, minPoolQuota, err)
+		}
+		// 读完响应体，连接作为空闲连接留在连接池中
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		cancel()
+	}
+
+	pool := client.Transport.(*PooledTransport).pool
+	if quota := atomic.LoadInt64(&pool.quota); quota != minPoolQuota {
+		t.Fatalf("配额应为 %d，实际 %d", minPoolQuota, quota)
+	}
+	if dials := atomic.LoadInt64(&pool.dials); dials != hosts {
+		t.Fatalf("应建立 %d 个连接，实际 %d", hosts, dials)
+	}
+}
@@ -0,0 +1,315 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+	jsonutil "pansou/util/json"
+)
+
+// storedCookie 落盘的Cookie
+type storedCookie struct {
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires"` // 零值表示会话Cookie，同样持久化以跨重启保持会话
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+}
+
+// PersistentCookieJar 插件的持久化Cookie容器。
+// 未在COOKIE_JAR_PLUGINS中启用的插件不保存也不发送Cookie，行为与未设置Jar的客户端一致
+type PersistentCookieJar struct {
+	name    string
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]storedCookie // 键为"域名|路径|名称"
+	loaded  bool
+	dirty   bool
+}
+
+// 插件Cookie容器注册表
+var (
+	cookieJars     = make(map[string]*PersistentCookieJar)
+	cookieJarsLock sync.Mutex
+)
+
+// PluginCookieJar 获取插件的持久化Cookie容器，同一插件的所有客户端共享
+func PluginCookieJar(name string) *PersistentCookieJar {
+	cookieJarsLock.Lock()
+	defer cookieJarsLock.Unlock()
+	if jar, ok := cookieJars[name]; ok {
+		return jar
+	}
+	jar := &PersistentCookieJar{name: name, cookies: make(map[string]storedCookie)}
+	jar.jar, _ = cookiejar.New(nil)
+	cookieJars[name] = jar
+	return jar
+}
+
+// LoadCookieJars 启动时从缓存目录加载已启用插件的Cookie
+func LoadCookieJars() {
+	for _, jar := range registeredCookieJars() {
+		if !jar.enabled() {
+			continue
+		}
+		if err := jar.load(); err != nil {
+			fmt.Printf("[Cookie] 插件 %s 的Cookie加载失败: %v\n", jar.name, err)
+		}
+	}
+}
+
+// SaveCookieJars 将有变化的Cookie写入缓存目录，关闭服务时调用
+func SaveCookieJars() error {
+	var errs []error
+	for _, jar := range registeredCookieJars() {
+		if err := jar.Save(); err != nil {
+			errs = append(errs, fmt.Errorf("插件 %s: %v", jar.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// registeredCookieJars 按插件名排序返回已创建的Cookie容器
+func registeredCookieJars() []*PersistentCookieJar {
+	cookieJarsLock.Lock()
+	defer cookieJarsLock.Unlock()
+	jars := make([]*PersistentCookieJar, 0, len(cookieJars))
+	for _, jar := range cookieJars {
+		jars = append(jars, jar)
+	}
+	sort.Slice(jars, func(i, j int) bool { return jars[i].name < jars[j].name })
+	return jars
+}
+
+// enabled 插件是否启用了持久化Cookie
+func (j *PersistentCookieJar) enabled() bool {
+	if config.AppConfig == nil {
+		return false
+	}
+	for _, name := range config.AppConfig.CookieJarPlugins {
+		if name == "*" || name == j.name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCookies 实现http.CookieJar接口
+func (j *PersistentCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if !j.enabled() || len(cookies) == 0 {
+		return
+	}
+	j.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range cookies {
+		key := cookieKey(u, c.Domain, c.Path, c.Name)
+
+		expires := c.Expires
+		if c.MaxAge > 0 {
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		if c.MaxAge < 0 || (!expires.IsZero() && !expires.After(now)) {
+			delete(j.cookies, key)
+		} else {
+			j.cookies[key] = storedCookie{
+				URL:      u.Scheme + "://" + u.Host + "/",
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				Expires:  expires,
+				Secure:   c.Secure,
+				HttpOnly: c.HttpOnly,
+			}
+		}
+		j.dirty = true
+	}
+}
+
+// Cookies 实现http.CookieJar接口
+func (j *PersistentCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	if !j.enabled() {
+		return nil
+	}
+	return j.jar.Cookies(u)
+}
+
+// Save 将Cookie写入缓存目录，未变化时不写入。设置COOKIE_JAR_KEY时加密保存
+func (j *PersistentCookieJar) Save() error {
+	j.mu.Lock()
+	if !j.dirty {
+		j.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	cookies := make([]storedCookie, 0, len(j.cookies))
+	for key, c := range j.cookies {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		cookies = append(cookies, c)
+	}
+	j.dirty = false
+	j.mu.Unlock()
+
+	sort.Slice(cookies, func(a, b int) bool {
+		if cookies[a].URL != cookies[b].URL {
+			return cookies[a].URL < cookies[b].URL
+		}
+		return cookies[a].Name < cookies[b].Name
+	})
+	data, err := jsonutil.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("Cookie序列化失败: %v", err)
+	}
+
+	file, key := cookieJarFile(j.name)
+	if key != nil {
+		if data, err = encryptCookieData(key, data); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// load 从缓存目录读取Cookie并写入容器，已过期的Cookie被丢弃
+func (j *PersistentCookieJar) load() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.loaded {
+		return nil
+	}
+	j.loaded = true
+
+	file, key := cookieJarFile(j.name)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if data, err = decryptCookieData(key, data); err != nil {
+			return err
+		}
+	}
+	var cookies []storedCookie
+	if err := jsonutil.Unmarshal(data, &cookies); err != nil {
+		return fmt.Errorf("Cookie文件格式无效: %v", err)
+	}
+
+	now := time.Now()
+	for _, c := range cookies {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			continue
+		}
+		j.jar.SetCookies(u, []*http.Cookie{{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}})
+		j.cookies[cookieKey(u, c.Domain, c.Path, c.Name)] = c
+	}
+	return nil
+}
+
+// cookieKey 返回Cookie在容器中的唯一键，未指定域名和路径时按来源主机和根路径计算
+func cookieKey(u *url.URL, domain, path, name string) string {
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	if path == "" {
+		path = "/"
+	}
+	return domain + "|" + path + "|" + name
+}
+
+// cookieJarFile 返回插件Cookie文件路径和加密密钥，未设置COOKIE_JAR_KEY时密钥为nil。
+// 加密文件与明文文件使用不同扩展名，切换加密设置后不会误读旧文件
+func cookieJarFile(name string) (string, []byte) {
+	dir := "./cache"
+	var secret string
+	if config.AppConfig != nil {
+		dir = config.AppConfig.CachePath
+		secret = config.AppConfig.CookieJarKey
+	}
+	file := filepath.Join(dir, "cookies", name+".json")
+	if secret == "" {
+		return file, nil
+	}
+	key := sha256.Sum256([]byte(secret))
+	return file + ".enc", key[:]
+}
+
+// encryptCookieData 使用AES-256-GCM加密，输出为nonce与密文的拼接
+func encryptCookieData(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newCookieCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptCookieData 解密encryptCookieData的输出
+func decryptCookieData(key, data []byte) ([]byte, error) {
+	gcm, err := newCookieCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("Cookie文件已损坏")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("Cookie文件解密失败，请检查COOKIE_JAR_KEY")
+	}
+	return plaintext, nil
+}
+
+func newCookieCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
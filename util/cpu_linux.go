@@ -0,0 +1,15 @@
+package util
+
+import (
+	"syscall"
+	"time"
+)
+
+// ProcessCPUTime 返回进程累计占用的CPU时间（用户态+内核态），无法获取时返回-1
+func ProcessCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return -1
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
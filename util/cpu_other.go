@@ -0,0 +1,10 @@
+//go:build !linux
+
+package util
+
+import "time"
+
+// ProcessCPUTime 返回进程累计占用的CPU时间，当前平台不支持时返回-1
+func ProcessCPUTime() time.Duration {
+	return -1
+}
@@ -0,0 +1,286 @@
+package util
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+// 未加载配置时的失效域名判定参数
+const (
+	defaultDeadDomainMinFailures   = 5
+	defaultDeadDomainAfter         = 10 * time.Minute
+	defaultDeadDomainRetryInterval = 5 * time.Minute
+)
+
+// domainHealth 单个主机组的连续失败记录
+type domainHealth struct {
+	failures     int       // 上次成功之后的失败次数
+	firstFailure time.Time // 上次成功之后的第一次失败时间
+	lastFailure  time.Time
+	lastError    string
+	deadSince    time.Time // 判定为失效的时间，未失效时为零值
+}
+
+// pluginDomains 插件请求过的主机组及降级状态
+type pluginDomains struct {
+	domains       map[string]bool
+	degradedSince time.Time // 所有主机组都失效的时间，未降级时为零值
+	nextRetry     time.Time // 降级期间下一次放行搜索以探测恢复的时间
+}
+
+var (
+	domainHealthMu  sync.Mutex
+	domainHealths   = make(map[string]*domainHealth)
+	pluginDomainMap = make(map[string]*pluginDomains)
+)
+
+// deadDomainSettings 返回判定失效的最少失败次数、持续时长和降级插件的重试间隔，最少失败次数为0表示不检测
+func deadDomainSettings() (int, time.Duration, time.Duration) {
+	if config.AppConfig == nil {
+		return defaultDeadDomainMinFailures, defaultDeadDomainAfter, defaultDeadDomainRetryInterval
+	}
+	return config.AppConfig.DeadDomainMinFailures, config.AppConfig.DeadDomainAfter, config.AppConfig.DeadDomainRetryInterval
+}
+
+// DomainHealthTransport 记录插件请求各主机组的结果：DNS解析失败、连接失败、超时和5xx响应计为失败，
+// 其余响应计为成功。主机组在一段时间内持续失败时判定为失效，依赖的主机组全部失效的插件被降级
+type DomainHealthTransport struct {
+	name string
+	base http.RoundTripper
+}
+
+// NewDomainHealthTransport 创建记录主机组健康状态的传输层，name为插件名
+func NewDomainHealthTransport(name string, base http.RoundTripper) *DomainHealthTransport {
+	return &DomainHealthTransport{name: name, base: base}
+}
+
+// RoundTrip 发出请求并记录结果
+func (t *DomainHealthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if minFailures, _, _ := deadDomainSettings(); minFailures > 0 {
+		if domain := hostLimitKey(req.URL.Hostname()); domain != "" {
+			switch {
+			case err != nil:
+				if isDomainFailure(err) {
+					recordDomainResult(t.name, domain, err.Error())
+				}
+			case resp.StatusCode >= 500:
+				recordDomainResult(t.name, domain, resp.Status)
+			default:
+				recordDomainResult(t.name, domain, "")
+			}
+		}
+	}
+	return resp, err
+}
+
+// CloseIdleConnections 关闭底层传输层的空闲连接
+func (t *DomainHealthTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// isDomainFailure 判断请求错误是否说明目标站点不可用：DNS解析失败、证书错误和可重试的网络错误，
+// 调用方取消不计入
+func isDomainFailure(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return true
+	}
+	return IsRetryableError(err)
+}
+
+// recordDomainResult 记录一次请求结果，failure为空表示成功。主机组失效或恢复时更新依赖它的插件的降级状态
+func recordDomainResult(pluginName, domain, failure string) {
+	minFailures, after, retryInterval := deadDomainSettings()
+	now := time.Now()
+
+	domainHealthMu.Lock()
+	defer domainHealthMu.Unlock()
+
+	deps, ok := pluginDomainMap[pluginName]
+	if !ok {
+		deps = &pluginDomains{domains: make(map[string]bool)}
+		pluginDomainMap[pluginName] = deps
+	}
+	// 插件开始请求新的主机组时降级状态可能改变
+	changed := !deps.domains[domain]
+	deps.domains[domain] = true
+
+	health, ok := domainHealths[domain]
+	if !ok {
+		health = &domainHealth{}
+		domainHealths[domain] = health
+	}
+	if failure == "" {
+		if !health.deadSince.IsZero() {
+			fmt.Printf("[失效域名] %s 已恢复\n", domain)
+			changed = true
+		}
+		*health = domainHealth{}
+		if changed {
+			updatePluginDegradation(now, retryInterval)
+		}
+		return
+	}
+
+	if health.failures == 0 {
+		health.firstFailure = now
+	}
+	health.failures++
+	health.lastFailure = now
+	health.lastError = failure
+	if health.deadSince.IsZero() && health.failures >= minFailures && now.Sub(health.firstFailure) >= after {
+		health.deadSince = now
+		fmt.Printf("[失效域名] %s 已连续失败%d次（%v），判定为失效: %s\n", domain, health.failures, now.Sub(health.firstFailure).Round(time.Second), failure)
+		changed = true
+	}
+	if changed {
+		updatePluginDegradation(now, retryInterval)
+	}
+}
+
+// updatePluginDegradation 按主机组的失效状态更新各插件的降级状态（调用方需持有锁）
+func updatePluginDegradation(now time.Time, retryInterval time.Duration) {
+	for name, deps := range pluginDomainMap {
+		degraded := pluginDegraded(deps)
+		switch {
+		case degraded && deps.degradedSince.IsZero():
+			deps.degradedSince = now
+			deps.nextRetry = now.Add(retryInterval)
+			fmt.Printf("[插件降级] %s 依赖的站点均已失效，暂停搜索，每%v重试一次\n", name, retryInterval)
+		case !degraded && !deps.degradedSince.IsZero():
+			deps.degradedSince = time.Time{}
+			deps.nextRetry = time.Time{}
+			fmt.Printf("[插件降级] %s 依赖的站点已恢复，重新参与搜索\n", name)
+		}
+	}
+}
+
+// pluginDegraded 插件请求过的主机组是否全部失效（调用方需持有锁）
+func pluginDegraded(deps *pluginDomains) bool {
+	if len(deps.domains) == 0 {
+		return false
+	}
+	for domain := range deps.domains {
+		health, ok := domainHealths[domain]
+		if !ok || health.deadSince.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPluginDegraded 插件是否处于降级状态（请求过的主机组全部失效），降级的插件不参与搜索。
+// 降级期间每隔重试间隔放行一次搜索，请求成功时主机组恢复，插件随之恢复
+func IsPluginDegraded(name string) bool {
+	_, _, retryInterval := deadDomainSettings()
+	now := time.Now()
+
+	domainHealthMu.Lock()
+	defer domainHealthMu.Unlock()
+	deps, ok := pluginDomainMap[name]
+	if !ok || deps.degradedSince.IsZero() {
+		return false
+	}
+	if now.Before(deps.nextRetry) {
+		return true
+	}
+	deps.nextRetry = now.Add(retryInterval)
+	return false
+}
+
+// DeadDomainStatus 失效主机组的状态
+type DeadDomainStatus struct {
+	Domain       string    `json:"domain"`
+	Failures     int       `json:"failures"`      // 上次成功之后的失败次数
+	FirstFailure time.Time `json:"first_failure"` // 上次成功之后的第一次失败时间
+	LastFailure  time.Time `json:"last_failure"`
+	LastError    string    `json:"last_error"`
+	DeadSince    time.Time `json:"dead_since"`
+	Plugins      []string  `json:"plugins"` // 请求过该主机组的插件
+}
+
+// DegradedPluginStatus 降级插件的状态
+type DegradedPluginStatus struct {
+	Name          string    `json:"name"`
+	Domains       []string  `json:"domains"` // 插件请求过的主机组（均已失效）
+	DegradedSince time.Time `json:"degraded_since"`
+	NextRetry     time.Time `json:"next_retry"`
+}
+
+// GetDeadDomains 获取已判定为失效的主机组，按失效时间排序
+func GetDeadDomains() []DeadDomainStatus {
+	domainHealthMu.Lock()
+	defer domainHealthMu.Unlock()
+
+	plugins := make(map[string][]string)
+	for name, deps := range pluginDomainMap {
+		for domain := range deps.domains {
+			plugins[domain] = append(plugins[domain], name)
+		}
+	}
+	dead := make([]DeadDomainStatus, 0)
+	for domain, health := range domainHealths {
+		if health.deadSince.IsZero() {
+			continue
+		}
+		names := plugins[domain]
+		sort.Strings(names)
+		dead = append(dead, DeadDomainStatus{
+			Domain:       domain,
+			Failures:     health.failures,
+			FirstFailure: health.firstFailure,
+			LastFailure:  health.lastFailure,
+			LastError:    health.lastError,
+			DeadSince:    health.deadSince,
+			Plugins:      names,
+		})
+	}
+	sort.Slice(dead, func(i, j int) bool {
+		if !dead[i].DeadSince.Equal(dead[j].DeadSince) {
+			return dead[i].DeadSince.Before(dead[j].DeadSince)
+		}
+		return dead[i].Domain < dead[j].Domain
+	})
+	return dead
+}
+
+// GetDegradedPlugins 获取处于降级状态的插件，按插件名排序
+func GetDegradedPlugins() []DegradedPluginStatus {
+	domainHealthMu.Lock()
+	defer domainHealthMu.Unlock()
+
+	degraded := make([]DegradedPluginStatus, 0)
+	for name, deps := range pluginDomainMap {
+		if deps.degradedSince.IsZero() {
+			continue
+		}
+		domains := make([]string, 0, len(deps.domains))
+		for domain := range deps.domains {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		degraded = append(degraded, DegradedPluginStatus{
+			Name:          name,
+			Domains:       domains,
+			DegradedSince: deps.degradedSince,
+			NextRetry:     deps.nextRetry,
+		})
+	}
+	sort.Slice(degraded, func(i, j int) bool { return degraded[i].Name < degraded[j].Name })
+	return degraded
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenFDCount 返回进程当前打开的文件描述符数，无法获取时返回-1
+func OpenFDCount() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+// FDLimit 返回进程文件描述符软上限，无法获取时返回-1
+func FDLimit() int {
+	var rlim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlim); err != nil {
+		return -1
+	}
+	if rlim.Cur > 1<<30 {
+		return 1 << 30
+	}
+	return int(rlim.Cur)
+}
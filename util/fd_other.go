@@ -0,0 +1,13 @@
+//go:build !linux
+
+package util
+
+// OpenFDCount 返回进程当前打开的文件描述符数，当前平台不支持时返回-1
+func OpenFDCount() int {
+	return -1
+}
+
+// FDLimit 返回进程文件描述符软上限，当前平台不支持时返回-1
+func FDLimit() int {
+	return -1
+}
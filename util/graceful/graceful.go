@@ -0,0 +1,149 @@
+// Package graceful 创建HTTP服务的监听器（TCP、Unix套接字、systemd套接字激活），并实现不中断服务的二进制升级：
+// 旧进程把监听套接字通过文件描述符继承交给新进程，新进程开始接受连接后通知旧进程，
+// 旧进程停止接受新连接、处理完进行中的请求并保存数据后退出
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 新进程通过环境变量获知继承的文件描述符
+const (
+	envListenerFD = "PANSOU_LISTENER_FD" // 继承的监听套接字
+	envReadyFD    = "PANSOU_READY_FD"    // 通知旧进程已就绪的管道写端
+)
+
+// systemd套接字激活传入的第一个文件描述符
+const systemdFirstFD = 3
+
+// ListenerSource 监听器的来源
+type ListenerSource string
+
+const (
+	SourceNew       ListenerSource = "new"       // 新建的监听
+	SourceInherited ListenerSource = "inherited" // 平滑升级时从旧进程继承
+	SourceSystemd   ListenerSource = "systemd"   // systemd套接字激活传入
+)
+
+// ListenOptions 监听配置
+type ListenOptions struct {
+	Addr             string      // TCP监听地址，如":8888"
+	UnixSocket       string      // Unix套接字路径，设置后代替TCP监听
+	UnixSocketMode   os.FileMode // Unix套接字文件权限
+	SocketActivation bool        // 是否使用systemd套接字激活（LISTEN_FDS）传入的监听套接字
+}
+
+// Listen 创建监听器，依次尝试：平滑升级时继承的监听套接字、systemd套接字激活、Unix套接字、TCP监听
+func Listen(opts ListenOptions) (net.Listener, ListenerSource, error) {
+	if fd := inheritedFD(envListenerFD); fd != nil {
+		defer fd.Close()
+		ln, err := net.FileListener(fd)
+		if err != nil {
+			return nil, "", fmt.Errorf("使用继承的监听套接字失败: %v", err)
+		}
+		return ln, SourceInherited, nil
+	}
+
+	if opts.SocketActivation {
+		ln, err := systemdListener()
+		if err != nil {
+			return nil, "", err
+		}
+		if ln != nil {
+			return ln, SourceSystemd, nil
+		}
+	}
+
+	if opts.UnixSocket != "" {
+		ln, err := listenUnix(opts.UnixSocket, opts.UnixSocketMode)
+		return ln, SourceNew, err
+	}
+
+	ln, err := net.Listen("tcp", opts.Addr)
+	return ln, SourceNew, err
+}
+
+// systemdListener 读取systemd套接字激活传入的第一个监听套接字（文件描述符3），
+// LISTEN_PID不是当前进程或未传入套接字时返回nil
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	// 清除环境变量，避免传给升级启动的新进程
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	fd := os.NewFile(uintptr(systemdFirstFD), "LISTEN_FD_3")
+	defer fd.Close()
+	ln, err := net.FileListener(fd)
+	if err != nil {
+		return nil, fmt.Errorf("使用systemd传入的监听套接字失败: %v", err)
+	}
+	return ln, nil
+}
+
+// listenUnix 监听Unix套接字：删除上次运行残留的套接字文件，并设置文件权限
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s 已存在且不是套接字文件", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("删除残留的套接字文件失败: %v", err)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("设置套接字文件权限失败: %v", err)
+		}
+	}
+	return ln, nil
+}
+
+// Ready 通知启动本进程的旧进程已开始接受连接，不是由升级启动时不做任何事
+func Ready() error {
+	fd := inheritedFD(envReadyFD)
+	if fd == nil {
+		return nil
+	}
+	defer fd.Close()
+	_, err := fd.Write([]byte("ready\n"))
+	return err
+}
+
+// WritePIDFile 将当前进程ID写入文件，升级后由新进程覆盖，供进程管理工具跟踪当前的服务进程
+func WritePIDFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+}
+
+// inheritedFD 读取环境变量指定的继承文件描述符，读取后清除环境变量，避免再传给下一代进程
+func inheritedFD(env string) *os.File {
+	value := strings.TrimSpace(os.Getenv(env))
+	if value == "" {
+		return nil
+	}
+	os.Unsetenv(env)
+	fd, err := strconv.Atoi(value)
+	if err != nil || fd < 3 {
+		return nil
+	}
+	return os.NewFile(uintptr(fd), env)
+}
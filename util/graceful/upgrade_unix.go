@@ -0,0 +1,95 @@
+//go:build !windows
+
+package graceful
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// filer 可以导出文件描述符的监听器（TCP和Unix套接字监听器）
+type filer interface {
+	File() (*os.File, error)
+}
+
+// NotifyUpgrade 收到SIGUSR2时向ch发送信号，表示需要升级
+func NotifyUpgrade(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR2)
+}
+
+// Upgrade 以相同参数启动当前可执行文件（升级时已替换为新版本），将监听套接字交给新进程，
+// 等待新进程就绪后返回。返回nil后调用方应停止接受新连接并退出；返回错误时旧进程继续提供服务
+func Upgrade(ln net.Listener, timeout time.Duration) error {
+	f, ok := ln.(filer)
+	if !ok {
+		return fmt.Errorf("监听器不支持导出文件描述符: %T", ln)
+	}
+	// 旧进程关闭监听器时不删除Unix套接字文件，新进程仍在使用
+	if unixLn, ok := ln.(*net.UnixListener); ok {
+		unixLn.SetUnlinkOnClose(false)
+	}
+	lnFile, err := f.File()
+	if err != nil {
+		return fmt.Errorf("导出监听套接字失败: %v", err)
+	}
+	defer lnFile.Close()
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("创建就绪通知管道失败: %v", err)
+	}
+	defer readyR.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		readyW.Close()
+		return fmt.Errorf("获取可执行文件路径失败: %v", err)
+	}
+
+	// ExtraFiles中的文件在新进程中依次为文件描述符3、4
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{lnFile, readyW}
+	cmd.Env = append(os.Environ(), envListenerFD+"=3", envReadyFD+"=4")
+	err = cmd.Start()
+	readyW.Close()
+	if err != nil {
+		return fmt.Errorf("启动新进程失败: %v", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	ready := make(chan error, 1)
+	go func() {
+		_, err := bufio.NewReader(readyR).ReadString('\n')
+		ready <- err
+	}()
+
+	select {
+	case err := <-ready:
+		if err == nil {
+			return nil
+		}
+		// 新进程未发送就绪通知就关闭了管道，等待其退出状态
+		select {
+		case err := <-exited:
+			return fmt.Errorf("新进程启动失败: %v", err)
+		case <-time.After(timeout):
+			cmd.Process.Kill()
+			return fmt.Errorf("新进程未就绪")
+		}
+	case err := <-exited:
+		return fmt.Errorf("新进程启动失败: %v", err)
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		return fmt.Errorf("等待新进程就绪超时（%v）", timeout)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package graceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// NotifyUpgrade 当前平台不支持升级信号
+func NotifyUpgrade(ch chan<- os.Signal) {}
+
+// Upgrade 当前平台不支持通过文件描述符继承交接监听套接字
+func Upgrade(ln net.Listener, timeout time.Duration) error {
+	return fmt.Errorf("当前平台不支持平滑升级")
+}
@@ -0,0 +1,258 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"pansou/config"
+)
+
+// hostLimit 单个主机组的并发配额和请求间隔，所有插件共享
+type hostLimit struct {
+	key      string
+	sem      chan struct{} // 为nil时不限制并发
+	interval time.Duration
+
+	mu       sync.Mutex
+	nextSlot time.Time // 下一个请求最早的发出时间
+
+	active   int64
+	requests int64
+	waits    int64 // 因并发上限而等待的次数
+	delays   int64 // 因请求间隔而延后的次数
+}
+
+var (
+	hostLimitsMu sync.Mutex
+	hostLimits   = make(map[string]*hostLimit)
+)
+
+// HostLimitTransport 按目标主机限制并发和请求频率的传输层。
+// 配额按主机组（HOST_GROUPS中配置的组，否则为注册域名，如a.example.com和b.example.com同属example.com）
+// 在所有插件之间共享，避免多个插件同时抓取同一站点导致服务器IP被封禁
+type HostLimitTransport struct {
+	base http.RoundTripper
+}
+
+// NewHostLimitTransport 创建按主机限流的传输层
+func NewHostLimitTransport(base http.RoundTripper) *HostLimitTransport {
+	return &HostLimitTransport{base: base}
+}
+
+// RoundTrip 获取主机配额后发出请求，配额在响应体读完或关闭时归还
+func (t *HostLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limit := getHostLimit(req.URL.Hostname())
+	if limit == nil {
+		return t.base.RoundTrip(req)
+	}
+	if err := limit.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		limit.release()
+		return nil, err
+	}
+	resp.Body = &hostLimitBody{ReadCloser: resp.Body, limit: limit}
+	return resp, nil
+}
+
+// CloseIdleConnections 关闭底层传输层的空闲连接
+func (t *HostLimitTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// hostLimitBody 读到末尾、读取出错或关闭时归还主机配额的响应体
+type hostLimitBody struct {
+	io.ReadCloser
+	limit    *hostLimit
+	released int32
+}
+
+// Read 读取响应体，读完后立即归还配额
+func (b *hostLimitBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
+	}
+	return n, err
+}
+
+// Close 关闭响应体并归还配额
+func (b *hostLimitBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+func (b *hostLimitBody) release() {
+	if atomic.CompareAndSwapInt32(&b.released, 0, 1) {
+		b.limit.release()
+	}
+}
+
+// hostLimitKey 计算主机所属的主机组：优先使用HOST_GROUPS中配置的组（含子域名），
+// 否则为注册域名，IP地址和无法识别的主机名使用主机本身
+func hostLimitKey(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return ""
+	}
+	if config.AppConfig != nil && len(config.AppConfig.HostGroups) > 0 {
+		for domain := host; domain != ""; {
+			if group, ok := config.AppConfig.HostGroups[domain]; ok {
+				return group
+			}
+			_, parent, found := strings.Cut(domain, ".")
+			if !found {
+				break
+			}
+			domain = parent
+		}
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+// getHostLimit 获取主机组的配额，并发和间隔都不限制时返回nil
+func getHostLimit(host string) *hostLimit {
+	key := hostLimitKey(host)
+	if key == "" || config.AppConfig == nil {
+		return nil
+	}
+
+	hostLimitsMu.Lock()
+	defer hostLimitsMu.Unlock()
+	if limit, ok := hostLimits[key]; ok {
+		return limit
+	}
+
+	maxConcurrency := config.AppConfig.HostMaxConcurrency
+	if override, ok := config.AppConfig.HostConcurrencyLimits[key]; ok {
+		maxConcurrency = override
+	}
+	interval := config.AppConfig.HostRequestInterval
+	if override, ok := config.AppConfig.HostIntervals[key]; ok {
+		interval = override
+	}
+
+	var limit *hostLimit
+	if maxConcurrency > 0 || interval > 0 {
+		limit = &hostLimit{key: key, interval: interval}
+		if maxConcurrency > 0 {
+			limit.sem = make(chan struct{}, maxConcurrency)
+		}
+	}
+	// 不限制的主机组也缓存结果，避免重复查找配置
+	hostLimits[key] = limit
+	return limit
+}
+
+// acquire 等待并发配额，再按请求间隔等待到本次请求的发出时间，ctx结束时放弃
+func (l *hostLimit) acquire(ctx context.Context) error {
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		default:
+			atomic.AddInt64(&l.waits, 1)
+			select {
+			case l.sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		start := l.nextSlot
+		if start.Before(now) {
+			start = now
+		}
+		l.nextSlot = start.Add(l.interval)
+		l.mu.Unlock()
+
+		if delay := start.Sub(now); delay > 0 {
+			atomic.AddInt64(&l.delays, 1)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				if l.sem != nil {
+					<-l.sem
+				}
+				return ctx.Err()
+			}
+		}
+	}
+
+	atomic.AddInt64(&l.active, 1)
+	atomic.AddInt64(&l.requests, 1)
+	return nil
+}
+
+// release 归还并发配额
+func (l *hostLimit) release() {
+	atomic.AddInt64(&l.active, -1)
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// HostLimitStats 单个主机组的限流统计
+type HostLimitStats struct {
+	Host       string `json:"host"`                  // 主机组
+	Limit      int    `json:"limit,omitempty"`       // 并发上限，0表示不限制
+	IntervalMs int64  `json:"interval_ms,omitempty"` // 请求间隔（毫秒）
+	Active     int64  `json:"active"`                // 当前进行中的请求数
+	Requests   int64  `json:"requests"`              // 累计请求数
+	Waits      int64  `json:"waits"`                 // 因并发上限而等待的次数
+	Delays     int64  `json:"delays"`                // 因请求间隔而延后的次数
+}
+
+// GetHostLimitStats 获取各主机组的限流统计，按累计请求数从多到少排序
+func GetHostLimitStats() []HostLimitStats {
+	hostLimitsMu.Lock()
+	stats := make([]HostLimitStats, 0, len(hostLimits))
+	for _, l := range hostLimits {
+		if l == nil {
+			continue
+		}
+		stats = append(stats, HostLimitStats{
+			Host:       l.key,
+			Limit:      cap(l.sem),
+			IntervalMs: l.interval.Milliseconds(),
+			Active:     atomic.LoadInt64(&l.active),
+			Requests:   atomic.LoadInt64(&l.requests),
+			Waits:      atomic.LoadInt64(&l.waits),
+			Delays:     atomic.LoadInt64(&l.delays),
+		})
+	}
+	hostLimitsMu.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Requests != stats[j].Requests {
+			return stats[i].Requests > stats[j].Requests
+		}
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
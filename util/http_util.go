@@ -66,7 +66,7 @@ func InitHTTPClient() {
 
 	// 创建客户端
 	httpClient = &http.Client{
-		Transport: transport,
+		Transport: NewPooledTransport("global", transport),
 		Timeout:   time.Duration(60) * time.Second,
 	}
 }
@@ -115,7 +115,11 @@ func FetchHTML(targetURL string) (string, error) {
 
 // BuildSearchURL 构建搜索URL
 func BuildSearchURL(channel string, keyword string, nextPageParam string) string {
-	baseURL := "https://t.me/s/" + channel
+	baseURL := "https://t.me/s/"
+	if config.AppConfig != nil && config.AppConfig.TGBaseURL != "" {
+		baseURL = config.AppConfig.TGBaseURL
+	}
+	baseURL += channel
 	if keyword != "" {
 		baseURL += "?q=" + url.QueryEscape(keyword)
 		if nextPageParam != "" {
@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pansou/config"
+)
+
+// 支持的语言
+const (
+	LangZH = "zh" // 中文，默认语言
+	LangEN = "en" // 英文
+)
+
+// bundles 各语言的消息模板，按消息ID索引，模板使用fmt格式化参数。
+// 新增消息时需同时补充所有语言，缺失时回退到中文
+var bundles = map[string]map[string]string{
+	LangZH: zhMessages,
+	LangEN: enMessages,
+}
+
+// T 按指定语言格式化消息，语言不支持或消息缺失时回退到中文，中文也缺失时返回消息ID
+func T(lang string, id string, args ...interface{}) string {
+	template, ok := bundles[lang][id]
+	if !ok {
+		template, ok = bundles[LangZH][id]
+		if !ok {
+			return id
+		}
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// L 按运维日志语言（LOG_LANG）格式化消息，与接口消息语言相互独立
+func L(id string, args ...interface{}) string {
+	return T(LogLang(), id, args...)
+}
+
+// LogLang 运维日志使用的语言
+func LogLang() string {
+	if config.AppConfig != nil && config.AppConfig.LogLang != "" {
+		return config.AppConfig.LogLang
+	}
+	return LangZH
+}
+
+// DefaultLang 请求未指定可识别语言时接口消息使用的语言
+func DefaultLang() string {
+	if config.AppConfig != nil && config.AppConfig.APIDefaultLang != "" {
+		return config.AppConfig.APIDefaultLang
+	}
+	return LangZH
+}
+
+// Supported 判断是否支持该语言
+func Supported(lang string) bool {
+	_, ok := bundles[lang]
+	return ok
+}
+
+// FromAcceptLanguage 根据Accept-Language请求头选择语言：按q值从高到低匹配第一个支持的语言，
+// zh-CN、en-US等带地区的标签按主语言匹配，没有可匹配的语言时返回DefaultLang
+func FromAcceptLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+		candidates = append(candidates, candidate{lang: tag, q: q})
+	}
+
+	// q值相同时保持请求头中的顺序
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if Supported(c.lang) {
+			return c.lang
+		}
+		if c.lang == "*" {
+			break
+		}
+	}
+	return DefaultLang()
+}
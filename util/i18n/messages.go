@@ -0,0 +1,244 @@
+package i18n
+
+// 消息ID：接口返回的错误和状态消息
+const (
+	MsgInvalidParams          = "request.invalid_params"
+	MsgReadBodyFailed         = "request.read_body_failed"
+	MsgInvalidRequest         = "request.invalid_request"
+	MsgInvalidRequestDetail   = "request.invalid_request_detail"
+	MsgQueryTooLong           = "request.query_too_long"
+	MsgBodyTooLarge           = "request.body_too_large"
+	MsgBodyReadTimeout        = "request.body_read_timeout"
+	MsgAuthRequired           = "auth.required"
+	MsgAuthMissingToken       = "auth.missing_token"
+	MsgAuthInvalidFormat      = "auth.invalid_format"
+	MsgAuthInvalidToken       = "auth.invalid_token"
+	MsgAuthForbidden          = "auth.forbidden"
+	MsgAuthUserTypeMismatch   = "auth.user_type_mismatch"
+	MsgAuthMemberRequired     = "auth.member_required"
+	MsgAuthLogoutFailed       = "auth.logout_failed"
+	MsgAuthTokenFailed        = "auth.token_failed"
+	MsgAuthRegistered         = "auth.registered"
+	MsgAuthLoggedOut          = "auth.logged_out"
+	MsgAuthProfileUpdated     = "auth.profile_updated"
+	MsgAuthPasswordChanged    = "auth.password_changed"
+	MsgAuthMembershipUpgraded = "auth.membership_upgraded"
+	MsgAuthTokenRefreshed     = "auth.token_refreshed"
+	MsgAccountDisabled        = "account.disabled"
+	MsgHistoryCleared         = "history.cleared"
+	MsgCacheManagerNotReady   = "cache.manager_not_ready"
+	MsgCacheInvalidInterval   = "cache.invalid_interval"
+	MsgCacheConfigUpdated     = "cache.config_updated"
+	MsgLinkExpired            = "click.link_expired"
+	MsgClusterInvalidSecret   = "cluster.invalid_secret"
+	MsgClusterPluginFailed    = "cluster.plugin_failed"
+	MsgTenantResolveFailed    = "tenant.resolve_failed"
+	MsgTenantUnknown          = "tenant.unknown"
+	MsgTenantInvalidAPIKey    = "tenant.invalid_api_key"
+	MsgTenantAPIKeyRequired   = "tenant.api_key_required"
+	MsgRateLimited            = "rate_limited"
+	MsgSearchInvalidExtFormat = "search.invalid_ext_format"
+	MsgSearchInvalidExt       = "search.invalid_ext"
+	MsgSearchInvalidFilter    = "search.invalid_filter"
+	MsgSearchBlocked          = "search.blocked"
+	MsgSearchNoSource         = "search.no_source"
+	MsgSearchInvalidLanguage  = "search.invalid_language"
+	MsgSearchInvalidRefresh   = "search.invalid_refresh"
+	MsgSearchFailed           = "search.failed"
+	MsgPageTokenInvalid       = "page_token.invalid"
+	MsgPageTokenExpired       = "page_token.expired"
+	MsgSourceNoTGChannels     = "source.no_tg_channels"
+	MsgSourcePluginsDisabled  = "source.plugins_disabled"
+	MsgSourceNoPlugins        = "source.no_plugins"
+	MsgSuggestQueryRequired   = "suggest.query_required"
+	MsgLookupQueryRequired    = "lookup.query_required"
+	MsgLookupInvalidQuery     = "lookup.invalid_query"
+	MsgPluginUnknown          = "plugin.unknown"
+	MsgMemoryInvalidGCPercent = "memory.invalid_gc_percent"
+	MsgMemoryInvalidLimit     = "memory.invalid_limit"
+	MsgMemorySettingsUpdated  = "memory.settings_updated"
+	MsgSearchKeywordRequired  = "search.keyword_required"
+	MsgSearchInvalidSource    = "search.invalid_source"
+	MsgSearchInvalidResult    = "search.invalid_result"
+	MsgSearchInvalidRerank    = "search.invalid_rerank"
+	MsgSearchInvalidMinRes    = "search.invalid_min_resolution"
+	MsgSearchUnknownProfile   = "search.unknown_profile"
+	MsgSearchProfileConflict  = "search.profile_conflict"
+	MsgProbeNoLinks           = "probe.no_links"
+	MsgProbeTooManyLinks      = "probe.too_many_links"
+	MsgMaintenanceNotice      = "maintenance.notice"
+	MsgLogInvalidSampleRate   = "log.invalid_sample_rate"
+	MsgLogInvalidDuration     = "log.invalid_duration"
+	MsgLogSettingsUpdated     = "log.settings_updated"
+)
+
+// 消息ID：运维日志
+const (
+	LogSourceNoTGChannels    = "log.source.no_tg_channels"
+	LogSourcePluginsDisabled = "log.source.plugins_disabled"
+	LogSourceNoPlugins       = "log.source.no_plugins"
+	LogSourceNoneAvailable   = "log.source.none_available"
+	LogSelfCheckPassed       = "log.self_check.passed"
+	LogSelfCheckFailed       = "log.self_check.failed"
+	LogSelfCheckMiswired     = "log.self_check.miswired"
+)
+
+// zhMessages 中文消息
+var zhMessages = map[string]string{
+	MsgInvalidParams:          "请求参数错误: %s",
+	MsgReadBodyFailed:         "读取请求数据失败: %s",
+	MsgInvalidRequest:         "无效的请求参数",
+	MsgInvalidRequestDetail:   "无效的请求参数: %s",
+	MsgQueryTooLong:           "查询字符串过长，最多%d字节",
+	MsgBodyTooLarge:           "请求数据过大，最多%d字节",
+	MsgBodyReadTimeout:        "读取请求数据超时",
+	MsgAuthRequired:           "需要认证",
+	MsgAuthMissingToken:       "缺少认证令牌",
+	MsgAuthInvalidFormat:      "无效的认证格式",
+	MsgAuthInvalidToken:       "无效的认证令牌: %s",
+	MsgAuthForbidden:          "权限不足",
+	MsgAuthUserTypeMismatch:   "用户类型不匹配",
+	MsgAuthMemberRequired:     "需要会员权限",
+	MsgAuthLogoutFailed:       "登出失败: %s",
+	MsgAuthTokenFailed:        "生成令牌失败: %s",
+	MsgAuthRegistered:         "注册成功",
+	MsgAuthLoggedOut:          "登出成功",
+	MsgAuthProfileUpdated:     "资料更新成功",
+	MsgAuthPasswordChanged:    "密码修改成功",
+	MsgAuthMembershipUpgraded: "会员升级成功",
+	MsgAuthTokenRefreshed:     "令牌刷新成功",
+	MsgAccountDisabled:        "账户已被禁用",
+	MsgHistoryCleared:         "搜索历史已清空",
+	MsgCacheManagerNotReady:   "缓存写入管理器未初始化",
+	MsgCacheInvalidInterval:   "无效的max_batch_interval: %s",
+	MsgCacheConfigUpdated:     "配置已更新",
+	MsgLinkExpired:            "链接不存在或已过期，请重新搜索",
+	MsgClusterInvalidSecret:   "无效的集群密钥",
+	MsgClusterPluginFailed:    "插件搜索失败: %s",
+	MsgTenantResolveFailed:    "租户识别失败: %s",
+	MsgTenantUnknown:          "未知的租户",
+	MsgTenantInvalidAPIKey:    "无效的API Key",
+	MsgTenantAPIKeyRequired:   "该租户需要通过API Key访问",
+	MsgRateLimited:            "请求过于频繁，请稍后再试",
+	MsgSearchInvalidExtFormat: "无效的ext参数格式: %s",
+	MsgSearchInvalidExt:       "无效的ext参数: %s",
+	MsgSearchInvalidFilter:    "无效的过滤条件: %s",
+	MsgSearchBlocked:          "搜索关键词包含不允许搜索的内容",
+	MsgSearchNoSource:         "没有可用的搜索来源: %s",
+	MsgSearchInvalidLanguage:  "不支持的语言参数: %s",
+	MsgSearchInvalidRefresh:   "无效的X-Cache-Refresh请求头: %s，可选值为 memory、full、tg、plugins",
+	MsgSearchFailed:           "搜索失败: %s",
+	MsgPageTokenInvalid:       "无效的分页令牌",
+	MsgPageTokenExpired:       "分页令牌已过期，请重新搜索",
+	MsgSourceNoTGChannels:     "未配置TG频道",
+	MsgSourcePluginsDisabled:  "插件已禁用",
+	MsgSourceNoPlugins:        "没有加载任何插件",
+	MsgSuggestQueryRequired:   "缺少搜索建议的输入参数q",
+	MsgLookupQueryRequired:    "缺少反查的链接参数q",
+	MsgLookupInvalidQuery:     "q必须为磁力链接、info-hash或网盘分享链接",
+	MsgPluginUnknown:          "插件不存在",
+	MsgMemoryInvalidGCPercent: "gc_percent必须在1~1000之间",
+	MsgMemoryInvalidLimit:     "memory_limit_mb不能小于0或超出范围",
+	MsgMemorySettingsUpdated:  "内存设置已更新，重启后恢复为环境变量配置",
+	MsgSearchKeywordRequired:  "搜索关键词不能为空",
+	MsgSearchInvalidSource:    "无效的src参数，可选值为 all、tg、plugin",
+	MsgSearchInvalidResult:    "无效的res参数，可选值为 all、results、merge、flat",
+	MsgSearchInvalidRerank:    "无效的rerank参数，可选值为 semantic",
+	MsgSearchInvalidMinRes:    "无效的min_resolution参数，可选值为 480p、720p、1080p、2160p（4k）、4320p（8k）",
+	MsgSearchUnknownProfile:   "插件组合不存在，可通过 /api/plugins/profiles 查看可用的组合",
+	MsgSearchProfileConflict:  "profile与plugins不能同时指定",
+	MsgProbeNoLinks:           "请至少提供一个待检测的链接",
+	MsgProbeTooManyLinks:      "单次最多检测%d个链接",
+	MsgMaintenanceNotice:      "服务维护中，暂停搜索TG频道和插件，结果只来自缓存",
+	MsgLogInvalidSampleRate:   "sample_rate必须在1~10000之间",
+	MsgLogInvalidDuration:     "duration必须是1秒到24小时之间的时长，如5m",
+	MsgLogSettingsUpdated:     "日志设置已更新，重启后恢复为环境变量配置",
+
+	LogSourceNoTGChannels:    "未配置默认TG频道（CHANNELS），只有请求中指定channels时才会搜索TG",
+	LogSourcePluginsDisabled: "插件已禁用（ASYNC_PLUGIN_ENABLED=false）",
+	LogSourceNoPlugins:       "没有加载任何插件（设置ENABLED_PLUGINS或PLUGIN_PRESET）",
+	LogSourceNoneAvailable:   "没有可用的搜索来源，未指定channels的搜索请求将返回503",
+	LogSelfCheckPassed:       "通过",
+	LogSelfCheckFailed:       "失败",
+	LogSelfCheckMiswired:     "缓存链路未正确连接，搜索结果可能无法写入缓存: %s",
+}
+
+// enMessages 英文消息
+var enMessages = map[string]string{
+	MsgInvalidParams:          "invalid request parameters: %s",
+	MsgReadBodyFailed:         "failed to read request body: %s",
+	MsgInvalidRequest:         "invalid request parameters",
+	MsgInvalidRequestDetail:   "invalid request parameters: %s",
+	MsgQueryTooLong:           "query string too long, at most %d bytes",
+	MsgBodyTooLarge:           "request body too large, at most %d bytes",
+	MsgBodyReadTimeout:        "timed out reading request body",
+	MsgAuthRequired:           "authentication required",
+	MsgAuthMissingToken:       "missing authentication token",
+	MsgAuthInvalidFormat:      "invalid authorization format",
+	MsgAuthInvalidToken:       "invalid authentication token: %s",
+	MsgAuthForbidden:          "insufficient permissions",
+	MsgAuthUserTypeMismatch:   "user type mismatch",
+	MsgAuthMemberRequired:     "membership required",
+	MsgAuthLogoutFailed:       "logout failed: %s",
+	MsgAuthTokenFailed:        "failed to generate token: %s",
+	MsgAuthRegistered:         "registered successfully",
+	MsgAuthLoggedOut:          "logged out successfully",
+	MsgAuthProfileUpdated:     "profile updated",
+	MsgAuthPasswordChanged:    "password changed",
+	MsgAuthMembershipUpgraded: "membership upgraded",
+	MsgAuthTokenRefreshed:     "token refreshed",
+	MsgAccountDisabled:        "account is disabled",
+	MsgHistoryCleared:         "search history cleared",
+	MsgCacheManagerNotReady:   "cache write manager is not initialized",
+	MsgCacheInvalidInterval:   "invalid max_batch_interval: %s",
+	MsgCacheConfigUpdated:     "configuration updated",
+	MsgLinkExpired:            "link not found or expired, please search again",
+	MsgClusterInvalidSecret:   "invalid cluster secret",
+	MsgClusterPluginFailed:    "plugin search failed: %s",
+	MsgTenantResolveFailed:    "failed to identify tenant: %s",
+	MsgTenantUnknown:          "unknown tenant",
+	MsgTenantInvalidAPIKey:    "invalid API key",
+	MsgTenantAPIKeyRequired:   "this tenant requires an API key",
+	MsgRateLimited:            "too many requests, please try again later",
+	MsgSearchInvalidExtFormat: "invalid ext parameter format: %s",
+	MsgSearchInvalidExt:       "invalid ext parameter: %s",
+	MsgSearchInvalidFilter:    "invalid filter: %s",
+	MsgSearchBlocked:          "the search keyword contains disallowed content",
+	MsgSearchNoSource:         "no search source available: %s",
+	MsgSearchInvalidLanguage:  "unsupported language: %s",
+	MsgSearchInvalidRefresh:   "invalid X-Cache-Refresh header: %s, expected one of memory, full, tg, plugins",
+	MsgSearchFailed:           "search failed: %s",
+	MsgPageTokenInvalid:       "invalid page token",
+	MsgPageTokenExpired:       "page token expired, please search again",
+	MsgSourceNoTGChannels:     "no TG channels configured",
+	MsgSourcePluginsDisabled:  "plugins are disabled",
+	MsgSourceNoPlugins:        "no plugins loaded",
+	MsgSuggestQueryRequired:   "missing suggestion query parameter q",
+	MsgLookupQueryRequired:    "missing lookup query parameter q",
+	MsgLookupInvalidQuery:     "q must be a magnet link, an info-hash or a cloud share link",
+	MsgPluginUnknown:          "plugin not found",
+	MsgMemoryInvalidGCPercent: "gc_percent must be between 1 and 1000",
+	MsgMemoryInvalidLimit:     "memory_limit_mb must not be negative or out of range",
+	MsgMemorySettingsUpdated:  "memory settings updated; they revert to the environment configuration on restart",
+	MsgSearchKeywordRequired:  "search keyword is required",
+	MsgSearchInvalidSource:    "invalid src, expected one of all, tg, plugin",
+	MsgSearchInvalidResult:    "invalid res, expected one of all, results, merge, flat",
+	MsgSearchInvalidRerank:    "invalid rerank, expected semantic",
+	MsgSearchInvalidMinRes:    "invalid min_resolution, expected one of 480p, 720p, 1080p, 2160p (4k), 4320p (8k)",
+	MsgSearchUnknownProfile:   "unknown plugin profile, see /api/plugins/profiles for available profiles",
+	MsgSearchProfileConflict:  "profile and plugins cannot be specified together",
+	MsgProbeNoLinks:           "at least one link is required",
+	MsgProbeTooManyLinks:      "at most %d links can be probed per request",
+	MsgMaintenanceNotice:      "service is under maintenance; TG channels and plugins are not searched, results come from cache only",
+	MsgLogInvalidSampleRate:   "sample_rate must be between 1 and 10000",
+	MsgLogInvalidDuration:     "duration must be between 1s and 24h, e.g. 5m",
+	MsgLogSettingsUpdated:     "log settings updated; they revert to the environment configuration on restart",
+
+	LogSourceNoTGChannels:    "no default TG channels configured (CHANNELS), TG is only searched when a request specifies channels",
+	LogSourcePluginsDisabled: "plugins are disabled (ASYNC_PLUGIN_ENABLED=false)",
+	LogSourceNoPlugins:       "no plugins loaded (set ENABLED_PLUGINS or PLUGIN_PRESET)",
+	LogSourceNoneAvailable:   "no search source available, search requests without channels will return 503",
+	LogSelfCheckPassed:       "passed",
+	LogSelfCheckFailed:       "failed",
+	LogSelfCheckMiswired:     "cache pipeline is not wired correctly, search results may not be cached: %s",
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 结果语言/地区标签
+const (
+	LangZhCN = "zh-CN" // 简体中文
+	LangZhTW = "zh-TW" // 繁体中文（港台）
+	LangEn   = "en"    // 英文
+	LangJP   = "jp"    // 日文
+)
+
+// 繁简对照字表：只收录繁简写法不同的常用字，用于区分简体与繁体标题
+const (
+	traditionalOnlyChars = "這個們來時會說為與國學書電視劇網絡發現動畫場體應該還點當實從開關門問間經對歷義頭風飛馬鳥龍車東長興無變讓請記語聽讀寫愛樂藝術優質簡灣華粵陸紀錄頻號碼載鏈盤資戰爭傳歲廣韓屆線級鬥獸蘭達"
+	simplifiedOnlyChars  = "这个们来时会说为与国学书电视剧网络发现动画场体应该还点当实从开关门问间经对历义头风飞马鸟龙车东长兴无变让请记语听读写爱乐艺术优质简湾华粤陆纪录频号码载链盘资战争传岁广韩届线级斗兽兰达"
+)
+
+var (
+	traditionalCharSet = makeRuneSet(traditionalOnlyChars)
+	simplifiedCharSet  = makeRuneSet(simplifiedOnlyChars)
+)
+
+// 来源默认语言：标题无法判断语言时按来源插件推断
+var sourceLanguageHints = map[string]string{
+	"javdb":        LangJP,
+	"thepiratebay": LangEn,
+}
+
+// makeRuneSet 将字符串转为字符集合
+func makeRuneSet(s string) map[rune]bool {
+	set := make(map[rune]bool)
+	for _, r := range s {
+		set[r] = true
+	}
+	return set
+}
+
+// DetectLanguage 根据标题文本推断语言：含假名判为日文，含汉字时按繁简特有字判断简繁，
+// 纯拉丁字母判为英文，无法判断时返回空字符串
+func DetectLanguage(text string) string {
+	var kana, han, latin, trad, simp int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana) && r != 'ー' && r != '・':
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if traditionalCharSet[r] {
+				trad++
+			} else if simplifiedCharSet[r] {
+				simp++
+			}
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0:
+		return LangJP
+	case han > 0:
+		if trad > simp {
+			return LangZhTW
+		}
+		return LangZhCN
+	case latin >= 3:
+		return LangEn
+	default:
+		return ""
+	}
+}
+
+// DetectResultLanguage 推断搜索结果的语言：优先依据标题，其次依据来源插件
+func DetectResultLanguage(title, source string) string {
+	if lang := DetectLanguage(title); lang != "" {
+		return lang
+	}
+	return sourceLanguageHints[strings.TrimPrefix(source, "plugin:")]
+}
+
+// NormalizeLanguage 将常见的语言写法规范化为结果语言标签，
+// "zh"返回空字符串和true表示匹配全部中文，无法识别时ok为false
+func NormalizeLanguage(lang string) (normalized string, ok bool) {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-")) {
+	case "zh-cn", "cn", "zh-hans", "zh-sg", "chs", "sc":
+		return LangZhCN, true
+	case "zh-tw", "tw", "zh-hk", "hk", "zh-hant", "zh-mo", "cht", "tc":
+		return LangZhTW, true
+	case "en", "en-us", "en-gb", "eng":
+		return LangEn, true
+	case "jp", "ja", "ja-jp", "jpn":
+		return LangJP, true
+	case "zh":
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+// ExpandLanguages 将语言参数列表规范化为标签集合（"zh"展开为简繁两种），忽略无法识别的值
+func ExpandLanguages(langs []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, lang := range langs {
+		normalized, ok := NormalizeLanguage(lang)
+		if !ok {
+			continue
+		}
+		if normalized == "" {
+			set[LangZhCN] = true
+			set[LangZhTW] = true
+			continue
+		}
+		set[normalized] = true
+	}
+	return set
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LinkMeta 从资源描述文本中提取的链接元数据，零值表示未知
+type LinkMeta struct {
+	Size      int64     // 文件总大小（字节）
+	FileCount int       // 文件数量
+	ExpiresAt time.Time // 分享链接过期时间
+}
+
+var (
+	// 带标签的大小，如"大小：1.5GB"、"容量: 700 MB"、"Size: 4.2G"
+	labeledSizeRegex = regexp.MustCompile(`(?i)(?:大小|容量|体积|size)\s*[:：]?\s*(\d+(?:\.\d+)?)\s*([KMGT])(?:i?B)?\b`)
+	// 不带标签的大小，必须带B后缀，避免误匹配"5G网络"之类的文本
+	bareSizeRegex = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s*([KMGT])i?B\b`)
+	// 文件数量，如"共12个文件"、"文件数：12"
+	fileCountRegex = regexp.MustCompile(`(?:(\d+)\s*个文件|文件数(?:量)?\s*[:：]?\s*(\d+))`)
+	// 过期日期，如"有效期至：2025-08-01"、"过期时间: 2025/08/01"
+	expiryDateRegex = regexp.MustCompile(`(?:有效期至|有效期|过期时间|到期时间|失效时间)\s*[:：]?\s*(\d{4})[-/.年](\d{1,2})[-/.月](\d{1,2})`)
+	// 相对有效期，如"有效期：7天"
+	expiryDaysRegex = regexp.MustCompile(`有效期\s*[:：]?\s*(\d+)\s*天`)
+)
+
+// 大小单位对应的字节数
+var sizeUnits = map[string]float64{
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// ExtractLinkMeta 从资源描述文本中提取大小、文件数和过期时间，
+// 相对有效期（如"7天"）以now为起点计算
+func ExtractLinkMeta(text string, now time.Time) LinkMeta {
+	var meta LinkMeta
+
+	if m := labeledSizeRegex.FindStringSubmatch(text); m != nil {
+		meta.Size = sizeToBytes(m[1], m[2])
+	} else if m := bareSizeRegex.FindStringSubmatch(text); m != nil {
+		meta.Size = sizeToBytes(m[1], m[2])
+	}
+
+	if m := fileCountRegex.FindStringSubmatch(text); m != nil {
+		count := m[1]
+		if count == "" {
+			count = m[2]
+		}
+		meta.FileCount, _ = strconv.Atoi(count)
+	}
+
+	if m := expiryDateRegex.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			// 当天结束时过期
+			meta.ExpiresAt = time.Date(year, time.Month(month), day, 23, 59, 59, 0, now.Location())
+		}
+	} else if m := expiryDaysRegex.FindStringSubmatch(text); m != nil {
+		if days, err := strconv.Atoi(m[1]); err == nil && days > 0 {
+			meta.ExpiresAt = now.AddDate(0, 0, days)
+		}
+	}
+
+	return meta
+}
+
+// sizeToBytes 将数值和单位转换为字节数
+func sizeToBytes(value, unit string) int64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(n * sizeUnits[strings.ToUpper(unit)])
+}
@@ -0,0 +1,166 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+)
+
+var (
+	// ErrInvalidLogSampleRate 日志采样率超出范围
+	ErrInvalidLogSampleRate = errors.New("sample_rate必须在1~10000之间")
+	// ErrInvalidDebugDuration 插件调试日志的持续时间超出范围
+	ErrInvalidDebugDuration = errors.New("duration必须在1秒到24小时之间")
+)
+
+const (
+	// 日志采样率的上限
+	maxLogSampleRate = 10000
+
+	// 插件调试日志默认和最长的持续时间
+	DefaultPluginDebugDuration = 5 * time.Minute
+	maxPluginDebugDuration     = 24 * time.Hour
+)
+
+// LogSettings 运行时日志设置
+type LogSettings struct {
+	Verbose      bool                 `json:"verbose"`       // 是否输出异步插件和缓存的详细日志，初始值为ASYNC_LOG_ENABLED
+	SampleRate   int                  `json:"sample_rate"`   // 缓存更新等高频详细日志每N条输出1条，1表示全部输出
+	DebugPlugins map[string]time.Time `json:"debug_plugins"` // 开启调试日志的插件及截止时间
+}
+
+var (
+	logSettingsOnce sync.Once
+	logVerbose      atomic.Bool
+	logSampleRate   atomic.Int64
+	logSampleCount  atomic.Uint64
+
+	// 插件名（小写） -> 调试日志截止时间
+	debugPlugins   = make(map[string]time.Time)
+	debugPluginsMu sync.RWMutex
+	// 是否有开启调试日志的插件，没有时PluginDebugEnabled不加锁
+	hasDebugPlugins atomic.Bool
+)
+
+// loadLogSettings 首次使用时按ASYNC_LOG_ENABLED初始化日志设置
+func loadLogSettings() {
+	logSettingsOnce.Do(func() {
+		logVerbose.Store(config.AppConfig == nil || config.AppConfig.AsyncLogEnabled)
+		logSampleRate.Store(1)
+	})
+}
+
+// AsyncLogEnabled 是否输出异步插件和缓存的详细日志
+func AsyncLogEnabled() bool {
+	loadLogSettings()
+	return logVerbose.Load()
+}
+
+// SampledLogEnabled 高频详细日志（如缓存更新）是否输出本条：未开启详细日志时不输出，
+// 否则按采样率每N条输出1条
+func SampledLogEnabled() bool {
+	if !AsyncLogEnabled() {
+		return false
+	}
+	rate := uint64(logSampleRate.Load())
+	return rate <= 1 || logSampleCount.Add(1)%rate == 1
+}
+
+// PluginLogEnabled 插件相关的高频详细日志是否输出本条：插件开启了调试日志时始终输出，否则按采样输出
+func PluginLogEnabled(name string) bool {
+	return PluginDebugEnabled(name) || SampledLogEnabled()
+}
+
+// PluginDebugEnabled 插件是否处于调试日志期间
+func PluginDebugEnabled(name string) bool {
+	if !hasDebugPlugins.Load() {
+		return false
+	}
+	debugPluginsMu.RLock()
+	until, ok := debugPlugins[strings.ToLower(name)]
+	debugPluginsMu.RUnlock()
+	return ok && time.Now().Before(until)
+}
+
+// PluginDebugf 插件处于调试日志期间时输出日志
+func PluginDebugf(name string, format string, args ...interface{}) {
+	if PluginDebugEnabled(name) {
+		fmt.Printf("[调试 %s] "+format+"\n", append([]interface{}{name}, args...)...)
+	}
+}
+
+// GetLogSettings 获取当前日志设置，已到期的插件调试日志不返回
+func GetLogSettings() LogSettings {
+	loadLogSettings()
+	settings := LogSettings{
+		Verbose:      logVerbose.Load(),
+		SampleRate:   int(logSampleRate.Load()),
+		DebugPlugins: make(map[string]time.Time),
+	}
+	now := time.Now()
+	debugPluginsMu.RLock()
+	defer debugPluginsMu.RUnlock()
+	for name, until := range debugPlugins {
+		if now.Before(until) {
+			settings.DebugPlugins[name] = until
+		}
+	}
+	return settings
+}
+
+// UpdateLogSettings 运行时开关详细日志和调整采样率，参数为nil时保持不变。
+// 只影响当前进程，重启后恢复为ASYNC_LOG_ENABLED的配置
+func UpdateLogSettings(verbose *bool, sampleRate *int) error {
+	loadLogSettings()
+	if sampleRate != nil && (*sampleRate < 1 || *sampleRate > maxLogSampleRate) {
+		return ErrInvalidLogSampleRate
+	}
+	if verbose != nil {
+		logVerbose.Store(*verbose)
+	}
+	if sampleRate != nil {
+		logSampleRate.Store(int64(*sampleRate))
+	}
+	fmt.Printf("[日志设置] 详细日志: %v | 采样率: 1/%d\n", logVerbose.Load(), logSampleRate.Load())
+	return nil
+}
+
+// EnablePluginDebug 在duration内输出插件的调试日志（不受详细日志开关和采样率限制），返回截止时间
+func EnablePluginDebug(name string, duration time.Duration) (time.Time, error) {
+	if duration < time.Second || duration > maxPluginDebugDuration {
+		return time.Time{}, ErrInvalidDebugDuration
+	}
+	until := time.Now().Add(duration)
+
+	debugPluginsMu.Lock()
+	defer debugPluginsMu.Unlock()
+	pruneDebugPluginsLocked()
+	debugPlugins[strings.ToLower(name)] = until
+	hasDebugPlugins.Store(true)
+	fmt.Printf("[日志设置] 已开启插件调试日志: %s | 截止: %s\n", name, until.Format(time.RFC3339))
+	return until, nil
+}
+
+// DisablePluginDebug 提前关闭插件的调试日志
+func DisablePluginDebug(name string) {
+	debugPluginsMu.Lock()
+	defer debugPluginsMu.Unlock()
+	delete(debugPlugins, strings.ToLower(name))
+	pruneDebugPluginsLocked()
+}
+
+// pruneDebugPluginsLocked 清理已到期的插件调试日志，调用方需持有debugPluginsMu
+func pruneDebugPluginsLocked() {
+	now := time.Now()
+	for name, until := range debugPlugins {
+		if !now.Before(until) {
+			delete(debugPlugins, name)
+		}
+	}
+	hasDebugPlugins.Store(len(debugPlugins) > 0)
+}
@@ -0,0 +1,115 @@
+package util
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// btih前缀
+const btihPrefix = "urn:btih:"
+
+// IsMagnetURL 是否为磁力链接
+func IsMagnetURL(rawURL string) bool {
+	return len(rawURL) >= 7 && strings.EqualFold(rawURL[:7], "magnet:")
+}
+
+// NormalizeMagnet 校验并规范化磁力链接：info-hash统一为40位小写十六进制（32位base32会被转换），
+// tracker去重并保持原有顺序，去除空参数。返回规范化后的链接和info-hash，链接无效时ok为false
+func NormalizeMagnet(rawURL string) (normalized string, infoHash string, ok bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !IsMagnetURL(rawURL) {
+		return "", "", false
+	}
+	query := rawURL[len("magnet:"):]
+	query = strings.TrimPrefix(query, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		// 参数中有未转义的字符时逐个解析，跳过无法解析的参数
+		params = parseQueryLenient(query)
+	}
+
+	for _, xt := range params["xt"] {
+		if len(xt) > len(btihPrefix) && strings.EqualFold(xt[:len(btihPrefix)], btihPrefix) {
+			if infoHash = normalizeInfoHash(xt[len(btihPrefix):]); infoHash != "" {
+				break
+			}
+		}
+	}
+	if infoHash == "" {
+		return "", "", false
+	}
+
+	var b strings.Builder
+	b.WriteString("magnet:?xt=")
+	b.WriteString(btihPrefix)
+	b.WriteString(infoHash)
+	if dn := strings.TrimSpace(firstParam(params, "dn")); dn != "" {
+		b.WriteString("&dn=")
+		b.WriteString(escapeMagnetParam(dn))
+	}
+	if xl := strings.TrimSpace(firstParam(params, "xl")); xl != "" {
+		b.WriteString("&xl=")
+		b.WriteString(escapeMagnetParam(xl))
+	}
+	seen := make(map[string]bool)
+	for _, tr := range params["tr"] {
+		tr = strings.TrimSpace(tr)
+		key := strings.ToLower(strings.TrimRight(tr, "/"))
+		if tr == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		b.WriteString("&tr=")
+		b.WriteString(escapeMagnetParam(tr))
+	}
+	return b.String(), infoHash, true
+}
+
+// normalizeInfoHash 将40位十六进制或32位base32的info-hash转换为小写十六进制，无效时返回空字符串
+func normalizeInfoHash(hash string) string {
+	hash = strings.TrimSpace(hash)
+	switch len(hash) {
+	case 40:
+		if _, err := hex.DecodeString(hash); err != nil {
+			return ""
+		}
+		return strings.ToLower(hash)
+	case 32:
+		decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+		if err != nil || len(decoded) != 20 {
+			return ""
+		}
+		return hex.EncodeToString(decoded)
+	}
+	return ""
+}
+
+// parseQueryLenient 逐个解析查询参数，跳过无法解码的参数
+func parseQueryLenient(query string) url.Values {
+	params := make(url.Values)
+	for _, part := range strings.Split(query, "&") {
+		key, value, _ := strings.Cut(part, "=")
+		k, err1 := url.QueryUnescape(key)
+		v, err2 := url.QueryUnescape(value)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		params[k] = append(params[k], v)
+	}
+	return params
+}
+
+// escapeMagnetParam 转义参数值，空格转义为%20（部分下载工具不把+解析为空格）
+func escapeMagnetParam(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// firstParam 获取参数的第一个值
+func firstParam(params url.Values, key string) string {
+	if values := params[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
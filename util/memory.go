@@ -0,0 +1,138 @@
+package util
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"pansou/config"
+)
+
+var (
+	// ErrInvalidGCPercent GC触发阈值超出范围
+	ErrInvalidGCPercent = errors.New("gc_percent必须在1~1000之间")
+	// ErrInvalidMemoryLimit 软内存上限无效
+	ErrInvalidMemoryLimit = errors.New("memory_limit_mb无效")
+)
+
+// MemoryStats 进程内存与GC设置
+type MemoryStats struct {
+	HeapAlloc     uint64    `json:"heap_alloc"`    // 堆上存活及未回收对象占用的字节数
+	HeapInuse     uint64    `json:"heap_inuse"`    // 正在使用的堆span字节数
+	HeapIdle      uint64    `json:"heap_idle"`     // 空闲的堆span字节数
+	HeapReleased  uint64    `json:"heap_released"` // 已归还操作系统的字节数
+	HeapSys       uint64    `json:"heap_sys"`      // 从操作系统申请的堆内存
+	HeapObjects   uint64    `json:"heap_objects"`  // 堆上的对象数
+	Sys           uint64    `json:"sys"`           // 从操作系统申请的全部内存
+	NumGC         uint32    `json:"num_gc"`        // 累计GC次数
+	LastGC        time.Time `json:"last_gc,omitempty"`
+	PauseTotalMs  float64   `json:"pause_total_ms"` // 累计GC停顿时间
+	Goroutines    int       `json:"goroutines"`
+	GCPercent     int       `json:"gc_percent"`      // 当前GC触发阈值
+	MemoryLimitMB int64     `json:"memory_limit_mb"` // 当前软内存上限，0表示不限制
+}
+
+// ManualGCResult 手动GC的结果
+type ManualGCResult struct {
+	Before     MemoryStats `json:"before"`
+	After      MemoryStats `json:"after"`
+	Freed      int64       `json:"freed"` // HeapAlloc减少的字节数
+	DurationMs float64     `json:"duration_ms"`
+}
+
+var (
+	// 当前的GC触发阈值，debug包没有只读取不修改的接口
+	gcPercent     int
+	gcPercentOnce sync.Once
+	memoryMu      sync.Mutex
+)
+
+// currentGCPercent 获取当前GC触发阈值，调用方需持有memoryMu
+func currentGCPercent() int {
+	gcPercentOnce.Do(func() {
+		gcPercent = 100 // Go默认值
+		if config.AppConfig != nil {
+			gcPercent = config.AppConfig.GCPercent
+		}
+	})
+	return gcPercent
+}
+
+// GetMemoryStats 获取进程内存统计和当前GC设置
+func GetMemoryStats() MemoryStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	stats := MemoryStats{
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		HeapIdle:     m.HeapIdle,
+		HeapReleased: m.HeapReleased,
+		HeapSys:      m.HeapSys,
+		HeapObjects:  m.HeapObjects,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalMs: float64(m.PauseTotalNs) / float64(time.Millisecond),
+		Goroutines:   runtime.NumGoroutine(),
+	}
+	if m.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(m.LastGC))
+	}
+
+	memoryMu.Lock()
+	stats.GCPercent = currentGCPercent()
+	memoryMu.Unlock()
+
+	// 传入负数只读取当前上限，math.MaxInt64表示未设置上限
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		stats.MemoryLimitMB = limit >> 20
+	}
+	return stats
+}
+
+// UpdateMemorySettings 运行时调整GC触发阈值和软内存上限，参数为nil时保持不变；
+// memoryLimitMB为0时取消上限。修改只在本次运行中有效，重启后恢复为GC_PERCENT和MEMORY_LIMIT_MB的配置
+func UpdateMemorySettings(percent *int, memoryLimitMB *int64) error {
+	if percent != nil && (*percent < 1 || *percent > 1000) {
+		return ErrInvalidGCPercent
+	}
+	if memoryLimitMB != nil && (*memoryLimitMB < 0 || *memoryLimitMB > math.MaxInt64>>20) {
+		return ErrInvalidMemoryLimit
+	}
+
+	memoryMu.Lock()
+	defer memoryMu.Unlock()
+	if percent != nil {
+		currentGCPercent()
+		debug.SetGCPercent(*percent)
+		gcPercent = *percent
+	}
+	if memoryLimitMB != nil {
+		limit := int64(math.MaxInt64)
+		if *memoryLimitMB > 0 {
+			limit = *memoryLimitMB << 20
+		}
+		debug.SetMemoryLimit(limit)
+	}
+	return nil
+}
+
+// RunManualGC 立即执行GC并将空闲内存归还操作系统，返回前后的内存统计
+func RunManualGC() ManualGCResult {
+	before := GetMemoryStats()
+	start := time.Now()
+	runtime.GC()
+	debug.FreeOSMemory()
+	duration := time.Since(start)
+	after := GetMemoryStats()
+
+	return ManualGCResult{
+		Before:     before,
+		After:      after,
+		Freed:      int64(before.HeapAlloc) - int64(after.HeapAlloc),
+		DurationMs: float64(duration) / float64(time.Millisecond),
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -533,13 +534,29 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 				Links:     links,
 				Tags:      tags,
 				Images:    images,
+				MentionedChannels: extractChannelMentions(messageDiv, messageTextElem, messageText, channel),
 			})
 		}
 	})
 
+	// 更早消息的分页参数
+	nextPageParam = ExtractNextPageParam(html)
+
 	return results, nextPageParam, nil
 }
 
+// 搜索结果页中"加载更早消息"链接的分页游标
+var messagesMoreBeforePattern = regexp.MustCompile(`<a[^>]*\btme_messages_more\b[^>]*\bdata-before="(\d+)"`)
+
+// ExtractNextPageParam 从频道搜索结果页中提取更早一页的分页参数（如"before=123"），没有更多结果时返回空字符串。
+// 只做正则匹配，可以在完整解析页面之前取得游标以提前开始抓取下一页
+func ExtractNextPageParam(html string) string {
+	if m := messagesMoreBeforePattern.FindStringSubmatch(html); m != nil {
+		return "before=" + m[1]
+	}
+	return ""
+}
+
 // extractImageURLFromStyle 从CSS样式字符串中提取background-image的URL
 func extractImageURLFromStyle(style string) string {
 	// 查找background-image:url('...') 或 background-image:url("...")
@@ -0,0 +1,193 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrNotStarted 任务在等待信号量期间超时或被取消，没有开始执行。此时的错误同时包装了ErrNotStarted和上下文错误
+var ErrNotStarted = errors.New("任务未开始执行")
+
+// Result 任务结果。Err为任务返回的错误、任务中的panic，或任务未在上下文结束前完成时的上下文错误
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Group 在加权信号量的限制下并发执行任务，按提交顺序收集类型化的结果。
+// 上下文取消或超时后Wait立即返回，未完成任务的结果为上下文错误；任务通过参数中的ctx感知取消
+type Group[T any] struct {
+	ctx     context.Context
+	sem     *Semaphore
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	results []Result[T]
+	done    []bool
+	started []bool
+}
+
+// NewGroup 创建任务组，多个任务组可以共享同一个信号量以限制总并发
+func NewGroup[T any](ctx context.Context, sem *Semaphore) *Group[T] {
+	return &Group[T]{ctx: ctx, sem: sem}
+}
+
+// Go 提交任务，任务执行期间占用weight个单位的信号量容量（不足1按1计，超过总容量按总容量计）
+func (g *Group[T]) Go(weight int64, task func(context.Context) (T, error)) {
+	g.GoWithTimeout(weight, 0, task)
+}
+
+// GoWithTimeout 提交带独立超时的任务，超时从提交时开始计算（包括等待信号量的时间）。
+// 超时后该任务的结果为上下文错误并释放占用的信号量容量，不再等待任务返回；timeout<=0时只受任务组上下文限制
+func (g *Group[T]) GoWithTimeout(weight int64, timeout time.Duration, task func(context.Context) (T, error)) {
+	if weight < 1 {
+		weight = 1
+	}
+	if weight > g.sem.Size() {
+		weight = g.sem.Size()
+	}
+
+	g.mu.Lock()
+	index := len(g.results)
+	g.results = append(g.results, Result[T]{})
+	g.done = append(g.done, false)
+	g.started = append(g.started, false)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ctx := g.ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(g.ctx, timeout)
+			defer cancel()
+		}
+		if err := g.sem.Acquire(ctx, weight); err != nil {
+			g.set(index, Result[T]{Err: notStarted(err)})
+			return
+		}
+		defer g.sem.Release(weight)
+		g.mu.Lock()
+		g.started[index] = true
+		g.mu.Unlock()
+
+		if timeout <= 0 {
+			value, err := runTask(ctx, task)
+			g.set(index, Result[T]{Value: value, Err: err})
+			return
+		}
+
+		// 任务不一定响应取消，超时后不再等待，任务在后台继续执行直到返回
+		finished := make(chan Result[T], 1)
+		go func() {
+			value, err := runTask(ctx, task)
+			finished <- Result[T]{Value: value, Err: err}
+		}()
+		select {
+		case result := <-finished:
+			g.set(index, result)
+		case <-ctx.Done():
+			g.set(index, Result[T]{Err: ctx.Err()})
+		}
+	}()
+}
+
+// set 记录任务结果
+func (g *Group[T]) set(index int, result Result[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.results[index] = result
+	g.done[index] = true
+}
+
+// Wait 等待所有任务完成或上下文结束，返回与提交顺序一致的结果。应在提交完所有任务后调用
+func (g *Group[T]) Wait() []Result[T] {
+	finished := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-g.ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	results := make([]Result[T], len(g.results))
+	for i, result := range g.results {
+		if !g.done[i] {
+			err := g.ctx.Err()
+			if !g.started[i] {
+				err = notStarted(err)
+			}
+			result = Result[T]{Err: err}
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// notStarted 包装任务等待信号量时收到的上下文错误
+func notStarted(err error) error {
+	return fmt.Errorf("%w: %w", ErrNotStarted, err)
+}
+
+// runTask 执行任务，任务中的panic转为错误，不影响其他任务
+func runTask[T any](ctx context.Context, task func(context.Context) (T, error)) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("[工作池] 任务发生panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("任务发生panic: %v", r)
+		}
+	}()
+	return task(ctx)
+}
+
+// Run 最多同时执行limit个任务（limit<=0时不限制），返回与tasks顺序一致的结果
+func Run[T any](ctx context.Context, limit int, tasks []func(context.Context) (T, error)) []Result[T] {
+	if len(tasks) == 0 {
+		return nil
+	}
+	if limit <= 0 || limit > len(tasks) {
+		limit = len(tasks)
+	}
+	group := NewGroup[T](ctx, NewSemaphore(int64(limit)))
+	for _, task := range tasks {
+		group.Go(1, task)
+	}
+	return group.Wait()
+}
+
+// RunWithTimeout 带超时执行任务，超时后返回已完成任务的结果，未完成的任务收到取消信号
+func RunWithTimeout[T any](timeout time.Duration, limit int, tasks []func(context.Context) (T, error)) []Result[T] {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return Run(ctx, limit, tasks)
+}
+
+// RunWithTaskTimeouts 带总超时执行任务，taskTimeouts[i]为tasks[i]的独立超时（<=0或未提供时只受总超时限制），
+// 使慢任务不能占满整个超时窗口。返回与tasks顺序一致的结果
+func RunWithTaskTimeouts[T any](timeout time.Duration, limit int, tasks []func(context.Context) (T, error), taskTimeouts []time.Duration) []Result[T] {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if limit <= 0 || limit > len(tasks) {
+		limit = len(tasks)
+	}
+	group := NewGroup[T](ctx, NewSemaphore(int64(limit)))
+	for i, task := range tasks {
+		var taskTimeout time.Duration
+		if i < len(taskTimeouts) {
+			taskTimeout = taskTimeouts[i]
+		}
+		group.GoWithTimeout(1, taskTimeout, task)
+	}
+	return group.Wait()
+}
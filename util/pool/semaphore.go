@@ -0,0 +1,115 @@
+package pool
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Semaphore 加权信号量：任务按权重占用容量，容量不足时按先来先得的顺序等待
+type Semaphore struct {
+	size    int64
+	cur     int64
+	mu      sync.Mutex
+	waiters list.List
+}
+
+// semaphoreWaiter 等待获取容量的调用方
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore 创建总容量为size的信号量
+func NewSemaphore(size int64) *Semaphore {
+	if size <= 0 {
+		size = 1
+	}
+	return &Semaphore{size: size}
+}
+
+// Size 信号量的总容量
+func (s *Semaphore) Size() int64 {
+	return s.size
+}
+
+// Acquire 获取n个单位的容量，ctx取消时放弃等待并返回ctx.Err()
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	if n > s.size {
+		// 超过总容量的请求永远无法满足
+		s.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, ready: ready})
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// 取消的同时已获取到容量，归还后返回取消
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 队首放弃等待后，后面较小的请求可能已经可以满足
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire 不等待地获取n个单位的容量，成功返回true
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release 归还n个单位的容量
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("pool: 信号量归还的容量多于获取的容量")
+	}
+	s.notifyWaiters()
+}
+
+// notifyWaiters 按顺序唤醒容量足够的等待者，队首无法满足时停止，避免大请求被饿死
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pansou/model"
+)
+
+// 分辨率从高到低排列，ResolutionRank按此顺序比较
+var resolutionOrder = []string{"4320p", "2160p", "1080p", "720p", "480p"}
+
+var (
+	// 分辨率标注，按从高到低的顺序匹配，标题中同时出现多个时取最高的
+	resolutionPatterns = []struct {
+		resolution string
+		pattern    *regexp.Regexp
+	}{
+		{"4320p", regexp.MustCompile(`(?i)\b(4320p|8k)\b|7680\s*[x×]\s*4320`)},
+		{"2160p", regexp.MustCompile(`(?i)\b(2160p|4k|uhd)\b|3840\s*[x×]\s*2160`)},
+		{"1080p", regexp.MustCompile(`(?i)\b(1080[pi]|fhd)\b|1920\s*[x×]\s*1080`)},
+		{"720p", regexp.MustCompile(`(?i)\b720p\b|1280\s*[x×]\s*720`)},
+		{"480p", regexp.MustCompile(`(?i)\b(480p|576p)\b|标清`)},
+	}
+	codecPatterns = []struct {
+		codec   string
+		pattern *regexp.Regexp
+	}{
+		{"h265", regexp.MustCompile(`(?i)\b(h\.?265|x265|hevc)\b`)},
+		{"h264", regexp.MustCompile(`(?i)\b(h\.?264|x264|avc)\b`)},
+		{"av1", regexp.MustCompile(`(?i)\bav1\b`)},
+	}
+	hdrRegex       = regexp.MustCompile(`(?i)\b(hdr(10\+?)?|dolby\s*vision|dovi)\b|杜比视界`)
+	fpsRegex       = regexp.MustCompile(`(?i)\b(\d{2,3})\s*(?:fps|帧)`)
+	subtitlesRegex = regexp.MustCompile(`中字|字幕|双语|简繁|中英|内封|内嵌|外挂字`)
+	completeRegex  = regexp.MustCompile(`完结|全集|全\d+集|全[一二三四五六七八九十百]+集`)
+)
+
+// ParseQualityAttributes 从标题中解析分辨率、编码、HDR、帧率、字幕、完结和大小标注，
+// 没有任何标注时返回nil
+func ParseQualityAttributes(title string) *model.ResultAttributes {
+	if title == "" {
+		return nil
+	}
+	var attrs model.ResultAttributes
+	for _, p := range resolutionPatterns {
+		if p.pattern.MatchString(title) {
+			attrs.Resolution = p.resolution
+			break
+		}
+	}
+	for _, p := range codecPatterns {
+		if p.pattern.MatchString(title) {
+			attrs.Codec = p.codec
+			break
+		}
+	}
+	attrs.HDR = hdrRegex.MatchString(title)
+	if m := fpsRegex.FindStringSubmatch(title); m != nil {
+		// 只接受24~240之间的帧率，避免误匹配其他数字
+		if fps, err := strconv.Atoi(m[1]); err == nil && fps >= 24 && fps <= 240 {
+			attrs.FPS = fps
+		}
+	}
+	attrs.Subtitles = subtitlesRegex.MatchString(title)
+	attrs.Complete = completeRegex.MatchString(title)
+	attrs.Size = ExtractLinkMeta(title, time.Time{}).Size
+
+	if attrs == (model.ResultAttributes{}) {
+		return nil
+	}
+	return &attrs
+}
+
+// ResolutionRank 分辨率的等级，数值越大分辨率越高，未知分辨率返回0。
+// 接受2160p、4k、8k、1080等写法
+func ResolutionRank(resolution string) int {
+	resolution = NormalizeResolution(resolution)
+	for i, r := range resolutionOrder {
+		if r == resolution {
+			return len(resolutionOrder) - i
+		}
+	}
+	return 0
+}
+
+// NormalizeResolution 规范化分辨率参数，无法识别时返回空字符串
+func NormalizeResolution(resolution string) string {
+	resolution = strings.ToLower(strings.TrimSpace(resolution))
+	switch resolution {
+	case "8k":
+		return "4320p"
+	case "4k", "uhd":
+		return "2160p"
+	case "fhd", "1080i":
+		return "1080p"
+	case "576p":
+		return "480p"
+	}
+	if !strings.HasSuffix(resolution, "p") {
+		resolution += "p"
+	}
+	for _, r := range resolutionOrder {
+		if r == resolution {
+			return r
+		}
+	}
+	return ""
+}
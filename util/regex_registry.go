@@ -0,0 +1,67 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// 预编译正则注册表：请求路径上使用的正则统一在包初始化时编译并登记，
+// 避免在函数内部重复调用regexp.MustCompile
+var (
+	patternRegistry   = make(map[string]*regexp.Regexp)
+	patternRegistryMu sync.RWMutex
+)
+
+// MustRegisterPattern 编译并登记正则，名称重复或表达式非法时panic（仅应在包初始化时调用）
+func MustRegisterPattern(name, expr string) *regexp.Regexp {
+	re := regexp.MustCompile(expr)
+
+	patternRegistryMu.Lock()
+	defer patternRegistryMu.Unlock()
+	if _, exists := patternRegistry[name]; exists {
+		panic(fmt.Sprintf("正则名称重复注册: %s", name))
+	}
+	patternRegistry[name] = re
+	return re
+}
+
+// GetPattern 按名称获取已登记的正则，不存在时返回nil
+func GetPattern(name string) *regexp.Regexp {
+	patternRegistryMu.RLock()
+	defer patternRegistryMu.RUnlock()
+	return patternRegistry[name]
+}
+
+// RegisteredPatterns 返回所有已登记的正则名称（按名称排序）
+func RegisteredPatterns() []string {
+	patternRegistryMu.RLock()
+	defer patternRegistryMu.RUnlock()
+	names := make([]string, 0, len(patternRegistry))
+	for name := range patternRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 搜索服务解析消息内容使用的正则
+var (
+	// GenericLinkPattern 匹配任意http/https链接
+	GenericLinkPattern = MustRegisterPattern("generic_link", `https?://[^\s"']+`)
+	// TrailingTitlePattern 匹配链接前文本末尾的标题（含清晰度、更新集数等后缀）
+	TrailingTitlePattern = MustRegisterPattern("trailing_title", `([^链地资网\s]+?(?:\([^)]+\))?(?:\s*\d+K)?(?:\s*臻彩)?(?:\s*MAX)?(?:\s*HDR)?(?:\s*更(?:新)?\d+集))$`)
+	// EmojiPattern 匹配表情符号和特殊符号
+	EmojiPattern = MustRegisterPattern("emoji", `[\p{So}\p{Sk}]`)
+)
+
+// 提取码解析使用的正则
+var (
+	// TianyiAccessCodePattern 天翼云盘访问码（支持URL编码形式）
+	TianyiAccessCodePattern = MustRegisterPattern("tianyi_access_code", `(?:（访问码：|%EF%BC%88%E8%AE%BF%E9%97%AE%E7%A0%81%EF%BC%9A)([a-zA-Z0-9]+)(?:）|%EF%BC%89)`)
+	// Pan115PasswordPattern 115网盘URL中的password参数
+	Pan115PasswordPattern = MustRegisterPattern("pan115_password", `password=([a-zA-Z0-9]{4})`)
+	// Pan123ExtractCodePattern 123网盘URL中的提取码（支持URL编码形式）
+	Pan123ExtractCodePattern = MustRegisterPattern("pan123_extract_code", `(?:提取码|%E6%8F%90%E5%8F%96%E7%A0%81)[:：]([a-zA-Z0-9]+)`)
+)
@@ -7,30 +7,42 @@ import (
 )
 
 // 通用网盘链接匹配正则表达式 - 修改为更精确的匹配模式（已移除magnet和ed2k支持）
-var AllPanLinksPattern = regexp.MustCompile(`(?i)(?:https?://(?:(?:[\w.-]+\.)?(?:pan\.(?:baidu|quark)\.cn|(?:www\.)?(?:alipan|aliyundrive)\.com|drive\.uc\.cn|cloud\.189\.cn|caiyun\.139\.com|(?:www\.)?123(?:684|685|912|pan|592)\.(?:com|cn)|115\.com|115cdn\.com|anxia\.com|pan\.xunlei\.com|mypikpak\.com))(?:/[^\s'"<>()]*)?)`)
+var AllPanLinksPattern = MustRegisterPattern("all_pan_links", `(?i)(?:https?://(?:(?:[\w.-]+\.)?(?:pan\.(?:baidu|quark)\.cn|(?:www\.)?(?:alipan|aliyundrive)\.com|drive\.uc\.cn|cloud\.189\.cn|caiyun\.139\.com|(?:www\.)?123(?:684|685|912|pan|592)\.(?:com|cn)|115\.com|115cdn\.com|anxia\.com|pan\.xunlei\.com|mypikpak\.com))(?:/[^\s'"<>()]*)?)`)
 
 // 单独定义各种网盘的链接匹配模式，以便更精确地提取
 // 修改百度网盘链接正则表达式，确保只匹配到链接本身，不包含后面的文本
-var BaiduPanPattern = regexp.MustCompile(`https?://pan\.baidu\.com/s/[a-zA-Z0-9_-]+(?:\?pwd=[a-zA-Z0-9]{4})?`)
-var QuarkPanPattern = regexp.MustCompile(`https?://pan\.quark\.cn/s/[a-zA-Z0-9]+`)
-var XunleiPanPattern = regexp.MustCompile(`https?://pan\.xunlei\.com/s/[a-zA-Z0-9]+(?:\?pwd=[a-zA-Z0-9]+)?(?:#)?`)
+var BaiduPanPattern = MustRegisterPattern("baidu_pan", `https?://pan\.baidu\.com/s/[a-zA-Z0-9_-]+(?:\?pwd=[a-zA-Z0-9]{4})?`)
+var QuarkPanPattern = MustRegisterPattern("quark_pan", `https?://pan\.quark\.cn/s/[a-zA-Z0-9]+`)
+var XunleiPanPattern = MustRegisterPattern("xunlei_pan", `https?://pan\.xunlei\.com/s/[a-zA-Z0-9]+(?:\?pwd=[a-zA-Z0-9]+)?(?:#)?`)
 // 添加天翼云盘链接正则表达式 - 精确匹配，支持URL编码的访问码
-var TianyiPanPattern = regexp.MustCompile(`https?://cloud\.189\.cn/t/[a-zA-Z0-9]+(?:%[0-9A-Fa-f]{2})*(?:（[^）]*）)?`)
+var TianyiPanPattern = MustRegisterPattern("tianyi_pan", `https?://cloud\.189\.cn/t/[a-zA-Z0-9]+(?:%[0-9A-Fa-f]{2})*(?:（[^）]*）)?`)
 // 添加UC网盘链接正则表达式
-var UCPanPattern = regexp.MustCompile(`https?://drive\.uc\.cn/s/[a-zA-Z0-9]+(?:\?public=\d)?`)
+var UCPanPattern = MustRegisterPattern("uc_pan", `https?://drive\.uc\.cn/s/[a-zA-Z0-9]+(?:\?public=\d)?`)
 // 添加123网盘链接正则表达式
-var Pan123Pattern = regexp.MustCompile(`https?://(?:www\.)?123(?:684|685|912|pan|592)\.(?:com|cn)/s/[a-zA-Z0-9_-]+(?:\?(?:%E6%8F%90%E5%8F%96%E7%A0%81|提取码)[:：][a-zA-Z0-9]+)?`)
+var Pan123Pattern = MustRegisterPattern("pan123", `https?://(?:www\.)?123(?:684|685|912|pan|592)\.(?:com|cn)/s/[a-zA-Z0-9_-]+(?:\?(?:%E6%8F%90%E5%8F%96%E7%A0%81|提取码)[:：][a-zA-Z0-9]+)?`)
 // 添加115网盘链接正则表达式
-var Pan115Pattern = regexp.MustCompile(`https?://(?:115\.com|115cdn\.com|anxia\.com)/s/[a-zA-Z0-9]+(?:\?password=[a-zA-Z0-9]{4})?(?:#)?`)
+var Pan115Pattern = MustRegisterPattern("pan115", `https?://(?:115\.com|115cdn\.com|anxia\.com)/s/[a-zA-Z0-9]+(?:\?password=[a-zA-Z0-9]{4})?(?:#)?`)
 // 添加阿里云盘链接正则表达式
-var AliyunPanPattern = regexp.MustCompile(`https?://(?:www\.)?(?:alipan|aliyundrive)\.com/s/[a-zA-Z0-9]+`)
+var AliyunPanPattern = MustRegisterPattern("aliyun_pan", `https?://(?:www\.)?(?:alipan|aliyundrive)\.com/s/[a-zA-Z0-9]+`)
 
 // 提取码匹配正则表达式 - 增强提取密码的能力
-var PasswordPattern = regexp.MustCompile(`(?i)(?:(?:提取|访问|提取密|密)码|pwd)[：:]\s*([a-zA-Z0-9]{4})`)
-var UrlPasswordPattern = regexp.MustCompile(`(?i)[?&]pwd=([a-zA-Z0-9]{4})`)
+var PasswordPattern = MustRegisterPattern("password", `(?i)(?:(?:提取|访问|提取密|密)码|pwd)[：:]\s*([a-zA-Z0-9]{4})`)
+var UrlPasswordPattern = MustRegisterPattern("url_password", `(?i)[?&]pwd=([a-zA-Z0-9]{4})`)
 
 // 百度网盘密码专用正则表达式 - 确保只提取4位密码
-var BaiduPasswordPattern = regexp.MustCompile(`(?i)(?:链接：.*?提取码：|密码：|提取码：|pwd=|pwd:|pwd：)([a-zA-Z0-9]{4})`)
+var BaiduPasswordPattern = MustRegisterPattern("baidu_password", `(?i)(?:链接：.*?提取码：|密码：|提取码：|pwd=|pwd:|pwd：)([a-zA-Z0-9]{4})`)
+
+// PanLinkPatterns 各网盘精确链接正则集合，用于从无换行的文本中逐类提取链接
+var PanLinkPatterns = []*regexp.Regexp{
+	TianyiPanPattern,  // 天翼云盘
+	BaiduPanPattern,   // 百度网盘
+	QuarkPanPattern,   // 夸克网盘
+	AliyunPanPattern,  // 阿里云盘
+	UCPanPattern,      // UC网盘
+	Pan123Pattern,     // 123网盘
+	Pan115Pattern,     // 115网盘
+	XunleiPanPattern,  // 迅雷网盘
+}
 
 // GetLinkType 获取链接类型
 func GetLinkType(url string) string {
@@ -402,8 +414,7 @@ func ExtractPassword(content, url string) string {
 	// 特殊处理天翼云盘URL中的访问码
 	if strings.Contains(url, "cloud.189.cn") {
 		// 天翼云盘访问码格式：（访问码：xxxx）或者URL编码形式
-		tianyiPasswordPattern := regexp.MustCompile(`(?:（访问码：|%EF%BC%88%E8%AE%BF%E9%97%AE%E7%A0%81%EF%BC%9A)([a-zA-Z0-9]+)(?:）|%EF%BC%89)`)
-		tianyiMatches := tianyiPasswordPattern.FindStringSubmatch(url)
+		tianyiMatches := TianyiAccessCodePattern.FindStringSubmatch(url)
 		if len(tianyiMatches) > 1 {
 			return tianyiMatches[1]
 		}
@@ -426,8 +437,7 @@ func ExtractPassword(content, url string) string {
 		strings.Contains(url, "password=") {
 		
 		// 尝试从URL中提取密码
-		passwordPattern := regexp.MustCompile(`password=([a-zA-Z0-9]{4})`)
-		passwordMatches := passwordPattern.FindStringSubmatch(url)
+		passwordMatches := Pan115PasswordPattern.FindStringSubmatch(url)
 		if len(passwordMatches) > 1 {
 			return passwordMatches[1]
 		}
@@ -443,8 +453,7 @@ func ExtractPassword(content, url string) string {
 		(strings.Contains(url, "提取码") || strings.Contains(url, "%E6%8F%90%E5%8F%96%E7%A0%81")) {
 		
 		// 尝试从URL中提取提取码（处理普通文本和URL编码两种情况）
-		extractCodePattern := regexp.MustCompile(`(?:提取码|%E6%8F%90%E5%8F%96%E7%A0%81)[:：]([a-zA-Z0-9]+)`)
-		codeMatches := extractCodePattern.FindStringSubmatch(url)
+		codeMatches := Pan123ExtractCodePattern.FindStringSubmatch(url)
 		if len(codeMatches) > 1 {
 			return codeMatches[1]
 		}
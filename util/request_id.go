@@ -0,0 +1,70 @@
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader 请求ID的HTTP头名称
+const RequestIDHeader = "X-Request-ID"
+
+// 请求ID最大长度，超过则视为非法并重新生成
+const maxRequestIDLength = 64
+
+// requestIDKey 上下文中保存请求ID的键类型
+type requestIDKey struct{}
+
+// NewRequestID 生成新的请求ID（16位十六进制）
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		// 随机源不可用时退化为时间戳
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// NormalizeRequestID 校验客户端传入的请求ID，非法时返回空字符串
+func NormalizeRequestID(id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" || len(id) > maxRequestIDLength {
+		return ""
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return ""
+		}
+	}
+	return id
+}
+
+// WithRequestID 将请求ID写入上下文
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext 从上下文中获取请求ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// RequestLogTag 返回用于日志前缀的请求ID标记，如 "[req:abcd] "，无ID时返回空字符串
+func RequestLogTag(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "[req:" + id + "] "
+}
@@ -0,0 +1,222 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+)
+
+const (
+	// 单个响应体超过此大小时不缓存
+	maxCachedResponseSize = 4 << 20
+	// 缓存的响应数上限，超出时淘汰最早写入的响应
+	maxCachedResponses = 256
+)
+
+// responseCacheKey 请求上下文中标记可缓存请求的键
+type responseCacheKey struct{}
+
+// cachedResponse 缓存的GET响应
+type cachedResponse struct {
+	status       string
+	statusCode   int
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+	storedAt     time.Time
+}
+
+var (
+	responseCacheMu sync.Mutex
+	responseCache   = make(map[string]*cachedResponse)
+
+	responseCacheHits        int64
+	responseCacheRevalidated int64
+	responseCacheMisses      int64
+)
+
+// WithResponseCache 标记请求的响应可以在DISCOVERY_CACHE_TTL内复用，用于插件获取buildId、
+// 扫描JS文件等开销大、内容很少变化的发现类请求。只对GET请求生效，缓存按插件和URL区分
+func WithResponseCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseCacheKey{}, true)
+}
+
+// ResponseCacheTransport 缓存带有WithResponseCache标记的GET请求的响应体。
+// 缓存过期后如果响应带有ETag或Last-Modified，发送条件请求，服务器返回304时继续使用缓存的响应体
+type ResponseCacheTransport struct {
+	name string
+	base http.RoundTripper
+}
+
+// NewResponseCacheTransport 创建缓存发现类请求响应的传输层
+func NewResponseCacheTransport(name string, base http.RoundTripper) *ResponseCacheTransport {
+	return &ResponseCacheTransport{name: name, base: base}
+}
+
+// RoundTrip 命中未过期的缓存时直接返回，否则发出（条件）请求并缓存200响应
+func (t *ResponseCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ttl := responseCacheTTL()
+	if ttl <= 0 || req.Method != http.MethodGet || req.Context().Value(responseCacheKey{}) == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	key := t.name + " " + req.URL.String()
+	responseCacheMu.Lock()
+	entry := responseCache[key]
+	responseCacheMu.Unlock()
+
+	if entry != nil && time.Since(entry.storedAt) < ttl {
+		atomic.AddInt64(&responseCacheHits, 1)
+		return entry.response(req), nil
+	}
+
+	outReq := req
+	if entry != nil && (entry.etag != "" || entry.lastModified != "") {
+		outReq = req.Clone(req.Context())
+		if entry.etag != "" && outReq.Header.Get("If-None-Match") == "" {
+			outReq.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" && outReq.Header.Get("If-Modified-Since") == "" {
+			outReq.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		atomic.AddInt64(&responseCacheRevalidated, 1)
+		refreshed := *entry
+		refreshed.storedAt = time.Now()
+		storeCachedResponse(key, &refreshed)
+		return refreshed.response(req), nil
+	}
+
+	atomic.AddInt64(&responseCacheMisses, 1)
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedResponseSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedResponseSize {
+		// 响应过大，不缓存，已读取的部分和剩余部分一起返回
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	entry = &cachedResponse{
+		status:       resp.Status,
+		statusCode:   resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		storedAt:     time.Now(),
+	}
+	storeCachedResponse(key, entry)
+	return entry.response(req), nil
+}
+
+// CloseIdleConnections 关闭底层传输层的空闲连接
+func (t *ResponseCacheTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// response 用缓存的响应构造新的响应
+func (e *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// storeCachedResponse 写入缓存，超出数量上限时淘汰最早写入的响应
+func storeCachedResponse(key string, entry *cachedResponse) {
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+	if _, ok := responseCache[key]; !ok && len(responseCache) >= maxCachedResponses {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range responseCache {
+			if oldestKey == "" || e.storedAt.Before(oldest) {
+				oldestKey, oldest = k, e.storedAt
+			}
+		}
+		delete(responseCache, oldestKey)
+	}
+	responseCache[key] = entry
+}
+
+// ClearResponseCache 清除插件缓存的发现类请求响应，如站点更新后缓存的首页已失效时
+func ClearResponseCache(name string) {
+	prefix := name + " "
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+	for key := range responseCache {
+		if strings.HasPrefix(key, prefix) {
+			delete(responseCache, key)
+		}
+	}
+}
+
+// responseCacheTTL 发现类请求响应的缓存时间，为0时不缓存
+func responseCacheTTL() time.Duration {
+	if config.AppConfig == nil {
+		return 0
+	}
+	return config.AppConfig.DiscoveryCacheTTL
+}
+
+// ResponseCacheStats 发现类请求响应缓存的统计
+type ResponseCacheStats struct {
+	Entries     int   `json:"entries"`     // 缓存的响应数
+	Bytes       int64 `json:"bytes"`       // 缓存的响应体总大小
+	Hits        int64 `json:"hits"`        // 直接使用缓存的次数
+	Revalidated int64 `json:"revalidated"` // 条件请求返回304、继续使用缓存的次数
+	Misses      int64 `json:"misses"`      // 重新获取响应的次数
+}
+
+// GetResponseCacheStats 获取发现类请求响应缓存的统计
+func GetResponseCacheStats() ResponseCacheStats {
+	stats := ResponseCacheStats{
+		Hits:        atomic.LoadInt64(&responseCacheHits),
+		Revalidated: atomic.LoadInt64(&responseCacheRevalidated),
+		Misses:      atomic.LoadInt64(&responseCacheMisses),
+	}
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+	stats.Entries = len(responseCache)
+	for _, e := range responseCache {
+		stats.Bytes += int64(len(e.body))
+	}
+	return stats
+}
@@ -0,0 +1,391 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"pansou/config"
+)
+
+// 默认重试参数
+const (
+	defaultRetryAttempts  = 2
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 2 * time.Second
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数（含首次请求），小于等于1时不重试
+	BaseDelay   time.Duration // 首次重试前的退避时间，之后每次翻倍
+	MaxDelay    time.Duration // 单次退避时间上限
+	// 是否重试非幂等请求（如POST）。默认只重试GET/HEAD/OPTIONS，
+	// 插件通过DoWithRetry显式要求重试时视为调用方确认请求可重放
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy 插件客户端默认重试策略：网络错误及429/5xx响应最多重试一次
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+	}
+}
+
+// Backoff 计算第n次重试（从1开始）前的等待时间：指数退避，实际等待在[d/2, d)之间随机抖动，
+// 避免多个插件同时失败后同步重试
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if p.BaseDelay <= 0 || retry <= 0 {
+		return 0
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// RetryBudget 一次搜索内可用的重试次数，同一次搜索的所有请求共享，
+// 避免上游故障时每个请求都重试到上限而放大流量
+type RetryBudget struct {
+	remaining int64
+}
+
+// NewRetryBudget 创建重试预算，n小于等于0时返回nil（不限制）
+func NewRetryBudget(n int) *RetryBudget {
+	if n <= 0 {
+		return nil
+	}
+	return &RetryBudget{remaining: int64(n)}
+}
+
+// NewSearchRetryBudget 按PLUGIN_RETRY_BUDGET配置创建单次插件搜索的重试预算
+func NewSearchRetryBudget() *RetryBudget {
+	if config.AppConfig == nil {
+		return nil
+	}
+	return NewRetryBudget(config.AppConfig.PluginRetryBudget)
+}
+
+// TryAcquire 消耗一次重试机会，预算用尽时返回false。nil预算不限制
+func (b *RetryBudget) TryAcquire() bool {
+	if b == nil {
+		return true
+	}
+	return atomic.AddInt64(&b.remaining, -1) >= 0
+}
+
+// IsRetryableStatus 判断响应状态码是否值得重试：请求超时、限流及网关/服务端临时错误
+func IsRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsRetryableError 判断请求错误是否值得重试：超时、连接被拒绝/重置、连接意外断开等临时网络错误可重试，
+// 调用方取消、证书错误等不可重试
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// retryPolicyKey 上下文中保存单次请求重试策略的键类型
+type retryPolicyKey struct{}
+
+// WithRetryPolicy 为单次请求指定重试策略，覆盖传输层的默认策略
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// RetryTransport 按重试策略重试临时失败请求的HTTP传输层，
+// 重试次数受所属搜索的重试预算约束，并按名称（通常为插件名）统计重试情况
+type RetryTransport struct {
+	name   string
+	base   http.RoundTripper
+	policy RetryPolicy
+	budget *RetryBudget
+	stats  *retryCounters
+}
+
+// NewRetryTransport 创建带重试的传输层，base为nil时使用http.DefaultTransport
+func NewRetryTransport(name string, base http.RoundTripper, policy RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		name:   name,
+		base:   base,
+		policy: policy,
+		stats:  getRetryCounters(name),
+	}
+}
+
+// NewPluginTransport 插件HTTP客户端使用的传输层：独立连接池（受全局连接预算约束）、站点失效检测、
+// 所有插件共享的按主机限流加默认重试策略
+func NewPluginTransport(name string, base *http.Transport) *RetryTransport {
+	pooled := NewPooledTransport(name, base)
+	return NewRetryTransport(name, NewResponseCacheTransport(name, NewHostLimitTransport(NewDomainHealthTransport(name, pooled))), DefaultRetryPolicy())
+}
+
+// WithBudget 返回共享底层传输层、使用指定重试预算的副本，用于为每次搜索单独计算预算
+func (t *RetryTransport) WithBudget(budget *RetryBudget) *RetryTransport {
+	clone := *t
+	clone.budget = budget
+	return &clone
+}
+
+// CloseIdleConnections 关闭底层传输层的空闲连接
+func (t *RetryTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.policy
+	if override, ok := req.Context().Value(retryPolicyKey{}).(RetryPolicy); ok {
+		policy = override
+	}
+	atomic.AddInt64(&t.stats.requests, 1)
+
+	attempt := req
+	for i := 1; ; i++ {
+		start := time.Now()
+		resp, err := t.base.RoundTrip(attempt)
+		t.debugAttempt(attempt, i, resp, err, time.Since(start))
+		retryable := err != nil && IsRetryableError(err) || err == nil && IsRetryableStatus(resp.StatusCode)
+		if !retryable || i >= policy.MaxAttempts || !canRetryRequest(req, policy) || req.Context().Err() != nil {
+			if i > 1 {
+				if err == nil && !retryable {
+					atomic.AddInt64(&t.stats.recovered, 1)
+				} else {
+					atomic.AddInt64(&t.stats.failures, 1)
+				}
+			}
+			return resp, err
+		}
+		if !t.budget.TryAcquire() {
+			atomic.AddInt64(&t.stats.budgetExhausted, 1)
+			return resp, err
+		}
+
+		delay := policy.Backoff(i)
+		if resp != nil {
+			if after := retryAfter(resp, policy.MaxDelay); after > delay {
+				delay = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		next, rewindErr := rewindRequest(req)
+		if rewindErr != nil {
+			return nil, rewindErr
+		}
+		atomic.AddInt64(&t.stats.retries, 1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		attempt = next
+	}
+}
+
+// debugAttempt 插件处于调试日志期间时输出每次请求的结果
+func (t *RetryTransport) debugAttempt(req *http.Request, attempt int, resp *http.Response, err error, elapsed time.Duration) {
+	if !PluginDebugEnabled(t.name) {
+		return
+	}
+	if err != nil {
+		PluginDebugf(t.name, "%s %s | 第%d次 | 耗时: %v | 错误: %v", req.Method, req.URL.Redacted(), attempt, elapsed, err)
+		return
+	}
+	PluginDebugf(t.name, "%s %s | 第%d次 | 耗时: %v | 状态码: %d", req.Method, req.URL.Redacted(), attempt, elapsed, resp.StatusCode)
+}
+
+// canRetryRequest 判断请求是否可以安全重放
+func canRetryRequest(req *http.Request, policy RetryPolicy) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if policy.RetryNonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// rewindRequest 复制请求并重新获取请求体
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// retryAfter 解析限流响应的Retry-After头（秒数），超过上限时忽略
+func retryAfter(resp *http.Response, maxDelay time.Duration) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	after := time.Duration(seconds) * time.Second
+	if maxDelay > 0 && after > maxDelay {
+		return 0
+	}
+	return after
+}
+
+// DoWithRetry 按指定策略发送请求，只有200响应视为成功，其他状态码关闭响应体并返回错误。
+// 客户端传输层为RetryTransport时沿用其统计名称和重试预算，否则按请求域名统计且不限制预算
+func DoWithRetry(client *http.Client, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+	if _, ok := client.Transport.(*RetryTransport); !ok {
+		wrapped := *client
+		wrapped.Transport = NewRetryTransport(req.URL.Host, client.Transport, DefaultRetryPolicy())
+		client = &wrapped
+	}
+
+	policy.RetryNonIdempotent = true
+	resp, err := client.Do(req.WithContext(WithRetryPolicy(req.Context(), policy)))
+	if err != nil {
+		return nil, fmt.Errorf("请求失败（最多尝试%d次）: %w", policy.MaxAttempts, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("请求失败（最多尝试%d次）: HTTP状态码: %d", policy.MaxAttempts, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// retryCounters 单个名称的重试计数
+type retryCounters struct {
+	requests        int64
+	retries         int64
+	recovered       int64
+	failures        int64
+	budgetExhausted int64
+}
+
+// 重试统计最多记录的名称数。DoWithRetry按请求域名统计，域名来自搜索结果时数量不受控制，
+// 超过上限后新名称的统计计入retryOverflowName
+const (
+	maxRetryCounterNames = 256
+	retryOverflowName    = "other"
+)
+
+var (
+	retryCountersMu sync.Mutex
+	retryCounterMap = make(map[string]*retryCounters)
+)
+
+// getRetryCounters 获取或创建指定名称的重试计数
+func getRetryCounters(name string) *retryCounters {
+	retryCountersMu.Lock()
+	defer retryCountersMu.Unlock()
+	counters, ok := retryCounterMap[name]
+	if !ok {
+		if len(retryCounterMap) >= maxRetryCounterNames {
+			name = retryOverflowName
+			if counters, ok = retryCounterMap[name]; ok {
+				return counters
+			}
+		}
+		counters = &retryCounters{}
+		retryCounterMap[name] = counters
+	}
+	return counters
+}
+
+// RetryStats 单个插件（或域名）的重试统计
+type RetryStats struct {
+	Name            string `json:"name"`
+	Requests        int64  `json:"requests"`         // 请求数（不含重试）
+	Retries         int64  `json:"retries"`          // 重试次数
+	Recovered       int64  `json:"recovered"`        // 重试后成功的请求数
+	Failures        int64  `json:"failures"`         // 重试到上限仍失败的请求数
+	BudgetExhausted int64  `json:"budget_exhausted"` // 因搜索重试预算用尽而放弃重试的次数
+}
+
+// GetRetryStats 获取各插件的重试统计，按重试次数倒序
+func GetRetryStats() []RetryStats {
+	retryCountersMu.Lock()
+	stats := make([]RetryStats, 0, len(retryCounterMap))
+	for name, c := range retryCounterMap {
+		stats = append(stats, RetryStats{
+			Name:            name,
+			Requests:        atomic.LoadInt64(&c.requests),
+			Retries:         atomic.LoadInt64(&c.retries),
+			Recovered:       atomic.LoadInt64(&c.recovered),
+			Failures:        atomic.LoadInt64(&c.failures),
+			BudgetExhausted: atomic.LoadInt64(&c.budgetExhausted),
+		})
+	}
+	retryCountersMu.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Retries != stats[j].Retries {
+			return stats[i].Retries > stats[j].Retries
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
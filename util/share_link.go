@@ -0,0 +1,147 @@
+package util
+
+import (
+	netUrl "net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// shareLinkRule 一种网盘分享链接的规范化规则
+type shareLinkRule struct {
+	host           string   // 规范主机名
+	hostAliases    []string // 指向同一服务的其他主机名，统一替换为规范主机名
+	passwordParams []string // 携带提取码的参数，移入Password字段
+	keepParams     []string // 分享链接必需的参数，其余参数（跟踪参数等）被去除
+	keepQuery      bool     // 查询串本身是分享ID（如移动云盘），原样保留
+	passwordLen    int      // 提取码的固定长度，超出部分截断，不足视为无效；0表示不限制
+}
+
+// shareLinkRules 按网盘类型的规范化规则，未列出的类型不处理
+var shareLinkRules = map[string]shareLinkRule{
+	"baidu":  {host: "pan.baidu.com", hostAliases: []string{"yun.baidu.com"}, passwordParams: []string{"pwd"}, keepParams: []string{"surl"}, passwordLen: 4},
+	"aliyun": {host: "www.alipan.com", hostAliases: []string{"alipan.com", "aliyundrive.com", "www.aliyundrive.com"}, passwordParams: []string{"pwd"}},
+	"quark":  {host: "pan.quark.cn", passwordParams: []string{"pwd"}},
+	"uc":     {host: "drive.uc.cn", passwordParams: []string{"pwd"}, keepParams: []string{"public"}},
+	"tianyi": {host: "cloud.189.cn", passwordParams: []string{"accessCode", "pwd"}, keepParams: []string{"code"}},
+	"xunlei": {host: "pan.xunlei.com", passwordParams: []string{"pwd"}, passwordLen: 4},
+	"115":    {host: "115.com", hostAliases: []string{"115cdn.com", "anxia.com"}, passwordParams: []string{"password", "pwd"}},
+	"123": {host: "www.123pan.com", hostAliases: []string{"123pan.com", "123pan.cn", "www.123pan.cn",
+		"123684.com", "www.123684.com", "123685.com", "www.123685.com", "123912.com", "www.123912.com", "123592.com", "www.123592.com"},
+		passwordParams: []string{"pwd"}},
+	"pikpak": {host: "mypikpak.com", hostAliases: []string{"www.mypikpak.com"}, passwordParams: []string{"pwd"}},
+	"mobile": {host: "caiyun.139.com", hostAliases: []string{"yun.139.com"}, keepQuery: true},
+}
+
+// 123网盘等链接中"?提取码:xxxx"形式的提取码
+var queryPasswordPattern = regexp.MustCompile(`^(?:提取码|%E6%8F%90%E5%8F%96%E7%A0%81)[:：]([a-zA-Z0-9]+)$`)
+
+// 链接后紧跟的"提取码: xxxx"等文字，TG消息中的链接有时会连同后面的文字一起被提取
+var trailingPasswordPattern = regexp.MustCompile(`(?:提取码|密码|访问码|pwd)\s*[:：=]?\s*([a-zA-Z0-9]+)`)
+
+// 提取码开头的字母数字部分，去除紧跟的中文、标点等
+var passwordPrefixPattern = regexp.MustCompile(`^[a-zA-Z0-9]+`)
+
+// CanonicalizeShareURL 规范化网盘分享链接，使不同来源返回的同一分享得到相同的链接：
+// 统一使用https和规范主机名（如aliyundrive.com统一为alipan.com），去除锚点和跟踪参数，
+// URL中的提取码（包括链接后紧跟的"提取码: xxxx"）被移出并作为password返回。无法识别的类型或链接原样返回
+func CanonicalizeShareURL(linkType string, rawURL string) (canonical string, password string) {
+	rule, ok := shareLinkRules[linkType]
+	if !ok {
+		return rawURL, ""
+	}
+
+	trimmed := strings.TrimSpace(rawURL)
+	var trailing string
+	if i := strings.IndexFunc(trimmed, unicode.IsSpace); i >= 0 {
+		trimmed, trailing = trimmed[:i], trimmed[i:]
+	}
+	// 省略协议的链接（如pan.baidu.com/s/xxx）按https处理
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+	u, err := netUrl.Parse(trimmed)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return rawURL, ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != rule.host {
+		matched := false
+		for _, alias := range rule.hostAliases {
+			if host == alias {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return rawURL, ""
+		}
+	}
+
+	u.Scheme = "https"
+	u.Host = rule.host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if !rule.keepQuery {
+		if m := queryPasswordPattern.FindStringSubmatch(u.RawQuery); m != nil {
+			password = m[1]
+			u.RawQuery = ""
+		} else {
+			params := u.Query()
+			for _, name := range rule.passwordParams {
+				// 参数名不区分大小写，如PWD=xxxx
+				for key, values := range params {
+					if strings.EqualFold(key, name) && password == "" {
+						password = cleanSharePassword(rule, values[0])
+					}
+				}
+			}
+			kept := netUrl.Values{}
+			for _, name := range rule.keepParams {
+				if value := params.Get(name); value != "" {
+					kept.Set(name, value)
+				}
+			}
+			u.RawQuery = kept.Encode()
+		}
+	}
+	if password == "" && trailing != "" {
+		if m := trailingPasswordPattern.FindStringSubmatch(trailing); m != nil {
+			password = cleanSharePassword(rule, m[1])
+		}
+	}
+	return u.String(), password
+}
+
+// NormalizeSharePassword 规范化网盘分享的提取码：去除首尾空白和紧跟的其他文字，
+// 百度网盘、迅雷云盘等固定长度的提取码截断到固定长度，长度不足时视为无效并返回空字符串
+func NormalizeSharePassword(linkType string, password string) string {
+	if password == "" {
+		return ""
+	}
+	rule, ok := shareLinkRules[linkType]
+	if !ok {
+		return strings.TrimSpace(password)
+	}
+	return cleanSharePassword(rule, password)
+}
+
+// cleanSharePassword 按规则规范化提取码
+func cleanSharePassword(rule shareLinkRule, password string) string {
+	password = passwordPrefixPattern.FindString(strings.TrimSpace(password))
+	if rule.passwordLen > 0 {
+		if len(password) < rule.passwordLen {
+			return ""
+		}
+		password = password[:rule.passwordLen]
+	}
+	return password
+}
+
+// ShareHost 获取网盘类型的规范主机名，没有规范化规则的类型返回空字符串
+func ShareHost(linkType string) string {
+	return shareLinkRules[linkType].host
+}
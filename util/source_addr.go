@@ -0,0 +1,121 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+)
+
+// sourceAddrPool 出站连接的本地源地址池：目标主机首次连接时按轮询分配源地址，
+// 之后同一主机始终使用该地址，避免会话（Cookie、登录状态）因出口IP变化而失效
+type sourceAddrPool struct {
+	addrs []*net.TCPAddr
+	dials []int64
+
+	mu    sync.Mutex
+	next  int
+	hosts map[string]int
+}
+
+var (
+	globalSourceAddrPool *sourceAddrPool
+	sourceAddrPoolOnce   sync.Once
+)
+
+// getSourceAddrPool 获取按SOURCE_ADDRESSES配置的源地址池，未配置或使用代理时返回nil
+func getSourceAddrPool() *sourceAddrPool {
+	sourceAddrPoolOnce.Do(func() {
+		if config.AppConfig == nil || len(config.AppConfig.SourceAddresses) == 0 {
+			return
+		}
+		if config.AppConfig.UseProxy {
+			fmt.Println("[源地址] 已配置代理，忽略SOURCE_ADDRESSES")
+			return
+		}
+		pool := &sourceAddrPool{hosts: make(map[string]int)}
+		for _, addr := range config.AppConfig.SourceAddresses {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				fmt.Printf("[源地址] 忽略无效的源地址: %s\n", addr)
+				continue
+			}
+			pool.addrs = append(pool.addrs, &net.TCPAddr{IP: ip})
+		}
+		if len(pool.addrs) == 0 {
+			return
+		}
+		pool.dials = make([]int64, len(pool.addrs))
+		globalSourceAddrPool = pool
+	})
+	return globalSourceAddrPool
+}
+
+// pick 为目标主机选择源地址。目标为IP地址时只在同一地址族的源地址中选择
+func (p *sourceAddrPool) pick(host string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i, ok := p.hosts[host]; ok {
+		return i
+	}
+
+	wantIPv4 := true
+	if ip := net.ParseIP(host); ip != nil {
+		wantIPv4 = ip.To4() != nil
+	}
+	chosen := -1
+	for n := 0; n < len(p.addrs); n++ {
+		i := (p.next + n) % len(p.addrs)
+		if (p.addrs[i].IP.To4() != nil) == wantIPv4 {
+			chosen = i
+			break
+		}
+	}
+	// 没有同一地址族的源地址时记为-1，由系统选择
+	if chosen >= 0 {
+		p.next = chosen + 1
+	}
+	p.hosts[host] = chosen
+	return chosen
+}
+
+// dialContext 绑定源地址拨号
+func (p *sourceAddrPool) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if i := p.pick(host); i >= 0 {
+		dialer.LocalAddr = p.addrs[i]
+		atomic.AddInt64(&p.dials[i], 1)
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// SourceAddrStats 源地址的使用统计
+type SourceAddrStats struct {
+	Address string `json:"address"`
+	Hosts   int    `json:"hosts"` // 绑定到该地址的目标主机数
+	Dials   int64  `json:"dials"` // 累计通过该地址建立的连接数
+}
+
+// stats 获取各源地址的使用统计
+func (p *sourceAddrPool) stats() []SourceAddrStats {
+	stats := make([]SourceAddrStats, len(p.addrs))
+	for i, addr := range p.addrs {
+		stats[i] = SourceAddrStats{Address: addr.IP.String(), Dials: atomic.LoadInt64(&p.dials[i])}
+	}
+	p.mu.Lock()
+	for _, i := range p.hosts {
+		if i >= 0 {
+			stats[i].Hosts++
+		}
+	}
+	p.mu.Unlock()
+	return stats
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// 预热请求读取响应体的上限，读完后连接才能放回空闲连接池
+const warmupMaxDrain = 64 * 1024
+
+// WarmConnections 预先解析rawURL的主机并完成TCP和TLS握手，建立的连接保留在名称为name的连接池中
+// （同名的每个传输层各一条），之后的请求可直接复用。预热只发出一次HEAD请求，
+// 不经过重试、主机限流和站点失效检测，返回预热成功的传输层数
+func WarmConnections(ctx context.Context, name string, rawURL string) (int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return 0, fmt.Errorf("无效的预热地址: %s", rawURL)
+	}
+	target := u.Scheme + "://" + u.Host + "/"
+
+	connPoolsMu.Lock()
+	var transports []*PooledTransport
+	if pool, ok := connPools[name]; ok {
+		transports = append(transports, pool.transports...)
+	}
+	connPoolsMu.Unlock()
+
+	warmed := 0
+	var firstErr error
+	for _, t := range transports {
+		if err := warmTransport(ctx, t, target); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		warmed++
+	}
+	return warmed, firstErr
+}
+
+// warmTransport 通过传输层向target发出HEAD请求，读完响应体使连接放回空闲连接池
+func warmTransport(ctx context.Context, t *PooledTransport, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	resp, err := t.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.CopyN(io.Discard, resp.Body, warmupMaxDrain)
+	return resp.Body.Close()
+}